@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/home"
+	dwarkaStore "gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/store"
+)
+
+var (
+	configurationFile string
+	prune             bool
+)
+
+// planCmd represents the plan command
+var planCmd = &cobra.Command{
+	Use:           "plan",
+	Short:         "Show the changes required to make the store match a declarative home configuration",
+	SilenceErrors: true,
+	PreRunE:       validateStoreFlags,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return reconcile(true)
+	},
+}
+
+// applyCmd represents the apply command
+var applyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Make the store match a declarative home configuration",
+	Long: `Creates or updates the buildings, floors, rooms, devices and nodes described
+in the configuration, applying the same configuration again makes no changes.
+Entities absent from the configuration are deleted only with --prune.`,
+	SilenceErrors: true,
+	PreRunE:       validateStoreFlags,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return reconcile(false)
+	},
+}
+
+func reconcile(dryRun bool) error {
+	mode := home.ModeMerge
+	if prune {
+		mode = home.ModeReplace
+	}
+
+	desired, err := readHome(configurationFile, "")
+	if err != nil {
+		return err
+	}
+
+	store, err := newStore()
+	if err != nil {
+		return err
+	}
+
+	plan, err := home.Import(store, desired, mode, dryRun)
+	if !dryRun {
+		auditApply(store, plan, err)
+	}
+	if err != nil {
+		return err
+	}
+	printPlan(plan)
+	return nil
+}
+
+// auditApply appends the apply to the audit stream along with an entry per
+// entity it changed, as the import of the server does
+func auditApply(store dwarkaStore.Store, plan home.Plan, applyErr error) {
+	now, route := time.Now(), "apply --file "+configurationFile
+	if applyErr != nil {
+		auditCommand(store, now, route, applyErr)
+		return
+	}
+	// the entries of the entities follow the entry of the command
+	entries, err := plan.Audit(now.Add(time.Nanosecond), cliActor(), "CLI", route)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to audit %s, reason: %v\n", route, err)
+	}
+	auditCommand(store, now, route, nil, entries...)
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{planCmd, applyCmd} {
+		rootCmd.AddCommand(cmd)
+		cmd.Flags().StringVarP(&configurationFile, "file", "f", "home.yaml", "declarative home configuration in JSON or YAML")
+		cmd.Flags().BoolVar(&prune, "prune", false, "delete the entities absent from the configuration")
+		addStoreFlags(cmd)
+	}
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/audit"
+)
+
+var (
+	auditEntity string
+	auditSince  string
+	auditUntil  string
+)
+
+// auditCmd reads the audit stream recorded by the server from the store
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Read the audit stream of the changes made through the server",
+}
+
+var auditExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the audit entries as JSON lines, the oldest first",
+	Long: `Export the audit entries as JSON lines, the oldest first.
+
+  dwarka audit export --entity home/terrace --since 2026-10-01T00:00:00Z > terrace.jsonl`,
+	Args:          cobra.NoArgs,
+	SilenceErrors: true,
+	PreRunE:       validateStoreFlags,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		since, err := parseTimeFlag("since", auditSince)
+		if err != nil {
+			return err
+		}
+		until, err := parseTimeFlag("until", auditUntil)
+		if err != nil {
+			return err
+		}
+
+		store, err := newStore()
+		if err != nil {
+			return err
+		}
+		entries, err := store.Audit(audit.Query{Entity: strings.Trim(auditEntity, "/"), Since: since, Until: until})
+		if err != nil {
+			return err
+		}
+		return entries.WriteJSONLines(os.Stdout)
+	},
+}
+
+// parseTimeFlag parses the RFC 3339 time of the flag, empty is zero time
+func parseTimeFlag(flag, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("--%s should be an RFC 3339 time e.g. 2026-10-19T03:00:00Z", flag)
+	}
+	return parsed, nil
+}
+
+func init() {
+	rootCmd.AddCommand(auditCmd)
+	auditCmd.AddCommand(auditExportCmd)
+	addStoreFlags(auditExportCmd)
+	auditExportCmd.Flags().StringVar(&auditEntity, "entity", "", "building, floor, room or device e.g. home/terrace, includes the entities nested in it")
+	auditExportCmd.Flags().StringVar(&auditSince, "since", "", "RFC 3339 time of the oldest entry")
+	auditExportCmd.Flags().StringVar(&auditUntil, "until", "", "RFC 3339 time after the latest entry")
+}
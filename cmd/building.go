@@ -0,0 +1,218 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/view"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+)
+
+var (
+	buildingInput view.Building
+	tariffInput   gateway.Tariff
+	buildingDate  string
+	energyPeriod  string
+)
+
+// buildingCmd represents the building command
+var buildingCmd = &cobra.Command{
+	Use:   "building",
+	Short: "Manage buildings of a running server",
+}
+
+var buildingListCmd = &cobra.Command{
+	Use:           "list",
+	Short:         "List buildings",
+	Args:          cobra.NoArgs,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		buildings, err := newClient().Buildings(context.Background())
+		if err != nil {
+			return err
+		}
+		return printBuildings(buildings, buildings...)
+	},
+}
+
+var buildingGetCmd = &cobra.Command{
+	Use:           "get <building-id>",
+	Short:         "Get building",
+	Args:          cobra.ExactArgs(1),
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		building, err := newClient().Building(context.Background(), args[0])
+		if err != nil {
+			return err
+		}
+		return printBuildings(building, building)
+	},
+}
+
+var buildingCreateCmd = &cobra.Command{
+	Use:           "create",
+	Short:         "Create building",
+	Args:          cobra.NoArgs,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if cmd.Flags().Changed("tariff-currency") || cmd.Flags().Changed("tariff-rate") {
+			tariff := tariffInput
+			buildingInput.Tariff = &tariff
+		}
+		id, err := newClient().CreateBuilding(context.Background(), buildingInput)
+		if err != nil {
+			return alreadyExists(err, entityBuilding, buildingInput.Name)
+		}
+		fmt.Println(id)
+		return nil
+	},
+}
+
+var buildingUpdateCmd = &cobra.Command{
+	Use:           "update <building-id>",
+	Short:         "Update building, only the given flags are changed",
+	Args:          cobra.ExactArgs(1),
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		building, err := newClient().Building(context.Background(), args[0])
+		if err != nil {
+			return err
+		}
+
+		flags := cmd.Flags()
+		if flags.Changed("description") {
+			building.Description = buildingInput.Description
+		}
+		if flags.Changed("latitude") {
+			building.Latitude = buildingInput.Latitude
+		}
+		if flags.Changed("longitude") {
+			building.Longitude = buildingInput.Longitude
+		}
+		if flags.Changed("timezone") {
+			building.Timezone = buildingInput.Timezone
+		}
+		if flags.Changed("tariff-currency") || flags.Changed("tariff-rate") {
+			tariff := gateway.Tariff{}
+			if building.Tariff != nil {
+				tariff = *building.Tariff
+			}
+			if flags.Changed("tariff-currency") {
+				tariff.Currency = tariffInput.Currency
+			}
+			if flags.Changed("tariff-rate") {
+				tariff.Rate = tariffInput.Rate
+			}
+			building.Tariff = &tariff
+		}
+		return newClient().UpdateBuilding(context.Background(), args[0], building)
+	},
+}
+
+var buildingDeleteCmd = &cobra.Command{
+	Use:           "delete <building-id>",
+	Short:         "Delete building along with its floors, rooms and devices",
+	Args:          cobra.ExactArgs(1),
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return newClient().DeleteBuilding(context.Background(), args[0])
+	},
+}
+
+var buildingSunCmd = &cobra.Command{
+	Use:           "sun <building-id>",
+	Short:         "Show the civil dawn, the sunrise, the sunset and the civil dusk at the building in UTC",
+	Args:          cobra.ExactArgs(1),
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		date := time.Now().UTC()
+		if buildingDate != "" {
+			var err error
+			date, err = time.Parse("2006-01-02", buildingDate)
+			if err != nil {
+				return fmt.Errorf("date %s should be given as yyyy-mm-dd e.g. 2026-10-19", buildingDate)
+			}
+		}
+
+		sun, err := newClient().Sun(context.Background(), args[0], date)
+		if err != nil {
+			return err
+		}
+		row := []string{sun.Date}
+		for _, at := range []*time.Time{sun.Dawn, sun.Sunrise, sun.Sunset, sun.Dusk} {
+			if at == nil {
+				row = append(row, "-")
+				continue
+			}
+			row = append(row, at.Format(time.RFC3339))
+		}
+		return printOutput(sun, []string{"DATE", "DAWN", "SUNRISE", "SUNSET", "DUSK"}, row)
+	},
+}
+
+var buildingEnergyCmd = &cobra.Command{
+	Use:           "energy <building-id>",
+	Short:         "Show the energy consumed in the building, its floors, rooms and devices over a period",
+	Args:          cobra.ExactArgs(1),
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		date := time.Now()
+		if buildingDate != "" {
+			var err error
+			date, err = time.Parse("2006-01-02", buildingDate)
+			if err != nil {
+				return fmt.Errorf("date %s should be given as yyyy-mm-dd e.g. 2026-10-19", buildingDate)
+			}
+		}
+
+		energy, err := newClient().Energy(context.Background(), args[0], energyPeriod, date)
+		if err != nil {
+			return err
+		}
+		rows := [][]string{{"building", energy.Building, formatFloat(energy.Consumption), formatCost(energy.Currency, energy.Cost)}}
+		for _, used := range energy.Usage {
+			rows = append(rows, []string{used.Kind, used.Path, formatFloat(used.Consumption), formatCost(energy.Currency, used.Cost)})
+		}
+		return printOutput(energy, []string{"KIND", "PATH", "KWH", "COST"}, rows...)
+	},
+}
+
+func formatCost(currency string, cost float64) string {
+	if currency == "" {
+		return "-"
+	}
+	return fmt.Sprintf("%.2f %s", cost, currency)
+}
+
+func printBuildings(value interface{}, buildings ...view.Building) error {
+	rows := make([][]string, 0, len(buildings))
+	for _, building := range buildings {
+		rows = append(rows, []string{building.ID, building.Name, formatFloat(building.Latitude), formatFloat(building.Longitude), building.Timezone, building.Description})
+	}
+	return printOutput(value, []string{"ID", "NAME", "LATITUDE", "LONGITUDE", "TIMEZONE", "DESCRIPTION"}, rows...)
+}
+
+func init() {
+	rootCmd.AddCommand(buildingCmd)
+	addClientFlags(buildingCmd)
+	buildingCmd.AddCommand(buildingListCmd, buildingGetCmd, buildingCreateCmd, buildingUpdateCmd, buildingDeleteCmd, buildingSunCmd, buildingEnergyCmd)
+
+	buildingCreateCmd.Flags().StringVar(&buildingInput.Name, "name", "", "name of the building")
+	_ = buildingCreateCmd.MarkFlagRequired("name")
+	for _, cmd := range []*cobra.Command{buildingCreateCmd, buildingUpdateCmd} {
+		cmd.Flags().StringVar(&buildingInput.Description, "description", "", "description of the building")
+		cmd.Flags().Float64Var(&buildingInput.Latitude, "latitude", 0, "latitude of the building")
+		cmd.Flags().Float64Var(&buildingInput.Longitude, "longitude", 0, "longitude of the building")
+		cmd.Flags().StringVar(&buildingInput.Timezone, "timezone", "", "IANA time zone of the schedules of the building e.g. Asia/Kolkata")
+		cmd.Flags().StringVar(&tariffInput.Currency, "tariff-currency", "", "ISO 4217 code of the currency of the tariff e.g. INR")
+		cmd.Flags().Float64Var(&tariffInput.Rate, "tariff-rate", 0, "price of a kWh consumed in the building")
+	}
+	buildingSunCmd.Flags().StringVar(&buildingDate, "date", "", "date given as yyyy-mm-dd, today in UTC by default")
+	buildingEnergyCmd.Flags().StringVar(&buildingDate, "date", "", "date of the period given as yyyy-mm-dd, today by default")
+	buildingEnergyCmd.Flags().StringVar(&energyPeriod, "period", "day", "day, week starting on monday or month")
+	for _, cmd := range []*cobra.Command{buildingGetCmd, buildingUpdateCmd, buildingDeleteCmd, buildingSunCmd, buildingEnergyCmd} {
+		cmd.Annotations = map[string]string{completionAnnotation: entityBuilding}
+	}
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/certs"
+)
+
+var (
+	certsDir     string
+	certsHosts   []string
+	certsClients []string
+	certsTTL     time.Duration
+)
+
+// certsCmd manages the certificates used to serve HTTPS
+var certsCmd = &cobra.Command{
+	Use:   "certs",
+	Short: "Manage the certificates used to serve https",
+}
+
+var certsGenerateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: "Generate a self-signed CA along with server and client certificates for development",
+	Long: `Generate a self-signed CA along with a server certificate for the hosts and a
+client certificate for every client, the files are meant for development only.
+
+  dwarka certs generate --dir certs --host localhost --client kitchen-panel
+  dwarka server --tls-cert certs/server.pem --tls-key certs/server-key.pem --tls-client-ca certs/ca.pem`,
+	Args:          cobra.NoArgs,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		files, err := certs.Generate(certsDir, certsHosts, certsClients, time.Now(), certsTTL)
+		if err != nil {
+			return err
+		}
+		for _, file := range files {
+			fmt.Println(file)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(certsCmd)
+	certsCmd.AddCommand(certsGenerateCmd)
+	certsGenerateCmd.Flags().StringVar(&certsDir, "dir", "certs", "directory to write the certificates and the keys to")
+	certsGenerateCmd.Flags().StringSliceVar(&certsHosts, "host", []string{"localhost", "127.0.0.1"}, "DNS name or IP address of the server, repeat for more")
+	certsGenerateCmd.Flags().StringSliceVar(&certsClients, "client", nil, "common name of a client certificate to issue, repeat for more")
+	certsGenerateCmd.Flags().DurationVar(&certsTTL, "ttl", 365*24*time.Hour, "validity of the certificates")
+}
@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/gosimple/slug"
+	"github.com/spf13/cobra"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/client"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+)
+
+var (
+	serverURL    string
+	serverToken  string
+	outputFormat string
+)
+
+// addClientFlags adds the flags required to talk to a running server
+// to the command and all of its sub commands
+func addClientFlags(cmd *cobra.Command) {
+	server := os.Getenv("DWARKA_SERVER")
+	if server == "" {
+		server = "http://localhost:1410"
+	}
+	cmd.PersistentFlags().StringVar(&serverURL, "server", server, "URL of the dwarka server, defaults to $DWARKA_SERVER")
+	cmd.PersistentFlags().StringVar(&serverToken, "token", os.Getenv("DWARKA_TOKEN"), "token sent as bearer authorization, defaults to $DWARKA_TOKEN")
+	cmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", outputTable, "output format table/json/yaml")
+	// arguments are validated before running the hook, failures after it
+	// are caused by the server and usage would only hide the error
+	cmd.PersistentPreRun = func(cmd *cobra.Command, args []string) {
+		cmd.SilenceUsage = true
+	}
+}
+
+// newClient returns the client of the server given by the flags
+func newClient() *client.Client {
+	return client.New(serverURL, client.WithToken(serverToken))
+}
+
+// alreadyExists describes the conflict reported while creating the entity
+func alreadyExists(err error, entity, name string) error {
+	if errors.Is(err, client.ErrConflict) {
+		return fmt.Errorf("%s %s already exists", entity, slug.Make(name))
+	}
+	return err
+}
+
+// printOutput writes the value in the requested output format, the
+// table is written using the headers and the row of every value
+func printOutput(value interface{}, headers []string, rows ...[]string) error {
+	switch outputFormat {
+	case outputJSON:
+		data, err := json.MarshalIndent(value, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	case outputYAML:
+		// round trip through JSON so that the keys match the JSON output
+		data, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		var generic interface{}
+		err = json.Unmarshal(data, &generic)
+		if err != nil {
+			return err
+		}
+		data, err = yaml.Marshal(generic)
+		if err != nil {
+			return err
+		}
+		fmt.Print(string(data))
+		return nil
+	case outputTable:
+		writer := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+		fmt.Fprintln(writer, strings.Join(headers, "\t"))
+		for _, row := range rows {
+			fmt.Fprintln(writer, strings.Join(row, "\t"))
+		}
+		return writer.Flush()
+	default:
+		return fmt.Errorf("unsupported output format '%s'", outputFormat)
+	}
+}
+
+func formatFloat(value float64) string {
+	return fmt.Sprintf("%g", value)
+}
+
+const (
+	entityBuilding = "building"
+	entityFloor    = "floor"
+	entityRoom     = "room"
+	entityDevice   = "device"
+)
+
+var (
+	parentBuilding string
+	parentFloor    string
+	parentRoom     string
+)
+
+// addParentFlags adds the flags identifying the parents of the entity
+// e.g. a room is identified using --building and --floor
+func addParentFlags(cmd *cobra.Command, entity string) {
+	flags := cmd.PersistentFlags()
+	flags.StringVar(&parentBuilding, entityBuilding, "", "id of the building")
+	_ = cobra.MarkFlagRequired(flags, entityBuilding)
+	_ = cobra.MarkFlagCustom(flags, entityBuilding, completionFunc(entityBuilding))
+	if entity == entityFloor {
+		return
+	}
+
+	flags.StringVar(&parentFloor, entityFloor, "", "id of the floor")
+	_ = cobra.MarkFlagRequired(flags, entityFloor)
+	_ = cobra.MarkFlagCustom(flags, entityFloor, completionFunc(entityFloor))
+	if entity == entityRoom {
+		return
+	}
+
+	flags.StringVar(&parentRoom, entityRoom, "", "id of the room")
+	_ = cobra.MarkFlagRequired(flags, entityRoom)
+	_ = cobra.MarkFlagCustom(flags, entityRoom, completionFunc(entityRoom))
+}
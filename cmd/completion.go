@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// completionAnnotation marks the commands whose argument is the id
+// of an entity, the value is the kind of the entity
+const completionAnnotation = "dwarka_completion_entity"
+
+// completionCmd represents the completion command
+var completionCmd = &cobra.Command{
+	Use:   "completion <bash|zsh|powershell>",
+	Short: "Generate shell completion script",
+	Long: `Generate shell completion script, the bash completion also completes
+the ids of buildings, floors, rooms and devices fetched from the server
+
+    $ source <(dwarka completion bash)`,
+	Args:          cobra.ExactArgs(1),
+	ValidArgs:     []string{"bash", "zsh", "powershell"},
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		switch args[0] {
+		case "bash":
+			rootCmd.BashCompletionFunction = bashCompletionFunction()
+			return rootCmd.GenBashCompletion(os.Stdout)
+		case "zsh":
+			return rootCmd.GenZshCompletion(os.Stdout)
+		case "powershell":
+			return rootCmd.GenPowerShellCompletion(os.Stdout)
+		default:
+			return fmt.Errorf("unsupported shell '%s'", args[0])
+		}
+	},
+}
+
+// completeIDsCmd prints the ids of the entities, used by the bash completion
+var completeIDsCmd = &cobra.Command{
+	Use:           "__complete-ids <building|floor|room|device>",
+	Hidden:        true,
+	Args:          cobra.ExactArgs(1),
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ids, err := entityIDs(context.Background(), args[0])
+		if err != nil {
+			return err
+		}
+
+		for _, id := range ids {
+			fmt.Println(id)
+		}
+		return nil
+	},
+}
+
+// entityIDs returns the ids of the entities of the kind using the parent flags
+func entityIDs(ctx context.Context, kind string) ([]string, error) {
+	c := newClient()
+	var ids []string
+	switch kind {
+	case entityBuilding:
+		buildings, err := c.Buildings(ctx)
+		for _, building := range buildings {
+			ids = append(ids, building.ID)
+		}
+		return ids, err
+	case entityFloor:
+		floors, err := c.Floors(ctx, parentBuilding)
+		for _, floor := range floors {
+			ids = append(ids, floor.ID)
+		}
+		return ids, err
+	case entityRoom:
+		rooms, err := c.Rooms(ctx, parentBuilding, parentFloor)
+		for _, room := range rooms {
+			ids = append(ids, room.ID)
+		}
+		return ids, err
+	case entityDevice:
+		devices, err := c.Devices(ctx, parentBuilding, parentFloor, parentRoom)
+		for _, device := range devices {
+			ids = append(ids, device.ID)
+		}
+		return ids, err
+	default:
+		return nil, fmt.Errorf("unknown kind %s, should be one of building, floor, room or device", kind)
+	}
+}
+
+func completionFunc(entity string) string {
+	return fmt.Sprintf("__%s_complete_ids %s", rootCmd.Name(), entity)
+}
+
+// bashCompletionFunction returns the bash functions which complete the
+// ids of the entities using the flags already present on the command line
+func bashCompletionFunction() string {
+	root := rootCmd.Name()
+	cases := map[string][]string{}
+	var walk func(cmd *cobra.Command)
+	walk = func(cmd *cobra.Command) {
+		if entity, ok := cmd.Annotations[completionAnnotation]; ok {
+			name := strings.Replace(cmd.CommandPath(), " ", "_", -1)
+			cases[entity] = append(cases[entity], name)
+		}
+		for _, child := range cmd.Commands() {
+			walk(child)
+		}
+	}
+	walk(rootCmd)
+
+	entities := make([]string, 0, len(cases))
+	for entity := range cases {
+		entities = append(entities, entity)
+	}
+	sort.Strings(entities)
+
+	builder := strings.Builder{}
+	fmt.Fprintf(&builder, `__%[1]s_complete_ids()
+{
+    local args=("$1") flag value
+    for flag in --server --token --building --floor --room; do
+        value=${flaghash[${flag}]:-${flaghash[${flag}=]}}
+        if [[ -n ${value} ]]; then
+            args+=("${flag}=${value}")
+        fi
+    done
+
+    local out
+    if out=$(%[1]s __complete-ids "${args[@]}" 2>/dev/null); then
+        COMPREPLY=( $(compgen -W "${out}" -- "$cur") )
+    fi
+}
+
+__%[1]s_custom_func()
+{
+    case ${last_command} in
+`, root)
+	for _, entity := range entities {
+		fmt.Fprintf(&builder, "        %s)\n            %s\n            return\n            ;;\n",
+			strings.Join(cases[entity], " | "), completionFunc(entity))
+	}
+	builder.WriteString("        *)\n            ;;\n    esac\n}\n")
+	return builder.String()
+}
+
+func init() {
+	rootCmd.AddCommand(completionCmd, completeIDsCmd)
+	addClientFlags(completeIDsCmd)
+	completeIDsCmd.PersistentFlags().StringVar(&parentBuilding, entityBuilding, "", "id of the building")
+	completeIDsCmd.PersistentFlags().StringVar(&parentFloor, entityFloor, "", "id of the floor")
+	completeIDsCmd.PersistentFlags().StringVar(&parentRoom, entityRoom, "", "id of the room")
+}
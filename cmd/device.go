@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/view"
+)
+
+var deviceInput view.Device
+
+// deviceCmd represents the device command
+var deviceCmd = &cobra.Command{
+	Use:   "device",
+	Short: "Manage and switch devices of a room of a running server",
+}
+
+var deviceListCmd = &cobra.Command{
+	Use:           "list",
+	Short:         "List devices of the room",
+	Args:          cobra.NoArgs,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		devices, err := newClient().Devices(context.Background(), parentBuilding, parentFloor, parentRoom)
+		if err != nil {
+			return err
+		}
+		return printDevices(devices, devices...)
+	},
+}
+
+var deviceGetCmd = &cobra.Command{
+	Use:           "get <device-id>",
+	Short:         "Get device",
+	Args:          cobra.ExactArgs(1),
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		device, err := newClient().Device(context.Background(), parentBuilding, parentFloor, parentRoom, args[0])
+		if err != nil {
+			return err
+		}
+		return printDevices(device, device)
+	},
+}
+
+var deviceCreateCmd = &cobra.Command{
+	Use:           "create",
+	Short:         "Create device in the room",
+	Args:          cobra.NoArgs,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := newClient().CreateDevice(context.Background(), parentBuilding, parentFloor, parentRoom, deviceInput)
+		if err != nil {
+			return alreadyExists(err, entityDevice, deviceInput.Name)
+		}
+		fmt.Println(id)
+		return nil
+	},
+}
+
+var deviceUpdateCmd = &cobra.Command{
+	Use:           "update <device-id>",
+	Short:         "Update device, only the given flags are changed",
+	Args:          cobra.ExactArgs(1),
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		device, err := newClient().Device(context.Background(), parentBuilding, parentFloor, parentRoom, args[0])
+		if err != nil {
+			return err
+		}
+
+		flags := cmd.Flags()
+		if flags.Changed("description") {
+			device.Description = deviceInput.Description
+		}
+		if flags.Changed("meta") {
+			device.Meta = deviceInput.Meta
+		}
+		return newClient().UpdateDevice(context.Background(), parentBuilding, parentFloor, parentRoom, args[0], device)
+	},
+}
+
+var deviceDeleteCmd = &cobra.Command{
+	Use:           "delete <device-id>",
+	Short:         "Delete device",
+	Args:          cobra.ExactArgs(1),
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return newClient().DeleteDevice(context.Background(), parentBuilding, parentFloor, parentRoom, args[0])
+	},
+}
+
+var deviceOnCmd = &cobra.Command{
+	Use:           "on <device-id>",
+	Short:         "Switch on the device",
+	Args:          cobra.ExactArgs(1),
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return newClient().SwitchOn(context.Background(), parentBuilding, parentFloor, parentRoom, args[0])
+	},
+}
+
+var deviceOffCmd = &cobra.Command{
+	Use:           "off <device-id>",
+	Short:         "Switch off the device",
+	Args:          cobra.ExactArgs(1),
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return newClient().SwitchOff(context.Background(), parentBuilding, parentFloor, parentRoom, args[0])
+	},
+}
+
+func printDevices(value interface{}, devices ...view.Device) error {
+	rows := make([][]string, 0, len(devices))
+	for _, device := range devices {
+		rows = append(rows, []string{device.ID, device.Name, device.Room, formatMeta(device.Meta), device.Description})
+	}
+	return printOutput(value, []string{"ID", "NAME", "ROOM", "META", "DESCRIPTION"}, rows...)
+}
+
+func formatMeta(meta map[string]string) string {
+	pairs := make([]string, 0, len(meta))
+	for key, value := range meta {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", key, value))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func init() {
+	rootCmd.AddCommand(deviceCmd)
+	addClientFlags(deviceCmd)
+	addParentFlags(deviceCmd, entityDevice)
+	deviceCmd.AddCommand(deviceListCmd, deviceGetCmd, deviceCreateCmd, deviceUpdateCmd, deviceDeleteCmd, deviceOnCmd, deviceOffCmd)
+
+	deviceCreateCmd.Flags().StringVar(&deviceInput.Name, "name", "", "name of the device")
+	_ = deviceCreateCmd.MarkFlagRequired("name")
+	for _, cmd := range []*cobra.Command{deviceCreateCmd, deviceUpdateCmd} {
+		cmd.Flags().StringVar(&deviceInput.Description, "description", "", "description of the device")
+		cmd.Flags().StringToStringVar(&deviceInput.Meta, "meta", nil, "metadata of the device e.g. pin=4,type=light")
+	}
+	for _, cmd := range []*cobra.Command{deviceGetCmd, deviceUpdateCmd, deviceDeleteCmd, deviceOnCmd, deviceOffCmd} {
+		cmd.Annotations = map[string]string{completionAnnotation: entityDevice}
+	}
+}
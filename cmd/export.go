@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+
+	"github.com/spf13/cobra"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/home"
+)
+
+var (
+	exportOutput string
+	exportFormat string
+)
+
+// exportCmd represents the export command
+var exportCmd = &cobra.Command{
+	Use:           "export",
+	Short:         "Export buildings, floors, rooms and devices as JSON or YAML",
+	SilenceErrors: true,
+	PreRunE:       validateStoreFlags,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := newStore()
+		if err != nil {
+			return err
+		}
+
+		exported, err := home.Export(store)
+		if err != nil {
+			return err
+		}
+
+		format := exportFormat
+		if format == "" {
+			format = home.FormatOf(exportOutput)
+		}
+
+		data, err := home.Encode(exported, format)
+		if err != nil {
+			return err
+		}
+
+		if exportOutput == "" {
+			_, err = os.Stdout.Write(data)
+			return err
+		}
+		return ioutil.WriteFile(exportOutput, data, 0644)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+	exportCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "file to write the export, defaults to stdout")
+	exportCmd.Flags().StringVar(&exportFormat, "format", "", "json or yaml, defaults to the extension of the output")
+	addStoreFlags(exportCmd)
+}
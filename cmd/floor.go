@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/view"
+)
+
+var floorInput view.Floor
+
+// floorCmd represents the floor command
+var floorCmd = &cobra.Command{
+	Use:   "floor",
+	Short: "Manage floors of a building of a running server",
+}
+
+var floorListCmd = &cobra.Command{
+	Use:           "list",
+	Short:         "List floors of the building",
+	Args:          cobra.NoArgs,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		floors, err := newClient().Floors(context.Background(), parentBuilding)
+		if err != nil {
+			return err
+		}
+		return printFloors(floors, floors...)
+	},
+}
+
+var floorGetCmd = &cobra.Command{
+	Use:           "get <floor-id>",
+	Short:         "Get floor",
+	Args:          cobra.ExactArgs(1),
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		floor, err := newClient().Floor(context.Background(), parentBuilding, args[0])
+		if err != nil {
+			return err
+		}
+		return printFloors(floor, floor)
+	},
+}
+
+var floorCreateCmd = &cobra.Command{
+	Use:           "create",
+	Short:         "Create floor in the building",
+	Args:          cobra.NoArgs,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := newClient().CreateFloor(context.Background(), parentBuilding, floorInput)
+		if err != nil {
+			return alreadyExists(err, entityFloor, floorInput.Name)
+		}
+		fmt.Println(id)
+		return nil
+	},
+}
+
+var floorUpdateCmd = &cobra.Command{
+	Use:           "update <floor-id>",
+	Short:         "Update floor, only the given flags are changed",
+	Args:          cobra.ExactArgs(1),
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		floor, err := newClient().Floor(context.Background(), parentBuilding, args[0])
+		if err != nil {
+			return err
+		}
+
+		flags := cmd.Flags()
+		if flags.Changed("description") {
+			floor.Description = floorInput.Description
+		}
+		if flags.Changed("level") {
+			floor.Level = floorInput.Level
+		}
+		return newClient().UpdateFloor(context.Background(), parentBuilding, args[0], floor)
+	},
+}
+
+var floorDeleteCmd = &cobra.Command{
+	Use:           "delete <floor-id>",
+	Short:         "Delete floor along with its rooms and devices",
+	Args:          cobra.ExactArgs(1),
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return newClient().DeleteFloor(context.Background(), parentBuilding, args[0])
+	},
+}
+
+func printFloors(value interface{}, floors ...view.Floor) error {
+	rows := make([][]string, 0, len(floors))
+	for _, floor := range floors {
+		rows = append(rows, []string{floor.ID, floor.Name, strconv.Itoa(floor.Level), floor.Building, floor.Description})
+	}
+	return printOutput(value, []string{"ID", "NAME", "LEVEL", "BUILDING", "DESCRIPTION"}, rows...)
+}
+
+func init() {
+	rootCmd.AddCommand(floorCmd)
+	addClientFlags(floorCmd)
+	addParentFlags(floorCmd, entityFloor)
+	floorCmd.AddCommand(floorListCmd, floorGetCmd, floorCreateCmd, floorUpdateCmd, floorDeleteCmd)
+
+	floorCreateCmd.Flags().StringVar(&floorInput.Name, "name", "", "name of the floor")
+	_ = floorCreateCmd.MarkFlagRequired("name")
+	for _, cmd := range []*cobra.Command{floorCreateCmd, floorUpdateCmd} {
+		cmd.Flags().StringVar(&floorInput.Description, "description", "", "description of the floor")
+		cmd.Flags().IntVar(&floorInput.Level, "level", 0, "level of the floor")
+	}
+	for _, cmd := range []*cobra.Command{floorGetCmd, floorUpdateCmd, floorDeleteCmd} {
+		cmd.Annotations = map[string]string{completionAnnotation: entityFloor}
+	}
+}
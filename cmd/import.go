@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/spf13/cobra"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/home"
+)
+
+var (
+	importFile   string
+	importFormat string
+	importMode   string
+	importDryRun bool
+)
+
+// importCmd represents the import command
+var importCmd = &cobra.Command{
+	Use:           "import",
+	Short:         "Import buildings, floors, rooms and devices from a JSON or YAML file",
+	SilenceErrors: true,
+	PreRunE:       validateStoreFlags,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		mode, err := home.NewMode(importMode)
+		if err != nil {
+			return err
+		}
+
+		desired, err := readHome(importFile, importFormat)
+		if err != nil {
+			return err
+		}
+
+		store, err := newStore()
+		if err != nil {
+			return err
+		}
+
+		plan, err := home.Import(store, desired, mode, importDryRun)
+		if err != nil {
+			return err
+		}
+		printPlan(plan)
+		return nil
+	},
+}
+
+// readHome reads the home from the file, - reads from stdin, and
+// converts it into a validated tree
+func readHome(file, format string) (gateway.Tree, error) {
+	data, err := readFile(file)
+	if err != nil {
+		return gateway.Tree{}, err
+	}
+
+	if format == "" {
+		format = home.FormatOf(file)
+	}
+
+	h, err := home.Decode(data, format)
+	if err != nil {
+		return gateway.Tree{}, err
+	}
+	return h.Tree()
+}
+
+func printPlan(plan home.Plan) {
+	fmt.Print(plan)
+	if !plan.DryRun && len(plan.Changes) > 0 {
+		fmt.Println("Changes applied.")
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+	importCmd.Flags().StringVarP(&importFile, "file", "f", "-", "file to import, - reads from stdin")
+	importCmd.Flags().StringVar(&importFormat, "format", "", "json or yaml, defaults to the extension of the file")
+	importCmd.Flags().StringVar(&importMode, "mode", string(home.ModeMerge), "merge keeps entities missing from the file, replace deletes them")
+	importCmd.Flags().BoolVar(&importDryRun, "dry-run", false, "report the changes without persisting them")
+	addStoreFlags(importCmd)
+}
+
+// readFile reads the file or stdin when the file is -
+func readFile(file string) ([]byte, error) {
+	if file == "-" {
+		return ioutil.ReadAll(os.Stdin)
+	}
+	return ioutil.ReadFile(file)
+}
@@ -0,0 +1,314 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/user"
+	"strings"
+	"time"
+
+	libkvStore "github.com/kvtools/valkeyrie/store"
+	"github.com/kvtools/valkeyrie/store/boltdb"
+	"github.com/kvtools/valkeyrie/store/consul"
+	"github.com/spf13/cobra"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/audit"
+	dwarkaStore "gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/store"
+)
+
+var (
+	backupOutput string
+	restoreFile  string
+	copyFrom     string
+	copyTo       string
+	copyBasePath string
+	copyOptions  dwarkaStore.CopyOptions
+)
+
+// storeCmd groups the offline maintenance commands of the store, the
+// server should be stopped while running them
+var storeCmd = &cobra.Command{
+	Use:   "store",
+	Short: "Offline maintenance of the store e.g. backup, restore and verify",
+}
+
+var storeBackupCmd = &cobra.Command{
+	Use:           "backup",
+	Short:         "Backup every key under the base path as JSON",
+	Args:          cobra.NoArgs,
+	SilenceErrors: true,
+	PreRunE:       validateStoreFlags,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		snapshot, err := readSnapshot()
+		if err != nil {
+			return err
+		}
+
+		data, err := json.MarshalIndent(snapshot, "", "  ")
+		if err != nil {
+			return err
+		}
+		data = append(data, '\n')
+
+		if backupOutput == "" || backupOutput == "-" {
+			_, err = os.Stdout.Write(data)
+			return err
+		}
+		err = ioutil.WriteFile(backupOutput, data, 0600)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Backed up %d keys to %s\n", len(snapshot.Entries), backupOutput)
+		return nil
+	},
+}
+
+var storeRestoreCmd = &cobra.Command{
+	Use:           "restore",
+	Short:         "Replace every key under the base path with the keys of a backup",
+	Args:          cobra.NoArgs,
+	SilenceErrors: true,
+	PreRunE:       validateStoreFlags,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		data, err := readFile(restoreFile)
+		if err != nil {
+			return err
+		}
+
+		snapshot := dwarkaStore.Snapshot{}
+		err = json.Unmarshal(data, &snapshot)
+		if err != nil {
+			return fmt.Errorf("unable to parse backup %s, %v", restoreFile, err)
+		}
+
+		kvStore, err := newKVStore()
+		if err != nil {
+			return err
+		}
+
+		err = dwarkaStore.Restore(kvStore, snapshot, storeBasePath)
+		auditRestore(dwarkaStore.NewPersistentStore(storeBasePath, kvStore), err)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Restored %d keys under %s\n", len(snapshot.Entries), storeBasePath)
+		return nil
+	},
+}
+
+var storeDumpCmd = &cobra.Command{
+	Use:           "dump",
+	Short:         "Print every key under the base path along with its value except the audit stream",
+	Args:          cobra.NoArgs,
+	SilenceErrors: true,
+	PreRunE:       validateStoreFlags,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		snapshot, err := readSnapshot()
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range snapshot.Entries {
+			value := bytes.Buffer{}
+			if json.Indent(&value, entry.Value, "  ", "  ") != nil {
+				value.Reset()
+				value.Write(entry.Value)
+			}
+			fmt.Printf("%s\n  %s\n", entry.Key, value.String())
+		}
+		return nil
+	},
+}
+
+var storeVerifyCmd = &cobra.Command{
+	Use:           "verify",
+	Short:         "Validate every collection and report keys unreachable from the buildings",
+	Args:          cobra.NoArgs,
+	SilenceErrors: true,
+	PreRunE:       validateStoreFlags,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		kvStore, err := newKVStore()
+		if err != nil {
+			return err
+		}
+
+		problems, err := dwarkaStore.Verify(kvStore, storeBasePath)
+		if err != nil {
+			return err
+		}
+
+		if len(problems) == 0 {
+			fmt.Println("No problems found.")
+			return nil
+		}
+		for _, problem := range problems {
+			fmt.Println(problem)
+		}
+		cmd.SilenceUsage = true
+		return fmt.Errorf("found %d problems under %s", len(problems), storeBasePath)
+	},
+}
+
+var storeCompactCmd = &cobra.Command{
+	Use:           "compact",
+	Short:         "Rewrite the BoltDB file to reclaim the space of deleted keys",
+	Args:          cobra.NoArgs,
+	SilenceErrors: true,
+	PreRunE:       validateStoreFlags,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if libkvStore.Backend(storeBackend) != libkvStore.BOLTDB {
+			return fmt.Errorf("compact is supported only for %s backend", libkvStore.BOLTDB)
+		}
+
+		before, after, err := dwarkaStore.CompactBoltDB(boldDBFilePath)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Compacted %s from %d to %d bytes\n", boldDBFilePath, before, after)
+		return nil
+	},
+}
+
+var storeCopyCmd = &cobra.Command{
+	Use:   "copy",
+	Short: "Copy every key under the base path along with the audit stream from one backend to another",
+	Long: `Copy every key under the base path along with the credentials and the audit stream from one
+backend to another e.g. from BoltDB to Consul, the backends are given as <backend>:<address> and
+the base path can be remapped as <from>:<to>. The keys are copied one at a time and read a
+collection at a time, --skip-credentials and --skip-audit leave the tokens and the audit stream
+out of the copy and the keys left out are reported`,
+	Example:       "  dwarka store copy --from boltdb:data/dwarka --to consul:http://127.0.0.1:8500 --base-path dwarka:home",
+	Args:          cobra.NoArgs,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		// both the backends are registered since the flag hack registers
+		// only the backend given by --store-backend
+		boltdb.Register()
+		consul.Register()
+
+		from, err := parseBackend("from", copyFrom)
+		if err != nil {
+			return err
+		}
+		to, err := parseBackend("to", copyTo)
+		if err != nil {
+			return err
+		}
+
+		fromBasePath, toBasePath := copyBasePath, copyBasePath
+		if i := strings.Index(copyBasePath, ":"); i >= 0 {
+			fromBasePath, toBasePath = copyBasePath[:i], copyBasePath[i+1:]
+		}
+		if fromBasePath == "" || toBasePath == "" {
+			return fmt.Errorf("invalid base path '%s', expected <path> or <from>:<to>", copyBasePath)
+		}
+
+		result, err := dwarkaStore.Copy(from, fromBasePath, to, toBasePath, copyOptions)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Copied %d keys from %s to %s, checksum %s\n", result.Keys, fromBasePath, toBasePath, result.Checksum)
+		if copyOptions.SkipCredentials {
+			fmt.Printf("Skipped %d keys of the credentials\n", result.SkippedCredentials)
+		}
+		if copyOptions.SkipAudit {
+			fmt.Printf("Skipped %d keys of the audit stream\n", result.SkippedAudit)
+		}
+		return nil
+	},
+}
+
+// auditRestore appends the restore to the audit stream as the restore of
+// the server does
+func auditRestore(store dwarkaStore.Store, restoreErr error) {
+	auditCommand(store, time.Now(), "store restore --file "+restoreFile, restoreErr)
+}
+
+// auditCommand appends the command to the audit stream as a request to the
+// server is, the actor is the user running the command
+func auditCommand(store dwarkaStore.Store, now time.Time, route string, commandErr error, entries ...audit.Entry) {
+	status := http.StatusOK
+	if commandErr != nil {
+		status = http.StatusInternalServerError
+	}
+
+	entry, err := audit.NewEntry(now, cliActor(), "CLI", route, "", status)
+	if err == nil {
+		if commandErr != nil {
+			entry.Error = commandErr.Error()
+		}
+		for _, entry := range append([]audit.Entry{entry}, entries...) {
+			if err = store.AppendAudit(entry); err != nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to audit %s, reason: %v\n", route, err)
+	}
+}
+
+// cliActor returns the user running the command
+func cliActor() audit.Actor {
+	actor := audit.Actor{Name: "unknown", Kind: "cli"}
+	if current, err := user.Current(); err == nil {
+		actor.Name = current.Username
+	}
+	return actor
+}
+
+func newKVStore() (libkvStore.Store, error) {
+	return dwarkaStore.NewKVStore(storeBackend, bucketName, addrs()...)
+}
+
+// parseBackend connects to the backend given as <backend>:<address>
+// e.g. boltdb:data/dwarka or consul:http://127.0.0.1:8500
+func parseBackend(flag, value string) (libkvStore.Store, error) {
+	i := strings.Index(value, ":")
+	if i <= 0 || i == len(value)-1 {
+		return nil, fmt.Errorf("invalid --%s '%s', expected <backend>:<address>", flag, value)
+	}
+
+	backend, address := value[:i], value[i+1:]
+	switch libkvStore.Backend(backend) {
+	case libkvStore.BOLTDB, libkvStore.CONSUL:
+	default:
+		return nil, fmt.Errorf("unsupported store backend '%s' in --%s", backend, flag)
+	}
+	return dwarkaStore.NewKVStore(backend, bucketName, address)
+}
+
+func readSnapshot() (dwarkaStore.Snapshot, error) {
+	kvStore, err := newKVStore()
+	if err != nil {
+		return dwarkaStore.Snapshot{}, err
+	}
+	return dwarkaStore.Backup(kvStore, storeBasePath)
+}
+
+func init() {
+	rootCmd.AddCommand(storeCmd)
+	storeCmd.AddCommand(storeBackupCmd, storeRestoreCmd, storeDumpCmd, storeVerifyCmd, storeCompactCmd)
+	for _, cmd := range storeCmd.Commands() {
+		addStoreFlags(cmd)
+	}
+	storeCmd.AddCommand(storeCopyCmd)
+
+	storeBackupCmd.Flags().StringVarP(&backupOutput, "output", "o", "", "file to write the backup, defaults to stdout")
+	storeRestoreCmd.Flags().StringVarP(&restoreFile, "file", "f", "", "backup to restore, - for stdin")
+	_ = storeRestoreCmd.MarkFlagRequired("file")
+
+	storeCopyCmd.Flags().StringVar(&copyFrom, "from", "", "backend to copy from as <backend>:<address> e.g. boltdb:data/dwarka")
+	storeCopyCmd.Flags().StringVar(&copyTo, "to", "", "backend to copy to as <backend>:<address> e.g. consul:http://127.0.0.1:8500")
+	storeCopyCmd.Flags().StringVar(&copyBasePath, "base-path", "dwarka", "base path to copy, <from>:<to> to copy under a different base path")
+	storeCopyCmd.Flags().StringVar(&bucketName, "bucket-name", "dwarka", "bucket name of the BoltDB backends")
+	storeCopyCmd.Flags().BoolVar(&copyOptions.Overwrite, "overwrite", false, "replace the keys already under the base path of the destination")
+	storeCopyCmd.Flags().BoolVar(&copyOptions.SkipCredentials, "skip-credentials", false, "leave the tokens and the signing key of the jwts out of the copy")
+	storeCopyCmd.Flags().BoolVar(&copyOptions.SkipAudit, "skip-audit", false, "leave the audit stream out of the copy")
+	_ = storeCopyCmd.MarkFlagRequired("from")
+	_ = storeCopyCmd.MarkFlagRequired("to")
+}
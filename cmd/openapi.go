@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+
+	"github.com/spf13/cobra"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api"
+)
+
+var openAPIOutput string
+
+// openAPICmd represents the openapi command
+var openAPICmd = &cobra.Command{
+	Use:           "openapi",
+	Short:         "Write the OpenAPI specification of the REST API",
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		data, err := json.MarshalIndent(api.OpenAPI(), "", "  ")
+		if err != nil {
+			return err
+		}
+
+		if openAPIOutput == "" {
+			_, err = os.Stdout.Write(append(data, '\n'))
+			return err
+		}
+		return ioutil.WriteFile(openAPIOutput, data, 0644)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(openAPICmd)
+	openAPICmd.Flags().StringVarP(&openAPIOutput, "output", "o", "", "file to write the specification, defaults to stdout")
+}
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/view"
+)
+
+var roomInput view.Room
+
+// roomCmd represents the room command
+var roomCmd = &cobra.Command{
+	Use:   "room",
+	Short: "Manage rooms of a floor of a running server",
+}
+
+var roomListCmd = &cobra.Command{
+	Use:           "list",
+	Short:         "List rooms of the floor",
+	Args:          cobra.NoArgs,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		rooms, err := newClient().Rooms(context.Background(), parentBuilding, parentFloor)
+		if err != nil {
+			return err
+		}
+		return printRooms(rooms, rooms...)
+	},
+}
+
+var roomGetCmd = &cobra.Command{
+	Use:           "get <room-id>",
+	Short:         "Get room",
+	Args:          cobra.ExactArgs(1),
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		room, err := newClient().Room(context.Background(), parentBuilding, parentFloor, args[0])
+		if err != nil {
+			return err
+		}
+		return printRooms(room, room)
+	},
+}
+
+var roomCreateCmd = &cobra.Command{
+	Use:           "create",
+	Short:         "Create room in the floor",
+	Args:          cobra.NoArgs,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := newClient().CreateRoom(context.Background(), parentBuilding, parentFloor, roomInput)
+		if err != nil {
+			return alreadyExists(err, entityRoom, roomInput.Name)
+		}
+		fmt.Println(id)
+		return nil
+	},
+}
+
+var roomUpdateCmd = &cobra.Command{
+	Use:           "update <room-id>",
+	Short:         "Update room, only the given flags are changed",
+	Args:          cobra.ExactArgs(1),
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		room, err := newClient().Room(context.Background(), parentBuilding, parentFloor, args[0])
+		if err != nil {
+			return err
+		}
+
+		flags := cmd.Flags()
+		if flags.Changed("description") {
+			room.Description = roomInput.Description
+		}
+		if flags.Changed("direction") {
+			room.Direction = roomInput.Direction
+		}
+		return newClient().UpdateRoom(context.Background(), parentBuilding, parentFloor, args[0], room)
+	},
+}
+
+var roomDeleteCmd = &cobra.Command{
+	Use:           "delete <room-id>",
+	Short:         "Delete room along with its devices",
+	Args:          cobra.ExactArgs(1),
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return newClient().DeleteRoom(context.Background(), parentBuilding, parentFloor, args[0])
+	},
+}
+
+func printRooms(value interface{}, rooms ...view.Room) error {
+	rows := make([][]string, 0, len(rooms))
+	for _, room := range rooms {
+		rows = append(rows, []string{room.ID, room.Name, room.Direction, room.Floor, room.Building, room.Description})
+	}
+	return printOutput(value, []string{"ID", "NAME", "DIRECTION", "FLOOR", "BUILDING", "DESCRIPTION"}, rows...)
+}
+
+func init() {
+	rootCmd.AddCommand(roomCmd)
+	addClientFlags(roomCmd)
+	addParentFlags(roomCmd, entityRoom)
+	roomCmd.AddCommand(roomListCmd, roomGetCmd, roomCreateCmd, roomUpdateCmd, roomDeleteCmd)
+
+	roomCreateCmd.Flags().StringVar(&roomInput.Name, "name", "", "name of the room")
+	_ = roomCreateCmd.MarkFlagRequired("name")
+	for _, cmd := range []*cobra.Command{roomCreateCmd, roomUpdateCmd} {
+		cmd.Flags().StringVar(&roomInput.Description, "description", "", "description of the room")
+		cmd.Flags().StringVar(&roomInput.Direction, "direction", "north", "direction the room faces north/east/south/west")
+	}
+	for _, cmd := range []*cobra.Command{roomGetCmd, roomUpdateCmd, roomDeleteCmd} {
+		cmd.Annotations = map[string]string{completionAnnotation: entityRoom}
+	}
+}
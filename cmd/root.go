@@ -13,7 +13,7 @@ var cfgFile string
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
-	Use:   "gateway",
+	Use:   "dwarka",
 	Short: "API gateway for smart-home",
 	Long:  `API gateway offers REST API to manage various device controlled using 'MQTT' protocol`,
 }
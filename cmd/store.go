@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/kvtools/valkeyrie/store"
+	"github.com/kvtools/valkeyrie/store/boltdb"
+	"github.com/kvtools/valkeyrie/store/consul"
+	"github.com/spf13/cobra"
+	dwarkaStore "gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/store"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/strings"
+)
+
+var (
+	storeBackend     string
+	boldDBFilePath   string
+	consulHTTPAddr   string
+	storeBasePath    string
+	bucketName       string
+	supportedBackend = []string{string(store.BOLTDB), string(store.CONSUL)}
+)
+
+var _ = func() error {
+	storeBackend = flagHackLookup("--store-backend")
+	if storeBackend == "" {
+		storeBackend = string(store.BOLTDB)
+	}
+	return nil
+}()
+
+func init() {
+	switch store.Backend(storeBackend) {
+	case store.CONSUL:
+		consul.Register()
+	case store.BOLTDB:
+		boltdb.Register()
+	}
+}
+
+// addStoreFlags adds the flags required to connect to the store backend,
+// commands using the flags should validate them using validateStoreFlags
+func addStoreFlags(cmd *cobra.Command) {
+	cmd.Flags().String("store-backend", string(store.BOLTDB), "store backend to use boltdb/consul")
+	cmd.Flags().StringVar(&storeBasePath, "store-base-path", "dwarka", "Base path for persisting all data")
+	cmd.Flags().StringVar(&bucketName, "bucket-name", "dwarka", "Base path for persisting all data")
+
+	switch store.Backend(storeBackend) {
+	case store.CONSUL:
+		usage := `The 'address' and port of the Consul HTTP agent. The value can be
+an IP address or DNS address, but it must also include the port.`
+		cmd.Flags().StringVar(&consulHTTPAddr, "consul-http-addr", "http://127.0.0.1:8500", usage)
+	case store.BOLTDB:
+		cmd.Flags().StringVar(&boldDBFilePath, "boltdb-file-path", "data/dwarka", "file path to use for persisting into disk")
+	}
+}
+
+func validateStoreFlags(cmd *cobra.Command, args []string) error {
+	if !strings.Contains(supportedBackend, storeBackend) {
+		return fmt.Errorf("unsupported store backend '%s'", storeBackend)
+	}
+	return nil
+}
+
+func newStore() (dwarkaStore.Store, error) {
+	return dwarkaStore.NewStore(storeBasePath, storeBackend, bucketName, addrs()...)
+}
+
+func addrs() []string {
+	backend := store.Backend(storeBackend)
+	switch backend {
+	case store.CONSUL:
+		return []string{consulHTTPAddr}
+	case store.BOLTDB:
+		return []string{boldDBFilePath}
+	default:
+		return []string{}
+	}
+}
@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/auth"
+)
+
+var (
+	tokenKind   string
+	tokenTTL    time.Duration
+	tokenRole   string
+	tokenScopes []string
+)
+
+// tokenCmd manages the tokens used to authenticate against the server,
+// the tokens are read from the store and hence take effect immediately
+var tokenCmd = &cobra.Command{
+	Use:   "token",
+	Short: "Manage the API keys and JWTs used to authenticate against the server",
+}
+
+var tokenCreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Create a token, the API key or the JWT is printed only once",
+	Long: `Create a token, the API key or the JWT is printed only once.
+
+A certificate token maps the client certificates with the name as their
+common name to the role and the scopes of the token, no secret is printed
+as the client authenticates using its certificate over mutual TLS.`,
+	Args:          cobra.ExactArgs(1),
+	SilenceErrors: true,
+	PreRunE:       validateStoreFlags,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		role, err := auth.NewRole(tokenRole)
+		if err != nil {
+			return err
+		}
+		scopes := make([]auth.Scope, 0, len(tokenScopes))
+		for _, value := range tokenScopes {
+			scope, err := auth.NewScope(value)
+			if err != nil {
+				return err
+			}
+			scopes = append(scopes, scope)
+		}
+
+		store, err := newStore()
+		if err != nil {
+			return err
+		}
+
+		var token auth.Token
+		var credentials string
+		switch auth.Kind(tokenKind) {
+		case auth.APIKey:
+			token, credentials, err = auth.NewAPIKey(args[0], time.Now(), tokenTTL)
+		case auth.JWT:
+			var signingKey []byte
+			signingKey, err = store.SigningKey()
+			if err == nil {
+				token, credentials, err = auth.NewJWT(args[0], time.Now(), tokenTTL, signingKey)
+			}
+		case auth.Certificate:
+			token, err = auth.NewCertificate(args[0], time.Now(), tokenTTL)
+		default:
+			return fmt.Errorf("unsupported token type '%s', expected %s, %s or %s", tokenKind, auth.APIKey, auth.JWT, auth.Certificate)
+		}
+		if err != nil {
+			return err
+		}
+
+		token.Role = role
+		if len(scopes) > 0 {
+			token.Scopes = scopes
+		}
+		err = store.UpsertToken(token)
+		if err != nil {
+			return err
+		}
+		if token.Kind == auth.Certificate {
+			fmt.Printf("Created %s %s for the client certificates of %s\n", token.Kind, token.ID, token.Name)
+			return nil
+		}
+		fmt.Fprintf(os.Stderr, "Created %s %s for %s, store it safely as it is not shown again\n", token.Kind, token.ID, token.Name)
+		fmt.Println(credentials)
+		return nil
+	},
+}
+
+var tokenListCmd = &cobra.Command{
+	Use:           "list",
+	Short:         "List tokens",
+	Args:          cobra.NoArgs,
+	SilenceErrors: true,
+	PreRunE:       validateStoreFlags,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		store, err := newStore()
+		if err != nil {
+			return err
+		}
+
+		tokens, err := store.Tokens()
+		if err != nil {
+			return err
+		}
+
+		list := make([]auth.Token, 0, len(tokens))
+		for _, token := range tokens {
+			list = append(list, token)
+		}
+		sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt.Before(list[j].CreatedAt) })
+
+		rows := make([][]string, 0, len(list))
+		for _, token := range list {
+			expiresAt := "never"
+			if token.ExpiresAt != nil {
+				expiresAt = token.ExpiresAt.Local().Format(time.RFC3339)
+			}
+			scopes := "*"
+			if len(token.Scopes) > 0 {
+				values := make([]string, 0, len(token.Scopes))
+				for _, scope := range token.Scopes {
+					values = append(values, string(scope))
+				}
+				scopes = strings.Join(values, ",")
+			}
+			rows = append(rows, []string{token.ID, token.Name, string(token.Kind), string(token.EffectiveRole()), scopes,
+				token.CreatedAt.Local().Format(time.RFC3339), expiresAt})
+		}
+		return printOutput(list, []string{"ID", "NAME", "TYPE", "ROLE", "SCOPES", "CREATED", "EXPIRES"}, rows...)
+	},
+}
+
+var tokenRevokeCmd = &cobra.Command{
+	Use:           "revoke <token-id>",
+	Short:         "Revoke a token, the API key or the JWT is rejected immediately",
+	Args:          cobra.ExactArgs(1),
+	SilenceErrors: true,
+	PreRunE:       validateStoreFlags,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		store, err := newStore()
+		if err != nil {
+			return err
+		}
+
+		err = store.DeleteToken(args[0])
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Revoked token %s\n", args[0])
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(tokenCmd)
+	tokenCmd.AddCommand(tokenCreateCmd, tokenListCmd, tokenRevokeCmd)
+	for _, cmd := range tokenCmd.Commands() {
+		addStoreFlags(cmd)
+	}
+
+	tokenCreateCmd.Flags().StringVar(&tokenKind, "type", string(auth.APIKey), "type of the token api-key/jwt/certificate")
+	tokenCreateCmd.Flags().DurationVar(&tokenTTL, "ttl", 0, "validity of the token e.g. 720h, required for jwt, 0 never expires an api key or a certificate token")
+	tokenCreateCmd.Flags().StringVar(&tokenRole, "role", string(auth.RoleViewer), "role of the token admin/editor/operator/viewer")
+	tokenCreateCmd.Flags().StringSliceVar(&tokenScopes, "scope", nil, "limit the token to a building, floor or room e.g. home/first-floor/kitchen, repeat for more")
+	tokenListCmd.Flags().StringVarP(&outputFormat, "output", "o", outputTable, "output format table/json/yaml")
+}
@@ -14,6 +14,8 @@ require (
 	github.com/spf13/viper v1.5.0
 	github.com/stretchr/testify v1.7.5
 	github.com/valyala/fasthttp v1.44.0
+	go.etcd.io/bbolt v1.3.4
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -48,9 +50,7 @@ require (
 	github.com/subosito/gotenv v1.2.0 // indirect
 	github.com/valyala/bytebufferpool v1.0.0 // indirect
 	github.com/valyala/tcplisten v1.0.0 // indirect
-	go.etcd.io/bbolt v1.3.4 // indirect
 	golang.org/x/sys v0.0.0-20220728004956-3c1f35247d10 // indirect
 	golang.org/x/text v0.3.7 // indirect
 	gopkg.in/yaml.v2 v2.2.8 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
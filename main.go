@@ -21,7 +21,13 @@ THE SOFTWARE.
 */
 package main
 
-import "gitlab.com/vedhabhavanam/smarthome/dwarka/cmd"
+import (
+	// the time zones of the buildings are resolved on the hosts without
+	// the zoneinfo as well
+	_ "time/tzdata"
+
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/cmd"
+)
 
 func main() {
 	cmd.Execute()
@@ -0,0 +1,50 @@
+package api
+
+import (
+	"crypto/subtle"
+	"errors"
+	"strings"
+
+	"github.com/valyala/fasthttp"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/server"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/auth"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/store"
+)
+
+const (
+	adminBasePath = "/admin"
+	bearerPrefix  = "Bearer "
+)
+
+var adminToken string
+
+// SetAdminToken sets the bearer token required by the admin routes,
+// the admin routes are forbidden when the token is empty
+func SetAdminToken(token string) {
+	adminToken = token
+}
+
+// requireAdmin allows the tokens of the admin role when the authentication
+// is enabled, the admin token of the server is required otherwise
+var requireAdmin = func(store store.Store, ctx server.RequestContext) error {
+	if token, ok := requestToken(ctx); ok {
+		err := token.Authorize(auth.Manage)
+		if err != nil {
+			return forbidden(ctx, err)
+		}
+		return ctx.Next()
+	}
+
+	if adminToken == "" {
+		err := errors.New("admin routes are disabled, start the server with an admin token")
+		return ctx.JSONResponse(map[string]string{"error": err.Error()}, fasthttp.StatusForbidden)
+	}
+
+	authorization := string(ctx.RequestHeader("Authorization"))
+	token := strings.TrimPrefix(authorization, bearerPrefix)
+	if !strings.HasPrefix(authorization, bearerPrefix) || subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) != 1 {
+		err := errors.New("admin token is missing or invalid")
+		return ctx.JSONResponse(map[string]string{"error": err.Error()}, fasthttp.StatusUnauthorized)
+	}
+	return ctx.Next()
+}
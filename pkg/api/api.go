@@ -1,25 +1,87 @@
 package api
 
 import (
+	"sort"
+
 	"github.com/valyala/fasthttp"
 	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/server"
 	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/store"
 )
 
-var routes []server.Route
+const (
+	// V1 is the current version of the REST API, routes added
+	// through AddRoute belong to this version
+	V1 = "v1"
+
+	// Unversioned represents the routes which are served without
+	// any version prefix e.g. /openapi.json
+	Unversioned = ""
+)
+
+var routes = map[string][]server.Route{}
 
-// AddRoute add route to list of known routes
+// AddRoute add route to list of known routes of the current version
 func AddRoute(route ...server.Route) {
-	routes = append(routes, route...)
+	AddVersionedRoute(V1, route...)
+}
+
+// AddVersionedRoute add route to list of known routes of the given version,
+// every version is served under its own prefix e.g. /v1/buildings
+func AddVersionedRoute(version string, route ...server.Route) {
+	routes[version] = append(routes[version], route...)
+}
+
+// AddUnversionedRoute add route to list of known routes which are
+// served without version prefix
+func AddUnversionedRoute(route ...server.Route) {
+	AddVersionedRoute(Unversioned, route...)
+}
+
+// Routes returns the known routes of the given version
+func Routes(version string) []server.Route {
+	return routes[version]
+}
+
+// Versions returns all the versions which has at least one route
+func Versions() []string {
+	versions := make([]string, 0, len(routes))
+	for version := range routes {
+		if version == Unversioned {
+			continue
+		}
+		versions = append(versions, version)
+	}
+	sort.Strings(versions)
+	return versions
 }
 
 // NewServer returns an abstracted http server with all
-// the known routes added
+// the known routes added under their version prefix, the
+// routes of V1 are also served without prefix as deprecated
+// aliases until the clients move to the versioned routes
 func NewServer(host, port string, store store.Store) server.Server {
 	httpServer := server.NewHTTPServer(host, port, store)
-	for _, route := range routes {
+	if certificates != nil {
+		httpServer = server.NewHTTPSServer(host, port, store, certificates)
+	}
+	if authenticationEnabled {
+		httpServer.Authenticate(authenticate)
+	}
+	for _, route := range routes[Unversioned] {
 		httpServer.Path(route)
 	}
+
+	for _, version := range Versions() {
+		group := httpServer.Group("/" + version)
+		for _, route := range routes[version] {
+			group.Path(route)
+		}
+	}
+
+	legacy := httpServer.Deprecated("/" + V1)
+	for _, route := range routes[V1] {
+		legacy.Path(route)
+	}
 	return httpServer
 }
 
@@ -31,6 +93,10 @@ func badRequest(ctx server.RequestContext, err error) error {
 	return ctx.JSONResponse(map[string]string{"error": err.Error()}, fasthttp.StatusBadRequest)
 }
 
+func forbidden(ctx server.RequestContext, err error) error {
+	return ctx.JSONResponse(map[string]string{"error": err.Error()}, fasthttp.StatusForbidden)
+}
+
 func created(ctx server.RequestContext, id string) error {
 	return ctx.JSONResponse(map[string]string{"id": id}, fasthttp.StatusCreated)
 }
@@ -0,0 +1,94 @@
+package api_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api"
+	mockStore "gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/internal/mocks/store"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/testutils"
+)
+
+func TestVersions(t *testing.T) {
+	t.Run("should serve routes under version prefix", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockKVStore := mockStore.NewMockStore(ctrl)
+		mockKVStore.EXPECT().Uptime().Return(testutils.Uptime(), nil)
+
+		request, err := http.NewRequest("GET", "http://test/v1/ping", nil)
+		if err != nil {
+			t.Error(err)
+		}
+
+		res, err := testutils.ServeHTTPRequest(mockKVStore, request)
+		assert.NoError(t, err)
+		assert.Equal(t, 200, res.StatusCode)
+		assert.Empty(t, res.Header.Get("Deprecation"))
+	})
+
+	t.Run("should serve unversioned routes as deprecated aliases", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockKVStore := mockStore.NewMockStore(ctrl)
+		mockKVStore.EXPECT().Buildings().Return(nil, nil)
+
+		request, err := http.NewRequest("GET", "http://test/buildings/building-one", nil)
+		if err != nil {
+			t.Error(err)
+		}
+
+		res, err := testutils.ServeHTTPRequest(mockKVStore, request)
+		assert.NoError(t, err)
+		assert.Equal(t, 404, res.StatusCode)
+		assert.Equal(t, "true", res.Header.Get("Deprecation"))
+		assert.Equal(t, `</v1/buildings/building-one>; rel="successor-version"`, res.Header.Get("Link"))
+	})
+
+	t.Run("should respond with the media type of the version accepted", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockKVStore := mockStore.NewMockStore(ctrl)
+		mockKVStore.EXPECT().Uptime().Return(testutils.Uptime(), nil).Times(2)
+
+		for _, url := range []string{"http://test/v1/ping", "http://test/ping"} {
+			request, err := http.NewRequest("GET", url, nil)
+			if err != nil {
+				t.Error(err)
+			}
+			request.Header.Set("Accept", "application/vnd.dwarka.v2+json, application/vnd.dwarka.v1+json;q=0.5")
+
+			res, err := testutils.ServeHTTPRequest(mockKVStore, request)
+			assert.NoError(t, err)
+			assert.Equal(t, 200, res.StatusCode, url)
+			assert.Equal(t, "application/vnd.dwarka.v1+json", res.Header.Get("Content-Type"), url)
+		}
+	})
+
+	t.Run("should not serve the versions which are not accepted", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockKVStore := mockStore.NewMockStore(ctrl)
+
+		for _, accept := range []string{"application/vnd.dwarka.v2+json", "application/vnd.dwarka.v1+json;q=0"} {
+			request, err := http.NewRequest("GET", "http://test/v1/ping", nil)
+			if err != nil {
+				t.Error(err)
+			}
+			request.Header.Set("Accept", accept)
+
+			res, err := testutils.ServeHTTPRequest(mockKVStore, request)
+			assert.NoError(t, err)
+			assert.Equal(t, 406, res.StatusCode, accept)
+			body, _ := ioutil.ReadAll(res.Body)
+			assert.JSONEq(t, `{"error":"/v1/ping is served as application/vnd.dwarka.v1+json"}`, string(body))
+		}
+	})
+
+	t.Run("should list v1 as a known version", func(t *testing.T) {
+		assert.Contains(t, api.Versions(), api.V1)
+	})
+}
@@ -0,0 +1,335 @@
+package api
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/valyala/fasthttp"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/server"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/view"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/audit"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/home"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/store"
+)
+
+const (
+	auditBasePath  = "/audit"
+	auditBeforeKey = "audit.before"
+	auditAfterKey  = "audit.after"
+	auditEntityKey = "audit.entity"
+	auditPlanKey   = "audit.plan"
+	entityParam    = "entity"
+	sinceParam     = "since"
+	untilParam     = "until"
+	jsonLines      = "jsonl"
+)
+
+var auditEnabled bool
+
+// EnableAudit records every create, update, delete and device command
+// in the audit stream of the store for the servers created afterwards
+func EnableAudit(enabled bool) {
+	auditEnabled = enabled
+}
+
+func init() {
+	AddRoute(
+		server.NewRouteWithFilters("GET", auditBasePath, auditHandler, &server.Filters{Before: []server.ResponseHandler{requireAdmin}}).Describe(server.Documentation{
+			Summary: "List the audit entries of the changes, the oldest first", Tags: []string{"admin"},
+			Query: map[string]string{
+				entityParam: "building, floor, room or device e.g. home/terrace, includes the entities nested in it",
+				sinceParam:  "RFC 3339 time of the oldest entry",
+				untilParam:  "RFC 3339 time after the latest entry",
+				formatParam: "json (default) or jsonl to export an entry per line",
+			},
+			Response: audit.Entries{}, Errors: []int{fasthttp.StatusBadRequest, fasthttp.StatusUnauthorized, fasthttp.StatusForbidden},
+		}),
+	)
+}
+
+// entityResource returns the ids of the building, floor, room and device
+// of the path
+func entityResource(ctx server.RequestContext) []string {
+	resource := pathResource(ctx)
+	if id, ok := ctx.UserValue(deviceID).(string); ok && id != "" && len(resource) == 3 {
+		resource = append(resource, id)
+	}
+	return resource
+}
+
+// snapshotEntity keeps the entity of the path before the request so that
+// the audit entry records the fields changed by the request, the entity
+// created by a POST does not exist before
+var snapshotEntity = func(store store.Store, ctx server.RequestContext) error {
+	if !auditEnabled || string(ctx.Method()) == "POST" {
+		return ctx.Next()
+	}
+	if _, ok := ctx.UserValue(auditEntityKey).(string); ok {
+		return ctx.Next()
+	}
+
+	resource := entityResource(ctx)
+	if len(resource) == 0 {
+		return ctx.Next()
+	}
+	before, err := entityState(store, resource)
+	if err != nil {
+		return internalServerError(ctx, err)
+	}
+	ctx.SetUserValue(auditBeforeKey, before)
+	return ctx.Next()
+}
+
+// recordAudit appends the entry of the request to the audit stream once
+// it is responded
+var recordAudit = func(store store.Store, ctx server.RequestContext) error {
+	appendAudit(store, ctx)
+	return ctx.Next()
+}
+
+// auditResponded wraps the filters of an audited route so that the requests
+// they respond to e.g. with 404 for a missing building are audited as well,
+// the After filters including recordAudit are not run for them
+func auditResponded(filters ...server.ResponseHandler) []server.ResponseHandler {
+	wrapped := make([]server.ResponseHandler, len(filters))
+	for i, filter := range filters {
+		filter := filter
+		wrapped[i] = func(store store.Store, ctx server.RequestContext) error {
+			passing := &passingContext{RequestContext: ctx}
+			err := filter(store, passing)
+			if !passing.passed {
+				appendAudit(store, ctx)
+			}
+			return err
+		}
+	}
+	return wrapped
+}
+
+// passingContext tells whether a filter passed the request on
+type passingContext struct {
+	server.RequestContext
+	passed bool
+}
+
+func (ctx *passingContext) Next() error {
+	ctx.passed = true
+	return ctx.RequestContext.Next()
+}
+
+// appendAudit appends the entry of the request, the entries failing to
+// append are logged as the request has been served already
+func appendAudit(store store.Store, ctx server.RequestContext) {
+	if !auditEnabled || string(ctx.Method()) == "GET" || string(ctx.QueryArgs().Peek(dryRunParam)) == "true" {
+		return
+	}
+
+	status := ctx.ResponseStatusCode()
+	if entity, ok := ctx.UserValue(auditEntityKey).(string); ok {
+		recordAuditOf(store, ctx, entity, status)
+		return
+	}
+
+	resource := entityResource(ctx)
+	if status == fasthttp.StatusCreated {
+		response := map[string]string{}
+		if json.Unmarshal(ctx.ResponseBody(), &response) == nil && response["id"] != "" {
+			resource = append(resource, response["id"])
+		}
+	}
+
+	entry, err := newAuditEntry(ctx, resource, status)
+	if err == nil && len(resource) > 0 {
+		before := ctx.UserValue(auditBeforeKey)
+		after := before
+		if status < fasthttp.StatusMultipleChoices {
+			after, err = entityState(store, resource)
+		}
+		if err == nil {
+			err = entry.SetState(before, after)
+		}
+	}
+	if err == nil {
+		err = store.AppendAudit(entry)
+	}
+	if err != nil {
+		log.Printf("unable to audit %s %s, reason: %v", ctx.Method(), ctx.Path(), err)
+	}
+
+	if plan, ok := ctx.UserValue(auditPlanKey).(home.Plan); ok && status < fasthttp.StatusMultipleChoices {
+		recordAuditOfPlan(store, ctx, plan)
+	}
+}
+
+// recordAuditOfPlan appends an entry per entity changed by the plan applied
+// by the request along with the entity before and after it
+func recordAuditOfPlan(store store.Store, ctx server.RequestContext, plan home.Plan) {
+	entries, err := plan.Audit(time.Now(), auditActor(ctx), string(ctx.Method()), string(ctx.Path()))
+	for i := 0; err == nil && i < len(entries); i++ {
+		err = store.AppendAudit(entries[i])
+	}
+	if err != nil {
+		log.Printf("unable to audit the changes of %s %s, reason: %v", ctx.Method(), ctx.Path(), err)
+	}
+}
+
+// setAuditEntity records the entity outside the tree of buildings, e.g. a
+// scene, as the entity of the audit entry along with its state before the
+// request, the handlers keep the state after the request in auditAfterKey
+func setAuditEntity(ctx server.RequestContext, entity string, before interface{}) {
+	ctx.SetUserValue(auditEntityKey, entity)
+	ctx.SetUserValue(auditBeforeKey, before)
+}
+
+// recordAuditOf appends the entry of the entity set by setAuditEntity
+func recordAuditOf(store store.Store, ctx server.RequestContext, entity string, status int) {
+	entry, err := newAuditEntry(ctx, strings.Split(entity, "/"), status)
+	if err == nil {
+		before := ctx.UserValue(auditBeforeKey)
+		after := before
+		if status < fasthttp.StatusMultipleChoices {
+			if state := ctx.UserValue(auditAfterKey); state != nil {
+				after = state
+			} else if string(ctx.Method()) == "DELETE" {
+				after = nil
+			}
+		}
+		err = entry.SetState(before, after)
+	}
+	if err == nil {
+		err = store.AppendAudit(entry)
+	}
+	if err != nil {
+		log.Printf("unable to audit %s %s, reason: %v", ctx.Method(), ctx.Path(), err)
+	}
+}
+
+// auditDenied appends the entry of a request denied by the authorization
+func auditDenied(store store.Store, ctx server.RequestContext) {
+	if !auditEnabled {
+		return
+	}
+
+	entry, err := newAuditEntry(ctx, entityResource(ctx), ctx.ResponseStatusCode())
+	if err == nil {
+		err = store.AppendAudit(entry)
+	}
+	if err != nil {
+		log.Printf("unable to audit %s %s, reason: %v", ctx.Method(), ctx.Path(), err)
+	}
+}
+
+func newAuditEntry(ctx server.RequestContext, resource []string, status int) (audit.Entry, error) {
+	entry, err := audit.NewEntry(time.Now(), auditActor(ctx), string(ctx.Method()), string(ctx.Path()), strings.Join(resource, "/"), status)
+	if err != nil {
+		return audit.Entry{}, err
+	}
+	if status >= fasthttp.StatusMultipleChoices {
+		response := map[string]interface{}{}
+		if json.Unmarshal(ctx.ResponseBody(), &response) == nil {
+			entry.Error, _ = response["error"].(string)
+		}
+	}
+	return entry, nil
+}
+
+// auditActor returns the principal of the request, the requests to the
+// admin routes carry the admin token when the authentication is disabled
+func auditActor(ctx server.RequestContext) audit.Actor {
+	token, ok := requestToken(ctx)
+	if !ok {
+		authorization := string(ctx.RequestHeader("Authorization"))
+		if adminToken == "" || subtle.ConstantTimeCompare([]byte(strings.TrimPrefix(authorization, bearerPrefix)), []byte(adminToken)) != 1 {
+			return audit.Anonymous
+		}
+		token = adminPrincipal
+	}
+	return audit.Actor{ID: token.ID, Name: token.Name, Kind: string(token.Kind)}
+}
+
+// entityState returns the view of the building, floor, room or device
+// given as its ids, nil when it does not exist
+func entityState(store store.Store, resource []string) (interface{}, error) {
+	buildings, err := store.Buildings()
+	if err != nil {
+		return nil, err
+	}
+	building, ok := buildings[resource[0]]
+	if !ok {
+		return nil, nil
+	} else if len(resource) == 1 {
+		return view.NewBuilding(building), nil
+	}
+
+	floors, err := store.Floors(building)
+	if err != nil {
+		return nil, err
+	}
+	floor, ok := floors[resource[1]]
+	if !ok {
+		return nil, nil
+	} else if len(resource) == 2 {
+		return view.NewFloor(floor), nil
+	}
+
+	rooms, err := store.Rooms(floor)
+	if err != nil {
+		return nil, err
+	}
+	room, ok := rooms[resource[2]]
+	if !ok {
+		return nil, nil
+	} else if len(resource) == 3 {
+		return view.NewRoom(room), nil
+	}
+
+	devices, err := store.Devices(room)
+	if err != nil {
+		return nil, err
+	}
+	device, ok := devices[resource[3]]
+	if !ok {
+		return nil, nil
+	}
+	return view.NewDevice(device), nil
+}
+
+var auditHandler = func(store store.Store, ctx server.RequestContext) error {
+	args := ctx.QueryArgs()
+	query := audit.Query{Entity: strings.Trim(string(args.Peek(entityParam)), "/")}
+	for param, value := range map[string]*time.Time{sinceParam: &query.Since, untilParam: &query.Until} {
+		if raw := string(args.Peek(param)); raw != "" {
+			parsed, err := time.Parse(time.RFC3339, raw)
+			if err != nil {
+				return badRequest(ctx, fmt.Errorf("%s should be an RFC 3339 time e.g. 2026-10-19T03:00:00Z", param))
+			}
+			*value = parsed
+		}
+	}
+
+	format := string(args.Peek(formatParam))
+	if format != "" && format != "json" && format != jsonLines {
+		return badRequest(ctx, fmt.Errorf("unsupported format '%s', expected json or jsonl", format))
+	}
+
+	entries, err := store.Audit(query)
+	if err != nil {
+		return internalServerError(ctx, err)
+	}
+	if format != jsonLines {
+		return ctx.JSONResponse(entries, fasthttp.StatusOK)
+	}
+
+	lines := &strings.Builder{}
+	err = entries.WriteJSONLines(lines)
+	if err != nil {
+		return internalServerError(ctx, err)
+	}
+	ctx.SetContentType("application/x-ndjson")
+	ctx.SetBodyString(lines.String())
+	return nil
+}
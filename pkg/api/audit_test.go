@@ -0,0 +1,204 @@
+package api_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/audit"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/auth"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/store"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/testutils"
+)
+
+// enableAudit enables the authentication along with the audit until the
+// end of the test and returns the seeded store
+func enableAudit(t *testing.T) store.Store {
+	persistentStore := enableAuthentication(t)
+	api.EnableAudit(true)
+	t.Cleanup(func() {
+		api.EnableAudit(false)
+	})
+	seedStore(t, persistentStore)
+	return persistentStore
+}
+
+func serveBodyAs(t *testing.T, persistentStore store.Store, apiKey, method, url, body string) *http.Response {
+	request, err := http.NewRequest(method, "http://test/v1"+url, strings.NewReader(body))
+	if err != nil {
+		t.Error(err)
+	}
+	request.Header.Set("X-API-Key", apiKey)
+
+	res, err := testutils.ServeHTTPRequest(persistentStore, request)
+	assert.NoError(t, err)
+	return res
+}
+
+func TestAudit(t *testing.T) {
+	t.Run("should record the actor along with the entity before and after", func(t *testing.T) {
+		persistentStore := enableAudit(t)
+		editor := issueToken(t, persistentStore, auth.RoleEditor)
+		admin := issueToken(t, persistentStore, auth.RoleAdmin)
+
+		res := serveBodyAs(t, persistentStore, editor, "PUT", "/buildings/building-one/floors/floor-one", `{"name":"floor-one","description":"terrace","level":2}`)
+		assert.Equal(t, fasthttp.StatusOK, res.StatusCode)
+		res = serveAs(t, persistentStore, editor, "DELETE", "/buildings/building-one/floors/floor-one")
+		assert.Equal(t, fasthttp.StatusOK, res.StatusCode)
+		res = serveAs(t, persistentStore, editor, "GET", "/buildings/building-one")
+		assert.Equal(t, fasthttp.StatusOK, res.StatusCode)
+
+		res = serveAs(t, persistentStore, admin, "GET", "/audit?entity=building-one/floor-one")
+		var entries audit.Entries
+		if assert.NoError(t, testutils.Read(res, &entries)) && assert.Len(t, entries, 2) {
+			update, deletion := entries[0], entries[1]
+			assert.Equal(t, "editor", update.Actor.Name)
+			assert.Equal(t, "PUT", update.Method)
+			assert.Equal(t, "/v1/buildings/building-one/floors/floor-one", update.Route)
+			assert.Equal(t, "building-one/floor-one", update.Entity)
+			assert.Equal(t, audit.Success, update.Outcome)
+			assert.Contains(t, update.Diff, audit.Change{Path: "description", Before: json.RawMessage(`""`), After: json.RawMessage(`"terrace"`)})
+
+			assert.Equal(t, "DELETE", deletion.Method)
+			assert.Contains(t, string(deletion.Before), `"description":"terrace"`)
+			assert.Nil(t, deletion.After)
+		}
+	})
+
+	t.Run("should record the id of the created entity", func(t *testing.T) {
+		persistentStore := enableAudit(t)
+		editor := issueToken(t, persistentStore, auth.RoleEditor)
+
+		res := serveBodyAs(t, persistentStore, editor, "POST", "/buildings/building-one/floors", `{"name":"terrace","level":3}`)
+		assert.Equal(t, fasthttp.StatusCreated, res.StatusCode)
+		res = serveBodyAs(t, persistentStore, editor, "POST", "/buildings/building-one/floors", `{"name":"terrace","level":3}`)
+		assert.Equal(t, fasthttp.StatusConflict, res.StatusCode)
+
+		entries, err := persistentStore.Audit(audit.Query{Entity: "building-one/terrace"})
+		if assert.NoError(t, err) && assert.Len(t, entries, 1) {
+			assert.Nil(t, entries[0].Before)
+			assert.Contains(t, entries[0].Diff, audit.Change{Path: "level", After: json.RawMessage("3")})
+		}
+
+		entries, err = persistentStore.Audit(audit.Query{Entity: "building-one"})
+		if assert.NoError(t, err) && assert.Len(t, entries, 2) {
+			assert.Equal(t, "building-one", entries[1].Entity)
+			assert.Equal(t, fasthttp.StatusConflict, entries[1].Status)
+			assert.Equal(t, audit.Failure, entries[1].Outcome)
+		}
+	})
+
+	t.Run("should record the denied requests", func(t *testing.T) {
+		persistentStore := enableAudit(t)
+		viewer := issueToken(t, persistentStore, auth.RoleViewer)
+
+		res := serveAs(t, persistentStore, viewer, "DELETE", "/buildings/building-one")
+		assert.Equal(t, fasthttp.StatusForbidden, res.StatusCode)
+
+		entries, err := persistentStore.Audit(audit.Query{})
+		if assert.NoError(t, err) && assert.Len(t, entries, 1) {
+			assert.Equal(t, "viewer", entries[0].Actor.Name)
+			assert.Equal(t, "building-one", entries[0].Entity)
+			assert.Equal(t, audit.Failure, entries[0].Outcome)
+			assert.Equal(t, "role viewer is not allowed to write", entries[0].Error)
+		}
+		buildings, err := persistentStore.Buildings()
+		assert.NoError(t, err)
+		assert.Contains(t, buildings, "building-one")
+	})
+
+	t.Run("should record the requests responded by the filters", func(t *testing.T) {
+		persistentStore := enableAudit(t)
+		editor := issueToken(t, persistentStore, auth.RoleEditor)
+
+		res := serveAs(t, persistentStore, editor, "DELETE", "/buildings/terrace")
+		assert.Equal(t, fasthttp.StatusNotFound, res.StatusCode)
+
+		entries, err := persistentStore.Audit(audit.Query{})
+		if assert.NoError(t, err) && assert.Len(t, entries, 1) {
+			assert.Equal(t, "DELETE", entries[0].Method)
+			assert.Equal(t, "terrace", entries[0].Entity)
+			assert.Equal(t, fasthttp.StatusNotFound, entries[0].Status)
+			assert.Equal(t, audit.Failure, entries[0].Outcome)
+		}
+	})
+
+	t.Run("should record every entity changed by the import", func(t *testing.T) {
+		persistentStore := enableAudit(t)
+		editor := issueToken(t, persistentStore, auth.RoleEditor)
+
+		res := serveBodyAs(t, persistentStore, editor, "POST", "/import?dry-run=true", `{"buildings":[{"name":"guest-house","latitude":12.97,"longitude":77.59,"floors":[{"name":"ground","level":1}]}]}`)
+		assert.Equal(t, fasthttp.StatusOK, res.StatusCode)
+		res = serveBodyAs(t, persistentStore, editor, "POST", "/import", `{"buildings":[{"name":"guest-house","latitude":12.97,"longitude":77.59,"floors":[{"name":"ground","level":1}]}]}`)
+		assert.Equal(t, fasthttp.StatusOK, res.StatusCode)
+
+		entries, err := persistentStore.Audit(audit.Query{})
+		if assert.NoError(t, err) && assert.Len(t, entries, 3) {
+			assert.Equal(t, "", entries[0].Entity)
+			assert.Equal(t, "/v1/import", entries[0].Route)
+		}
+		entries, err = persistentStore.Audit(audit.Query{Entity: "guest-house"})
+		if assert.NoError(t, err) && assert.Len(t, entries, 2) {
+			assert.Equal(t, "guest-house", entries[0].Entity)
+			assert.Equal(t, "guest-house/ground", entries[1].Entity)
+			assert.Equal(t, "editor", entries[1].Actor.Name)
+			assert.Nil(t, entries[1].Before)
+			assert.Contains(t, entries[1].Diff, audit.Change{Path: "level", After: json.RawMessage("1")})
+		}
+	})
+
+	t.Run("should export the entries as json lines", func(t *testing.T) {
+		persistentStore := enableAudit(t)
+		editor := issueToken(t, persistentStore, auth.RoleEditor)
+		admin := issueToken(t, persistentStore, auth.RoleAdmin)
+		serveAs(t, persistentStore, editor, "DELETE", "/buildings/building-two")
+		serveAs(t, persistentStore, editor, "DELETE", "/buildings/building-one")
+
+		res := serveAs(t, persistentStore, admin, "GET", "/audit?format=jsonl&since=2020-01-01T00:00:00Z")
+
+		assert.Equal(t, fasthttp.StatusOK, res.StatusCode)
+		assert.Equal(t, "application/x-ndjson", res.Header.Get("Content-Type"))
+		scanner := bufio.NewScanner(res.Body)
+		entities := []string{}
+		for scanner.Scan() {
+			entry, err := audit.NewEntryFromJSON(scanner.Bytes())
+			assert.NoError(t, err)
+			entities = append(entities, entry.Entity)
+		}
+		assert.Equal(t, []string{"building-two", "building-one"}, entities)
+	})
+
+	t.Run("should reject malformed query", func(t *testing.T) {
+		persistentStore := enableAudit(t)
+		admin := issueToken(t, persistentStore, auth.RoleAdmin)
+
+		for url, message := range map[string]string{
+			"/audit?since=yesterday": "since should be an RFC 3339 time e.g. 2026-10-19T03:00:00Z",
+			"/audit?format=csv":      "unsupported format 'csv', expected json or jsonl",
+		} {
+			res := serveAs(t, persistentStore, admin, "GET", url)
+
+			assert.Equal(t, fasthttp.StatusBadRequest, res.StatusCode)
+			actual, err := testutils.ReadError(res)
+			assert.NoError(t, err)
+			assert.Equal(t, message, actual)
+		}
+	})
+
+	t.Run("should not record without audit", func(t *testing.T) {
+		persistentStore := enableAuthentication(t)
+		seedStore(t, persistentStore)
+		editor := issueToken(t, persistentStore, auth.RoleEditor)
+
+		serveAs(t, persistentStore, editor, "DELETE", "/buildings/building-one")
+
+		entries, err := persistentStore.Audit(audit.Query{})
+		assert.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+}
@@ -0,0 +1,46 @@
+package api
+
+import (
+	"crypto/subtle"
+	"time"
+
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/server"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/auth"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/store"
+)
+
+var authenticationEnabled bool
+
+// EnableAuthentication requires the credentials of every route except
+// the public ones for the servers created afterwards
+func EnableAuthentication(enabled bool) {
+	authenticationEnabled = enabled
+}
+
+// adminPrincipal is the token of the requests authenticated using the
+// admin token of the server
+var adminPrincipal = auth.Token{ID: "admin", Name: "admin", Kind: auth.Admin, Role: auth.RoleAdmin}
+
+// authenticate verifies the credentials of a request against the tokens
+// in the store, the admin token of the server is accepted as well so
+// that the admin routes can be reached when the authentication is enabled.
+// The bearer token or the API key takes precedence over the client
+// certificate so that a client is able to act with another token
+var authenticate server.Authenticator = func(store store.Store, credentials server.Credentials) (auth.Token, error) {
+	if adminToken != "" && subtle.ConstantTimeCompare([]byte(credentials.Token), []byte(adminToken)) == 1 {
+		return adminPrincipal, nil
+	}
+
+	tokens, err := store.Tokens()
+	if err != nil {
+		return auth.Token{}, err
+	}
+	if credentials.Token == "" {
+		return tokens.AuthenticateCertificate(credentials.ClientCertificate, time.Now())
+	}
+	signingKey, err := store.SigningKey()
+	if err != nil {
+		return auth.Token{}, err
+	}
+	return tokens.Authenticate(credentials.Token, signingKey, time.Now())
+}
@@ -0,0 +1,136 @@
+package api_test
+
+import (
+	"errors"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	libKVStore "github.com/kvtools/valkeyrie/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/auth"
+	mockStore "gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/internal/mocks/store"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/store"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/testutils"
+)
+
+// enableAuthentication enables the authentication until the end of the
+// test and returns a BoltDB store in a temp directory to issue the tokens
+func enableAuthentication(t *testing.T) store.Store {
+	api.EnableAuthentication(true)
+	t.Cleanup(func() {
+		api.EnableAuthentication(false)
+	})
+	return newBoltStore(t)
+}
+
+// newBoltStore returns a BoltDB store in a temp directory
+func newBoltStore(t *testing.T) store.Store {
+	kvStore, err := store.NewKVStore(string(libKVStore.BOLTDB), "dwarka", filepath.Join(t.TempDir(), "dwarka.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store.NewPersistentStore("dwarka", kvStore)
+}
+
+func serveWithHeader(t *testing.T, store store.Store, url, header, value string) *http.Response {
+	request, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		t.Error(err)
+	}
+	if header != "" {
+		request.Header.Set(header, value)
+	}
+
+	res, err := testutils.ServeHTTPRequest(store, request)
+	assert.NoError(t, err)
+	return res
+}
+
+func TestAuthentication(t *testing.T) {
+	t.Run("should get 401 without credentials", func(t *testing.T) {
+		persistentStore := enableAuthentication(t)
+
+		for _, url := range []string{"http://test/v1/buildings", "http://test/buildings"} {
+			res := serveWithHeader(t, persistentStore, url, "", "")
+
+			assert.Equal(t, fasthttp.StatusUnauthorized, res.StatusCode)
+			assert.Equal(t, `Bearer realm="dwarka"`, res.Header.Get("WWW-Authenticate"))
+			message, err := testutils.ReadError(res)
+			assert.NoError(t, err)
+			assert.Equal(t, "credentials are missing, use bearer authorization or X-API-Key header", message)
+		}
+	})
+
+	t.Run("should serve public routes without credentials", func(t *testing.T) {
+		persistentStore := enableAuthentication(t)
+		assert.NoError(t, persistentStore.RefreshUptime())
+
+		for _, url := range []string{"http://test/v1/ping", "http://test/ping", "http://test/openapi.json"} {
+			res := serveWithHeader(t, persistentStore, url, "", "")
+
+			assert.Equal(t, fasthttp.StatusOK, res.StatusCode, url)
+		}
+	})
+
+	t.Run("should authenticate api key and jwt", func(t *testing.T) {
+		persistentStore := enableAuthentication(t)
+		signingKey, err := persistentStore.SigningKey()
+		if !assert.NoError(t, err) {
+			return
+		}
+		apiKeyToken, apiKey, _ := auth.NewAPIKey("alice", time.Now(), 0)
+		jwtToken, jwt, _ := auth.NewJWT("bob", time.Now(), time.Hour, signingKey)
+		assert.NoError(t, persistentStore.UpsertToken(apiKeyToken))
+		assert.NoError(t, persistentStore.UpsertToken(jwtToken))
+
+		res := serveWithHeader(t, persistentStore, "http://test/v1/buildings", "X-API-Key", apiKey)
+		assert.Equal(t, fasthttp.StatusOK, res.StatusCode)
+
+		res = serveWithHeader(t, persistentStore, "http://test/v1/buildings", "Authorization", "Bearer "+apiKey)
+		assert.Equal(t, fasthttp.StatusOK, res.StatusCode)
+
+		res = serveWithHeader(t, persistentStore, "http://test/v1/buildings", "Authorization", "Bearer "+jwt)
+		assert.Equal(t, fasthttp.StatusOK, res.StatusCode)
+	})
+
+	t.Run("should get 401 for revoked token", func(t *testing.T) {
+		persistentStore := enableAuthentication(t)
+		token, apiKey, _ := auth.NewAPIKey("alice", time.Now(), 0)
+		assert.NoError(t, persistentStore.UpsertToken(token))
+		assert.NoError(t, persistentStore.DeleteToken(token.ID))
+
+		res := serveWithHeader(t, persistentStore, "http://test/v1/buildings", "X-API-Key", apiKey)
+
+		assert.Equal(t, fasthttp.StatusUnauthorized, res.StatusCode)
+		message, err := testutils.ReadError(res)
+		assert.NoError(t, err)
+		assert.Equal(t, "api key is invalid or revoked", message)
+	})
+
+	t.Run("should authenticate admin token", func(t *testing.T) {
+		persistentStore := enableAuthentication(t)
+		api.SetAdminToken("secret")
+		defer api.SetAdminToken("")
+
+		res := serveWithHeader(t, persistentStore, "http://test/v1/buildings", "Authorization", "Bearer secret")
+
+		assert.Equal(t, fasthttp.StatusOK, res.StatusCode)
+	})
+
+	t.Run("should get 500 when tokens can not be read", func(t *testing.T) {
+		enableAuthentication(t)
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockKVStore := mockStore.NewMockStore(ctrl)
+		mockKVStore.EXPECT().Tokens().Return(nil, errors.New("store not available"))
+
+		res := serveWithHeader(t, mockKVStore, "http://test/v1/buildings", "X-API-Key", "dwk_id_secret")
+
+		assert.Equal(t, fasthttp.StatusInternalServerError, res.StatusCode)
+	})
+}
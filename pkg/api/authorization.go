@@ -0,0 +1,151 @@
+package api
+
+import (
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/server"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/auth"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/store"
+)
+
+// authorized returns the filters of a route which authorize the action
+// before running the given filters e.g. findAndLoadBuilding, the scopes
+// are checked before loading so that the entities outside the scopes
+// are forbidden irrespective of their existence. The actions other than
+// read are audited including the requests the filters respond to
+func authorized(action auth.Action, filters ...server.ResponseHandler) *server.Filters {
+	before := []server.ResponseHandler{authorize(action)}
+	if action == auth.Read {
+		return &server.Filters{Before: append(before, filters...)}
+	}
+	before = append(before, auditResponded(append(filters, snapshotEntity)...)...)
+	return &server.Filters{Before: before, After: []server.ResponseHandler{recordAudit}}
+}
+
+// authorizedInRoom returns the filters of the routes of the entities of a
+// building which may be limited to a room e.g. the scenes, the token is
+// required to read the building of the path and the filters authorize the
+// action on the room of the entity so that a token limited to a room acts
+// on the entities of the room only
+func authorizedInRoom(action auth.Action, filters ...server.ResponseHandler) *server.Filters {
+	before := append([]server.ResponseHandler{authorizeRole(action)}, filters...)
+	if action == auth.Read {
+		return &server.Filters{Before: before}
+	}
+	return &server.Filters{Before: auditResponded(append(before, snapshotEntity)...), After: []server.ResponseHandler{recordAudit}}
+}
+
+// authorizeRole rejects the request with 403 unless the role of its token
+// allows the action and the token is allowed to read the building, floor
+// and room of the path
+func authorizeRole(action auth.Action) server.ResponseHandler {
+	return func(store store.Store, ctx server.RequestContext) error {
+		token, ok := requestToken(ctx)
+		if !ok {
+			return ctx.Next()
+		}
+
+		err := token.AuthorizeRole(action)
+		if err == nil {
+			err = token.Authorize(auth.Read, pathResource(ctx)...)
+		}
+		if err != nil {
+			return forbidden(ctx, err)
+		}
+		return ctx.Next()
+	}
+}
+
+// authorizeOn returns the reason the token of the request is not allowed
+// the action on the resource given as the ids of the building, floor and
+// room, nil when the authentication is disabled
+func authorizeOn(ctx server.RequestContext, action auth.Action, resource ...string) error {
+	token, ok := requestToken(ctx)
+	if !ok {
+		return nil
+	}
+	return token.Authorize(action, resource...)
+}
+
+// authorize rejects the request with 403 unless its token allows the
+// action on the building, floor and room of the path, every request is
+// allowed when the authentication is disabled
+func authorize(action auth.Action) server.ResponseHandler {
+	return func(store store.Store, ctx server.RequestContext) error {
+		token, ok := requestToken(ctx)
+		if !ok {
+			return ctx.Next()
+		}
+
+		err := token.Authorize(action, pathResource(ctx)...)
+		if err != nil {
+			err = forbidden(ctx, err)
+			if action != auth.Read {
+				auditDenied(store, ctx)
+			}
+			return err
+		}
+		return ctx.Next()
+	}
+}
+
+func requestToken(ctx server.RequestContext) (auth.Token, bool) {
+	token, ok := ctx.UserValue(server.TokenKey).(auth.Token)
+	return token, ok
+}
+
+// pathResource returns the ids of the building, floor and room of the path
+func pathResource(ctx server.RequestContext) []string {
+	var resource []string
+	for _, key := range []string{buildingID, floorID, roomID} {
+		id, ok := ctx.UserValue(key).(string)
+		if !ok || id == "" {
+			break
+		}
+		resource = append(resource, id)
+	}
+	return resource
+}
+
+// visible returns true when the token of the request is allowed to read
+// the resource given as the ids of the building, floor and room
+func visible(ctx server.RequestContext, resource ...string) bool {
+	token, ok := requestToken(ctx)
+	return !ok || token.Visible(resource...)
+}
+
+// visibleTree returns the tree without the entities the token of the
+// request is not allowed to read
+func visibleTree(ctx server.RequestContext, tree gateway.Tree) gateway.Tree {
+	if _, ok := requestToken(ctx); !ok {
+		return tree
+	}
+
+	result := gateway.NewTree()
+	for id, building := range tree.Buildings {
+		if !visible(ctx, id) {
+			continue
+		}
+		result.Buildings[id] = building
+
+		floors := gateway.Floors{}
+		for floorID, floor := range tree.FloorsOf(building) {
+			if !visible(ctx, id, floorID) {
+				continue
+			}
+			floors[floorID] = floor
+
+			rooms := gateway.Rooms{}
+			for roomID, room := range tree.RoomsOf(floor) {
+				if !visible(ctx, id, floorID, roomID) {
+					continue
+				}
+				rooms[roomID] = room
+				result.AddDevices(room, tree.DevicesOf(room))
+				result.AddNodes(room, tree.NodesOf(room))
+			}
+			result.AddRooms(floor, rooms)
+		}
+		result.AddFloors(building, floors)
+	}
+	return result
+}
@@ -0,0 +1,225 @@
+package api_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/view"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/auth"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/store"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/testutils"
+)
+
+// policies lists the least role allowed for every route, the roles are
+// ordered from the least to the most privileged
+var policies = map[string]auth.Role{
+	"GET /buildings":                      auth.RoleViewer,
+	"POST /buildings":                     auth.RoleEditor,
+	"GET /buildings/{building-id}":        auth.RoleViewer,
+	"PUT /buildings/{building-id}":        auth.RoleEditor,
+	"DELETE /buildings/{building-id}":     auth.RoleEditor,
+	"GET /buildings/{building-id}/sun":    auth.RoleViewer,
+	"GET /buildings/{building-id}/energy": auth.RoleViewer,
+
+	"GET /buildings/{building-id}/floors":               auth.RoleViewer,
+	"POST /buildings/{building-id}/floors":              auth.RoleEditor,
+	"GET /buildings/{building-id}/floors/{floor-id}":    auth.RoleViewer,
+	"PUT /buildings/{building-id}/floors/{floor-id}":    auth.RoleEditor,
+	"DELETE /buildings/{building-id}/floors/{floor-id}": auth.RoleEditor,
+
+	"GET /buildings/{building-id}/floors/{floor-id}/rooms":              auth.RoleViewer,
+	"POST /buildings/{building-id}/floors/{floor-id}/rooms":             auth.RoleEditor,
+	"GET /buildings/{building-id}/floors/{floor-id}/rooms/{room-id}":    auth.RoleViewer,
+	"PUT /buildings/{building-id}/floors/{floor-id}/rooms/{room-id}":    auth.RoleEditor,
+	"DELETE /buildings/{building-id}/floors/{floor-id}/rooms/{room-id}": auth.RoleEditor,
+
+	"GET /buildings/{building-id}/floors/{floor-id}/rooms/{room-id}/devices":                              auth.RoleViewer,
+	"POST /buildings/{building-id}/floors/{floor-id}/rooms/{room-id}/devices":                             auth.RoleEditor,
+	"GET /buildings/{building-id}/floors/{floor-id}/rooms/{room-id}/devices/{device-id}":                  auth.RoleViewer,
+	"PUT /buildings/{building-id}/floors/{floor-id}/rooms/{room-id}/devices/{device-id}":                  auth.RoleEditor,
+	"DELETE /buildings/{building-id}/floors/{floor-id}/rooms/{room-id}/devices/{device-id}":               auth.RoleEditor,
+	"GET /buildings/{building-id}/floors/{floor-id}/rooms/{room-id}/devices/{device-id}/metrics":          auth.RoleViewer,
+	"GET /buildings/{building-id}/floors/{floor-id}/rooms/{room-id}/devices/{device-id}/metrics/{metric}": auth.RoleViewer,
+	"POST /buildings/{building-id}/floors/{floor-id}/rooms/{room-id}/devices/{device-id}/on":              auth.RoleOperator,
+	"POST /buildings/{building-id}/floors/{floor-id}/rooms/{room-id}/devices/{device-id}/off":             auth.RoleOperator,
+
+	"GET /buildings/{building-id}/scenes":                      auth.RoleViewer,
+	"POST /buildings/{building-id}/scenes":                     auth.RoleEditor,
+	"POST /buildings/{building-id}/scenes/capture":             auth.RoleEditor,
+	"GET /buildings/{building-id}/scenes/{scene-id}":           auth.RoleViewer,
+	"PUT /buildings/{building-id}/scenes/{scene-id}":           auth.RoleEditor,
+	"DELETE /buildings/{building-id}/scenes/{scene-id}":        auth.RoleEditor,
+	"POST /buildings/{building-id}/scenes/{scene-id}/activate": auth.RoleOperator,
+
+	"GET /buildings/{building-id}/automations":                    auth.RoleViewer,
+	"POST /buildings/{building-id}/automations":                   auth.RoleEditor,
+	"GET /buildings/{building-id}/automations/{automation-id}":    auth.RoleViewer,
+	"PUT /buildings/{building-id}/automations/{automation-id}":    auth.RoleEditor,
+	"DELETE /buildings/{building-id}/automations/{automation-id}": auth.RoleEditor,
+	"POST /buildings/{building-id}/events":                        auth.RoleOperator,
+
+	"GET /buildings/{building-id}/schedules":                         auth.RoleViewer,
+	"POST /buildings/{building-id}/schedules":                        auth.RoleEditor,
+	"POST /buildings/{building-id}/schedules/next-runs":              auth.RoleViewer,
+	"GET /buildings/{building-id}/schedules/{schedule-id}":           auth.RoleViewer,
+	"PUT /buildings/{building-id}/schedules/{schedule-id}":           auth.RoleEditor,
+	"DELETE /buildings/{building-id}/schedules/{schedule-id}":        auth.RoleEditor,
+	"GET /buildings/{building-id}/schedules/{schedule-id}/next-runs": auth.RoleViewer,
+
+	"GET /buildings/{building-id}/groups":                    auth.RoleViewer,
+	"POST /buildings/{building-id}/groups":                   auth.RoleEditor,
+	"GET /buildings/{building-id}/groups/{group-id}":         auth.RoleViewer,
+	"PUT /buildings/{building-id}/groups/{group-id}":         auth.RoleEditor,
+	"DELETE /buildings/{building-id}/groups/{group-id}":      auth.RoleEditor,
+	"GET /buildings/{building-id}/groups/{group-id}/members": auth.RoleViewer,
+	"POST /buildings/{building-id}/groups/{group-id}/on":     auth.RoleOperator,
+	"POST /buildings/{building-id}/groups/{group-id}/off":    auth.RoleOperator,
+
+	"GET /tree":                         auth.RoleViewer,
+	"GET /search":                       auth.RoleViewer,
+	"GET /buildings/{building-id}/tree": auth.RoleViewer,
+	"POST /import":                      auth.RoleEditor,
+	"GET /export":                       auth.RoleViewer,
+
+	"GET /admin/backups":                      auth.RoleAdmin,
+	"POST /admin/backups":                     auth.RoleAdmin,
+	"POST /admin/backups/{backup-id}/restore": auth.RoleAdmin,
+	"GET /audit":                              auth.RoleAdmin,
+}
+
+var roles = []auth.Role{auth.RoleViewer, auth.RoleOperator, auth.RoleEditor, auth.RoleAdmin}
+
+// seedStore persists building-one/floor-one/room-one/porch-light along
+// with building-two
+func seedStore(t *testing.T, persistentStore store.Store) {
+	device := testutils.NewDevice("porch-light")
+	room := device.Room
+	floor := room.Floor
+	assert.NoError(t, persistentStore.UpsertBuilding(floor.Building.(gateway.Building)))
+	assert.NoError(t, persistentStore.UpsertBuilding(testutils.NewBuilding("building-two")))
+	assert.NoError(t, persistentStore.UpsertFloor(floor))
+	assert.NoError(t, persistentStore.UpsertRoom(room))
+	assert.NoError(t, persistentStore.UpsertDevice(device))
+}
+
+func issueToken(t *testing.T, persistentStore store.Store, role auth.Role, scopes ...auth.Scope) string {
+	token, apiKey, err := auth.NewAPIKey(string(role), time.Now(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token.Role = role
+	token.Scopes = scopes
+	assert.NoError(t, persistentStore.UpsertToken(token))
+	return apiKey
+}
+
+func serveAs(t *testing.T, persistentStore store.Store, apiKey, method, url string) *http.Response {
+	request, err := http.NewRequest(method, "http://test/v1"+url, nil)
+	if err != nil {
+		t.Error(err)
+	}
+	request.Header.Set("X-API-Key", apiKey)
+
+	res, err := testutils.ServeHTTPRequest(persistentStore, request)
+	assert.NoError(t, err)
+	return res
+}
+
+func TestAuthorization(t *testing.T) {
+	t.Run("should have a policy for every route", func(t *testing.T) {
+		for _, route := range api.Routes(api.V1) {
+			if route.IsPublic() {
+				continue
+			}
+			assert.Contains(t, policies, route.Method()+" "+route.URL())
+		}
+	})
+
+	t.Run("should allow the roles of the policy", func(t *testing.T) {
+		persistentStore := enableAuthentication(t)
+		seedStore(t, persistentStore)
+		apiKeys := map[auth.Role]string{}
+		for _, role := range roles {
+			apiKeys[role] = issueToken(t, persistentStore, role)
+		}
+
+		for route, least := range policies {
+			parts := strings.SplitN(route, " ", 2)
+			method := parts[0]
+			url := strings.NewReplacer("{building-id}", "building-one", "{floor-id}", "floor-one",
+				"{room-id}", "room-one", "{device-id}", "porch-light", "{scene-id}", "movie-mode", "{automation-id}", "porch-at-dusk", "{schedule-id}", "porch-on-weekdays", "{group-id}", "exterior-lights", "{metric}", "temperature", "{backup-id}", "20261019T030000Z").Replace(parts[1])
+
+			allowed := false
+			for _, role := range roles {
+				allowed = allowed || role == least
+				// the entities might be deleted by the routes served earlier,
+				// any status except 403 tells that the role is allowed
+				res := serveAs(t, persistentStore, apiKeys[role], method, url)
+
+				if allowed {
+					assert.NotEqual(t, fasthttp.StatusForbidden, res.StatusCode, "%s should be allowed for %s", route, role)
+				} else {
+					assert.Equal(t, fasthttp.StatusForbidden, res.StatusCode, "%s should be forbidden for %s", route, role)
+				}
+			}
+		}
+	})
+
+	t.Run("should limit the scoped tokens to their buildings", func(t *testing.T) {
+		persistentStore := enableAuthentication(t)
+		seedStore(t, persistentStore)
+		apiKey := issueToken(t, persistentStore, auth.RoleEditor, "building-two")
+
+		res := serveAs(t, persistentStore, apiKey, "GET", "/buildings")
+		var buildings []view.Building
+		if assert.NoError(t, testutils.Read(res, &buildings)) && assert.Len(t, buildings, 1) {
+			assert.Equal(t, "building-two", buildings[0].ID)
+		}
+
+		res = serveAs(t, persistentStore, apiKey, "GET", "/buildings/building-one")
+		assert.Equal(t, fasthttp.StatusForbidden, res.StatusCode)
+		message, err := testutils.ReadError(res)
+		assert.NoError(t, err)
+		assert.Equal(t, "token is limited to building-two", message)
+
+		res = serveAs(t, persistentStore, apiKey, "GET", "/buildings/building-three")
+		assert.Equal(t, fasthttp.StatusForbidden, res.StatusCode)
+
+		res = serveAs(t, persistentStore, apiKey, "POST", "/buildings")
+		assert.Equal(t, fasthttp.StatusForbidden, res.StatusCode)
+
+		res = serveAs(t, persistentStore, apiKey, "GET", "/tree")
+		var tree []view.BuildingTree
+		if assert.NoError(t, testutils.Read(res, &tree)) && assert.Len(t, tree, 1) {
+			assert.Equal(t, "building-two", tree[0].ID)
+		}
+	})
+
+	t.Run("should show the enclosing entities of the scoped rooms", func(t *testing.T) {
+		persistentStore := enableAuthentication(t)
+		seedStore(t, persistentStore)
+		floor := testutils.NewFloor("floor-one")
+		assert.NoError(t, persistentStore.UpsertRoom(gateway.Room{Floor: floor, PhysicalEntity: gateway.PhysicalEntity{Name: "room-two"}}))
+		apiKey := issueToken(t, persistentStore, auth.RoleOperator, "building-one/floor-one/room-one")
+
+		res := serveAs(t, persistentStore, apiKey, "GET", "/buildings/building-one/floors/floor-one")
+		assert.Equal(t, fasthttp.StatusOK, res.StatusCode)
+
+		res = serveAs(t, persistentStore, apiKey, "GET", "/buildings/building-one/floors/floor-one/rooms")
+		var rooms []view.Room
+		if assert.NoError(t, testutils.Read(res, &rooms)) && assert.Len(t, rooms, 1) {
+			assert.Equal(t, "room-one", rooms[0].ID)
+		}
+
+		res = serveAs(t, persistentStore, apiKey, "DELETE", "/buildings/building-one/floors/floor-one")
+		assert.Equal(t, fasthttp.StatusForbidden, res.StatusCode)
+		res = serveAs(t, persistentStore, apiKey, "GET", "/buildings/building-one/floors/floor-one/rooms/room-two")
+		assert.Equal(t, fasthttp.StatusForbidden, res.StatusCode)
+	})
+}
@@ -0,0 +1,262 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"time"
+
+	"github.com/valyala/fasthttp"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/server"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/view"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/auth"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/automation"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/store"
+)
+
+const (
+	automationID      = "automation-id"
+	automationUserKey = "automation"
+)
+
+var engine *automation.Engine
+
+// EnableAutomation publishes the state of the switched devices and the
+// events posted to the buildings to the engine for the servers created
+// afterwards
+func EnableAutomation(automationEngine *automation.Engine) {
+	engine = automationEngine
+}
+
+func automationPath() string {
+	return path.Join(automationsBasePath(), fmt.Sprintf("{%s}", automationID))
+}
+
+func automationsBasePath() string {
+	return path.Join(buildingPath(), "automations")
+}
+
+func init() {
+	tags := []string{"automations"}
+	AddRoute(
+		server.NewRouteWithFilters("GET", automationsBasePath(), listAutomationsHandler, authorized(auth.Read, findAndLoadBuilding)).Describe(server.Documentation{
+			Summary: "List automations of the building", Tags: tags, Query: selectorQuery, Response: []view.Automation{},
+			Errors: []int{fasthttp.StatusBadRequest},
+		}),
+		server.NewRouteWithFilters("POST", automationsBasePath(), createAutomationHandler, authorized(auth.Write, findAndLoadBuilding)).Describe(server.Documentation{
+			Summary: "Create automation in the building", Tags: tags, Request: view.Automation{}, Response: map[string]string{},
+			Status: fasthttp.StatusCreated, Errors: []int{fasthttp.StatusConflict},
+		}),
+		server.NewRouteWithFilters("GET", automationPath(), getAutomationHandler, authorized(auth.Read, findAndLoadAutomation)).Describe(server.Documentation{
+			Summary: "Get automation", Tags: tags, Response: view.Automation{},
+		}),
+		server.NewRouteWithFilters("PUT", automationPath(), updateAutomationHandler, authorized(auth.Write, findAndLoadAutomation)).Describe(server.Documentation{
+			Summary: "Update automation", Tags: tags, Request: view.Automation{},
+		}),
+		server.NewRouteWithFilters("DELETE", automationPath(), deleteAutomationHandler, authorized(auth.Write, findAndLoadAutomation)).Describe(server.Documentation{
+			Summary: "Delete automation", Tags: tags,
+		}),
+		// the events are not audited as the sensors report them continuously
+		server.NewRouteWithFilters("POST", path.Join(buildingPath(), "events"), postEventHandler, &server.Filters{Before: []server.ResponseHandler{authorize(auth.Control), findAndLoadBuilding}}).Describe(server.Documentation{
+			Summary: "Report a device state, a room occupancy or an mqtt message to the automations, the readings on the tele/ topics are stored as telemetry", Tags: tags, Request: automation.Event{},
+			Status: fasthttp.StatusAccepted, Errors: []int{fasthttp.StatusServiceUnavailable},
+		}),
+	)
+}
+
+var findAndLoadAutomation = func(kvStore store.Store, ctx server.RequestContext) error {
+	err := loadBuildingsAndBuildingFromContext(kvStore, ctx)
+	if err != nil {
+		switch err.(type) {
+		case store.NotFound:
+			return notFound(ctx)
+		default:
+			return internalServerError(ctx, err)
+		}
+	}
+
+	building := ctx.UserValue(buildingUserKey).(gateway.Building)
+	automations, err := kvStore.Automations(building)
+	if err != nil {
+		return internalServerError(ctx, err)
+	}
+
+	id, _ := ctx.UserValue(automationID).(string)
+	automation, ok := automations[id]
+	if !ok {
+		return notFound(ctx)
+	}
+	ctx.SetUserValue(automationUserKey, automation)
+	setAuditEntity(ctx, automationEntity(automation), view.NewAutomation(automation))
+	return ctx.Next()
+}
+
+// automationEntity returns the entity of the automation recorded by the audit
+func automationEntity(automation gateway.Automation) string {
+	return path.Join(automation.Building.ID(), "automations", automation.ID())
+}
+
+var listAutomationsHandler = func(store store.Store, ctx server.RequestContext) error {
+	building, ok := ctx.UserValue(buildingUserKey).(gateway.Building)
+	if !ok {
+		return notFound(ctx)
+	}
+
+	selector, err := labelSelector(ctx)
+	if err != nil {
+		return badRequest(ctx, err)
+	}
+
+	automations, err := store.Automations(building)
+	if err != nil {
+		return internalServerError(ctx, err)
+	}
+
+	for id, automation := range automations {
+		if !selector.Matches(automation.Labels) {
+			delete(automations, id)
+		}
+	}
+	return ctx.JSONResponse(view.NewAutomations(automations), http.StatusOK)
+}
+
+var createAutomationHandler = func(store store.Store, ctx server.RequestContext) error {
+	building, ok := ctx.UserValue(buildingUserKey).(gateway.Building)
+	if !ok {
+		return notFound(ctx)
+	}
+
+	automation, err := gateway.NewAutomation(building, ctx.PostBody())
+	if err != nil {
+		return badRequest(ctx, err)
+	}
+
+	automations, err := store.Automations(building)
+	if err != nil {
+		return internalServerError(ctx, err)
+	}
+
+	setAuditEntity(ctx, automationEntity(automation), nil)
+	if _, ok := automations[automation.ID()]; ok {
+		return conflict(ctx)
+	}
+
+	automations[automation.ID()] = automation
+	err = store.UpsertAutomations(building, automations)
+	if err != nil {
+		return internalServerError(ctx, err)
+	}
+	ctx.SetUserValue(auditAfterKey, view.NewAutomation(automation))
+	return created(ctx, automation.ID())
+}
+
+var getAutomationHandler = func(store store.Store, ctx server.RequestContext) error {
+	automation, ok := ctx.UserValue(automationUserKey).(gateway.Automation)
+	if !ok {
+		return notFound(ctx)
+	}
+
+	return ctx.JSONResponse(view.NewAutomation(automation), fasthttp.StatusOK)
+}
+
+var updateAutomationHandler = func(store store.Store, ctx server.RequestContext) error {
+	building, ok := ctx.UserValue(buildingUserKey).(gateway.Building)
+	if !ok {
+		return notFound(ctx)
+	}
+
+	automation, err := gateway.NewAutomation(building, ctx.PostBody())
+	if err != nil {
+		return badRequest(ctx, err)
+	}
+
+	err = store.UpsertAutomation(automation)
+	if err != nil {
+		return internalServerError(ctx, err)
+	}
+	ctx.SetUserValue(auditAfterKey, view.NewAutomation(automation))
+	return nil
+}
+
+var deleteAutomationHandler = func(store store.Store, ctx server.RequestContext) error {
+	automation, ok := ctx.UserValue(automationUserKey).(gateway.Automation)
+	if !ok {
+		return notFound(ctx)
+	}
+
+	err := store.DeleteAutomation(automation)
+	if err != nil {
+		return internalServerError(ctx, err)
+	}
+	return nil
+}
+
+var postEventHandler = func(store store.Store, ctx server.RequestContext) error {
+	building, ok := ctx.UserValue(buildingUserKey).(gateway.Building)
+	if !ok {
+		return notFound(ctx)
+	}
+	if engine == nil && telemetryDB == nil {
+		return ctx.JSONResponse(map[string]string{"error": "automations are disabled"}, fasthttp.StatusServiceUnavailable)
+	}
+
+	event := automation.Event{}
+	err := json.Unmarshal(ctx.PostBody(), &event)
+	if err != nil {
+		return badRequest(ctx, err)
+	}
+	event.Building = building.ID()
+	err = event.Validate()
+	if err != nil {
+		return badRequest(ctx, err)
+	}
+
+	switch event.Kind {
+	case automation.EventMQTT:
+		bridgesSeen.mark(building.ID(), time.Now())
+	case automation.EventState:
+		devicesSeen.mark(path.Join(building.ID(), event.Path), time.Now())
+	}
+
+	// the readings are stored before the automations see the message
+	readings, err := readingsOf(event)
+	if err != nil {
+		return badRequest(ctx, err)
+	}
+	err = appendReadings(readings)
+	if err != nil {
+		return internalServerError(ctx, err)
+	}
+	ingested := len(readings) > 0
+
+	if engine == nil {
+		if ingested {
+			return ctx.JSONResponse(map[string]string{}, fasthttp.StatusAccepted)
+		}
+		return ctx.JSONResponse(map[string]string{"error": "automations are disabled"}, fasthttp.StatusServiceUnavailable)
+	}
+	// the readings stored are accepted even when the automations drop the
+	// message as a retry would add them to the rollups again
+	if !engine.Publish(event) && !ingested {
+		return ctx.JSONResponse(map[string]string{"error": "automation queue is full, retry later"}, fasthttp.StatusServiceUnavailable)
+	}
+	return ctx.JSONResponse(map[string]string{}, fasthttp.StatusAccepted)
+}
+
+// publishState reports the state the device was switched to the engine,
+// the device is online as it was switched
+func publishState(device gateway.Device, state gateway.State) {
+	seenDevice(device)
+	floor := device.Room.Floor
+	if engine == nil || floor.Building == nil {
+		return
+	}
+	engine.Publish(automation.Event{
+		Kind:     automation.EventState,
+		Building: floor.Building.ID(),
+		Path:     path.Join(floor.ID(), device.Room.ID(), device.ID()),
+		State:    string(state),
+	})
+}
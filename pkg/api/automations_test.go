@@ -0,0 +1,110 @@
+package api_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/view"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/auth"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/automation"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/testutils"
+)
+
+const automationsURL = "/buildings/building-one/automations"
+
+func TestAutomations(t *testing.T) {
+	lampFollowsDoor := `{"name":"lamp follows door",
+		"triggers":[{"type":"state","device":"floor-one/room-one/front-door","to":"open"}],
+		"actions":[{"type":"device","device":"floor-one/room-one/broken-lamp","state":"on"}]}`
+
+	t.Run("should create, list, update and delete the automations", func(t *testing.T) {
+		persistentStore := enableAuthentication(t)
+		seedScenes(t, persistentStore)
+		editor := issueToken(t, persistentStore, auth.RoleEditor)
+
+		res := serveBodyAs(t, persistentStore, editor, "POST", automationsURL, lampFollowsDoor)
+		assert.Equal(t, fasthttp.StatusCreated, res.StatusCode)
+		res = serveBodyAs(t, persistentStore, editor, "POST", automationsURL, lampFollowsDoor)
+		assert.Equal(t, fasthttp.StatusConflict, res.StatusCode)
+
+		res = serveAs(t, persistentStore, editor, "GET", automationsURL)
+		var automations []view.Automation
+		if assert.NoError(t, testutils.Read(res, &automations)) && assert.Len(t, automations, 1) {
+			assert.Equal(t, "lamp-follows-door", automations[0].ID)
+			assert.Equal(t, "building-one", automations[0].Building)
+			assert.Equal(t, []gateway.Trigger{{Type: gateway.TriggerState, Device: "floor-one/room-one/front-door", To: "open"}}, automations[0].Triggers)
+		}
+
+		res = serveBodyAs(t, persistentStore, editor, "PUT", automationsURL+"/lamp-follows-door", `{"name":"lamp follows door","disabled":true,
+			"triggers":[{"type":"time","at":"18:30"}],"actions":[{"type":"scene","scene":"movie-mode"}]}`)
+		assert.Equal(t, fasthttp.StatusOK, res.StatusCode)
+		res = serveAs(t, persistentStore, editor, "GET", automationsURL+"/lamp-follows-door")
+		automation := view.Automation{}
+		if assert.NoError(t, testutils.Read(res, &automation)) {
+			assert.True(t, automation.Disabled)
+			assert.Equal(t, "18:30", automation.Triggers[0].At)
+		}
+
+		res = serveAs(t, persistentStore, editor, "DELETE", automationsURL+"/lamp-follows-door")
+		assert.Equal(t, fasthttp.StatusOK, res.StatusCode)
+		res = serveAs(t, persistentStore, editor, "GET", automationsURL+"/lamp-follows-door")
+		assert.Equal(t, fasthttp.StatusNotFound, res.StatusCode)
+	})
+
+	t.Run("should reject invalid automation", func(t *testing.T) {
+		persistentStore := enableAuthentication(t)
+		seedStore(t, persistentStore)
+		editor := issueToken(t, persistentStore, auth.RoleEditor)
+
+		for body, message := range map[string]string{
+			`{"name":"porch at dusk","triggers":[{"type":"sun","event":"noon"}],"actions":[{"type":"scene","scene":"movie-mode"}]}`:  "trigger 1: moment noon should be dawn, sunrise, sunset or dusk optionally followed by an offset e.g. sunset-15m",
+			`{"name":"porch at dusk","triggers":[{"type":"time","at":"7pm"}],"actions":[{"type":"scene","scene":"movie-mode"}]}`:     "trigger 1: time 7pm should be given as hh:mm e.g. 18:30 or as a solar moment e.g. sunset-15m",
+			`{"name":"porch at dusk","triggers":[{"type":"time","at":"19:00"}],"actions":[{"type":"webhook","url":"/hooks/porch"}]}`: "action 1: url /hooks/porch should be an absolute http or https url",
+		} {
+			res := serveBodyAs(t, persistentStore, editor, "POST", automationsURL, body)
+
+			assert.Equal(t, fasthttp.StatusBadRequest, res.StatusCode)
+			actual, err := testutils.ReadError(res)
+			assert.NoError(t, err)
+			assert.Equal(t, message, actual)
+		}
+	})
+
+	t.Run("should run the automations triggered by the events", func(t *testing.T) {
+		persistentStore := enableAuthentication(t)
+		node := seedScenes(t, persistentStore)
+		editor := issueToken(t, persistentStore, auth.RoleEditor)
+		operator := issueToken(t, persistentStore, auth.RoleOperator)
+		serveBodyAs(t, persistentStore, editor, "POST", automationsURL, lampFollowsDoor)
+		serveBodyAs(t, persistentStore, editor, "POST", automationsURL, `{"name":"light follows lamp",
+			"triggers":[{"type":"state","device":"floor-one/room-one/porch-light","to":"on"}],
+			"actions":[{"type":"device","device":"floor-one/room-one/broken-lamp","state":"off"}]}`)
+
+		res := serveBodyAs(t, persistentStore, operator, "POST", "/buildings/building-one/events", `{"kind":"state","path":"floor-one/room-one/front-door","state":"open"}`)
+		assert.Equal(t, fasthttp.StatusServiceUnavailable, res.StatusCode)
+
+		engine := automation.NewEngine(persistentStore, automation.NewManualClock(time.Now()))
+		api.EnableAutomation(engine)
+		stop := make(chan struct{})
+		t.Cleanup(func() {
+			close(stop)
+			api.EnableAutomation(nil)
+		})
+		go engine.Run(stop)
+
+		res = serveBodyAs(t, persistentStore, operator, "POST", "/buildings/building-one/events", `{"kind":"state","path":"front-door","state":"open"}`)
+		assert.Equal(t, fasthttp.StatusBadRequest, res.StatusCode)
+		res = serveBodyAs(t, persistentStore, operator, "POST", "/buildings/building-one/events", `{"kind":"state","path":"floor-one/room-one/front-door","state":"open"}`)
+		assert.Equal(t, fasthttp.StatusAccepted, res.StatusCode)
+		assert.Eventually(t, func() bool { return len(node.Switched()) == 1 }, time.Second, 10*time.Millisecond)
+
+		res = serveAs(t, persistentStore, operator, "POST", "/buildings/building-one/floors/floor-one/rooms/room-one/devices/porch-light/on")
+		assert.Equal(t, fasthttp.StatusOK, res.StatusCode)
+		assert.Eventually(t, func() bool { return len(node.Switched()) == 3 }, time.Second, 10*time.Millisecond)
+		assert.Equal(t, []string{"on:broken-lamp", "on:porch-light", "off:broken-lamp"}, node.Switched())
+	})
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"path"
+
+	"github.com/valyala/fasthttp"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/server"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/backup"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/store"
+)
+
+const backupID = "backup-id"
+
+var backups *backup.Backups
+
+func backupsBasePath() string {
+	return path.Join(adminBasePath, "backups")
+}
+
+func backupPath() string {
+	return path.Join(backupsBasePath(), fmt.Sprintf("{%s}", backupID))
+}
+
+// EnableBackups serves the backups through the admin routes, the
+// routes respond with 501 until the backups are enabled
+func EnableBackups(enabled *backup.Backups) {
+	backups = enabled
+}
+
+func init() {
+	adminFilters := &server.Filters{Before: auditResponded(requireAdmin, backupsEnabled), After: []server.ResponseHandler{recordAudit}}
+	tags := []string{"admin"}
+	adminErrors := []int{fasthttp.StatusUnauthorized, fasthttp.StatusForbidden, fasthttp.StatusNotImplemented}
+	AddRoute(
+		server.NewRouteWithFilters("GET", backupsBasePath(), listBackupsHandler, adminFilters).Describe(server.Documentation{
+			Summary: "List backups of the store, the latest first", Tags: tags, Response: []backup.Backup{}, Errors: adminErrors,
+		}),
+		server.NewRouteWithFilters("POST", backupsBasePath(), createBackupHandler, adminFilters).Describe(server.Documentation{
+			Summary: "Backup the store", Tags: tags, Response: backup.Backup{},
+			Status: fasthttp.StatusCreated, Errors: adminErrors,
+		}),
+		server.NewRouteWithFilters("POST", path.Join(backupPath(), "restore"), restoreBackupHandler, adminFilters).Describe(server.Documentation{
+			Summary: "Replace the store with the backup after verifying its checksum", Tags: tags, Response: backup.Backup{},
+			Errors: append(adminErrors, fasthttp.StatusNotFound),
+		}),
+	)
+}
+
+var backupsEnabled = func(store store.Store, ctx server.RequestContext) error {
+	if backups == nil {
+		err := errors.New("backups are not enabled, start the server with a backup directory")
+		return ctx.JSONResponse(map[string]string{"error": err.Error()}, fasthttp.StatusNotImplemented)
+	}
+	return ctx.Next()
+}
+
+var listBackupsHandler = func(store store.Store, ctx server.RequestContext) error {
+	list, err := backups.List()
+	if err != nil {
+		return internalServerError(ctx, err)
+	}
+	return ctx.JSONResponse(list, fasthttp.StatusOK)
+}
+
+var createBackupHandler = func(store store.Store, ctx server.RequestContext) error {
+	created, err := backups.Create()
+	if err != nil {
+		return internalServerError(ctx, err)
+	}
+	return ctx.JSONResponse(created, fasthttp.StatusCreated)
+}
+
+var restoreBackupHandler = func(store store.Store, ctx server.RequestContext) error {
+	id, _ := ctx.UserValue(backupID).(string)
+	restored, err := backups.Restore(id)
+	if err != nil {
+		switch err.(type) {
+		case backup.NotFound:
+			return notFound(ctx)
+		default:
+			return internalServerError(ctx, err)
+		}
+	}
+	store.Reindex()
+	return ctx.JSONResponse(restored, fasthttp.StatusOK)
+}
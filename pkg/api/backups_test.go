@@ -0,0 +1,169 @@
+package api_test
+
+import (
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	libKVStore "github.com/kvtools/valkeyrie/store"
+	"github.com/kvtools/valkeyrie/store/boltdb"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/audit"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/backup"
+	mockStore "gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/internal/mocks/store"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/store"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/testutils"
+)
+
+const backupsURL = "http://test/v1/admin/backups"
+
+func init() {
+	boltdb.Register()
+}
+
+// enableBackups enables the backups of a BoltDB store in a temp directory
+// and the admin token until the end of the test
+func enableBackups(t *testing.T) (*backup.Backups, store.Store) {
+	dir := t.TempDir()
+	kvStore, err := store.NewKVStore(string(libKVStore.BOLTDB), "dwarka", filepath.Join(dir, "dwarka.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	backups, err := backup.NewBackups(filepath.Join(dir, "backups"), kvStore, "dwarka", backup.Retention{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	api.EnableBackups(backups)
+	api.SetAdminToken("secret")
+	t.Cleanup(func() {
+		api.EnableBackups(nil)
+		api.SetAdminToken("")
+	})
+	return backups, store.NewPersistentStore("dwarka", kvStore)
+}
+
+func serveAdmin(t *testing.T, method, url, token string) *http.Response {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	request, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		t.Error(err)
+	}
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	mockKVStore := mockStore.NewMockStore(ctrl)
+	mockKVStore.EXPECT().Reindex().AnyTimes()
+
+	res, err := testutils.ServeHTTPRequest(mockKVStore, request)
+	assert.NoError(t, err)
+	return res
+}
+
+func TestBackups(t *testing.T) {
+	t.Run("test GET /admin/backups", func(t *testing.T) {
+		t.Run("should list the backups", func(t *testing.T) {
+			backups, _ := enableBackups(t)
+			created, err := backups.Create()
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			res := serveAdmin(t, "GET", backupsURL, "secret")
+			assert.Equal(t, fasthttp.StatusOK, res.StatusCode)
+
+			var actual []backup.Backup
+			err = testutils.Read(res, &actual)
+			if assert.NoError(t, err) && assert.Len(t, actual, 1) {
+				assert.Equal(t, created.ID, actual[0].ID)
+				assert.Equal(t, created.Checksum, actual[0].Checksum)
+			}
+		})
+
+		t.Run("should get 401 for invalid token", func(t *testing.T) {
+			enableBackups(t)
+
+			res := serveAdmin(t, "GET", backupsURL, "guess")
+			assert.Equal(t, fasthttp.StatusUnauthorized, res.StatusCode)
+
+			res = serveAdmin(t, "GET", backupsURL, "")
+			assert.Equal(t, fasthttp.StatusUnauthorized, res.StatusCode)
+		})
+
+		t.Run("should get 403 when admin token is not configured", func(t *testing.T) {
+			res := serveAdmin(t, "GET", backupsURL, "secret")
+			assert.Equal(t, fasthttp.StatusForbidden, res.StatusCode)
+		})
+
+		t.Run("should get 501 when backups are not enabled", func(t *testing.T) {
+			api.SetAdminToken("secret")
+			defer api.SetAdminToken("")
+
+			res := serveAdmin(t, "GET", backupsURL, "secret")
+			assert.Equal(t, fasthttp.StatusNotImplemented, res.StatusCode)
+		})
+	})
+
+	t.Run("test POST /admin/backups/:backup-id/restore", func(t *testing.T) {
+		t.Run("should restore the backup", func(t *testing.T) {
+			backups, persistentStore := enableBackups(t)
+			buildings, building := testutils.NewBuildings("building-one")
+			assert.NoError(t, persistentStore.UpsertBuildings(buildings))
+			created, err := backups.Create()
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.NoError(t, persistentStore.DeleteBuilding(building))
+
+			res := serveAdmin(t, "POST", backupsURL+"/"+created.ID+"/restore", "secret")
+			assert.Equal(t, fasthttp.StatusOK, res.StatusCode)
+
+			actual, err := persistentStore.Buildings()
+			if assert.NoError(t, err) {
+				assert.Equal(t, buildings, actual)
+			}
+		})
+
+		t.Run("should keep the audit stream and record the restore", func(t *testing.T) {
+			backups, persistentStore := enableBackups(t)
+			api.EnableAudit(true)
+			defer api.EnableAudit(false)
+			created, err := backups.Create()
+			if !assert.NoError(t, err) {
+				return
+			}
+			deletion, _ := audit.NewEntry(time.Now(), audit.Actor{Name: "editor"}, "DELETE", "/v1/buildings/home/floors/terrace", "home/terrace", fasthttp.StatusOK)
+			assert.NoError(t, persistentStore.AppendAudit(deletion))
+
+			request, _ := http.NewRequest("POST", backupsURL+"/"+created.ID+"/restore", nil)
+			request.Header.Set("Authorization", "Bearer secret")
+			res, err := testutils.ServeHTTPRequest(persistentStore, request)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, fasthttp.StatusOK, res.StatusCode)
+
+			entries, err := persistentStore.Audit(audit.Query{})
+			if assert.NoError(t, err) && assert.Len(t, entries, 2) {
+				assert.Equal(t, deletion.ID, entries[0].ID)
+				assert.Equal(t, "/v1/admin/backups/"+created.ID+"/restore", entries[1].Route)
+				assert.Equal(t, audit.Success, entries[1].Outcome)
+			}
+		})
+
+		t.Run("should get 404 for unknown backup", func(t *testing.T) {
+			enableBackups(t)
+
+			res := serveAdmin(t, "POST", backupsURL+"/20200101T000000Z/restore", "secret")
+			assert.Equal(t, fasthttp.StatusNotFound, res.StatusCode)
+		})
+	})
+}
@@ -7,6 +7,8 @@ import (
 
 	"github.com/valyala/fasthttp"
 	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/server"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/view"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/auth"
 	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
 	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/store"
 )
@@ -23,13 +25,25 @@ func buildingPath() string {
 }
 
 func init() {
-	buildingFilters := &server.Filters{Before: []server.ResponseHandler{findAndLoadBuilding}}
+	tags := []string{"buildings"}
 	AddRoute(
-		server.NewRoute("GET", buildingsBasePath, listBuildingsHandler),
-		server.NewRoute("POST", buildingsBasePath, createBuildingHandler),
-		server.NewRouteWithFilters("GET", buildingPath(), getBuildingHandler, buildingFilters),
-		server.NewRouteWithFilters("PUT", buildingPath(), updateBuildingHandler, buildingFilters),
-		server.NewRouteWithFilters("DELETE", buildingPath(), deleteBuildingHandler, buildingFilters),
+		server.NewRouteWithFilters("GET", buildingsBasePath, listBuildingsHandler, authorized(auth.Read)).Describe(server.Documentation{
+			Summary: "List buildings", Tags: tags, Query: selectorQuery, Response: []view.Building{},
+			Errors: []int{fasthttp.StatusBadRequest},
+		}),
+		server.NewRouteWithFilters("POST", buildingsBasePath, createBuildingHandler, authorized(auth.Write)).Describe(server.Documentation{
+			Summary: "Create building", Tags: tags, Request: view.Building{}, Response: map[string]string{},
+			Status: fasthttp.StatusCreated, Errors: []int{fasthttp.StatusConflict},
+		}),
+		server.NewRouteWithFilters("GET", buildingPath(), getBuildingHandler, authorized(auth.Read, findAndLoadBuilding)).Describe(server.Documentation{
+			Summary: "Get building", Tags: tags, Response: view.Building{},
+		}),
+		server.NewRouteWithFilters("PUT", buildingPath(), updateBuildingHandler, authorized(auth.Write, findAndLoadBuilding)).Describe(server.Documentation{
+			Summary: "Update building", Tags: tags, Request: view.Building{},
+		}),
+		server.NewRouteWithFilters("DELETE", buildingPath(), deleteBuildingHandler, authorized(auth.Write, findAndLoadBuilding)).Describe(server.Documentation{
+			Summary: "Delete building along with its floors and rooms", Tags: tags,
+		}),
 	)
 }
 
@@ -68,15 +82,26 @@ var findAndLoadBuilding = func(kvStore store.Store, ctx server.RequestContext) e
 }
 
 var listBuildingsHandler = func(store store.Store, ctx server.RequestContext) error {
+	selector, err := labelSelector(ctx)
+	if err != nil {
+		return badRequest(ctx, err)
+	}
+
 	buildings, err := store.Buildings()
 	if err != nil {
 		return internalServerError(ctx, err)
 	}
-	return ctx.JSONResponse(buildings, http.StatusOK)
+
+	for id, building := range buildings {
+		if !visible(ctx, id) || !selector.Matches(building.Labels) {
+			delete(buildings, id)
+		}
+	}
+	return ctx.JSONResponse(view.NewBuildings(buildings), http.StatusOK)
 }
 
 var createBuildingHandler = func(store store.Store, ctx server.RequestContext) error {
-	building, err := gateway.NewBuilding(ctx.PostBody())
+	building, err := view.ConvertBuilding(ctx.PostBody())
 	if err != nil {
 		return badRequest(ctx, err)
 	}
@@ -104,11 +129,11 @@ var getBuildingHandler = func(store store.Store, ctx server.RequestContext) erro
 		return notFound(ctx)
 	}
 
-	return ctx.JSONResponse(building, fasthttp.StatusOK)
+	return ctx.JSONResponse(view.NewBuilding(building), fasthttp.StatusOK)
 }
 
 var updateBuildingHandler = func(store store.Store, ctx server.RequestContext) error {
-	building, err := gateway.NewBuilding(ctx.PostBody())
+	building, err := view.ConvertBuilding(ctx.PostBody())
 	if err != nil {
 		return badRequest(ctx, err)
 	}
@@ -7,6 +7,7 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"github.com/valyala/fasthttp"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/view"
 	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
 	mockStore "gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/internal/mocks/store"
 	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/testutils"
@@ -40,10 +41,55 @@ func TestBuildings(t *testing.T) {
 			assert.NoError(t, err)
 			assert.Equal(t, fasthttp.StatusOK, res.StatusCode)
 
-			actual := gateway.Buildings{}
+			var actual []view.Building
 			err = testutils.Read(res, &actual)
 			if assert.NoError(t, err) {
-				assert.Equal(t, buildings, actual)
+				assert.Equal(t, view.NewBuildings(buildings), actual)
+			}
+		})
+
+		t.Run("should return the buildings matching the label selector", func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockKVStore := mockStore.NewMockStore(ctrl)
+			outdoor := gateway.Building{PhysicalEntity: gateway.PhysicalEntity{
+				Name: "farm house", Labels: map[string]string{"env": "outdoor"},
+			}}
+			mockKVStore.EXPECT().Buildings().Return(gateway.Buildings{building.ID(): building, outdoor.ID(): outdoor}, nil)
+
+			request, err := http.NewRequest("GET", "http://test/buildings?selector=env%20in%20(outdoor,garden)", nil)
+			if err != nil {
+				t.Error(err)
+			}
+
+			res, err := testutils.ServeHTTPRequest(mockKVStore, request)
+			assert.NoError(t, err)
+			assert.Equal(t, fasthttp.StatusOK, res.StatusCode)
+
+			var actual []view.Building
+			err = testutils.Read(res, &actual)
+			if assert.NoError(t, err) {
+				assert.Equal(t, view.NewBuildings(gateway.Buildings{outdoor.ID(): outdoor}), actual)
+			}
+		})
+
+		t.Run("should reject an invalid label selector", func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockKVStore := mockStore.NewMockStore(ctrl)
+
+			request, err := http.NewRequest("GET", "http://test/buildings?selector=env=", nil)
+			if err != nil {
+				t.Error(err)
+			}
+
+			res, err := testutils.ServeHTTPRequest(mockKVStore, request)
+			assert.NoError(t, err)
+			assert.Equal(t, fasthttp.StatusBadRequest, res.StatusCode)
+
+			msg, err := testutils.ReadError(res)
+			if assert.NoError(t, err) {
+				assert.Equal(t, "requirement 'env=' should be given as key=value, key!=value, key in (a,b), key notin (a,b), key or !key", msg)
 			}
 		})
 
@@ -235,10 +281,10 @@ func TestBuildings(t *testing.T) {
 			assert.NoError(t, err)
 			assert.Equal(t, fasthttp.StatusOK, res.StatusCode)
 
-			actual := gateway.Building{}
+			actual := view.Building{}
 			err = testutils.Read(res, &actual)
 			if assert.NoError(t, err) {
-				assert.Equal(t, building, actual)
+				assert.Equal(t, view.NewBuilding(building), actual)
 			}
 		})
 
@@ -355,7 +401,7 @@ func TestBuildings(t *testing.T) {
 
 			msg, err := testutils.ReadError(res)
 			if assert.NoError(t, err) {
-				assert.Equal(t, "lan: cannot be blank; name: the length must be between 5 and 50.", msg)
+				assert.Equal(t, "longitude: cannot be blank; name: the length must be between 5 and 50.", msg)
 			}
 		})
 
@@ -0,0 +1,241 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+
+	"github.com/valyala/fasthttp"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/server"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/view"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/auth"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/store"
+)
+
+const (
+	deviceID       = "device-id"
+	deviceUserKey  = "device"
+	devicesUserKey = "devices"
+)
+
+func devicePath() string {
+	return path.Join(devicesBasePath(), fmt.Sprintf("{%s}", deviceID))
+}
+
+func devicesBasePath() string {
+	return path.Join(roomPath(), "devices")
+}
+
+func init() {
+	tags := []string{"devices"}
+	switchErrors := []int{fasthttp.StatusConflict, fasthttp.StatusNotImplemented, fasthttp.StatusBadGateway}
+	AddRoute(
+		server.NewRouteWithFilters("GET", devicesBasePath(), listDevicesHandler, authorized(auth.Read, findAndLoadRoom)).Describe(server.Documentation{
+			Summary: "List devices of the room", Tags: tags, Query: selectorQuery, Response: []view.Device{},
+			Errors: []int{fasthttp.StatusBadRequest},
+		}),
+		server.NewRouteWithFilters("POST", devicesBasePath(), createDeviceHandler, authorized(auth.Write, findAndLoadRoom)).Describe(server.Documentation{
+			Summary: "Create device in the room", Tags: tags, Request: view.Device{}, Response: map[string]string{},
+			Status: fasthttp.StatusCreated, Errors: []int{fasthttp.StatusConflict},
+		}),
+		server.NewRouteWithFilters("GET", devicePath(), getDeviceHandler, authorized(auth.Read, findAndLoadDevice)).Describe(server.Documentation{
+			Summary: "Get device", Tags: tags, Response: view.Device{},
+		}),
+		server.NewRouteWithFilters("PUT", devicePath(), updateDeviceHandler, authorized(auth.Write, findAndLoadDevice)).Describe(server.Documentation{
+			Summary: "Update device", Tags: tags, Request: view.Device{},
+		}),
+		server.NewRouteWithFilters("DELETE", devicePath(), deleteDeviceHandler, authorized(auth.Write, findAndLoadDevice)).Describe(server.Documentation{
+			Summary: "Delete device", Tags: tags,
+		}),
+		server.NewRouteWithFilters("POST", path.Join(devicePath(), "on"), switchDeviceHandler(gateway.StateOn), authorized(auth.Control, findAndLoadDevice)).Describe(server.Documentation{
+			Summary: "Switch on the device using the node which controls it", Tags: tags, Errors: switchErrors,
+		}),
+		server.NewRouteWithFilters("POST", path.Join(devicePath(), "off"), switchDeviceHandler(gateway.StateOff), authorized(auth.Control, findAndLoadDevice)).Describe(server.Documentation{
+			Summary: "Switch off the device using the node which controls it", Tags: tags, Errors: switchErrors,
+		}),
+	)
+}
+
+func loadDevicesAndDeviceFromContext(kvStore store.Store, ctx server.RequestContext) error {
+	err := loadRoomsAndRoomFromContext(kvStore, ctx)
+	if err != nil {
+		return err
+	}
+
+	room, ok := ctx.UserValue(roomUserKey).(gateway.Room)
+	if !ok {
+		return store.NotFound("unable to find room using context")
+	}
+
+	devices, err := kvStore.Devices(room)
+	if err != nil {
+		return err
+	}
+
+	id, ok := ctx.UserValue(deviceID).(string)
+	if !ok {
+		return store.NotFound("unable to find device key in the context")
+	}
+
+	device, ok := devices[id]
+	if !ok {
+		return store.NotFound("unable to find device using context")
+	}
+
+	ctx.SetUserValue(devicesUserKey, devices)
+	ctx.SetUserValue(deviceUserKey, device)
+	return nil
+}
+
+var findAndLoadDevice = func(kvStore store.Store, ctx server.RequestContext) error {
+	err := loadDevicesAndDeviceFromContext(kvStore, ctx)
+	if err != nil {
+		switch err.(type) {
+		case store.NotFound:
+			return notFound(ctx)
+		default:
+			return internalServerError(ctx, err)
+		}
+	}
+	return ctx.Next()
+}
+
+var listDevicesHandler = func(store store.Store, ctx server.RequestContext) error {
+	room, ok := ctx.UserValue(roomUserKey).(gateway.Room)
+	if !ok {
+		return notFound(ctx)
+	}
+
+	selector, err := labelSelector(ctx)
+	if err != nil {
+		return badRequest(ctx, err)
+	}
+
+	devices, err := store.Devices(room)
+	if err != nil {
+		return internalServerError(ctx, err)
+	}
+
+	for id, device := range devices {
+		if !selector.Matches(device.Labels) {
+			delete(devices, id)
+		}
+	}
+	return ctx.JSONResponse(view.NewDevices(devices), http.StatusOK)
+}
+
+var createDeviceHandler = func(store store.Store, ctx server.RequestContext) error {
+	room, ok := ctx.UserValue(roomUserKey).(gateway.Room)
+	if !ok {
+		return notFound(ctx)
+	}
+
+	device, err := view.ConvertDevice(room, ctx.PostBody())
+	if err != nil {
+		return badRequest(ctx, err)
+	}
+
+	devices, err := store.Devices(room)
+	if err != nil {
+		return internalServerError(ctx, err)
+	}
+
+	if _, ok := devices[device.ID()]; ok {
+		return conflict(ctx)
+	}
+
+	devices[device.ID()] = device
+	err = store.UpsertDevices(room, devices)
+	if err != nil {
+		return internalServerError(ctx, err)
+	}
+	return created(ctx, device.ID())
+}
+
+var getDeviceHandler = func(store store.Store, ctx server.RequestContext) error {
+	device, ok := ctx.UserValue(deviceUserKey).(gateway.Device)
+	if !ok {
+		return notFound(ctx)
+	}
+
+	return ctx.JSONResponse(view.NewDevice(device), fasthttp.StatusOK)
+}
+
+var updateDeviceHandler = func(store store.Store, ctx server.RequestContext) error {
+	room, ok := ctx.UserValue(roomUserKey).(gateway.Room)
+	if !ok {
+		return notFound(ctx)
+	}
+
+	device, err := view.ConvertDevice(room, ctx.PostBody())
+	if err != nil {
+		return badRequest(ctx, err)
+	}
+
+	err = store.UpsertDevice(device)
+	if err != nil {
+		return internalServerError(ctx, err)
+	}
+
+	return nil
+}
+
+var deleteDeviceHandler = func(store store.Store, ctx server.RequestContext) error {
+	device, ok := ctx.UserValue(deviceUserKey).(gateway.Device)
+	if !ok {
+		return notFound(ctx)
+	}
+
+	err := store.DeleteDevice(device)
+	if err != nil {
+		return internalServerError(ctx, err)
+	}
+
+	return nil
+}
+
+// switchDeviceHandler returns the handler which switches the device to
+// the state using the node of the room which controls the device, the
+// state is recorded for the scenes captured afterwards
+func switchDeviceHandler(state gateway.State) server.ResponseHandler {
+	return func(store store.Store, ctx server.RequestContext) error {
+		device, ok := ctx.UserValue(deviceUserKey).(gateway.Device)
+		if !ok {
+			return notFound(ctx)
+		}
+
+		nodes, err := store.Nodes(device.Room)
+		if err != nil {
+			return internalServerError(ctx, err)
+		}
+
+		metadata, ok := gateway.NodeOf(nodes, device)
+		if !ok {
+			err := fmt.Errorf("device %s is not controlled by any node", device.ID())
+			return ctx.JSONResponse(map[string]string{"error": err.Error()}, fasthttp.StatusConflict)
+		}
+
+		node, err := gateway.NewNodeFor(metadata)
+		if err != nil {
+			switch err.(type) {
+			case gateway.DriverNotFound:
+				return ctx.JSONResponse(map[string]string{"error": err.Error()}, fasthttp.StatusNotImplemented)
+			default:
+				return internalServerError(ctx, err)
+			}
+		}
+
+		err = state.Switch(node, device)
+		if err != nil {
+			return ctx.JSONResponse(map[string]string{"error": err.Error()}, fasthttp.StatusBadGateway)
+		}
+
+		err = store.UpsertState(device, state)
+		if err != nil {
+			return internalServerError(ctx, err)
+		}
+		publishState(device, state)
+		return nil
+	}
+}
@@ -0,0 +1,256 @@
+package api_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/view"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+	mockStore "gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/internal/mocks/store"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/testutils"
+)
+
+const devicesURL = "http://test/v1/buildings/building-one/floors/floor-one/rooms/room-one/devices"
+
+type fakeNode struct {
+	switched []string
+	err      error
+}
+
+func (node *fakeNode) On(device gateway.Device) error {
+	node.switched = append(node.switched, "on:"+device.ID())
+	return node.err
+}
+
+func (node *fakeNode) Off(device gateway.Device) error {
+	node.switched = append(node.switched, "off:"+device.ID())
+	return node.err
+}
+
+func expectRoom(mockKVStore *mockStore.MockStore) gateway.Room {
+	buildings, building := testutils.NewBuildings("building-one")
+	floors, floor := testutils.NewFloors("floor-one")
+	rooms, room := testutils.NewRooms("room-one")
+	mockKVStore.EXPECT().Buildings().Return(buildings, nil)
+	mockKVStore.EXPECT().Floors(building).Return(floors, nil)
+	mockKVStore.EXPECT().Rooms(floor).Return(rooms, nil)
+	return room
+}
+
+func serve(t *testing.T, mockKVStore *mockStore.MockStore, method, url string, body interface{}) *http.Response {
+	var data []byte
+	if body != nil {
+		data, _ = json.Marshal(body)
+	}
+
+	request, err := http.NewRequest(method, url, bytes.NewReader(data))
+	if err != nil {
+		t.Error(err)
+	}
+
+	res, err := testutils.ServeHTTPRequest(mockKVStore, request)
+	assert.NoError(t, err)
+	return res
+}
+
+func TestDevices(t *testing.T) {
+	devices, device := testutils.NewDevices("porch-light")
+
+	t.Run("test GET /buildings/:building-id/floors/:floor-id/rooms/:room-id/devices", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockKVStore := mockStore.NewMockStore(ctrl)
+		room := expectRoom(mockKVStore)
+		mockKVStore.EXPECT().Devices(room).Return(devices, nil)
+
+		res := serve(t, mockKVStore, "GET", devicesURL, nil)
+		assert.Equal(t, fasthttp.StatusOK, res.StatusCode)
+
+		var actual []view.Device
+		err := testutils.Read(res, &actual)
+		if assert.NoError(t, err) {
+			assert.Equal(t, view.NewDevices(devices), actual)
+		}
+	})
+
+	t.Run("test POST /buildings/:building-id/floors/:floor-id/rooms/:room-id/devices", func(t *testing.T) {
+		t.Run("should create device", func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockKVStore := mockStore.NewMockStore(ctrl)
+			room := expectRoom(mockKVStore)
+			created := gateway.Device{Room: room, Meta: map[string]string{"pin": "4"}, PhysicalEntity: gateway.PhysicalEntity{Name: "ceiling-fan"}}
+			mockKVStore.EXPECT().Devices(room).Return(gateway.Devices{}, nil)
+			mockKVStore.EXPECT().UpsertDevices(room, gateway.Devices{created.ID(): created}).Return(nil)
+
+			res := serve(t, mockKVStore, "POST", devicesURL, view.Device{Name: "ceiling-fan", Meta: map[string]string{"pin": "4"}})
+
+			assert.Equal(t, fasthttp.StatusCreated, res.StatusCode)
+		})
+
+		t.Run("should get 409 if the device already exists", func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockKVStore := mockStore.NewMockStore(ctrl)
+			room := expectRoom(mockKVStore)
+			mockKVStore.EXPECT().Devices(room).Return(devices, nil)
+
+			res := serve(t, mockKVStore, "POST", devicesURL, view.Device{Name: "porch-light"})
+
+			assert.Equal(t, fasthttp.StatusConflict, res.StatusCode)
+		})
+
+		t.Run("should handle validation error if any", func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockKVStore := mockStore.NewMockStore(ctrl)
+			expectRoom(mockKVStore)
+
+			res := serve(t, mockKVStore, "POST", devicesURL, view.Device{Name: "fan"})
+
+			assert.Equal(t, fasthttp.StatusBadRequest, res.StatusCode)
+			message, err := testutils.ReadError(res)
+			if assert.NoError(t, err) {
+				assert.Equal(t, "name: the length must be between 5 and 50.", message)
+			}
+		})
+	})
+
+	t.Run("test GET /buildings/:building-id/floors/:floor-id/rooms/:room-id/devices/:device-id", func(t *testing.T) {
+		t.Run("should return the device", func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockKVStore := mockStore.NewMockStore(ctrl)
+			room := expectRoom(mockKVStore)
+			mockKVStore.EXPECT().Devices(room).Return(devices, nil)
+
+			res := serve(t, mockKVStore, "GET", devicesURL+"/porch-light", nil)
+			assert.Equal(t, fasthttp.StatusOK, res.StatusCode)
+
+			actual := view.Device{}
+			err := testutils.Read(res, &actual)
+			if assert.NoError(t, err) {
+				assert.Equal(t, view.NewDevice(device), actual)
+			}
+		})
+
+		t.Run("should return 404 if device is not available", func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockKVStore := mockStore.NewMockStore(ctrl)
+			room := expectRoom(mockKVStore)
+			mockKVStore.EXPECT().Devices(room).Return(gateway.Devices{}, nil)
+
+			res := serve(t, mockKVStore, "GET", devicesURL+"/porch-light", nil)
+
+			assert.Equal(t, fasthttp.StatusNotFound, res.StatusCode)
+		})
+	})
+
+	t.Run("test PUT /buildings/:building-id/floors/:floor-id/rooms/:room-id/devices/:device-id", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockKVStore := mockStore.NewMockStore(ctrl)
+		room := expectRoom(mockKVStore)
+		mockKVStore.EXPECT().Devices(room).Return(devices, nil)
+		updated := gateway.Device{Room: room, PhysicalEntity: gateway.PhysicalEntity{Name: "porch-light", Description: "updated"}}
+		mockKVStore.EXPECT().UpsertDevice(updated).Return(nil)
+
+		res := serve(t, mockKVStore, "PUT", devicesURL+"/porch-light", view.Device{Name: "porch-light", Description: "updated"})
+
+		assert.Equal(t, fasthttp.StatusOK, res.StatusCode)
+	})
+
+	t.Run("test DELETE /buildings/:building-id/floors/:floor-id/rooms/:room-id/devices/:device-id", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockKVStore := mockStore.NewMockStore(ctrl)
+		room := expectRoom(mockKVStore)
+		mockKVStore.EXPECT().Devices(room).Return(devices, nil)
+		mockKVStore.EXPECT().DeleteDevice(device).Return(nil)
+
+		res := serve(t, mockKVStore, "DELETE", devicesURL+"/porch-light", nil)
+
+		assert.Equal(t, fasthttp.StatusOK, res.StatusCode)
+	})
+}
+
+func TestSwitchDevice(t *testing.T) {
+	devices, device := testutils.NewDevices("porch-light")
+	node := gateway.NodeMetadata{
+		Room:           device.Room,
+		Devices:        []string{device.ID()},
+		Host:           "192.168.1.20",
+		Type:           gateway.NodeTypeMqtt,
+		PhysicalEntity: gateway.PhysicalEntity{Name: "node-one"},
+	}
+
+	t.Run("should switch on the device using the node driver", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		fake := &fakeNode{}
+		testutils.UseNode(t, node.Host, fake)
+		mockKVStore := mockStore.NewMockStore(ctrl)
+		room := expectRoom(mockKVStore)
+		mockKVStore.EXPECT().Devices(room).Return(devices, nil)
+		mockKVStore.EXPECT().Nodes(device.Room).Return(gateway.Nodes{node.ID(): node}, nil)
+		mockKVStore.EXPECT().UpsertState(gomock.Any(), gateway.StateOn).Return(nil)
+
+		res := serve(t, mockKVStore, "POST", devicesURL+"/porch-light/on", nil)
+
+		assert.Equal(t, fasthttp.StatusOK, res.StatusCode)
+		assert.Equal(t, []string{"on:porch-light"}, fake.switched)
+	})
+
+	t.Run("should get 502 when the node fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		testutils.UseNode(t, node.Host, &fakeNode{err: errors.New("node unreachable")})
+		mockKVStore := mockStore.NewMockStore(ctrl)
+		room := expectRoom(mockKVStore)
+		mockKVStore.EXPECT().Devices(room).Return(devices, nil)
+		mockKVStore.EXPECT().Nodes(device.Room).Return(gateway.Nodes{node.ID(): node}, nil)
+
+		res := serve(t, mockKVStore, "POST", devicesURL+"/porch-light/off", nil)
+
+		assert.Equal(t, fasthttp.StatusBadGateway, res.StatusCode)
+	})
+
+	t.Run("should get 409 when no node controls the device", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockKVStore := mockStore.NewMockStore(ctrl)
+		room := expectRoom(mockKVStore)
+		mockKVStore.EXPECT().Devices(room).Return(devices, nil)
+		mockKVStore.EXPECT().Nodes(device.Room).Return(gateway.Nodes{}, nil)
+
+		res := serve(t, mockKVStore, "POST", devicesURL+"/porch-light/on", nil)
+
+		assert.Equal(t, fasthttp.StatusConflict, res.StatusCode)
+		message, err := testutils.ReadError(res)
+		if assert.NoError(t, err) {
+			assert.Equal(t, "device porch-light is not controlled by any node", message)
+		}
+	})
+
+	t.Run("should get 501 when no driver is registered for the node type", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		wifi := node
+		wifi.Type = gateway.NodeTypeWifi
+		mockKVStore := mockStore.NewMockStore(ctrl)
+		room := expectRoom(mockKVStore)
+		mockKVStore.EXPECT().Devices(room).Return(devices, nil)
+		mockKVStore.EXPECT().Nodes(device.Room).Return(gateway.Nodes{wifi.ID(): wifi}, nil)
+
+		res := serve(t, mockKVStore, "POST", devicesURL+"/porch-light/on", nil)
+
+		assert.Equal(t, fasthttp.StatusNotImplemented, res.StatusCode)
+	})
+}
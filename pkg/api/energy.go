@@ -0,0 +1,136 @@
+package api
+
+import (
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/valyala/fasthttp"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/server"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/view"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/auth"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/home"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/store"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/telemetry"
+)
+
+const (
+	periodParam    = "period"
+	periodDay      = "day"
+	periodWeek     = "week"
+	periodMonth    = "month"
+	formatCSV      = "csv"
+	csvContentType = "text/csv"
+)
+
+func init() {
+	AddRoute(
+		server.NewRouteWithFilters("GET", path.Join(buildingPath(), "energy"), energyHandler, authorized(auth.Read, telemetryEnabled, findAndLoadBuilding)).Describe(server.Documentation{
+			Summary: "Get the energy consumed in the building and in its floors, rooms and devices over a period", Tags: []string{"telemetry"},
+			Query: map[string]string{
+				periodParam: "day (default), week starting on monday or month",
+				dateParam:   "date of the period given as 2006-01-02 in the time zone of the building, today by default",
+				formatParam: "json (default) or csv",
+			},
+			Response: view.Energy{}, Errors: []int{fasthttp.StatusBadRequest, fasthttp.StatusNotImplemented},
+		}),
+	)
+}
+
+// periodOf returns the start and the end of the period holding the date
+func periodOf(period string, date time.Time) (time.Time, time.Time, error) {
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+	switch period {
+	case periodDay:
+		return day, day.AddDate(0, 0, 1), nil
+	case periodWeek:
+		monday := day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+		return monday, monday.AddDate(0, 0, 7), nil
+	case periodMonth:
+		first := day.AddDate(0, 0, 1-day.Day())
+		return first, first.AddDate(0, 1, 0), nil
+	default:
+		return time.Time{}, time.Time{}, fmt.Errorf("%s should be one of %s, %s or %s", periodParam, periodDay, periodWeek, periodMonth)
+	}
+}
+
+var energyHandler = func(store store.Store, ctx server.RequestContext) error {
+	building, ok := ctx.UserValue(buildingUserKey).(gateway.Building)
+	if !ok {
+		return notFound(ctx)
+	}
+
+	args := ctx.QueryArgs()
+	format := string(args.Peek(formatParam))
+	if format != "" && format != home.FormatJSON && format != formatCSV {
+		return badRequest(ctx, fmt.Errorf("%s should be %s or %s", formatParam, home.FormatJSON, formatCSV))
+	}
+	location := building.Location(time.Local)
+	date := time.Now().In(location)
+	if raw := string(args.Peek(dateParam)); raw != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", raw, location)
+		if err != nil {
+			return badRequest(ctx, fmt.Errorf("date %s should be given as yyyy-mm-dd e.g. 2026-10-19", raw))
+		}
+		date = parsed
+	}
+	period := string(args.Peek(periodParam))
+	if period == "" {
+		period = periodDay
+	}
+	from, to, err := periodOf(period, date)
+	if err != nil {
+		return badRequest(ctx, err)
+	}
+
+	tree, err := store.Tree()
+	if err != nil {
+		return internalServerError(ctx, err)
+	}
+	tree = visibleTree(ctx, tree)
+
+	// the floors and the rooms are reported when one of their devices
+	// reports the energy or the power
+	usage := []view.Usage{}
+	for floorID, floor := range tree.FloorsOf(building) {
+		floorUsage, floorMetered := view.Usage{Kind: "floor", Path: floorID, Name: floor.Name}, false
+		for roomID, room := range tree.RoomsOf(floor) {
+			roomUsage, roomMetered := view.Usage{Kind: "room", Path: path.Join(floorID, roomID), Name: room.Name}, false
+			for deviceID, device := range tree.DevicesOf(room) {
+				series := telemetry.Series{Building: building.ID(), Floor: floorID, Room: roomID, Device: deviceID}
+				kWh, metered, err := telemetryDB.Consumption(series, from, to)
+				if err != nil {
+					return internalServerError(ctx, err)
+				}
+				if !metered {
+					continue
+				}
+				usage = append(usage, view.Usage{Kind: "device", Path: path.Join(floorID, roomID, deviceID), Name: device.Name, Consumption: kWh})
+				roomUsage.Consumption += kWh
+				roomMetered = true
+			}
+			if roomMetered {
+				usage = append(usage, roomUsage)
+				floorUsage.Consumption += roomUsage.Consumption
+				floorMetered = true
+			}
+		}
+		if floorMetered {
+			usage = append(usage, floorUsage)
+		}
+	}
+	energy := view.NewEnergy(building, period, from, to, usage)
+
+	if format != formatCSV {
+		return ctx.JSONResponse(energy, fasthttp.StatusOK)
+	}
+	data, err := energy.CSV()
+	if err != nil {
+		return internalServerError(ctx, err)
+	}
+	ctx.SetContentType(csvContentType)
+	ctx.SetStatusCode(fasthttp.StatusOK)
+	ctx.SetBodyString(data)
+	return nil
+}
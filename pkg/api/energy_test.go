@@ -0,0 +1,104 @@
+package api_test
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/view"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/auth"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/store"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/telemetry"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/testutils"
+)
+
+const energyURL = "/buildings/building-one/energy"
+
+// seedEnergy seeds the store with building-one charging 8 INR a kWh and
+// the porch-light counting 1 kWh on 2026-10-19
+func seedEnergy(t *testing.T, persistentStore store.Store) {
+	seedStore(t, persistentStore)
+	building := testutils.NewBuilding("building-one")
+	building.Timezone, building.Tariff = "UTC", &gateway.Tariff{Currency: "INR", Rate: 8}
+	assert.NoError(t, persistentStore.UpsertBuilding(building))
+
+	porchLight := telemetry.Series{Building: "building-one", Floor: "floor-one", Room: "room-one", Device: "porch-light", Metric: "energy_total"}
+	start := time.Date(2026, 10, 19, 6, 0, 0, 0, time.UTC)
+	readings := []telemetry.Reading{}
+	for i, value := range []float64{1, 1.5, 2} {
+		readings = append(readings, telemetry.Reading{Series: porchLight, Time: start.Add(time.Duration(i) * 35 * time.Minute), Value: value})
+	}
+	assert.NoError(t, enableTelemetry(t).Append(readings...))
+}
+
+func TestEnergy(t *testing.T) {
+	t.Run("should report the energy consumed over the period along with its cost", func(t *testing.T) {
+		persistentStore := enableAuthentication(t)
+		seedEnergy(t, persistentStore)
+		viewer := issueToken(t, persistentStore, auth.RoleViewer)
+
+		res := serveAs(t, persistentStore, viewer, "GET", energyURL+"?period=week&date=2026-10-21")
+
+		assert.Equal(t, fasthttp.StatusOK, res.StatusCode)
+		energy := view.Energy{}
+		if assert.NoError(t, testutils.Read(res, &energy)) {
+			assert.Equal(t, "2026-10-19T00:00:00Z", energy.From.Format(time.RFC3339))
+			assert.Equal(t, "2026-10-26T00:00:00Z", energy.To.Format(time.RFC3339))
+			assert.Equal(t, 1.0, energy.Consumption)
+			assert.Equal(t, 8.0, energy.Cost)
+			assert.Equal(t, "INR", energy.Currency)
+			assert.Equal(t, []view.Usage{
+				{Kind: "floor", Path: "floor-one", Name: "floor-one", Consumption: 1, Cost: 8},
+				{Kind: "room", Path: "floor-one/room-one", Name: "room-one", Consumption: 1, Cost: 8},
+				{Kind: "device", Path: "floor-one/room-one/porch-light", Name: "porch-light", Consumption: 1, Cost: 8},
+			}, energy.Usage)
+		}
+
+		res = serveAs(t, persistentStore, viewer, "GET", energyURL+"?period=day&date=2026-10-20")
+		energy = view.Energy{}
+		if assert.NoError(t, testutils.Read(res, &energy)) {
+			assert.Equal(t, 0.0, energy.Consumption)
+			assert.Len(t, energy.Usage, 3)
+		}
+	})
+
+	t.Run("should export the report as csv", func(t *testing.T) {
+		persistentStore := enableAuthentication(t)
+		seedEnergy(t, persistentStore)
+		viewer := issueToken(t, persistentStore, auth.RoleViewer)
+
+		res := serveAs(t, persistentStore, viewer, "GET", energyURL+"?period=month&date=2026-10-21&format=csv")
+
+		assert.Equal(t, fasthttp.StatusOK, res.StatusCode)
+		assert.Equal(t, "text/csv", res.Header.Get("Content-Type"))
+		body, err := io.ReadAll(res.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, `kind,path,name,from,to,kwh,cost,currency
+building,building-one,building-one,2026-10-01T00:00:00Z,2026-11-01T00:00:00Z,1.000,8.00,INR
+floor,floor-one,floor-one,2026-10-01T00:00:00Z,2026-11-01T00:00:00Z,1.000,8.00,INR
+room,floor-one/room-one,room-one,2026-10-01T00:00:00Z,2026-11-01T00:00:00Z,1.000,8.00,INR
+device,floor-one/room-one/porch-light,porch-light,2026-10-01T00:00:00Z,2026-11-01T00:00:00Z,1.000,8.00,INR
+`, string(body))
+	})
+
+	t.Run("should reject invalid periods", func(t *testing.T) {
+		persistentStore := enableAuthentication(t)
+		seedEnergy(t, persistentStore)
+		viewer := issueToken(t, persistentStore, auth.RoleViewer)
+
+		for url, message := range map[string]string{
+			energyURL + "?period=year":     "period should be one of day, week or month",
+			energyURL + "?date=19-10-2026": "date 19-10-2026 should be given as yyyy-mm-dd e.g. 2026-10-19",
+			energyURL + "?format=yaml":     "format should be json or csv",
+		} {
+			res := serveAs(t, persistentStore, viewer, "GET", url)
+			assert.Equal(t, fasthttp.StatusBadRequest, res.StatusCode, url)
+			actual, err := testutils.ReadError(res)
+			assert.NoError(t, err)
+			assert.Equal(t, message, actual, url)
+		}
+	})
+}
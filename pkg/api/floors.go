@@ -7,6 +7,8 @@ import (
 
 	"github.com/valyala/fasthttp"
 	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/server"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/view"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/auth"
 	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
 	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/store"
 )
@@ -26,14 +28,25 @@ func floorsBasePath() string {
 }
 
 func init() {
-	floorFilters := &server.Filters{Before: []server.ResponseHandler{findAndLoadFloor}}
-	buildingFilters := &server.Filters{Before: []server.ResponseHandler{findAndLoadBuilding}}
+	tags := []string{"floors"}
 	AddRoute(
-		server.NewRouteWithFilters("GET", floorsBasePath(), listFloorsHandler, buildingFilters),
-		server.NewRouteWithFilters("POST", floorsBasePath(), createFloorHandler, buildingFilters),
-		server.NewRouteWithFilters("GET", floorPath(), getFloorHandler, floorFilters),
-		server.NewRouteWithFilters("PUT", floorPath(), updateFloorHandler, floorFilters),
-		server.NewRouteWithFilters("DELETE", floorPath(), deleteFloorHandler, floorFilters),
+		server.NewRouteWithFilters("GET", floorsBasePath(), listFloorsHandler, authorized(auth.Read, findAndLoadBuilding)).Describe(server.Documentation{
+			Summary: "List floors of the building", Tags: tags, Query: selectorQuery, Response: []view.Floor{},
+			Errors: []int{fasthttp.StatusBadRequest},
+		}),
+		server.NewRouteWithFilters("POST", floorsBasePath(), createFloorHandler, authorized(auth.Write, findAndLoadBuilding)).Describe(server.Documentation{
+			Summary: "Create floor in the building", Tags: tags, Request: view.Floor{}, Response: map[string]string{},
+			Status: fasthttp.StatusCreated, Errors: []int{fasthttp.StatusConflict},
+		}),
+		server.NewRouteWithFilters("GET", floorPath(), getFloorHandler, authorized(auth.Read, findAndLoadFloor)).Describe(server.Documentation{
+			Summary: "Get floor", Tags: tags, Response: view.Floor{},
+		}),
+		server.NewRouteWithFilters("PUT", floorPath(), updateFloorHandler, authorized(auth.Write, findAndLoadFloor)).Describe(server.Documentation{
+			Summary: "Update floor", Tags: tags, Request: view.Floor{},
+		}),
+		server.NewRouteWithFilters("DELETE", floorPath(), deleteFloorHandler, authorized(auth.Write, findAndLoadFloor)).Describe(server.Documentation{
+			Summary: "Delete floor along with its rooms", Tags: tags,
+		}),
 	)
 }
 
@@ -87,11 +100,22 @@ var listFloorsHandler = func(store store.Store, ctx server.RequestContext) error
 		return notFound(ctx)
 	}
 
+	selector, err := labelSelector(ctx)
+	if err != nil {
+		return badRequest(ctx, err)
+	}
+
 	floors, err := store.Floors(building)
 	if err != nil {
 		return internalServerError(ctx, err)
 	}
-	return ctx.JSONResponse(floors, http.StatusOK)
+
+	for id, floor := range floors {
+		if !visible(ctx, building.ID(), id) || !selector.Matches(floor.Labels) {
+			delete(floors, id)
+		}
+	}
+	return ctx.JSONResponse(view.NewFloors(floors), http.StatusOK)
 }
 
 var createFloorHandler = func(store store.Store, ctx server.RequestContext) error {
@@ -100,7 +124,7 @@ var createFloorHandler = func(store store.Store, ctx server.RequestContext) erro
 		return notFound(ctx)
 	}
 
-	floor, err := gateway.NewFloor(building, ctx.PostBody())
+	floor, err := view.ConvertFloor(building, ctx.PostBody())
 	if err != nil {
 		return badRequest(ctx, err)
 	}
@@ -128,7 +152,7 @@ var getFloorHandler = func(store store.Store, ctx server.RequestContext) error {
 		return notFound(ctx)
 	}
 
-	return ctx.JSONResponse(floor, fasthttp.StatusOK)
+	return ctx.JSONResponse(view.NewFloor(floor), fasthttp.StatusOK)
 }
 
 var updateFloorHandler = func(store store.Store, ctx server.RequestContext) error {
@@ -137,7 +161,7 @@ var updateFloorHandler = func(store store.Store, ctx server.RequestContext) erro
 		return notFound(ctx)
 	}
 
-	floor, err := gateway.NewFloor(building, ctx.PostBody())
+	floor, err := view.ConvertFloor(building, ctx.PostBody())
 	if err != nil {
 		return badRequest(ctx, err)
 	}
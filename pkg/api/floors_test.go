@@ -7,6 +7,7 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"github.com/valyala/fasthttp"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/view"
 	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
 	mockStore "gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/internal/mocks/store"
 	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/testutils"
@@ -43,10 +44,10 @@ func TestFloors(t *testing.T) {
 			assert.NoError(t, err)
 			assert.Equal(t, fasthttp.StatusOK, res.StatusCode)
 
-			actual := gateway.Floors{}
+			var actual []view.Floor
 			err = testutils.Read(res, &actual)
 			if assert.NoError(t, err) {
-				assert.Equal(t, floors, actual)
+				assert.Equal(t, view.NewFloors(floors), actual)
 			}
 		})
 
@@ -260,10 +261,10 @@ func TestFloors(t *testing.T) {
 			assert.NoError(t, err)
 			assert.Equal(t, fasthttp.StatusOK, res.StatusCode)
 
-			actual := gateway.Floor{}
+			actual := view.Floor{}
 			err = testutils.Read(res, &actual)
 			if assert.NoError(t, err) {
-				assert.Equal(t, floor, actual)
+				assert.Equal(t, view.NewFloor(floor), actual)
 			}
 		})
 
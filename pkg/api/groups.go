@@ -0,0 +1,242 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+
+	"github.com/valyala/fasthttp"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/server"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/view"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/auth"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/store"
+)
+
+const (
+	groupID      = "group-id"
+	groupUserKey = "group"
+)
+
+func groupPath() string {
+	return path.Join(groupsBasePath(), fmt.Sprintf("{%s}", groupID))
+}
+
+func groupsBasePath() string {
+	return path.Join(buildingPath(), "groups")
+}
+
+func init() {
+	tags := []string{"groups"}
+	AddRoute(
+		server.NewRouteWithFilters("GET", groupsBasePath(), listGroupsHandler, authorized(auth.Read, findAndLoadBuilding)).Describe(server.Documentation{
+			Summary: "List device groups of the building", Tags: tags, Query: selectorQuery, Response: []view.Group{},
+			Errors: []int{fasthttp.StatusBadRequest},
+		}),
+		server.NewRouteWithFilters("POST", groupsBasePath(), createGroupHandler, authorized(auth.Write, findAndLoadBuilding)).Describe(server.Documentation{
+			Summary: "Create device group in the building from devices or a selector", Tags: tags, Request: view.Group{}, Response: map[string]string{},
+			Status: fasthttp.StatusCreated, Errors: []int{fasthttp.StatusConflict},
+		}),
+		server.NewRouteWithFilters("GET", groupPath(), getGroupHandler, authorized(auth.Read, findAndLoadGroup)).Describe(server.Documentation{
+			Summary: "Get device group", Tags: tags, Response: view.Group{},
+		}),
+		server.NewRouteWithFilters("PUT", groupPath(), updateGroupHandler, authorized(auth.Write, findAndLoadGroup)).Describe(server.Documentation{
+			Summary: "Update device group", Tags: tags, Request: view.Group{},
+		}),
+		server.NewRouteWithFilters("DELETE", groupPath(), deleteGroupHandler, authorized(auth.Write, findAndLoadGroup)).Describe(server.Documentation{
+			Summary: "Delete device group", Tags: tags,
+		}),
+		server.NewRouteWithFilters("GET", path.Join(groupPath(), "members"), listMembersHandler, authorized(auth.Read, findAndLoadGroup)).Describe(server.Documentation{
+			Summary: "List the devices of the group, a dynamic group is resolved from its selector", Tags: tags, Response: view.Members{},
+		}),
+		server.NewRouteWithFilters("POST", path.Join(groupPath(), "on"), commandGroupHandler(gateway.StateOn), authorized(auth.Control, findAndLoadGroup)).Describe(server.Documentation{
+			Summary: "Switch on the devices of the group in parallel, 207 when any of them fails", Tags: tags, Response: view.Command{},
+			Errors: []int{fasthttp.StatusMultiStatus},
+		}),
+		server.NewRouteWithFilters("POST", path.Join(groupPath(), "off"), commandGroupHandler(gateway.StateOff), authorized(auth.Control, findAndLoadGroup)).Describe(server.Documentation{
+			Summary: "Switch off the devices of the group in parallel, 207 when any of them fails", Tags: tags, Response: view.Command{},
+			Errors: []int{fasthttp.StatusMultiStatus},
+		}),
+	)
+}
+
+var findAndLoadGroup = func(kvStore store.Store, ctx server.RequestContext) error {
+	err := loadBuildingsAndBuildingFromContext(kvStore, ctx)
+	if err != nil {
+		switch err.(type) {
+		case store.NotFound:
+			return notFound(ctx)
+		default:
+			return internalServerError(ctx, err)
+		}
+	}
+
+	building := ctx.UserValue(buildingUserKey).(gateway.Building)
+	groups, err := kvStore.Groups(building)
+	if err != nil {
+		return internalServerError(ctx, err)
+	}
+
+	id, _ := ctx.UserValue(groupID).(string)
+	group, ok := groups[id]
+	if !ok {
+		return notFound(ctx)
+	}
+	ctx.SetUserValue(groupUserKey, group)
+	setAuditEntity(ctx, groupEntity(group), view.NewGroup(group))
+	return ctx.Next()
+}
+
+// groupEntity returns the entity of the group recorded by the audit
+func groupEntity(group gateway.Group) string {
+	return path.Join(group.Building.ID(), "groups", group.ID())
+}
+
+var listGroupsHandler = func(store store.Store, ctx server.RequestContext) error {
+	building, ok := ctx.UserValue(buildingUserKey).(gateway.Building)
+	if !ok {
+		return notFound(ctx)
+	}
+
+	selector, err := labelSelector(ctx)
+	if err != nil {
+		return badRequest(ctx, err)
+	}
+
+	groups, err := store.Groups(building)
+	if err != nil {
+		return internalServerError(ctx, err)
+	}
+
+	for id, group := range groups {
+		if !selector.Matches(group.Labels) {
+			delete(groups, id)
+		}
+	}
+	return ctx.JSONResponse(view.NewGroups(groups), http.StatusOK)
+}
+
+var createGroupHandler = func(store store.Store, ctx server.RequestContext) error {
+	building, ok := ctx.UserValue(buildingUserKey).(gateway.Building)
+	if !ok {
+		return notFound(ctx)
+	}
+
+	group, err := view.ConvertGroup(building, ctx.PostBody())
+	if err != nil {
+		return badRequest(ctx, err)
+	}
+
+	groups, err := store.Groups(building)
+	if err != nil {
+		return internalServerError(ctx, err)
+	}
+
+	setAuditEntity(ctx, groupEntity(group), nil)
+	if _, ok := groups[group.ID()]; ok {
+		return conflict(ctx)
+	}
+
+	groups[group.ID()] = group
+	err = store.UpsertGroups(building, groups)
+	if err != nil {
+		return internalServerError(ctx, err)
+	}
+	ctx.SetUserValue(auditAfterKey, view.NewGroup(group))
+	return created(ctx, group.ID())
+}
+
+var getGroupHandler = func(store store.Store, ctx server.RequestContext) error {
+	group, ok := ctx.UserValue(groupUserKey).(gateway.Group)
+	if !ok {
+		return notFound(ctx)
+	}
+
+	return ctx.JSONResponse(view.NewGroup(group), fasthttp.StatusOK)
+}
+
+var updateGroupHandler = func(store store.Store, ctx server.RequestContext) error {
+	building, ok := ctx.UserValue(buildingUserKey).(gateway.Building)
+	if !ok {
+		return notFound(ctx)
+	}
+
+	group, err := view.ConvertGroup(building, ctx.PostBody())
+	if err != nil {
+		return badRequest(ctx, err)
+	}
+
+	err = store.UpsertGroup(group)
+	if err != nil {
+		return internalServerError(ctx, err)
+	}
+	ctx.SetUserValue(auditAfterKey, view.NewGroup(group))
+	return nil
+}
+
+var deleteGroupHandler = func(store store.Store, ctx server.RequestContext) error {
+	group, ok := ctx.UserValue(groupUserKey).(gateway.Group)
+	if !ok {
+		return notFound(ctx)
+	}
+
+	err := store.DeleteGroup(group)
+	if err != nil {
+		return internalServerError(ctx, err)
+	}
+	return nil
+}
+
+// listMembersHandler lists the devices of the group which the token of the
+// request is allowed to read
+var listMembersHandler = func(store store.Store, ctx server.RequestContext) error {
+	group, ok := ctx.UserValue(groupUserKey).(gateway.Group)
+	if !ok {
+		return notFound(ctx)
+	}
+
+	tree, err := store.Tree()
+	if err != nil {
+		return internalServerError(ctx, err)
+	}
+
+	members := []gateway.Target{}
+	for _, member := range group.Members(tree) {
+		if visible(ctx, group.Building.ID(), member.Floor, member.Room) {
+			members = append(members, member)
+		}
+	}
+	return ctx.JSONResponse(view.NewMembers(group, tree, members), fasthttp.StatusOK)
+}
+
+// commandGroupHandler returns the handler which switches the devices of
+// the group to the state in parallel and records the state of the devices
+// switched successfully
+func commandGroupHandler(state gateway.State) server.ResponseHandler {
+	return func(store store.Store, ctx server.RequestContext) error {
+		group, ok := ctx.UserValue(groupUserKey).(gateway.Group)
+		if !ok {
+			return notFound(ctx)
+		}
+
+		tree, err := store.Tree()
+		if err != nil {
+			return internalServerError(ctx, err)
+		}
+
+		results := group.Command(tree, state).Activate(tree)
+		status := fasthttp.StatusOK
+		for _, result := range results {
+			if result.Err != nil {
+				status = fasthttp.StatusMultiStatus
+				continue
+			}
+			err = store.UpsertState(*result.Device, result.Target.State)
+			if err != nil {
+				return internalServerError(ctx, err)
+			}
+			publishState(*result.Device, result.Target.State)
+		}
+		return ctx.JSONResponse(view.NewCommand(group, state, results), status)
+	}
+}
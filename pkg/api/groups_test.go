@@ -0,0 +1,162 @@
+package api_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/view"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/audit"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/auth"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/store"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/testutils"
+)
+
+const groupsURL = "/buildings/building-one/groups"
+
+// seedGroups seeds the store with the porch-light and the broken-lamp of
+// room-one labelled as exterior lights along with the ceiling-fan
+func seedGroups(t *testing.T, persistentStore store.Store) *testutils.RecordingNode {
+	node := seedScenes(t, persistentStore)
+	for name, capability := range map[string]string{"porch-light": "light", "broken-lamp": "light", "ceiling-fan": "fan"} {
+		device := testutils.NewDevice(name)
+		device.Meta = map[string]string{gateway.CapabilityMeta: capability, "zone": "exterior"}
+		assert.NoError(t, persistentStore.UpsertDevice(device))
+	}
+	return node
+}
+
+func TestGroups(t *testing.T) {
+	exteriorLights := `{"name":"exterior lights","selector":{"capability":"light","meta":{"zone":"exterior"}}}`
+
+	t.Run("should create, list, update and delete the groups", func(t *testing.T) {
+		persistentStore := enableAuthentication(t)
+		seedGroups(t, persistentStore)
+		editor := issueToken(t, persistentStore, auth.RoleEditor)
+
+		res := serveBodyAs(t, persistentStore, editor, "POST", groupsURL, exteriorLights)
+		assert.Equal(t, fasthttp.StatusCreated, res.StatusCode)
+		res = serveBodyAs(t, persistentStore, editor, "POST", groupsURL, exteriorLights)
+		assert.Equal(t, fasthttp.StatusConflict, res.StatusCode)
+
+		res = serveAs(t, persistentStore, editor, "GET", groupsURL)
+		var groups []view.Group
+		if assert.NoError(t, testutils.Read(res, &groups)) && assert.Len(t, groups, 1) {
+			assert.Equal(t, "exterior-lights", groups[0].ID)
+			assert.Equal(t, "building-one", groups[0].Building)
+			assert.Equal(t, &gateway.Selector{Capability: "light", Meta: map[string]string{"zone": "exterior"}}, groups[0].Selector)
+		}
+
+		res = serveBodyAs(t, persistentStore, editor, "PUT", groupsURL+"/exterior-lights", `{"name":"exterior lights","description":"porch only",
+			"devices":["floor-one/room-one/porch-light"]}`)
+		assert.Equal(t, fasthttp.StatusOK, res.StatusCode)
+		res = serveAs(t, persistentStore, editor, "GET", groupsURL+"/exterior-lights")
+		group := view.Group{}
+		if assert.NoError(t, testutils.Read(res, &group)) {
+			assert.Equal(t, "porch only", group.Description)
+			assert.Equal(t, []string{"floor-one/room-one/porch-light"}, group.Devices)
+			assert.Nil(t, group.Selector)
+		}
+
+		res = serveAs(t, persistentStore, editor, "DELETE", groupsURL+"/exterior-lights")
+		assert.Equal(t, fasthttp.StatusOK, res.StatusCode)
+		res = serveAs(t, persistentStore, editor, "GET", groupsURL+"/exterior-lights")
+		assert.Equal(t, fasthttp.StatusNotFound, res.StatusCode)
+	})
+
+	t.Run("should reject invalid group", func(t *testing.T) {
+		persistentStore := enableAuthentication(t)
+		seedGroups(t, persistentStore)
+		editor := issueToken(t, persistentStore, auth.RoleEditor)
+
+		res := serveBodyAs(t, persistentStore, editor, "POST", groupsURL, `{"name":"exterior lights","devices":["porch-light"]}`)
+
+		assert.Equal(t, fasthttp.StatusBadRequest, res.StatusCode)
+		message, err := testutils.ReadError(res)
+		assert.NoError(t, err)
+		assert.Equal(t, "device porch-light should be given as floor-id/room-id/device-id", message)
+	})
+
+	t.Run("should list the members of the groups", func(t *testing.T) {
+		persistentStore := enableAuthentication(t)
+		seedGroups(t, persistentStore)
+		editor := issueToken(t, persistentStore, auth.RoleEditor)
+		serveBodyAs(t, persistentStore, editor, "POST", groupsURL, exteriorLights)
+		serveBodyAs(t, persistentStore, editor, "POST", groupsURL, `{"name":"porch and garage","devices":["floor-one/room-one/porch-light","floor-one/garage/garage-light"]}`)
+
+		res := serveAs(t, persistentStore, editor, "GET", groupsURL+"/exterior-lights/members")
+		members := view.Members{}
+		if assert.NoError(t, testutils.Read(res, &members)) {
+			assert.True(t, members.Dynamic)
+			assert.Equal(t, []view.Member{
+				{Device: "floor-one/room-one/broken-lamp", Name: "broken-lamp", Meta: map[string]string{"capability": "light", "zone": "exterior"}},
+				{Device: "floor-one/room-one/porch-light", Name: "porch-light", Meta: map[string]string{"capability": "light", "zone": "exterior"}},
+			}, members.Members)
+		}
+
+		res = serveAs(t, persistentStore, editor, "GET", groupsURL+"/porch-and-garage/members")
+		members = view.Members{}
+		if assert.NoError(t, testutils.Read(res, &members)) && assert.Len(t, members.Members, 2) {
+			assert.False(t, members.Dynamic)
+			assert.Equal(t, view.Member{Device: "floor-one/garage/garage-light", Missing: true}, members.Members[0])
+		}
+
+		scoped := issueToken(t, persistentStore, auth.RoleViewer, "building-one/floor-two")
+		res = serveAs(t, persistentStore, scoped, "GET", groupsURL+"/exterior-lights/members")
+		members = view.Members{}
+		if assert.NoError(t, testutils.Read(res, &members)) {
+			assert.Empty(t, members.Members)
+		}
+	})
+
+	t.Run("should switch the devices of the group with the result of every device", func(t *testing.T) {
+		persistentStore := enableAuthentication(t)
+		node := seedGroups(t, persistentStore)
+		editor := issueToken(t, persistentStore, auth.RoleEditor)
+		operator := issueToken(t, persistentStore, auth.RoleOperator)
+		serveBodyAs(t, persistentStore, editor, "POST", groupsURL, exteriorLights)
+
+		res := serveAs(t, persistentStore, operator, "POST", groupsURL+"/exterior-lights/on")
+
+		assert.Equal(t, fasthttp.StatusMultiStatus, res.StatusCode)
+		command := view.Command{}
+		if assert.NoError(t, testutils.Read(res, &command)) {
+			assert.Equal(t, view.Command{Group: "exterior-lights", State: gateway.StateOn, Switched: 1, Failed: 1, Results: []view.TargetResult{
+				{Device: "floor-one/room-one/broken-lamp", State: gateway.StateOn, Outcome: view.Failed, Error: "node unreachable"},
+				{Device: "floor-one/room-one/porch-light", State: gateway.StateOn, Outcome: view.Switched},
+			}}, command)
+		}
+		assert.ElementsMatch(t, []string{"on:porch-light", "on:broken-lamp"}, node.Switched())
+
+		states, err := persistentStore.States(testutils.NewRoom("room-one"))
+		assert.NoError(t, err)
+		assert.Equal(t, gateway.States{"porch-light": gateway.StateOn}, states)
+
+		serveBodyAs(t, persistentStore, editor, "PUT", groupsURL+"/exterior-lights", `{"name":"exterior lights","devices":["floor-one/room-one/porch-light"]}`)
+		res = serveAs(t, persistentStore, operator, "POST", groupsURL+"/exterior-lights/off")
+		assert.Equal(t, fasthttp.StatusOK, res.StatusCode)
+	})
+
+	t.Run("should audit the changes of the groups", func(t *testing.T) {
+		persistentStore := enableAuthentication(t)
+		seedGroups(t, persistentStore)
+		api.EnableAudit(true)
+		t.Cleanup(func() {
+			api.EnableAudit(false)
+		})
+		editor := issueToken(t, persistentStore, auth.RoleEditor)
+
+		serveBodyAs(t, persistentStore, editor, "POST", groupsURL, exteriorLights)
+		serveAs(t, persistentStore, editor, "DELETE", groupsURL+"/exterior-lights")
+
+		entries, err := persistentStore.Audit(audit.Query{Entity: "building-one/groups/exterior-lights"})
+		if assert.NoError(t, err) && assert.Len(t, entries, 2) {
+			assert.Nil(t, entries[0].Before)
+			assert.Contains(t, string(entries[0].After), `"capability":"light"`)
+			assert.Equal(t, "DELETE", entries[1].Method)
+			assert.Nil(t, entries[1].After)
+		}
+	})
+}
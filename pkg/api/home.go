@@ -0,0 +1,88 @@
+package api
+
+import (
+	"github.com/valyala/fasthttp"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/server"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/auth"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/home"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/store"
+)
+
+const (
+	importBasePath = "/import"
+	exportBasePath = "/export"
+	modeParam      = "mode"
+	dryRunParam    = "dry-run"
+	formatParam    = "format"
+
+	yamlContentType = "application/yaml"
+)
+
+func init() {
+	tags := []string{"home"}
+	AddRoute(
+		server.NewRouteWithFilters("POST", importBasePath, importHandler, authorized(auth.Write)).Describe(server.Documentation{
+			Summary: "Import buildings, floors, rooms and devices", Tags: tags,
+			Query: map[string]string{
+				modeParam:   "merge (default) keeps entities missing from the import, replace deletes them",
+				dryRunParam: "true to report the changes without persisting them",
+				formatParam: "json (default) or yaml",
+			},
+			Request: home.Home{}, Response: home.Plan{},
+		}),
+		server.NewRouteWithFilters("GET", exportBasePath, exportHandler, authorized(auth.Read)).Describe(server.Documentation{
+			Summary: "Export buildings, floors, rooms and devices", Tags: tags,
+			Query:    map[string]string{formatParam: "json (default) or yaml"},
+			Response: home.Home{}, Errors: []int{fasthttp.StatusBadRequest},
+		}),
+	)
+}
+
+var importHandler = func(store store.Store, ctx server.RequestContext) error {
+	args := ctx.QueryArgs()
+	mode, err := home.NewMode(string(args.Peek(modeParam)))
+	if err != nil {
+		return badRequest(ctx, err)
+	}
+
+	h, err := home.Decode(ctx.PostBody(), string(args.Peek(formatParam)))
+	if err != nil {
+		return badRequest(ctx, err)
+	}
+
+	desired, err := h.Tree()
+	if err != nil {
+		return badRequest(ctx, err)
+	}
+
+	plan, err := home.Import(store, desired, mode, args.GetBool(dryRunParam))
+	if err != nil {
+		return internalServerError(ctx, err)
+	}
+	if !plan.DryRun {
+		ctx.SetUserValue(auditPlanKey, plan)
+	}
+	return ctx.JSONResponse(plan, fasthttp.StatusOK)
+}
+
+var exportHandler = func(store store.Store, ctx server.RequestContext) error {
+	format := string(ctx.QueryArgs().Peek(formatParam))
+	tree, err := store.Tree()
+	if err != nil {
+		return internalServerError(ctx, err)
+	}
+	exported := home.NewHome(visibleTree(ctx, tree))
+
+	if format == "" || format == home.FormatJSON {
+		return ctx.JSONResponse(exported, fasthttp.StatusOK)
+	}
+
+	data, err := home.Encode(exported, format)
+	if err != nil {
+		return badRequest(ctx, err)
+	}
+	ctx.SetContentType(yamlContentType)
+	ctx.SetStatusCode(fasthttp.StatusOK)
+	ctx.SetBodyString(string(data))
+	return nil
+}
@@ -0,0 +1,163 @@
+package api_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/home"
+	mockStore "gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/internal/mocks/store"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/testutils"
+)
+
+const homeYAML = `buildings:
+  - name: building-one
+    latitude: 12.97
+    longitude: 77.59
+    floors:
+      - name: floor-one
+        level: 1
+        rooms:
+          - name: room-one
+            direction: north
+`
+
+func TestImport(t *testing.T) {
+	t.Run("test POST /import", func(t *testing.T) {
+		t.Run("should report the changes without persisting for dry run", func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockKVStore := mockStore.NewMockStore(ctrl)
+			mockKVStore.EXPECT().Tree().Return(gateway.NewTree(), nil)
+
+			request, err := http.NewRequest("POST", "http://test/v1/import?format=yaml&dry-run=true", bytes.NewReader([]byte(homeYAML)))
+			if err != nil {
+				t.Error(err)
+			}
+
+			res, err := testutils.ServeHTTPRequest(mockKVStore, request)
+			assert.NoError(t, err)
+			assert.Equal(t, fasthttp.StatusOK, res.StatusCode)
+
+			var actual home.Plan
+			err = testutils.Read(res, &actual)
+			if assert.NoError(t, err) {
+				assert.True(t, actual.DryRun)
+				assert.Equal(t, home.ModeMerge, actual.Mode)
+				assert.Equal(t, 3, actual.Count(home.ActionCreate))
+			}
+		})
+
+		t.Run("should persist the imported home", func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockKVStore := mockStore.NewMockStore(ctrl)
+			mockKVStore.EXPECT().Tree().Return(gateway.NewTree(), nil)
+			mockKVStore.EXPECT().UpsertBuildings(gomock.Any()).Return(nil)
+			mockKVStore.EXPECT().UpsertFloors(gomock.Any(), gomock.Any()).Return(nil)
+			mockKVStore.EXPECT().UpsertRooms(gomock.Any(), gomock.Any()).Return(nil)
+			mockKVStore.EXPECT().UpsertDevices(gomock.Any(), gateway.Devices{}).Return(nil)
+			mockKVStore.EXPECT().UpsertNodes(gomock.Any(), gateway.Nodes{}).Return(nil)
+
+			request, err := http.NewRequest("POST", "http://test/v1/import?format=yaml&mode=replace", bytes.NewReader([]byte(homeYAML)))
+			if err != nil {
+				t.Error(err)
+			}
+
+			res, err := testutils.ServeHTTPRequest(mockKVStore, request)
+			assert.NoError(t, err)
+			assert.Equal(t, fasthttp.StatusOK, res.StatusCode)
+		})
+
+		t.Run("should get 400 for unknown mode", func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockKVStore := mockStore.NewMockStore(ctrl)
+
+			request, err := http.NewRequest("POST", "http://test/v1/import?mode=sync", bytes.NewReader([]byte("{}")))
+			if err != nil {
+				t.Error(err)
+			}
+
+			res, err := testutils.ServeHTTPRequest(mockKVStore, request)
+			assert.NoError(t, err)
+			assert.Equal(t, fasthttp.StatusBadRequest, res.StatusCode)
+		})
+
+		t.Run("should get 400 for invalid entity", func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockKVStore := mockStore.NewMockStore(ctrl)
+
+			body := `{"buildings": [{"name": "one", "latitude": 1, "longitude": 1}]}`
+			request, err := http.NewRequest("POST", "http://test/v1/import", bytes.NewReader([]byte(body)))
+			if err != nil {
+				t.Error(err)
+			}
+
+			res, err := testutils.ServeHTTPRequest(mockKVStore, request)
+			assert.NoError(t, err)
+			assert.Equal(t, fasthttp.StatusBadRequest, res.StatusCode)
+
+			message, err := testutils.ReadError(res)
+			if assert.NoError(t, err) {
+				assert.Equal(t, "building one: name: the length must be between 5 and 50.", message)
+			}
+		})
+	})
+}
+
+func TestExport(t *testing.T) {
+	t.Run("test GET /export", func(t *testing.T) {
+		t.Run("should export the home as json", func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockKVStore := mockStore.NewMockStore(ctrl)
+			mockKVStore.EXPECT().Tree().Return(newTree(), nil)
+
+			request, err := http.NewRequest("GET", "http://test/v1/export", nil)
+			if err != nil {
+				t.Error(err)
+			}
+
+			res, err := testutils.ServeHTTPRequest(mockKVStore, request)
+			assert.NoError(t, err)
+			assert.Equal(t, fasthttp.StatusOK, res.StatusCode)
+
+			var actual home.Home
+			err = testutils.Read(res, &actual)
+			if assert.NoError(t, err) {
+				assert.Equal(t, home.NewHome(newTree()), actual)
+			}
+		})
+
+		t.Run("should export the home as yaml", func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockKVStore := mockStore.NewMockStore(ctrl)
+			mockKVStore.EXPECT().Tree().Return(newTree(), nil)
+
+			request, err := http.NewRequest("GET", "http://test/v1/export?format=yaml", nil)
+			if err != nil {
+				t.Error(err)
+			}
+
+			res, err := testutils.ServeHTTPRequest(mockKVStore, request)
+			assert.NoError(t, err)
+			assert.Equal(t, fasthttp.StatusOK, res.StatusCode)
+			assert.Equal(t, "application/yaml", res.Header.Get("Content-Type"))
+
+			data, err := io.ReadAll(res.Body)
+			if assert.NoError(t, err) {
+				actual, err := home.Decode(data, home.FormatYAML)
+				assert.NoError(t, err)
+				assert.Equal(t, home.NewHome(newTree()), actual)
+			}
+		})
+	})
+}
@@ -0,0 +1,127 @@
+package api
+
+import (
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/valyala/fasthttp"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/server"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/auth"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/metrics"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/store"
+)
+
+const metricsPath = "/metrics"
+
+// onlineWindow is how long a device is online after it was last seen i.e.
+// switched, reporting its state or publishing its readings
+const onlineWindow = 10 * time.Minute
+
+func init() {
+	AddUnversionedRoute(
+		server.NewRouteWithFilters("GET", metricsPath, metricsHandler, authorized(auth.Read)).Describe(server.Documentation{
+			Summary: "Metrics of the requests, the store, the nodes, the mqtt broker and messages and the devices in the Prometheus text format",
+			Tags:    []string{"meta"},
+		}),
+	)
+}
+
+// lastSeen holds the time the devices, given as building-id/floor-id/
+// room-id/device-id, and the mqtt bridges of the buildings were last seen
+// along with the messages forwarded by the bridges
+type lastSeen struct {
+	mu       sync.Mutex
+	at       map[string]time.Time
+	messages map[string]float64
+}
+
+var (
+	devicesSeen = newLastSeen()
+	bridgesSeen = newLastSeen()
+)
+
+func newLastSeen() *lastSeen {
+	return &lastSeen{at: map[string]time.Time{}, messages: map[string]float64{}}
+}
+
+func (seen *lastSeen) mark(key string, at time.Time) {
+	seen.mu.Lock()
+	defer seen.mu.Unlock()
+	seen.at[key] = at
+	seen.messages[key]++
+}
+
+func (seen *lastSeen) get(key string) (time.Time, float64, bool) {
+	seen.mu.Lock()
+	defer seen.mu.Unlock()
+	at, ok := seen.at[key]
+	return at, seen.messages[key], ok
+}
+
+// seenDevice marks the device as online
+func seenDevice(device gateway.Device) {
+	floor := device.Room.Floor
+	if floor.Building == nil {
+		return
+	}
+	devicesSeen.mark(path.Join(floor.Building.ID(), floor.ID(), device.Room.ID(), device.ID()), time.Now())
+}
+
+func gaugeOf(value bool) float64 {
+	if value {
+		return 1
+	}
+	return 0
+}
+
+var metricsHandler = func(store store.Store, ctx server.RequestContext) error {
+	tree, err := store.Tree()
+	if err != nil {
+		return internalServerError(ctx, err)
+	}
+	tree = visibleTree(ctx, tree)
+
+	// the metrics of the buildings are collected on every scrape so that
+	// they hold only the entities visible to the token
+	now := time.Now()
+	scrape := metrics.NewRegistry()
+	if mqttClient != nil {
+		scrape.NewGauge("dwarka_mqtt_bridge_connected",
+			"Whether the server is connected to the mqtt broker", "broker").Set(gaugeOf(mqttClient.Connected()), mqttClient.Broker())
+	}
+	lastMessage := scrape.NewGauge("dwarka_mqtt_last_message_timestamp_seconds",
+		"Unix time of the last mqtt message forwarded by the bridge of the building", "building")
+	messages := scrape.NewCounter("dwarka_mqtt_messages_total",
+		"MQTT messages forwarded by the bridge of the building since the server started", "building")
+	online := scrape.NewGauge("dwarka_device_online",
+		"Whether the device was switched, reported its state or published readings in the last 10 minutes", "building", "floor", "room", "device")
+	for id, building := range tree.Buildings {
+		at, count, ok := bridgesSeen.get(id)
+		if ok {
+			lastMessage.Set(float64(at.UnixNano())/1e9, id)
+			messages.Add(count, id)
+		}
+		for floorID, floor := range tree.FloorsOf(building) {
+			for roomID, room := range tree.RoomsOf(floor) {
+				for deviceID := range tree.DevicesOf(room) {
+					at, _, ok := devicesSeen.get(path.Join(id, floorID, roomID, deviceID))
+					online.Set(gaugeOf(ok && now.Sub(at) < onlineWindow), id, floorID, roomID, deviceID)
+				}
+			}
+		}
+	}
+
+	builder := &strings.Builder{}
+	for _, registry := range []*metrics.Registry{metrics.Default, scrape} {
+		if _, err := registry.WriteTo(builder); err != nil {
+			return internalServerError(ctx, err)
+		}
+	}
+	ctx.SetContentType(metrics.ContentType)
+	ctx.SetStatusCode(fasthttp.StatusOK)
+	ctx.SetBodyString(builder.String())
+	return nil
+}
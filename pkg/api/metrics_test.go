@@ -0,0 +1,121 @@
+package api_test
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/auth"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/mqtt"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/store"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/testutils"
+)
+
+// scrape returns the metrics served to the api key
+func scrape(t *testing.T, persistentStore store.Store, apiKey string) (int, string) {
+	request, err := http.NewRequest("GET", "http://test/metrics", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	request.Header.Set("X-API-Key", apiKey)
+	res, err := testutils.ServeHTTPRequest(persistentStore, request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := io.ReadAll(res.Body)
+	assert.NoError(t, err)
+	return res.StatusCode, string(body)
+}
+
+func TestMetrics(t *testing.T) {
+	t.Run("should count the requests by route template including the rejected ones", func(t *testing.T) {
+		persistentStore := enableAuthentication(t)
+		seedStore(t, persistentStore)
+		viewer := issueToken(t, persistentStore, auth.RoleViewer)
+
+		assert.Equal(t, fasthttp.StatusOK, serveAs(t, persistentStore, viewer, "GET", "/buildings/building-one").StatusCode)
+		assert.Equal(t, fasthttp.StatusNotFound, serveAs(t, persistentStore, viewer, "GET", "/buildings/building-three").StatusCode)
+		assert.Equal(t, fasthttp.StatusUnauthorized, serveAs(t, persistentStore, "", "GET", "/buildings/building-one").StatusCode)
+		status, body := scrape(t, persistentStore, viewer)
+
+		assert.Equal(t, fasthttp.StatusOK, status)
+		assert.Contains(t, body, "# TYPE dwarka_http_requests_total counter\n")
+		assert.Contains(t, body, `dwarka_http_requests_total{method="GET",route="/v1/buildings/{building-id}",status="200"}`)
+		assert.Contains(t, body, `dwarka_http_requests_total{method="GET",route="/v1/buildings/{building-id}",status="404"}`)
+		assert.Contains(t, body, `dwarka_http_requests_total{method="GET",route="/v1/buildings/{building-id}",status="401"}`)
+		assert.Contains(t, body, `dwarka_http_request_duration_seconds_bucket{method="GET",route="/v1/buildings/{building-id}",le="+Inf"}`)
+		assert.NotContains(t, body, "building-three")
+	})
+
+	t.Run("should report the devices and the mqtt bridges seen recently", func(t *testing.T) {
+		persistentStore := enableAuthentication(t)
+		seedStore(t, persistentStore)
+		enableTelemetry(t)
+		operator := issueToken(t, persistentStore, auth.RoleOperator)
+		scoped := issueToken(t, persistentStore, auth.RoleViewer, "building-two")
+
+		res := serveBodyAs(t, persistentStore, operator, "POST", "/buildings/building-one/events",
+			`{"kind":"mqtt","topic":"tele/floor-one/room-one/porch-light/power","payload":"40"}`)
+		assert.Equal(t, fasthttp.StatusAccepted, res.StatusCode)
+		_, body := scrape(t, persistentStore, operator)
+
+		assert.Contains(t, body, `dwarka_device_online{building="building-one",floor="floor-one",room="room-one",device="porch-light"} 1`)
+		assert.Contains(t, body, `dwarka_mqtt_last_message_timestamp_seconds{building="building-one"} `)
+		assert.Contains(t, body, `dwarka_mqtt_messages_total{building="building-one"} `)
+		assert.NotContains(t, body, `building="building-two"} `)
+		assert.NotContains(t, body, "dwarka_mqtt_bridge_connected")
+
+		_, body = scrape(t, persistentStore, scoped)
+		assert.NotContains(t, body, `building="building-one"`)
+	})
+
+	t.Run("should report the connection to the mqtt broker", func(t *testing.T) {
+		persistentStore := enableAuthentication(t)
+		viewer := issueToken(t, persistentStore, auth.RoleViewer)
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer listener.Close()
+		client, err := mqtt.NewClient(mqtt.Options{Broker: "tcp://" + listener.Addr().String(), Topics: api.MQTTTopics("dwarka")})
+		if err != nil {
+			t.Fatal(err)
+		}
+		api.EnableMQTT(client)
+		defer api.EnableMQTT(nil)
+		connected := fmt.Sprintf(`dwarka_mqtt_bridge_connected{broker="%s"} `, listener.Addr())
+
+		_, body := scrape(t, persistentStore, viewer)
+		assert.Contains(t, body, connected+"0\n")
+
+		stop := make(chan struct{})
+		defer close(stop)
+		go client.Run(func(mqtt.Message) {}, stop)
+		conn, err := listener.Accept()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+		// the broker accepts the connection without reading the connect
+		_, err = conn.Write([]byte{0x20, 2, 0, 0})
+		assert.NoError(t, err)
+		assert.Eventually(t, client.Connected, time.Second, 10*time.Millisecond)
+
+		_, body = scrape(t, persistentStore, viewer)
+		assert.Contains(t, body, connected+"1\n")
+	})
+
+	t.Run("should require a token when the authentication is enabled", func(t *testing.T) {
+		persistentStore := enableAuthentication(t)
+
+		status, _ := scrape(t, persistentStore, "")
+
+		assert.Equal(t, fasthttp.StatusUnauthorized, status)
+	})
+}
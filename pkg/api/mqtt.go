@@ -0,0 +1,72 @@
+package api
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/automation"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/mqtt"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/store"
+)
+
+// mqttClient is the subscriber to the broker, nil when no broker is given
+var mqttClient *mqtt.Client
+
+// EnableMQTT sets the subscriber to the broker whose connection is
+// reported in the metrics
+func EnableMQTT(client *mqtt.Client) {
+	mqttClient = client
+}
+
+// MQTTTopics returns the topics of the buildings under the prefix
+func MQTTTopics(prefix string) []string {
+	return []string{strings.TrimSuffix(prefix, "/") + "/#"}
+}
+
+// MQTTHandler returns the handler of the messages published by the bridges
+// on the topics of the buildings i.e. prefix/building-id/topic, a message
+// is handled as the mqtt event of the topic posted to the building i.e. the
+// readings on the tele/ topics are stored and the automations see it. The
+// messages of the unknown buildings and the invalid ones are dropped and so
+// are the retained ones, the broker sends them again on every connection
+func MQTTHandler(store store.Store, prefix string) mqtt.Handler {
+	prefix = strings.TrimSuffix(prefix, "/") + "/"
+	return func(message mqtt.Message) {
+		if message.Retained {
+			return
+		}
+		ids := strings.SplitN(strings.TrimPrefix(message.Topic, prefix), "/", 2)
+		if !strings.HasPrefix(message.Topic, prefix) || len(ids) != 2 {
+			log.Printf("dropped mqtt message on %s, the topic should be given as %sbuilding-id/topic", message.Topic, prefix)
+			return
+		}
+		buildings, err := store.Buildings()
+		if err != nil {
+			log.Printf("dropped mqtt message on %s, reason: %v", message.Topic, err)
+			return
+		}
+		if _, ok := buildings[ids[0]]; !ok {
+			log.Printf("dropped mqtt message on %s, building %s not found", message.Topic, ids[0])
+			return
+		}
+
+		event := automation.Event{Kind: automation.EventMQTT, Building: ids[0], Topic: ids[1], Payload: string(message.Payload)}
+		if err := event.Validate(); err != nil {
+			log.Printf("dropped mqtt message on %s, reason: %v", message.Topic, err)
+			return
+		}
+		bridgesSeen.mark(event.Building, time.Now())
+		// the readings are stored before the automations see the message
+		readings, err := readingsOf(event)
+		if err == nil {
+			err = appendReadings(readings)
+		}
+		if err != nil {
+			log.Printf("unable to store the readings of the mqtt message on %s, reason: %v", message.Topic, err)
+		}
+		if engine != nil && !engine.Publish(event) {
+			log.Printf("dropped mqtt message on %s, automation queue is full", message.Topic)
+		}
+	}
+}
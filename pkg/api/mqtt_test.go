@@ -0,0 +1,75 @@
+package api_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/view"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/auth"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/automation"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/mqtt"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/testutils"
+)
+
+func TestMQTTTopics(t *testing.T) {
+	assert.Equal(t, []string{"dwarka/#"}, api.MQTTTopics("dwarka"))
+	assert.Equal(t, []string{"home/dwarka/#"}, api.MQTTTopics("home/dwarka/"))
+}
+
+func TestMQTTHandler(t *testing.T) {
+	t.Run("should publish the messages of the buildings to the automations", func(t *testing.T) {
+		persistentStore := enableAuthentication(t)
+		node := seedScenes(t, persistentStore)
+		editor := issueToken(t, persistentStore, auth.RoleEditor)
+		res := serveBodyAs(t, persistentStore, editor, "POST", automationsURL, `{"name":"lamp on motion",
+			"triggers":[{"type":"mqtt","topic":"hall/+/motion","payload":"on"}],
+			"actions":[{"type":"device","device":"floor-one/room-one/broken-lamp","state":"on"}]}`)
+		assert.Equal(t, fasthttp.StatusCreated, res.StatusCode)
+
+		engine := automation.NewEngine(persistentStore, automation.NewManualClock(time.Now()))
+		api.EnableAutomation(engine)
+		stop := make(chan struct{})
+		t.Cleanup(func() {
+			close(stop)
+			api.EnableAutomation(nil)
+		})
+		go engine.Run(stop)
+		handle := api.MQTTHandler(persistentStore, "dwarka/")
+
+		for _, message := range []mqtt.Message{
+			{Topic: "dwarka/building-one/hall/door/motion", Payload: []byte("on"), Retained: true},
+			{Topic: "dwarka/building-three/hall/door/motion", Payload: []byte("on")},
+			{Topic: "dwarka/building-one", Payload: []byte("on")},
+			{Topic: "home/building-one/hall/door/motion", Payload: []byte("on")},
+			{Topic: "dwarka/building-one/hall/door/motion", Payload: []byte("off")},
+			{Topic: "dwarka/building-one/hall/door/motion", Payload: []byte("on")},
+		} {
+			handle(message)
+		}
+
+		assert.Eventually(t, func() bool { return len(node.Switched()) == 1 }, time.Second, 10*time.Millisecond)
+		assert.Equal(t, []string{"on:broken-lamp"}, node.Switched())
+	})
+
+	t.Run("should store the readings published on the tele topics", func(t *testing.T) {
+		persistentStore := enableAuthentication(t)
+		seedStore(t, persistentStore)
+		enableTelemetry(t)
+		viewer := issueToken(t, persistentStore, auth.RoleViewer)
+		handle := api.MQTTHandler(persistentStore, "dwarka")
+
+		handle(mqtt.Message{Topic: "dwarka/building-one/tele/floor-one/room-one/porch-light/SENSOR", Payload: []byte(`{"Power":40}`)})
+		handle(mqtt.Message{Topic: "dwarka/building-one/tele/floor-one/room-one/porch-light/power", Payload: []byte("NaN")})
+		handle(mqtt.Message{Topic: "dwarka/building-one/tele/floor-one/room-one/porch-light/power", Payload: []byte("60")})
+
+		res := serveAs(t, persistentStore, viewer, "GET", metricsURL+"/power?from=2026-10-19T06:00:00Z&to=2026-10-19T07:00:00Z&step=1h")
+		metric := view.Metric{}
+		if assert.NoError(t, testutils.Read(res, &metric)) && assert.Len(t, metric.Points, 1) {
+			assert.Equal(t, int64(2), metric.Points[0].Count)
+			assert.Equal(t, 60.0, metric.Points[0].Last)
+		}
+	})
+}
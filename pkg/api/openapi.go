@@ -0,0 +1,38 @@
+package api
+
+import (
+	"github.com/valyala/fasthttp"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/openapi"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/server"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/store"
+)
+
+const (
+	openAPIPath  = "/openapi.json"
+	openAPITitle = "dwarka"
+)
+
+func init() {
+	AddUnversionedRoute(
+		server.NewRoute("GET", openAPIPath, openAPIHandler).Describe(server.Documentation{
+			Summary:  "OpenAPI specification of the REST API",
+			Tags:     []string{"meta"},
+			Response: map[string]interface{}{},
+		}).Public(),
+	)
+}
+
+// OpenAPI returns the OpenAPI document describing every known route
+func OpenAPI() *openapi.Document {
+	versions := Versions()
+	document := openapi.New(openAPITitle, versions[len(versions)-1])
+	document.Add("", Routes(Unversioned)...)
+	for _, version := range versions {
+		document.Add("/"+version, Routes(version)...)
+	}
+	return document
+}
+
+var openAPIHandler = func(_ store.Store, ctx server.RequestContext) error {
+	return ctx.JSONResponse(OpenAPI(), fasthttp.StatusOK)
+}
@@ -0,0 +1,251 @@
+package openapi
+
+import (
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/server"
+)
+
+const (
+	// Version is the version of the OpenAPI specification the document conforms to
+	Version = "3.0.3"
+
+	errorSchema = "Error"
+
+	bearerScheme = "bearer"
+	apiKeyScheme = "apiKey"
+)
+
+var pathParameter = regexp.MustCompile(`{([^}]+)}`)
+
+// Document represents an OpenAPI 3 document
+type Document struct {
+	OpenAPI    string                `json:"openapi"`
+	Info       Info                  `json:"info"`
+	Paths      map[string]PathItem   `json:"paths"`
+	Components Components            `json:"components"`
+	Security   []SecurityRequirement `json:"security"`
+	schemas    *generator
+}
+
+// Info provides metadata about the API
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// PathItem describes the operations available on a single path
+// keyed by lower case http method
+type PathItem map[string]Operation
+
+// Operation describes a single API operation on a path
+type Operation struct {
+	Summary     string              `json:"summary,omitempty"`
+	Tags        []string            `json:"tags,omitempty"`
+	OperationID string              `json:"operationId"`
+	Parameters  []Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]Response `json:"responses"`
+	// Security is empty for the operations served without authentication,
+	// nil for the ones requiring the security of the document
+	Security *[]SecurityRequirement `json:"security,omitempty"`
+}
+
+// SecurityRequirement lists the security schemes required by an operation
+// keyed by the name of the scheme
+type SecurityRequirement map[string][]string
+
+// SecurityScheme describes a scheme to authenticate the requests
+type SecurityScheme struct {
+	Type   string `json:"type"`
+	Scheme string `json:"scheme,omitempty"`
+	In     string `json:"in,omitempty"`
+	Name   string `json:"name,omitempty"`
+}
+
+// Parameter describes a single operation parameter
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody describes a single request body
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response describes a single response from an API Operation
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType provides schema for the media type
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components holds the reusable schemas referred by the document
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes"`
+}
+
+// New returns an empty Document with the given title and version
+func New(title, version string) *Document {
+	schemas := map[string]*Schema{
+		errorSchema: {
+			Type:       "object",
+			Properties: map[string]*Schema{"error": {Type: "string"}},
+		},
+	}
+	return &Document{
+		OpenAPI: Version,
+		Info:    Info{Title: title, Version: version},
+		Paths:   map[string]PathItem{},
+		Components: Components{Schemas: schemas, SecuritySchemes: map[string]SecurityScheme{
+			bearerScheme: {Type: "http", Scheme: "bearer"},
+			apiKeyScheme: {Type: "apiKey", In: "header", Name: "X-API-Key"},
+		}},
+		Security: []SecurityRequirement{{bearerScheme: {}}, {apiKeyScheme: {}}},
+		schemas:  newGenerator(schemas),
+	}
+}
+
+// Add documents the routes under the given prefix, routes without
+// documentation are skipped
+func (document *Document) Add(prefix string, routes ...server.Route) {
+	for _, route := range routes {
+		documentation := route.Documentation()
+		if documentation == nil {
+			continue
+		}
+
+		url := prefix + route.URL()
+		item, ok := document.Paths[url]
+		if !ok {
+			item = PathItem{}
+			document.Paths[url] = item
+		}
+		op := operation(document.schemas, route.Method(), url, *documentation)
+		if route.IsPublic() {
+			op.Security = &[]SecurityRequirement{}
+		}
+		item[strings.ToLower(route.Method())] = op
+	}
+}
+
+// Documented returns true when the document has an operation for method and url
+func (document *Document) Documented(method, url string) bool {
+	item, ok := document.Paths[url]
+	if !ok {
+		return false
+	}
+	_, ok = item[strings.ToLower(method)]
+	return ok
+}
+
+func operation(schemas *generator, method, url string, documentation server.Documentation) Operation {
+	op := Operation{
+		Summary:     documentation.Summary,
+		Tags:        documentation.Tags,
+		OperationID: operationID(method, url),
+		Parameters:  parameters(url, documentation.Query),
+		Responses:   map[string]Response{},
+	}
+
+	if documentation.Request != nil {
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content:  jsonContent(schemas.schema(documentation.Request)),
+		}
+	}
+
+	status := documentation.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	response := Response{Description: http.StatusText(status)}
+	if documentation.Response != nil {
+		response.Content = jsonContent(schemas.schema(documentation.Response))
+	}
+	op.Responses[strconv.Itoa(status)] = response
+
+	for _, code := range errorCodes(url, documentation) {
+		op.Responses[strconv.Itoa(code)] = Response{
+			Description: http.StatusText(code),
+			Content:     jsonContent(reference(errorSchema)),
+		}
+	}
+	return op
+}
+
+func errorCodes(url string, documentation server.Documentation) []int {
+	codes := map[int]bool{http.StatusInternalServerError: true}
+	if pathParameter.MatchString(url) {
+		codes[http.StatusNotFound] = true
+	}
+	if documentation.Request != nil {
+		codes[http.StatusBadRequest] = true
+	}
+	for _, code := range documentation.Errors {
+		codes[code] = true
+	}
+
+	result := make([]int, 0, len(codes))
+	for code := range codes {
+		result = append(result, code)
+	}
+	sort.Ints(result)
+	return result
+}
+
+func parameters(url string, query map[string]string) []Parameter {
+	var result []Parameter
+	for _, match := range pathParameter.FindAllStringSubmatch(url, -1) {
+		result = append(result, Parameter{
+			Name:     match[1],
+			In:       "path",
+			Required: true,
+			Schema:   &Schema{Type: "string"},
+		})
+	}
+
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		result = append(result, Parameter{
+			Name:        name,
+			In:          "query",
+			Description: query[name],
+			Schema:      &Schema{Type: "string"},
+		})
+	}
+	return result
+}
+
+func operationID(method, url string) string {
+	parts := []string{strings.ToLower(method)}
+	for _, part := range strings.Split(url, "/") {
+		part = strings.Trim(part, "{}")
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, "-")
+}
+
+func jsonContent(schema *Schema) map[string]MediaType {
+	return map[string]MediaType{"application/json": {Schema: schema}}
+}
@@ -0,0 +1,85 @@
+package openapi_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/openapi"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/server"
+)
+
+type parent struct {
+	Name string `json:"name"`
+}
+
+type child struct {
+	ID      string            `json:"id"`
+	Count   int               `json:"count"`
+	Ratio   float64           `json:"ratio"`
+	Meta    map[string]string `json:"meta"`
+	Parents []parent          `json:"parents"`
+	Ignored string            `json:"-"`
+	parent
+}
+
+func TestDocument_Add(t *testing.T) {
+
+	document := openapi.New("test", "v1")
+	document.Add("/v1",
+		server.NewRoute("POST", "/children/{child-id}", nil).Describe(server.Documentation{
+			Summary:  "Create child",
+			Query:    map[string]string{"depth": "nesting depth"},
+			Request:  child{},
+			Response: []child{},
+			Status:   201,
+			Errors:   []int{409},
+		}),
+		server.NewRoute("GET", "/undocumented", nil),
+		server.NewRoute("GET", "/ping", nil).Public().Describe(server.Documentation{Summary: "Ping"}),
+	)
+
+	t.Run("should skip routes without documentation", func(t *testing.T) {
+		assert.False(t, document.Documented("GET", "/v1/undocumented"))
+	})
+
+	t.Run("should document route with parameters and responses", func(t *testing.T) {
+		if !assert.True(t, document.Documented("POST", "/v1/children/{child-id}")) {
+			return
+		}
+		operation := document.Paths["/v1/children/{child-id}"]["post"]
+
+		assert.Equal(t, "post-v1-children-child-id", operation.OperationID)
+		assert.Equal(t, []openapi.Parameter{
+			{Name: "child-id", In: "path", Required: true, Schema: &openapi.Schema{Type: "string"}},
+			{Name: "depth", In: "query", Description: "nesting depth", Schema: &openapi.Schema{Type: "string"}},
+		}, operation.Parameters)
+		assert.Equal(t, "#/components/schemas/child", operation.RequestBody.Content["application/json"].Schema.Ref)
+
+		var codes []string
+		for code := range operation.Responses {
+			codes = append(codes, code)
+		}
+		assert.ElementsMatch(t, []string{"201", "400", "404", "409", "500"}, codes)
+	})
+
+	t.Run("should not require security for public routes", func(t *testing.T) {
+		assert.Nil(t, document.Paths["/v1/children/{child-id}"]["post"].Security)
+		assert.Equal(t, &[]openapi.SecurityRequirement{}, document.Paths["/v1/ping"]["get"].Security)
+		assert.Contains(t, document.Components.SecuritySchemes, "bearer")
+	})
+
+	t.Run("should derive schema from struct fields", func(t *testing.T) {
+		schema := document.Components.Schemas["child"]
+		if !assert.NotNil(t, schema) {
+			return
+		}
+
+		assert.Equal(t, &openapi.Schema{Type: "string"}, schema.Properties["id"])
+		assert.Equal(t, &openapi.Schema{Type: "integer", Format: "int32"}, schema.Properties["count"])
+		assert.Equal(t, &openapi.Schema{Type: "number", Format: "double"}, schema.Properties["ratio"])
+		assert.Equal(t, &openapi.Schema{Type: "object", AdditionalProperties: &openapi.Schema{Type: "string"}}, schema.Properties["meta"])
+		assert.Equal(t, "#/components/schemas/parent", schema.Properties["parents"].Items.Ref)
+		assert.Equal(t, &openapi.Schema{Type: "string"}, schema.Properties["name"])
+		assert.NotContains(t, schema.Properties, "Ignored")
+	})
+}
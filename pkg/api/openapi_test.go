@@ -0,0 +1,54 @@
+package api_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/openapi"
+	mockStore "gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/internal/mocks/store"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/testutils"
+)
+
+func TestOpenAPI(t *testing.T) {
+	t.Run("should document every registered route", func(t *testing.T) {
+		document := api.OpenAPI()
+
+		for _, version := range append(api.Versions(), api.Unversioned) {
+			prefix := ""
+			if version != api.Unversioned {
+				prefix = "/" + version
+			}
+			for _, route := range api.Routes(version) {
+				assert.NotNil(t, route.Documentation(), "%s %s is not documented", route.Method(), route.URL())
+				assert.True(t, document.Documented(route.Method(), prefix+route.URL()),
+					"%s %s is missing in specification", route.Method(), prefix+route.URL())
+			}
+		}
+	})
+
+	t.Run("should serve the specification at /openapi.json", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockKVStore := mockStore.NewMockStore(ctrl)
+
+		request, err := http.NewRequest("GET", "http://test/openapi.json", nil)
+		if err != nil {
+			t.Error(err)
+		}
+
+		res, err := testutils.ServeHTTPRequest(mockKVStore, request)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		actual := openapi.Document{}
+		err = testutils.Read(res, &actual)
+		if assert.NoError(t, err) {
+			assert.Equal(t, openapi.Version, actual.OpenAPI)
+			assert.True(t, actual.Documented("GET", "/v1/buildings/{building-id}"))
+			assert.Contains(t, actual.Components.Schemas, "Building")
+		}
+	})
+}
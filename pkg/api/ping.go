@@ -4,11 +4,16 @@ import (
 	"fmt"
 	"github.com/valyala/fasthttp"
 	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/server"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
 	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/store"
 )
 
 func init() {
-	AddRoute(server.NewRoute("GET", "/ping", pingHandler))
+	AddRoute(server.NewRoute("GET", "/ping", pingHandler).Describe(server.Documentation{
+		Summary:  "Server status along with start time",
+		Tags:     []string{"meta"},
+		Response: map[string]gateway.Status{},
+	}).Public())
 }
 
 var pingHandler = func(store store.Store, ctx server.RequestContext) error {
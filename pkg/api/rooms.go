@@ -8,6 +8,7 @@ import (
 	"github.com/valyala/fasthttp"
 	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/server"
 	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/view"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/auth"
 	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
 	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/store"
 )
@@ -27,14 +28,25 @@ func roomsBasePath() string {
 }
 
 func init() {
-	roomFilters := &server.Filters{Before: []server.ResponseHandler{findAndLoadRoom}}
-	floorFilters := &server.Filters{Before: []server.ResponseHandler{findAndLoadFloor}}
+	tags := []string{"rooms"}
 	AddRoute(
-		server.NewRouteWithFilters("GET", roomsBasePath(), listRoomsHandler, floorFilters),
-		server.NewRouteWithFilters("POST", roomsBasePath(), createRoomHandler, floorFilters),
-		server.NewRouteWithFilters("GET", roomPath(), getRoomHandler, roomFilters),
-		server.NewRouteWithFilters("PUT", roomPath(), updateRoomHandler, roomFilters),
-		server.NewRouteWithFilters("DELETE", roomPath(), deleteRoomHandler, roomFilters),
+		server.NewRouteWithFilters("GET", roomsBasePath(), listRoomsHandler, authorized(auth.Read, findAndLoadFloor)).Describe(server.Documentation{
+			Summary: "List rooms of the floor", Tags: tags, Query: selectorQuery, Response: []view.Room{},
+			Errors: []int{fasthttp.StatusBadRequest},
+		}),
+		server.NewRouteWithFilters("POST", roomsBasePath(), createRoomHandler, authorized(auth.Write, findAndLoadFloor)).Describe(server.Documentation{
+			Summary: "Create room in the floor", Tags: tags, Request: view.Room{}, Response: map[string]string{},
+			Status: fasthttp.StatusCreated, Errors: []int{fasthttp.StatusConflict},
+		}),
+		server.NewRouteWithFilters("GET", roomPath(), getRoomHandler, authorized(auth.Read, findAndLoadRoom)).Describe(server.Documentation{
+			Summary: "Get room", Tags: tags, Response: view.Room{},
+		}),
+		server.NewRouteWithFilters("PUT", roomPath(), updateRoomHandler, authorized(auth.Write, findAndLoadRoom)).Describe(server.Documentation{
+			Summary: "Update room", Tags: tags, Request: view.Room{},
+		}),
+		server.NewRouteWithFilters("DELETE", roomPath(), deleteRoomHandler, authorized(auth.Write, findAndLoadRoom)).Describe(server.Documentation{
+			Summary: "Delete room", Tags: tags,
+		}),
 	)
 }
 
@@ -88,10 +100,21 @@ var listRoomsHandler = func(store store.Store, ctx server.RequestContext) error
 		return notFound(ctx)
 	}
 
+	selector, err := labelSelector(ctx)
+	if err != nil {
+		return badRequest(ctx, err)
+	}
+
 	rooms, err := store.Rooms(floor)
 	if err != nil {
 		return internalServerError(ctx, err)
 	}
+
+	for id, room := range rooms {
+		if !visible(ctx, append(pathResource(ctx), id)...) || !selector.Matches(room.Labels) {
+			delete(rooms, id)
+		}
+	}
 	return ctx.JSONResponse(view.NewRooms(rooms), http.StatusOK)
 }
 
@@ -101,7 +124,7 @@ var createRoomHandler = func(store store.Store, ctx server.RequestContext) error
 		return notFound(ctx)
 	}
 
-	room, err := view.Convert(floor, ctx.PostBody())
+	room, err := view.ConvertRoom(floor, ctx.PostBody())
 	if err != nil {
 		return badRequest(ctx, err)
 	}
@@ -138,7 +161,7 @@ var updateRoomHandler = func(store store.Store, ctx server.RequestContext) error
 		return notFound(ctx)
 	}
 
-	room, err := view.Convert(floor, ctx.PostBody())
+	room, err := view.ConvertRoom(floor, ctx.PostBody())
 	if err != nil {
 		return badRequest(ctx, err)
 	}
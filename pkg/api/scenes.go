@@ -0,0 +1,327 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/valyala/fasthttp"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/server"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/view"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/auth"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/store"
+)
+
+const (
+	sceneID      = "scene-id"
+	sceneUserKey = "scene"
+)
+
+func scenePath() string {
+	return path.Join(scenesBasePath(), fmt.Sprintf("{%s}", sceneID))
+}
+
+func scenesBasePath() string {
+	return path.Join(buildingPath(), "scenes")
+}
+
+func init() {
+	tags := []string{"scenes"}
+	AddRoute(
+		server.NewRouteWithFilters("GET", scenesBasePath(), listScenesHandler, authorized(auth.Read, findAndLoadBuilding)).Describe(server.Documentation{
+			Summary: "List scenes of the building", Tags: tags, Query: selectorQuery, Response: []view.Scene{},
+			Errors: []int{fasthttp.StatusBadRequest},
+		}),
+		server.NewRouteWithFilters("POST", scenesBasePath(), createSceneHandler, authorizedInRoom(auth.Write, findAndLoadBuilding)).Describe(server.Documentation{
+			Summary: "Create scene in the building", Tags: tags, Request: view.Scene{}, Response: map[string]string{},
+			Status: fasthttp.StatusCreated, Errors: []int{fasthttp.StatusConflict},
+		}),
+		server.NewRouteWithFilters("POST", path.Join(scenesBasePath(), "capture"), captureSceneHandler, authorizedInRoom(auth.Write, findAndLoadBuilding)).Describe(server.Documentation{
+			Summary: "Create scene from the reported state of the devices", Tags: tags, Request: view.Capture{}, Response: map[string]string{},
+			Status: fasthttp.StatusCreated, Errors: []int{fasthttp.StatusConflict},
+		}),
+		server.NewRouteWithFilters("GET", scenePath(), getSceneHandler, authorizedInRoom(auth.Read, findAndLoadScene, authorizeScene(auth.Read))).Describe(server.Documentation{
+			Summary: "Get scene", Tags: tags, Response: view.Scene{},
+		}),
+		server.NewRouteWithFilters("PUT", scenePath(), updateSceneHandler, authorizedInRoom(auth.Write, findAndLoadScene, authorizeScene(auth.Write))).Describe(server.Documentation{
+			Summary: "Update scene, a new name renames it", Tags: tags, Request: view.Scene{}, Errors: []int{fasthttp.StatusConflict},
+		}),
+		server.NewRouteWithFilters("DELETE", scenePath(), deleteSceneHandler, authorizedInRoom(auth.Write, findAndLoadScene, authorizeScene(auth.Write))).Describe(server.Documentation{
+			Summary: "Delete scene", Tags: tags,
+		}),
+		server.NewRouteWithFilters("POST", path.Join(scenePath(), "activate"), activateSceneHandler, authorizedInRoom(auth.Control, findAndLoadScene, authorizeScene(auth.Control))).Describe(server.Documentation{
+			Summary: "Switch the devices of the scene in parallel, 207 when any of them fails", Tags: tags, Response: view.Activation{},
+			Errors: []int{fasthttp.StatusMultiStatus},
+		}),
+	)
+}
+
+var findAndLoadScene = func(kvStore store.Store, ctx server.RequestContext) error {
+	err := loadBuildingsAndBuildingFromContext(kvStore, ctx)
+	if err != nil {
+		switch err.(type) {
+		case store.NotFound:
+			return notFound(ctx)
+		default:
+			return internalServerError(ctx, err)
+		}
+	}
+
+	building := ctx.UserValue(buildingUserKey).(gateway.Building)
+	scenes, err := kvStore.Scenes(building)
+	if err != nil {
+		return internalServerError(ctx, err)
+	}
+
+	id, _ := ctx.UserValue(sceneID).(string)
+	scene, ok := scenes[id]
+	if !ok {
+		return notFound(ctx)
+	}
+	ctx.SetUserValue(sceneUserKey, scene)
+	setAuditEntity(ctx, sceneEntity(scene), view.NewScene(scene))
+	return ctx.Next()
+}
+
+// authorizeScene rejects the request with 403 unless its token allows the
+// action on the room of the scene of the path, on its building when the
+// scene has no room
+func authorizeScene(action auth.Action) server.ResponseHandler {
+	return func(store store.Store, ctx server.RequestContext) error {
+		scene, _ := ctx.UserValue(sceneUserKey).(gateway.Scene)
+		if err := authorizeOn(ctx, action, sceneResource(scene)...); err != nil {
+			return forbidden(ctx, err)
+		}
+		return ctx.Next()
+	}
+}
+
+// sceneResource returns the ids of the building, floor and room of the
+// scene, the building only when the scene has no room
+func sceneResource(scene gateway.Scene) []string {
+	if scene.Room == "" {
+		return []string{scene.Building.ID()}
+	}
+	return append([]string{scene.Building.ID()}, strings.Split(scene.Room, "/")...)
+}
+
+// sceneEntity returns the entity of the scene recorded by the audit
+func sceneEntity(scene gateway.Scene) string {
+	return path.Join(scene.Building.ID(), "scenes", scene.ID())
+}
+
+var listScenesHandler = func(store store.Store, ctx server.RequestContext) error {
+	building, ok := ctx.UserValue(buildingUserKey).(gateway.Building)
+	if !ok {
+		return notFound(ctx)
+	}
+
+	selector, err := labelSelector(ctx)
+	if err != nil {
+		return badRequest(ctx, err)
+	}
+
+	scenes, err := store.Scenes(building)
+	if err != nil {
+		return internalServerError(ctx, err)
+	}
+
+	for id, scene := range scenes {
+		if !selector.Matches(scene.Labels) {
+			delete(scenes, id)
+		} else if !visible(ctx, sceneResource(scene)...) {
+			delete(scenes, id)
+		}
+	}
+	return ctx.JSONResponse(view.NewScenes(scenes), http.StatusOK)
+}
+
+var createSceneHandler = func(store store.Store, ctx server.RequestContext) error {
+	building, ok := ctx.UserValue(buildingUserKey).(gateway.Building)
+	if !ok {
+		return notFound(ctx)
+	}
+
+	scene, err := view.ConvertScene(building, ctx.PostBody())
+	if err != nil {
+		return badRequest(ctx, err)
+	}
+	return insertScene(store, ctx, scene)
+}
+
+var captureSceneHandler = func(store store.Store, ctx server.RequestContext) error {
+	building, ok := ctx.UserValue(buildingUserKey).(gateway.Building)
+	if !ok {
+		return notFound(ctx)
+	}
+
+	capture := view.Capture{}
+	err := json.Unmarshal(ctx.PostBody(), &capture)
+	if err != nil {
+		return badRequest(ctx, err)
+	}
+
+	tree, err := store.Tree()
+	if err != nil {
+		return internalServerError(ctx, err)
+	}
+
+	devices := map[string]bool{}
+	for _, device := range capture.Devices {
+		devices[device] = true
+	}
+	targets := []gateway.Target{}
+	for floorID, floor := range tree.FloorsOf(building) {
+		for roomID, room := range tree.RoomsOf(floor) {
+			if capture.Room != "" && capture.Room != floorID+"/"+roomID {
+				continue
+			}
+			states, err := store.States(room)
+			if err != nil {
+				return internalServerError(ctx, err)
+			}
+			for deviceID, device := range tree.DevicesOf(room) {
+				target := gateway.Target{Floor: floorID, Room: roomID, Device: deviceID}
+				if len(devices) > 0 && !devices[target.Path()] {
+					continue
+				}
+				if target.State, ok = gateway.ReportedState(tree.NodesOf(room), states, device); ok {
+					targets = append(targets, target)
+				}
+			}
+		}
+	}
+	if len(targets) == 0 {
+		return badRequest(ctx, fmt.Errorf("no device in the scope has reported its state"))
+	}
+	sort.Slice(targets, func(i, j int) bool { return targets[i].Path() < targets[j].Path() })
+
+	scene := gateway.Scene{
+		Building:       building,
+		Room:           capture.Room,
+		Targets:        targets,
+		PhysicalEntity: gateway.PhysicalEntity{Name: capture.Name, Description: capture.Description},
+	}
+	err = scene.Validate()
+	if err != nil {
+		return badRequest(ctx, err)
+	}
+	return insertScene(store, ctx, scene)
+}
+
+// insertScene persists the scene unless the token is not allowed to write
+// its room or the building has a scene with the same id
+func insertScene(store store.Store, ctx server.RequestContext, scene gateway.Scene) error {
+	setAuditEntity(ctx, sceneEntity(scene), nil)
+	if err := authorizeOn(ctx, auth.Write, sceneResource(scene)...); err != nil {
+		return forbidden(ctx, err)
+	}
+
+	scenes, err := store.Scenes(scene.Building)
+	if err != nil {
+		return internalServerError(ctx, err)
+	}
+	if _, ok := scenes[scene.ID()]; ok {
+		return conflict(ctx)
+	}
+
+	scenes[scene.ID()] = scene
+	err = store.UpsertScenes(scene.Building, scenes)
+	if err != nil {
+		return internalServerError(ctx, err)
+	}
+	ctx.SetUserValue(auditAfterKey, view.NewScene(scene))
+	return created(ctx, scene.ID())
+}
+
+var getSceneHandler = func(store store.Store, ctx server.RequestContext) error {
+	scene, ok := ctx.UserValue(sceneUserKey).(gateway.Scene)
+	if !ok {
+		return notFound(ctx)
+	}
+
+	return ctx.JSONResponse(view.NewScene(scene), fasthttp.StatusOK)
+}
+
+// updateSceneHandler replaces the scene of the path, a scene renamed to the
+// name of another scene is a conflict, otherwise the entry of its former
+// name is removed in the same write
+var updateSceneHandler = func(store store.Store, ctx server.RequestContext) error {
+	building, ok := ctx.UserValue(buildingUserKey).(gateway.Building)
+	existing, found := ctx.UserValue(sceneUserKey).(gateway.Scene)
+	if !ok || !found {
+		return notFound(ctx)
+	}
+
+	scene, err := view.ConvertScene(building, ctx.PostBody())
+	if err != nil {
+		return badRequest(ctx, err)
+	}
+
+	if err := authorizeOn(ctx, auth.Write, sceneResource(scene)...); err != nil {
+		return forbidden(ctx, err)
+	}
+
+	scenes, err := store.Scenes(building)
+	if err != nil {
+		return internalServerError(ctx, err)
+	}
+	if _, ok := scenes[scene.ID()]; ok && scene.ID() != existing.ID() {
+		return conflict(ctx)
+	}
+	delete(scenes, existing.ID())
+	scenes[scene.ID()] = scene
+
+	err = store.UpsertScenes(building, scenes)
+	if err != nil {
+		return internalServerError(ctx, err)
+	}
+	ctx.SetUserValue(auditAfterKey, view.NewScene(scene))
+	return nil
+}
+
+var deleteSceneHandler = func(store store.Store, ctx server.RequestContext) error {
+	scene, ok := ctx.UserValue(sceneUserKey).(gateway.Scene)
+	if !ok {
+		return notFound(ctx)
+	}
+
+	err := store.DeleteScene(scene)
+	if err != nil {
+		return internalServerError(ctx, err)
+	}
+	return nil
+}
+
+// activateSceneHandler switches the devices of the scene in parallel and
+// records the state of the devices switched successfully
+var activateSceneHandler = func(store store.Store, ctx server.RequestContext) error {
+	scene, ok := ctx.UserValue(sceneUserKey).(gateway.Scene)
+	if !ok {
+		return notFound(ctx)
+	}
+
+	tree, err := store.Tree()
+	if err != nil {
+		return internalServerError(ctx, err)
+	}
+
+	results := scene.Activate(tree)
+	status := fasthttp.StatusOK
+	for _, result := range results {
+		if result.Err != nil {
+			status = fasthttp.StatusMultiStatus
+			continue
+		}
+		err = store.UpsertState(*result.Device, result.Target.State)
+		if err != nil {
+			return internalServerError(ctx, err)
+		}
+		publishState(*result.Device, result.Target.State)
+	}
+	return ctx.JSONResponse(view.NewActivation(scene, results), status)
+}
@@ -0,0 +1,201 @@
+package api_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/view"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/audit"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/auth"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/store"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/testutils"
+)
+
+const scenesURL = "/buildings/building-one/scenes"
+
+// seedScenes seeds the store along with the broken-lamp controlled by the
+// node of room-one and returns the node switching the devices
+func seedScenes(t *testing.T, persistentStore store.Store) *testutils.RecordingNode {
+	seedStore(t, persistentStore)
+	lamp := testutils.NewDevice("broken-lamp")
+	assert.NoError(t, persistentStore.UpsertDevice(lamp))
+	node, host := testutils.NewRecordingNode(t)
+	assert.NoError(t, persistentStore.UpsertNodes(lamp.Room, gateway.Nodes{"node-one": gateway.NodeMetadata{
+		Room: lamp.Room, Devices: []string{"porch-light", "broken-lamp"}, Host: host, Type: gateway.NodeTypeMqtt,
+		PhysicalEntity: gateway.PhysicalEntity{Name: "node-one"},
+	}}))
+	return node
+}
+
+func TestScenes(t *testing.T) {
+	movieMode := `{"name":"movie-mode","targets":[
+		{"floor":"floor-one","room":"room-one","device":"porch-light","state":"off"},
+		{"floor":"floor-one","room":"room-one","device":"broken-lamp","state":"on"}]}`
+
+	t.Run("should create, list, update and delete the scenes", func(t *testing.T) {
+		persistentStore := enableAuthentication(t)
+		seedScenes(t, persistentStore)
+		editor := issueToken(t, persistentStore, auth.RoleEditor)
+
+		res := serveBodyAs(t, persistentStore, editor, "POST", scenesURL, movieMode)
+		assert.Equal(t, fasthttp.StatusCreated, res.StatusCode)
+		res = serveBodyAs(t, persistentStore, editor, "POST", scenesURL, movieMode)
+		assert.Equal(t, fasthttp.StatusConflict, res.StatusCode)
+
+		res = serveAs(t, persistentStore, editor, "GET", scenesURL)
+		var scenes []view.Scene
+		if assert.NoError(t, testutils.Read(res, &scenes)) && assert.Len(t, scenes, 1) {
+			assert.Equal(t, "movie-mode", scenes[0].ID)
+			assert.Equal(t, "building-one", scenes[0].Building)
+			assert.Len(t, scenes[0].Targets, 2)
+		}
+
+		res = serveBodyAs(t, persistentStore, editor, "PUT", scenesURL+"/movie-mode", `{"name":"movie-mode","description":"lights off","targets":[
+			{"floor":"floor-one","room":"room-one","device":"porch-light","state":"off"}]}`)
+		assert.Equal(t, fasthttp.StatusOK, res.StatusCode)
+		res = serveAs(t, persistentStore, editor, "GET", scenesURL+"/movie-mode")
+		scene := view.Scene{}
+		if assert.NoError(t, testutils.Read(res, &scene)) {
+			assert.Equal(t, "lights off", scene.Description)
+			assert.Len(t, scene.Targets, 1)
+		}
+
+		res = serveAs(t, persistentStore, editor, "DELETE", scenesURL+"/movie-mode")
+		assert.Equal(t, fasthttp.StatusOK, res.StatusCode)
+		res = serveAs(t, persistentStore, editor, "GET", scenesURL+"/movie-mode")
+		assert.Equal(t, fasthttp.StatusNotFound, res.StatusCode)
+	})
+
+	t.Run("should rename the scene of the path", func(t *testing.T) {
+		persistentStore := enableAuthentication(t)
+		seedScenes(t, persistentStore)
+		editor := issueToken(t, persistentStore, auth.RoleEditor)
+		serveBodyAs(t, persistentStore, editor, "POST", scenesURL, movieMode)
+		serveBodyAs(t, persistentStore, editor, "POST", scenesURL, `{"name":"all-off","targets":[
+			{"floor":"floor-one","room":"room-one","device":"porch-light","state":"off"}]}`)
+
+		res := serveBodyAs(t, persistentStore, editor, "PUT", scenesURL+"/movie-mode", `{"name":"all-off","targets":[
+			{"floor":"floor-one","room":"room-one","device":"broken-lamp","state":"off"}]}`)
+		assert.Equal(t, fasthttp.StatusConflict, res.StatusCode)
+
+		res = serveBodyAs(t, persistentStore, editor, "PUT", scenesURL+"/movie-mode", `{"name":"cinema-mode","targets":[
+			{"floor":"floor-one","room":"room-one","device":"porch-light","state":"off"}]}`)
+		assert.Equal(t, fasthttp.StatusOK, res.StatusCode)
+		res = serveAs(t, persistentStore, editor, "GET", scenesURL)
+		var scenes []view.Scene
+		if assert.NoError(t, testutils.Read(res, &scenes)) && assert.Len(t, scenes, 2) {
+			assert.ElementsMatch(t, []string{"all-off", "cinema-mode"}, []string{scenes[0].ID, scenes[1].ID})
+		}
+	})
+
+	t.Run("should authorize the tokens on the room of the scene", func(t *testing.T) {
+		persistentStore := enableAuthentication(t)
+		seedScenes(t, persistentStore)
+		editor := issueToken(t, persistentStore, auth.RoleEditor)
+		roomEditor := issueToken(t, persistentStore, auth.RoleEditor, "building-one/floor-one/room-one")
+		otherRoomEditor := issueToken(t, persistentStore, auth.RoleEditor, "building-one/floor-one/room-two")
+		roomViewer := issueToken(t, persistentStore, auth.RoleViewer, "building-one/floor-one/room-one")
+		porchOff := `{"name":"porch-off","room":"floor-one/room-one","targets":[
+			{"floor":"floor-one","room":"room-one","device":"porch-light","state":"off"}]}`
+		serveBodyAs(t, persistentStore, editor, "POST", scenesURL, movieMode)
+
+		assert.Equal(t, fasthttp.StatusForbidden, serveBodyAs(t, persistentStore, otherRoomEditor, "POST", scenesURL, porchOff).StatusCode)
+		assert.Equal(t, fasthttp.StatusForbidden, serveBodyAs(t, persistentStore, roomViewer, "POST", scenesURL, porchOff).StatusCode)
+		assert.Equal(t, fasthttp.StatusCreated, serveBodyAs(t, persistentStore, roomEditor, "POST", scenesURL, porchOff).StatusCode)
+
+		assert.Equal(t, fasthttp.StatusOK, serveAs(t, persistentStore, roomEditor, "POST", scenesURL+"/porch-off/activate").StatusCode)
+		assert.Equal(t, fasthttp.StatusForbidden, serveAs(t, persistentStore, roomEditor, "POST", scenesURL+"/movie-mode/activate").StatusCode)
+		assert.Equal(t, fasthttp.StatusForbidden, serveAs(t, persistentStore, otherRoomEditor, "GET", scenesURL+"/porch-off").StatusCode)
+		assert.Equal(t, fasthttp.StatusForbidden, serveAs(t, persistentStore, otherRoomEditor, "DELETE", scenesURL+"/porch-off").StatusCode)
+		assert.Equal(t, fasthttp.StatusOK, serveAs(t, persistentStore, roomViewer, "GET", scenesURL+"/porch-off").StatusCode)
+
+		res := serveBodyAs(t, persistentStore, roomEditor, "PUT", scenesURL+"/porch-off", `{"name":"porch-off","room":"floor-one/room-two","targets":[
+			{"floor":"floor-one","room":"room-two","device":"fan","state":"off"}]}`)
+		assert.Equal(t, fasthttp.StatusForbidden, res.StatusCode)
+		assert.Equal(t, fasthttp.StatusOK, serveAs(t, persistentStore, roomEditor, "DELETE", scenesURL+"/porch-off").StatusCode)
+	})
+
+	t.Run("should reject invalid scene", func(t *testing.T) {
+		persistentStore := enableAuthentication(t)
+		seedScenes(t, persistentStore)
+		editor := issueToken(t, persistentStore, auth.RoleEditor)
+
+		res := serveBodyAs(t, persistentStore, editor, "POST", scenesURL, `{"name":"movie-mode","targets":[
+			{"floor":"floor-one","room":"room-one","device":"porch-light","state":"dim"}]}`)
+
+		assert.Equal(t, fasthttp.StatusBadRequest, res.StatusCode)
+		message, err := testutils.ReadError(res)
+		assert.NoError(t, err)
+		assert.Equal(t, "target floor-one/room-one/porch-light: state dim not supported, expected on or off", message)
+	})
+
+	t.Run("should activate the scene with the result of every device", func(t *testing.T) {
+		persistentStore := enableAuthentication(t)
+		node := seedScenes(t, persistentStore)
+		editor := issueToken(t, persistentStore, auth.RoleEditor)
+		operator := issueToken(t, persistentStore, auth.RoleOperator)
+		serveBodyAs(t, persistentStore, editor, "POST", scenesURL, movieMode)
+
+		res := serveAs(t, persistentStore, operator, "POST", scenesURL+"/movie-mode/activate")
+
+		assert.Equal(t, fasthttp.StatusMultiStatus, res.StatusCode)
+		activation := view.Activation{}
+		if assert.NoError(t, testutils.Read(res, &activation)) {
+			assert.Equal(t, []view.TargetResult{
+				{Device: "floor-one/room-one/porch-light", State: gateway.StateOff, Outcome: view.Switched},
+				{Device: "floor-one/room-one/broken-lamp", State: gateway.StateOn, Outcome: view.Failed, Error: "node unreachable"},
+			}, activation.Results)
+		}
+		assert.ElementsMatch(t, []string{"off:porch-light", "on:broken-lamp"}, node.Switched())
+
+		states, err := persistentStore.States(testutils.NewRoom("room-one"))
+		assert.NoError(t, err)
+		assert.Equal(t, gateway.States{"porch-light": gateway.StateOff}, states)
+	})
+
+	t.Run("should capture the reported state of the devices", func(t *testing.T) {
+		persistentStore := enableAuthentication(t)
+		seedScenes(t, persistentStore)
+		editor := issueToken(t, persistentStore, auth.RoleEditor)
+
+		res := serveBodyAs(t, persistentStore, editor, "POST", scenesURL+"/capture", `{"name":"evening"}`)
+		assert.Equal(t, fasthttp.StatusBadRequest, res.StatusCode)
+
+		res = serveAs(t, persistentStore, editor, "POST", "/buildings/building-one/floors/floor-one/rooms/room-one/devices/porch-light/on")
+		assert.Equal(t, fasthttp.StatusOK, res.StatusCode)
+		res = serveBodyAs(t, persistentStore, editor, "POST", scenesURL+"/capture", `{"name":"evening","room":"floor-one/room-one"}`)
+		assert.Equal(t, fasthttp.StatusCreated, res.StatusCode)
+
+		scenes, err := persistentStore.Scenes(testutils.NewBuilding("building-one"))
+		if assert.NoError(t, err) && assert.Contains(t, scenes, "evening") {
+			assert.Equal(t, "floor-one/room-one", scenes["evening"].Room)
+			assert.Equal(t, []gateway.Target{
+				{Floor: "floor-one", Room: "room-one", Device: "porch-light", State: gateway.StateOn},
+			}, scenes["evening"].Targets)
+		}
+	})
+
+	t.Run("should audit the changes of the scenes", func(t *testing.T) {
+		persistentStore := enableAuthentication(t)
+		seedScenes(t, persistentStore)
+		api.EnableAudit(true)
+		t.Cleanup(func() {
+			api.EnableAudit(false)
+		})
+		editor := issueToken(t, persistentStore, auth.RoleEditor)
+
+		serveBodyAs(t, persistentStore, editor, "POST", scenesURL, movieMode)
+		serveAs(t, persistentStore, editor, "DELETE", scenesURL+"/movie-mode")
+
+		entries, err := persistentStore.Audit(audit.Query{Entity: "building-one/scenes/movie-mode"})
+		if assert.NoError(t, err) && assert.Len(t, entries, 2) {
+			assert.Nil(t, entries[0].Before)
+			assert.Contains(t, string(entries[0].After), `"id":"movie-mode"`)
+			assert.Equal(t, "DELETE", entries[1].Method)
+			assert.Nil(t, entries[1].After)
+		}
+	})
+}
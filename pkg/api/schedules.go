@@ -0,0 +1,265 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/valyala/fasthttp"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/server"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/view"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/auth"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/store"
+)
+
+const (
+	scheduleID      = "schedule-id"
+	scheduleUserKey = "schedule"
+	countParam      = "count"
+	afterParam      = "after"
+
+	defaultNextRuns = 5
+	maxNextRuns     = 100
+)
+
+func schedulePath() string {
+	return path.Join(schedulesBasePath(), fmt.Sprintf("{%s}", scheduleID))
+}
+
+func schedulesBasePath() string {
+	return path.Join(buildingPath(), "schedules")
+}
+
+func init() {
+	tags := []string{"schedules"}
+	nextRunsQuery := map[string]string{
+		countParam: fmt.Sprintf("number of runs, %d by default and %d at most", defaultNextRuns, maxNextRuns),
+		afterParam: "RFC 3339 time after which the runs are listed, now by default",
+	}
+	AddRoute(
+		server.NewRouteWithFilters("GET", schedulesBasePath(), listSchedulesHandler, authorized(auth.Read, findAndLoadBuilding)).Describe(server.Documentation{
+			Summary: "List schedules of the building along with their next run", Tags: tags, Query: selectorQuery, Response: []view.Schedule{},
+			Errors: []int{fasthttp.StatusBadRequest},
+		}),
+		server.NewRouteWithFilters("POST", schedulesBasePath(), createScheduleHandler, authorized(auth.Write, findAndLoadBuilding)).Describe(server.Documentation{
+			Summary: "Create schedule in the building", Tags: tags, Request: view.Schedule{}, Response: map[string]string{},
+			Status: fasthttp.StatusCreated, Errors: []int{fasthttp.StatusConflict},
+		}),
+		server.NewRouteWithFilters("POST", path.Join(schedulesBasePath(), "next-runs"), previewScheduleHandler, authorized(auth.Read, findAndLoadBuilding)).Describe(server.Documentation{
+			Summary: "Preview the next runs of a schedule without creating it", Tags: tags, Query: nextRunsQuery,
+			Request: view.Schedule{}, Response: view.NextRuns{}, Errors: []int{fasthttp.StatusBadRequest},
+		}),
+		server.NewRouteWithFilters("GET", schedulePath(), getScheduleHandler, authorized(auth.Read, findAndLoadSchedule)).Describe(server.Documentation{
+			Summary: "Get schedule", Tags: tags, Response: view.Schedule{},
+		}),
+		server.NewRouteWithFilters("PUT", schedulePath(), updateScheduleHandler, authorized(auth.Write, findAndLoadSchedule)).Describe(server.Documentation{
+			Summary: "Update schedule, a new name renames it", Tags: tags, Request: view.Schedule{}, Errors: []int{fasthttp.StatusConflict},
+		}),
+		server.NewRouteWithFilters("DELETE", schedulePath(), deleteScheduleHandler, authorized(auth.Write, findAndLoadSchedule)).Describe(server.Documentation{
+			Summary: "Delete schedule", Tags: tags,
+		}),
+		server.NewRouteWithFilters("GET", path.Join(schedulePath(), "next-runs"), nextRunsHandler, authorized(auth.Read, findAndLoadSchedule)).Describe(server.Documentation{
+			Summary: "List the next runs of the schedule in the time zone of the building", Tags: tags, Query: nextRunsQuery,
+			Response: view.NextRuns{}, Errors: []int{fasthttp.StatusBadRequest},
+		}),
+	)
+}
+
+var findAndLoadSchedule = func(kvStore store.Store, ctx server.RequestContext) error {
+	err := loadBuildingsAndBuildingFromContext(kvStore, ctx)
+	if err != nil {
+		switch err.(type) {
+		case store.NotFound:
+			return notFound(ctx)
+		default:
+			return internalServerError(ctx, err)
+		}
+	}
+
+	building := ctx.UserValue(buildingUserKey).(gateway.Building)
+	schedules, err := kvStore.Schedules(building)
+	if err != nil {
+		return internalServerError(ctx, err)
+	}
+
+	id, _ := ctx.UserValue(scheduleID).(string)
+	schedule, ok := schedules[id]
+	if !ok {
+		return notFound(ctx)
+	}
+	ctx.SetUserValue(scheduleUserKey, schedule)
+	setAuditEntity(ctx, scheduleEntity(schedule), view.NewSchedule(building, schedule, time.Now()))
+	return ctx.Next()
+}
+
+// scheduleEntity returns the entity of the schedule recorded by the audit
+func scheduleEntity(schedule gateway.Schedule) string {
+	return path.Join(schedule.Building.ID(), "schedules", schedule.ID())
+}
+
+var listSchedulesHandler = func(store store.Store, ctx server.RequestContext) error {
+	building, ok := ctx.UserValue(buildingUserKey).(gateway.Building)
+	if !ok {
+		return notFound(ctx)
+	}
+
+	selector, err := labelSelector(ctx)
+	if err != nil {
+		return badRequest(ctx, err)
+	}
+
+	schedules, err := store.Schedules(building)
+	if err != nil {
+		return internalServerError(ctx, err)
+	}
+
+	for id, schedule := range schedules {
+		if !selector.Matches(schedule.Labels) {
+			delete(schedules, id)
+		}
+	}
+	return ctx.JSONResponse(view.NewSchedules(building, schedules, time.Now()), http.StatusOK)
+}
+
+var createScheduleHandler = func(store store.Store, ctx server.RequestContext) error {
+	building, ok := ctx.UserValue(buildingUserKey).(gateway.Building)
+	if !ok {
+		return notFound(ctx)
+	}
+
+	schedule, err := gateway.NewSchedule(building, ctx.PostBody())
+	if err != nil {
+		return badRequest(ctx, err)
+	}
+	schedule.Created, schedule.LastRun = time.Now().UTC().Truncate(time.Second), nil
+
+	schedules, err := store.Schedules(building)
+	if err != nil {
+		return internalServerError(ctx, err)
+	}
+
+	setAuditEntity(ctx, scheduleEntity(schedule), nil)
+	if _, ok := schedules[schedule.ID()]; ok {
+		return conflict(ctx)
+	}
+
+	schedules[schedule.ID()] = schedule
+	err = store.UpsertSchedules(building, schedules)
+	if err != nil {
+		return internalServerError(ctx, err)
+	}
+	ctx.SetUserValue(auditAfterKey, view.NewSchedule(building, schedule, time.Now()))
+	return created(ctx, schedule.ID())
+}
+
+var getScheduleHandler = func(store store.Store, ctx server.RequestContext) error {
+	building, ok := ctx.UserValue(buildingUserKey).(gateway.Building)
+	schedule, found := ctx.UserValue(scheduleUserKey).(gateway.Schedule)
+	if !ok || !found {
+		return notFound(ctx)
+	}
+
+	return ctx.JSONResponse(view.NewSchedule(building, schedule, time.Now()), fasthttp.StatusOK)
+}
+
+// updateScheduleHandler replaces the schedule of the path, the time it was
+// created and its last run are kept. A schedule renamed to the name of
+// another schedule is a conflict, otherwise the entry of its former name is
+// removed in the same write
+var updateScheduleHandler = func(store store.Store, ctx server.RequestContext) error {
+	building, ok := ctx.UserValue(buildingUserKey).(gateway.Building)
+	existing, found := ctx.UserValue(scheduleUserKey).(gateway.Schedule)
+	if !ok || !found {
+		return notFound(ctx)
+	}
+
+	schedule, err := gateway.NewSchedule(building, ctx.PostBody())
+	if err != nil {
+		return badRequest(ctx, err)
+	}
+	schedule.Created, schedule.LastRun = existing.Created, existing.LastRun
+
+	schedules, err := store.Schedules(building)
+	if err != nil {
+		return internalServerError(ctx, err)
+	}
+	if _, ok := schedules[schedule.ID()]; ok && schedule.ID() != existing.ID() {
+		return conflict(ctx)
+	}
+	delete(schedules, existing.ID())
+	schedules[schedule.ID()] = schedule
+
+	err = store.UpsertSchedules(building, schedules)
+	if err != nil {
+		return internalServerError(ctx, err)
+	}
+	ctx.SetUserValue(auditAfterKey, view.NewSchedule(building, schedule, time.Now()))
+	return nil
+}
+
+var deleteScheduleHandler = func(store store.Store, ctx server.RequestContext) error {
+	schedule, ok := ctx.UserValue(scheduleUserKey).(gateway.Schedule)
+	if !ok {
+		return notFound(ctx)
+	}
+
+	err := store.DeleteSchedule(schedule)
+	if err != nil {
+		return internalServerError(ctx, err)
+	}
+	return nil
+}
+
+var nextRunsHandler = func(store store.Store, ctx server.RequestContext) error {
+	building, ok := ctx.UserValue(buildingUserKey).(gateway.Building)
+	schedule, found := ctx.UserValue(scheduleUserKey).(gateway.Schedule)
+	if !ok || !found {
+		return notFound(ctx)
+	}
+	return respondNextRuns(ctx, building, schedule)
+}
+
+var previewScheduleHandler = func(store store.Store, ctx server.RequestContext) error {
+	building, ok := ctx.UserValue(buildingUserKey).(gateway.Building)
+	if !ok {
+		return notFound(ctx)
+	}
+
+	schedule, err := gateway.NewSchedule(building, ctx.PostBody())
+	if err != nil {
+		return badRequest(ctx, err)
+	}
+	return respondNextRuns(ctx, building, schedule)
+}
+
+// respondNextRuns responds with the runs of the schedule after the time
+// and up to the count given in the query
+func respondNextRuns(ctx server.RequestContext, building gateway.Building, schedule gateway.Schedule) error {
+	args := ctx.QueryArgs()
+	count := defaultNextRuns
+	if raw := string(args.Peek(countParam)); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > maxNextRuns {
+			return badRequest(ctx, fmt.Errorf("%s should be a number between 1 and %d", countParam, maxNextRuns))
+		}
+		count = parsed
+	}
+	after := time.Now()
+	if raw := string(args.Peek(afterParam)); raw != "" {
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return badRequest(ctx, fmt.Errorf("%s should be an RFC 3339 time e.g. 2026-10-19T03:00:00Z", afterParam))
+		}
+		after = parsed
+	}
+
+	location := building.Location(time.Local)
+	runs, err := schedule.NextRuns(building, after.In(location), count)
+	if err != nil {
+		return badRequest(ctx, err)
+	}
+	return ctx.JSONResponse(view.NextRuns{Schedule: schedule.ID(), Timezone: location.String(), Runs: runs}, fasthttp.StatusOK)
+}
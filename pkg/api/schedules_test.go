@@ -0,0 +1,158 @@
+package api_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/view"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/audit"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/auth"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/store"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/testutils"
+)
+
+const schedulesURL = "/buildings/building-one/schedules"
+
+// seedSchedules seeds the store with building-one in Bengaluru
+func seedSchedules(t *testing.T, persistentStore store.Store) {
+	seedStore(t, persistentStore)
+	building := testutils.NewBuilding("building-one")
+	building.Lat, building.Lan, building.Timezone = 12.97, 77.59, "Asia/Kolkata"
+	assert.NoError(t, persistentStore.UpsertBuilding(building))
+}
+
+func TestSchedules(t *testing.T) {
+	porchOnWeekdays := `{"name":"porch on weekdays","cron":"30 18 * * 1-5","skip":["2026-10-20"],
+		"device":"floor-one/room-one/porch-light","state":"on"}`
+
+	t.Run("should create, list, update and delete the schedules", func(t *testing.T) {
+		persistentStore := enableAuthentication(t)
+		seedSchedules(t, persistentStore)
+		editor := issueToken(t, persistentStore, auth.RoleEditor)
+
+		res := serveBodyAs(t, persistentStore, editor, "POST", schedulesURL, porchOnWeekdays)
+		assert.Equal(t, fasthttp.StatusCreated, res.StatusCode)
+		res = serveBodyAs(t, persistentStore, editor, "POST", schedulesURL, porchOnWeekdays)
+		assert.Equal(t, fasthttp.StatusConflict, res.StatusCode)
+
+		res = serveAs(t, persistentStore, editor, "GET", schedulesURL)
+		var schedules []view.Schedule
+		if assert.NoError(t, testutils.Read(res, &schedules)) && assert.Len(t, schedules, 1) {
+			assert.Equal(t, "porch-on-weekdays", schedules[0].ID)
+			assert.Equal(t, "building-one", schedules[0].Building)
+			assert.Equal(t, "recurring", schedules[0].Mode)
+			assert.Equal(t, "skip", schedules[0].Missed)
+			assert.WithinDuration(t, time.Now(), schedules[0].Created, time.Minute)
+			assert.NotNil(t, schedules[0].NextRun)
+		}
+
+		res = serveBodyAs(t, persistentStore, editor, "PUT", schedulesURL+"/porch-on-weekdays", `{"name":"porch on weekdays","disabled":true,
+			"sun":"sunset-15m","missed":"run-once","room":"floor-one/room-one","state":"on"}`)
+		assert.Equal(t, fasthttp.StatusOK, res.StatusCode)
+		res = serveAs(t, persistentStore, editor, "GET", schedulesURL+"/porch-on-weekdays")
+		schedule := view.Schedule{}
+		if assert.NoError(t, testutils.Read(res, &schedule)) {
+			assert.True(t, schedule.Disabled)
+			assert.Equal(t, "sunset-15m", schedule.Sun)
+			assert.Equal(t, "floor-one/room-one", schedule.Room)
+			assert.Equal(t, schedules[0].Created, schedule.Created)
+			assert.Nil(t, schedule.NextRun)
+		}
+
+		res = serveAs(t, persistentStore, editor, "DELETE", schedulesURL+"/porch-on-weekdays")
+		assert.Equal(t, fasthttp.StatusOK, res.StatusCode)
+		res = serveAs(t, persistentStore, editor, "GET", schedulesURL+"/porch-on-weekdays")
+		assert.Equal(t, fasthttp.StatusNotFound, res.StatusCode)
+	})
+
+	t.Run("should rename the schedule of the path", func(t *testing.T) {
+		persistentStore := enableAuthentication(t)
+		seedSchedules(t, persistentStore)
+		editor := issueToken(t, persistentStore, auth.RoleEditor)
+		serveBodyAs(t, persistentStore, editor, "POST", schedulesURL, porchOnWeekdays)
+		serveBodyAs(t, persistentStore, editor, "POST", schedulesURL, `{"name":"porch at night","cron":"0 22 * * *",
+			"device":"floor-one/room-one/porch-light","state":"off"}`)
+
+		res := serveBodyAs(t, persistentStore, editor, "PUT", schedulesURL+"/porch-on-weekdays", `{"name":"porch at night","cron":"0 23 * * *",
+			"device":"floor-one/room-one/porch-light","state":"off"}`)
+		assert.Equal(t, fasthttp.StatusConflict, res.StatusCode)
+
+		res = serveBodyAs(t, persistentStore, editor, "PUT", schedulesURL+"/porch-on-weekdays", `{"name":"porch on workdays","cron":"30 18 * * 1-5",
+			"device":"floor-one/room-one/porch-light","state":"on"}`)
+		assert.Equal(t, fasthttp.StatusOK, res.StatusCode)
+		res = serveAs(t, persistentStore, editor, "GET", schedulesURL)
+		var schedules []view.Schedule
+		if assert.NoError(t, testutils.Read(res, &schedules)) && assert.Len(t, schedules, 2) {
+			ids := []string{schedules[0].ID, schedules[1].ID}
+			assert.ElementsMatch(t, []string{"porch-at-night", "porch-on-workdays"}, ids)
+		}
+	})
+
+	t.Run("should reject invalid schedule", func(t *testing.T) {
+		persistentStore := enableAuthentication(t)
+		seedSchedules(t, persistentStore)
+		editor := issueToken(t, persistentStore, auth.RoleEditor)
+
+		res := serveBodyAs(t, persistentStore, editor, "POST", schedulesURL, `{"name":"porch on weekdays","cron":"30 18 * * mon-fri-sat","device":"floor-one/room-one/porch-light","state":"on"}`)
+
+		assert.Equal(t, fasthttp.StatusBadRequest, res.StatusCode)
+		message, err := testutils.ReadError(res)
+		assert.NoError(t, err)
+		assert.Contains(t, message, "cron expression '30 18 * * mon-fri-sat'")
+	})
+
+	t.Run("should list the next runs in the time zone of the building", func(t *testing.T) {
+		persistentStore := enableAuthentication(t)
+		seedSchedules(t, persistentStore)
+		editor := issueToken(t, persistentStore, auth.RoleEditor)
+		viewer := issueToken(t, persistentStore, auth.RoleViewer)
+		serveBodyAs(t, persistentStore, editor, "POST", schedulesURL, porchOnWeekdays)
+		expected := []string{"2026-10-19T18:30:00+05:30", "2026-10-21T18:30:00+05:30", "2026-10-22T18:30:00+05:30"}
+
+		for _, res := range []struct {
+			method, url, body string
+		}{
+			{"GET", schedulesURL + "/porch-on-weekdays/next-runs?count=3&after=2026-10-19T12:00:00Z", ""},
+			{"POST", schedulesURL + "/next-runs?count=3&after=2026-10-19T12:00:00Z", porchOnWeekdays},
+		} {
+			response := serveBodyAs(t, persistentStore, viewer, res.method, res.url, res.body)
+			assert.Equal(t, fasthttp.StatusOK, response.StatusCode)
+			nextRuns := view.NextRuns{}
+			if assert.NoError(t, testutils.Read(response, &nextRuns)) && assert.Len(t, nextRuns.Runs, 3) {
+				assert.Equal(t, "porch-on-weekdays", nextRuns.Schedule)
+				assert.Equal(t, "Asia/Kolkata", nextRuns.Timezone)
+				for i, run := range nextRuns.Runs {
+					assert.Equal(t, expected[i], run.Format(time.RFC3339))
+				}
+			}
+		}
+
+		res := serveAs(t, persistentStore, viewer, "GET", schedulesURL+"/porch-on-weekdays/next-runs?count=1000")
+		assert.Equal(t, fasthttp.StatusBadRequest, res.StatusCode)
+	})
+
+	t.Run("should audit the changes of the schedules", func(t *testing.T) {
+		persistentStore := enableAuthentication(t)
+		seedSchedules(t, persistentStore)
+		api.EnableAudit(true)
+		t.Cleanup(func() {
+			api.EnableAudit(false)
+		})
+		editor := issueToken(t, persistentStore, auth.RoleEditor)
+
+		serveBodyAs(t, persistentStore, editor, "POST", schedulesURL, porchOnWeekdays)
+		serveBodyAs(t, persistentStore, editor, "POST", schedulesURL+"/next-runs", porchOnWeekdays)
+		serveAs(t, persistentStore, editor, "DELETE", schedulesURL+"/porch-on-weekdays")
+
+		entries, err := persistentStore.Audit(audit.Query{Entity: "building-one/schedules/porch-on-weekdays"})
+		if assert.NoError(t, err) && assert.Len(t, entries, 2) {
+			assert.Nil(t, entries[0].Before)
+			assert.Contains(t, string(entries[0].After), `"cron":"30 18 * * 1-5"`)
+			assert.Equal(t, "DELETE", entries[1].Method)
+			assert.Nil(t, entries[1].After)
+		}
+	})
+}
@@ -0,0 +1,108 @@
+package api
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/valyala/fasthttp"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/server"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/view"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/auth"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/labels"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/search"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/store"
+)
+
+const (
+	searchBasePath     = "/search"
+	selectorParam      = "selector"
+	queryParam         = "q"
+	limitParam         = "limit"
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+// selectorQuery documents the selector accepted by the list endpoints
+var selectorQuery = map[string]string{
+	selectorParam: "label selector e.g. env=outdoor,type in (light,fan), every entity by default",
+}
+
+func init() {
+	AddRoute(
+		server.NewRouteWithFilters("GET", searchBasePath, searchHandler, authorized(auth.Read)).Describe(server.Documentation{
+			Summary: "Search the buildings, floors, rooms and devices by their words or by their labels", Tags: []string{"search"},
+			Query: map[string]string{
+				queryParam:    "words matched against the names, descriptions, labels and meta allowing prefixes and typos e.g. guest geyser",
+				selectorParam: selectorQuery[selectorParam],
+				limitParam:    fmt.Sprintf("maximum number of results between 1 and %d, %d by default", maxSearchLimit, defaultSearchLimit),
+			},
+			Response: []view.Match{}, Errors: []int{fasthttp.StatusBadRequest},
+		}),
+	)
+}
+
+// labelSelector returns the selector given in the query, the empty
+// selector matches every entity
+func labelSelector(ctx server.RequestContext) (labels.Selector, error) {
+	return labels.Parse(string(ctx.QueryArgs().Peek(selectorParam)))
+}
+
+// visiblePath returns true when the entity given as building-id/floor-id/
+// room-id/device-id is visible to the token of the request, a device is
+// visible along with its room
+func visiblePath(ctx server.RequestContext, entity string) bool {
+	ids := strings.Split(entity, "/")
+	if len(ids) > 3 {
+		ids = ids[:3]
+	}
+	return visible(ctx, ids...)
+}
+
+var searchHandler = func(store store.Store, ctx server.RequestContext) error {
+	selector, err := labelSelector(ctx)
+	if err != nil {
+		return badRequest(ctx, err)
+	}
+
+	limit := defaultSearchLimit
+	if raw := string(ctx.QueryArgs().Peek(limitParam)); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > maxSearchLimit {
+			return badRequest(ctx, fmt.Errorf("%s should be a number between 1 and %d", limitParam, maxSearchLimit))
+		}
+		limit = parsed
+	}
+
+	query := strings.TrimSpace(string(ctx.QueryArgs().Peek(queryParam)))
+	if query == "" {
+		labelled, err := store.Labelled(selector)
+		if err != nil {
+			return internalServerError(ctx, err)
+		}
+
+		for entity := range labelled {
+			if !visiblePath(ctx, entity) {
+				delete(labelled, entity)
+			}
+		}
+		matches := view.NewMatches(labelled)
+		if len(matches) > limit {
+			matches = matches[:limit]
+		}
+		return ctx.JSONResponse(matches, fasthttp.StatusOK)
+	}
+
+	hits, err := store.Search(query)
+	if err != nil {
+		return internalServerError(ctx, err)
+	}
+
+	result := []search.Hit{}
+	for _, hit := range hits {
+		if len(result) < limit && visiblePath(ctx, hit.Path) && selector.Matches(hit.Labels) {
+			result = append(result, hit)
+		}
+	}
+	return ctx.JSONResponse(view.NewHits(result), fasthttp.StatusOK)
+}
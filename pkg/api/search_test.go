@@ -0,0 +1,116 @@
+package api_test
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/view"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/auth"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/store"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/testutils"
+)
+
+// seedLabels seeds the store with building-two and the porch-light
+// labelled as outdoor
+func seedLabels(t *testing.T, persistentStore store.Store) {
+	seedStore(t, persistentStore)
+	building := testutils.NewBuilding("building-two")
+	building.Labels = map[string]string{"env": "outdoor"}
+	assert.NoError(t, persistentStore.UpsertBuilding(building))
+	device := testutils.NewDevice("porch-light")
+	device.Labels = map[string]string{"env": "outdoor", "type": "light"}
+	assert.NoError(t, persistentStore.UpsertDevice(device))
+}
+
+func search(t *testing.T, persistentStore store.Store, apiKey, selector string) []view.Match {
+	res := serveAs(t, persistentStore, apiKey, "GET", "/search?selector="+url.QueryEscape(selector))
+	assert.Equal(t, fasthttp.StatusOK, res.StatusCode)
+	var matches []view.Match
+	assert.NoError(t, testutils.Read(res, &matches))
+	return matches
+}
+
+func TestSearch(t *testing.T) {
+	t.Run("should return the entities matching the selector across the hierarchy", func(t *testing.T) {
+		persistentStore := enableAuthentication(t)
+		seedLabels(t, persistentStore)
+		viewer := issueToken(t, persistentStore, auth.RoleViewer)
+
+		assert.Equal(t, []view.Match{
+			{Kind: "device", Path: "building-one/floor-one/room-one/porch-light", Building: "building-one", Floor: "floor-one",
+				Room: "room-one", Device: "porch-light", Labels: map[string]string{"env": "outdoor", "type": "light"}},
+			{Kind: "building", Path: "building-two", Building: "building-two", Labels: map[string]string{"env": "outdoor"}},
+		}, search(t, persistentStore, viewer, "env=outdoor"))
+		assert.Len(t, search(t, persistentStore, viewer, "env=outdoor,type in (light,fan)"), 1)
+		assert.Len(t, search(t, persistentStore, viewer, "!env"), 3)
+	})
+
+	t.Run("should keep the index up to date with the entities", func(t *testing.T) {
+		persistentStore := enableAuthentication(t)
+		seedLabels(t, persistentStore)
+		editor := issueToken(t, persistentStore, auth.RoleEditor)
+
+		res := serveBodyAs(t, persistentStore, editor, "PUT", "/buildings/building-one/floors/floor-one/rooms/room-one/devices/porch-light",
+			`{"name":"porch-light","labels":{"env":"indoor"}}`)
+		assert.Equal(t, fasthttp.StatusOK, res.StatusCode)
+		assert.Empty(t, search(t, persistentStore, editor, "type=light"))
+
+		res = serveAs(t, persistentStore, editor, "DELETE", "/buildings/building-two")
+		assert.Equal(t, fasthttp.StatusOK, res.StatusCode)
+		assert.Empty(t, search(t, persistentStore, editor, "env=outdoor"))
+	})
+
+	t.Run("should hide the entities the token is not allowed to read", func(t *testing.T) {
+		persistentStore := enableAuthentication(t)
+		seedLabels(t, persistentStore)
+		scoped := issueToken(t, persistentStore, auth.RoleViewer, "building-one/floor-one/room-one")
+
+		matches := search(t, persistentStore, scoped, "env=outdoor")
+
+		if assert.Len(t, matches, 1) {
+			assert.Equal(t, "building-one/floor-one/room-one/porch-light", matches[0].Path)
+		}
+	})
+
+	t.Run("should return the entities matching the words with their location", func(t *testing.T) {
+		persistentStore := enableAuthentication(t)
+		seedLabels(t, persistentStore)
+		geyser := testutils.NewDevice("geyser")
+		geyser.Description = "instant water heater"
+		assert.NoError(t, persistentStore.UpsertDevice(geyser))
+		viewer := issueToken(t, persistentStore, auth.RoleViewer)
+
+		res := serveAs(t, persistentStore, viewer, "GET", "/search?q="+url.QueryEscape("room one gyser"))
+
+		assert.Equal(t, fasthttp.StatusOK, res.StatusCode)
+		var matches []view.Match
+		if assert.NoError(t, testutils.Read(res, &matches)) && assert.Len(t, matches, 1) {
+			assert.Equal(t, "building-one/floor-one/room-one/geyser", matches[0].Path)
+			assert.Equal(t, "geyser", matches[0].Name)
+			assert.Equal(t, "building-one / floor-one / room-one", matches[0].Location)
+		}
+
+		res = serveAs(t, persistentStore, viewer, "GET", "/search?q=light&selector=env%3Doutdoor&limit=1")
+		matches = nil
+		if assert.NoError(t, testutils.Read(res, &matches)) && assert.Len(t, matches, 1) {
+			assert.Equal(t, "porch-light", matches[0].Device)
+		}
+
+		res = serveAs(t, persistentStore, viewer, "GET", "/search?q=light&limit=1000")
+		assert.Equal(t, fasthttp.StatusBadRequest, res.StatusCode)
+	})
+
+	t.Run("should reject invalid selector", func(t *testing.T) {
+		persistentStore := enableAuthentication(t)
+		viewer := issueToken(t, persistentStore, auth.RoleViewer)
+
+		res := serveAs(t, persistentStore, viewer, "GET", "/search?selector="+url.QueryEscape("type in light"))
+
+		assert.Equal(t, fasthttp.StatusBadRequest, res.StatusCode)
+		message, err := testutils.ReadError(res)
+		assert.NoError(t, err)
+		assert.Equal(t, "requirement 'type in light' should be given as key=value, key!=value, key in (a,b), key notin (a,b), key or !key", message)
+	})
+}
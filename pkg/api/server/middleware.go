@@ -3,13 +3,42 @@ package server
 import (
 	"fmt"
 	"github.com/savsgio/atreugo/v11"
+	"github.com/valyala/fasthttp"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/auth"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/metrics"
+	"strconv"
+	"strings"
 	"time"
 )
 
 const (
 	startTime = "request.startTime"
+
+	// passedKey is set when a middleware passes the request on, the
+	// request is measured by the middleware which responds
+	passedKey   = "request.passed"
+	measuredKey = "request.measured"
+
+	// TokenKey is the user value holding the auth.Token of the
+	// authenticated request
+	TokenKey = "auth.token"
+
+	bearerPrefix = "Bearer "
+
+	// mediaTypeKey is the user value holding the media type of the
+	// version of the API negotiated with the Accept header
+	mediaTypeKey = "request.mediaType"
+
+	mediaTypePrefix = "application/vnd.dwarka."
+	mediaTypeSuffix = "+json"
 )
 
+// MediaType returns the media type of the responses of the version of the
+// API e.g. application/vnd.dwarka.v1+json
+func MediaType(version string) string {
+	return mediaTypePrefix + version + mediaTypeSuffix
+}
+
 type handlerInfo struct {
 	method    []byte
 	path      []byte
@@ -22,18 +51,157 @@ var startMeasure = func(ctx *atreugo.RequestCtx) error {
 		path:      ctx.Path(),
 		startTime: time.Now(),
 	})
-	return ctx.Next()
+	return next(ctx)
 }
 
+var (
+	requestsServed = metrics.Default.NewCounter("dwarka_http_requests_total",
+		"Requests served by method, route template and status code", "method", "route", "status")
+	requestDuration = metrics.Default.NewHistogram("dwarka_http_request_duration_seconds",
+		"Latency of the requests by method and route template", metrics.DefaultBuckets, "method", "route")
+)
+
 var stopMeasure = func(ctx *atreugo.RequestCtx) error {
+	measure(ctx, nil)
+	return next(ctx)
+}
+
+// measure logs the request and records it in the metrics once, the status
+// of a request failed with an error is the one of the error view
+func measure(ctx *atreugo.RequestCtx, err error) {
 	info, ok := ctx.UserValue("info").(handlerInfo)
-	if ok {
-		duration := time.Since(info.startTime)
-		ctx.Logger().Printf("%s - %d - %s", info.path, ctx.Response.StatusCode(), timeUnit(duration))
+	if measured, _ := ctx.UserValue(measuredKey).(bool); !ok || measured {
+		return
+	}
+	ctx.SetUserValue(measuredKey, true)
+
+	status := ctx.Response.StatusCode()
+	if err != nil && status == fasthttp.StatusOK {
+		status = fasthttp.StatusInternalServerError
 	}
+	duration := time.Since(info.startTime)
+	route := string(ctx.MatchedRoutePath())
+	requestsServed.Inc(string(info.method), route, strconv.Itoa(status))
+	requestDuration.Observe(duration.Seconds(), string(info.method), route)
+	ctx.Logger().Printf("%s - %d - %s", info.path, status, timeUnit(duration))
+}
+
+// next passes the request on to the next middleware
+func next(ctx *atreugo.RequestCtx) error {
+	ctx.SetUserValue(passedKey, true)
 	return ctx.Next()
 }
 
+// responding wraps the middleware of a route to measure the request when
+// the middleware responds instead of passing the request on
+func responding(middleware atreugo.Middleware) atreugo.Middleware {
+	return func(ctx *atreugo.RequestCtx) error {
+		ctx.SetUserValue(passedKey, false)
+		err := middleware(ctx)
+		if passed, _ := ctx.UserValue(passedKey).(bool); err != nil || !passed {
+			measure(ctx, err)
+		}
+		return err
+	}
+}
+
+func deprecation(successor string) atreugo.Middleware {
+	return func(ctx *atreugo.RequestCtx) error {
+		ctx.Response.Header.Set("Deprecation", "true")
+		ctx.Response.Header.Set("Link", fmt.Sprintf("<%s%s>; rel=\"successor-version\"", successor, ctx.Path()))
+		return next(ctx)
+	}
+}
+
+// negotiate serves the request when the Accept header names no version of
+// the API or names the version of the route, the responses are then of the
+// media type of the version
+func negotiate(version string) atreugo.Middleware {
+	return func(ctx *atreugo.RequestCtx) error {
+		versions, named := acceptedVersions(string(ctx.Request.Header.Peek("Accept")))
+		if !named {
+			return next(ctx)
+		}
+		for _, accepted := range versions {
+			if accepted == version {
+				ctx.SetUserValue(mediaTypeKey, MediaType(version))
+				return next(ctx)
+			}
+		}
+		return ctx.JSONResponse(map[string]string{"error": fmt.Sprintf("%s is served as %s", ctx.Path(), MediaType(version))}, fasthttp.StatusNotAcceptable)
+	}
+}
+
+// acceptedVersions returns the versions of the API named by the media
+// ranges of the Accept header which are not refused with q=0 along with
+// whether the header names a version at all
+func acceptedVersions(accept string) ([]string, bool) {
+	var versions []string
+	named := false
+	for _, mediaRange := range strings.Split(accept, ",") {
+		params := strings.Split(mediaRange, ";")
+		mediaType := strings.ToLower(strings.TrimSpace(params[0]))
+		if !strings.HasPrefix(mediaType, mediaTypePrefix) || !strings.HasSuffix(mediaType, mediaTypeSuffix) {
+			continue
+		}
+		named = true
+		refused := false
+		for _, param := range params[1:] {
+			if q, err := strconv.ParseFloat(strings.TrimPrefix(strings.TrimSpace(param), "q="), 64); err == nil && q == 0 {
+				refused = true
+			}
+		}
+		if !refused {
+			versions = append(versions, strings.TrimSuffix(strings.TrimPrefix(mediaType, mediaTypePrefix), mediaTypeSuffix))
+		}
+	}
+	return versions, named
+}
+
+// versioned sets the media type of the negotiated version on the JSON
+// responses
+func versioned(ctx *atreugo.RequestCtx) error {
+	mediaType, ok := ctx.UserValue(mediaTypeKey).(string)
+	if ok && strings.HasPrefix(string(ctx.Response.Header.ContentType()), "application/json") {
+		ctx.Response.Header.SetContentType(mediaType)
+	}
+	return next(ctx)
+}
+
+func (server HTTPServer) authenticate(ctx *atreugo.RequestCtx) error {
+	authenticator := server.authentication.authenticator
+	if authenticator == nil {
+		return next(ctx)
+	}
+
+	credentials := Credentials{Token: string(ctx.Request.Header.Peek("X-API-Key"))}
+	if authorization := string(ctx.Request.Header.Peek("Authorization")); strings.HasPrefix(authorization, bearerPrefix) {
+		credentials.Token = strings.TrimPrefix(authorization, bearerPrefix)
+	}
+	if ctx.IsTLS() {
+		if state := ctx.TLSConnectionState(); state != nil && len(state.VerifiedChains) > 0 {
+			credentials.ClientCertificate = state.VerifiedChains[0][0].Subject.CommonName
+		}
+	}
+	if credentials.Token == "" && credentials.ClientCertificate == "" {
+		return unauthorized(ctx, auth.Unauthorized("credentials are missing, use bearer authorization or X-API-Key header"))
+	}
+
+	token, err := authenticator(server.store, credentials)
+	if _, ok := err.(auth.Unauthorized); ok {
+		return unauthorized(ctx, err)
+	} else if err != nil {
+		return ctx.JSONResponse(map[string]string{"error": err.Error()}, fasthttp.StatusInternalServerError)
+	}
+	ctx.SetUserValue(TokenKey, token)
+	return next(ctx)
+}
+
+func unauthorized(ctx *atreugo.RequestCtx, err error) error {
+	ctx.Response.Header.Set("WWW-Authenticate", `Bearer realm="dwarka"`)
+	return ctx.JSONResponse(map[string]string{"error": err.Error()}, fasthttp.StatusUnauthorized)
+}
+
 func timeUnit(duration time.Duration) string {
 	us := duration.Microseconds()
 	ms := duration.Milliseconds()
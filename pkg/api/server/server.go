@@ -5,6 +5,7 @@ import (
 	"github.com/savsgio/atreugo/v11"
 	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/store"
 	"net"
+	"strings"
 	"time"
 )
 
@@ -16,12 +17,23 @@ type Server interface {
 	ListenAndServe() error
 	Serve(ln net.Listener) error
 	Path(route Route)
+	Group(prefix string) Server
+	Deprecated(successor string) Server
+	Authenticate(authenticator Authenticator)
 }
 
 // HTTPServer represents atreugo server backed by libkv/PersistentStore
 type HTTPServer struct {
 	atreugo *atreugo.Atreugo
+	router  *atreugo.Router
 	store   store.Store
+	// authentication is shared by the groups so that authentication
+	// enabled after binding the routes applies to every route
+	authentication *authentication
+}
+
+type authentication struct {
+	authenticator Authenticator
 }
 
 // ListenAndServe binds the http server to the bind-address/bind-port
@@ -43,8 +55,9 @@ func (server HTTPServer) Serve(ln net.Listener) error {
 func (server HTTPServer) filters(handlers []ResponseHandler) []atreugo.Middleware {
 	filters := make([]atreugo.Middleware, 0, len(handlers))
 	for _, filter := range handlers {
+		filter := filter
 		filters = append(filters, func(ctx *atreugo.RequestCtx) error {
-			return filter(server.store, ctx)
+			return filter(server.store, requestContext{ctx})
 		})
 	}
 	return filters
@@ -52,20 +65,105 @@ func (server HTTPServer) filters(handlers []ResponseHandler) []atreugo.Middlewar
 
 // Path binds a route to HTTPServer for handling request
 func (server HTTPServer) Path(route Route) {
-	path := server.atreugo.Path(route.httpMethod, route.url, func(ctx *atreugo.RequestCtx) error {
-		return route.handler(server.store, ctx)
+	path := server.router.Path(route.httpMethod, route.url, func(ctx *atreugo.RequestCtx) error {
+		err := route.handler(server.store, requestContext{ctx})
+		if err != nil {
+			measure(ctx, err)
+		}
+		return err
 	})
 
+	middlewares := atreugo.Middlewares{}
+	if !route.public {
+		middlewares.Before = append(middlewares.Before, server.authenticate)
+	}
 	if route.filters != nil {
-		path.Middlewares(atreugo.Middlewares{
-			Before: server.filters(route.filters.Before),
-			After:  server.filters(route.filters.After),
-		})
+		middlewares.Before = append(middlewares.Before, server.filters(route.filters.Before)...)
+		middlewares.After = server.filters(route.filters.After)
 	}
+	// the requests the middlewares respond to are measured by them as the
+	// middlewares after them including stopMeasure are not run
+	for i, middleware := range middlewares.Before {
+		middlewares.Before[i] = responding(middleware)
+	}
+	for i, middleware := range middlewares.After {
+		middlewares.After[i] = responding(middleware)
+	}
+	path.Middlewares(middlewares)
+}
+
+// Authenticate requires the credentials of every route except the public
+// ones, the credentials are read from the bearer authorization or the
+// X-API-Key header, or the verified client certificate when served over
+// mutual TLS, and verified using the authenticator
+func (server HTTPServer) Authenticate(authenticator Authenticator) {
+	server.authentication.authenticator = authenticator
+}
+
+// Group returns a Server which binds the routes under the given prefix,
+// it is used to serve multiple versions of the API side by side. The prefix
+// is the version e.g. /v1, the requests accepting only the media types of
+// the other versions are responded with 406 Not Acceptable
+func (server HTTPServer) Group(prefix string) Server {
+	router := server.router.NewGroupPath(prefix)
+	negotiated(router, strings.TrimPrefix(prefix, "/"))
+	return &HTTPServer{atreugo: server.atreugo, router: router, store: server.store, authentication: server.authentication}
+}
+
+// Deprecated returns a Server which binds the routes as deprecated aliases,
+// every response carries a Deprecation header and a Link to the same path
+// under the successor prefix, the media types are negotiated as the ones
+// of the successor
+func (server HTTPServer) Deprecated(successor string) Server {
+	router := server.router.NewGroupPath("")
+	router.UseBefore(deprecation(successor))
+	negotiated(router, strings.TrimPrefix(successor, "/"))
+	return &HTTPServer{atreugo: server.atreugo, router: router, store: server.store, authentication: server.authentication}
+}
+
+func negotiated(router *atreugo.Router, version string) {
+	router.UseBefore(responding(negotiate(version)))
+	router.UseAfter(versioned)
+}
+
+// requestContext adapts atreugo.RequestCtx to RequestContext
+type requestContext struct {
+	*atreugo.RequestCtx
+}
+
+// Next passes the request on to the next filter
+func (ctx requestContext) Next() error {
+	return next(ctx.RequestCtx)
+}
+
+// RequestHeader returns the value of the request header
+func (ctx requestContext) RequestHeader(key string) []byte {
+	return ctx.Request.Header.Peek(key)
+}
+
+// ResponseStatusCode returns the status code of the response, it is
+// used by the after filters
+func (ctx requestContext) ResponseStatusCode() int {
+	return ctx.Response.StatusCode()
+}
+
+// ResponseBody returns the body of the response
+func (ctx requestContext) ResponseBody() []byte {
+	return ctx.Response.Body()
 }
 
 // NewHTTPServer returns a abstracted HTTP server
 func NewHTTPServer(host, port string, store store.Store) Server {
+	return newHTTPServer(host, port, store, nil)
+}
+
+// NewHTTPSServer returns a abstracted HTTPS server, the connections are
+// served using the latest certificates
+func NewHTTPSServer(host, port string, store store.Store, certificates *Certificates) Server {
+	return newHTTPServer(host, port, store, certificates)
+}
+
+func newHTTPServer(host, port string, store store.Store, certificates *Certificates) Server {
 	config := atreugo.Config{
 		Name:              "dwarka",
 		ReduceMemoryUsage: false,
@@ -74,8 +172,13 @@ func NewHTTPServer(host, port string, store store.Store) Server {
 		WriteTimeout:      time.Second * 1,
 		ReadTimeout:       time.Second * 1,
 	}
+	if certificates != nil {
+		config.TLSEnable = true
+		config.TLSConfig = certificates.Config()
+	}
 	server := atreugo.New(config)
+	server.SaveMatchedRoutePath(true)
 	server.UseBefore(startMeasure)
 	server.UseAfter(stopMeasure)
-	return &HTTPServer{atreugo: server, store: store}
+	return &HTTPServer{atreugo: server, router: server.Router, store: store, authentication: &authentication{}}
 }
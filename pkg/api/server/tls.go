@@ -0,0 +1,149 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// TLS represents the files to serve HTTPS, the client CA requires the
+// clients to present a certificate signed by it
+type TLS struct {
+	CertFile     string
+	KeyFile      string
+	ClientCAFile string
+	// ClientCertificateOptional verifies the client certificate only when
+	// given so that the clients are able to use the tokens instead
+	ClientCertificateOptional bool
+}
+
+// Certificates holds the certificate of the server and the client CAs
+// loaded from the files, Reload replaces them when the files change so
+// that the renewed certificates apply to the new connections
+type Certificates struct {
+	config       TLS
+	mutex        sync.RWMutex
+	certificate  *tls.Certificate
+	clientCAs    *x509.CertPool
+	modification string
+}
+
+// NewCertificates loads the certificate, the key and the client CA
+func NewCertificates(config TLS) (*Certificates, error) {
+	if config.CertFile == "" || config.KeyFile == "" {
+		return nil, errors.New("both the certificate and the key are required to serve https")
+	}
+
+	certificates := &Certificates{config: config}
+	_, err := certificates.Reload()
+	if err != nil {
+		return nil, err
+	}
+	return certificates, nil
+}
+
+// Reload loads the files again when they are modified, it returns true
+// when the certificates are replaced. The certificates in use are kept
+// when the files fail to load e.g. while they are being written
+func (certificates *Certificates) Reload() (bool, error) {
+	modification, err := certificates.modified()
+	if err != nil {
+		return false, err
+	}
+
+	certificates.mutex.RLock()
+	unchanged := modification == certificates.modification
+	certificates.mutex.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	certificate, err := tls.LoadX509KeyPair(certificates.config.CertFile, certificates.config.KeyFile)
+	if err != nil {
+		return false, fmt.Errorf("unable to load the certificate, reason: %v", err)
+	}
+
+	var clientCAs *x509.CertPool
+	if certificates.config.ClientCAFile != "" {
+		data, err := os.ReadFile(certificates.config.ClientCAFile)
+		if err != nil {
+			return false, fmt.Errorf("unable to load the client CA, reason: %v", err)
+		}
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(data) {
+			return false, fmt.Errorf("client CA %s has no certificates", certificates.config.ClientCAFile)
+		}
+	}
+
+	certificates.mutex.Lock()
+	defer certificates.mutex.Unlock()
+	certificates.certificate = &certificate
+	certificates.clientCAs = clientCAs
+	certificates.modification = modification
+	return true, nil
+}
+
+// Watch reloads the certificates every interval until done is closed
+func (certificates *Certificates) Watch(interval time.Duration, done <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			reloaded, err := certificates.Reload()
+			if err != nil {
+				log.Printf("keeping the certificates in use, reason: %v", err)
+			} else if reloaded {
+				log.Printf("reloaded the certificates from %s", certificates.config.CertFile)
+			}
+		}
+	}
+}
+
+// Config returns the TLS configuration reading the latest certificates
+// on every handshake
+func (certificates *Certificates) Config() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			certificates.mutex.RLock()
+			defer certificates.mutex.RUnlock()
+
+			config := &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*certificates.certificate},
+			}
+			if certificates.clientCAs != nil {
+				config.ClientCAs = certificates.clientCAs
+				config.ClientAuth = tls.RequireAndVerifyClientCert
+				if certificates.config.ClientCertificateOptional {
+					config.ClientAuth = tls.VerifyClientCertIfGiven
+				}
+			}
+			return config, nil
+		},
+	}
+}
+
+// modified returns the modification time and the size of the files
+func (certificates *Certificates) modified() (string, error) {
+	modification := ""
+	for _, file := range []string{certificates.config.CertFile, certificates.config.KeyFile, certificates.config.ClientCAFile} {
+		if file == "" {
+			continue
+		}
+		info, err := os.Stat(file)
+		if err != nil {
+			return "", err
+		}
+		modification += fmt.Sprintf("%s:%d:%d;", file, info.ModTime().UnixNano(), info.Size())
+	}
+	return modification, nil
+}
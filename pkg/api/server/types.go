@@ -1,6 +1,8 @@
 package server
 
 import (
+	"github.com/valyala/fasthttp"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/auth"
 	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/store"
 )
 
@@ -10,13 +12,33 @@ import (
 type RequestContext interface {
 	JSONResponse(body interface{}, statusCode ...int) error
 	PostBody() []byte
+	RequestHeader(key string) []byte
+	Method() []byte
+	Path() []byte
+	QueryArgs() *fasthttp.Args
 	UserValue(key interface{}) interface{}
 	SetStatusCode(statusCode int)
 	SetBodyString(body string)
+	SetContentType(contentType string)
 	Next() error
 	SetUserValue(key interface{}, value interface{})
+	ResponseStatusCode() int
+	ResponseBody() []byte
 }
 
+// Credentials represents the credentials presented by a request
+type Credentials struct {
+	// Token is the bearer token or the API key
+	Token string
+	// ClientCertificate is the common name of the verified client
+	// certificate when served over mutual TLS
+	ClientCertificate string
+}
+
+// Authenticator returns the token of the credentials, auth.Unauthorized
+// errors are responded with 401
+type Authenticator func(store store.Store, credentials Credentials) (auth.Token, error)
+
 // ResponseHandler represents a function for responding to http request
 type ResponseHandler func(store store.Store, ctx RequestContext) error
 
@@ -26,10 +48,58 @@ type RequestHandler func(httpMethod, url string, handler ResponseHandler)
 // Route represents a http route represented by httpMethod, url and
 // response handler
 type Route struct {
-	httpMethod string
-	url        string
-	handler    ResponseHandler
-	filters    *Filters
+	httpMethod    string
+	url           string
+	handler       ResponseHandler
+	filters       *Filters
+	documentation *Documentation
+	public        bool
+}
+
+// Documentation describes a route for the API specification,
+// Request and Response are sample values of the body whose
+// type is used to derive the schema
+type Documentation struct {
+	Summary  string
+	Tags     []string
+	Query    map[string]string
+	Request  interface{}
+	Response interface{}
+	Status   int
+	Errors   []int
+}
+
+// Method returns the http method of the route
+func (route Route) Method() string {
+	return route.httpMethod
+}
+
+// URL returns the url template of the route
+func (route Route) URL() string {
+	return route.url
+}
+
+// Documentation returns the documentation of the route, nil
+// when the route is not documented
+func (route Route) Documentation() *Documentation {
+	return route.documentation
+}
+
+// IsPublic returns true when the route is served without authentication
+func (route Route) IsPublic() bool {
+	return route.public
+}
+
+// Public returns a copy of the route which is served without authentication
+func (route Route) Public() Route {
+	route.public = true
+	return route
+}
+
+// Describe returns a copy of the route with the documentation attached
+func (route Route) Describe(documentation Documentation) Route {
+	route.documentation = &documentation
+	return route
 }
 
 // Filters like middlewares, but for specific paths.
@@ -0,0 +1,44 @@
+package api
+
+import (
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/valyala/fasthttp"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/server"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/view"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/auth"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/store"
+)
+
+const dateParam = "date"
+
+func init() {
+	AddRoute(
+		server.NewRouteWithFilters("GET", path.Join(buildingPath(), "sun"), sunHandler, authorized(auth.Read, findAndLoadBuilding)).Describe(server.Documentation{
+			Summary: "Get the civil dawn, the sunrise, the sunset and the civil dusk at the building", Tags: []string{"buildings"},
+			Query:    map[string]string{dateParam: "date given as 2006-01-02, today in UTC by default"},
+			Response: view.Sun{}, Errors: []int{fasthttp.StatusBadRequest},
+		}),
+	)
+}
+
+var sunHandler = func(store store.Store, ctx server.RequestContext) error {
+	building, ok := ctx.UserValue(buildingUserKey).(gateway.Building)
+	if !ok {
+		return notFound(ctx)
+	}
+
+	date := time.Now().UTC()
+	if raw := string(ctx.QueryArgs().Peek(dateParam)); raw != "" {
+		parsed, err := time.Parse("2006-01-02", raw)
+		if err != nil {
+			return badRequest(ctx, fmt.Errorf("date %s should be given as yyyy-mm-dd e.g. 2026-10-19", raw))
+		}
+		date = parsed
+	}
+
+	return ctx.JSONResponse(view.NewSun(building, date), fasthttp.StatusOK)
+}
@@ -0,0 +1,70 @@
+package api_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/view"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+	mockStore "gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/internal/mocks/store"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/testutils"
+)
+
+func TestSun(t *testing.T) {
+	newBuildings := func(latitude float64) gateway.Buildings {
+		building := gateway.Building{Lat: latitude, Lan: 10.39, PhysicalEntity: gateway.PhysicalEntity{Name: "building-one"}}
+		return gateway.Buildings{building.ID(): building}
+	}
+	serve := func(t *testing.T, buildings gateway.Buildings, url string) *http.Response {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockKVStore := mockStore.NewMockStore(ctrl)
+		mockKVStore.EXPECT().Buildings().Return(buildings, nil)
+
+		request, _ := http.NewRequest("GET", url, nil)
+		res, err := testutils.ServeHTTPRequest(mockKVStore, request)
+		assert.NoError(t, err)
+		return res
+	}
+
+	t.Run("should return the solar events of the date at the building", func(t *testing.T) {
+		res := serve(t, newBuildings(12.97), "http://test/buildings/building-one/sun?date=2026-10-19")
+
+		assert.Equal(t, fasthttp.StatusOK, res.StatusCode)
+		sun := view.Sun{}
+		if assert.NoError(t, testutils.Read(res, &sun)) {
+			assert.Equal(t, "building-one", sun.Building)
+			assert.Equal(t, "2026-10-19", sun.Date)
+			for _, at := range []*time.Time{sun.Dawn, sun.Sunrise, sun.Sunset, sun.Dusk} {
+				if assert.NotNil(t, at) {
+					assert.Equal(t, "2026-10-19", at.Format("2006-01-02"))
+				}
+			}
+			assert.True(t, sun.Dawn.Before(*sun.Sunrise) && sun.Sunrise.Before(*sun.Sunset) && sun.Sunset.Before(*sun.Dusk))
+		}
+	})
+
+	t.Run("should leave out the events which do not occur on the date", func(t *testing.T) {
+		res := serve(t, newBuildings(63.43), "http://test/buildings/building-one/sun?date=2026-06-21")
+
+		sun := view.Sun{}
+		if assert.NoError(t, testutils.Read(res, &sun)) {
+			assert.NotNil(t, sun.Sunset)
+			assert.Nil(t, sun.Dawn)
+			assert.Nil(t, sun.Dusk)
+		}
+	})
+
+	t.Run("should reject invalid date", func(t *testing.T) {
+		res := serve(t, newBuildings(12.97), "http://test/buildings/building-one/sun?date=19/10/2026")
+
+		assert.Equal(t, fasthttp.StatusBadRequest, res.StatusCode)
+		message, err := testutils.ReadError(res)
+		assert.NoError(t, err)
+		assert.Equal(t, "date 19/10/2026 should be given as yyyy-mm-dd e.g. 2026-10-19", message)
+	})
+}
@@ -0,0 +1,152 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/valyala/fasthttp"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/server"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/view"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/auth"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/automation"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/store"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/telemetry"
+)
+
+const (
+	metricName        = "metric"
+	fromParam         = "from"
+	toParam           = "to"
+	stepParam         = "step"
+	defaultMetricSpan = 24 * time.Hour
+)
+
+var telemetryDB *telemetry.DB
+
+// EnableTelemetry ingests the readings published on the tele/ topics and
+// serves the metrics of the devices, the metric routes respond with 501
+// until the telemetry is enabled
+func EnableTelemetry(db *telemetry.DB) {
+	telemetryDB = db
+}
+
+// readingsOf returns the readings of an mqtt event on a tele/ topic, none
+// when the telemetry is disabled or the topic carries no readings
+func readingsOf(event automation.Event) ([]telemetry.Reading, error) {
+	if event.Kind != automation.EventMQTT || !telemetry.IsTelemetry(event.Topic) || telemetryDB == nil {
+		return nil, nil
+	}
+	return telemetry.Parse(event.Building, event.Topic, event.Payload, telemetryDB.Clock())
+}
+
+// appendReadings stores the readings and marks their device as online
+func appendReadings(readings []telemetry.Reading) error {
+	if len(readings) == 0 {
+		return nil
+	}
+	err := telemetryDB.Append(readings...)
+	if err != nil {
+		return err
+	}
+	series := readings[0].Series
+	devicesSeen.mark(path.Join(series.Building, series.Floor, series.Room, series.Device), time.Now())
+	return nil
+}
+
+func metricsBasePath() string {
+	return path.Join(devicePath(), "metrics")
+}
+
+func init() {
+	tags := []string{"telemetry"}
+	AddRoute(
+		server.NewRouteWithFilters("GET", metricsBasePath(), listMetricsHandler, authorized(auth.Read, telemetryEnabled, findAndLoadDevice)).Describe(server.Documentation{
+			Summary: "List the metrics of the device having readings", Tags: tags, Response: view.Metrics{},
+			Errors: []int{fasthttp.StatusNotImplemented},
+		}),
+		server.NewRouteWithFilters("GET", path.Join(metricsBasePath(), fmt.Sprintf("{%s}", metricName)), metricHandler, authorized(auth.Read, telemetryEnabled, findAndLoadDevice)).Describe(server.Documentation{
+			Summary: "Get the readings of a metric of the device aggregated into points", Tags: tags,
+			Query: map[string]string{
+				fromParam: "RFC 3339 time of the first reading e.g. 2026-10-19T00:00:00Z, a day before to by default",
+				toParam:   "RFC 3339 time until which the readings are returned, now by default",
+				stepParam: "interval of the points e.g. 5m or 1h, the resolution of the readings by default",
+			},
+			Response: view.Metric{}, Errors: []int{fasthttp.StatusBadRequest, fasthttp.StatusNotImplemented},
+		}),
+	)
+}
+
+var telemetryEnabled = func(store store.Store, ctx server.RequestContext) error {
+	if telemetryDB == nil {
+		err := errors.New("telemetry is not enabled, start the server with a telemetry file")
+		return ctx.JSONResponse(map[string]string{"error": err.Error()}, fasthttp.StatusNotImplemented)
+	}
+	return ctx.Next()
+}
+
+// deviceSeries returns the series of the metric of the device
+func deviceSeries(device gateway.Device, metric string) telemetry.Series {
+	floor := device.Room.Floor
+	return telemetry.Series{Building: floor.Building.ID(), Floor: floor.ID(), Room: device.Room.ID(), Device: device.ID(), Metric: metric}
+}
+
+var listMetricsHandler = func(store store.Store, ctx server.RequestContext) error {
+	device, ok := ctx.UserValue(deviceUserKey).(gateway.Device)
+	if !ok {
+		return notFound(ctx)
+	}
+
+	series := deviceSeries(device, "")
+	metrics, err := telemetryDB.Metrics(path.Join(series.Building, series.Floor, series.Room, series.Device))
+	if err != nil {
+		return internalServerError(ctx, err)
+	}
+	return ctx.JSONResponse(view.Metrics{Device: device.ID(), Metrics: metrics}, fasthttp.StatusOK)
+}
+
+var metricHandler = func(store store.Store, ctx server.RequestContext) error {
+	device, ok := ctx.UserValue(deviceUserKey).(gateway.Device)
+	if !ok {
+		return notFound(ctx)
+	}
+	metric, _ := ctx.UserValue(metricName).(string)
+	series := deviceSeries(device, metric)
+	if err := series.Validate(); err != nil {
+		return badRequest(ctx, err)
+	}
+
+	args := ctx.QueryArgs()
+	to := telemetryDB.Clock()
+	if raw := string(args.Peek(toParam)); raw != "" {
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return badRequest(ctx, fmt.Errorf("%s should be an RFC 3339 time e.g. 2026-10-19T03:00:00Z", toParam))
+		}
+		to = parsed
+	}
+	from := to.Add(-defaultMetricSpan)
+	if raw := string(args.Peek(fromParam)); raw != "" {
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return badRequest(ctx, fmt.Errorf("%s should be an RFC 3339 time e.g. 2026-10-19T00:00:00Z", fromParam))
+		}
+		from = parsed
+	}
+	var step time.Duration
+	if raw := string(args.Peek(stepParam)); raw != "" {
+		parsed, err := time.ParseDuration(raw)
+		if err != nil || parsed < time.Second {
+			return badRequest(ctx, fmt.Errorf("%s should be a duration of at least a second e.g. 5m", stepParam))
+		}
+		step = parsed
+	}
+
+	points, resolution, err := telemetryDB.Query(series, from, to, step)
+	if err != nil {
+		return badRequest(ctx, err)
+	}
+	return ctx.JSONResponse(view.NewMetric(series, resolution, step, points), fasthttp.StatusOK)
+}
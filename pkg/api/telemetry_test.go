@@ -0,0 +1,137 @@
+package api_test
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/view"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/auth"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/automation"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/telemetry"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/testutils"
+)
+
+const metricsURL = "/buildings/building-one/floors/floor-one/rooms/room-one/devices/porch-light/metrics"
+
+// enableTelemetry enables the telemetry with a clock which moves a minute
+// on every reading from 2026-10-19T06:00:00Z
+func enableTelemetry(t *testing.T) *telemetry.DB {
+	db, err := telemetry.Open(filepath.Join(t.TempDir(), "telemetry.db"), telemetry.DefaultRetention)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 10, 19, 6, 0, 0, 0, time.UTC)
+	db.Clock = func() time.Time {
+		now = now.Add(time.Minute)
+		return now
+	}
+	api.EnableTelemetry(db)
+	t.Cleanup(func() {
+		api.EnableTelemetry(nil)
+		_ = db.Close()
+	})
+	return db
+}
+
+func TestTelemetry(t *testing.T) {
+	t.Run("should ingest the readings of the tele topics and serve the metrics", func(t *testing.T) {
+		persistentStore := enableAuthentication(t)
+		seedStore(t, persistentStore)
+		enableTelemetry(t)
+		operator := issueToken(t, persistentStore, auth.RoleOperator)
+		viewer := issueToken(t, persistentStore, auth.RoleViewer)
+
+		for _, payload := range []string{`{"Power":40,"Voltage":230}`, `{"Power":60,"Voltage":231}`} {
+			res := serveBodyAs(t, persistentStore, operator, "POST", "/buildings/building-one/events",
+				`{"kind":"mqtt","topic":"tele/floor-one/room-one/porch-light/SENSOR","payload":`+quote(payload)+`}`)
+			assert.Equal(t, fasthttp.StatusAccepted, res.StatusCode)
+		}
+
+		res := serveAs(t, persistentStore, viewer, "GET", metricsURL)
+		metrics := view.Metrics{}
+		if assert.NoError(t, testutils.Read(res, &metrics)) {
+			assert.Equal(t, view.Metrics{Device: "porch-light", Metrics: []string{"power", "voltage"}}, metrics)
+		}
+
+		res = serveAs(t, persistentStore, viewer, "GET", metricsURL+"/power?from=2026-10-19T06:00:00Z&to=2026-10-19T07:00:00Z&step=1h")
+		assert.Equal(t, fasthttp.StatusOK, res.StatusCode)
+		metric := view.Metric{}
+		if assert.NoError(t, testutils.Read(res, &metric)) && assert.Len(t, metric.Points, 1) {
+			assert.Equal(t, "floor-one/room-one/porch-light", metric.Device)
+			assert.Equal(t, "1h", metric.Step)
+			assert.Equal(t, "1h", metric.Resolution)
+			assert.Equal(t, int64(2), metric.Points[0].Count)
+			assert.Equal(t, 50.0, metric.Points[0].Mean)
+			assert.Equal(t, 60.0, metric.Points[0].Last)
+		}
+	})
+
+	t.Run("should accept the readings stored when the automation queue is full", func(t *testing.T) {
+		persistentStore := enableAuthentication(t)
+		seedStore(t, persistentStore)
+		db := enableTelemetry(t)
+		operator := issueToken(t, persistentStore, auth.RoleOperator)
+		engine := automation.NewEngine(persistentStore, automation.NewManualClock(time.Now()))
+		api.EnableAutomation(engine)
+		t.Cleanup(func() { api.EnableAutomation(nil) })
+		for engine.Publish(automation.Event{Kind: automation.EventMQTT, Building: "building-one", Topic: "home/hall/motion"}) {
+		}
+
+		res := serveBodyAs(t, persistentStore, operator, "POST", "/buildings/building-one/events",
+			`{"kind":"mqtt","topic":"tele/floor-one/room-one/porch-light/power","payload":"40"}`)
+		assert.Equal(t, fasthttp.StatusAccepted, res.StatusCode)
+		res = serveBodyAs(t, persistentStore, operator, "POST", "/buildings/building-one/events",
+			`{"kind":"mqtt","topic":"home/hall/motion","payload":"on"}`)
+		assert.Equal(t, fasthttp.StatusServiceUnavailable, res.StatusCode)
+
+		series := telemetry.Series{Building: "building-one", Floor: "floor-one", Room: "room-one", Device: "porch-light", Metric: "power"}
+		points, _, err := db.Query(series, time.Date(2026, 10, 19, 6, 0, 0, 0, time.UTC), time.Date(2026, 10, 19, 7, 0, 0, 0, time.UTC), time.Hour)
+		if assert.NoError(t, err) && assert.Len(t, points, 1) {
+			assert.Equal(t, int64(1), points[0].Count)
+		}
+	})
+
+	t.Run("should reject invalid readings and queries", func(t *testing.T) {
+		persistentStore := enableAuthentication(t)
+		seedStore(t, persistentStore)
+		enableTelemetry(t)
+		operator := issueToken(t, persistentStore, auth.RoleOperator)
+
+		res := serveBodyAs(t, persistentStore, operator, "POST", "/buildings/building-one/events",
+			`{"kind":"mqtt","topic":"tele/floor-one/room-one/porch-light/power","payload":"high"}`)
+		assert.Equal(t, fasthttp.StatusBadRequest, res.StatusCode)
+
+		for url, message := range map[string]string{
+			metricsURL + "/power?from=yesterday":                                    "from should be an RFC 3339 time e.g. 2026-10-19T00:00:00Z",
+			metricsURL + "/power?step=1ms":                                          "step should be a duration of at least a second e.g. 5m",
+			metricsURL + "/power?from=2026-10-20T00:00:00Z&to=2026-10-19T00:00:00Z": "from should be before to",
+			metricsURL + "/Power":                                                   "metric 'Power' should have up to 63 lower case letters, digits, '.', '-' or '_' e.g. temperature",
+		} {
+			res = serveAs(t, persistentStore, operator, "GET", url)
+			assert.Equal(t, fasthttp.StatusBadRequest, res.StatusCode, url)
+			actual, err := testutils.ReadError(res)
+			assert.NoError(t, err)
+			assert.Equal(t, message, actual, url)
+		}
+	})
+
+	t.Run("should get 501 until the telemetry is enabled", func(t *testing.T) {
+		persistentStore := enableAuthentication(t)
+		seedStore(t, persistentStore)
+		viewer := issueToken(t, persistentStore, auth.RoleViewer)
+
+		res := serveAs(t, persistentStore, viewer, "GET", metricsURL+"/power")
+
+		assert.Equal(t, fasthttp.StatusNotImplemented, res.StatusCode)
+	})
+}
+
+func quote(value string) string {
+	data, _ := json.Marshal(value)
+	return string(data)
+}
@@ -0,0 +1,13 @@
+package api
+
+import (
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/server"
+)
+
+var certificates *server.Certificates
+
+// EnableTLS serves HTTPS using the certificates for the servers created
+// afterwards, nil serves plaintext HTTP
+func EnableTLS(tlsCertificates *server.Certificates) {
+	certificates = tlsCertificates
+}
@@ -0,0 +1,192 @@
+package api_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/server"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/auth"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/certs"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/store"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/testutils"
+)
+
+// enableTLS generates the certificates along with the client certificates
+// of the clients and serves HTTPS until the end of the test, it returns
+// the directory of the certificates
+func enableTLS(t *testing.T, optional bool, clients ...string) string {
+	dir := filepath.Join(t.TempDir(), "certs")
+	_, err := certs.Generate(dir, []string{"127.0.0.1"}, clients, time.Now(), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certificates, err := server.NewCertificates(server.TLS{
+		CertFile:                  filepath.Join(dir, certs.ServerFile),
+		KeyFile:                   filepath.Join(dir, certs.ServerKeyFile),
+		ClientCAFile:              filepath.Join(dir, certs.CAFile),
+		ClientCertificateOptional: optional,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	api.EnableTLS(certificates)
+	t.Cleanup(func() {
+		api.EnableTLS(nil)
+	})
+	return dir
+}
+
+// serveTLS serves the api over a local listener and returns the client
+// presenting the certificate of the client unless it is empty
+func serveTLS(t *testing.T, persistentStore store.Store, dir, client string) (*http.Client, string) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		_ = api.NewServer("", "", persistentStore).Serve(ln)
+	}()
+	t.Cleanup(func() {
+		_ = ln.Close()
+	})
+
+	data, err := os.ReadFile(filepath.Join(dir, certs.CAFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := &tls.Config{RootCAs: x509.NewCertPool()}
+	config.RootCAs.AppendCertsFromPEM(data)
+	if client != "" {
+		certificate, err := tls.LoadX509KeyPair(filepath.Join(dir, client+".pem"), filepath.Join(dir, client+"-key.pem"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		config.Certificates = []tls.Certificate{certificate}
+	}
+	return &http.Client{Transport: &http.Transport{TLSClientConfig: config}}, "https://" + ln.Addr().String()
+}
+
+func TestTLS(t *testing.T) {
+	t.Run("should authenticate the client certificate mapped to a token", func(t *testing.T) {
+		persistentStore := enableAuthentication(t)
+		dir := enableTLS(t, true, "kitchen-panel", "hall-panel")
+		token, err := auth.NewCertificate("kitchen-panel", time.Now(), 0)
+		assert.NoError(t, err)
+		token.Role = auth.RoleViewer
+		assert.NoError(t, persistentStore.UpsertToken(token))
+
+		client, url := serveTLS(t, persistentStore, dir, "kitchen-panel")
+		res, err := client.Get(url + "/v1/buildings")
+		if assert.NoError(t, err) {
+			assert.Equal(t, fasthttp.StatusOK, res.StatusCode)
+		}
+		res, err = client.Post(url+"/v1/buildings", "application/json", nil)
+		if assert.NoError(t, err) {
+			assert.Equal(t, fasthttp.StatusForbidden, res.StatusCode)
+		}
+
+		client, url = serveTLS(t, persistentStore, dir, "hall-panel")
+		res, err = client.Get(url + "/v1/buildings")
+		if assert.NoError(t, err) {
+			assert.Equal(t, fasthttp.StatusUnauthorized, res.StatusCode)
+			message, err := testutils.ReadError(res)
+			assert.NoError(t, err)
+			assert.Equal(t, "client certificate hall-panel is not mapped to a token, use 'dwarka token create hall-panel --type certificate'", message)
+		}
+	})
+
+	t.Run("should accept the tokens without client certificate", func(t *testing.T) {
+		persistentStore := enableAuthentication(t)
+		dir := enableTLS(t, true)
+		apiKey := issueToken(t, persistentStore, auth.RoleViewer)
+
+		client, url := serveTLS(t, persistentStore, dir, "")
+		request, err := http.NewRequest("GET", url+"/v1/buildings", nil)
+		assert.NoError(t, err)
+		request.Header.Set("X-API-Key", apiKey)
+		res, err := client.Do(request)
+		if assert.NoError(t, err) {
+			assert.Equal(t, fasthttp.StatusOK, res.StatusCode)
+		}
+
+		res, err = client.Get(url + "/v1/buildings")
+		if assert.NoError(t, err) {
+			assert.Equal(t, fasthttp.StatusUnauthorized, res.StatusCode)
+		}
+	})
+
+	t.Run("should require the client certificate without authentication", func(t *testing.T) {
+		dir := enableTLS(t, false, "kitchen-panel")
+
+		client, url := serveTLS(t, newBoltStore(t), dir, "kitchen-panel")
+		res, err := client.Get(url + "/ping")
+		if assert.NoError(t, err) {
+			assert.Equal(t, fasthttp.StatusOK, res.StatusCode)
+		}
+
+		client, url = serveTLS(t, newBoltStore(t), dir, "")
+		_, err = client.Get(url + "/ping")
+		assert.Error(t, err)
+	})
+}
+
+func TestCertificates_Reload(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "certs")
+	_, err := certs.Generate(dir, []string{"localhost"}, nil, time.Now(), time.Hour)
+	assert.NoError(t, err)
+	config := server.TLS{CertFile: filepath.Join(dir, certs.ServerFile), KeyFile: filepath.Join(dir, certs.ServerKeyFile)}
+	certificates, err := server.NewCertificates(config)
+	assert.NoError(t, err)
+	served := func() []byte {
+		tlsConfig, err := certificates.Config().GetConfigForClient(&tls.ClientHelloInfo{})
+		assert.NoError(t, err)
+		return tlsConfig.Certificates[0].Certificate[0]
+	}
+	previous := served()
+
+	t.Run("should keep the certificates when unchanged", func(t *testing.T) {
+		reloaded, err := certificates.Reload()
+
+		assert.NoError(t, err)
+		assert.False(t, reloaded)
+		assert.Equal(t, previous, served())
+	})
+
+	t.Run("should keep the certificates when the files fail to load", func(t *testing.T) {
+		assert.NoError(t, os.WriteFile(config.KeyFile, []byte("partially written"), 0600))
+
+		reloaded, err := certificates.Reload()
+
+		assert.Error(t, err)
+		assert.False(t, reloaded)
+		assert.Equal(t, previous, served())
+	})
+
+	t.Run("should replace the certificates when the files change", func(t *testing.T) {
+		_, err := certs.Generate(dir, []string{"localhost"}, nil, time.Now(), time.Hour)
+		assert.NoError(t, err)
+
+		reloaded, err := certificates.Reload()
+
+		assert.NoError(t, err)
+		assert.True(t, reloaded)
+		assert.NotEqual(t, previous, served())
+	})
+
+	t.Run("should fail without the key", func(t *testing.T) {
+		_, err := server.NewCertificates(server.TLS{CertFile: config.CertFile})
+
+		assert.EqualError(t, err, "both the certificate and the key are required to serve https")
+	})
+}
@@ -0,0 +1,117 @@
+package api
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/valyala/fasthttp"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/server"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/view"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/auth"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/store"
+)
+
+const (
+	treeBasePath = "/tree"
+	depthParam   = "depth"
+	fieldsParam  = "fields"
+)
+
+func buildingTreePath() string {
+	return path.Join(buildingPath(), "tree")
+}
+
+func init() {
+	tags := []string{"tree"}
+	query := map[string]string{
+		depthParam:  "levels of nesting, 1 for buildings, 2 for floors, 3 for rooms and 4 for devices",
+		fieldsParam: "comma separated list of fields to include for every entity e.g. id,name",
+	}
+	AddRoute(
+		server.NewRouteWithFilters("GET", treeBasePath, treeHandler, authorized(auth.Read)).Describe(server.Documentation{
+			Summary: "All buildings along with their floors, rooms and devices", Tags: tags, Query: query,
+			Response: []view.BuildingTree{}, Errors: []int{fasthttp.StatusBadRequest},
+		}),
+		server.NewRouteWithFilters("GET", buildingTreePath(), buildingTreeHandler, authorized(auth.Read)).Describe(server.Documentation{
+			Summary: "Building along with its floors, rooms and devices", Tags: tags, Query: query,
+			Response: view.BuildingTree{}, Errors: []int{fasthttp.StatusBadRequest},
+		}),
+	)
+}
+
+type treeOptions struct {
+	depth  int
+	fields []string
+}
+
+func parseTreeOptions(ctx server.RequestContext) (treeOptions, error) {
+	options := treeOptions{}
+	args := ctx.QueryArgs()
+
+	if depth := string(args.Peek(depthParam)); depth != "" {
+		value, err := strconv.Atoi(depth)
+		if err != nil || value < 1 {
+			return options, fmt.Errorf("depth should be a positive number, got '%s'", depth)
+		}
+		options.depth = value
+	}
+
+	if fields := string(args.Peek(fieldsParam)); fields != "" {
+		for _, field := range strings.Split(fields, ",") {
+			if field = strings.TrimSpace(field); field != "" {
+				options.fields = append(options.fields, field)
+			}
+		}
+	}
+	return options, nil
+}
+
+func treeResponse(ctx server.RequestContext, options treeOptions, value interface{}) error {
+	if len(options.fields) == 0 {
+		return ctx.JSONResponse(value, fasthttp.StatusOK)
+	}
+
+	result, err := view.Sparse(value, options.fields)
+	if err != nil {
+		return internalServerError(ctx, err)
+	}
+	return ctx.JSONResponse(result, fasthttp.StatusOK)
+}
+
+var treeHandler = func(store store.Store, ctx server.RequestContext) error {
+	options, err := parseTreeOptions(ctx)
+	if err != nil {
+		return badRequest(ctx, err)
+	}
+
+	tree, err := store.Tree()
+	if err != nil {
+		return internalServerError(ctx, err)
+	}
+	tree = visibleTree(ctx, tree)
+
+	return treeResponse(ctx, options, view.NewTree(tree, options.depth))
+}
+
+var buildingTreeHandler = func(store store.Store, ctx server.RequestContext) error {
+	options, err := parseTreeOptions(ctx)
+	if err != nil {
+		return badRequest(ctx, err)
+	}
+
+	tree, err := store.Tree()
+	if err != nil {
+		return internalServerError(ctx, err)
+	}
+	tree = visibleTree(ctx, tree)
+
+	id, _ := ctx.UserValue(buildingID).(string)
+	building, ok := tree.Buildings[id]
+	if !ok {
+		return notFound(ctx)
+	}
+
+	return treeResponse(ctx, options, view.NewBuildingTree(tree, building, options.depth))
+}
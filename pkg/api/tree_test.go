@@ -0,0 +1,188 @@
+package api_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/view"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+	mockStore "gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/internal/mocks/store"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/testutils"
+)
+
+func newTree() gateway.Tree {
+	device := testutils.NewDevice("porch-light")
+	room := device.Room
+	floor := room.Floor
+	building := floor.Building.(gateway.Building)
+
+	tree := gateway.NewTree()
+	tree.Buildings[building.ID()] = building
+	tree.AddFloors(building, gateway.Floors{floor.ID(): floor})
+	tree.AddRooms(floor, gateway.Rooms{room.ID(): room})
+	tree.AddDevices(room, gateway.Devices{device.ID(): device})
+	return tree
+}
+
+func TestTree(t *testing.T) {
+	tree := newTree()
+
+	t.Run("test GET /tree", func(t *testing.T) {
+		t.Run("should return the whole tree", func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockKVStore := mockStore.NewMockStore(ctrl)
+			mockKVStore.EXPECT().Tree().Return(tree, nil)
+
+			request, err := http.NewRequest("GET", "http://test/v1/tree", nil)
+			if err != nil {
+				t.Error(err)
+			}
+
+			res, err := testutils.ServeHTTPRequest(mockKVStore, request)
+			assert.NoError(t, err)
+			assert.Equal(t, fasthttp.StatusOK, res.StatusCode)
+
+			var actual []view.BuildingTree
+			err = testutils.Read(res, &actual)
+			if assert.NoError(t, err) {
+				assert.Equal(t, view.NewTree(tree, 0), actual)
+				assert.Equal(t, "porch-light", actual[0].Floors[0].Rooms[0].Devices[0].ID)
+			}
+		})
+
+		t.Run("should limit nesting using depth", func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockKVStore := mockStore.NewMockStore(ctrl)
+			mockKVStore.EXPECT().Tree().Return(tree, nil)
+
+			request, err := http.NewRequest("GET", "http://test/v1/tree?depth=2", nil)
+			if err != nil {
+				t.Error(err)
+			}
+
+			res, err := testutils.ServeHTTPRequest(mockKVStore, request)
+			assert.NoError(t, err)
+			assert.Equal(t, fasthttp.StatusOK, res.StatusCode)
+
+			var actual []view.BuildingTree
+			err = testutils.Read(res, &actual)
+			if assert.NoError(t, err) {
+				assert.Len(t, actual[0].Floors, 1)
+				assert.Nil(t, actual[0].Floors[0].Rooms)
+			}
+		})
+
+		t.Run("should return only the requested fields", func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockKVStore := mockStore.NewMockStore(ctrl)
+			mockKVStore.EXPECT().Tree().Return(tree, nil)
+
+			request, err := http.NewRequest("GET", "http://test/v1/tree?depth=2&fields=id,name", nil)
+			if err != nil {
+				t.Error(err)
+			}
+
+			res, err := testutils.ServeHTTPRequest(mockKVStore, request)
+			assert.NoError(t, err)
+			assert.Equal(t, fasthttp.StatusOK, res.StatusCode)
+
+			var actual []map[string]interface{}
+			err = testutils.Read(res, &actual)
+			if assert.NoError(t, err) {
+				assert.Equal(t, []map[string]interface{}{{
+					"id":     "building-one",
+					"name":   "building-one",
+					"floors": []interface{}{map[string]interface{}{"id": "floor-one", "name": "floor-one"}},
+				}}, actual)
+			}
+		})
+
+		t.Run("should return 400 for invalid depth", func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockKVStore := mockStore.NewMockStore(ctrl)
+
+			request, err := http.NewRequest("GET", "http://test/v1/tree?depth=none", nil)
+			if err != nil {
+				t.Error(err)
+			}
+
+			res, err := testutils.ServeHTTPRequest(mockKVStore, request)
+			assert.NoError(t, err)
+			assert.Equal(t, fasthttp.StatusBadRequest, res.StatusCode)
+
+			msg, err := testutils.ReadError(res)
+			if assert.NoError(t, err) {
+				assert.Equal(t, "depth should be a positive number, got 'none'", msg)
+			}
+		})
+
+		t.Run("should handle error returned by the store", func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockKVStore := mockStore.NewMockStore(ctrl)
+			mockKVStore.EXPECT().Tree().Return(gateway.Tree{}, fmt.Errorf("unable to contact store"))
+
+			request, err := http.NewRequest("GET", "http://test/v1/tree", nil)
+			if err != nil {
+				t.Error(err)
+			}
+
+			res, err := testutils.ServeHTTPRequest(mockKVStore, request)
+			assert.NoError(t, err)
+			assert.Equal(t, fasthttp.StatusInternalServerError, res.StatusCode)
+
+			msg, err := testutils.ReadError(res)
+			if assert.NoError(t, err) {
+				assert.Equal(t, "unable to contact store", msg)
+			}
+		})
+	})
+
+	t.Run("test GET /buildings/:building-id/tree", func(t *testing.T) {
+		t.Run("should return the building tree", func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockKVStore := mockStore.NewMockStore(ctrl)
+			mockKVStore.EXPECT().Tree().Return(tree, nil)
+
+			request, err := http.NewRequest("GET", "http://test/v1/buildings/building-one/tree", nil)
+			if err != nil {
+				t.Error(err)
+			}
+
+			res, err := testutils.ServeHTTPRequest(mockKVStore, request)
+			assert.NoError(t, err)
+			assert.Equal(t, fasthttp.StatusOK, res.StatusCode)
+
+			actual := view.BuildingTree{}
+			err = testutils.Read(res, &actual)
+			if assert.NoError(t, err) {
+				assert.Equal(t, view.NewBuildingTree(tree, tree.Buildings["building-one"], 0), actual)
+			}
+		})
+
+		t.Run("should return 404 if building is not available", func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockKVStore := mockStore.NewMockStore(ctrl)
+			mockKVStore.EXPECT().Tree().Return(tree, nil)
+
+			request, err := http.NewRequest("GET", "http://test/v1/buildings/building-two/tree", nil)
+			if err != nil {
+				t.Error(err)
+			}
+
+			res, err := testutils.ServeHTTPRequest(mockKVStore, request)
+			assert.NoError(t, err)
+			assert.Equal(t, fasthttp.StatusNotFound, res.StatusCode)
+		})
+	})
+}
@@ -0,0 +1,50 @@
+package view
+
+import (
+	"sort"
+
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+)
+
+// Automation is the view model for gateway.Automation which exposes the
+// building it belongs to
+type Automation struct {
+	ID          string              `json:"id"`
+	Name        string              `json:"name"`
+	Description string              `json:"description"`
+	Building    string              `json:"building"`
+	Disabled    bool                `json:"disabled"`
+	Triggers    []gateway.Trigger   `json:"triggers"`
+	Conditions  []gateway.Condition `json:"conditions"`
+	Actions     []gateway.Action    `json:"actions"`
+	Labels      map[string]string   `json:"labels,omitempty"`
+}
+
+// NewAutomations converts gateway.Automations into []Automation ordered by id
+func NewAutomations(automations gateway.Automations) []Automation {
+	result := make([]Automation, 0, len(automations))
+	for _, automation := range automations {
+		result = append(result, NewAutomation(automation))
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result
+}
+
+// NewAutomation converts the gateway.Automation to view.Automation
+func NewAutomation(automation gateway.Automation) Automation {
+	conditions := automation.Conditions
+	if conditions == nil {
+		conditions = []gateway.Condition{}
+	}
+	return Automation{
+		ID:          automation.ID(),
+		Name:        automation.Name,
+		Description: automation.Description,
+		Building:    entityID(automation.Building),
+		Disabled:    automation.Disabled,
+		Triggers:    automation.Triggers,
+		Conditions:  conditions,
+		Actions:     automation.Actions,
+		Labels:      automation.Labels,
+	}
+}
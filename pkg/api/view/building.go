@@ -0,0 +1,113 @@
+package view
+
+import (
+	"encoding/json"
+	"sort"
+
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+)
+
+// Building a structure with a roof and walls, such as a house or factory
+// this is a view model for gateway.Building which exposes the coordinates
+// as latitude and longitude
+type Building struct {
+	ID          string            `json:"id"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Latitude    float64           `json:"latitude"`
+	Longitude   float64           `json:"longitude"`
+	Timezone    string            `json:"timezone,omitempty"`
+	Tariff      *gateway.Tariff   `json:"tariff,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+}
+
+// buildingRequest represents the accepted shape of a building in a request
+// body, it understands the legacy lat/lan fields for backward compatibility
+type buildingRequest struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Latitude    *float64          `json:"latitude"`
+	Longitude   *float64          `json:"longitude"`
+	Lat         *float64          `json:"lat"`
+	Lan         *float64          `json:"lan"`
+	Timezone    string            `json:"timezone"`
+	Tariff      *gateway.Tariff   `json:"tariff"`
+	Labels      map[string]string `json:"labels"`
+}
+
+var buildingFields = map[string]string{"lat": "latitude", "lan": "longitude"}
+
+// Building converts the view.Building to gateway.Building
+func (building Building) Building() gateway.Building {
+	return gateway.Building{
+		Lat:      building.Latitude,
+		Lan:      building.Longitude,
+		Timezone: building.Timezone,
+		Tariff:   building.Tariff,
+		PhysicalEntity: gateway.PhysicalEntity{
+			Name:        building.Name,
+			Description: building.Description,
+			Labels:      building.Labels,
+		},
+	}
+}
+
+// ConvertBuilding uses []byte representing view.Building as gateway.Building
+func ConvertBuilding(data []byte) (gateway.Building, error) {
+	request := buildingRequest{}
+	err := json.Unmarshal(data, &request)
+	if err != nil {
+		return gateway.Building{}, err
+	}
+
+	building := Building{
+		Name:        request.Name,
+		Description: request.Description,
+		Latitude:    firstOf(request.Latitude, request.Lat),
+		Longitude:   firstOf(request.Longitude, request.Lan),
+		Timezone:    request.Timezone,
+		Tariff:      request.Tariff,
+		Labels:      request.Labels,
+	}
+
+	data, err = json.Marshal(building.Building())
+	if err != nil {
+		return gateway.Building{}, err
+	}
+
+	result, err := gateway.NewBuilding(data)
+	return result, renameFields(err, buildingFields)
+}
+
+// NewBuildings convert gateway.Buildings into []Building ordered by id
+func NewBuildings(buildings gateway.Buildings) []Building {
+	result := make([]Building, 0, len(buildings))
+	for _, building := range buildings {
+		result = append(result, NewBuilding(building))
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result
+}
+
+// NewBuilding converts the gateway.Building to view.Building
+func NewBuilding(building gateway.Building) Building {
+	return Building{
+		ID:          building.ID(),
+		Name:        building.Name,
+		Description: building.Description,
+		Latitude:    building.Lat,
+		Longitude:   building.Lan,
+		Timezone:    building.Timezone,
+		Tariff:      building.Tariff,
+		Labels:      building.Labels,
+	}
+}
+
+func firstOf(values ...*float64) float64 {
+	for _, value := range values {
+		if value != nil {
+			return *value
+		}
+	}
+	return 0
+}
@@ -0,0 +1,103 @@
+package view_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/view"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+)
+
+func TestNewBuilding(t *testing.T) {
+	building := gateway.Building{
+		Lat:            13.0827,
+		Lan:            80.2707,
+		PhysicalEntity: gateway.PhysicalEntity{Name: "vedha bhavanam", Description: "private home"},
+	}
+	expected := view.Building{
+		ID:          "vedha-bhavanam",
+		Name:        "vedha bhavanam",
+		Description: "private home",
+		Latitude:    13.0827,
+		Longitude:   80.2707,
+	}
+
+	actual := view.NewBuilding(building)
+
+	if !cmp.Equal(expected, actual) {
+		assert.Fail(t, cmp.Diff(expected, actual))
+	}
+}
+
+func TestNewBuildings(t *testing.T) {
+	one := gateway.Building{PhysicalEntity: gateway.PhysicalEntity{Name: "building one"}}
+	two := gateway.Building{PhysicalEntity: gateway.PhysicalEntity{Name: "building two"}}
+
+	actual := view.NewBuildings(gateway.Buildings{two.ID(): two, one.ID(): one})
+
+	assert.Equal(t, []view.Building{view.NewBuilding(one), view.NewBuilding(two)}, actual)
+}
+
+func TestConvertBuilding(t *testing.T) {
+	type scenario struct {
+		name     string
+		data     string
+		expected gateway.Building
+		error    string
+	}
+	scenarios := []scenario{
+		{
+			name: "ConvertBuilding should convert latitude and longitude",
+			data: `{"name": "vedha bhavanam", "description": "private home", "latitude": 13.08, "longitude": 80.27}`,
+			expected: gateway.Building{
+				Lat:            13.08,
+				Lan:            80.27,
+				PhysicalEntity: gateway.PhysicalEntity{Name: "vedha bhavanam", Description: "private home"},
+			},
+		},
+		{
+			name: "ConvertBuilding should accept legacy lat and lan",
+			data: `{"name": "vedha bhavanam", "description": "private home", "lat": 13.08, "lan": 80.27}`,
+			expected: gateway.Building{
+				Lat:            13.08,
+				Lan:            80.27,
+				PhysicalEntity: gateway.PhysicalEntity{Name: "vedha bhavanam", Description: "private home"},
+			},
+		},
+		{
+			name: "ConvertBuilding should prefer latitude and longitude over legacy fields",
+			data: `{"name": "vedha bhavanam", "latitude": 13.08, "longitude": 80.27, "lat": 1, "lan": 2}`,
+			expected: gateway.Building{
+				Lat:            13.08,
+				Lan:            80.27,
+				PhysicalEntity: gateway.PhysicalEntity{Name: "vedha bhavanam"},
+			},
+		},
+		{
+			name: "ConvertBuilding should report validation errors using view field names",
+			data: `{"name": "vedha bhavanam"}`,
+			expected: gateway.Building{
+				PhysicalEntity: gateway.PhysicalEntity{Name: "vedha bhavanam"},
+			},
+			error: "latitude: cannot be blank; longitude: cannot be blank.",
+		},
+	}
+
+	for _, testScenario := range scenarios {
+		t.Run(testScenario.name, func(t *testing.T) {
+			actual, err := view.ConvertBuilding([]byte(testScenario.data))
+
+			if !cmp.Equal(testScenario.expected, actual) {
+				assert.Fail(t, cmp.Diff(testScenario.expected, actual))
+			}
+			if testScenario.error != "" {
+				if assert.Error(t, err) {
+					assert.Equal(t, testScenario.error, err.Error())
+				}
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package view
+
+import (
+	"encoding/json"
+	"sort"
+
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+)
+
+// Device is a electronic / electrical equipment made or adapted for a particular purpose
+// this is a view model for gateway.Device which flattens the entity and exposes
+// the room, floor and building it belongs to
+type Device struct {
+	ID          string            `json:"id"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Meta        map[string]string `json:"meta"`
+	Room        string            `json:"room"`
+	Floor       string            `json:"floor"`
+	Building    string            `json:"building"`
+	Labels      map[string]string `json:"labels,omitempty"`
+}
+
+// Device converts the view.Device to gateway.Device
+func (device Device) Device() gateway.Device {
+	return gateway.Device{
+		Meta: device.Meta,
+		PhysicalEntity: gateway.PhysicalEntity{
+			Name:        device.Name,
+			Description: device.Description,
+			Labels:      device.Labels,
+		},
+	}
+}
+
+// NewDevices convert gateway.Devices into []Device ordered by id
+func NewDevices(devices gateway.Devices) []Device {
+	result := make([]Device, 0, len(devices))
+	for _, device := range devices {
+		result = append(result, NewDevice(device))
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result
+}
+
+// NewDevice converts the gateway.Device to view.Device
+func NewDevice(device gateway.Device) Device {
+	return Device{
+		ID:          device.ID(),
+		Name:        device.Name,
+		Description: device.Description,
+		Meta:        device.Meta,
+		Room:        device.Room.ID(),
+		Floor:       device.Room.Floor.ID(),
+		Building:    entityID(device.Room.Floor.Building),
+		Labels:      device.Labels,
+	}
+}
+
+// ConvertDevice uses room and []byte representing view.Device as gateway.Device
+func ConvertDevice(room gateway.Room, data []byte) (gateway.Device, error) {
+	device := Device{}
+	err := json.Unmarshal(data, &device)
+	if err != nil {
+		return gateway.Device{}, err
+	}
+
+	data, err = json.Marshal(device.Device())
+	if err != nil {
+		return gateway.Device{}, err
+	}
+	return gateway.NewDevice(room, data)
+}
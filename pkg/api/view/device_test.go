@@ -0,0 +1,44 @@
+package view_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/view"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/testutils"
+)
+
+func TestNewDevice(t *testing.T) {
+	device := testutils.NewDevice("porch light")
+	device.Meta = map[string]string{"type": "light"}
+	expected := view.Device{
+		ID:       "porch-light",
+		Name:     "porch light",
+		Meta:     map[string]string{"type": "light"},
+		Room:     "room-one",
+		Floor:    "floor-one",
+		Building: "building-one",
+	}
+
+	actual := view.NewDevice(device)
+
+	if !cmp.Equal(expected, actual) {
+		assert.Fail(t, cmp.Diff(expected, actual))
+	}
+}
+
+func TestConvertDevice(t *testing.T) {
+	room := testutils.NewRoom("room-one")
+
+	actual, err := view.ConvertDevice(room, []byte(`{"name": "porch light", "meta": {"pin": "4"}}`))
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "porch-light", actual.ID())
+		assert.Equal(t, room, actual.Room)
+		assert.Equal(t, map[string]string{"pin": "4"}, actual.Meta)
+	}
+
+	_, err = view.ConvertDevice(room, []byte(`{"name": "fan"}`))
+	assert.EqualError(t, err, "name: the length must be between 5 and 50.")
+}
@@ -0,0 +1,103 @@
+package view
+
+import (
+	"encoding/csv"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+)
+
+// Energy represents the energy consumed in a building over a period along
+// with the consumption of its floors, rooms and devices, the cost is given
+// when the building has a tariff
+type Energy struct {
+	Building    string    `json:"building"`
+	Name        string    `json:"name"`
+	Period      string    `json:"period"`
+	From        time.Time `json:"from"`
+	To          time.Time `json:"to"`
+	Currency    string    `json:"currency,omitempty"`
+	Rate        float64   `json:"rate,omitempty"`
+	Consumption float64   `json:"kwh"`
+	Cost        float64   `json:"cost,omitempty"`
+	Usage       []Usage   `json:"usage"`
+}
+
+// Usage represents the energy consumed by a floor, a room or a device given
+// as floor-id, floor-id/room-id or floor-id/room-id/device-id
+type Usage struct {
+	Kind        string  `json:"kind"`
+	Path        string  `json:"path"`
+	Name        string  `json:"name"`
+	Consumption float64 `json:"kwh"`
+	Cost        float64 `json:"cost,omitempty"`
+}
+
+var usageKinds = map[string]int{"floor": 0, "room": 1, "device": 2}
+
+// NewEnergy returns the energy consumed in the building, the floors, the
+// rooms and the devices are ordered by kind and path
+func NewEnergy(building gateway.Building, period string, from, to time.Time, usage []Usage) Energy {
+	energy := Energy{Building: building.ID(), Name: building.Name, Period: period, From: from, To: to, Usage: usage}
+	if energy.Usage == nil {
+		energy.Usage = []Usage{}
+	}
+	sort.Slice(energy.Usage, func(i, j int) bool {
+		if energy.Usage[i].Kind != energy.Usage[j].Kind {
+			return usageKinds[energy.Usage[i].Kind] < usageKinds[energy.Usage[j].Kind]
+		}
+		return energy.Usage[i].Path < energy.Usage[j].Path
+	})
+
+	for i, used := range energy.Usage {
+		if used.Kind == "device" {
+			energy.Consumption += used.Consumption
+		}
+		if building.Tariff != nil {
+			energy.Usage[i].Cost = roundTo(building.Tariff.Cost(used.Consumption), 2)
+		}
+		energy.Usage[i].Consumption = roundTo(used.Consumption, 3)
+	}
+	if building.Tariff != nil {
+		energy.Currency, energy.Rate = building.Tariff.Currency, building.Tariff.Rate
+		energy.Cost = roundTo(building.Tariff.Cost(energy.Consumption), 2)
+	}
+	energy.Consumption = roundTo(energy.Consumption, 3)
+	return energy
+}
+
+// CSV returns the energy as comma separated values with a row for the
+// building followed by the rows of its floors, rooms and devices
+func (energy Energy) CSV() (string, error) {
+	builder := &strings.Builder{}
+	writer := csv.NewWriter(builder)
+	rows := [][]string{
+		{"kind", "path", "name", "from", "to", "kwh", "cost", "currency"},
+		energy.row("building", energy.Building, energy.Name, energy.Consumption, energy.Cost),
+	}
+	for _, used := range energy.Usage {
+		rows = append(rows, energy.row(used.Kind, used.Path, used.Name, used.Consumption, used.Cost))
+	}
+	if err := writer.WriteAll(rows); err != nil {
+		return "", err
+	}
+	return builder.String(), nil
+}
+
+func (energy Energy) row(kind, path, name string, kWh, cost float64) []string {
+	formattedCost := ""
+	if energy.Currency != "" {
+		formattedCost = strconv.FormatFloat(cost, 'f', 2, 64)
+	}
+	return []string{kind, path, name, energy.From.Format(time.RFC3339), energy.To.Format(time.RFC3339),
+		strconv.FormatFloat(kWh, 'f', 3, 64), formattedCost, energy.Currency}
+}
+
+func roundTo(value float64, places int) float64 {
+	scale := math.Pow(10, float64(places))
+	return math.Round(value*scale) / scale
+}
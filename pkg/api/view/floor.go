@@ -0,0 +1,68 @@
+package view
+
+import (
+	"encoding/json"
+	"sort"
+
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+)
+
+// Floor a horizontal plane or line with respect to the distance above or below a given point
+// this is a view model for gateway.Floor which exposes the building it belongs to
+type Floor struct {
+	ID          string            `json:"id"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Level       int               `json:"level"`
+	Building    string            `json:"building"`
+	Labels      map[string]string `json:"labels,omitempty"`
+}
+
+// Floor converts the view.Floor to gateway.Floor
+func (floor Floor) Floor() gateway.Floor {
+	return gateway.Floor{
+		Level: floor.Level,
+		PhysicalEntity: gateway.PhysicalEntity{
+			Name:        floor.Name,
+			Description: floor.Description,
+			Labels:      floor.Labels,
+		},
+	}
+}
+
+// ConvertFloor uses building and []byte representing view.Floor as gateway.Floor
+func ConvertFloor(building gateway.Building, data []byte) (gateway.Floor, error) {
+	floor := Floor{}
+	err := json.Unmarshal(data, &floor)
+	if err != nil {
+		return gateway.Floor{}, err
+	}
+
+	data, err = json.Marshal(floor.Floor())
+	if err != nil {
+		return gateway.Floor{}, err
+	}
+	return gateway.NewFloor(building, data)
+}
+
+// NewFloors convert gateway.Floors into []Floor ordered by id
+func NewFloors(floors gateway.Floors) []Floor {
+	result := make([]Floor, 0, len(floors))
+	for _, floor := range floors {
+		result = append(result, NewFloor(floor))
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result
+}
+
+// NewFloor converts the gateway.Floor to view.Floor
+func NewFloor(floor gateway.Floor) Floor {
+	return Floor{
+		ID:          floor.ID(),
+		Name:        floor.Name,
+		Description: floor.Description,
+		Level:       floor.Level,
+		Building:    entityID(floor.Building),
+		Labels:      floor.Labels,
+	}
+}
@@ -0,0 +1,67 @@
+package view_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/view"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/testutils"
+)
+
+func TestNewFloor(t *testing.T) {
+	t.Run("should include the building the floor belongs to", func(t *testing.T) {
+		floor := testutils.NewFloor("floor one")
+		floor.Level = 2
+		floor.Description = "terrace"
+		expected := view.Floor{
+			ID:          "floor-one",
+			Name:        "floor one",
+			Description: "terrace",
+			Level:       2,
+			Building:    "building-one",
+		}
+
+		actual := view.NewFloor(floor)
+
+		if !cmp.Equal(expected, actual) {
+			assert.Fail(t, cmp.Diff(expected, actual))
+		}
+	})
+
+	t.Run("should leave building empty when floor is not associated", func(t *testing.T) {
+		floor := gateway.Floor{Level: 1, PhysicalEntity: gateway.PhysicalEntity{Name: "floor one"}}
+
+		actual := view.NewFloor(floor)
+
+		assert.Equal(t, "", actual.Building)
+	})
+}
+
+func TestConvertFloor(t *testing.T) {
+	t.Run("should convert floor and associate it to building", func(t *testing.T) {
+		building := testutils.NewBuilding("building one")
+		expected := gateway.Floor{
+			Building:       building,
+			Level:          1,
+			PhysicalEntity: gateway.PhysicalEntity{Name: "floor one", Description: "ground"},
+		}
+
+		actual, err := view.ConvertFloor(building, []byte(`{"id": "ignored", "name": "floor one", "description": "ground", "level": 1}`))
+
+		if assert.NoError(t, err) {
+			if !cmp.Equal(expected, actual) {
+				assert.Fail(t, cmp.Diff(expected, actual))
+			}
+		}
+	})
+
+	t.Run("should return validation error", func(t *testing.T) {
+		_, err := view.ConvertFloor(testutils.NewBuilding("building one"), []byte(`{"name": "one"}`))
+
+		if assert.Error(t, err) {
+			assert.Equal(t, "level: cannot be blank; name: the length must be between 5 and 50.", err.Error())
+		}
+	})
+}
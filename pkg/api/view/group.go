@@ -0,0 +1,127 @@
+package view
+
+import (
+	"encoding/json"
+	"sort"
+
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+)
+
+// Group is the view model for gateway.Group which exposes the building it
+// belongs to, the devices are given as floor-id/room-id/device-id
+type Group struct {
+	ID          string            `json:"id"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Building    string            `json:"building"`
+	Devices     []string          `json:"devices,omitempty"`
+	Selector    *gateway.Selector `json:"selector,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+}
+
+// Member is a device of the group, Missing is set for the devices of a
+// static group which are not found
+type Member struct {
+	Device  string            `json:"device"`
+	Name    string            `json:"name,omitempty"`
+	Meta    map[string]string `json:"meta,omitempty"`
+	Missing bool              `json:"missing,omitempty"`
+}
+
+// Members represents the devices of a group
+type Members struct {
+	Group   string   `json:"group"`
+	Dynamic bool     `json:"dynamic"`
+	Members []Member `json:"members"`
+}
+
+// Command represents the outcome of switching the devices of a group
+// along with the number of devices switched and failed
+type Command struct {
+	Group    string         `json:"group"`
+	State    gateway.State  `json:"state"`
+	Switched int            `json:"switched"`
+	Failed   int            `json:"failed"`
+	Results  []TargetResult `json:"results"`
+}
+
+// Group converts the view.Group to gateway.Group
+func (group Group) Group() gateway.Group {
+	return gateway.Group{
+		Devices:  group.Devices,
+		Selector: group.Selector,
+		PhysicalEntity: gateway.PhysicalEntity{
+			Name:        group.Name,
+			Description: group.Description,
+			Labels:      group.Labels,
+		},
+	}
+}
+
+// ConvertGroup uses building and []byte representing view.Group as gateway.Group
+func ConvertGroup(building gateway.Building, data []byte) (gateway.Group, error) {
+	group := Group{}
+	err := json.Unmarshal(data, &group)
+	if err != nil {
+		return gateway.Group{}, err
+	}
+
+	data, err = json.Marshal(group.Group())
+	if err != nil {
+		return gateway.Group{}, err
+	}
+	return gateway.NewGroup(building, data)
+}
+
+// NewGroups converts gateway.Groups into []Group ordered by id
+func NewGroups(groups gateway.Groups) []Group {
+	result := make([]Group, 0, len(groups))
+	for _, group := range groups {
+		result = append(result, NewGroup(group))
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result
+}
+
+// NewGroup converts the gateway.Group to view.Group
+func NewGroup(group gateway.Group) Group {
+	return Group{
+		ID:          group.ID(),
+		Name:        group.Name,
+		Description: group.Description,
+		Building:    entityID(group.Building),
+		Devices:     group.Devices,
+		Selector:    group.Selector,
+		Labels:      group.Labels,
+	}
+}
+
+// NewMembers returns the members of the group found in the tree
+func NewMembers(group gateway.Group, tree gateway.Tree, members []gateway.Target) Members {
+	result := Members{Group: group.ID(), Dynamic: group.Dynamic(), Members: make([]Member, 0, len(members))}
+	for _, target := range members {
+		member := Member{Device: target.Path(), Missing: true}
+		if floor, ok := tree.FloorsOf(group.Building)[target.Floor]; ok {
+			if room, ok := tree.RoomsOf(floor)[target.Room]; ok {
+				if device, ok := tree.DevicesOf(room)[target.Device]; ok {
+					member = Member{Device: target.Path(), Name: device.Name, Meta: device.Meta}
+				}
+			}
+		}
+		result.Members = append(result.Members, member)
+	}
+	return result
+}
+
+// NewCommand converts the results of switching the devices of the group
+func NewCommand(group gateway.Group, state gateway.State, results []gateway.Result) Command {
+	command := Command{Group: group.ID(), State: state, Results: NewActivation(gateway.Scene{}, results).Results}
+	for _, result := range command.Results {
+		if result.Outcome == Failed {
+			command.Failed++
+		} else {
+			command.Switched++
+		}
+	}
+	return command
+}
@@ -2,6 +2,8 @@ package view
 
 import (
 	"encoding/json"
+	"sort"
+
 	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
 )
 
@@ -9,9 +11,22 @@ import (
 // this is a view model for device.Room which abstracts the internal
 // implementation details of direction
 type Room struct {
-	Direction   string `json:"direction"`
-	Name        string `json:"name"`
-	Description string `json:"description"`
+	ID          string            `json:"id"`
+	Direction   string            `json:"direction"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Floor       string            `json:"floor"`
+	Building    string            `json:"building"`
+	Labels      map[string]string `json:"labels,omitempty"`
+}
+
+// roomRequest represents the accepted shape of a room in a request body,
+// direction can either be a string or the legacy gateway.Direction value
+type roomRequest struct {
+	Direction   json.RawMessage   `json:"direction"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Labels      map[string]string `json:"labels"`
 }
 
 // Room converts the view.Room to device.Room
@@ -26,21 +41,37 @@ func (room Room) Room() (gateway.Room, error) {
 		PhysicalEntity: gateway.PhysicalEntity{
 			Name:        room.Name,
 			Description: room.Description,
+			Labels:      room.Labels,
 		},
 	}, nil
 }
 
-// Convert uses floor and []byte representing view.Room as device.Room
-func Convert(floor gateway.Floor, data []byte) (gateway.Room, error) {
-	room := Room{}
-	err := json.Unmarshal(data, &room)
+// ConvertRoom uses floor and []byte representing view.Room as device.Room
+func ConvertRoom(floor gateway.Floor, data []byte) (gateway.Room, error) {
+	request := roomRequest{}
+	err := json.Unmarshal(data, &request)
 	if err != nil {
 		return gateway.Room{}, err
 	}
 
-	r, err := room.Room()
-	if err != nil {
-		return gateway.Room{}, err
+	var r gateway.Room
+	var legacyDirection gateway.Direction
+	if json.Unmarshal(request.Direction, &legacyDirection) == nil {
+		r = gateway.Room{
+			Direction: legacyDirection,
+			PhysicalEntity: gateway.PhysicalEntity{
+				Name:        request.Name,
+				Description: request.Description,
+				Labels:      request.Labels,
+			},
+		}
+	} else {
+		room := Room{Name: request.Name, Description: request.Description, Labels: request.Labels}
+		_ = json.Unmarshal(request.Direction, &room.Direction)
+		r, err = room.Room()
+		if err != nil {
+			return gateway.Room{}, err
+		}
 	}
 
 	data, err = json.Marshal(r)
@@ -50,20 +81,25 @@ func Convert(floor gateway.Floor, data []byte) (gateway.Room, error) {
 	return gateway.NewRoom(floor, data)
 }
 
-// NewRooms convert device.Rooms into []Room
+// NewRooms convert device.Rooms into []Room ordered by id
 func NewRooms(rooms gateway.Rooms) []Room {
 	result := make([]Room, 0, len(rooms))
 	for _, room := range rooms {
 		result = append(result, NewRoom(room))
 	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
 	return result
 }
 
 // NewRoom converts the view.Room to device.Room
 func NewRoom(room gateway.Room) Room {
 	return Room{
+		ID:          room.ID(),
 		Direction:   room.Direction.Direction(),
 		Name:        room.Name,
 		Description: room.Description,
+		Floor:       room.Floor.ID(),
+		Building:    entityID(room.Floor.Building),
+		Labels:      room.Labels,
 	}
 }
@@ -5,6 +5,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/view"
 	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/testutils"
 	"testing"
 )
 
@@ -18,6 +19,7 @@ func TestNewRoom(t *testing.T) {
 		{
 			name: "NewRoom should convert north room",
 			expected: view.Room{
+				ID:          "north-room",
 				Direction:   "north",
 				Name:        "north room",
 				Description: "room facing north",
@@ -33,6 +35,7 @@ func TestNewRoom(t *testing.T) {
 		{
 			name: "NewRoom should convert east room",
 			expected: view.Room{
+				ID:          "east-room",
 				Direction:   "east",
 				Name:        "east room",
 				Description: "room facing east",
@@ -48,6 +51,7 @@ func TestNewRoom(t *testing.T) {
 		{
 			name: "NewRoom should convert south room",
 			expected: view.Room{
+				ID:          "south-room",
 				Direction:   "south",
 				Name:        "south room",
 				Description: "room facing south",
@@ -63,6 +67,7 @@ func TestNewRoom(t *testing.T) {
 		{
 			name: "NewRoom should convert west room",
 			expected: view.Room{
+				ID:          "west-room",
 				Direction:   "west",
 				Name:        "west room",
 				Description: "room facing west",
@@ -189,3 +194,43 @@ func TestRoom_Room(t *testing.T) {
 		})
 	}
 }
+
+func TestConvertRoom(t *testing.T) {
+	floor := testutils.NewFloor("floor-one")
+	expected := gateway.Room{
+		Floor:     floor,
+		Direction: gateway.DirectionEast,
+		PhysicalEntity: gateway.PhysicalEntity{
+			Name:        "east room",
+			Description: "room facing east",
+		},
+	}
+
+	t.Run("should convert direction string", func(t *testing.T) {
+		actual, err := view.ConvertRoom(floor, []byte(`{"name": "east room", "description": "room facing east", "direction": "east"}`))
+
+		if assert.NoError(t, err) {
+			if !cmp.Equal(expected, actual) {
+				assert.Fail(t, cmp.Diff(expected, actual))
+			}
+		}
+	})
+
+	t.Run("should accept legacy direction value", func(t *testing.T) {
+		actual, err := view.ConvertRoom(floor, []byte(`{"name": "east room", "description": "room facing east", "direction": 2}`))
+
+		if assert.NoError(t, err) {
+			if !cmp.Equal(expected, actual) {
+				assert.Fail(t, cmp.Diff(expected, actual))
+			}
+		}
+	})
+
+	t.Run("should return error for unsupported direction", func(t *testing.T) {
+		_, err := view.ConvertRoom(floor, []byte(`{"name": "east room", "direction": "south west"}`))
+
+		if assert.Error(t, err) {
+			assert.Equal(t, "direction south west not supported", err.Error())
+		}
+	})
+}
@@ -0,0 +1,120 @@
+package view
+
+import (
+	"encoding/json"
+	"sort"
+
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+)
+
+const (
+	// Switched is the outcome of a target switched by the node
+	Switched = "switched"
+	// Failed is the outcome of a target which could not be switched
+	Failed = "failed"
+)
+
+// Scene is the view model for gateway.Scene which exposes the building it
+// belongs to, the room is given as floor-id/room-id
+type Scene struct {
+	ID          string            `json:"id"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Building    string            `json:"building"`
+	Room        string            `json:"room,omitempty"`
+	Targets     []gateway.Target  `json:"targets"`
+	Labels      map[string]string `json:"labels,omitempty"`
+}
+
+// Capture describes the scene to capture from the reported state of the
+// devices of the building, or of the room when given as floor-id/room-id.
+// Devices limits the scene to the devices given as floor-id/room-id/device-id
+type Capture struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Room        string   `json:"room,omitempty"`
+	Devices     []string `json:"devices,omitempty"`
+}
+
+// TargetResult is the outcome of switching a device of the scene
+type TargetResult struct {
+	Device  string        `json:"device"`
+	State   gateway.State `json:"state"`
+	Outcome string        `json:"outcome"`
+	Error   string        `json:"error,omitempty"`
+}
+
+// Activation represents the outcome of activating a scene
+type Activation struct {
+	Scene   string         `json:"scene"`
+	Results []TargetResult `json:"results"`
+}
+
+// Scene converts the view.Scene to gateway.Scene
+func (scene Scene) Scene() gateway.Scene {
+	return gateway.Scene{
+		Room:    scene.Room,
+		Targets: scene.Targets,
+		PhysicalEntity: gateway.PhysicalEntity{
+			Name:        scene.Name,
+			Description: scene.Description,
+			Labels:      scene.Labels,
+		},
+	}
+}
+
+// ConvertScene uses building and []byte representing view.Scene as gateway.Scene
+func ConvertScene(building gateway.Building, data []byte) (gateway.Scene, error) {
+	scene := Scene{}
+	err := json.Unmarshal(data, &scene)
+	if err != nil {
+		return gateway.Scene{}, err
+	}
+
+	data, err = json.Marshal(scene.Scene())
+	if err != nil {
+		return gateway.Scene{}, err
+	}
+	return gateway.NewScene(building, data)
+}
+
+// NewScenes converts gateway.Scenes into []Scene ordered by id
+func NewScenes(scenes gateway.Scenes) []Scene {
+	result := make([]Scene, 0, len(scenes))
+	for _, scene := range scenes {
+		result = append(result, NewScene(scene))
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result
+}
+
+// NewScene converts the gateway.Scene to view.Scene
+func NewScene(scene gateway.Scene) Scene {
+	targets := scene.Targets
+	if targets == nil {
+		targets = []gateway.Target{}
+	}
+	return Scene{
+		ID:          scene.ID(),
+		Name:        scene.Name,
+		Description: scene.Description,
+		Building:    entityID(scene.Building),
+		Room:        scene.Room,
+		Targets:     targets,
+		Labels:      scene.Labels,
+	}
+}
+
+// NewActivation converts the results of activating the scene
+func NewActivation(scene gateway.Scene, results []gateway.Result) Activation {
+	activation := Activation{Scene: scene.ID(), Results: make([]TargetResult, 0, len(results))}
+	for _, result := range results {
+		targetResult := TargetResult{Device: result.Target.Path(), State: result.Target.State, Outcome: Switched}
+		if result.Err != nil {
+			targetResult.Outcome = Failed
+			targetResult.Error = result.Err.Error()
+		}
+		activation.Results = append(activation.Results, targetResult)
+	}
+	return activation
+}
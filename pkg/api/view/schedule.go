@@ -0,0 +1,83 @@
+package view
+
+import (
+	"sort"
+	"time"
+
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+)
+
+// Schedule is the view model for gateway.Schedule which exposes the
+// building it belongs to along with the time of its next run, the times
+// are read only
+type Schedule struct {
+	ID          string            `json:"id"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Building    string            `json:"building"`
+	Disabled    bool              `json:"disabled"`
+	Mode        string            `json:"mode"`
+	Cron        string            `json:"cron,omitempty"`
+	Sun         string            `json:"sun,omitempty"`
+	At          string            `json:"at,omitempty"`
+	Skip        []string          `json:"skip"`
+	Missed      string            `json:"missed"`
+	Device      string            `json:"device,omitempty"`
+	Room        string            `json:"room,omitempty"`
+	Scene       string            `json:"scene,omitempty"`
+	State       gateway.State     `json:"state,omitempty"`
+	Created     time.Time         `json:"created"`
+	LastRun     *time.Time        `json:"lastRun,omitempty"`
+	NextRun     *time.Time        `json:"nextRun,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+}
+
+// NextRuns is the preview of the next runs of a schedule
+type NextRuns struct {
+	Schedule string      `json:"schedule"`
+	Timezone string      `json:"timezone"`
+	Runs     []time.Time `json:"runs"`
+}
+
+// NewSchedules converts gateway.Schedules into []Schedule ordered by id
+func NewSchedules(building gateway.Building, schedules gateway.Schedules, now time.Time) []Schedule {
+	result := make([]Schedule, 0, len(schedules))
+	for _, schedule := range schedules {
+		result = append(result, NewSchedule(building, schedule, now))
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result
+}
+
+// NewSchedule converts the gateway.Schedule to view.Schedule, the next run
+// is left out when the schedule is disabled or does not run anymore
+func NewSchedule(building gateway.Building, schedule gateway.Schedule, now time.Time) Schedule {
+	skip := schedule.Skip
+	if skip == nil {
+		skip = []string{}
+	}
+	result := Schedule{
+		ID:          schedule.ID(),
+		Name:        schedule.Name,
+		Description: schedule.Description,
+		Building:    entityID(schedule.Building),
+		Disabled:    schedule.Disabled,
+		Mode:        schedule.Mode,
+		Cron:        schedule.Cron,
+		Sun:         schedule.Sun,
+		At:          schedule.At,
+		Skip:        skip,
+		Missed:      schedule.Missed,
+		Device:      schedule.Device,
+		Room:        schedule.Room,
+		Scene:       schedule.Scene,
+		State:       schedule.State,
+		Created:     schedule.Created,
+		LastRun:     schedule.LastRun,
+		Labels:      schedule.Labels,
+	}
+	if next, err := schedule.Next(building, now); err == nil && !next.IsZero() && !schedule.Disabled {
+		result.NextRun = &next
+	}
+	return result
+}
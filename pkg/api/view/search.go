@@ -0,0 +1,69 @@
+package view
+
+import (
+	"sort"
+	"strings"
+
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/search"
+)
+
+// Match is a building, floor, room or device found by a search, Path is
+// given as building-id/floor-id/room-id/device-id up to the entity. The
+// matches of the words carry the Name, the Location given as the names of
+// the building, the floor and the room, and the Score of the match
+type Match struct {
+	Kind     string            `json:"kind"`
+	Path     string            `json:"path"`
+	Building string            `json:"building"`
+	Floor    string            `json:"floor,omitempty"`
+	Room     string            `json:"room,omitempty"`
+	Device   string            `json:"device,omitempty"`
+	Name     string            `json:"name,omitempty"`
+	Location string            `json:"location,omitempty"`
+	Score    float64           `json:"score,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"`
+}
+
+var matchKinds = []string{"building", "floor", "room", "device"}
+
+func newMatch(entity string, labels map[string]string) Match {
+	ids := strings.Split(entity, "/")
+	match := Match{Kind: matchKinds[len(ids)-1], Path: entity, Labels: labels}
+	for i, id := range ids {
+		switch i {
+		case 0:
+			match.Building = id
+		case 1:
+			match.Floor = id
+		case 2:
+			match.Room = id
+		case 3:
+			match.Device = id
+		}
+	}
+	return match
+}
+
+// NewMatches converts the labels of the entities keyed by path into
+// []Match ordered by path
+func NewMatches(labelled map[string]map[string]string) []Match {
+	result := make([]Match, 0, len(labelled))
+	for entity, labels := range labelled {
+		result = append(result, newMatch(entity, labels))
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Path < result[j].Path })
+	return result
+}
+
+// NewHits converts the hits of a search into []Match keeping their order
+func NewHits(hits []search.Hit) []Match {
+	result := make([]Match, 0, len(hits))
+	for _, hit := range hits {
+		match := newMatch(hit.Path, hit.Labels)
+		match.Name = hit.Name
+		match.Location = strings.Join(hit.Names[:len(hit.Names)-1], " / ")
+		match.Score = hit.Score
+		result = append(result, match)
+	}
+	return result
+}
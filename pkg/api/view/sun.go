@@ -0,0 +1,37 @@
+package view
+
+import (
+	"time"
+
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/solar"
+)
+
+// Sun is the solar events of a day at the building in UTC, an event which
+// does not occur on the date is left out e.g. the dusk of a white night
+type Sun struct {
+	Building  string     `json:"building"`
+	Date      string     `json:"date"`
+	Latitude  float64    `json:"latitude"`
+	Longitude float64    `json:"longitude"`
+	Dawn      *time.Time `json:"dawn,omitempty"`
+	Sunrise   *time.Time `json:"sunrise,omitempty"`
+	Sunset    *time.Time `json:"sunset,omitempty"`
+	Dusk      *time.Time `json:"dusk,omitempty"`
+}
+
+// NewSun calculates the solar events of the date at the building
+func NewSun(building gateway.Building, date time.Time) Sun {
+	sun := Sun{
+		Building:  building.ID(),
+		Date:      date.Format("2006-01-02"),
+		Latitude:  building.Lat,
+		Longitude: building.Lan,
+	}
+	for event, at := range map[string]**time.Time{solar.Dawn: &sun.Dawn, solar.Sunrise: &sun.Sunrise, solar.Sunset: &sun.Sunset, solar.Dusk: &sun.Dusk} {
+		if value, err := solar.Time(event, date, building.Lat, building.Lan); err == nil {
+			*at = &value
+		}
+	}
+	return sun
+}
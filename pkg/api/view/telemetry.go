@@ -0,0 +1,39 @@
+package view
+
+import (
+	"path"
+	"time"
+
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/telemetry"
+)
+
+// Metrics represents the metrics of a device having readings
+type Metrics struct {
+	Device  string   `json:"device"`
+	Metrics []string `json:"metrics"`
+}
+
+// Metric represents the points of a metric of a device, Resolution is the
+// tier the points are read from i.e. raw, 5m or 1h and Step is the interval
+// the points are aggregated into when given
+type Metric struct {
+	Device     string            `json:"device"`
+	Metric     string            `json:"metric"`
+	Resolution string            `json:"resolution"`
+	Step       string            `json:"step,omitempty"`
+	Points     []telemetry.Point `json:"points"`
+}
+
+// NewMetric returns the view of the points of the series
+func NewMetric(series telemetry.Series, resolution telemetry.Resolution, step time.Duration, points []telemetry.Point) Metric {
+	metric := Metric{
+		Device:     path.Join(series.Floor, series.Room, series.Device),
+		Metric:     series.Metric,
+		Resolution: resolution.String(),
+		Points:     points,
+	}
+	if step > 0 {
+		metric.Step = telemetry.Resolution(step).String()
+	}
+	return metric
+}
@@ -0,0 +1,122 @@
+package view
+
+import (
+	"encoding/json"
+	"sort"
+
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+)
+
+const (
+	// DepthBuildings limits the tree to buildings
+	DepthBuildings = iota + 1
+	// DepthFloors limits the tree to buildings and floors
+	DepthFloors
+	// DepthRooms limits the tree to buildings, floors and rooms
+	DepthRooms
+	// DepthDevices includes every level of the tree
+	DepthDevices
+)
+
+var children = map[string]bool{"floors": true, "rooms": true, "devices": true}
+
+// BuildingTree represents a building along with its floors
+type BuildingTree struct {
+	Building
+	Floors []FloorTree `json:"floors,omitempty"`
+}
+
+// FloorTree represents a floor along with its rooms
+type FloorTree struct {
+	Floor
+	Rooms []RoomTree `json:"rooms,omitempty"`
+}
+
+// RoomTree represents a room along with its devices
+type RoomTree struct {
+	Room
+	Devices []Device `json:"devices,omitempty"`
+}
+
+// NewTree converts the gateway.Tree into []BuildingTree ordered by id, depth
+// limits the levels of nesting, zero or negative depth includes every level
+func NewTree(tree gateway.Tree, depth int) []BuildingTree {
+	result := make([]BuildingTree, 0, len(tree.Buildings))
+	for _, building := range tree.Buildings {
+		result = append(result, NewBuildingTree(tree, building, depth))
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result
+}
+
+// NewBuildingTree converts the building and the entities nested under it in
+// gateway.Tree into BuildingTree, depth limits the levels of nesting
+func NewBuildingTree(tree gateway.Tree, building gateway.Building, depth int) BuildingTree {
+	result := BuildingTree{Building: NewBuilding(building)}
+	if !expand(depth, DepthFloors) {
+		return result
+	}
+
+	for _, floor := range tree.FloorsOf(building) {
+		floorTree := FloorTree{Floor: NewFloor(floor)}
+		if expand(depth, DepthRooms) {
+			for _, room := range tree.RoomsOf(floor) {
+				roomTree := RoomTree{Room: NewRoom(room)}
+				if expand(depth, DepthDevices) {
+					roomTree.Devices = NewDevices(tree.DevicesOf(room))
+				}
+				floorTree.Rooms = append(floorTree.Rooms, roomTree)
+			}
+			sort.Slice(floorTree.Rooms, func(i, j int) bool { return floorTree.Rooms[i].ID < floorTree.Rooms[j].ID })
+		}
+		result.Floors = append(result.Floors, floorTree)
+	}
+	sort.Slice(result.Floors, func(i, j int) bool { return result.Floors[i].ID < result.Floors[j].ID })
+	return result
+}
+
+// Sparse returns value with only the given fields of every node, the
+// nested floors, rooms and devices are always retained
+func Sparse(value interface{}, fields []string) (interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var result interface{}
+	err = json.Unmarshal(data, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	selected := map[string]bool{}
+	for _, field := range fields {
+		selected[field] = true
+	}
+	return sparse(result, selected), nil
+}
+
+func sparse(value interface{}, fields map[string]bool) interface{} {
+	switch node := value.(type) {
+	case []interface{}:
+		for i, item := range node {
+			node[i] = sparse(item, fields)
+		}
+		return node
+	case map[string]interface{}:
+		for key, item := range node {
+			if children[key] {
+				node[key] = sparse(item, fields)
+			} else if !fields[key] {
+				delete(node, key)
+			}
+		}
+		return node
+	default:
+		return value
+	}
+}
+
+func expand(depth, level int) bool {
+	return depth <= 0 || depth >= level
+}
@@ -0,0 +1,33 @@
+package view
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation/v3"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+)
+
+// renameFields rewrites the keys of validation errors so that the
+// error refers to the field names exposed by the view model
+func renameFields(err error, fields map[string]string) error {
+	errors, ok := err.(validation.Errors)
+	if !ok {
+		return err
+	}
+
+	result := validation.Errors{}
+	for field, fieldErr := range errors {
+		if name, ok := fields[field]; ok {
+			field = name
+		}
+		result[field] = fieldErr
+	}
+	return result
+}
+
+// entityID returns the id of the entity or empty string when the
+// entity is not associated
+func entityID(entity gateway.Entity) string {
+	if entity == nil {
+		return ""
+	}
+	return entity.ID()
+}
@@ -0,0 +1,220 @@
+package audit
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// Success is the outcome of the requests responded with 2xx
+	Success = "success"
+	// Failure is the outcome of the requests responded with an error
+	Failure = "failure"
+)
+
+// Actor is the principal who made the request, it is anonymous when the
+// authentication is disabled
+type Actor struct {
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name"`
+	Kind string `json:"kind,omitempty"`
+}
+
+// Anonymous is the actor of the requests made without authentication
+var Anonymous = Actor{Name: "anonymous"}
+
+// Change is a field which differs between the entity before and after
+// the request, the path of a nested field is joined by dots
+type Change struct {
+	Path   string          `json:"path"`
+	Before json.RawMessage `json:"before,omitempty"`
+	After  json.RawMessage `json:"after,omitempty"`
+}
+
+// Entry records a create, update, delete or a device command, the entity
+// is the ids of the building, floor, room and device joined by /
+type Entry struct {
+	ID      string          `json:"id"`
+	Time    time.Time       `json:"time"`
+	Actor   Actor           `json:"actor"`
+	Method  string          `json:"method"`
+	Route   string          `json:"route"`
+	Entity  string          `json:"entity,omitempty"`
+	Before  json.RawMessage `json:"before,omitempty"`
+	After   json.RawMessage `json:"after,omitempty"`
+	Diff    []Change        `json:"diff,omitempty"`
+	Status  int             `json:"status"`
+	Outcome string          `json:"outcome"`
+	Error   string          `json:"error,omitempty"`
+}
+
+// Entries represents the entries ordered by time
+type Entries []Entry
+
+// Query filters the entries, the entity matches the entries of the
+// entity along with the entities nested in it
+type Query struct {
+	Entity string
+	Since  time.Time
+	Until  time.Time
+}
+
+// NewEntry returns the entry of a request responded with the status at
+// the time, the id orders the entries by time
+func NewEntry(now time.Time, actor Actor, method, route, entity string, status int) (Entry, error) {
+	suffix := make([]byte, 4)
+	_, err := rand.Read(suffix)
+	if err != nil {
+		return Entry{}, fmt.Errorf("unable to generate random bytes, reason: %v", err)
+	}
+
+	outcome := Success
+	if status >= 300 {
+		outcome = Failure
+	}
+	return Entry{
+		ID:      fmt.Sprintf("%019d-%s", now.UnixNano(), hex.EncodeToString(suffix)),
+		Time:    now.UTC(),
+		Actor:   actor,
+		Method:  method,
+		Route:   route,
+		Entity:  entity,
+		Status:  status,
+		Outcome: outcome,
+	}, nil
+}
+
+// NewEntryFromJSON parses the entry persisted as JSON
+func NewEntryFromJSON(data []byte) (Entry, error) {
+	entry := Entry{}
+	err := json.Unmarshal(data, &entry)
+	if err != nil {
+		return Entry{}, err
+	}
+	return entry, nil
+}
+
+// SetState records the entity before and after the request along with
+// the fields which changed, nil is an entity which does not exist
+func (entry *Entry) SetState(before, after interface{}) error {
+	var err error
+	entry.Before, err = marshal(before)
+	if err != nil {
+		return err
+	}
+	entry.After, err = marshal(after)
+	if err != nil {
+		return err
+	}
+	entry.Diff, err = Diff(entry.Before, entry.After)
+	return err
+}
+
+// Matches returns true when the entry satisfies the query
+func (query Query) Matches(entry Entry) bool {
+	if query.Entity != "" && entry.Entity != query.Entity && !strings.HasPrefix(entry.Entity, query.Entity+"/") {
+		return false
+	}
+	if !query.Since.IsZero() && entry.Time.Before(query.Since) {
+		return false
+	}
+	if !query.Until.IsZero() && !entry.Time.Before(query.Until) {
+		return false
+	}
+	return true
+}
+
+// WriteJSONLines writes every entry as JSON on its own line
+func (entries Entries) WriteJSONLines(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	for _, entry := range entries {
+		err := encoder.Encode(entry)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Diff returns the fields which differ between the JSON documents, the
+// objects are compared field by field and the rest as a whole
+func Diff(before, after json.RawMessage) ([]Change, error) {
+	var beforeValue, afterValue interface{}
+	if len(before) > 0 {
+		if err := json.Unmarshal(before, &beforeValue); err != nil {
+			return nil, err
+		}
+	}
+	if len(after) > 0 {
+		if err := json.Unmarshal(after, &afterValue); err != nil {
+			return nil, err
+		}
+	}
+
+	changes := []Change{}
+	err := diff("", beforeValue, afterValue, &changes)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes, nil
+}
+
+func diff(path string, before, after interface{}, changes *[]Change) error {
+	if reflect.DeepEqual(before, after) {
+		return nil
+	}
+
+	beforeFields, beforeIsObject := before.(map[string]interface{})
+	afterFields, afterIsObject := after.(map[string]interface{})
+	if (beforeIsObject || before == nil) && (afterIsObject || after == nil) && (beforeIsObject || afterIsObject) {
+		keys := map[string]bool{}
+		for key := range beforeFields {
+			keys[key] = true
+		}
+		for key := range afterFields {
+			keys[key] = true
+		}
+		for key := range keys {
+			err := diff(join(path, key), beforeFields[key], afterFields[key], changes)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	change := Change{Path: path}
+	var err error
+	change.Before, err = marshal(before)
+	if err != nil {
+		return err
+	}
+	change.After, err = marshal(after)
+	if err != nil {
+		return err
+	}
+	*changes = append(*changes, change)
+	return nil
+}
+
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func marshal(value interface{}) (json.RawMessage, error) {
+	if value == nil {
+		return nil, nil
+	}
+	return json.Marshal(value)
+}
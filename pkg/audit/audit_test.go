@@ -0,0 +1,106 @@
+package audit_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/audit"
+)
+
+var now = time.Date(2026, 10, 19, 3, 0, 0, 0, time.UTC)
+
+func TestNewEntry(t *testing.T) {
+	t.Run("should order the ids by time", func(t *testing.T) {
+		first, err := audit.NewEntry(now, audit.Anonymous, "DELETE", "/v1/buildings/home", "home", 200)
+		assert.NoError(t, err)
+		second, err := audit.NewEntry(now.Add(time.Nanosecond), audit.Anonymous, "DELETE", "/v1/buildings/home", "home", 200)
+		assert.NoError(t, err)
+
+		assert.Less(t, first.ID, second.ID)
+		assert.Equal(t, audit.Success, first.Outcome)
+	})
+
+	t.Run("should fail the outcome of the errors", func(t *testing.T) {
+		entry, err := audit.NewEntry(now, audit.Anonymous, "POST", "/v1/buildings", "home", 409)
+
+		assert.NoError(t, err)
+		assert.Equal(t, audit.Failure, entry.Outcome)
+	})
+}
+
+func TestEntry_SetState(t *testing.T) {
+	t.Run("should diff the changed fields", func(t *testing.T) {
+		entry := audit.Entry{}
+		before := map[string]interface{}{"id": "terrace", "name": "Terrace", "location": map[string]interface{}{"level": 2, "open": true}}
+		after := map[string]interface{}{"id": "terrace", "name": "Roof", "location": map[string]interface{}{"level": 3, "open": true}}
+
+		err := entry.SetState(before, after)
+
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"id":"terrace","name":"Terrace","location":{"level":2,"open":true}}`, string(entry.Before))
+		assert.Equal(t, []audit.Change{
+			{Path: "location.level", Before: json.RawMessage("2"), After: json.RawMessage("3")},
+			{Path: "name", Before: json.RawMessage(`"Terrace"`), After: json.RawMessage(`"Roof"`)},
+		}, entry.Diff)
+	})
+
+	t.Run("should diff every field of a deleted entity", func(t *testing.T) {
+		entry := audit.Entry{}
+
+		err := entry.SetState(map[string]string{"id": "terrace", "name": "Terrace"}, nil)
+
+		assert.NoError(t, err)
+		assert.Nil(t, entry.After)
+		assert.Equal(t, []audit.Change{
+			{Path: "id", Before: json.RawMessage(`"terrace"`)},
+			{Path: "name", Before: json.RawMessage(`"Terrace"`)},
+		}, entry.Diff)
+	})
+
+	t.Run("should compare the lists as a whole", func(t *testing.T) {
+		changes, err := audit.Diff(json.RawMessage(`{"tags":["a","b"]}`), json.RawMessage(`{"tags":["a"]}`))
+
+		assert.NoError(t, err)
+		assert.Equal(t, []audit.Change{{Path: "tags", Before: json.RawMessage(`["a","b"]`), After: json.RawMessage(`["a"]`)}}, changes)
+	})
+}
+
+func TestQuery_Matches(t *testing.T) {
+	entry := audit.Entry{Entity: "home/terrace/garden", Time: now}
+
+	for _, test := range []struct {
+		query   audit.Query
+		matches bool
+	}{
+		{audit.Query{}, true},
+		{audit.Query{Entity: "home/terrace"}, true},
+		{audit.Query{Entity: "home/terrace/garden"}, true},
+		{audit.Query{Entity: "home/terr"}, false},
+		{audit.Query{Entity: "guest-house"}, false},
+		{audit.Query{Since: now}, true},
+		{audit.Query{Since: now.Add(time.Second)}, false},
+		{audit.Query{Until: now}, false},
+		{audit.Query{Until: now.Add(time.Second)}, true},
+	} {
+		assert.Equal(t, test.matches, test.query.Matches(entry), "%+v", test.query)
+	}
+}
+
+func TestEntries_WriteJSONLines(t *testing.T) {
+	entries := audit.Entries{{ID: "1", Time: now, Actor: audit.Anonymous}, {ID: "2", Time: now, Actor: audit.Anonymous}}
+	buffer := &bytes.Buffer{}
+
+	err := entries.WriteJSONLines(buffer)
+
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	if assert.Len(t, lines, 2) {
+		entry, err := audit.NewEntryFromJSON([]byte(lines[1]))
+		assert.NoError(t, err)
+		assert.Equal(t, entries[1], entry)
+	}
+}
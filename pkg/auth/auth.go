@@ -0,0 +1,270 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Kind is the kind of the credentials issued for a token
+type Kind string
+
+const (
+	// APIKey is a static key compared against the hash persisted in the store
+	APIKey Kind = "api-key"
+	// JWT is a bearer token signed using HMAC SHA256 with the signing key
+	JWT Kind = "jwt"
+	// Certificate maps the common name of a client certificate verified
+	// over mutual TLS to the role and the scopes of the token
+	Certificate Kind = "certificate"
+	// Admin is the kind of the principal authenticated using the admin token
+	// of the server, such tokens are never persisted in the store
+	Admin Kind = "admin"
+
+	apiKeyPrefix = "dwk_"
+	jwtAlgorithm = "HS256"
+)
+
+// Unauthorized is returned when the credentials are missing, invalid,
+// expired or revoked
+type Unauthorized string
+
+func (err Unauthorized) Error() string {
+	return string(err)
+}
+
+// Token describes the credentials issued to a client, the secret of an
+// API key is persisted only as its hash. Revoking a token deletes it
+// and invalidates the API key or the JWT issued with it. The role and
+// the scopes are read from the store on every request, changing them
+// applies to the credentials issued already
+type Token struct {
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	Kind      Kind       `json:"kind"`
+	Hash      string     `json:"hash,omitempty"`
+	Role      Role       `json:"role,omitempty"`
+	Scopes    []Scope    `json:"scopes,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+// Tokens represents the tokens keyed by id
+type Tokens map[string]Token
+
+type jwtHeader struct {
+	Algorithm string `json:"alg"`
+	Type      string `json:"typ"`
+}
+
+type jwtClaims struct {
+	ID        string `json:"jti"`
+	Subject   string `json:"sub"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// NewTokens parses the tokens persisted as JSON
+func NewTokens(data []byte) (Tokens, error) {
+	tokens := Tokens{}
+	err := json.Unmarshal(data, &tokens)
+	if err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// NewSigningKey returns a random key to sign the JWTs
+func NewSigningKey() ([]byte, error) {
+	return random(32)
+}
+
+// NewAPIKey returns the token along with the API key issued for it, the
+// key never expires when ttl is zero
+func NewAPIKey(name string, now time.Time, ttl time.Duration) (Token, string, error) {
+	token, err := newToken(name, APIKey, now, ttl)
+	if err != nil {
+		return Token{}, "", err
+	}
+
+	secret, err := random(32)
+	if err != nil {
+		return Token{}, "", err
+	}
+	token.Hash = hash(hex.EncodeToString(secret))
+	return token, apiKeyPrefix + token.ID + "_" + hex.EncodeToString(secret), nil
+}
+
+// NewJWT returns the token along with the JWT issued for it, the JWT is
+// signed using the signing key and expires after ttl
+func NewJWT(name string, now time.Time, ttl time.Duration, signingKey []byte) (Token, string, error) {
+	if ttl <= 0 {
+		return Token{}, "", errors.New("ttl of a jwt should be greater than zero")
+	}
+
+	token, err := newToken(name, JWT, now, ttl)
+	if err != nil {
+		return Token{}, "", err
+	}
+
+	header, err := encode(jwtHeader{Algorithm: jwtAlgorithm, Type: "JWT"})
+	if err != nil {
+		return Token{}, "", err
+	}
+	claims, err := encode(jwtClaims{ID: token.ID, Subject: name, IssuedAt: now.Unix(), ExpiresAt: token.ExpiresAt.Unix()})
+	if err != nil {
+		return Token{}, "", err
+	}
+
+	unsigned := header + "." + claims
+	return token, unsigned + "." + sign(unsigned, signingKey), nil
+}
+
+// NewCertificate returns the token of the client certificates with the
+// name as their common name, no secret is issued as the certificate
+// authority vouches for the client
+func NewCertificate(name string, now time.Time, ttl time.Duration) (Token, error) {
+	return newToken(name, Certificate, now, ttl)
+}
+
+// Authenticate returns the token of the credentials i.e. an API key or a
+// JWT, the error is always Unauthorized
+func (tokens Tokens) Authenticate(credentials string, signingKey []byte, now time.Time) (Token, error) {
+	var token Token
+	var err error
+	if strings.HasPrefix(credentials, apiKeyPrefix) {
+		token, err = tokens.apiKey(strings.TrimPrefix(credentials, apiKeyPrefix))
+	} else {
+		token, err = tokens.jwt(credentials, signingKey, now)
+	}
+	if err != nil {
+		return Token{}, err
+	}
+	return token.unexpired(now)
+}
+
+// AuthenticateCertificate returns the token of the client certificate
+// given as its common name, the certificate should have been verified
+// against the client CA already. The error is always Unauthorized
+func (tokens Tokens) AuthenticateCertificate(commonName string, now time.Time) (Token, error) {
+	for _, token := range tokens {
+		if token.Kind == Certificate && token.Name == commonName {
+			return token.unexpired(now)
+		}
+	}
+	return Token{}, Unauthorized(fmt.Sprintf("client certificate %s is not mapped to a token, use 'dwarka token create %s --type certificate'", commonName, commonName))
+}
+
+func (token Token) unexpired(now time.Time) (Token, error) {
+	if token.ExpiresAt != nil && !now.Before(*token.ExpiresAt) {
+		return Token{}, Unauthorized(fmt.Sprintf("token %s has expired", token.ID))
+	}
+	return token, nil
+}
+
+func (tokens Tokens) apiKey(key string) (Token, error) {
+	parts := strings.SplitN(key, "_", 2)
+	if len(parts) != 2 {
+		return Token{}, Unauthorized("api key is malformed")
+	}
+
+	token, ok := tokens[parts[0]]
+	if !ok || token.Kind != APIKey {
+		return Token{}, Unauthorized("api key is invalid or revoked")
+	}
+	if subtle.ConstantTimeCompare([]byte(hash(parts[1])), []byte(token.Hash)) != 1 {
+		return Token{}, Unauthorized("api key is invalid or revoked")
+	}
+	return token, nil
+}
+
+func (tokens Tokens) jwt(credentials string, signingKey []byte, now time.Time) (Token, error) {
+	parts := strings.Split(credentials, ".")
+	if len(parts) != 3 {
+		return Token{}, Unauthorized("token is neither an api key nor a jwt")
+	}
+
+	header := jwtHeader{}
+	if decode(parts[0], &header) != nil || header.Algorithm != jwtAlgorithm {
+		return Token{}, Unauthorized(fmt.Sprintf("jwt should be signed using %s", jwtAlgorithm))
+	}
+	if !hmac.Equal([]byte(parts[2]), []byte(sign(parts[0]+"."+parts[1], signingKey))) {
+		return Token{}, Unauthorized("signature of the jwt is invalid")
+	}
+
+	claims := jwtClaims{}
+	if decode(parts[1], &claims) != nil {
+		return Token{}, Unauthorized("claims of the jwt are malformed")
+	}
+	if claims.ExpiresAt == 0 || now.Unix() >= claims.ExpiresAt {
+		return Token{}, Unauthorized("jwt has expired")
+	}
+
+	token, ok := tokens[claims.ID]
+	if !ok || token.Kind != JWT {
+		return Token{}, Unauthorized(fmt.Sprintf("jwt %s has been revoked", claims.ID))
+	}
+	return token, nil
+}
+
+func newToken(name string, kind Kind, now time.Time, ttl time.Duration) (Token, error) {
+	if strings.TrimSpace(name) == "" {
+		return Token{}, errors.New("name of the token should not be empty")
+	}
+
+	id, err := random(8)
+	if err != nil {
+		return Token{}, err
+	}
+
+	token := Token{ID: hex.EncodeToString(id), Name: name, Kind: kind, CreatedAt: now.UTC()}
+	if ttl > 0 {
+		expiresAt := now.Add(ttl).UTC()
+		token.ExpiresAt = &expiresAt
+	}
+	return token, nil
+}
+
+func random(size int) ([]byte, error) {
+	value := make([]byte, size)
+	_, err := rand.Read(value)
+	if err != nil {
+		return nil, fmt.Errorf("unable to generate random bytes, reason: %v", err)
+	}
+	return value, nil
+}
+
+func hash(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func sign(unsigned string, signingKey []byte) string {
+	mac := hmac.New(sha256.New, signingKey)
+	mac.Write([]byte(unsigned))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func encode(value interface{}) (string, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decode(part string, value interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, value)
+}
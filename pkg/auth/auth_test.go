@@ -0,0 +1,128 @@
+package auth_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/auth"
+)
+
+var (
+	now        = time.Date(2026, 10, 19, 3, 0, 0, 0, time.UTC)
+	signingKey = []byte("signing-key")
+)
+
+func TestNewAPIKey(t *testing.T) {
+	t.Run("should persist only the hash of the key", func(t *testing.T) {
+		token, key, err := auth.NewAPIKey("alice", now, 0)
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, auth.APIKey, token.Kind)
+			assert.Equal(t, "alice", token.Name)
+			assert.Nil(t, token.ExpiresAt)
+			assert.True(t, strings.HasPrefix(key, "dwk_"+token.ID+"_"))
+			assert.NotContains(t, key, token.Hash)
+		}
+	})
+
+	t.Run("should fail for empty name", func(t *testing.T) {
+		_, _, err := auth.NewAPIKey(" ", now, 0)
+
+		assert.EqualError(t, err, "name of the token should not be empty")
+	})
+}
+
+func TestNewJWT(t *testing.T) {
+	t.Run("should fail without ttl", func(t *testing.T) {
+		_, _, err := auth.NewJWT("alice", now, 0, signingKey)
+
+		assert.EqualError(t, err, "ttl of a jwt should be greater than zero")
+	})
+}
+
+func TestTokens_Authenticate(t *testing.T) {
+	apiKeyToken, apiKey, _ := auth.NewAPIKey("alice", now, time.Hour)
+	jwtToken, jwt, _ := auth.NewJWT("bob", now, time.Hour, signingKey)
+	tokens := auth.Tokens{apiKeyToken.ID: apiKeyToken, jwtToken.ID: jwtToken}
+
+	t.Run("should authenticate api key", func(t *testing.T) {
+		token, err := tokens.Authenticate(apiKey, signingKey, now)
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, apiKeyToken, token)
+		}
+	})
+
+	t.Run("should authenticate jwt", func(t *testing.T) {
+		token, err := tokens.Authenticate(jwt, signingKey, now)
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, jwtToken, token)
+		}
+	})
+
+	t.Run("should fail for wrong api key", func(t *testing.T) {
+		_, err := tokens.Authenticate("dwk_"+apiKeyToken.ID+"_secret", signingKey, now)
+
+		assert.Equal(t, auth.Unauthorized("api key is invalid or revoked"), err)
+	})
+
+	t.Run("should fail for expired credentials", func(t *testing.T) {
+		_, err := tokens.Authenticate(apiKey, signingKey, now.Add(time.Hour))
+		assert.Equal(t, auth.Unauthorized("token "+apiKeyToken.ID+" has expired"), err)
+
+		_, err = tokens.Authenticate(jwt, signingKey, now.Add(time.Hour))
+		assert.Equal(t, auth.Unauthorized("jwt has expired"), err)
+	})
+
+	t.Run("should fail for jwt signed with another key", func(t *testing.T) {
+		_, err := tokens.Authenticate(jwt, []byte("another-key"), now)
+
+		assert.Equal(t, auth.Unauthorized("signature of the jwt is invalid"), err)
+	})
+
+	t.Run("should fail for revoked credentials", func(t *testing.T) {
+		revoked := auth.Tokens{}
+
+		_, err := revoked.Authenticate(apiKey, signingKey, now)
+		assert.Equal(t, auth.Unauthorized("api key is invalid or revoked"), err)
+
+		_, err = revoked.Authenticate(jwt, signingKey, now)
+		assert.Equal(t, auth.Unauthorized("jwt "+jwtToken.ID+" has been revoked"), err)
+	})
+
+	t.Run("should fail for malformed credentials", func(t *testing.T) {
+		_, err := tokens.Authenticate("secret", signingKey, now)
+
+		assert.Equal(t, auth.Unauthorized("token is neither an api key nor a jwt"), err)
+	})
+}
+
+func TestTokens_AuthenticateCertificate(t *testing.T) {
+	certificateToken, _ := auth.NewCertificate("kitchen-panel", now, time.Hour)
+	apiKeyToken, _, _ := auth.NewAPIKey("hall-panel", now, 0)
+	tokens := auth.Tokens{certificateToken.ID: certificateToken, apiKeyToken.ID: apiKeyToken}
+
+	t.Run("should authenticate the common name", func(t *testing.T) {
+		token, err := tokens.AuthenticateCertificate("kitchen-panel", now)
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, certificateToken, token)
+			assert.Empty(t, token.Hash)
+		}
+	})
+
+	t.Run("should fail for unmapped common name", func(t *testing.T) {
+		_, err := tokens.AuthenticateCertificate("hall-panel", now)
+
+		assert.Equal(t, auth.Unauthorized("client certificate hall-panel is not mapped to a token, use 'dwarka token create hall-panel --type certificate'"), err)
+	})
+
+	t.Run("should fail for expired token", func(t *testing.T) {
+		_, err := tokens.AuthenticateCertificate("kitchen-panel", now.Add(time.Hour))
+
+		assert.Equal(t, auth.Unauthorized("token "+certificateToken.ID+" has expired"), err)
+	})
+}
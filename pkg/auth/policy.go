@@ -0,0 +1,159 @@
+package auth
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Role grants the actions a token is allowed to perform
+type Role string
+
+// Action is an operation on the buildings, floors, rooms and devices
+type Action string
+
+const (
+	// RoleAdmin is allowed every action including the admin routes
+	RoleAdmin Role = "admin"
+	// RoleEditor is allowed to change the buildings, floors, rooms and devices
+	RoleEditor Role = "editor"
+	// RoleOperator is allowed to control the devices e.g. switch them on
+	RoleOperator Role = "operator"
+	// RoleViewer is allowed to read only
+	RoleViewer Role = "viewer"
+
+	// Read reads the entities
+	Read Action = "read"
+	// Control changes the state of the devices e.g. switching them on
+	Control Action = "control"
+	// Write creates, updates or deletes the entities
+	Write Action = "write"
+	// Manage administers the server e.g. backups and tokens
+	Manage Action = "manage"
+)
+
+var permissions = map[Role][]Action{
+	RoleAdmin:    {Read, Control, Write, Manage},
+	RoleEditor:   {Read, Control, Write},
+	RoleOperator: {Read, Control},
+	RoleViewer:   {Read},
+}
+
+// Forbidden is returned when the token is not allowed to perform the
+// action on the resource
+type Forbidden string
+
+func (err Forbidden) Error() string {
+	return string(err)
+}
+
+// Scope limits a token to a building, floor or room along with everything
+// nested in it, given as the ids joined by / e.g. home/first-floor/kitchen
+type Scope string
+
+// NewRole parses the role
+func NewRole(role string) (Role, error) {
+	if _, ok := permissions[Role(role)]; !ok {
+		return "", fmt.Errorf("unsupported role '%s', expected admin, editor, operator or viewer", role)
+	}
+	return Role(role), nil
+}
+
+// NewScope parses the scope given as building, building/floor or
+// building/floor/room
+func NewScope(scope string) (Scope, error) {
+	ids := strings.Split(scope, "/")
+	if len(ids) > 3 {
+		return "", fmt.Errorf("scope '%s' should be a building, floor or room e.g. home/first-floor/kitchen", scope)
+	}
+	for _, id := range ids {
+		if id == "" {
+			return "", fmt.Errorf("scope '%s' should not have empty ids", scope)
+		}
+	}
+	return Scope(scope), nil
+}
+
+// Allows returns true when the role grants the action
+func (role Role) Allows(action Action) bool {
+	for _, allowed := range permissions[role] {
+		if allowed == action {
+			return true
+		}
+	}
+	return false
+}
+
+// contains returns true when the resource is the scope or nested in it
+func (scope Scope) contains(resource []string) bool {
+	ids := strings.Split(string(scope), "/")
+	if len(resource) < len(ids) {
+		return false
+	}
+	for i, id := range ids {
+		if resource[i] != id {
+			return false
+		}
+	}
+	return true
+}
+
+// encloses returns true when the scope is nested in the resource
+func (scope Scope) encloses(resource []string) bool {
+	ids := strings.Split(string(scope), "/")
+	return len(resource) < len(ids) && Scope(strings.Join(ids[:len(resource)], "/")).contains(resource)
+}
+
+// EffectiveRole returns the role of the token, the tokens issued before
+// the roles were introduced have no role and remain allowed every action
+func (token Token) EffectiveRole() Role {
+	if token.Role == "" {
+		return RoleAdmin
+	}
+	return token.Role
+}
+
+// AuthorizeRole returns Forbidden unless the role of the token allows the
+// action regardless of its scopes
+func (token Token) AuthorizeRole(action Action) error {
+	role := token.EffectiveRole()
+	if !role.Allows(action) {
+		return Forbidden(fmt.Sprintf("role %s is not allowed to %s", role, action))
+	}
+	return nil
+}
+
+// Authorize returns Forbidden unless the role of the token allows the
+// action on the resource given as the ids of the building, floor and room.
+// An empty resource is the whole home, scoped tokens are allowed to read
+// it as long as the entities outside their scopes are left out. The
+// buildings and floors enclosing the scopes are readable as well so that
+// the scoped entities can be reached
+func (token Token) Authorize(action Action, resource ...string) error {
+	if err := token.AuthorizeRole(action); err != nil {
+		return err
+	}
+	if len(token.Scopes) == 0 || (len(resource) == 0 && action == Read) {
+		return nil
+	}
+
+	for _, scope := range token.Scopes {
+		if len(resource) > 0 && scope.contains(resource) {
+			return nil
+		}
+		if action == Read && scope.encloses(resource) {
+			return nil
+		}
+	}
+
+	scopes := make([]string, 0, len(token.Scopes))
+	for _, scope := range token.Scopes {
+		scopes = append(scopes, string(scope))
+	}
+	return Forbidden(fmt.Sprintf("token is limited to %s", strings.Join(scopes, ", ")))
+}
+
+// Visible returns true when the token is allowed to read the resource
+// given as the ids of the building, floor and room
+func (token Token) Visible(resource ...string) bool {
+	return token.Authorize(Read, resource...) == nil
+}
@@ -0,0 +1,88 @@
+package auth_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/auth"
+)
+
+func TestNewRole(t *testing.T) {
+	role, err := auth.NewRole("operator")
+	assert.NoError(t, err)
+	assert.Equal(t, auth.RoleOperator, role)
+
+	_, err = auth.NewRole("owner")
+	assert.EqualError(t, err, "unsupported role 'owner', expected admin, editor, operator or viewer")
+}
+
+func TestNewScope(t *testing.T) {
+	for scope, message := range map[string]string{
+		"home/first-floor/kitchen/fridge": "scope 'home/first-floor/kitchen/fridge' should be a building, floor or room e.g. home/first-floor/kitchen",
+		"home//kitchen":                   "scope 'home//kitchen' should not have empty ids",
+		"":                                "scope '' should not have empty ids",
+	} {
+		_, err := auth.NewScope(scope)
+
+		assert.EqualError(t, err, message)
+	}
+}
+
+func TestToken_Authorize(t *testing.T) {
+	t.Run("should allow the actions of the role", func(t *testing.T) {
+		for role, allowed := range map[auth.Role][]auth.Action{
+			auth.RoleAdmin:    {auth.Read, auth.Control, auth.Write, auth.Manage},
+			auth.RoleEditor:   {auth.Read, auth.Control, auth.Write},
+			auth.RoleOperator: {auth.Read, auth.Control},
+			auth.RoleViewer:   {auth.Read},
+			"":                {auth.Read, auth.Control, auth.Write, auth.Manage},
+		} {
+			token := auth.Token{Role: role}
+			for _, action := range []auth.Action{auth.Read, auth.Control, auth.Write, auth.Manage} {
+				err := token.Authorize(action, "home")
+
+				expected := false
+				for _, value := range allowed {
+					expected = expected || value == action
+				}
+				assert.Equal(t, expected, err == nil, "%s should be allowed to %s: %v", role, action, expected)
+			}
+		}
+	})
+
+	t.Run("should describe the denied action", func(t *testing.T) {
+		err := auth.Token{Role: auth.RoleOperator}.Authorize(auth.Write, "home", "first-floor")
+
+		assert.Equal(t, auth.Forbidden("role operator is not allowed to write"), err)
+	})
+
+	t.Run("should limit the token to its scopes", func(t *testing.T) {
+		token := auth.Token{Role: auth.RoleEditor, Scopes: []auth.Scope{"guest-house", "home/first-floor/kitchen"}}
+
+		for _, test := range []struct {
+			action   auth.Action
+			resource []string
+			allowed  bool
+		}{
+			{auth.Write, []string{"guest-house"}, true},
+			{auth.Write, []string{"guest-house", "ground-floor", "hall"}, true},
+			{auth.Write, []string{"home", "first-floor", "kitchen"}, true},
+			{auth.Write, []string{"home", "first-floor"}, false},
+			{auth.Read, []string{"home", "first-floor"}, true},
+			{auth.Read, []string{"home"}, true},
+			{auth.Read, []string{"home", "first-floor", "bedroom"}, false},
+			{auth.Read, []string{"home", "ground-floor"}, false},
+			{auth.Read, []string{"farm-house"}, false},
+			{auth.Read, nil, true},
+			{auth.Write, nil, false},
+		} {
+			err := token.Authorize(test.action, test.resource...)
+
+			if test.allowed {
+				assert.NoError(t, err, "%s %v", test.action, test.resource)
+			} else {
+				assert.Equal(t, auth.Forbidden("token is limited to guest-house, home/first-floor/kitchen"), err, "%s %v", test.action, test.resource)
+			}
+		}
+	})
+}
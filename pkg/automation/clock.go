@@ -0,0 +1,81 @@
+package automation
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock tells the time to the engine
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type systemClock struct{}
+
+// SystemClock is the Clock of the wall time in the local time zone
+var SystemClock Clock = systemClock{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// ManualClock is a Clock which moves only when it is advanced, the
+// engine running on it is deterministic e.g. in tests
+type ManualClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []waiter
+}
+
+type waiter struct {
+	deadline time.Time
+	c        chan time.Time
+}
+
+// NewManualClock returns a ManualClock stopped at now
+func NewManualClock(now time.Time) *ManualClock {
+	return &ManualClock{now: now}
+}
+
+// Now returns the time the clock is stopped at
+func (clock *ManualClock) Now() time.Time {
+	clock.mu.Lock()
+	defer clock.mu.Unlock()
+	return clock.now
+}
+
+// After returns the channel which receives the time once the clock is
+// advanced by d
+func (clock *ManualClock) After(d time.Duration) <-chan time.Time {
+	clock.mu.Lock()
+	defer clock.mu.Unlock()
+	c := make(chan time.Time, 1)
+	if d <= 0 {
+		c <- clock.now
+		return c
+	}
+	clock.waiters = append(clock.waiters, waiter{deadline: clock.now.Add(d), c: c})
+	return c
+}
+
+// Advance moves the clock by d and fires the channels of After which are
+// due by then
+func (clock *ManualClock) Advance(d time.Duration) {
+	clock.mu.Lock()
+	defer clock.mu.Unlock()
+	clock.now = clock.now.Add(d)
+	pending := clock.waiters[:0]
+	for _, w := range clock.waiters {
+		if w.deadline.After(clock.now) {
+			pending = append(pending, w)
+			continue
+		}
+		w.c <- clock.now
+	}
+	clock.waiters = pending
+}
@@ -0,0 +1,592 @@
+package automation
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/solar"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/store"
+)
+
+const (
+	// EventState is reported when a device changes its state e.g. a door
+	// sensor opens or a light is switched on
+	EventState = "state"
+	// EventOccupancy is reported when a room becomes occupied or vacant
+	EventOccupancy = "occupancy"
+	// EventMQTT is reported when a message is published on a topic
+	EventMQTT = "mqtt"
+
+	// Occupied is the state of an occupied room
+	Occupied = "occupied"
+	// Vacant is the state of a vacant room
+	Vacant = "vacant"
+
+	queueSize      = 64
+	webhookTimeout = 10 * time.Second
+)
+
+// Event is reported to the engine by the devices of a building. Path is
+// given as floor-id/room-id/device-id for the state and as
+// floor-id/room-id for the occupancy
+type Event struct {
+	Kind     string `json:"kind"`
+	Building string `json:"building"`
+	Path     string `json:"path,omitempty"`
+	State    string `json:"state,omitempty"`
+	Topic    string `json:"topic,omitempty"`
+	Payload  string `json:"payload,omitempty"`
+}
+
+// Validate validates whether event has all the necessary fields
+func (event Event) Validate() error {
+	switch event.Kind {
+	case EventState:
+		if !validPath(event.Path, 3) {
+			return fmt.Errorf("path %s should be given as floor-id/room-id/device-id", event.Path)
+		}
+		if event.State == "" {
+			return errors.New("state is required")
+		}
+	case EventOccupancy:
+		if !validPath(event.Path, 2) {
+			return fmt.Errorf("path %s should be given as floor-id/room-id", event.Path)
+		}
+		if event.State != Occupied && event.State != Vacant {
+			return fmt.Errorf("state %s not supported, expected occupied or vacant", event.State)
+		}
+	case EventMQTT:
+		if event.Topic == "" || strings.ContainsAny(event.Topic, "+#") {
+			return fmt.Errorf("topic '%s' should be given without wildcards", event.Topic)
+		}
+	default:
+		return fmt.Errorf("kind %s not supported, expected state, occupancy or mqtt", event.Kind)
+	}
+	return nil
+}
+
+func validPath(path string, ids int) bool {
+	parts := strings.Split(path, "/")
+	if len(parts) != ids {
+		return false
+	}
+	for _, part := range parts {
+		if part == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// Run is the outcome of an automation triggered by an event or the time
+type Run struct {
+	Automation string
+	Building   string
+	Time       time.Time
+	Trigger    gateway.Trigger
+	Results    []Result
+}
+
+// Result is the outcome of an action of the automation
+type Result struct {
+	Action gateway.Action
+	Err    error
+}
+
+// Engine runs the automations of the buildings when they are triggered
+// by the events or by the time of the day, the automations are read from
+// the store on every evaluation so that the changes apply immediately
+type Engine struct {
+	store  store.Store
+	clock  Clock
+	client *http.Client
+	events chan Event
+
+	mu sync.Mutex
+	// states holds the last reported state of the devices and the
+	// occupancy of the rooms keyed by building-id/path
+	states map[string]string
+	last   time.Time
+	// lanes hold the runs of the automations waiting for their actions
+	// keyed by building-id/automation-id
+	lanes map[string]*lane
+}
+
+// lane runs the actions of an automation in the order it was triggered
+type lane struct {
+	runs    []func() Run
+	running bool
+}
+
+// NewEngine returns the Engine of the automations in the store running
+// on the clock
+func NewEngine(store store.Store, clock Clock) *Engine {
+	return &Engine{
+		store:  store,
+		clock:  clock,
+		client: &http.Client{Timeout: webhookTimeout},
+		events: make(chan Event, queueSize),
+		states: map[string]string{},
+		last:   clock.Now(),
+		lanes:  map[string]*lane{},
+	}
+}
+
+// Publish queues the event to be evaluated by Run, false is returned when
+// the queue is full
+func (engine *Engine) Publish(event Event) bool {
+	select {
+	case engine.events <- event:
+		return true
+	default:
+		log.Printf("automation queue is full, dropped %s event of %s", event.Kind, event.Building)
+		return false
+	}
+}
+
+// Run evaluates the published events as they arrive and the time of the
+// day every minute until stop is closed. The actions are run off the loop
+// so that a slow webhook or node never holds up the events, the actions of
+// an automation are run in the order it was triggered
+func (engine *Engine) Run(stop <-chan struct{}) {
+	tick := engine.clock.After(untilNextMinute(engine.clock.Now()))
+	for {
+		select {
+		case <-stop:
+			return
+		case event := <-engine.events:
+			engine.start(engine.triggeredBy(event))
+		case now := <-tick:
+			engine.start(engine.dueAt(now))
+			tick = engine.clock.After(untilNextMinute(now))
+		}
+	}
+}
+
+// triggered is an automation whose conditions held when it was triggered
+// along with the run of its actions
+type triggered struct {
+	key string
+	run func() Run
+}
+
+// start queues the runs on the lanes of their automations
+func (engine *Engine) start(runs []triggered) {
+	engine.mu.Lock()
+	defer engine.mu.Unlock()
+	for _, run := range runs {
+		current, ok := engine.lanes[run.key]
+		if !ok {
+			current = &lane{}
+			engine.lanes[run.key] = current
+		}
+		current.runs = append(current.runs, run.run)
+		if !current.running {
+			current.running = true
+			go engine.drain(run.key, current)
+		}
+	}
+}
+
+// drain runs the queued runs of the lane until it is empty
+func (engine *Engine) drain(key string, current *lane) {
+	for {
+		engine.mu.Lock()
+		if len(current.runs) == 0 {
+			current.running = false
+			delete(engine.lanes, key)
+			engine.mu.Unlock()
+			return
+		}
+		run := current.runs[0]
+		current.runs = current.runs[1:]
+		engine.mu.Unlock()
+		run()
+	}
+}
+
+// runAll runs the actions of the triggered automations one after another
+func runAll(runs []triggered) []Run {
+	var result []Run
+	for _, run := range runs {
+		result = append(result, run.run())
+	}
+	return result
+}
+
+func untilNextMinute(now time.Time) time.Duration {
+	return now.Truncate(time.Minute).Add(time.Minute).Sub(now)
+}
+
+// Handle runs the automations of the building triggered by the event
+// waiting for their actions
+func (engine *Engine) Handle(event Event) []Run {
+	return runAll(engine.triggeredBy(event))
+}
+
+// triggeredBy returns the automations of the building triggered by the
+// event whose conditions hold
+func (engine *Engine) triggeredBy(event Event) []triggered {
+	now := engine.clock.Now()
+	key := event.Building + "/" + event.Path
+	engine.mu.Lock()
+	previous := engine.states[key]
+	if event.Kind != EventMQTT {
+		engine.states[key] = event.State
+	}
+	engine.mu.Unlock()
+
+	building, automations, err := engine.automationsOf(event.Building)
+	if err != nil {
+		log.Printf("unable to evaluate %s event of %s, reason: %v", event.Kind, event.Building, err)
+		return nil
+	}
+
+	var runs []triggered
+	for _, automation := range automations {
+		for _, trigger := range automation.Triggers {
+			if fires(trigger, event, previous) {
+				if run, ok := engine.fire(building, automation, trigger, now); ok {
+					runs = append(runs, run)
+				}
+				break
+			}
+		}
+	}
+	return runs
+}
+
+// Tick runs the automations triggered by the time of the day or by the
+// sun since the previous tick waiting for their actions
+func (engine *Engine) Tick(now time.Time) []Run {
+	return runAll(engine.dueAt(now))
+}
+
+// dueAt returns the automations triggered by the time of the day or by
+// the sun since the previous tick whose conditions hold
+func (engine *Engine) dueAt(now time.Time) []triggered {
+	engine.mu.Lock()
+	last := engine.last
+	if !now.After(last) {
+		engine.mu.Unlock()
+		return nil
+	}
+	engine.last = now
+	engine.mu.Unlock()
+
+	buildings, err := engine.store.Buildings()
+	if err != nil {
+		log.Printf("unable to evaluate the automations, reason: %v", err)
+		return nil
+	}
+	ids := make([]string, 0, len(buildings))
+	for id := range buildings {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var runs []triggered
+	for _, id := range ids {
+		building, automations, err := engine.automationsOf(id)
+		if err != nil {
+			log.Printf("unable to evaluate the automations of %s, reason: %v", id, err)
+			continue
+		}
+		for _, automation := range automations {
+			for _, trigger := range automation.Triggers {
+				if engine.due(building, trigger, last, now) {
+					if run, ok := engine.fire(building, automation, trigger, now); ok {
+						runs = append(runs, run)
+					}
+					break
+				}
+			}
+		}
+	}
+	return runs
+}
+
+// automationsOf returns the building along with its enabled automations
+// ordered by id
+func (engine *Engine) automationsOf(id string) (gateway.Building, []gateway.Automation, error) {
+	buildings, err := engine.store.Buildings()
+	if err != nil {
+		return gateway.Building{}, nil, err
+	}
+	building, ok := buildings[id]
+	if !ok {
+		return gateway.Building{}, nil, fmt.Errorf("building %s not found", id)
+	}
+
+	automations, err := engine.store.Automations(building)
+	if err != nil {
+		return gateway.Building{}, nil, err
+	}
+	enabled := make([]gateway.Automation, 0, len(automations))
+	for _, automation := range automations {
+		if !automation.Disabled {
+			enabled = append(enabled, automation)
+		}
+	}
+	sort.Slice(enabled, func(i, j int) bool { return enabled[i].ID() < enabled[j].ID() })
+	return building, enabled, nil
+}
+
+// fires returns true when the event triggers the state or the mqtt
+// trigger, the state trigger fires only when the state changes
+func fires(trigger gateway.Trigger, event Event, previous string) bool {
+	switch trigger.Type {
+	case gateway.TriggerState:
+		return event.Kind == EventState && trigger.Device == event.Path && event.State != previous &&
+			(trigger.To == "" || trigger.To == event.State) && (trigger.From == "" || trigger.From == previous)
+	case gateway.TriggerMQTT:
+		return event.Kind == EventMQTT && matchTopic(trigger.Topic, event.Topic) &&
+			(trigger.Payload == "" || trigger.Payload == event.Payload)
+	default:
+		return false
+	}
+}
+
+// matchTopic matches the topic against the filter, + matches a level of
+// the topic and # matches the rest of the levels
+func matchTopic(filter, topic string) bool {
+	filters, levels := strings.Split(filter, "/"), strings.Split(topic, "/")
+	for i, level := range filters {
+		if level == "#" {
+			return true
+		}
+		if i >= len(levels) || (level != "+" && level != levels[i]) {
+			return false
+		}
+	}
+	return len(filters) == len(levels)
+}
+
+// due returns true when the time or the sun trigger occurs after last
+// until now, the days are in the time zone of the building and otherwise
+// in the time zone of now
+func (engine *Engine) due(building gateway.Building, trigger gateway.Trigger, last, now time.Time) bool {
+	if trigger.Type != gateway.TriggerTime && trigger.Type != gateway.TriggerSun {
+		return false
+	}
+
+	location := building.Location(now.Location())
+	last = last.In(location)
+	day := time.Date(last.Year(), last.Month(), last.Day(), 0, 0, 0, 0, location)
+	for ; !day.After(now); day = day.AddDate(0, 0, 1) {
+		at, err := occurrence(building, trigger, day)
+		if err != nil {
+			log.Printf("unable to evaluate %s trigger of %s, reason: %v", trigger.Type, building.ID(), err)
+			return false
+		}
+		if at.After(last) && !at.After(now) {
+			return true
+		}
+	}
+	return false
+}
+
+// occurrence returns the time the time or the sun trigger occurs on the day
+func occurrence(building gateway.Building, trigger gateway.Trigger, day time.Time) (time.Time, error) {
+	if trigger.Type == gateway.TriggerTime {
+		return timeOfDay(building, trigger.At, day)
+	}
+
+	moment, err := trigger.Moment()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return moment.At(day, building.Lat, building.Lan)
+}
+
+// timeOfDay returns the time on the day given as 15:04 or as a solar
+// moment of the building e.g. sunset-15m
+func timeOfDay(building gateway.Building, value string, day time.Time) (time.Time, error) {
+	if timeOfDay, err := gateway.ParseTimeOfDay(value); err == nil {
+		return time.Date(day.Year(), day.Month(), day.Day(), int(timeOfDay/time.Hour), int(timeOfDay%time.Hour/time.Minute), 0, 0, day.Location()), nil
+	}
+
+	moment, err := solar.ParseMoment(value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return moment.At(day, building.Lat, building.Lan)
+}
+
+// fire returns the run of the actions of the automation when its
+// conditions hold
+func (engine *Engine) fire(building gateway.Building, automation gateway.Automation, trigger gateway.Trigger, now time.Time) (triggered, bool) {
+	tree, err := engine.store.Tree()
+	if err != nil {
+		log.Printf("unable to run automation %s of %s, reason: %v", automation.ID(), building.ID(), err)
+		return triggered{}, false
+	}
+
+	for _, condition := range automation.Conditions {
+		ok, err := engine.holds(building, tree, condition, now)
+		if err != nil {
+			log.Printf("unable to evaluate %s condition of automation %s of %s, reason: %v", condition.Type, automation.ID(), building.ID(), err)
+			return triggered{}, false
+		}
+		if !ok {
+			return triggered{}, false
+		}
+	}
+
+	return triggered{key: building.ID() + "/" + automation.ID(), run: func() Run {
+		run := Run{Automation: automation.ID(), Building: building.ID(), Time: now, Trigger: trigger}
+		failed := 0
+		for i, action := range automation.Actions {
+			err := engine.act(building, tree, run, action)
+			if err != nil {
+				failed++
+				log.Printf("action %d of automation %s of %s failed, reason: %v", i+1, automation.ID(), building.ID(), err)
+			}
+			run.Results = append(run.Results, Result{Action: action, Err: err})
+		}
+		log.Printf("automation %s of %s triggered by %s, %d of %d actions failed", automation.ID(), building.ID(), trigger.Type, failed, len(automation.Actions))
+		return run
+	}}, true
+}
+
+// holds returns true when the condition holds at now
+func (engine *Engine) holds(building gateway.Building, tree gateway.Tree, condition gateway.Condition, now time.Time) (bool, error) {
+	switch condition.Type {
+	case gateway.ConditionState:
+		state, err := engine.state(building, tree, condition.Device)
+		return state == condition.State, err
+	case gateway.ConditionOccupancy:
+		engine.mu.Lock()
+		occupied := engine.states[building.ID()+"/"+condition.Room] == Occupied
+		engine.mu.Unlock()
+		return occupied == condition.Occupied, nil
+	case gateway.ConditionTime:
+		now = now.In(building.Location(now.Location()))
+		day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		after, err := boundary(building, condition.After, day, day)
+		if err != nil {
+			return false, err
+		}
+		before, err := boundary(building, condition.Before, day, day.AddDate(0, 0, 1))
+		if err != nil {
+			return false, err
+		}
+		if after.Before(before) {
+			return !now.Before(after) && now.Before(before), nil
+		}
+		return !now.Before(after) || now.Before(before), nil
+	default:
+		return false, fmt.Errorf("condition %s not supported", condition.Type)
+	}
+}
+
+// boundary returns the time of the day given as 15:04 or as a solar
+// moment, the fallback when it is not given
+func boundary(building gateway.Building, value string, day, fallback time.Time) (time.Time, error) {
+	if value == "" {
+		return fallback, nil
+	}
+	return timeOfDay(building, value, day)
+}
+
+// state returns the last reported state of the device, the state the
+// device was last switched to is used until the device reports one
+func (engine *Engine) state(building gateway.Building, tree gateway.Tree, path string) (string, error) {
+	engine.mu.Lock()
+	state, ok := engine.states[building.ID()+"/"+path]
+	engine.mu.Unlock()
+	if ok {
+		return state, nil
+	}
+
+	ids := strings.Split(path, "/")
+	room, ok := tree.RoomsOf(tree.FloorsOf(building)[ids[0]])[ids[1]]
+	if !ok {
+		return "", nil
+	}
+	states, err := engine.store.States(room)
+	if err != nil {
+		return "", err
+	}
+	return string(states[ids[2]]), nil
+}
+
+// act runs the action of the automation
+func (engine *Engine) act(building gateway.Building, tree gateway.Tree, run Run, action gateway.Action) error {
+	switch action.Type {
+	case gateway.ActionDevice:
+		ids := strings.Split(action.Device, "/")
+		return engine.activate(tree, gateway.Scene{
+			Building: building,
+			Targets:  []gateway.Target{{Floor: ids[0], Room: ids[1], Device: ids[2], State: action.State}},
+		})
+	case gateway.ActionScene:
+		scenes, err := engine.store.Scenes(building)
+		if err != nil {
+			return err
+		}
+		scene, ok := scenes[action.Scene]
+		if !ok {
+			return fmt.Errorf("scene %s not found", action.Scene)
+		}
+		return engine.activate(tree, scene)
+	case gateway.ActionWebhook:
+		return engine.post(action.URL, run)
+	default:
+		return fmt.Errorf("action %s not supported", action.Type)
+	}
+}
+
+// activate switches the devices of the scene and records their states
+func (engine *Engine) activate(tree gateway.Tree, scene gateway.Scene) error {
+	var failed []string
+	for _, result := range scene.Activate(tree) {
+		if result.Err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", result.Target.Path(), result.Err))
+			continue
+		}
+		err := engine.store.UpsertState(*result.Device, result.Target.State)
+		if err != nil {
+			return err
+		}
+		engine.mu.Lock()
+		engine.states[scene.Building.ID()+"/"+result.Target.Path()] = string(result.Target.State)
+		engine.mu.Unlock()
+	}
+	if len(failed) > 0 {
+		return errors.New(strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// post posts the run to the url of the webhook
+func (engine *Engine) post(url string, run Run) error {
+	data, err := json.Marshal(map[string]interface{}{
+		"automation": run.Automation,
+		"building":   run.Building,
+		"time":       run.Time,
+		"trigger":    run.Trigger,
+	})
+	if err != nil {
+		return err
+	}
+
+	response, err := engine.client.Post(url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("webhook %s responded with %d", url, response.StatusCode)
+	}
+	return nil
+}
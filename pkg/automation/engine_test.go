@@ -0,0 +1,258 @@
+package automation_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/automation"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/solar"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/store"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/testutils"
+)
+
+// sunset of building-one on 2026-10-19 is at 12:29 UTC
+var evening = time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+// newStore returns the store with building-one in Bengaluru along with
+// floor-one/room-one having the porch-light, the broken-lamp and the
+// front-door
+func newStore(t *testing.T, automations ...gateway.Automation) (store.Store, *testutils.RecordingNode) {
+	building := testutils.NewBuilding("building-one")
+	building.Lat, building.Lan = 12.97, 77.59
+	persistentStore, node := testutils.NewSwitchingStore(t, building, "porch-light", "broken-lamp", "front-door")
+	for _, automation := range automations {
+		automation.Building = building
+		assert.NoError(t, persistentStore.UpsertAutomation(automation))
+	}
+	return persistentStore, node
+}
+
+func newAutomation(name string, triggers []gateway.Trigger, conditions []gateway.Condition, actions ...gateway.Action) gateway.Automation {
+	return gateway.Automation{
+		Triggers:       triggers,
+		Conditions:     conditions,
+		Actions:        actions,
+		PhysicalEntity: gateway.PhysicalEntity{Name: name},
+	}
+}
+
+var porchLightOn = gateway.Action{Type: gateway.ActionDevice, Device: "floor-one/room-one/porch-light", State: gateway.StateOn}
+
+func doorOpens() automation.Event {
+	return automation.Event{Kind: automation.EventState, Building: "building-one", Path: "floor-one/room-one/front-door", State: "open"}
+}
+
+func TestEngine_Handle(t *testing.T) {
+	welcome := newAutomation("welcome home",
+		[]gateway.Trigger{{Type: gateway.TriggerState, Device: "floor-one/room-one/front-door", To: "open"}},
+		[]gateway.Condition{{Type: gateway.ConditionTime, After: solar.Sunset, Before: solar.Sunrise}},
+		porchLightOn,
+	)
+
+	t.Run("should switch on the porch light when the door opens after sunset", func(t *testing.T) {
+		persistentStore, node := newStore(t, welcome)
+		clock := automation.NewManualClock(evening)
+		engine := automation.NewEngine(persistentStore, clock)
+
+		assert.Empty(t, engine.Handle(doorOpens()))
+		engine.Handle(automation.Event{Kind: automation.EventState, Building: "building-one", Path: "floor-one/room-one/front-door", State: "closed"})
+		clock.Advance(time.Hour)
+		runs := engine.Handle(doorOpens())
+
+		if assert.Len(t, runs, 1) {
+			assert.Equal(t, "welcome-home", runs[0].Automation)
+			assert.Equal(t, []automation.Result{{Action: porchLightOn}}, runs[0].Results)
+		}
+		assert.Equal(t, []string{"on:porch-light"}, node.Switched())
+		states, err := persistentStore.States(testutils.NewRoom("room-one"))
+		assert.NoError(t, err)
+		assert.Equal(t, gateway.States{"porch-light": gateway.StateOn}, states)
+	})
+
+	t.Run("should fire only when the state changes", func(t *testing.T) {
+		persistentStore, node := newStore(t, welcome)
+		engine := automation.NewEngine(persistentStore, automation.NewManualClock(evening.Add(time.Hour)))
+
+		engine.Handle(doorOpens())
+		engine.Handle(doorOpens())
+
+		assert.Equal(t, []string{"on:porch-light"}, node.Switched())
+	})
+
+	t.Run("should hold the state and the occupancy conditions", func(t *testing.T) {
+		lamp := gateway.Action{Type: gateway.ActionDevice, Device: "floor-one/room-one/broken-lamp", State: gateway.StateOff}
+		persistentStore, node := newStore(t, newAutomation("vacant room",
+			[]gateway.Trigger{{Type: gateway.TriggerState, Device: "floor-one/room-one/front-door", From: "open", To: "closed"}},
+			[]gateway.Condition{
+				{Type: gateway.ConditionOccupancy, Room: "floor-one/room-one", Occupied: false},
+				{Type: gateway.ConditionState, Device: "floor-one/room-one/porch-light", State: "on"},
+			},
+			lamp,
+		))
+		engine := automation.NewEngine(persistentStore, automation.NewManualClock(evening))
+		closes := automation.Event{Kind: automation.EventState, Building: "building-one", Path: "floor-one/room-one/front-door", State: "closed"}
+		assert.NoError(t, persistentStore.UpsertState(testutils.NewDevice("porch-light"), gateway.StateOn))
+
+		engine.Handle(automation.Event{Kind: automation.EventOccupancy, Building: "building-one", Path: "floor-one/room-one", State: automation.Occupied})
+		engine.Handle(doorOpens())
+		assert.Empty(t, engine.Handle(closes))
+
+		engine.Handle(automation.Event{Kind: automation.EventOccupancy, Building: "building-one", Path: "floor-one/room-one", State: automation.Vacant})
+		engine.Handle(doorOpens())
+		runs := engine.Handle(closes)
+
+		if assert.Len(t, runs, 1) {
+			assert.EqualError(t, runs[0].Results[0].Err, "floor-one/room-one/broken-lamp: node unreachable")
+		}
+		assert.Equal(t, []string{"off:broken-lamp"}, node.Switched())
+	})
+
+	t.Run("should post the run to the webhook on the mqtt message", func(t *testing.T) {
+		posted := make(chan map[string]interface{}, 2)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body := map[string]interface{}{}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			posted <- body
+		}))
+		defer server.Close()
+		persistentStore, _ := newStore(t, newAutomation("doorbell rings",
+			[]gateway.Trigger{{Type: gateway.TriggerMQTT, Topic: "home/+/doorbell/#", Payload: "pressed"}}, nil,
+			gateway.Action{Type: gateway.ActionWebhook, URL: server.URL},
+		))
+		engine := automation.NewEngine(persistentStore, automation.NewManualClock(evening))
+
+		for topic, fired := range map[string]bool{
+			"home/porch/doorbell/button": true,
+			"home/porch/doorbell":        true,
+			"home/doorbell":              false,
+			"office/porch/doorbell":      false,
+		} {
+			runs := engine.Handle(automation.Event{Kind: automation.EventMQTT, Building: "building-one", Topic: topic, Payload: "pressed"})
+			assert.Equal(t, fired, len(runs) == 1, topic)
+		}
+		assert.Empty(t, engine.Handle(automation.Event{Kind: automation.EventMQTT, Building: "building-one", Topic: "home/porch/doorbell", Payload: "released"}))
+
+		body := <-posted
+		assert.Equal(t, "doorbell-rings", body["automation"])
+		assert.Equal(t, "building-one", body["building"])
+	})
+}
+
+func TestEngine_Tick(t *testing.T) {
+	t.Run("should fire the sun and the time triggers once", func(t *testing.T) {
+		persistentStore, node := newStore(t,
+			newAutomation("porch at dusk", []gateway.Trigger{{Type: gateway.TriggerSun, Event: solar.Sunset, Offset: "-15m"}}, nil, porchLightOn),
+			newAutomation("lamp at night", []gateway.Trigger{{Type: gateway.TriggerTime, At: "13:00"}}, nil,
+				gateway.Action{Type: gateway.ActionDevice, Device: "floor-one/room-one/broken-lamp", State: gateway.StateOff}),
+		)
+		clock := automation.NewManualClock(evening)
+		engine := automation.NewEngine(persistentStore, clock)
+
+		assert.Empty(t, engine.Tick(evening.Add(10*time.Minute)))
+		runs := engine.Tick(evening.Add(20 * time.Minute))
+		if assert.Len(t, runs, 1) {
+			assert.Equal(t, "porch-at-dusk", runs[0].Automation)
+		}
+		assert.Empty(t, engine.Tick(evening.Add(30*time.Minute)))
+		runs = engine.Tick(evening.Add(25 * time.Hour))
+		assert.Len(t, runs, 2)
+
+		assert.Equal(t, []string{"on:porch-light", "off:broken-lamp", "on:porch-light"}, node.Switched())
+	})
+
+	t.Run("should fire the time trigger in the time zone of the building", func(t *testing.T) {
+		persistentStore, node := newStore(t,
+			newAutomation("porch at six", []gateway.Trigger{{Type: gateway.TriggerTime, At: "18:00"}}, nil, porchLightOn),
+		)
+		building := testutils.NewBuilding("building-one")
+		building.Lat, building.Lan, building.Timezone = 12.97, 77.59, "Asia/Kolkata"
+		assert.NoError(t, persistentStore.UpsertBuilding(building))
+		engine := automation.NewEngine(persistentStore, automation.NewManualClock(evening))
+
+		assert.Empty(t, engine.Tick(evening.Add(20*time.Minute)))
+		assert.Len(t, engine.Tick(evening.Add(30*time.Minute)), 1)
+
+		assert.Equal(t, []string{"on:porch-light"}, node.Switched())
+	})
+
+	t.Run("should fire the time trigger given as a solar moment of the building", func(t *testing.T) {
+		persistentStore, node := newStore(t,
+			newAutomation("porch at dusk", []gateway.Trigger{{Type: gateway.TriggerTime, At: "dusk-5m"}}, nil, porchLightOn),
+		)
+		engine := automation.NewEngine(persistentStore, automation.NewManualClock(evening))
+
+		assert.Empty(t, engine.Tick(evening.Add(40*time.Minute)))
+		assert.Len(t, engine.Tick(evening.Add(50*time.Minute)), 1)
+
+		assert.Equal(t, []string{"on:porch-light"}, node.Switched())
+	})
+
+	t.Run("should run on the clock until stopped", func(t *testing.T) {
+		persistentStore, node := newStore(t,
+			newAutomation("porch at dusk", []gateway.Trigger{{Type: gateway.TriggerSun, Event: solar.Sunset}}, nil, porchLightOn),
+			newAutomation("welcome home", []gateway.Trigger{{Type: gateway.TriggerState, Device: "floor-one/room-one/front-door"}}, nil,
+				gateway.Action{Type: gateway.ActionDevice, Device: "floor-one/room-one/porch-light", State: gateway.StateOff}),
+		)
+		clock := automation.NewManualClock(evening)
+		engine := automation.NewEngine(persistentStore, clock)
+		stop := make(chan struct{})
+		done := make(chan struct{})
+		go func() {
+			engine.Run(stop)
+			close(done)
+		}()
+
+		assert.True(t, engine.Publish(doorOpens()))
+		assert.Eventually(t, func() bool { return len(node.Switched()) == 1 }, time.Second, 10*time.Millisecond)
+		// the engine ticks at its own pace, the clock keeps moving until
+		// a tick passes the sunset
+		assert.Eventually(t, func() bool {
+			clock.Advance(time.Minute)
+			return len(node.Switched()) == 2
+		}, time.Second, 10*time.Millisecond)
+		close(stop)
+		<-done
+
+		assert.Equal(t, []string{"off:porch-light", "on:porch-light"}, node.Switched())
+	})
+
+	t.Run("should handle the events while a webhook is slow", func(t *testing.T) {
+		release := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-release
+		}))
+		defer server.Close()
+		defer close(release)
+		persistentStore, node := newStore(t,
+			newAutomation("doorbell rings", []gateway.Trigger{{Type: gateway.TriggerMQTT, Topic: "home/doorbell"}}, nil,
+				gateway.Action{Type: gateway.ActionWebhook, URL: server.URL}),
+			newAutomation("welcome home", []gateway.Trigger{{Type: gateway.TriggerState, Device: "floor-one/room-one/front-door"}}, nil,
+				gateway.Action{Type: gateway.ActionDevice, Device: "floor-one/room-one/porch-light", State: gateway.StateOff}),
+		)
+		engine := automation.NewEngine(persistentStore, automation.NewManualClock(evening))
+		stop := make(chan struct{})
+		defer close(stop)
+		go engine.Run(stop)
+
+		assert.True(t, engine.Publish(automation.Event{Kind: automation.EventMQTT, Building: "building-one", Topic: "home/doorbell"}))
+		assert.True(t, engine.Publish(doorOpens()))
+
+		assert.Eventually(t, func() bool { return len(node.Switched()) == 1 }, time.Second, 10*time.Millisecond)
+	})
+}
+
+func TestEvent_Validate(t *testing.T) {
+	for message, event := range map[string]automation.Event{
+		"path front-door should be given as floor-id/room-id/device-id": {Kind: automation.EventState, Path: "front-door", State: "open"},
+		"state busy not supported, expected occupied or vacant":         {Kind: automation.EventOccupancy, Path: "floor-one/room-one", State: "busy"},
+		"topic 'home/#' should be given without wildcards":              {Kind: automation.EventMQTT, Topic: "home/#"},
+		"kind motion not supported, expected state, occupancy or mqtt":  {Kind: "motion"},
+	} {
+		assert.EqualError(t, event.Validate(), message)
+	}
+}
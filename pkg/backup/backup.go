@@ -0,0 +1,278 @@
+package backup
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kvtools/valkeyrie/store"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/cron"
+	dwarkaStore "gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/store"
+)
+
+const (
+	extension         = ".json.gz"
+	checksumExtension = ".sha256"
+	// idLayout leaves out the fraction of a whole second so that the
+	// backups created before the milliseconds were kept are still parsed
+	idLayout = "20060102T150405.999Z"
+)
+
+// Backup describes a snapshot of the store persisted in the directory
+type Backup struct {
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+	Size      int64     `json:"size"`
+	Checksum  string    `json:"checksum"`
+}
+
+// NotFound is returned when the backup does not exist in the directory
+type NotFound string
+
+func (err NotFound) Error() string {
+	return string(err)
+}
+
+// Retention limits the backups kept in the directory, zero values
+// keep the backups irrespective of the count or the age
+type Retention struct {
+	Count int
+	Age   time.Duration
+}
+
+// Backups persists gzipped snapshots of the keys under the base path
+// to the directory, every snapshot has a sha256 checksum next to it
+// in the format of sha256sum
+type Backups struct {
+	dir       string
+	kvStore   store.Store
+	basePath  string
+	retention Retention
+	mu        sync.Mutex
+
+	// Clock returns the current time, used to name the backups
+	// and to apply the retention
+	Clock func() time.Time
+}
+
+// NewBackups returns Backups persisting the snapshots to the directory,
+// the directory is created when missing
+func NewBackups(dir string, kvStore store.Store, basePath string, retention Retention) (*Backups, error) {
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create backup directory %s, reason: %v", dir, err)
+	}
+	return &Backups{dir: dir, kvStore: kvStore, basePath: basePath, retention: retention, Clock: time.Now}, nil
+}
+
+// Create persists a snapshot of the store and removes the backups
+// which are no longer retained
+func (backups *Backups) Create() (Backup, error) {
+	backups.mu.Lock()
+	defer backups.mu.Unlock()
+
+	snapshot, err := dwarkaStore.Backup(backups.kvStore, backups.basePath)
+	if err != nil {
+		return Backup{}, err
+	}
+	// the id has the precision of a millisecond and so does the creation
+	// time, an existing backup is never replaced
+	now := backups.Clock()
+	snapshot.CreatedAt = now.UTC().Truncate(time.Millisecond)
+	id := snapshot.CreatedAt.Format(idLayout)
+	if _, err := os.Stat(backups.path(id)); err == nil {
+		return Backup{}, fmt.Errorf("backup %s already exists", id)
+	} else if !os.IsNotExist(err) {
+		return Backup{}, err
+	}
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return Backup{}, err
+	}
+
+	compressed := bytes.Buffer{}
+	writer := gzip.NewWriter(&compressed)
+	_, err = writer.Write(data)
+	if err == nil {
+		err = writer.Close()
+	}
+	if err != nil {
+		return Backup{}, err
+	}
+
+	checksum := sha256.Sum256(compressed.Bytes())
+	backup := Backup{ID: id, CreatedAt: snapshot.CreatedAt, Size: int64(compressed.Len()), Checksum: hex.EncodeToString(checksum[:])}
+
+	// the snapshot is renamed only after it is completely written so
+	// that a crash never leaves a partial backup behind
+	temporary := backups.path(id) + ".tmp"
+	err = ioutil.WriteFile(temporary, compressed.Bytes(), 0600)
+	if err != nil {
+		return Backup{}, err
+	}
+	err = ioutil.WriteFile(backups.path(id)+checksumExtension, []byte(fmt.Sprintf("%s  %s\n", backup.Checksum, id+extension)), 0600)
+	if err != nil {
+		_ = os.Remove(temporary)
+		return Backup{}, err
+	}
+	err = os.Rename(temporary, backups.path(id))
+	if err != nil {
+		return Backup{}, err
+	}
+
+	return backup, backups.prune(now)
+}
+
+// List returns the backups in the directory, the latest first
+func (backups *Backups) List() ([]Backup, error) {
+	files, err := filepath.Glob(filepath.Join(backups.dir, "*"+extension))
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]Backup, 0, len(files))
+	for _, file := range files {
+		id := strings.TrimSuffix(filepath.Base(file), extension)
+		backup, err := backups.backup(id)
+		if err != nil {
+			log.Printf("skipping backup %s, reason: %v", file, err)
+			continue
+		}
+		result = append(result, backup)
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].CreatedAt.After(result[j].CreatedAt) })
+	return result, nil
+}
+
+// Restore verifies the checksum of the backup and replaces every key
+// under the base path with the keys of the backup
+func (backups *Backups) Restore(id string) (Backup, error) {
+	backups.mu.Lock()
+	defer backups.mu.Unlock()
+
+	backup, err := backups.backup(id)
+	if err != nil {
+		return Backup{}, err
+	}
+
+	data, err := ioutil.ReadFile(backups.path(id))
+	if err != nil {
+		return Backup{}, err
+	}
+
+	checksum := sha256.Sum256(data)
+	if hex.EncodeToString(checksum[:]) != backup.Checksum {
+		return Backup{}, fmt.Errorf("checksum of backup %s does not match, the backup is corrupted", id)
+	}
+
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return Backup{}, err
+	}
+	defer reader.Close()
+
+	snapshot := dwarkaStore.Snapshot{}
+	err = json.NewDecoder(reader).Decode(&snapshot)
+	if err != nil {
+		return Backup{}, fmt.Errorf("unable to parse backup %s, reason: %v", id, err)
+	}
+	return backup, dwarkaStore.Restore(backups.kvStore, snapshot, backups.basePath)
+}
+
+// Run creates a backup at every time matching the schedule until the
+// stop channel is closed, failures are logged and retried at the next time
+func (backups *Backups) Run(schedule cron.Schedule, stop <-chan struct{}) {
+	for {
+		next := schedule.Next(backups.Clock())
+		if next.IsZero() {
+			log.Printf("backup schedule '%s' never matches, scheduled backups are disabled", schedule)
+			return
+		}
+
+		timer := time.NewTimer(next.Sub(backups.Clock()))
+		select {
+		case <-stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		backup, err := backups.Create()
+		if err != nil {
+			log.Printf("scheduled backup failed, reason: %v", err)
+			continue
+		}
+		log.Printf("scheduled backup %s created with %d bytes", backup.ID, backup.Size)
+	}
+}
+
+// prune removes the backups exceeding the count or the age of the
+// retention, the latest backup is always kept
+func (backups *Backups) prune(now time.Time) error {
+	list, err := backups.List()
+	if err != nil {
+		return err
+	}
+
+	for i, backup := range list {
+		if i == 0 {
+			continue
+		}
+
+		expired := backups.retention.Age > 0 && now.Sub(backup.CreatedAt) > backups.retention.Age
+		exceeded := backups.retention.Count > 0 && i >= backups.retention.Count
+		if !expired && !exceeded {
+			continue
+		}
+
+		for _, file := range []string{backups.path(backup.ID), backups.path(backup.ID) + checksumExtension} {
+			err = os.Remove(file)
+			if err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (backups *Backups) backup(id string) (Backup, error) {
+	createdAt, err := time.Parse(idLayout, id)
+	if err != nil {
+		return Backup{}, NotFound(fmt.Sprintf("backup %s not found", id))
+	}
+
+	info, err := os.Stat(backups.path(id))
+	if os.IsNotExist(err) {
+		return Backup{}, NotFound(fmt.Sprintf("backup %s not found", id))
+	} else if err != nil {
+		return Backup{}, err
+	}
+
+	data, err := ioutil.ReadFile(backups.path(id) + checksumExtension)
+	if err != nil {
+		return Backup{}, fmt.Errorf("unable to read checksum of backup %s, reason: %v", id, err)
+	}
+
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return Backup{}, fmt.Errorf("checksum of backup %s is empty", id)
+	}
+	return Backup{ID: id, CreatedAt: createdAt, Size: info.Size(), Checksum: fields[0]}, nil
+}
+
+func (backups *Backups) path(id string) string {
+	return filepath.Join(backups.dir, id+extension)
+}
@@ -0,0 +1,198 @@
+package backup_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	libKVStore "github.com/kvtools/valkeyrie/store"
+	"github.com/kvtools/valkeyrie/store/boltdb"
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/backup"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/cron"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/store"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/testutils"
+)
+
+func init() {
+	boltdb.Register()
+}
+
+// newBackups returns backups of a BoltDB store in a temp directory along
+// with the store, the clock of the backups advances a minute on every call
+func newBackups(t *testing.T, retention backup.Retention) (*backup.Backups, store.Store, string) {
+	dir := t.TempDir()
+	kvStore, err := store.NewKVStore(string(libKVStore.BOLTDB), "dwarka", filepath.Join(dir, "dwarka.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	backupDir := filepath.Join(dir, "backups")
+	backups, err := backup.NewBackups(backupDir, kvStore, "dwarka", retention)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2026, 10, 19, 3, 0, 0, 0, time.UTC)
+	backups.Clock = func() time.Time {
+		now = now.Add(time.Minute)
+		return now
+	}
+	return backups, store.NewPersistentStore("dwarka", kvStore), backupDir
+}
+
+func TestBackups_Create(t *testing.T) {
+	t.Run("should persist gzipped snapshot along with checksum", func(t *testing.T) {
+		backups, persistentStore, dir := newBackups(t, backup.Retention{})
+		assert.NoError(t, persistentStore.UpsertBuilding(testutils.NewBuilding("building-one")))
+
+		created, err := backups.Create()
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, "20261019T030100Z", created.ID)
+			checksum, err := ioutil.ReadFile(filepath.Join(dir, created.ID+".json.gz.sha256"))
+			assert.NoError(t, err)
+			assert.Equal(t, created.Checksum+"  20261019T030100Z.json.gz\n", string(checksum))
+
+			list, err := backups.List()
+			assert.NoError(t, err)
+			assert.Equal(t, []backup.Backup{created}, list)
+		}
+	})
+
+	t.Run("should keep the latest backups within the count", func(t *testing.T) {
+		backups, _, _ := newBackups(t, backup.Retention{Count: 2})
+		for i := 0; i < 4; i++ {
+			_, err := backups.Create()
+			assert.NoError(t, err)
+		}
+
+		list, err := backups.List()
+
+		if assert.NoError(t, err) && assert.Len(t, list, 2) {
+			assert.Equal(t, "20261019T030400Z", list[0].ID)
+			assert.Equal(t, "20261019T030300Z", list[1].ID)
+		}
+	})
+
+	t.Run("should keep the backups created within a second apart", func(t *testing.T) {
+		backups, _, dir := newBackups(t, backup.Retention{})
+		now := time.Date(2026, 10, 19, 3, 1, 0, 0, time.UTC)
+		backups.Clock = func() time.Time {
+			now = now.Add(250 * time.Millisecond)
+			return now
+		}
+		for i := 0; i < 4; i++ {
+			_, err := backups.Create()
+			assert.NoError(t, err)
+		}
+
+		list, err := backups.List()
+
+		if assert.NoError(t, err) && assert.Len(t, list, 4) {
+			assert.Equal(t, "20261019T030101Z", list[0].ID)
+			assert.Equal(t, "20261019T030100.75Z", list[1].ID)
+			assert.Equal(t, "20261019T030100.5Z", list[2].ID)
+			assert.Equal(t, "20261019T030100.25Z", list[3].ID)
+			checksum, _ := ioutil.ReadFile(filepath.Join(dir, "20261019T030100.5Z.json.gz.sha256"))
+			assert.Equal(t, list[2].Checksum+"  20261019T030100.5Z.json.gz\n", string(checksum))
+		}
+	})
+
+	t.Run("should not replace a backup created at the same time", func(t *testing.T) {
+		backups, persistentStore, dir := newBackups(t, backup.Retention{})
+		backups.Clock = func() time.Time { return time.Date(2026, 10, 19, 3, 1, 0, 0, time.UTC) }
+		created, err := backups.Create()
+		assert.NoError(t, err)
+		assert.NoError(t, persistentStore.UpsertBuilding(testutils.NewBuilding("building-one")))
+
+		_, err = backups.Create()
+
+		assert.EqualError(t, err, "backup 20261019T030100Z already exists")
+		list, err := backups.List()
+		assert.NoError(t, err)
+		assert.Equal(t, []backup.Backup{created}, list)
+		checksum, _ := ioutil.ReadFile(filepath.Join(dir, created.ID+".json.gz.sha256"))
+		assert.Equal(t, created.Checksum+"  20261019T030100Z.json.gz\n", string(checksum))
+	})
+
+	t.Run("should remove backups older than the age but the latest", func(t *testing.T) {
+		backups, _, _ := newBackups(t, backup.Retention{Age: 90 * time.Second})
+		for i := 0; i < 3; i++ {
+			_, err := backups.Create()
+			assert.NoError(t, err)
+		}
+
+		list, err := backups.List()
+
+		if assert.NoError(t, err) && assert.Len(t, list, 2) {
+			assert.Equal(t, "20261019T030300Z", list[0].ID)
+			assert.Equal(t, "20261019T030200Z", list[1].ID)
+		}
+	})
+}
+
+func TestBackups_Restore(t *testing.T) {
+	t.Run("should replace the store with the backup", func(t *testing.T) {
+		backups, persistentStore, _ := newBackups(t, backup.Retention{})
+		buildings, _ := testutils.NewBuildings("building-one")
+		assert.NoError(t, persistentStore.UpsertBuildings(buildings))
+		created, err := backups.Create()
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.NoError(t, persistentStore.UpsertBuildings(gateway.Buildings{}))
+
+		restored, err := backups.Restore(created.ID)
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, created, restored)
+			actual, err := persistentStore.Buildings()
+			assert.NoError(t, err)
+			assert.Equal(t, buildings, actual)
+		}
+	})
+
+	t.Run("should fail for corrupted backup", func(t *testing.T) {
+		backups, _, dir := newBackups(t, backup.Retention{})
+		created, err := backups.Create()
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, created.ID+".json.gz"), []byte("corrupted"), 0600))
+
+		_, err = backups.Restore(created.ID)
+
+		assert.EqualError(t, err, "checksum of backup 20261019T030100Z does not match, the backup is corrupted")
+	})
+
+	t.Run("should fail with not found for unknown backup", func(t *testing.T) {
+		backups, _, _ := newBackups(t, backup.Retention{})
+
+		_, err := backups.Restore("../dwarka")
+
+		assert.Equal(t, backup.NotFound("backup ../dwarka not found"), err)
+	})
+}
+
+func TestBackups_Run(t *testing.T) {
+	backups, _, _ := newBackups(t, backup.Retention{})
+	backups.Clock = func() time.Time {
+		return time.Date(2026, 10, 19, 3, 0, 59, 999000000, time.UTC)
+	}
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		backups.Run(cron.MustParse("* * * * *"), stop)
+		close(done)
+	}()
+
+	assert.Eventually(t, func() bool {
+		list, err := backups.List()
+		return err == nil && len(list) == 1
+	}, time.Second, 10*time.Millisecond)
+	close(stop)
+	<-done
+}
@@ -0,0 +1,211 @@
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// CAFile is the name of the certificate of the certificate authority
+	CAFile = "ca.pem"
+	// CAKeyFile is the name of the key of the certificate authority
+	CAKeyFile = "ca-key.pem"
+	// ServerFile is the name of the certificate of the server
+	ServerFile = "server.pem"
+	// ServerKeyFile is the name of the key of the server
+	ServerKeyFile = "server-key.pem"
+)
+
+// Authority is a self-signed certificate authority issuing the server
+// and the client certificates, it is meant for development only
+type Authority struct {
+	Certificate *x509.Certificate
+	Key         *ecdsa.PrivateKey
+}
+
+// Pair is a certificate along with its key encoded as PEM
+type Pair struct {
+	Certificate []byte
+	Key         []byte
+}
+
+// NewAuthority returns a certificate authority valid from now until ttl
+func NewAuthority(name string, now time.Time, ttl time.Duration) (Authority, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return Authority{}, err
+	}
+	template, err := newTemplate(name, now, ttl)
+	if err != nil {
+		return Authority{}, err
+	}
+	template.IsCA = true
+	template.BasicConstraintsValid = true
+	template.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return Authority{}, err
+	}
+	certificate, err := x509.ParseCertificate(der)
+	if err != nil {
+		return Authority{}, err
+	}
+	return Authority{Certificate: certificate, Key: key}, nil
+}
+
+// Pair returns the certificate and the key of the authority
+func (authority Authority) Pair() (Pair, error) {
+	key, err := x509.MarshalECPrivateKey(authority.Key)
+	if err != nil {
+		return Pair{}, err
+	}
+	return Pair{
+		Certificate: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: authority.Certificate.Raw}),
+		Key:         pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: key}),
+	}, nil
+}
+
+// IssueServer returns a server certificate for the hosts given as DNS
+// names or IP addresses
+func (authority Authority) IssueServer(hosts []string, now time.Time, ttl time.Duration) (Pair, error) {
+	if len(hosts) == 0 {
+		return Pair{}, errors.New("server certificate should have at least one host")
+	}
+
+	template, err := newTemplate(hosts[0], now, ttl)
+	if err != nil {
+		return Pair{}, err
+	}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+	template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+	return authority.issue(template)
+}
+
+// IssueClient returns a client certificate with the name as its common
+// name, the server maps the common name to the certificate tokens
+func (authority Authority) IssueClient(name string, now time.Time, ttl time.Duration) (Pair, error) {
+	if strings.TrimSpace(name) == "" {
+		return Pair{}, errors.New("name of the client should not be empty")
+	}
+
+	template, err := newTemplate(name, now, ttl)
+	if err != nil {
+		return Pair{}, err
+	}
+	template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+	return authority.issue(template)
+}
+
+func (authority Authority) issue(template *x509.Certificate) (Pair, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return Pair{}, err
+	}
+	template.KeyUsage = x509.KeyUsageDigitalSignature
+
+	der, err := x509.CreateCertificate(rand.Reader, template, authority.Certificate, &key.PublicKey, authority.Key)
+	if err != nil {
+		return Pair{}, err
+	}
+	encodedKey, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return Pair{}, err
+	}
+	return Pair{
+		Certificate: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		Key:         pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: encodedKey}),
+	}, nil
+}
+
+// Write writes the certificate and the key to the files, the key is
+// readable by the owner only
+func (pair Pair) Write(certificateFile, keyFile string) error {
+	err := os.WriteFile(certificateFile, pair.Certificate, 0644)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(keyFile, pair.Key, 0600)
+}
+
+// Generate writes a certificate authority along with a server
+// certificate for the hosts and a client certificate for every client
+// to the directory, it returns the names of the files written
+func Generate(dir string, hosts, clients []string, now time.Time, ttl time.Duration) ([]string, error) {
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return nil, err
+	}
+
+	authority, err := NewAuthority("dwarka development CA", now, ttl)
+	if err != nil {
+		return nil, err
+	}
+	pair, err := authority.Pair()
+	if err != nil {
+		return nil, err
+	}
+	files := []string{filepath.Join(dir, CAFile), filepath.Join(dir, CAKeyFile)}
+	err = pair.Write(files[0], files[1])
+	if err != nil {
+		return nil, err
+	}
+
+	pair, err = authority.IssueServer(hosts, now, ttl)
+	if err != nil {
+		return nil, err
+	}
+	files = append(files, filepath.Join(dir, ServerFile), filepath.Join(dir, ServerKeyFile))
+	err = pair.Write(files[2], files[3])
+	if err != nil {
+		return nil, err
+	}
+
+	for _, client := range clients {
+		pair, err = authority.IssueClient(client, now, ttl)
+		if err != nil {
+			return nil, err
+		}
+		certificateFile, keyFile := filepath.Join(dir, client+".pem"), filepath.Join(dir, client+"-key.pem")
+		err = pair.Write(certificateFile, keyFile)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, certificateFile, keyFile)
+	}
+	return files, nil
+}
+
+func newTemplate(name string, now time.Time, ttl time.Duration) (*x509.Certificate, error) {
+	if ttl <= 0 {
+		return nil, errors.New("ttl of a certificate should be greater than zero")
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, fmt.Errorf("unable to generate serial number, reason: %v", err)
+	}
+	return &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: name, Organization: []string{"dwarka"}},
+		NotBefore:    now.Add(-time.Minute).UTC(),
+		NotAfter:     now.Add(ttl).UTC(),
+	}, nil
+}
@@ -0,0 +1,65 @@
+package certs_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/certs"
+)
+
+func TestGenerate(t *testing.T) {
+	t.Run("should issue the certificates signed by the authority", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "certs")
+		now := time.Now()
+
+		files, err := certs.Generate(dir, []string{"localhost", "127.0.0.1"}, []string{"kitchen-panel"}, now, time.Hour)
+
+		assert.NoError(t, err)
+		assert.Len(t, files, 6)
+		info, err := os.Stat(filepath.Join(dir, certs.ServerKeyFile))
+		if assert.NoError(t, err) {
+			assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, certs.CAFile))
+		assert.NoError(t, err)
+		roots := x509.NewCertPool()
+		assert.True(t, roots.AppendCertsFromPEM(data))
+
+		server, err := tls.LoadX509KeyPair(filepath.Join(dir, certs.ServerFile), filepath.Join(dir, certs.ServerKeyFile))
+		if assert.NoError(t, err) {
+			certificate, err := x509.ParseCertificate(server.Certificate[0])
+			assert.NoError(t, err)
+			_, err = certificate.Verify(x509.VerifyOptions{DNSName: "localhost", Roots: roots})
+			assert.NoError(t, err)
+			_, err = certificate.Verify(x509.VerifyOptions{DNSName: "127.0.0.1", Roots: roots})
+			assert.NoError(t, err)
+		}
+
+		client, err := tls.LoadX509KeyPair(filepath.Join(dir, "kitchen-panel.pem"), filepath.Join(dir, "kitchen-panel-key.pem"))
+		if assert.NoError(t, err) {
+			certificate, err := x509.ParseCertificate(client.Certificate[0])
+			assert.NoError(t, err)
+			assert.Equal(t, "kitchen-panel", certificate.Subject.CommonName)
+			_, err = certificate.Verify(x509.VerifyOptions{Roots: roots, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}})
+			assert.NoError(t, err)
+		}
+	})
+
+	t.Run("should fail without hosts", func(t *testing.T) {
+		_, err := certs.Generate(t.TempDir(), nil, nil, time.Now(), time.Hour)
+
+		assert.EqualError(t, err, "server certificate should have at least one host")
+	})
+
+	t.Run("should fail without ttl", func(t *testing.T) {
+		_, err := certs.Generate(t.TempDir(), []string{"localhost"}, nil, time.Now(), 0)
+
+		assert.EqualError(t, err, "ttl of a certificate should be greater than zero")
+	})
+}
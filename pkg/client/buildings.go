@@ -0,0 +1,72 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"path"
+	"time"
+
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/view"
+)
+
+func buildingsRoute() string {
+	return "buildings"
+}
+
+func buildingRoute(building string) string {
+	return escape(buildingsRoute(), building)
+}
+
+// Buildings returns all the buildings ordered by id
+func (client *Client) Buildings(ctx context.Context) ([]view.Building, error) {
+	var buildings []view.Building
+	err := client.do(ctx, http.MethodGet, buildingsRoute(), nil, nil, &buildings)
+	return buildings, err
+}
+
+// Building returns the building with the id
+func (client *Client) Building(ctx context.Context, id string) (view.Building, error) {
+	building := view.Building{}
+	err := client.do(ctx, http.MethodGet, buildingRoute(id), nil, nil, &building)
+	return building, err
+}
+
+// Sun returns the civil dawn, the sunrise, the sunset and the civil dusk
+// of the date at the building
+func (client *Client) Sun(ctx context.Context, id string, date time.Time) (view.Sun, error) {
+	sun := view.Sun{}
+	query := url.Values{"date": []string{date.Format("2006-01-02")}}
+	err := client.do(ctx, http.MethodGet, path.Join(buildingRoute(id), "sun"), query, nil, &sun)
+	return sun, err
+}
+
+// Energy returns the energy consumed in the building over the period i.e.
+// day, week or month holding the date
+func (client *Client) Energy(ctx context.Context, id, period string, date time.Time) (view.Energy, error) {
+	energy := view.Energy{}
+	query := url.Values{"period": []string{period}, "date": []string{date.Format("2006-01-02")}}
+	err := client.do(ctx, http.MethodGet, path.Join(buildingRoute(id), "energy"), query, nil, &energy)
+	return energy, err
+}
+
+// CreateBuilding creates the building and returns its id
+func (client *Client) CreateBuilding(ctx context.Context, building view.Building) (string, error) {
+	return client.create(ctx, buildingsRoute(), building)
+}
+
+// UpdateBuilding replaces the building with the id
+func (client *Client) UpdateBuilding(ctx context.Context, id string, building view.Building) error {
+	return client.do(ctx, http.MethodPut, buildingRoute(id), nil, building, nil)
+}
+
+// DeleteBuilding deletes the building along with its floors and rooms
+func (client *Client) DeleteBuilding(ctx context.Context, id string) error {
+	return client.do(ctx, http.MethodDelete, buildingRoute(id), nil, nil, nil)
+}
+
+func (client *Client) create(ctx context.Context, route string, entity interface{}) (string, error) {
+	response := map[string]string{}
+	err := client.do(ctx, http.MethodPost, route, nil, entity, &response)
+	return response["id"], err
+}
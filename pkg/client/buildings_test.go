@@ -0,0 +1,166 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/view"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/client"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+	mockStore "gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/internal/mocks/store"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/telemetry"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/testutils"
+)
+
+func newBuilding() view.Building {
+	return view.Building{Name: "building-one", Latitude: 12.97, Longitude: 77.59}
+}
+
+func TestClient_Buildings(t *testing.T) {
+	ctx := context.Background()
+	buildings, building := testutils.NewBuildings("building-one")
+
+	t.Run("should list the buildings", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		store := mockStore.NewMockStore(ctrl)
+		store.EXPECT().Buildings().Return(buildings, nil)
+
+		actual, err := newClient(t, store).Buildings(ctx)
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, view.NewBuildings(buildings), actual)
+		}
+	})
+
+	t.Run("should get the solar events of the date at the building", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		store := mockStore.NewMockStore(ctrl)
+		store.EXPECT().Buildings().Return(buildings, nil)
+		date := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+
+		actual, err := newClient(t, store).Sun(ctx, "building-one", date)
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, view.NewSun(building, date), actual)
+		}
+	})
+
+	t.Run("should get the energy consumed over the period at the building", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		store := mockStore.NewMockStore(ctrl)
+		store.EXPECT().Buildings().Return(buildings, nil)
+		store.EXPECT().Tree().Return(gateway.NewTree(), nil)
+		db, err := telemetry.Open(filepath.Join(t.TempDir(), "telemetry.db"), telemetry.DefaultRetention)
+		if err != nil {
+			t.Fatal(err)
+		}
+		api.EnableTelemetry(db)
+		defer func() {
+			api.EnableTelemetry(nil)
+			_ = db.Close()
+		}()
+		date := time.Date(2026, 10, 19, 0, 0, 0, 0, time.Local)
+
+		actual, err := newClient(t, store).Energy(ctx, "building-one", "week", date)
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, "week", actual.Period)
+			assert.True(t, date.Equal(actual.From))
+			assert.Empty(t, actual.Usage)
+		}
+	})
+
+	t.Run("should get the building", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		store := mockStore.NewMockStore(ctrl)
+		store.EXPECT().Buildings().Return(buildings, nil)
+
+		actual, err := newClient(t, store).Building(ctx, "building-one")
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, view.NewBuilding(building), actual)
+		}
+	})
+
+	t.Run("should fail with not found for missing building", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		store := mockStore.NewMockStore(ctrl)
+		store.EXPECT().Buildings().Return(gateway.Buildings{}, nil)
+
+		_, err := newClient(t, store).Building(ctx, "building-one")
+
+		assert.True(t, errors.Is(err, client.ErrNotFound))
+		assert.EqualError(t, err, "buildings/building-one not found")
+	})
+
+	t.Run("should create the building", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		store := mockStore.NewMockStore(ctrl)
+		store.EXPECT().Buildings().Return(gateway.Buildings{}, nil)
+		store.EXPECT().UpsertBuildings(gateway.Buildings{"building-one": newBuilding().Building()}).Return(nil)
+
+		id, err := newClient(t, store).CreateBuilding(ctx, newBuilding())
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, "building-one", id)
+		}
+	})
+
+	t.Run("should fail with conflict for existing building", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		store := mockStore.NewMockStore(ctrl)
+		store.EXPECT().Buildings().Return(buildings, nil)
+
+		_, err := newClient(t, store).CreateBuilding(ctx, newBuilding())
+
+		assert.True(t, errors.Is(err, client.ErrConflict))
+	})
+
+	t.Run("should fail with bad request for invalid building", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		store := mockStore.NewMockStore(ctrl)
+
+		_, err := newClient(t, store).CreateBuilding(ctx, view.Building{Name: "one", Latitude: 1, Longitude: 1})
+
+		assert.True(t, errors.Is(err, client.ErrBadRequest))
+		assert.EqualError(t, err, "name: the length must be between 5 and 50.")
+	})
+
+	t.Run("should update the building", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		store := mockStore.NewMockStore(ctrl)
+		store.EXPECT().Buildings().Return(buildings, nil)
+		store.EXPECT().UpsertBuilding(newBuilding().Building()).Return(nil)
+
+		err := newClient(t, store).UpdateBuilding(ctx, "building-one", newBuilding())
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("should delete the building", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		store := mockStore.NewMockStore(ctrl)
+		store.EXPECT().Buildings().Return(buildings, nil)
+		store.EXPECT().DeleteBuilding(building).Return(nil)
+
+		err := newClient(t, store).DeleteBuilding(ctx, "building-one")
+
+		assert.NoError(t, err)
+	})
+}
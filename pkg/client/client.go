@@ -0,0 +1,184 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+)
+
+const (
+	version = "v1"
+
+	defaultTimeout = 30 * time.Second
+	defaultBackoff = 200 * time.Millisecond
+)
+
+// Client talks to the REST API of a running dwarka server, the
+// requests which fail because the server is unreachable or unavailable
+// are retried when they are safe to repeat
+type Client struct {
+	server     string
+	token      string
+	httpClient *http.Client
+	retries    int
+	backoff    time.Duration
+}
+
+// Option customises the Client created by New
+type Option func(*Client)
+
+// WithToken sends the token as bearer authorization with every request
+func WithToken(token string) Option {
+	return func(client *Client) {
+		client.token = token
+	}
+}
+
+// WithHTTPClient uses the given http.Client to send the requests
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(client *Client) {
+		client.httpClient = httpClient
+	}
+}
+
+// WithRetries retries the failed GET, PUT and DELETE requests up to
+// retries times, waiting backoff multiplied by the attempt in between
+func WithRetries(retries int, backoff time.Duration) Option {
+	return func(client *Client) {
+		client.retries = retries
+		client.backoff = backoff
+	}
+}
+
+// New returns a Client for the server e.g. http://localhost:1410, by
+// default failed requests are retried twice
+func New(server string, options ...Option) *Client {
+	client := &Client{
+		server:     strings.TrimSuffix(server, "/"),
+		httpClient: &http.Client{Timeout: defaultTimeout},
+		retries:    2,
+		backoff:    defaultBackoff,
+	}
+	for _, option := range options {
+		option(client)
+	}
+	return client
+}
+
+// Ping returns the status of the server along with its start time
+func (client *Client) Ping(ctx context.Context) (gateway.Status, error) {
+	response := map[string]gateway.Status{}
+	err := client.do(ctx, http.MethodGet, "ping", nil, nil, &response)
+	return response["status"], err
+}
+
+// do sends the request to the versioned route and decodes the JSON
+// response into out when out is not nil
+func (client *Client) do(ctx context.Context, method, route string, query url.Values, body, out interface{}) error {
+	var data []byte
+	if body != nil {
+		var err error
+		data, err = json.Marshal(body)
+		if err != nil {
+			return err
+		}
+	}
+
+	target := client.server + path.Join("/", version, route)
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	for attempt := 1; ; attempt++ {
+		status, response, err := client.send(ctx, method, target, data)
+		if attempt > client.retries || !retryable(method, status, err) {
+			if err != nil {
+				return err
+			}
+			return decode(status, route, response, out)
+		}
+
+		timer := time.NewTimer(client.backoff * time.Duration(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (client *Client) send(ctx context.Context, method, target string, data []byte) (int, []byte, error) {
+	request, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(data))
+	if err != nil {
+		return 0, nil, err
+	}
+	if data != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	if client.token != "" {
+		request.Header.Set("Authorization", "Bearer "+client.token)
+	}
+
+	response, err := client.httpClient.Do(request)
+	if err != nil {
+		if ctx.Err() != nil {
+			return 0, nil, ctx.Err()
+		}
+		return 0, nil, fmt.Errorf("unable to reach server %s, %w", client.server, err)
+	}
+	defer response.Body.Close()
+
+	body, err := ioutil.ReadAll(response.Body)
+	return response.StatusCode, body, err
+}
+
+// retryable reports whether the request is worth repeating, only the
+// idempotent requests are repeated when the server is unreachable or
+// responds that it is unavailable for the moment
+func retryable(method string, status int, err error) bool {
+	switch method {
+	case http.MethodGet, http.MethodPut, http.MethodDelete:
+	default:
+		return false
+	}
+
+	if err != nil {
+		return err != context.Canceled && err != context.DeadlineExceeded
+	}
+
+	switch status {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+func decode(status int, route string, data []byte, out interface{}) error {
+	if status >= http.StatusBadRequest {
+		return newError(status, route, data)
+	}
+
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
+
+func escape(segments ...string) string {
+	escaped := make([]string, 0, len(segments))
+	for _, segment := range segments {
+		escaped = append(escaped, url.PathEscape(segment))
+	}
+	return path.Join(escaped...)
+}
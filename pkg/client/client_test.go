@@ -0,0 +1,157 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttputil"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/client"
+	mockStore "gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/internal/mocks/store"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/testutils"
+)
+
+// newClient returns a client of the api served over an in-memory listener
+func newClient(t *testing.T, store *mockStore.MockStore, options ...client.Option) *client.Client {
+	httpClient, closeServer := testutils.ServeInmemory(store)
+	t.Cleanup(closeServer)
+
+	options = append([]client.Option{client.WithHTTPClient(httpClient), client.WithRetries(0, 0)}, options...)
+	return client.New("http://test", options...)
+}
+
+// newHandlerClient returns a client of the handler served over an in-memory listener
+func newHandlerClient(t *testing.T, handler fasthttp.RequestHandler, options ...client.Option) *client.Client {
+	ln := fasthttputil.NewInmemoryListener()
+	t.Cleanup(func() {
+		_ = ln.Close()
+	})
+	go func() {
+		_ = fasthttp.Serve(ln, handler)
+	}()
+
+	httpClient := &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+				return ln.Dial()
+			},
+		},
+	}
+	options = append([]client.Option{client.WithHTTPClient(httpClient)}, options...)
+	return client.New("http://test/", options...)
+}
+
+func TestClient_Ping(t *testing.T) {
+	t.Run("should return the status of the server", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		store := mockStore.NewMockStore(ctrl)
+		store.EXPECT().Uptime().Return(testutils.Uptime(), nil)
+
+		status, err := newClient(t, store).Ping(context.Background())
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, testutils.Uptime(), status)
+		}
+	})
+
+	t.Run("should return the error reported by the server", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		store := mockStore.NewMockStore(ctrl)
+		store.EXPECT().Uptime().Return(nil, errors.New("store failed"))
+
+		_, err := newClient(t, store).Ping(context.Background())
+
+		assert.EqualError(t, err, "there was problem when reading value from store, reason: store failed")
+		assert.Equal(t, fasthttp.StatusInternalServerError, err.(client.Error).StatusCode)
+	})
+}
+
+func TestClient_Retries(t *testing.T) {
+	t.Run("should retry the request while the server is unavailable", func(t *testing.T) {
+		attempts := 0
+		c := newHandlerClient(t, func(ctx *fasthttp.RequestCtx) {
+			attempts++
+			if attempts < 3 {
+				ctx.SetStatusCode(fasthttp.StatusServiceUnavailable)
+				return
+			}
+			ctx.SetBodyString(`{"status": {"startTime": "now"}}`)
+		}, client.WithRetries(2, time.Millisecond))
+
+		status, err := c.Ping(context.Background())
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, "now", status["startTime"])
+			assert.Equal(t, 3, attempts)
+		}
+	})
+
+	t.Run("should give up after the retries", func(t *testing.T) {
+		attempts := 0
+		c := newHandlerClient(t, func(ctx *fasthttp.RequestCtx) {
+			attempts++
+			ctx.SetStatusCode(fasthttp.StatusBadGateway)
+		}, client.WithRetries(1, time.Millisecond))
+
+		_, err := c.Ping(context.Background())
+
+		assert.EqualError(t, err, "server responded with 502 bad gateway")
+		assert.Equal(t, 2, attempts)
+	})
+
+	t.Run("should not retry the request creating entity", func(t *testing.T) {
+		attempts := 0
+		c := newHandlerClient(t, func(ctx *fasthttp.RequestCtx) {
+			attempts++
+			ctx.SetStatusCode(fasthttp.StatusServiceUnavailable)
+		}, client.WithRetries(2, time.Millisecond))
+
+		_, err := c.CreateBuilding(context.Background(), newBuilding())
+
+		assert.Error(t, err)
+		assert.Equal(t, 1, attempts)
+	})
+
+	t.Run("should stop retrying when the context is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		c := newHandlerClient(t, func(requestCtx *fasthttp.RequestCtx) {
+			cancel()
+			requestCtx.SetStatusCode(fasthttp.StatusServiceUnavailable)
+		}, client.WithRetries(2, time.Minute))
+
+		_, err := c.Ping(ctx)
+
+		assert.Equal(t, context.Canceled, err)
+	})
+}
+
+func TestClient_Token(t *testing.T) {
+	authorization := ""
+	c := newHandlerClient(t, func(ctx *fasthttp.RequestCtx) {
+		authorization = string(ctx.Request.Header.Peek("Authorization"))
+		ctx.SetBodyString(`{}`)
+	}, client.WithToken("secret"))
+
+	_, err := c.Ping(context.Background())
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "Bearer secret", authorization)
+	}
+}
+
+func TestError_Is(t *testing.T) {
+	err := error(client.Error{StatusCode: http.StatusNotFound, Route: "buildings/one"})
+
+	assert.True(t, errors.Is(err, client.ErrNotFound))
+	assert.False(t, errors.Is(err, client.ErrConflict))
+	assert.EqualError(t, err, "buildings/one not found")
+	assert.EqualError(t, client.Error{StatusCode: http.StatusBadRequest, Message: "name: cannot be blank."}, "name: cannot be blank.")
+}
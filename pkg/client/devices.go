@@ -0,0 +1,56 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"path"
+
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/view"
+)
+
+func devicesRoute(building, floor, room string) string {
+	return path.Join(roomRoute(building, floor, room), "devices")
+}
+
+func deviceRoute(building, floor, room, device string) string {
+	return path.Join(devicesRoute(building, floor, room), escape(device))
+}
+
+// Devices returns the devices of the room ordered by id
+func (client *Client) Devices(ctx context.Context, building, floor, room string) ([]view.Device, error) {
+	var devices []view.Device
+	err := client.do(ctx, http.MethodGet, devicesRoute(building, floor, room), nil, nil, &devices)
+	return devices, err
+}
+
+// Device returns the device with the id from the room
+func (client *Client) Device(ctx context.Context, building, floor, room, id string) (view.Device, error) {
+	device := view.Device{}
+	err := client.do(ctx, http.MethodGet, deviceRoute(building, floor, room, id), nil, nil, &device)
+	return device, err
+}
+
+// CreateDevice creates the device in the room and returns its id
+func (client *Client) CreateDevice(ctx context.Context, building, floor, room string, device view.Device) (string, error) {
+	return client.create(ctx, devicesRoute(building, floor, room), device)
+}
+
+// UpdateDevice replaces the device with the id in the room
+func (client *Client) UpdateDevice(ctx context.Context, building, floor, room, id string, device view.Device) error {
+	return client.do(ctx, http.MethodPut, deviceRoute(building, floor, room, id), nil, device, nil)
+}
+
+// DeleteDevice deletes the device with the id from the room
+func (client *Client) DeleteDevice(ctx context.Context, building, floor, room, id string) error {
+	return client.do(ctx, http.MethodDelete, deviceRoute(building, floor, room, id), nil, nil, nil)
+}
+
+// SwitchOn switches on the device through the node controlling it
+func (client *Client) SwitchOn(ctx context.Context, building, floor, room, id string) error {
+	return client.do(ctx, http.MethodPost, path.Join(deviceRoute(building, floor, room, id), "on"), nil, nil, nil)
+}
+
+// SwitchOff switches off the device through the node controlling it
+func (client *Client) SwitchOff(ctx context.Context, building, floor, room, id string) error {
+	return client.do(ctx, http.MethodPost, path.Join(deviceRoute(building, floor, room, id), "off"), nil, nil, nil)
+}
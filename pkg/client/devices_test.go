@@ -0,0 +1,69 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/view"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/client"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+	mockStore "gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/internal/mocks/store"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/testutils"
+)
+
+func expectRoom(store *mockStore.MockStore) gateway.Room {
+	rooms, room := testutils.NewRooms("room-one")
+	expectFloor(store)
+	store.EXPECT().Rooms(room.Floor).Return(rooms, nil)
+	return room
+}
+
+func TestClient_Devices(t *testing.T) {
+	ctx := context.Background()
+	devices, device := testutils.NewDevices("porch-light")
+
+	t.Run("should list the devices of the room", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		store := mockStore.NewMockStore(ctrl)
+		room := expectRoom(store)
+		store.EXPECT().Devices(room).Return(devices, nil)
+
+		actual, err := newClient(t, store).Devices(ctx, "building-one", "floor-one", "room-one")
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, view.NewDevices(devices), actual)
+		}
+	})
+
+	t.Run("should get the device", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		store := mockStore.NewMockStore(ctrl)
+		room := expectRoom(store)
+		store.EXPECT().Devices(room).Return(devices, nil)
+
+		actual, err := newClient(t, store).Device(ctx, "building-one", "floor-one", "room-one", "porch-light")
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, view.NewDevice(device), actual)
+		}
+	})
+
+	t.Run("should fail with conflict when switching device without node", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		store := mockStore.NewMockStore(ctrl)
+		room := expectRoom(store)
+		store.EXPECT().Devices(room).Return(devices, nil)
+		store.EXPECT().Nodes(room).Return(gateway.Nodes{}, nil)
+
+		err := newClient(t, store).SwitchOn(ctx, "building-one", "floor-one", "room-one", "porch-light")
+
+		assert.True(t, errors.Is(err, client.ErrConflict))
+		assert.EqualError(t, err, "device porch-light is not controlled by any node")
+	})
+}
@@ -0,0 +1,66 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+var (
+	// ErrBadRequest matches the errors of requests rejected by the server
+	// e.g. because the entity is invalid
+	ErrBadRequest = Error{StatusCode: http.StatusBadRequest}
+	// ErrUnauthorized matches the errors of requests without valid
+	// credentials when the server requires authentication
+	ErrUnauthorized = Error{StatusCode: http.StatusUnauthorized}
+	// ErrForbidden matches the errors of requests which are not allowed
+	// by the role or the scopes of the token
+	ErrForbidden = Error{StatusCode: http.StatusForbidden}
+	// ErrNotFound matches the errors of requests for missing entities
+	ErrNotFound = Error{StatusCode: http.StatusNotFound}
+	// ErrConflict matches the errors of requests creating an entity
+	// which already exists
+	ErrConflict = Error{StatusCode: http.StatusConflict}
+)
+
+// Error is returned when the server responds with an error status, use
+// errors.Is with ErrBadRequest, ErrUnauthorized, ErrForbidden, ErrNotFound or ErrConflict to check
+// the kind of the error
+type Error struct {
+	StatusCode int
+	Route      string
+	Message    string
+}
+
+func (err Error) Error() string {
+	if err.Message != "" {
+		return err.Message
+	}
+
+	switch err.StatusCode {
+	case http.StatusNotFound:
+		if err.Route != "" {
+			return fmt.Sprintf("%s not found", err.Route)
+		}
+	case http.StatusConflict:
+		if err.Route != "" {
+			return fmt.Sprintf("%s already exists", err.Route)
+		}
+	}
+	return fmt.Sprintf("server responded with %d %s", err.StatusCode, strings.ToLower(http.StatusText(err.StatusCode)))
+}
+
+// Is reports whether the target is an Error with the same status code
+func (err Error) Is(target error) bool {
+	other, ok := target.(Error)
+	return ok && other.StatusCode == err.StatusCode
+}
+
+func newError(status int, route string, data []byte) error {
+	body := map[string]string{}
+	if json.Unmarshal(data, &body) != nil {
+		body = map[string]string{}
+	}
+	return Error{StatusCode: status, Route: route, Message: body["error"]}
+}
@@ -0,0 +1,45 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/view"
+)
+
+func floorsRoute(building string) string {
+	return escape(buildingsRoute(), building, "floors")
+}
+
+func floorRoute(building, floor string) string {
+	return escape(buildingsRoute(), building, "floors", floor)
+}
+
+// Floors returns the floors of the building ordered by id
+func (client *Client) Floors(ctx context.Context, building string) ([]view.Floor, error) {
+	var floors []view.Floor
+	err := client.do(ctx, http.MethodGet, floorsRoute(building), nil, nil, &floors)
+	return floors, err
+}
+
+// Floor returns the floor with the id from the building
+func (client *Client) Floor(ctx context.Context, building, id string) (view.Floor, error) {
+	floor := view.Floor{}
+	err := client.do(ctx, http.MethodGet, floorRoute(building, id), nil, nil, &floor)
+	return floor, err
+}
+
+// CreateFloor creates the floor in the building and returns its id
+func (client *Client) CreateFloor(ctx context.Context, building string, floor view.Floor) (string, error) {
+	return client.create(ctx, floorsRoute(building), floor)
+}
+
+// UpdateFloor replaces the floor with the id in the building
+func (client *Client) UpdateFloor(ctx context.Context, building, id string, floor view.Floor) error {
+	return client.do(ctx, http.MethodPut, floorRoute(building, id), nil, floor, nil)
+}
+
+// DeleteFloor deletes the floor along with its rooms
+func (client *Client) DeleteFloor(ctx context.Context, building, id string) error {
+	return client.do(ctx, http.MethodDelete, floorRoute(building, id), nil, nil, nil)
+}
@@ -0,0 +1,61 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/view"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/client"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+	mockStore "gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/internal/mocks/store"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/testutils"
+)
+
+func TestClient_Floors(t *testing.T) {
+	ctx := context.Background()
+	buildings, building := testutils.NewBuildings("building-one")
+	floors, floor := testutils.NewFloors("floor-one")
+
+	t.Run("should list the floors of the building", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		store := mockStore.NewMockStore(ctrl)
+		store.EXPECT().Buildings().Return(buildings, nil)
+		store.EXPECT().Floors(building).Return(floors, nil)
+
+		actual, err := newClient(t, store).Floors(ctx, "building-one")
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, view.NewFloors(floors), actual)
+		}
+	})
+
+	t.Run("should create the floor in the building", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		store := mockStore.NewMockStore(ctrl)
+		store.EXPECT().Buildings().Return(buildings, nil)
+		store.EXPECT().Floors(building).Return(gateway.Floors{}, nil)
+		store.EXPECT().UpsertFloors(building, gomock.Any()).Return(nil)
+
+		id, err := newClient(t, store).CreateFloor(ctx, "building-one", view.Floor{Name: "floor-one", Level: 1})
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, "floor-one", id)
+		}
+	})
+
+	t.Run("should fail with not found for missing building", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		store := mockStore.NewMockStore(ctrl)
+		store.EXPECT().Buildings().Return(gateway.Buildings{}, nil)
+
+		_, err := newClient(t, store).Floor(ctx, "building-one", floor.ID())
+
+		assert.True(t, errors.Is(err, client.ErrNotFound))
+	})
+}
@@ -0,0 +1,63 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"path"
+
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/view"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+)
+
+func groupsRoute(building string) string {
+	return path.Join(buildingRoute(building), "groups")
+}
+
+func groupRoute(building, group string) string {
+	return path.Join(groupsRoute(building), escape(group))
+}
+
+// Groups returns the device groups of the building ordered by id
+func (client *Client) Groups(ctx context.Context, building string) ([]view.Group, error) {
+	var groups []view.Group
+	err := client.do(ctx, http.MethodGet, groupsRoute(building), nil, nil, &groups)
+	return groups, err
+}
+
+// Group returns the device group with the id from the building
+func (client *Client) Group(ctx context.Context, building, id string) (view.Group, error) {
+	group := view.Group{}
+	err := client.do(ctx, http.MethodGet, groupRoute(building, id), nil, nil, &group)
+	return group, err
+}
+
+// CreateGroup creates the device group in the building and returns its id
+func (client *Client) CreateGroup(ctx context.Context, building string, group view.Group) (string, error) {
+	return client.create(ctx, groupsRoute(building), group)
+}
+
+// UpdateGroup replaces the device group with the id in the building
+func (client *Client) UpdateGroup(ctx context.Context, building, id string, group view.Group) error {
+	return client.do(ctx, http.MethodPut, groupRoute(building, id), nil, group, nil)
+}
+
+// DeleteGroup deletes the device group with the id from the building
+func (client *Client) DeleteGroup(ctx context.Context, building, id string) error {
+	return client.do(ctx, http.MethodDelete, groupRoute(building, id), nil, nil, nil)
+}
+
+// GroupMembers returns the devices of the group
+func (client *Client) GroupMembers(ctx context.Context, building, id string) (view.Members, error) {
+	members := view.Members{}
+	err := client.do(ctx, http.MethodGet, path.Join(groupRoute(building, id), "members"), nil, nil, &members)
+	return members, err
+}
+
+// SwitchGroup switches the devices of the group to the state and returns
+// the outcome of every device, the command is returned even when some of
+// the devices fail to switch
+func (client *Client) SwitchGroup(ctx context.Context, building, id string, state gateway.State) (view.Command, error) {
+	command := view.Command{}
+	err := client.do(ctx, http.MethodPost, path.Join(groupRoute(building, id), string(state)), nil, nil, &command)
+	return command, err
+}
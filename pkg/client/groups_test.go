@@ -0,0 +1,53 @@
+package client_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/view"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+	mockStore "gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/internal/mocks/store"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/testutils"
+)
+
+func TestClient_Groups(t *testing.T) {
+	ctx := context.Background()
+	buildings, building := testutils.NewBuildings("building-one")
+	group := gateway.Group{
+		Building:       building,
+		Devices:        []string{"floor-one/room-one/porch-light"},
+		PhysicalEntity: gateway.PhysicalEntity{Name: "exterior lights"},
+	}
+
+	t.Run("should list the groups of the building", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		store := mockStore.NewMockStore(ctrl)
+		store.EXPECT().Buildings().Return(buildings, nil)
+		store.EXPECT().Groups(building).Return(gateway.Groups{group.ID(): group}, nil)
+
+		actual, err := newClient(t, store).Groups(ctx, "building-one")
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, []view.Group{view.NewGroup(group)}, actual)
+		}
+	})
+
+	t.Run("should return the command when a device fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		store := mockStore.NewMockStore(ctrl)
+		store.EXPECT().Buildings().Return(buildings, nil)
+		store.EXPECT().Groups(building).Return(gateway.Groups{group.ID(): group}, nil)
+		store.EXPECT().Tree().Return(gateway.NewTree(), nil)
+
+		command, err := newClient(t, store).SwitchGroup(ctx, "building-one", "exterior-lights", gateway.StateOff)
+
+		if assert.NoError(t, err) && assert.Len(t, command.Results, 1) {
+			assert.Equal(t, 1, command.Failed)
+			assert.Equal(t, "floor floor-one not found", command.Results[0].Error)
+		}
+	})
+}
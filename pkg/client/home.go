@@ -0,0 +1,70 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/view"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/home"
+)
+
+// TreeOptions limits the tree returned by the server, zero values
+// return every level and every field
+type TreeOptions struct {
+	// Depth is one of view.DepthBuildings, view.DepthFloors,
+	// view.DepthRooms or view.DepthDevices
+	Depth  int
+	Fields []string
+}
+
+func (options TreeOptions) query() url.Values {
+	query := url.Values{}
+	if options.Depth > 0 {
+		query.Set("depth", strconv.Itoa(options.Depth))
+	}
+	if len(options.Fields) > 0 {
+		query.Set("fields", strings.Join(options.Fields, ","))
+	}
+	return query
+}
+
+// Tree returns all the buildings along with their floors, rooms and devices
+func (client *Client) Tree(ctx context.Context, options TreeOptions) ([]view.BuildingTree, error) {
+	var tree []view.BuildingTree
+	err := client.do(ctx, http.MethodGet, "tree", options.query(), nil, &tree)
+	return tree, err
+}
+
+// BuildingTree returns the building along with its floors, rooms and devices
+func (client *Client) BuildingTree(ctx context.Context, id string, options TreeOptions) (view.BuildingTree, error) {
+	tree := view.BuildingTree{}
+	err := client.do(ctx, http.MethodGet, path.Join(buildingRoute(id), "tree"), options.query(), nil, &tree)
+	return tree, err
+}
+
+// Import persists the home and returns the changes made to the store,
+// with dryRun the changes are only reported
+func (client *Client) Import(ctx context.Context, h home.Home, mode home.Mode, dryRun bool) (home.Plan, error) {
+	query := url.Values{}
+	if mode != "" {
+		query.Set("mode", string(mode))
+	}
+	if dryRun {
+		query.Set("dry-run", "true")
+	}
+
+	plan := home.Plan{}
+	err := client.do(ctx, http.MethodPost, "import", query, h, &plan)
+	return plan, err
+}
+
+// Export returns all the buildings, floors, rooms and devices
+func (client *Client) Export(ctx context.Context) (home.Home, error) {
+	exported := home.Home{}
+	err := client.do(ctx, http.MethodGet, "export", nil, nil, &exported)
+	return exported, err
+}
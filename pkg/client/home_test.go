@@ -0,0 +1,62 @@
+package client_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/view"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/client"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/home"
+	mockStore "gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/internal/mocks/store"
+)
+
+func newTree() gateway.Tree {
+	tree := gateway.NewTree()
+	building := newBuilding().Building()
+	tree.Buildings[building.ID()] = building
+	return tree
+}
+
+func TestClient_Tree(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	store := mockStore.NewMockStore(ctrl)
+	store.EXPECT().Tree().Return(newTree(), nil)
+
+	tree, err := newClient(t, store).Tree(context.Background(), client.TreeOptions{Depth: view.DepthBuildings, Fields: []string{"id", "name"}})
+
+	if assert.NoError(t, err) && assert.Len(t, tree, 1) {
+		assert.Equal(t, view.Building{ID: "building-one", Name: "building-one"}, tree[0].Building)
+	}
+}
+
+func TestClient_Import(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	store := mockStore.NewMockStore(ctrl)
+	store.EXPECT().Tree().Return(gateway.NewTree(), nil)
+
+	plan, err := newClient(t, store).Import(context.Background(), home.NewHome(newTree()), home.ModeReplace, true)
+
+	if assert.NoError(t, err) {
+		assert.True(t, plan.DryRun)
+		assert.Equal(t, home.ModeReplace, plan.Mode)
+		assert.Equal(t, 1, plan.Count(home.ActionCreate))
+	}
+}
+
+func TestClient_Export(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	store := mockStore.NewMockStore(ctrl)
+	store.EXPECT().Tree().Return(newTree(), nil)
+
+	exported, err := newClient(t, store).Export(context.Background())
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, home.NewHome(newTree()), exported)
+	}
+}
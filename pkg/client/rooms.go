@@ -0,0 +1,45 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/view"
+)
+
+func roomsRoute(building, floor string) string {
+	return escape(buildingsRoute(), building, "floors", floor, "rooms")
+}
+
+func roomRoute(building, floor, room string) string {
+	return escape(buildingsRoute(), building, "floors", floor, "rooms", room)
+}
+
+// Rooms returns the rooms of the floor ordered by id
+func (client *Client) Rooms(ctx context.Context, building, floor string) ([]view.Room, error) {
+	var rooms []view.Room
+	err := client.do(ctx, http.MethodGet, roomsRoute(building, floor), nil, nil, &rooms)
+	return rooms, err
+}
+
+// Room returns the room with the id from the floor
+func (client *Client) Room(ctx context.Context, building, floor, id string) (view.Room, error) {
+	room := view.Room{}
+	err := client.do(ctx, http.MethodGet, roomRoute(building, floor, id), nil, nil, &room)
+	return room, err
+}
+
+// CreateRoom creates the room in the floor and returns its id
+func (client *Client) CreateRoom(ctx context.Context, building, floor string, room view.Room) (string, error) {
+	return client.create(ctx, roomsRoute(building, floor), room)
+}
+
+// UpdateRoom replaces the room with the id in the floor
+func (client *Client) UpdateRoom(ctx context.Context, building, floor, id string, room view.Room) error {
+	return client.do(ctx, http.MethodPut, roomRoute(building, floor, id), nil, room, nil)
+}
+
+// DeleteRoom deletes the room along with its devices
+func (client *Client) DeleteRoom(ctx context.Context, building, floor, id string) error {
+	return client.do(ctx, http.MethodDelete, roomRoute(building, floor, id), nil, nil, nil)
+}
@@ -0,0 +1,51 @@
+package client_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/view"
+	mockStore "gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/internal/mocks/store"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/testutils"
+)
+
+func expectFloor(store *mockStore.MockStore) {
+	buildings, building := testutils.NewBuildings("building-one")
+	floors, _ := testutils.NewFloors("floor-one")
+	store.EXPECT().Buildings().Return(buildings, nil)
+	store.EXPECT().Floors(building).Return(floors, nil)
+}
+
+func TestClient_Rooms(t *testing.T) {
+	ctx := context.Background()
+	rooms, room := testutils.NewRooms("room-one")
+
+	t.Run("should list the rooms of the floor", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		store := mockStore.NewMockStore(ctrl)
+		expectFloor(store)
+		store.EXPECT().Rooms(room.Floor).Return(rooms, nil)
+
+		actual, err := newClient(t, store).Rooms(ctx, "building-one", "floor-one")
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, view.NewRooms(rooms), actual)
+		}
+	})
+
+	t.Run("should delete the room", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		store := mockStore.NewMockStore(ctrl)
+		expectFloor(store)
+		store.EXPECT().Rooms(room.Floor).Return(rooms, nil)
+		store.EXPECT().DeleteRoom(room).Return(nil)
+
+		err := newClient(t, store).DeleteRoom(ctx, "building-one", "floor-one", "room-one")
+
+		assert.NoError(t, err)
+	})
+}
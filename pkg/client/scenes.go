@@ -0,0 +1,61 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"path"
+
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/view"
+)
+
+func scenesRoute(building string) string {
+	return path.Join(buildingRoute(building), "scenes")
+}
+
+func sceneRoute(building, scene string) string {
+	return path.Join(scenesRoute(building), escape(scene))
+}
+
+// Scenes returns the scenes of the building ordered by id
+func (client *Client) Scenes(ctx context.Context, building string) ([]view.Scene, error) {
+	var scenes []view.Scene
+	err := client.do(ctx, http.MethodGet, scenesRoute(building), nil, nil, &scenes)
+	return scenes, err
+}
+
+// Scene returns the scene with the id from the building
+func (client *Client) Scene(ctx context.Context, building, id string) (view.Scene, error) {
+	scene := view.Scene{}
+	err := client.do(ctx, http.MethodGet, sceneRoute(building, id), nil, nil, &scene)
+	return scene, err
+}
+
+// CreateScene creates the scene in the building and returns its id
+func (client *Client) CreateScene(ctx context.Context, building string, scene view.Scene) (string, error) {
+	return client.create(ctx, scenesRoute(building), scene)
+}
+
+// CaptureScene creates the scene from the reported state of the devices
+// and returns its id
+func (client *Client) CaptureScene(ctx context.Context, building string, capture view.Capture) (string, error) {
+	return client.create(ctx, path.Join(scenesRoute(building), "capture"), capture)
+}
+
+// UpdateScene replaces the scene with the id in the building
+func (client *Client) UpdateScene(ctx context.Context, building, id string, scene view.Scene) error {
+	return client.do(ctx, http.MethodPut, sceneRoute(building, id), nil, scene, nil)
+}
+
+// DeleteScene deletes the scene with the id from the building
+func (client *Client) DeleteScene(ctx context.Context, building, id string) error {
+	return client.do(ctx, http.MethodDelete, sceneRoute(building, id), nil, nil, nil)
+}
+
+// ActivateScene switches the devices of the scene and returns the outcome
+// of every device, the activation is returned even when some of the
+// devices fail to switch
+func (client *Client) ActivateScene(ctx context.Context, building, id string) (view.Activation, error) {
+	activation := view.Activation{}
+	err := client.do(ctx, http.MethodPost, path.Join(sceneRoute(building, id), "activate"), nil, nil, &activation)
+	return activation, err
+}
@@ -0,0 +1,67 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/api/view"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/client"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+	mockStore "gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/internal/mocks/store"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/testutils"
+)
+
+func TestClient_Scenes(t *testing.T) {
+	ctx := context.Background()
+	buildings, building := testutils.NewBuildings("building-one")
+	scene := gateway.Scene{
+		Building:       building,
+		Targets:        []gateway.Target{{Floor: "floor-one", Room: "room-one", Device: "porch-light", State: gateway.StateOff}},
+		PhysicalEntity: gateway.PhysicalEntity{Name: "movie-mode"},
+	}
+
+	t.Run("should list the scenes of the building", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		store := mockStore.NewMockStore(ctrl)
+		store.EXPECT().Buildings().Return(buildings, nil)
+		store.EXPECT().Scenes(building).Return(gateway.Scenes{scene.ID(): scene}, nil)
+
+		actual, err := newClient(t, store).Scenes(ctx, "building-one")
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, []view.Scene{view.NewScene(scene)}, actual)
+		}
+	})
+
+	t.Run("should fail with conflict when creating an existing scene", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		store := mockStore.NewMockStore(ctrl)
+		store.EXPECT().Buildings().Return(buildings, nil)
+		store.EXPECT().Scenes(building).Return(gateway.Scenes{scene.ID(): scene}, nil)
+
+		_, err := newClient(t, store).CreateScene(ctx, "building-one", view.NewScene(scene))
+
+		assert.True(t, errors.Is(err, client.ErrConflict))
+	})
+
+	t.Run("should return the activation when a device fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		store := mockStore.NewMockStore(ctrl)
+		store.EXPECT().Buildings().Return(buildings, nil)
+		store.EXPECT().Scenes(building).Return(gateway.Scenes{scene.ID(): scene}, nil)
+		store.EXPECT().Tree().Return(gateway.NewTree(), nil)
+
+		activation, err := newClient(t, store).ActivateScene(ctx, "building-one", "movie-mode")
+
+		if assert.NoError(t, err) && assert.Len(t, activation.Results, 1) {
+			assert.Equal(t, view.Failed, activation.Results[0].Outcome)
+			assert.Equal(t, "floor floor-one not found", activation.Results[0].Error)
+		}
+	})
+}
@@ -0,0 +1,210 @@
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule represents a cron expression with the fields minute, hour,
+// day of month, month and day of week e.g. "30 3 * * 1-5"
+type Schedule struct {
+	expression string
+	minutes    uint64
+	hours      uint64
+	days       uint64
+	months     uint64
+	weekdays   uint64
+	// anyDay and anyWeekday are tracked since the day matches either of
+	// day of month and day of week when both of them are restricted
+	anyDay     bool
+	anyWeekday bool
+}
+
+type field struct {
+	name  string
+	min   int
+	max   int
+	names map[string]int
+}
+
+var (
+	minuteField  = field{name: "minute", min: 0, max: 59}
+	hourField    = field{name: "hour", min: 0, max: 23}
+	dayField     = field{name: "day of month", min: 1, max: 31}
+	monthField   = field{name: "month", min: 1, max: 12, names: names("jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec")}
+	weekdayField = field{name: "day of week", min: 0, max: 7, names: names("sun", "mon", "tue", "wed", "thu", "fri", "sat")}
+)
+
+var shortcuts = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// searchLimit bounds the search of the next time, every valid expression
+// matches at least once in a leap cycle
+const searchLimit = 5 * 366 * 24 * 60
+
+// Parse parses the cron expression of five fields, the fields accept
+// *, numbers, ranges e.g. 1-5, steps e.g. */15 and lists e.g. 1,15 along
+// with the names of months and weekdays. The shortcuts @hourly, @daily,
+// @weekly, @monthly and @yearly are supported as well
+func Parse(expression string) (Schedule, error) {
+	expanded := strings.TrimSpace(expression)
+	if shortcut, ok := shortcuts[strings.ToLower(expanded)]; ok {
+		expanded = shortcut
+	}
+
+	fields := strings.Fields(expanded)
+	if len(fields) != 5 {
+		return Schedule{}, fmt.Errorf("cron expression '%s' should have 5 fields, got %d", expression, len(fields))
+	}
+
+	schedule := Schedule{expression: expression}
+	var err error
+	parsed := []*uint64{&schedule.minutes, &schedule.hours, &schedule.days, &schedule.months, &schedule.weekdays}
+	for i, f := range []field{minuteField, hourField, dayField, monthField, weekdayField} {
+		*parsed[i], err = f.parse(fields[i])
+		if err != nil {
+			return Schedule{}, fmt.Errorf("cron expression '%s': %v", expression, err)
+		}
+	}
+
+	// sunday is both 0 and 7
+	if schedule.weekdays&(1<<7) != 0 {
+		schedule.weekdays |= 1
+	}
+	schedule.anyDay = fields[2] == "*"
+	schedule.anyWeekday = fields[4] == "*"
+	return schedule, nil
+}
+
+// MustParse is like Parse but panics when the expression is invalid
+func MustParse(expression string) Schedule {
+	schedule, err := Parse(expression)
+	if err != nil {
+		panic(err)
+	}
+	return schedule
+}
+
+// String returns the expression the schedule was parsed from
+func (schedule Schedule) String() string {
+	return schedule.expression
+}
+
+// Next returns the first time after the given time matching the
+// schedule in the location of the given time, zero time is returned
+// when nothing matches e.g. 30th of February
+func (schedule Schedule) Next(after time.Time) time.Time {
+	next := after.Truncate(time.Minute).Add(time.Minute)
+	for i := 0; i < searchLimit; i++ {
+		switch {
+		case !has(schedule.months, int(next.Month())):
+			next = time.Date(next.Year(), next.Month()+1, 1, 0, 0, 0, 0, next.Location())
+		case !schedule.matchesDay(next):
+			next = time.Date(next.Year(), next.Month(), next.Day()+1, 0, 0, 0, 0, next.Location())
+		case !has(schedule.hours, next.Hour()):
+			next = time.Date(next.Year(), next.Month(), next.Day(), next.Hour()+1, 0, 0, 0, next.Location())
+		case !has(schedule.minutes, next.Minute()):
+			next = next.Add(time.Minute)
+		default:
+			return next
+		}
+	}
+	return time.Time{}
+}
+
+func (schedule Schedule) matchesDay(t time.Time) bool {
+	day := has(schedule.days, t.Day())
+	weekday := has(schedule.weekdays, int(t.Weekday()))
+	if schedule.anyDay || schedule.anyWeekday {
+		return day && weekday
+	}
+	return day || weekday
+}
+
+func (f field) parse(expression string) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(expression, ",") {
+		value, err := f.parsePart(part)
+		if err != nil {
+			return 0, err
+		}
+		bits |= value
+	}
+	return bits, nil
+}
+
+func (f field) parsePart(part string) (uint64, error) {
+	rangePart, step := part, 1
+	if i := strings.Index(part, "/"); i >= 0 {
+		value, err := strconv.Atoi(part[i+1:])
+		if err != nil || value < 1 {
+			return 0, fmt.Errorf("invalid step in %s '%s'", f.name, part)
+		}
+		rangePart, step = part[:i], value
+	}
+
+	start, end := f.min, f.max
+	if rangePart != "*" {
+		bounds := strings.SplitN(rangePart, "-", 2)
+		var err error
+		start, err = f.value(bounds[0])
+		if err != nil {
+			return 0, err
+		}
+		end = start
+		if len(bounds) == 2 {
+			end, err = f.value(bounds[1])
+			if err != nil {
+				return 0, err
+			}
+		} else if step > 1 {
+			end = f.max
+		}
+		if start > end {
+			return 0, fmt.Errorf("invalid range in %s '%s'", f.name, part)
+		}
+	}
+
+	var bits uint64
+	for value := start; value <= end; value += step {
+		bits |= 1 << uint(value)
+	}
+	return bits, nil
+}
+
+func (f field) value(text string) (int, error) {
+	if value, ok := f.names[strings.ToLower(text)]; ok {
+		return value, nil
+	}
+
+	value, err := strconv.Atoi(text)
+	if err != nil || value < f.min || value > f.max {
+		return 0, fmt.Errorf("%s should be between %d and %d, got '%s'", f.name, f.min, f.max, text)
+	}
+	return value, nil
+}
+
+func has(bits uint64, value int) bool {
+	return bits&(1<<uint(value)) != 0
+}
+
+func names(values ...string) map[string]int {
+	result := map[string]int{}
+	offset := 0
+	if len(values) == 12 {
+		offset = 1
+	}
+	for i, value := range values {
+		result[value] = i + offset
+	}
+	return result
+}
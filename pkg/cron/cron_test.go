@@ -0,0 +1,77 @@
+package cron_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/cron"
+)
+
+func at(value string) time.Time {
+	t, err := time.Parse("2006-01-02 15:04", value)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestParse(t *testing.T) {
+	t.Run("should fail for invalid expressions", func(t *testing.T) {
+		for expression, message := range map[string]string{
+			"* * * *":       "cron expression '* * * *' should have 5 fields, got 4",
+			"60 * * * *":    "cron expression '60 * * * *': minute should be between 0 and 59, got '60'",
+			"* * * * 1-x":   "cron expression '* * * * 1-x': day of week should be between 0 and 7, got 'x'",
+			"*/0 * * * *":   "cron expression '*/0 * * * *': invalid step in minute '*/0'",
+			"* 5-1 * * *":   "cron expression '* 5-1 * * *': invalid range in hour '5-1'",
+			"* * 0 * *":     "cron expression '* * 0 * *': day of month should be between 1 and 31, got '0'",
+			"@fortnightly":  "cron expression '@fortnightly' should have 5 fields, got 1",
+			"* * * foo * *": "cron expression '* * * foo * *' should have 5 fields, got 6",
+		} {
+			_, err := cron.Parse(expression)
+
+			assert.EqualError(t, err, message)
+		}
+	})
+}
+
+func TestSchedule_Next(t *testing.T) {
+	for _, test := range []struct {
+		expression string
+		after      string
+		expected   string
+	}{
+		{"* * * * *", "2026-10-19 03:15", "2026-10-19 03:16"},
+		{"30 3 * * *", "2026-10-19 03:15", "2026-10-19 03:30"},
+		{"30 3 * * *", "2026-10-19 03:30", "2026-10-20 03:30"},
+		{"*/15 * * * *", "2026-10-19 03:16", "2026-10-19 03:30"},
+		{"0 9-17/4 * * *", "2026-10-19 14:00", "2026-10-19 17:00"},
+		{"0 0 * * mon-fri", "2026-10-23 12:00", "2026-10-26 00:00"},
+		{"0 0 * * 7", "2026-10-19 00:00", "2026-10-25 00:00"},
+		{"0 0 1 jan *", "2026-10-19 00:00", "2027-01-01 00:00"},
+		{"0 0 29 2 *", "2026-10-19 00:00", "2028-02-29 00:00"},
+		{"0 0 13 * fri", "2026-10-19 00:00", "2026-10-23 00:00"},
+		{"@daily", "2026-10-19 03:15", "2026-10-20 00:00"},
+		{"@hourly", "2026-10-19 03:15", "2026-10-19 04:00"},
+	} {
+		t.Run(test.expression+" after "+test.after, func(t *testing.T) {
+			schedule, err := cron.Parse(test.expression)
+
+			if assert.NoError(t, err) {
+				assert.Equal(t, at(test.expected), schedule.Next(at(test.after)))
+			}
+		})
+	}
+
+	t.Run("should return zero time when nothing matches", func(t *testing.T) {
+		assert.True(t, cron.MustParse("0 0 30 2 *").Next(at("2026-10-19 00:00")).IsZero())
+	})
+
+	t.Run("should match in the location of the time", func(t *testing.T) {
+		kolkata := time.FixedZone("IST", 5*60*60+30*60)
+
+		next := cron.MustParse("0 6 * * *").Next(time.Date(2026, 10, 19, 7, 0, 0, 0, kolkata))
+
+		assert.Equal(t, time.Date(2026, 10, 20, 6, 0, 0, 0, kolkata), next)
+	})
+}
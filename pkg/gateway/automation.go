@@ -0,0 +1,282 @@
+package gateway
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v3"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/solar"
+)
+
+const (
+	// TriggerState fires when a device of the building reports a state
+	TriggerState = "state"
+	// TriggerMQTT fires when a message is published on the topic
+	TriggerMQTT = "mqtt"
+	// TriggerTime fires every day at the time of the day
+	TriggerTime = "time"
+	// TriggerSun fires every day at a solar event of the building e.g. the
+	// sunset
+	TriggerSun = "sun"
+
+	// ConditionState holds when the device is in the state
+	ConditionState = "state"
+	// ConditionTime holds between the times of the day
+	ConditionTime = "time"
+	// ConditionOccupancy holds when the room is occupied, or vacant
+	ConditionOccupancy = "occupancy"
+
+	// ActionDevice switches a device
+	ActionDevice = "device"
+	// ActionScene activates a scene of the building
+	ActionScene = "scene"
+	// ActionWebhook posts the run of the automation to an url
+	ActionWebhook = "webhook"
+)
+
+// Trigger starts the automation. Device is given as floor-id/room-id/device-id
+// and the state trigger fires on any state unless To is given, From limits
+// it to the changes from the state. The mqtt trigger matches Topic which
+// supports the wildcards + and #, and Payload when given. At is given as
+// 15:04 or as a solar moment of the building e.g. sunset-15m, Event is one
+// of the solar events and Offset moves it e.g. -15m
+type Trigger struct {
+	Type    string `json:"type"`
+	Device  string `json:"device,omitempty"`
+	From    string `json:"from,omitempty"`
+	To      string `json:"to,omitempty"`
+	Topic   string `json:"topic,omitempty"`
+	Payload string `json:"payload,omitempty"`
+	At      string `json:"at,omitempty"`
+	Event   string `json:"event,omitempty"`
+	Offset  string `json:"offset,omitempty"`
+}
+
+// Condition limits the runs of the automation. After and Before of the
+// time condition are given as 15:04 or as solar moments e.g. dusk, the
+// window wraps around midnight when After is later than Before e.g.
+// sunset to sunrise+30m.
+// Room of the occupancy condition is given as floor-id/room-id
+type Condition struct {
+	Type     string `json:"type"`
+	Device   string `json:"device,omitempty"`
+	State    string `json:"state,omitempty"`
+	After    string `json:"after,omitempty"`
+	Before   string `json:"before,omitempty"`
+	Room     string `json:"room,omitempty"`
+	Occupied bool   `json:"occupied,omitempty"`
+}
+
+// Action is run in order when the automation is triggered and its
+// conditions hold, Device is given as floor-id/room-id/device-id
+type Action struct {
+	Type   string `json:"type"`
+	Device string `json:"device,omitempty"`
+	State  State  `json:"state,omitempty"`
+	Scene  string `json:"scene,omitempty"`
+	URL    string `json:"url,omitempty"`
+}
+
+// Automation runs the actions when any of the triggers fires while all
+// of the conditions hold e.g. switch on the porch light when the front
+// door opens after sunset
+type Automation struct {
+	Building   Entity      `json:"-"`
+	Disabled   bool        `json:"disabled,omitempty"`
+	Triggers   []Trigger   `json:"triggers"`
+	Conditions []Condition `json:"conditions,omitempty"`
+	Actions    []Action    `json:"actions"`
+	PhysicalEntity
+}
+
+// Validate validates whether automation has all the necessary fields
+func (automation Automation) Validate() error {
+	err := validation.ValidateStruct(&automation,
+		validation.Field(&automation.Name, validation.Required, validation.Length(5, 50)),
+		validation.Field(&automation.Labels, validation.By(validateLabels)),
+		validation.Field(&automation.Triggers, validation.Required),
+		validation.Field(&automation.Actions, validation.Required),
+	)
+	if err != nil {
+		return err
+	}
+
+	for i, trigger := range automation.Triggers {
+		if err := trigger.validate(); err != nil {
+			return fmt.Errorf("trigger %d: %v", i+1, err)
+		}
+	}
+	for i, condition := range automation.Conditions {
+		if err := condition.validate(); err != nil {
+			return fmt.Errorf("condition %d: %v", i+1, err)
+		}
+	}
+	for i, action := range automation.Actions {
+		if err := action.validate(); err != nil {
+			return fmt.Errorf("action %d: %v", i+1, err)
+		}
+	}
+	return nil
+}
+
+func (trigger Trigger) validate() error {
+	switch trigger.Type {
+	case TriggerState:
+		return validatePath("device", trigger.Device, 3)
+	case TriggerMQTT:
+		if trigger.Topic == "" {
+			return fmt.Errorf("topic is required")
+		}
+		return nil
+	case TriggerTime:
+		return validateTime(trigger.At)
+	case TriggerSun:
+		_, err := trigger.Moment()
+		return err
+	default:
+		return fmt.Errorf("type %s not supported, expected state, mqtt, time or sun", trigger.Type)
+	}
+}
+
+// Moment returns the solar moment the sun trigger fires at, the event
+// moved by the offset
+func (trigger Trigger) Moment() (solar.Moment, error) {
+	moment, err := solar.ParseMoment(trigger.Event)
+	if err != nil {
+		return solar.Moment{}, err
+	}
+	if trigger.Offset == "" {
+		return moment, nil
+	}
+	offset, err := time.ParseDuration(trigger.Offset)
+	if err != nil {
+		return solar.Moment{}, fmt.Errorf("offset %s should be a duration e.g. -15m", trigger.Offset)
+	}
+	moment.Offset += offset
+	return moment, nil
+}
+
+func (condition Condition) validate() error {
+	switch condition.Type {
+	case ConditionState:
+		if condition.State == "" {
+			return fmt.Errorf("state is required")
+		}
+		return validatePath("device", condition.Device, 3)
+	case ConditionTime:
+		if condition.After == "" && condition.Before == "" {
+			return fmt.Errorf("after or before is required")
+		}
+		for _, value := range []string{condition.After, condition.Before} {
+			if value == "" {
+				continue
+			}
+			if err := validateTime(value); err != nil {
+				return err
+			}
+		}
+		return nil
+	case ConditionOccupancy:
+		return validatePath("room", condition.Room, 2)
+	default:
+		return fmt.Errorf("type %s not supported, expected state, time or occupancy", condition.Type)
+	}
+}
+
+func (action Action) validate() error {
+	switch action.Type {
+	case ActionDevice:
+		if _, err := NewState(string(action.State)); err != nil {
+			return err
+		}
+		return validatePath("device", action.Device, 3)
+	case ActionScene:
+		if action.Scene == "" {
+			return fmt.Errorf("scene is required")
+		}
+		return nil
+	case ActionWebhook:
+		target, err := url.Parse(action.URL)
+		if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+			return fmt.Errorf("url %s should be an absolute http or https url", action.URL)
+		}
+		return nil
+	default:
+		return fmt.Errorf("type %s not supported, expected device, scene or webhook", action.Type)
+	}
+}
+
+// validatePath checks that the path has the ids of the entities e.g.
+// floor-id/room-id/device-id
+func validatePath(name, value string, ids int) error {
+	expected := []string{"floor-id", "room-id", "device-id"}[:ids]
+	parts := strings.Split(value, "/")
+	if len(parts) != ids {
+		return fmt.Errorf("%s %s should be given as %s", name, value, strings.Join(expected, "/"))
+	}
+	for _, part := range parts {
+		if part == "" {
+			return fmt.Errorf("%s %s should be given as %s", name, value, strings.Join(expected, "/"))
+		}
+	}
+	return nil
+}
+
+// ParseTimeOfDay parses the time of the day given as 15:04 and returns
+// the duration since midnight
+func ParseTimeOfDay(value string) (time.Duration, error) {
+	parsed, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("time %s should be given as hh:mm e.g. 18:30", value)
+	}
+	return time.Duration(parsed.Hour())*time.Hour + time.Duration(parsed.Minute())*time.Minute, nil
+}
+
+// validateTime checks that the time is given as 15:04 or as a solar moment
+func validateTime(value string) error {
+	if _, err := ParseTimeOfDay(value); err == nil {
+		return nil
+	}
+	if _, err := solar.ParseMoment(value); err == nil {
+		return nil
+	}
+	return fmt.Errorf("time %s should be given as hh:mm e.g. 18:30 or as a solar moment e.g. sunset-15m", value)
+}
+
+// NewAutomation returns an Automation from []byte
+func NewAutomation(building Building, data []byte) (Automation, error) {
+	automation := Automation{Building: building}
+	err := json.Unmarshal(data, &automation)
+	if err != nil {
+		return Automation{}, fmt.Errorf("unable to parse automation, %w", err)
+	}
+
+	err = automation.Validate()
+	if err != nil {
+		return automation, err
+	}
+
+	return automation, nil
+}
+
+// Automations represents map string, Automation
+type Automations map[string]Automation
+
+// NewAutomations returns list of Automations from []byte
+func NewAutomations(building Entity, data []byte) (Automations, error) {
+	automations := Automations{}
+	err := json.Unmarshal(data, &automations)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse automations, %w", err)
+	}
+
+	result := Automations{}
+	for _, automation := range automations {
+		automation.Building = building
+		result[automation.ID()] = automation
+	}
+	return result, nil
+}
@@ -0,0 +1,58 @@
+package gateway_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/solar"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/testutils"
+)
+
+func newAutomation(triggers []gateway.Trigger, conditions []gateway.Condition, actions ...gateway.Action) gateway.Automation {
+	return gateway.Automation{
+		Building:       testutils.NewBuilding("building-one"),
+		Triggers:       triggers,
+		Conditions:     conditions,
+		Actions:        actions,
+		PhysicalEntity: gateway.PhysicalEntity{Name: "welcome home"},
+	}
+}
+
+func TestNewAutomation(t *testing.T) {
+	doorOpens := []gateway.Trigger{{Type: gateway.TriggerState, Device: "floor-one/hall/front-door", To: "open"}}
+	porchLightOn := gateway.Action{Type: gateway.ActionDevice, Device: "floor-one/porch/porch-light", State: gateway.StateOn}
+
+	t.Run("should return automation associated to a building", func(t *testing.T) {
+		building := testutils.NewBuilding("building-one")
+		automation := newAutomation(doorOpens, []gateway.Condition{{Type: gateway.ConditionTime, After: solar.Sunset, Before: "23:30"}}, porchLightOn)
+		data, _ := json.Marshal(automation)
+
+		actual, err := gateway.NewAutomation(building, data)
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, "welcome-home", actual.ID())
+			if !cmp.Equal(automation, actual) {
+				assert.Fail(t, cmp.Diff(automation, actual))
+			}
+		}
+	})
+
+	t.Run("should validate the triggers, the conditions and the actions", func(t *testing.T) {
+		for message, automation := range map[string]gateway.Automation{
+			"triggers: cannot be blank.": newAutomation(nil, nil, porchLightOn),
+			"actions: cannot be blank.":  newAutomation(doorOpens, nil),
+			"trigger 1: device front-door should be given as floor-id/room-id/device-id":                       newAutomation([]gateway.Trigger{{Type: gateway.TriggerState, Device: "front-door"}}, nil, porchLightOn),
+			"trigger 1: offset 15 should be a duration e.g. -15m":                                              newAutomation([]gateway.Trigger{{Type: gateway.TriggerSun, Event: solar.Sunset, Offset: "15"}}, nil, porchLightOn),
+			"trigger 1: type motion not supported, expected state, mqtt, time or sun":                          newAutomation([]gateway.Trigger{{Type: "motion"}}, nil, porchLightOn),
+			"condition 1: time 25:00 should be given as hh:mm e.g. 18:30 or as a solar moment e.g. sunset-15m": newAutomation(doorOpens, []gateway.Condition{{Type: gateway.ConditionTime, After: "25:00"}}, porchLightOn),
+			"condition 1: room hall should be given as floor-id/room-id":                                       newAutomation(doorOpens, []gateway.Condition{{Type: gateway.ConditionOccupancy, Room: "hall"}}, porchLightOn),
+			"action 1: state dim not supported, expected on or off":                                            newAutomation(doorOpens, nil, gateway.Action{Type: gateway.ActionDevice, Device: "floor-one/porch/porch-light", State: "dim"}),
+			"action 1: type notify not supported, expected device, scene or webhook":                           newAutomation(doorOpens, nil, gateway.Action{Type: "notify"}),
+		} {
+			assert.EqualError(t, automation.Validate(), message)
+		}
+	})
+}
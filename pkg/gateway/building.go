@@ -2,26 +2,89 @@ package gateway
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"regexp"
+	"time"
+
 	validation "github.com/go-ozzo/ozzo-validation/v3"
 )
 
-// Building  a structure with a roof and walls, such as a house or factory
+var currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// Building  a structure with a roof and walls, such as a house or factory,
+// Timezone is the IANA time zone of the schedules e.g. Asia/Kolkata and
+// Tariff is the price of the energy consumed in the building
 type Building struct {
-	Lat float64 `json:"lat"`
-	Lan float64 `json:"lan"`
+	Lat      float64 `json:"lat"`
+	Lan      float64 `json:"lan"`
+	Timezone string  `json:"timezone,omitempty"`
+	Tariff   *Tariff `json:"tariff,omitempty"`
 	PhysicalEntity
 }
 
+// Tariff is the price of a kWh in the Currency given as an ISO 4217 code
+// e.g. INR
+type Tariff struct {
+	Currency string  `json:"currency"`
+	Rate     float64 `json:"rate"`
+}
+
+// Validate validates whether tariff has a currency and a rate which is
+// not negative
+func (tariff Tariff) Validate() error {
+	return validation.ValidateStruct(&tariff,
+		validation.Field(&tariff.Currency, validation.Required, validation.By(validateCurrency)),
+		validation.Field(&tariff.Rate, validation.Min(0.0)),
+	)
+}
+
+func validateCurrency(value interface{}) error {
+	currency, _ := value.(string)
+	if !currencyPattern.MatchString(currency) {
+		return errors.New("should be an ISO 4217 code e.g. INR")
+	}
+	return nil
+}
+
+// Cost returns the price of the energy in kWh
+func (tariff Tariff) Cost(kWh float64) float64 {
+	return kWh * tariff.Rate
+}
+
 // Validate validates whether building has all the necessary fields
 func (building Building) Validate() error {
 	return validation.ValidateStruct(&building,
 		validation.Field(&building.Name, validation.Required, validation.Length(5, 50)),
+		validation.Field(&building.Labels, validation.By(validateLabels)),
 		validation.Field(&building.Lat, validation.Required),
 		validation.Field(&building.Lan, validation.Required),
+		validation.Field(&building.Timezone, validation.By(validateTimezone)),
+		validation.Field(&building.Tariff),
 	)
 }
 
+func validateTimezone(value interface{}) error {
+	timezone, _ := value.(string)
+	if _, err := time.LoadLocation(timezone); err != nil {
+		return fmt.Errorf("unknown time zone %s, expected an IANA time zone e.g. Asia/Kolkata", timezone)
+	}
+	return nil
+}
+
+// Location returns the time zone of the building, the fallback when the
+// building has none
+func (building Building) Location(fallback *time.Location) *time.Location {
+	if building.Timezone == "" {
+		return fallback
+	}
+	location, err := time.LoadLocation(building.Timezone)
+	if err != nil {
+		return fallback
+	}
+	return location
+}
+
 // NewBuilding returns a Building from []byte
 func NewBuilding(data []byte) (Building, error) {
 	building := Building{}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"github.com/stretchr/testify/assert"
 	"testing"
+	"time"
 )
 
 func TestBuilding_Validate(t *testing.T) {
@@ -52,6 +53,46 @@ func TestBuilding_Validate(t *testing.T) {
 			assert.Equal(t, "name: the length must be between 5 and 50.", err.Error())
 		}
 	})
+
+	t.Run("should error if time zone is unknown", func(t *testing.T) {
+		building := Building{
+			Lat:      1.2,
+			Lan:      1.4,
+			Timezone: "Asia/Bengaluru",
+			PhysicalEntity: PhysicalEntity{
+				Name: "building one",
+			},
+		}
+
+		err := building.Validate()
+
+		if assert.Error(t, err) {
+			assert.Equal(t, "timezone: unknown time zone Asia/Bengaluru, expected an IANA time zone e.g. Asia/Kolkata.", err.Error())
+		}
+		building.Timezone = "Asia/Kolkata"
+		assert.NoError(t, building.Validate())
+		assert.Equal(t, "Asia/Kolkata", building.Location(time.UTC).String())
+	})
+
+	t.Run("should error if tariff has no currency or a negative rate", func(t *testing.T) {
+		building := Building{
+			Lat:    1.2,
+			Lan:    1.4,
+			Tariff: &Tariff{Currency: "rupee", Rate: -1},
+			PhysicalEntity: PhysicalEntity{
+				Name: "building one",
+			},
+		}
+
+		err := building.Validate()
+
+		if assert.Error(t, err) {
+			assert.Equal(t, "tariff: (currency: should be an ISO 4217 code e.g. INR; rate: must be no less than 0.).", err.Error())
+		}
+		building.Tariff = &Tariff{Currency: "INR", Rate: 7.5}
+		assert.NoError(t, building.Validate())
+		assert.Equal(t, 15.0, building.Tariff.Cost(2))
+	})
 }
 
 func TestNewBuilding(t *testing.T) {
@@ -0,0 +1,58 @@
+package gateway
+
+import (
+	"encoding/json"
+	"fmt"
+	validation "github.com/go-ozzo/ozzo-validation/v3"
+)
+
+// Device is a electronic / electrical equipment made or adapted for a particular purpose
+type Device struct {
+	Room           Room              `json:"-"`
+	Meta           map[string]string `json:"meta"`
+	PhysicalEntity `json:"entity"`
+}
+
+// Validate validates whether device has all the necessary fields
+func (device Device) Validate() error {
+	return validation.ValidateStruct(&device,
+		validation.Field(&device.Name, validation.Required, validation.Length(5, 50)),
+		validation.Field(&device.Labels, validation.By(validateLabels)),
+	)
+}
+
+// NewDevice returns a Device from []byte
+func NewDevice(room Room, data []byte) (Device, error) {
+	device := Device{Room: room}
+	err := json.Unmarshal(data, &device)
+	if err != nil {
+		return Device{}, fmt.Errorf("unable to parse device, %w", err)
+	}
+
+	err = device.Validate()
+	if err != nil {
+		return device, err
+	}
+
+	return device, nil
+}
+
+// Devices represents map string, Device
+type Devices map[string]Device
+
+// NewDevices returns list of Devices from []byte
+func NewDevices(room Room, data []byte) (Devices, error) {
+	devices := Devices{}
+	err := json.Unmarshal(data, &devices)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse devices, %w", err)
+	}
+
+	result := Devices{}
+
+	for _, device := range devices {
+		device.Room = room
+		result[device.ID()] = device
+	}
+	return result, nil
+}
@@ -0,0 +1,65 @@
+package gateway_test
+
+import (
+	"encoding/json"
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/testutils"
+	"testing"
+)
+
+func TestNewDevice(t *testing.T) {
+	t.Run("should return device associated to a room", func(t *testing.T) {
+		room := testutils.NewRoom("room-one")
+		device := gateway.Device{
+			Room: room,
+			Meta: map[string]string{"type": "light"},
+			PhysicalEntity: gateway.PhysicalEntity{
+				Name:        "porch light",
+				Description: "for test",
+			},
+		}
+
+		data, _ := json.Marshal(device)
+
+		actual, err := gateway.NewDevice(room, data)
+
+		if assert.NoError(t, err) {
+			if !cmp.Equal(device, actual) {
+				assert.Fail(t, cmp.Diff(device, actual))
+			}
+		}
+	})
+
+	t.Run("should return validation error", func(t *testing.T) {
+		_, err := gateway.NewDevice(testutils.NewRoom("room-one"), []byte(`{"entity": {"name": "fan"}}`))
+
+		if assert.Error(t, err) {
+			assert.Equal(t, "name: the length must be between 5 and 50.", err.Error())
+		}
+	})
+}
+
+func TestNewDevices(t *testing.T) {
+	t.Run("should return devices associated to a room", func(t *testing.T) {
+		room := testutils.NewRoom("room-one")
+		devices := gateway.Devices{"porch-light": gateway.Device{
+			Room: room,
+			PhysicalEntity: gateway.PhysicalEntity{
+				Name:        "porch light",
+				Description: "for test",
+			},
+		}}
+
+		data, _ := json.Marshal(devices)
+
+		actual, err := gateway.NewDevices(room, data)
+
+		if assert.NoError(t, err) {
+			if !cmp.Equal(devices, actual) {
+				assert.Fail(t, cmp.Diff(devices, actual))
+			}
+		}
+	})
+}
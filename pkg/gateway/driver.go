@@ -0,0 +1,109 @@
+package gateway
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/metrics"
+)
+
+// Driver returns the Node used to control the devices of the node
+// described by the metadata
+type Driver func(metadata NodeMetadata) (Node, error)
+
+// DriverNotFound is returned when no driver is registered for the node type
+type DriverNotFound string
+
+// Error returns the underlying error as string
+func (err DriverNotFound) Error() string {
+	return string(err)
+}
+
+var (
+	driversMu sync.RWMutex
+	drivers   = map[NodeType]Driver{}
+
+	nodeCommands = metrics.Default.NewCounter("dwarka_node_commands_total",
+		"Commands sent to the nodes by node type, command and result i.e. success or failure", "type", "command", "result")
+)
+
+// RegisterDriver registers the driver for the node type, registering
+// again for the same node type replaces the driver
+func RegisterDriver(nodeType NodeType, driver Driver) {
+	driversMu.Lock()
+	defer driversMu.Unlock()
+	drivers[nodeType] = driver
+}
+
+// NewNodeFor returns the Node for the metadata using the driver
+// registered for its node type
+func NewNodeFor(metadata NodeMetadata) (Node, error) {
+	driversMu.RLock()
+	driver, ok := drivers[metadata.Type]
+	driversMu.RUnlock()
+	if !ok {
+		return nil, DriverNotFound(fmt.Sprintf("no driver registered for node type %s", metadata.Type.NodeType()))
+	}
+	node, err := driver(metadata)
+	if err != nil {
+		return nil, err
+	}
+	counted := countedNode{Node: node, nodeType: metadata.Type.NodeType()}
+	if reporter, ok := node.(Reporter); ok {
+		return countedReporter{countedNode: counted, Reporter: reporter}, nil
+	}
+	return counted, nil
+}
+
+// countedNode counts the commands sent to the node in the metrics
+type countedNode struct {
+	Node
+	nodeType string
+}
+
+// On switches on the device counting the command
+func (node countedNode) On(device Device) error {
+	return node.count("on", node.Node.On(device))
+}
+
+// Off switches off the device counting the command
+func (node countedNode) Off(device Device) error {
+	return node.count("off", node.Node.Off(device))
+}
+
+func (node countedNode) count(command string, err error) error {
+	result := "success"
+	if err != nil {
+		result = "failure"
+	}
+	nodeCommands.Inc(node.nodeType, command, result)
+	return err
+}
+
+// countedReporter is a countedNode which is able to report the state of
+// its devices
+type countedReporter struct {
+	countedNode
+	Reporter
+}
+
+// NodeOf returns the node which controls the device, when more than
+// one node controls the device the node with the lowest id is returned
+func NodeOf(nodes Nodes, device Device) (NodeMetadata, bool) {
+	ids := make([]string, 0, len(nodes))
+	for id := range nodes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, nodeID := range ids {
+		node := nodes[nodeID]
+		for _, id := range node.Devices {
+			if id == device.ID() {
+				return node, true
+			}
+		}
+	}
+	return NodeMetadata{}, false
+}
@@ -0,0 +1,72 @@
+package gateway_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/metrics"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/testutils"
+	"strings"
+	"testing"
+)
+
+func TestNewNodeFor(t *testing.T) {
+	t.Run("should fail when no driver is registered", func(t *testing.T) {
+		_, err := gateway.NewNodeFor(gateway.NodeMetadata{Type: gateway.NodeTypeWifi})
+
+		assert.Equal(t, gateway.DriverNotFound("no driver registered for node type wifi"), err)
+	})
+
+	t.Run("should use the driver registered for the node type", func(t *testing.T) {
+		var actual gateway.NodeMetadata
+		gateway.RegisterDriver(gateway.NodeTypeMqtt, func(metadata gateway.NodeMetadata) (gateway.Node, error) {
+			actual = metadata
+			return nil, nil
+		})
+		metadata := gateway.NodeMetadata{Type: gateway.NodeTypeMqtt, Host: "192.168.1.20"}
+
+		_, err := gateway.NewNodeFor(metadata)
+
+		assert.NoError(t, err)
+		assert.Equal(t, metadata, actual)
+	})
+
+	t.Run("should count the commands keeping the node a reporter", func(t *testing.T) {
+		recorder := &recordingNode{state: gateway.StateOn}
+		gateway.RegisterDriver(gateway.NodeTypeMqtt, func(metadata gateway.NodeMetadata) (gateway.Node, error) {
+			return recorder, nil
+		})
+
+		node, err := gateway.NewNodeFor(gateway.NodeMetadata{Type: gateway.NodeTypeMqtt})
+
+		if assert.NoError(t, err) {
+			assert.NoError(t, node.Off(testutils.NewDevice("porch-light")))
+			assert.EqualError(t, node.On(testutils.NewDevice("broken-lamp")), "node unreachable")
+			reporter, ok := node.(gateway.Reporter)
+			if assert.True(t, ok) {
+				state, _ := reporter.State(testutils.NewDevice("porch-light"))
+				assert.Equal(t, gateway.StateOn, state)
+			}
+		}
+		builder := &strings.Builder{}
+		_, err = metrics.Default.WriteTo(builder)
+		assert.NoError(t, err)
+		assert.Contains(t, builder.String(), `dwarka_node_commands_total{type="mqtt",command="off",result="success"}`)
+		assert.Contains(t, builder.String(), `dwarka_node_commands_total{type="mqtt",command="on",result="failure"}`)
+	})
+}
+
+func TestNodeOf(t *testing.T) {
+	device := testutils.NewDevice("porch-light")
+	nodes := gateway.Nodes{
+		"node-two": {Devices: []string{"porch-light"}, PhysicalEntity: gateway.PhysicalEntity{Name: "node-two"}},
+		"node-one": {Devices: []string{"porch-light"}, PhysicalEntity: gateway.PhysicalEntity{Name: "node-one"}},
+	}
+
+	node, ok := gateway.NodeOf(nodes, device)
+
+	assert.True(t, ok)
+	assert.Equal(t, "node-one", node.ID())
+
+	_, ok = gateway.NodeOf(nodes, testutils.NewDevice("ceiling-fan"))
+	assert.False(t, ok)
+}
@@ -17,6 +17,7 @@ type Floor struct {
 func (floor Floor) Validate() error {
 	return validation.ValidateStruct(&floor,
 		validation.Field(&floor.Name, validation.Required, validation.Length(5, 50)),
+		validation.Field(&floor.Labels, validation.By(validateLabels)),
 		validation.Field(&floor.Level, validation.Required),
 	)
 }
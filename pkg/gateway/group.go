@@ -0,0 +1,184 @@
+package gateway
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	validation "github.com/go-ozzo/ozzo-validation/v3"
+)
+
+// CapabilityMeta is the key of the device meta holding the capabilities
+// of the device separated by commas e.g. light,dimmable
+const CapabilityMeta = "capability"
+
+// Selector selects the devices of the building in the Floor given as
+// floor-id, or in the Room given as floor-id/room-id, having the
+// Capability and every Meta label
+type Selector struct {
+	Floor      string            `json:"floor,omitempty"`
+	Room       string            `json:"room,omitempty"`
+	Capability string            `json:"capability,omitempty"`
+	Meta       map[string]string `json:"meta,omitempty"`
+}
+
+// Validate validates whether the selector is well formed
+func (selector Selector) Validate() error {
+	if selector.Floor != "" && selector.Room != "" {
+		return fmt.Errorf("selector is given with either a floor or a room")
+	}
+	if selector.Floor != "" {
+		return validatePath("floor", selector.Floor, 1)
+	}
+	if selector.Room != "" {
+		return validatePath("room", selector.Room, 2)
+	}
+	return nil
+}
+
+// Matches returns true when the device placed in the floor and the room
+// is selected
+func (selector Selector) Matches(floor, room string, device Device) bool {
+	if selector.Floor != "" && selector.Floor != floor {
+		return false
+	}
+	if selector.Room != "" && selector.Room != floor+"/"+room {
+		return false
+	}
+	if selector.Capability != "" && !device.HasCapability(selector.Capability) {
+		return false
+	}
+	for key, value := range selector.Meta {
+		if actual, ok := device.Meta[key]; !ok || actual != value {
+			return false
+		}
+	}
+	return true
+}
+
+// HasCapability returns true when the capability is one of the
+// capabilities given in the meta of the device
+func (device Device) HasCapability(capability string) bool {
+	for _, actual := range strings.Split(device.Meta[CapabilityMeta], ",") {
+		if strings.EqualFold(strings.TrimSpace(actual), capability) {
+			return true
+		}
+	}
+	return false
+}
+
+// Group represents devices of a building spanning rooms and floors which
+// are commanded together e.g. all exterior lights. A static group lists
+// its Devices given as floor-id/room-id/device-id whereas a dynamic group
+// has its devices chosen by the Selector whenever it is used
+type Group struct {
+	Building Entity    `json:"-"`
+	Devices  []string  `json:"devices,omitempty"`
+	Selector *Selector `json:"selector,omitempty"`
+	PhysicalEntity
+}
+
+// Validate validates whether group has all the necessary fields
+func (group Group) Validate() error {
+	err := validation.ValidateStruct(&group,
+		validation.Field(&group.Name, validation.Required, validation.Length(5, 50)),
+		validation.Field(&group.Labels, validation.By(validateLabels)),
+	)
+	if err != nil {
+		return err
+	}
+
+	if (len(group.Devices) == 0) == (group.Selector == nil) {
+		return fmt.Errorf("group is given as either devices or a selector")
+	}
+	if group.Selector != nil {
+		return group.Selector.Validate()
+	}
+	seen := map[string]bool{}
+	for _, device := range group.Devices {
+		if err := validatePath("device", device, 3); err != nil {
+			return err
+		}
+		if seen[device] {
+			return fmt.Errorf("device %s is repeated", device)
+		}
+		seen[device] = true
+	}
+	return nil
+}
+
+// Dynamic returns true when the devices of the group are chosen by its
+// selector
+func (group Group) Dynamic() bool {
+	return group.Selector != nil
+}
+
+// Members returns the devices of the group ordered by their path, the
+// devices of a static group are returned even when missing from the tree
+func (group Group) Members(tree Tree) []Target {
+	members := []Target{}
+	if !group.Dynamic() {
+		for _, device := range group.Devices {
+			ids := strings.Split(device, "/")
+			members = append(members, Target{Floor: ids[0], Room: ids[1], Device: ids[2]})
+		}
+	} else {
+		for floorID, floor := range tree.FloorsOf(group.Building) {
+			for roomID, room := range tree.RoomsOf(floor) {
+				for deviceID, device := range tree.DevicesOf(room) {
+					if group.Selector.Matches(floorID, roomID, device) {
+						members = append(members, Target{Floor: floorID, Room: roomID, Device: deviceID})
+					}
+				}
+			}
+		}
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].Path() < members[j].Path() })
+	return members
+}
+
+// Command returns the scene switching every member of the group to the
+// state, activating the scene dispatches the command to the group
+func (group Group) Command(tree Tree, state State) Scene {
+	targets := group.Members(tree)
+	for i := range targets {
+		targets[i].State = state
+	}
+	return Scene{Building: group.Building, Targets: targets, PhysicalEntity: group.PhysicalEntity}
+}
+
+// NewGroup returns a Group from []byte
+func NewGroup(building Building, data []byte) (Group, error) {
+	group := Group{Building: building}
+	err := json.Unmarshal(data, &group)
+	if err != nil {
+		return Group{}, fmt.Errorf("unable to parse group, %w", err)
+	}
+
+	err = group.Validate()
+	if err != nil {
+		return group, err
+	}
+
+	return group, nil
+}
+
+// Groups represents map string, Group
+type Groups map[string]Group
+
+// NewGroups returns list of Groups from []byte
+func NewGroups(building Entity, data []byte) (Groups, error) {
+	groups := Groups{}
+	err := json.Unmarshal(data, &groups)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse groups, %w", err)
+	}
+
+	result := Groups{}
+	for _, group := range groups {
+		group.Building = building
+		result[group.ID()] = group
+	}
+	return result, nil
+}
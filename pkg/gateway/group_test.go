@@ -0,0 +1,136 @@
+package gateway_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/testutils"
+)
+
+// newGroupTree returns the tree of building-one having the porch light and
+// the hall fan on the ground floor and the balcony light upstairs
+func newGroupTree() gateway.Tree {
+	building := testutils.NewBuilding("building-one")
+	tree := gateway.NewTree()
+	tree.Buildings[building.ID()] = building
+	floors := gateway.Floors{}
+	for floorID, rooms := range map[string]map[string]map[string]string{
+		"ground":   {"porch": {"porch-light": "light"}, "hall": {"hall-fan": "fan"}},
+		"upstairs": {"balcony": {"balcony-light": "light, dimmable"}},
+	} {
+		floor := gateway.Floor{Building: building, PhysicalEntity: gateway.PhysicalEntity{Name: floorID}}
+		floors[floorID] = floor
+		floorRooms := gateway.Rooms{}
+		for roomID, devices := range rooms {
+			room := gateway.Room{Floor: floor, PhysicalEntity: gateway.PhysicalEntity{Name: roomID}}
+			floorRooms[roomID] = room
+			roomDevices := gateway.Devices{}
+			for deviceID, capability := range devices {
+				roomDevices[deviceID] = gateway.Device{
+					Room:           room,
+					Meta:           map[string]string{gateway.CapabilityMeta: capability, "zone": "exterior"},
+					PhysicalEntity: gateway.PhysicalEntity{Name: deviceID},
+				}
+			}
+			if roomID == "hall" {
+				roomDevices["hall-fan"].Meta["zone"] = "interior"
+			}
+			tree.AddDevices(room, roomDevices)
+		}
+		tree.AddRooms(floor, floorRooms)
+	}
+	tree.AddFloors(building, floors)
+	return tree
+}
+
+func TestNewGroup(t *testing.T) {
+	building := testutils.NewBuilding("building-one")
+
+	t.Run("should return static and dynamic groups", func(t *testing.T) {
+		static, err := gateway.NewGroup(building, []byte(`{"name":"porch lights","devices":["ground/porch/porch-light"]}`))
+		if assert.NoError(t, err) {
+			assert.Equal(t, "porch-lights", static.ID())
+			assert.False(t, static.Dynamic())
+		}
+
+		dynamic, err := gateway.NewGroup(building, []byte(`{"name":"exterior lights","selector":{"capability":"light","meta":{"zone":"exterior"}}}`))
+		if assert.NoError(t, err) {
+			assert.True(t, dynamic.Dynamic())
+			assert.Equal(t, "light", dynamic.Selector.Capability)
+		}
+	})
+
+	t.Run("should validate the devices and the selector", func(t *testing.T) {
+		for body, message := range map[string]string{
+			`{"name":"porch lights"}`: "group is given as either devices or a selector",
+			`{"name":"porch lights","devices":["ground/porch/porch-light"],"selector":{}}`:              "group is given as either devices or a selector",
+			`{"name":"porch lights","devices":["porch/porch-light"]}`:                                   "device porch/porch-light should be given as floor-id/room-id/device-id",
+			`{"name":"porch lights","devices":["ground/porch/porch-light","ground/porch/porch-light"]}`: "device ground/porch/porch-light is repeated",
+			`{"name":"porch lights","selector":{"floor":"ground","room":"ground/porch"}}`:               "selector is given with either a floor or a room",
+			`{"name":"porch lights","selector":{"room":"porch"}}`:                                       "room porch should be given as floor-id/room-id",
+			`{"name":"lamp","devices":["ground/porch/porch-light"]}`:                                    "name: the length must be between 5 and 50.",
+		} {
+			_, err := gateway.NewGroup(building, []byte(body))
+
+			assert.EqualError(t, err, message, body)
+		}
+	})
+}
+
+func TestGroup_Members(t *testing.T) {
+	tree := newGroupTree()
+	building := testutils.NewBuilding("building-one")
+
+	t.Run("should select the devices across the floors and the rooms", func(t *testing.T) {
+		for _, testCase := range []struct {
+			selector gateway.Selector
+			expected []string
+		}{
+			{gateway.Selector{}, []string{"ground/hall/hall-fan", "ground/porch/porch-light", "upstairs/balcony/balcony-light"}},
+			{gateway.Selector{Floor: "ground"}, []string{"ground/hall/hall-fan", "ground/porch/porch-light"}},
+			{gateway.Selector{Room: "upstairs/balcony"}, []string{"upstairs/balcony/balcony-light"}},
+			{gateway.Selector{Capability: "Light"}, []string{"ground/porch/porch-light", "upstairs/balcony/balcony-light"}},
+			{gateway.Selector{Capability: "dimmable"}, []string{"upstairs/balcony/balcony-light"}},
+			{gateway.Selector{Meta: map[string]string{"zone": "exterior"}}, []string{"ground/porch/porch-light", "upstairs/balcony/balcony-light"}},
+			{gateway.Selector{Floor: "ground", Meta: map[string]string{"zone": "interior"}}, []string{"ground/hall/hall-fan"}},
+			{gateway.Selector{Meta: map[string]string{"zone": "garden"}}, []string{}},
+		} {
+			selector := testCase.selector
+			group := gateway.Group{Building: building, Selector: &selector}
+
+			members := group.Members(tree)
+
+			paths := []string{}
+			for _, member := range members {
+				paths = append(paths, member.Path())
+			}
+			assert.Equal(t, testCase.expected, paths, "%+v", testCase.selector)
+		}
+	})
+
+	t.Run("should list the devices of a static group even when missing", func(t *testing.T) {
+		group := gateway.Group{Building: building, Devices: []string{"upstairs/balcony/balcony-light", "ground/garage/garage-light"}}
+
+		assert.Equal(t, []gateway.Target{
+			{Floor: "ground", Room: "garage", Device: "garage-light"},
+			{Floor: "upstairs", Room: "balcony", Device: "balcony-light"},
+		}, group.Members(tree))
+	})
+}
+
+func TestGroup_Command(t *testing.T) {
+	group := gateway.Group{
+		Building:       testutils.NewBuilding("building-one"),
+		Selector:       &gateway.Selector{Capability: "light"},
+		PhysicalEntity: gateway.PhysicalEntity{Name: "all lights"},
+	}
+
+	scene := group.Command(newGroupTree(), gateway.StateOff)
+
+	assert.Equal(t, "all-lights", scene.ID())
+	assert.Equal(t, []gateway.Target{
+		{Floor: "ground", Room: "porch", Device: "porch-light", State: gateway.StateOff},
+		{Floor: "upstairs", Room: "balcony", Device: "balcony-light", State: gateway.StateOff},
+	}, scene.Targets)
+}
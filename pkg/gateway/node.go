@@ -0,0 +1,87 @@
+package gateway
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	validation "github.com/go-ozzo/ozzo-validation/v3"
+)
+
+// NodeType returns the string representation of node type
+func (nodeType NodeType) NodeType() string {
+	switch nodeType {
+	case NodeTypeMqtt:
+		return "mqtt"
+	default:
+		return "wifi"
+	}
+}
+
+// NewNodeType converts string node type as NodeType
+func NewNodeType(nodeType string) (NodeType, error) {
+	switch strings.ToLower(nodeType) {
+	case "wifi":
+		return NodeTypeWifi, nil
+	case "mqtt":
+		return NodeTypeMqtt, nil
+	default:
+		return -1, errors.New(fmt.Sprintf("node type %s not supported", nodeType))
+	}
+}
+
+// NodeMetadata represents information about a node placed in a room,
+// Devices holds the id of the devices of the room the node controls
+type NodeMetadata struct {
+	Room           Room     `json:"-"`
+	Devices        []string `json:"devices"`
+	Host           string   `json:"host"`
+	Type           NodeType `json:"type"`
+	PhysicalEntity `json:"entity"`
+}
+
+// Validate validates whether node has all the necessary fields
+func (node NodeMetadata) Validate() error {
+	return validation.ValidateStruct(&node,
+		validation.Field(&node.Name, validation.Required, validation.Length(5, 50)),
+		validation.Field(&node.Labels, validation.By(validateLabels)),
+		validation.Field(&node.Host, validation.Required),
+	)
+}
+
+// NewNode returns a NodeMetadata from []byte
+func NewNode(room Room, data []byte) (NodeMetadata, error) {
+	node := NodeMetadata{Room: room}
+	err := json.Unmarshal(data, &node)
+	if err != nil {
+		return NodeMetadata{}, fmt.Errorf("unable to parse node, %w", err)
+	}
+
+	err = node.Validate()
+	if err != nil {
+		return node, err
+	}
+
+	return node, nil
+}
+
+// Nodes represents map string, NodeMetadata
+type Nodes map[string]NodeMetadata
+
+// NewNodes returns list of Nodes from []byte
+func NewNodes(room Room, data []byte) (Nodes, error) {
+	nodes := Nodes{}
+	err := json.Unmarshal(data, &nodes)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse nodes, %w", err)
+	}
+
+	result := Nodes{}
+
+	for _, node := range nodes {
+		node.Room = room
+		result[node.ID()] = node
+	}
+	return result, nil
+}
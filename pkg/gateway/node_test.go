@@ -0,0 +1,80 @@
+package gateway_test
+
+import (
+	"encoding/json"
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/testutils"
+	"testing"
+)
+
+func TestNodeType(t *testing.T) {
+	for _, name := range []string{"wifi", "mqtt"} {
+		nodeType, err := gateway.NewNodeType(name)
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, name, nodeType.NodeType())
+		}
+	}
+
+	_, err := gateway.NewNodeType("zigbee")
+	assert.EqualError(t, err, "node type zigbee not supported")
+}
+
+func TestNewNode(t *testing.T) {
+	t.Run("should return node associated to a room", func(t *testing.T) {
+		room := testutils.NewRoom("room-one")
+		node := gateway.NodeMetadata{
+			Room:    room,
+			Devices: []string{"porch-light"},
+			Host:    "192.168.1.20",
+			Type:    gateway.NodeTypeMqtt,
+			PhysicalEntity: gateway.PhysicalEntity{
+				Name:        "node-one",
+				Description: "for test",
+			},
+		}
+
+		data, _ := json.Marshal(node)
+
+		actual, err := gateway.NewNode(room, data)
+
+		if assert.NoError(t, err) {
+			if !cmp.Equal(node, actual) {
+				assert.Fail(t, cmp.Diff(node, actual))
+			}
+		}
+	})
+
+	t.Run("should return validation error", func(t *testing.T) {
+		_, err := gateway.NewNode(testutils.NewRoom("room-one"), []byte(`{"entity": {"name": "node-one"}}`))
+
+		if assert.Error(t, err) {
+			assert.Equal(t, "host: cannot be blank.", err.Error())
+		}
+	})
+}
+
+func TestNewNodes(t *testing.T) {
+	t.Run("should return nodes associated to a room", func(t *testing.T) {
+		room := testutils.NewRoom("room-one")
+		nodes := gateway.Nodes{"node-one": gateway.NodeMetadata{
+			Room: room,
+			Host: "192.168.1.20",
+			PhysicalEntity: gateway.PhysicalEntity{
+				Name: "node one",
+			},
+		}}
+
+		data, _ := json.Marshal(nodes)
+
+		actual, err := gateway.NewNodes(room, data)
+
+		if assert.NoError(t, err) {
+			if !cmp.Equal(nodes, actual) {
+				assert.Fail(t, cmp.Diff(nodes, actual))
+			}
+		}
+	})
+}
@@ -17,6 +17,7 @@ type Room struct {
 func (room Room) Validate() error {
 	return validation.ValidateStruct(&room,
 		validation.Field(&room.Name, validation.Required, validation.Length(5, 50)),
+		validation.Field(&room.Labels, validation.By(validateLabels)),
 		validation.Field(&room.Direction, validation.Required),
 	)
 }
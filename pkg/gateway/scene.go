@@ -0,0 +1,229 @@
+package gateway
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	validation "github.com/go-ozzo/ozzo-validation/v3"
+)
+
+// State is the state of a device as reported by the node controlling it
+type State string
+
+const (
+	// StateOn is the state of a device switched on
+	StateOn State = "on"
+	// StateOff is the state of a device switched off
+	StateOff State = "off"
+)
+
+// NewState parses the state of a device
+func NewState(state string) (State, error) {
+	switch State(strings.ToLower(state)) {
+	case StateOn:
+		return StateOn, nil
+	case StateOff:
+		return StateOff, nil
+	default:
+		return "", fmt.Errorf("state %s not supported, expected on or off", state)
+	}
+}
+
+// Switch switches the device to the state using the node
+func (state State) Switch(node Node, device Device) error {
+	if state == StateOn {
+		return node.On(device)
+	}
+	return node.Off(device)
+}
+
+// States represents the last reported state of the devices of a room
+// keyed by the id of the device
+type States map[string]State
+
+// NewStates returns the States from []byte
+func NewStates(data []byte) (States, error) {
+	states := States{}
+	err := json.Unmarshal(data, &states)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse states, %w", err)
+	}
+	return states, nil
+}
+
+// Reporter is implemented by the nodes which are able to report the
+// state of their devices, the state of the devices controlled by other
+// nodes is the state they were last switched to
+type Reporter interface {
+	State(Device) (State, error)
+}
+
+// Target is the state a device is switched to when the scene is activated
+type Target struct {
+	Floor  string `json:"floor"`
+	Room   string `json:"room"`
+	Device string `json:"device"`
+	State  State  `json:"state"`
+}
+
+// Path returns the ids of the floor, room and device joined by /
+func (target Target) Path() string {
+	return strings.Join([]string{target.Floor, target.Room, target.Device}, "/")
+}
+
+// Scene represents the states of several devices of a building which
+// are switched together e.g. movie mode, a scene of a room given as
+// floor-id/room-id holds the devices of the room only
+type Scene struct {
+	Building Entity   `json:"-"`
+	Room     string   `json:"room,omitempty"`
+	Targets  []Target `json:"targets"`
+	PhysicalEntity
+}
+
+// Validate validates whether scene has all the necessary fields
+func (scene Scene) Validate() error {
+	err := validation.ValidateStruct(&scene,
+		validation.Field(&scene.Name, validation.Required, validation.Length(5, 50)),
+		validation.Field(&scene.Labels, validation.By(validateLabels)),
+		validation.Field(&scene.Targets, validation.Required),
+	)
+	if err != nil {
+		return err
+	}
+
+	if scene.Room != "" && len(strings.Split(scene.Room, "/")) != 2 {
+		return fmt.Errorf("room %s should be given as floor-id/room-id", scene.Room)
+	}
+	seen := map[string]bool{}
+	for _, target := range scene.Targets {
+		if target.Floor == "" || target.Room == "" || target.Device == "" {
+			return fmt.Errorf("target %s should have the floor, room and device", target.Path())
+		}
+		if _, err := NewState(string(target.State)); err != nil {
+			return fmt.Errorf("target %s: %v", target.Path(), err)
+		}
+		if scene.Room != "" && target.Floor+"/"+target.Room != scene.Room {
+			return fmt.Errorf("target %s is outside the room %s of the scene", target.Path(), scene.Room)
+		}
+		if seen[target.Path()] {
+			return fmt.Errorf("target %s is repeated", target.Path())
+		}
+		seen[target.Path()] = true
+	}
+	return nil
+}
+
+// NewScene returns a Scene from []byte
+func NewScene(building Building, data []byte) (Scene, error) {
+	scene := Scene{Building: building}
+	err := json.Unmarshal(data, &scene)
+	if err != nil {
+		return Scene{}, fmt.Errorf("unable to parse scene, %w", err)
+	}
+
+	err = scene.Validate()
+	if err != nil {
+		return scene, err
+	}
+
+	return scene, nil
+}
+
+// Scenes represents map string, Scene
+type Scenes map[string]Scene
+
+// NewScenes returns list of Scenes from []byte
+func NewScenes(building Entity, data []byte) (Scenes, error) {
+	scenes := Scenes{}
+	err := json.Unmarshal(data, &scenes)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse scenes, %w", err)
+	}
+
+	result := Scenes{}
+	for _, scene := range scenes {
+		scene.Building = building
+		result[scene.ID()] = scene
+	}
+	return result, nil
+}
+
+// Result is the outcome of switching a target of a scene, Device is
+// set once the device of the target is found
+type Result struct {
+	Target Target
+	Device *Device
+	Err    error
+}
+
+// Activate switches every target of the scene in parallel using the
+// nodes of the tree which control the devices, the results are in the
+// order of the targets
+func (scene Scene) Activate(tree Tree) []Result {
+	results := make([]Result, len(scene.Targets))
+	var wg sync.WaitGroup
+	for i, target := range scene.Targets {
+		results[i].Target = target
+		device, node, err := scene.resolve(tree, target)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		results[i].Device = &device
+
+		wg.Add(1)
+		go func(result *Result, node Node) {
+			defer wg.Done()
+			result.Err = result.Target.State.Switch(node, *result.Device)
+		}(&results[i], node)
+	}
+	wg.Wait()
+	return results
+}
+
+// resolve returns the device of the target along with the node which
+// controls it
+func (scene Scene) resolve(tree Tree, target Target) (Device, Node, error) {
+	floor, ok := tree.FloorsOf(scene.Building)[target.Floor]
+	if !ok {
+		return Device{}, nil, fmt.Errorf("floor %s not found", target.Floor)
+	}
+	room, ok := tree.RoomsOf(floor)[target.Room]
+	if !ok {
+		return Device{}, nil, fmt.Errorf("room %s/%s not found", target.Floor, target.Room)
+	}
+	device, ok := tree.DevicesOf(room)[target.Device]
+	if !ok {
+		return Device{}, nil, fmt.Errorf("device %s not found", target.Path())
+	}
+
+	metadata, ok := NodeOf(tree.NodesOf(room), device)
+	if !ok {
+		return device, nil, fmt.Errorf("device %s is not controlled by any node", device.ID())
+	}
+	node, err := NewNodeFor(metadata)
+	if err != nil {
+		return device, nil, err
+	}
+	return device, node, nil
+}
+
+// ReportedState returns the state of the device reported by the node
+// controlling it when the node is a Reporter, otherwise the state the
+// device was last switched to
+func ReportedState(nodes Nodes, states States, device Device) (State, bool) {
+	if metadata, ok := NodeOf(nodes, device); ok {
+		if node, err := NewNodeFor(metadata); err == nil {
+			if reporter, ok := node.(Reporter); ok {
+				if state, err := reporter.State(device); err == nil {
+					return state, true
+				}
+			}
+		}
+	}
+	state, ok := states[device.ID()]
+	return state, ok
+}
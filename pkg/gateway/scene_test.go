@@ -0,0 +1,182 @@
+package gateway_test
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/testutils"
+)
+
+func newScene(room string, targets ...gateway.Target) gateway.Scene {
+	return gateway.Scene{
+		Building:       testutils.NewBuilding("building-one"),
+		Room:           room,
+		Targets:        targets,
+		PhysicalEntity: gateway.PhysicalEntity{Name: "movie mode"},
+	}
+}
+
+func TestNewScene(t *testing.T) {
+	t.Run("should return scene associated to a building", func(t *testing.T) {
+		building := testutils.NewBuilding("building-one")
+		scene := newScene("", gateway.Target{Floor: "floor-one", Room: "hall", Device: "projector", State: gateway.StateOn})
+		data, _ := json.Marshal(scene)
+
+		actual, err := gateway.NewScene(building, data)
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, "movie-mode", actual.ID())
+			if !cmp.Equal(scene, actual) {
+				assert.Fail(t, cmp.Diff(scene, actual))
+			}
+		}
+	})
+
+	t.Run("should validate the targets", func(t *testing.T) {
+		projector := gateway.Target{Floor: "floor-one", Room: "hall", Device: "projector", State: gateway.StateOn}
+		for message, scene := range map[string]gateway.Scene{
+			"targets: cannot be blank.":                                                      newScene(""),
+			"room hall should be given as floor-id/room-id":                                  newScene("hall", projector),
+			"target floor-one/hall/projector is outside the room floor-one/den of the scene": newScene("floor-one/den", projector),
+			"target floor-one/hall/projector is repeated":                                    newScene("", projector, projector),
+			"target floor-one//projector should have the floor, room and device":             newScene("", gateway.Target{Floor: "floor-one", Device: "projector", State: gateway.StateOn}),
+			"target floor-one/hall/projector: state dim not supported, expected on or off":   newScene("", gateway.Target{Floor: "floor-one", Room: "hall", Device: "projector", State: "dim"}),
+		} {
+			assert.EqualError(t, scene.Validate(), message)
+		}
+	})
+}
+
+func TestNewScenes(t *testing.T) {
+	building := testutils.NewBuilding("building-one")
+	scenes := gateway.Scenes{"movie-mode": newScene("", gateway.Target{Floor: "floor-one", Room: "hall", Device: "projector", State: gateway.StateOff})}
+	data, _ := json.Marshal(scenes)
+
+	actual, err := gateway.NewScenes(building, data)
+
+	if assert.NoError(t, err) {
+		if !cmp.Equal(scenes, actual) {
+			assert.Fail(t, cmp.Diff(scenes, actual))
+		}
+	}
+}
+
+func TestNewState(t *testing.T) {
+	state, err := gateway.NewState("ON")
+	assert.NoError(t, err)
+	assert.Equal(t, gateway.StateOn, state)
+
+	_, err = gateway.NewState("dim")
+	assert.EqualError(t, err, "state dim not supported, expected on or off")
+}
+
+// recordingNode records the switched devices, it is safe for the
+// parallel activation of the scenes
+type recordingNode struct {
+	mu       sync.Mutex
+	switched []string
+	state    gateway.State
+}
+
+func (node *recordingNode) On(device gateway.Device) error {
+	return node.record("on:" + device.ID())
+}
+
+func (node *recordingNode) Off(device gateway.Device) error {
+	return node.record("off:" + device.ID())
+}
+
+func (node *recordingNode) State(device gateway.Device) (gateway.State, error) {
+	return node.state, nil
+}
+
+func (node *recordingNode) record(value string) error {
+	node.mu.Lock()
+	defer node.mu.Unlock()
+	node.switched = append(node.switched, value)
+	if strings.Contains(value, "broken") {
+		return errors.New("node unreachable")
+	}
+	return nil
+}
+
+func newSceneTree(devices ...string) (gateway.Tree, gateway.Room) {
+	device := testutils.NewDevice(devices[0])
+	room := device.Room
+	floor := room.Floor
+	tree := gateway.NewTree()
+	tree.Buildings[floor.Building.ID()] = floor.Building.(gateway.Building)
+	tree.AddFloors(floor.Building, gateway.Floors{floor.ID(): floor})
+	tree.AddRooms(floor, gateway.Rooms{room.ID(): room})
+
+	roomDevices := gateway.Devices{}
+	for _, name := range devices {
+		device := testutils.NewDevice(name)
+		roomDevices[device.ID()] = device
+	}
+	tree.AddDevices(room, roomDevices)
+	tree.AddNodes(room, gateway.Nodes{"node-one": gateway.NodeMetadata{
+		Room: room, Devices: devices, Host: "192.168.1.20", Type: gateway.NodeTypeMqtt,
+		PhysicalEntity: gateway.PhysicalEntity{Name: "node-one"},
+	}})
+	return tree, room
+}
+
+func TestScene_Activate(t *testing.T) {
+	node := &recordingNode{}
+	gateway.RegisterDriver(gateway.NodeTypeMqtt, func(metadata gateway.NodeMetadata) (gateway.Node, error) {
+		return node, nil
+	})
+	tree, _ := newSceneTree("porch-light", "projector", "broken-lamp")
+	scene := newScene("",
+		gateway.Target{Floor: "floor-one", Room: "room-one", Device: "porch-light", State: gateway.StateOff},
+		gateway.Target{Floor: "floor-one", Room: "room-one", Device: "projector", State: gateway.StateOn},
+		gateway.Target{Floor: "floor-one", Room: "room-one", Device: "broken-lamp", State: gateway.StateOn},
+		gateway.Target{Floor: "floor-one", Room: "room-two", Device: "speaker", State: gateway.StateOn},
+	)
+
+	results := scene.Activate(tree)
+
+	if assert.Len(t, results, 4) {
+		assert.NoError(t, results[0].Err)
+		assert.Equal(t, "porch-light", results[0].Device.ID())
+		assert.NoError(t, results[1].Err)
+		assert.EqualError(t, results[2].Err, "node unreachable")
+		assert.EqualError(t, results[3].Err, "room floor-one/room-two not found")
+		assert.Nil(t, results[3].Device)
+	}
+	assert.ElementsMatch(t, []string{"off:porch-light", "on:projector", "on:broken-lamp"}, node.switched)
+}
+
+func TestReportedState(t *testing.T) {
+	tree, room := newSceneTree("porch-light")
+	device := tree.DevicesOf(room)["porch-light"]
+	states := gateway.States{"porch-light": gateway.StateOff}
+
+	t.Run("should prefer the state reported by the node", func(t *testing.T) {
+		gateway.RegisterDriver(gateway.NodeTypeMqtt, func(metadata gateway.NodeMetadata) (gateway.Node, error) {
+			return &recordingNode{state: gateway.StateOn}, nil
+		})
+
+		state, ok := gateway.ReportedState(tree.NodesOf(room), states, device)
+
+		assert.True(t, ok)
+		assert.Equal(t, gateway.StateOn, state)
+	})
+
+	t.Run("should fall back to the state the device was switched to", func(t *testing.T) {
+		state, ok := gateway.ReportedState(gateway.Nodes{}, states, device)
+
+		assert.True(t, ok)
+		assert.Equal(t, gateway.StateOff, state)
+
+		_, ok = gateway.ReportedState(gateway.Nodes{}, gateway.States{}, device)
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,256 @@
+package gateway
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v3"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/cron"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/solar"
+)
+
+const (
+	// ScheduleRecurring runs on every occurrence of the cron expression or
+	// every day at the solar moment
+	ScheduleRecurring = "recurring"
+	// ScheduleOnce runs once at the time
+	ScheduleOnce = "once"
+
+	// MissedSkip drops the runs missed while the server was stopped
+	MissedSkip = "skip"
+	// MissedRunOnce runs the schedule once when the server starts after
+	// missing any of its runs
+	MissedRunOnce = "run-once"
+
+	// DateLayout is the layout of the skipped dates
+	DateLayout = "2006-01-02"
+	// DateTimeLayout is the layout of the time of the schedules run once
+	DateTimeLayout = "2006-01-02T15:04"
+)
+
+// Schedule switches a device or the devices of a room to the state, or
+// activates a scene, at the times given in the time zone of the building.
+// Recurring schedules are given as a cron expression, or as a solar moment
+// e.g. sunset-15m to run every day, and the schedules run once are given
+// At 2006-01-02T15:04. No run happens on the Skip dates e.g. holidays.
+// Device is given as floor-id/room-id/device-id and Room as
+// floor-id/room-id
+type Schedule struct {
+	Building Entity     `json:"-"`
+	Disabled bool       `json:"disabled,omitempty"`
+	Mode     string     `json:"mode,omitempty"`
+	Cron     string     `json:"cron,omitempty"`
+	Sun      string     `json:"sun,omitempty"`
+	At       string     `json:"at,omitempty"`
+	Skip     []string   `json:"skip,omitempty"`
+	Missed   string     `json:"missed,omitempty"`
+	Device   string     `json:"device,omitempty"`
+	Room     string     `json:"room,omitempty"`
+	Scene    string     `json:"scene,omitempty"`
+	State    State      `json:"state,omitempty"`
+	Created  time.Time  `json:"created"`
+	LastRun  *time.Time `json:"lastRun,omitempty"`
+	PhysicalEntity
+}
+
+// Validate validates whether schedule has all the necessary fields
+func (schedule Schedule) Validate() error {
+	err := validation.ValidateStruct(&schedule,
+		validation.Field(&schedule.Name, validation.Required, validation.Length(5, 50)),
+		validation.Field(&schedule.Labels, validation.By(validateLabels)),
+		validation.Field(&schedule.Mode, validation.In(ScheduleRecurring, ScheduleOnce)),
+		validation.Field(&schedule.Missed, validation.In(MissedSkip, MissedRunOnce)),
+	)
+	if err != nil {
+		return err
+	}
+
+	switch schedule.Mode {
+	case ScheduleOnce:
+		if schedule.Cron != "" || schedule.Sun != "" {
+			return fmt.Errorf("schedule run once is given as at rather than as cron or sun")
+		}
+		if _, err := time.Parse(DateTimeLayout, schedule.At); err != nil {
+			return fmt.Errorf("at %s should be given as yyyy-mm-ddThh:mm e.g. 2026-10-24T18:30", schedule.At)
+		}
+	default:
+		if (schedule.Cron == "") == (schedule.Sun == "") || schedule.At != "" {
+			return fmt.Errorf("recurring schedule is given as either cron or sun")
+		}
+		if schedule.Cron != "" {
+			if _, err := cron.Parse(schedule.Cron); err != nil {
+				return err
+			}
+		}
+		if schedule.Sun != "" {
+			if _, err := solar.ParseMoment(schedule.Sun); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, date := range schedule.Skip {
+		if _, err := time.Parse(DateLayout, date); err != nil {
+			return fmt.Errorf("skip date %s should be given as yyyy-mm-dd e.g. 2026-12-25", date)
+		}
+	}
+	return schedule.validateTarget()
+}
+
+func (schedule Schedule) validateTarget() error {
+	targets := 0
+	for _, target := range []string{schedule.Device, schedule.Room, schedule.Scene} {
+		if target != "" {
+			targets++
+		}
+	}
+	if targets != 1 {
+		return fmt.Errorf("schedule targets either a device, a room or a scene")
+	}
+
+	switch {
+	case schedule.Scene != "":
+		if schedule.State != "" {
+			return fmt.Errorf("state is given for the devices and the rooms only")
+		}
+		return nil
+	case schedule.Room != "":
+		if _, err := NewState(string(schedule.State)); err != nil {
+			return err
+		}
+		return validatePath("room", schedule.Room, 2)
+	default:
+		if _, err := NewState(string(schedule.State)); err != nil {
+			return err
+		}
+		return validatePath("device", schedule.Device, 3)
+	}
+}
+
+// Next returns the first run of the schedule after the time, the runs are
+// in the time zone of the building and otherwise in the time zone of after.
+// The zero time is returned when the schedule does not run anymore
+func (schedule Schedule) Next(building Building, after time.Time) (time.Time, error) {
+	location := building.Location(after.Location())
+	after = after.In(location)
+	switch {
+	case schedule.Mode == ScheduleOnce:
+		at, err := time.ParseInLocation(DateTimeLayout, schedule.At, location)
+		if err != nil || !at.After(after) || schedule.skipped(at) {
+			return time.Time{}, err
+		}
+		return at, nil
+	case schedule.Sun != "":
+		return schedule.nextMoment(building, after)
+	default:
+		return schedule.nextCron(after)
+	}
+}
+
+// NextRuns returns up to count runs of the schedule after the time
+func (schedule Schedule) NextRuns(building Building, after time.Time, count int) ([]time.Time, error) {
+	runs := make([]time.Time, 0, count)
+	for len(runs) < count {
+		next, err := schedule.Next(building, after)
+		if err != nil {
+			return nil, err
+		}
+		if next.IsZero() {
+			break
+		}
+		runs = append(runs, next)
+		after = next
+	}
+	return runs, nil
+}
+
+func (schedule Schedule) nextCron(after time.Time) (time.Time, error) {
+	expression, err := cron.Parse(schedule.Cron)
+	if err != nil {
+		return time.Time{}, err
+	}
+	// every skipped date moves the search to the next day
+	for i := 0; i <= len(schedule.Skip); i++ {
+		next := expression.Next(after)
+		if next.IsZero() || !schedule.skipped(next) {
+			return next, nil
+		}
+		after = time.Date(next.Year(), next.Month(), next.Day()+1, 0, 0, 0, 0, next.Location()).Add(-time.Minute)
+	}
+	return time.Time{}, nil
+}
+
+func (schedule Schedule) nextMoment(building Building, after time.Time) (time.Time, error) {
+	moment, err := solar.ParseMoment(schedule.Sun)
+	if err != nil {
+		return time.Time{}, err
+	}
+	// the moment may not occur on some of the days e.g. the dusk of a
+	// white night, the search gives up after a year
+	day := time.Date(after.Year(), after.Month(), after.Day(), 0, 0, 0, 0, after.Location())
+	for i := 0; i <= 366+len(schedule.Skip); i++ {
+		at, err := moment.At(day.AddDate(0, 0, i), building.Lat, building.Lan)
+		if err != nil {
+			continue
+		}
+		at = at.In(after.Location()).Truncate(time.Minute)
+		if at.After(after) && !schedule.skipped(at) {
+			return at, nil
+		}
+	}
+	return time.Time{}, nil
+}
+
+// skipped returns true when the time is on one of the skipped dates
+func (schedule Schedule) skipped(at time.Time) bool {
+	date := at.Format(DateLayout)
+	for _, skip := range schedule.Skip {
+		if skip == date {
+			return true
+		}
+	}
+	return false
+}
+
+// NewSchedule returns a Schedule from []byte, the mode is recurring and
+// the missed runs are skipped unless given
+func NewSchedule(building Building, data []byte) (Schedule, error) {
+	schedule := Schedule{Building: building}
+	err := json.Unmarshal(data, &schedule)
+	if err != nil {
+		return Schedule{}, fmt.Errorf("unable to parse schedule, %w", err)
+	}
+	if schedule.Mode == "" {
+		schedule.Mode = ScheduleRecurring
+	}
+	if schedule.Missed == "" {
+		schedule.Missed = MissedSkip
+	}
+
+	err = schedule.Validate()
+	if err != nil {
+		return schedule, err
+	}
+
+	return schedule, nil
+}
+
+// Schedules represents map string, Schedule
+type Schedules map[string]Schedule
+
+// NewSchedules returns list of Schedules from []byte
+func NewSchedules(building Entity, data []byte) (Schedules, error) {
+	schedules := Schedules{}
+	err := json.Unmarshal(data, &schedules)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse schedules, %w", err)
+	}
+
+	result := Schedules{}
+	for _, schedule := range schedules {
+		schedule.Building = building
+		result[schedule.ID()] = schedule
+	}
+	return result, nil
+}
@@ -0,0 +1,90 @@
+package gateway_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/testutils"
+)
+
+func TestNewSchedule(t *testing.T) {
+	building := testutils.NewBuilding("building-one")
+
+	t.Run("should default to a recurring schedule skipping the missed runs", func(t *testing.T) {
+		schedule, err := gateway.NewSchedule(building, []byte(`{"name":"porch at dusk","sun":"sunset-15m","device":"floor-one/porch/porch-light","state":"on"}`))
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, "porch-at-dusk", schedule.ID())
+			assert.Equal(t, gateway.ScheduleRecurring, schedule.Mode)
+			assert.Equal(t, gateway.MissedSkip, schedule.Missed)
+		}
+	})
+
+	t.Run("should validate the times and the target", func(t *testing.T) {
+		for body, message := range map[string]string{
+			`{"name":"porch at dusk","device":"floor-one/porch/porch-light","state":"on"}`:                                    "recurring schedule is given as either cron or sun",
+			`{"name":"porch at dusk","cron":"0 18 * *","device":"floor-one/porch/porch-light","state":"on"}`:                  "cron expression '0 18 * *' should have 5 fields, got 4",
+			`{"name":"porch at dusk","sun":"noon","device":"floor-one/porch/porch-light","state":"on"}`:                       "moment noon should be dawn, sunrise, sunset or dusk optionally followed by an offset e.g. sunset-15m",
+			`{"name":"porch at dusk","mode":"once","at":"24/10/2026","device":"floor-one/porch/porch-light","state":"on"}`:    "at 24/10/2026 should be given as yyyy-mm-ddThh:mm e.g. 2026-10-24T18:30",
+			`{"name":"porch at dusk","mode":"twice","cron":"@daily","device":"floor-one/porch/porch-light","state":"on"}`:     "mode: must be a valid value.",
+			`{"name":"porch at dusk","cron":"@daily","missed":"run-all","device":"floor-one/porch/porch-light","state":"on"}`: "missed: must be a valid value.",
+			`{"name":"porch at dusk","cron":"@daily","skip":["25/12"],"device":"floor-one/porch/porch-light","state":"on"}`:   "skip date 25/12 should be given as yyyy-mm-dd e.g. 2026-12-25",
+			`{"name":"porch at dusk","cron":"@daily","room":"floor-one/porch","scene":"movie-mode","state":"on"}`:             "schedule targets either a device, a room or a scene",
+			`{"name":"porch at dusk","cron":"@daily","room":"porch","state":"on"}`:                                            "room porch should be given as floor-id/room-id",
+			`{"name":"porch at dusk","cron":"@daily","scene":"movie-mode","state":"on"}`:                                      "state is given for the devices and the rooms only",
+			`{"name":"porch at dusk","cron":"@daily","device":"floor-one/porch/porch-light","state":"dim"}`:                   "state dim not supported, expected on or off",
+		} {
+			_, err := gateway.NewSchedule(building, []byte(body))
+
+			assert.EqualError(t, err, message, body)
+		}
+	})
+}
+
+func TestSchedule_Next(t *testing.T) {
+	building := testutils.NewBuilding("building-one")
+	building.Lat, building.Lan, building.Timezone = 12.97, 77.59, "Asia/Kolkata"
+	kolkata, _ := time.LoadLocation("Asia/Kolkata")
+	// monday 19 october 2026 at noon in Bengaluru
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, kolkata)
+
+	t.Run("should return the runs of the cron expression in the time zone of the building", func(t *testing.T) {
+		schedule := gateway.Schedule{Mode: gateway.ScheduleRecurring, Cron: "30 18 * * 1-5", Skip: []string{"2026-10-20", "2026-10-21"}}
+
+		runs, err := schedule.NextRuns(building, now.UTC(), 3)
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, []time.Time{
+				time.Date(2026, 10, 19, 18, 30, 0, 0, kolkata),
+				time.Date(2026, 10, 22, 18, 30, 0, 0, kolkata),
+				time.Date(2026, 10, 23, 18, 30, 0, 0, kolkata),
+			}, runs)
+		}
+	})
+
+	t.Run("should return the solar moment of every day", func(t *testing.T) {
+		schedule := gateway.Schedule{Mode: gateway.ScheduleRecurring, Sun: "sunset-15m", Skip: []string{"2026-10-19"}}
+
+		runs, err := schedule.NextRuns(building, now, 2)
+
+		if assert.NoError(t, err) && assert.Len(t, runs, 2) {
+			assert.Equal(t, "2026-10-20 17:43", runs[0].Format("2006-01-02 15:04"))
+			assert.Equal(t, "2026-10-21 17:42", runs[1].Format("2006-01-02 15:04"))
+		}
+	})
+
+	t.Run("should return the time of the schedule run once until it passes", func(t *testing.T) {
+		schedule := gateway.Schedule{Mode: gateway.ScheduleOnce, At: "2026-10-24T07:00"}
+
+		runs, err := schedule.NextRuns(building, now, 3)
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, []time.Time{time.Date(2026, 10, 24, 7, 0, 0, 0, kolkata)}, runs)
+		}
+		next, err := schedule.Next(building, runs[0])
+		assert.NoError(t, err)
+		assert.True(t, next.IsZero())
+	})
+}
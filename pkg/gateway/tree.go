@@ -0,0 +1,76 @@
+package gateway
+
+import "path"
+
+// Tree represents the buildings along with the floors, rooms,
+// devices and nodes nested under them, the nested collections are keyed by
+// the path of the entity they belong to
+type Tree struct {
+	Buildings Buildings
+	Floors    map[string]Floors
+	Rooms     map[string]Rooms
+	Devices   map[string]Devices
+	Nodes     map[string]Nodes
+}
+
+// NewTree returns an empty Tree
+func NewTree() Tree {
+	return Tree{
+		Buildings: Buildings{},
+		Floors:    map[string]Floors{},
+		Rooms:     map[string]Rooms{},
+		Devices:   map[string]Devices{},
+		Nodes:     map[string]Nodes{},
+	}
+}
+
+// FloorsOf returns the floors of the building
+func (tree Tree) FloorsOf(building Entity) Floors {
+	return tree.Floors[building.ID()]
+}
+
+// RoomsOf returns the rooms of the floor
+func (tree Tree) RoomsOf(floor Floor) Rooms {
+	return tree.Rooms[floorKey(floor)]
+}
+
+// DevicesOf returns the devices of the room
+func (tree Tree) DevicesOf(room Room) Devices {
+	return tree.Devices[roomKey(room)]
+}
+
+// NodesOf returns the nodes of the room
+func (tree Tree) NodesOf(room Room) Nodes {
+	return tree.Nodes[roomKey(room)]
+}
+
+// AddFloors associates the floors to the building in the tree
+func (tree Tree) AddFloors(building Entity, floors Floors) {
+	tree.Floors[building.ID()] = floors
+}
+
+// AddRooms associates the rooms to the floor in the tree
+func (tree Tree) AddRooms(floor Floor, rooms Rooms) {
+	tree.Rooms[floorKey(floor)] = rooms
+}
+
+// AddDevices associates the devices to the room in the tree
+func (tree Tree) AddDevices(room Room, devices Devices) {
+	tree.Devices[roomKey(room)] = devices
+}
+
+// AddNodes associates the nodes to the room in the tree
+func (tree Tree) AddNodes(room Room, nodes Nodes) {
+	tree.Nodes[roomKey(room)] = nodes
+}
+
+func floorKey(floor Floor) string {
+	if floor.Building == nil {
+		return floor.ID()
+	}
+	return path.Join(floor.Building.ID(), floor.ID())
+}
+
+func roomKey(room Room) string {
+	return path.Join(floorKey(room.Floor), room.ID())
+}
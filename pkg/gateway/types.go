@@ -2,6 +2,7 @@ package gateway
 
 import (
 	"github.com/gosimple/slug"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/labels"
 )
 
 const (
@@ -35,10 +36,12 @@ type Entity interface {
 	Validate() error
 }
 
-// PhysicalEntity a thing with distinct and independent existence
+// PhysicalEntity a thing with distinct and independent existence, the
+// labels e.g. env=outdoor are used to select the entities
 type PhysicalEntity struct {
-	Name        string `json:"name"`
-	Description string `json:"description"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Labels      map[string]string `json:"labels,omitempty"`
 }
 
 // ID returns slug representing the entity
@@ -46,23 +49,9 @@ func (entity PhysicalEntity) ID() string {
 	return slug.Make(entity.Name)
 }
 
-// Device is a electronic / electrical equipment made or adapted for a particular purpose
-type Device struct {
-	Meta           map[string]string `json:"meta"`
-	PhysicalEntity `json:"entity"`
-}
-
-// Devices collection of device
-type Devices []Device
-
-// NodeMetadata represents information about a node
-type NodeMetadata struct {
-	Building `json:"building"`
-	Floor    `json:"floor"`
-	Room     `json:"room"`
-	Devices  `json:"devices"`
-	Host     string   `json:"host"`
-	Type     NodeType `json:"type"`
+func validateLabels(value interface{}) error {
+	entityLabels, _ := value.(map[string]string)
+	return labels.Validate(entityLabels)
 }
 
 // Node a piece of equipment
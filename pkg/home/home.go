@@ -0,0 +1,391 @@
+package home
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// FormatJSON represents the home model encoded as JSON
+	FormatJSON = "json"
+	// FormatYAML represents the home model encoded as YAML
+	FormatYAML = "yaml"
+)
+
+// Home represents the whole home model, every building along
+// with the floors, rooms and devices nested under it
+type Home struct {
+	Buildings []Building `json:"buildings" yaml:"buildings"`
+}
+
+// Building represents a building in the home model
+type Building struct {
+	Name        string            `json:"name" yaml:"name"`
+	Description string            `json:"description,omitempty" yaml:"description,omitempty"`
+	Latitude    float64           `json:"latitude" yaml:"latitude"`
+	Longitude   float64           `json:"longitude" yaml:"longitude"`
+	Timezone    string            `json:"timezone,omitempty" yaml:"timezone,omitempty"`
+	Tariff      *gateway.Tariff   `json:"tariff,omitempty" yaml:"tariff,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	Floors      []Floor           `json:"floors,omitempty" yaml:"floors,omitempty"`
+}
+
+// Floor represents a floor of a building in the home model
+type Floor struct {
+	Name        string            `json:"name" yaml:"name"`
+	Description string            `json:"description,omitempty" yaml:"description,omitempty"`
+	Level       int               `json:"level" yaml:"level"`
+	Labels      map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	Rooms       []Room            `json:"rooms,omitempty" yaml:"rooms,omitempty"`
+}
+
+// Room represents a room of a floor in the home model
+type Room struct {
+	Name        string            `json:"name" yaml:"name"`
+	Description string            `json:"description,omitempty" yaml:"description,omitempty"`
+	Direction   string            `json:"direction" yaml:"direction"`
+	Labels      map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	Devices     []Device          `json:"devices,omitempty" yaml:"devices,omitempty"`
+	Nodes       []Node            `json:"nodes,omitempty" yaml:"nodes,omitempty"`
+}
+
+// Device represents a device of a room in the home model
+type Device struct {
+	Name        string            `json:"name" yaml:"name"`
+	Description string            `json:"description,omitempty" yaml:"description,omitempty"`
+	Meta        map[string]string `json:"meta,omitempty" yaml:"meta,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+}
+
+// Node represents a node of a room in the home model, Devices
+// refers to the names of the devices of the room it controls
+type Node struct {
+	Name        string   `json:"name" yaml:"name"`
+	Description string   `json:"description,omitempty" yaml:"description,omitempty"`
+	Host        string   `json:"host" yaml:"host"`
+	Type        string   `json:"type" yaml:"type"`
+	Devices     []string `json:"devices,omitempty" yaml:"devices,omitempty"`
+}
+
+// Decode returns Home from data encoded in the given format
+func Decode(data []byte, format string) (Home, error) {
+	home := Home{}
+	var err error
+	switch strings.ToLower(format) {
+	case FormatJSON, "":
+		err = json.Unmarshal(data, &home)
+	case FormatYAML, "yml":
+		err = yaml.Unmarshal(data, &home)
+	default:
+		return Home{}, fmt.Errorf("format %s not supported", format)
+	}
+
+	if err != nil {
+		return Home{}, fmt.Errorf("unable to parse home, %w", err)
+	}
+	return home, nil
+}
+
+// Encode returns the home encoded in the given format
+func Encode(home Home, format string) ([]byte, error) {
+	switch strings.ToLower(format) {
+	case FormatJSON, "":
+		data, err := json.MarshalIndent(home, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(data, '\n'), nil
+	case FormatYAML, "yml":
+		buffer := bytes.Buffer{}
+		encoder := yaml.NewEncoder(&buffer)
+		encoder.SetIndent(2)
+		err := encoder.Encode(home)
+		if err != nil {
+			return nil, err
+		}
+		return buffer.Bytes(), encoder.Close()
+	default:
+		return nil, fmt.Errorf("format %s not supported", format)
+	}
+}
+
+// FormatOf returns the format based on the extension of the file name
+func FormatOf(name string) string {
+	if strings.HasSuffix(name, ".yaml") || strings.HasSuffix(name, ".yml") {
+		return FormatYAML
+	}
+	return FormatJSON
+}
+
+// NewHome converts gateway.Tree into Home ordered by id
+func NewHome(tree gateway.Tree) Home {
+	home := Home{Buildings: []Building{}}
+	for _, id := range sortedKeys(tree.Buildings) {
+		building := tree.Buildings[id]
+		b := newBuilding(building)
+
+		floors := tree.FloorsOf(building)
+		for _, id := range sortedKeys(floors) {
+			floor := floors[id]
+			f := newFloor(floor)
+
+			rooms := tree.RoomsOf(floor)
+			for _, id := range sortedKeys(rooms) {
+				room := rooms[id]
+				r := newRoom(room)
+
+				devices := tree.DevicesOf(room)
+				for _, id := range sortedKeys(devices) {
+					r.Devices = append(r.Devices, newDevice(devices[id]))
+				}
+
+				nodes := tree.NodesOf(room)
+				for _, id := range sortedKeys(nodes) {
+					r.Nodes = append(r.Nodes, newNode(nodes[id]))
+				}
+				f.Rooms = append(f.Rooms, r)
+			}
+			b.Floors = append(b.Floors, f)
+		}
+		home.Buildings = append(home.Buildings, b)
+	}
+	return home
+}
+
+func newBuilding(building gateway.Building) Building {
+	return Building{
+		Name:        building.Name,
+		Description: building.Description,
+		Latitude:    building.Lat,
+		Longitude:   building.Lan,
+		Timezone:    building.Timezone,
+		Tariff:      building.Tariff,
+		Labels:      building.Labels,
+	}
+}
+
+func newFloor(floor gateway.Floor) Floor {
+	return Floor{Name: floor.Name, Description: floor.Description, Level: floor.Level, Labels: floor.Labels}
+}
+
+func newRoom(room gateway.Room) Room {
+	return Room{Name: room.Name, Description: room.Description, Direction: room.Direction.Direction(), Labels: room.Labels}
+}
+
+func newDevice(device gateway.Device) Device {
+	return Device{Name: device.Name, Description: device.Description, Meta: device.Meta, Labels: device.Labels}
+}
+
+func newNode(node gateway.NodeMetadata) Node {
+	return Node{
+		Name:        node.Name,
+		Description: node.Description,
+		Host:        node.Host,
+		Type:        node.Type.NodeType(),
+		Devices:     node.Devices,
+	}
+}
+
+// Tree converts the Home into gateway.Tree, every entity is validated
+// the same way as it is validated when created through the REST API
+func (home Home) Tree() (gateway.Tree, error) {
+	tree := gateway.NewTree()
+	for _, b := range home.Buildings {
+		data, err := json.Marshal(gateway.Building{
+			Lat:            b.Latitude,
+			Lan:            b.Longitude,
+			Timezone:       b.Timezone,
+			Tariff:         b.Tariff,
+			PhysicalEntity: gateway.PhysicalEntity{Name: b.Name, Description: b.Description, Labels: b.Labels},
+		})
+		if err != nil {
+			return gateway.Tree{}, err
+		}
+
+		building, err := gateway.NewBuilding(data)
+		if err != nil {
+			return gateway.Tree{}, fmt.Errorf("building %s: %w", b.Name, err)
+		}
+		if _, ok := tree.Buildings[building.ID()]; ok {
+			return gateway.Tree{}, fmt.Errorf("building %s: defined more than once", b.Name)
+		}
+		tree.Buildings[building.ID()] = building
+
+		floors, err := newFloors(tree, building, b.Floors)
+		if err != nil {
+			return gateway.Tree{}, fmt.Errorf("building %s: %w", b.Name, err)
+		}
+		tree.AddFloors(building, floors)
+	}
+	return tree, nil
+}
+
+func newFloors(tree gateway.Tree, building gateway.Building, floors []Floor) (gateway.Floors, error) {
+	result := gateway.Floors{}
+	for _, f := range floors {
+		data, err := json.Marshal(gateway.Floor{
+			Level:          f.Level,
+			PhysicalEntity: gateway.PhysicalEntity{Name: f.Name, Description: f.Description, Labels: f.Labels},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		floor, err := gateway.NewFloor(building, data)
+		if err != nil {
+			return nil, fmt.Errorf("floor %s: %w", f.Name, err)
+		}
+		if _, ok := result[floor.ID()]; ok {
+			return nil, fmt.Errorf("floor %s: defined more than once", f.Name)
+		}
+		result[floor.ID()] = floor
+
+		rooms, err := newRooms(tree, floor, f.Rooms)
+		if err != nil {
+			return nil, fmt.Errorf("floor %s: %w", f.Name, err)
+		}
+		tree.AddRooms(floor, rooms)
+	}
+	return result, nil
+}
+
+func newRooms(tree gateway.Tree, floor gateway.Floor, rooms []Room) (gateway.Rooms, error) {
+	result := gateway.Rooms{}
+	for _, r := range rooms {
+		direction, err := gateway.NewDirection(r.Direction)
+		if err != nil {
+			return nil, fmt.Errorf("room %s: %w", r.Name, err)
+		}
+
+		data, err := json.Marshal(gateway.Room{
+			Direction:      direction,
+			PhysicalEntity: gateway.PhysicalEntity{Name: r.Name, Description: r.Description, Labels: r.Labels},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		room, err := gateway.NewRoom(floor, data)
+		if err != nil {
+			return nil, fmt.Errorf("room %s: %w", r.Name, err)
+		}
+		if _, ok := result[room.ID()]; ok {
+			return nil, fmt.Errorf("room %s: defined more than once", r.Name)
+		}
+		result[room.ID()] = room
+
+		devices, err := newDevices(room, r.Devices)
+		if err != nil {
+			return nil, fmt.Errorf("room %s: %w", r.Name, err)
+		}
+		tree.AddDevices(room, devices)
+
+		nodes, err := newNodes(room, devices, r.Nodes)
+		if err != nil {
+			return nil, fmt.Errorf("room %s: %w", r.Name, err)
+		}
+		tree.AddNodes(room, nodes)
+	}
+	return result, nil
+}
+
+func newDevices(room gateway.Room, devices []Device) (gateway.Devices, error) {
+	result := gateway.Devices{}
+	for _, d := range devices {
+		data, err := json.Marshal(gateway.Device{
+			Meta:           d.Meta,
+			PhysicalEntity: gateway.PhysicalEntity{Name: d.Name, Description: d.Description, Labels: d.Labels},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		device, err := gateway.NewDevice(room, data)
+		if err != nil {
+			return nil, fmt.Errorf("device %s: %w", d.Name, err)
+		}
+		if _, ok := result[device.ID()]; ok {
+			return nil, fmt.Errorf("device %s: defined more than once", d.Name)
+		}
+		result[device.ID()] = device
+	}
+	return result, nil
+}
+
+func newNodes(room gateway.Room, devices gateway.Devices, nodes []Node) (gateway.Nodes, error) {
+	result := gateway.Nodes{}
+	for _, n := range nodes {
+		nodeType := gateway.NodeTypeWifi
+		if n.Type != "" {
+			var err error
+			nodeType, err = gateway.NewNodeType(n.Type)
+			if err != nil {
+				return nil, fmt.Errorf("node %s: %w", n.Name, err)
+			}
+		}
+
+		ids := make([]string, 0, len(n.Devices))
+		for _, name := range n.Devices {
+			id := gateway.PhysicalEntity{Name: name}.ID()
+			if _, ok := devices[id]; !ok {
+				return nil, fmt.Errorf("node %s: device %s not found in the room", n.Name, name)
+			}
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+
+		data, err := json.Marshal(gateway.NodeMetadata{
+			Devices:        ids,
+			Host:           n.Host,
+			Type:           nodeType,
+			PhysicalEntity: gateway.PhysicalEntity{Name: n.Name, Description: n.Description},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		node, err := gateway.NewNode(room, data)
+		if err != nil {
+			return nil, fmt.Errorf("node %s: %w", n.Name, err)
+		}
+		if _, ok := result[node.ID()]; ok {
+			return nil, fmt.Errorf("node %s: defined more than once", n.Name)
+		}
+		result[node.ID()] = node
+	}
+	return result, nil
+}
+
+func sortedKeys(collection interface{}) []string {
+	var keys []string
+	switch items := collection.(type) {
+	case gateway.Buildings:
+		for key := range items {
+			keys = append(keys, key)
+		}
+	case gateway.Floors:
+		for key := range items {
+			keys = append(keys, key)
+		}
+	case gateway.Rooms:
+		for key := range items {
+			keys = append(keys, key)
+		}
+	case gateway.Devices:
+		for key := range items {
+			keys = append(keys, key)
+		}
+	case gateway.Nodes:
+		for key := range items {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,138 @@
+package home_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/home"
+)
+
+func newHome() home.Home {
+	return home.Home{Buildings: []home.Building{{
+		Name:      "building-one",
+		Latitude:  12.97,
+		Longitude: 77.59,
+		Floors: []home.Floor{{
+			Name:  "floor-one",
+			Level: 1,
+			Rooms: []home.Room{{
+				Name:      "room-one",
+				Direction: "north",
+				Devices: []home.Device{{
+					Name: "porch-light",
+					Meta: map[string]string{"pin": "4"},
+				}},
+				Nodes: []home.Node{{
+					Name:    "node-one",
+					Host:    "192.168.1.20",
+					Type:    "mqtt",
+					Devices: []string{"porch-light"},
+				}},
+			}},
+		}},
+	}}}
+}
+
+func TestDecode(t *testing.T) {
+	t.Run("should round trip the home as json and yaml", func(t *testing.T) {
+		for _, format := range []string{home.FormatJSON, home.FormatYAML} {
+			data, err := home.Encode(newHome(), format)
+			if !assert.NoError(t, err) {
+				continue
+			}
+
+			decoded, err := home.Decode(data, format)
+
+			if assert.NoError(t, err) {
+				assert.Equal(t, newHome(), decoded)
+			}
+		}
+	})
+
+	t.Run("should fail for unsupported format", func(t *testing.T) {
+		_, err := home.Decode([]byte("{}"), "xml")
+
+		assert.EqualError(t, err, "format xml not supported")
+	})
+
+	t.Run("should fail for malformed data", func(t *testing.T) {
+		_, err := home.Decode([]byte("{"), home.FormatJSON)
+
+		assert.EqualError(t, err, "unable to parse home, unexpected end of JSON input")
+	})
+}
+
+func TestFormatOf(t *testing.T) {
+	assert.Equal(t, home.FormatYAML, home.FormatOf("home.yaml"))
+	assert.Equal(t, home.FormatYAML, home.FormatOf("home.yml"))
+	assert.Equal(t, home.FormatJSON, home.FormatOf("home.json"))
+}
+
+func TestHome_Tree(t *testing.T) {
+	t.Run("should convert the home into tree", func(t *testing.T) {
+		tree, err := newHome().Tree()
+
+		if assert.NoError(t, err) {
+			building := tree.Buildings["building-one"]
+			assert.Equal(t, 12.97, building.Lat)
+			floor := tree.FloorsOf(building)["floor-one"]
+			assert.Equal(t, 1, floor.Level)
+			room := tree.RoomsOf(floor)["room-one"]
+			assert.Equal(t, gateway.DirectionNorth, room.Direction)
+			assert.Equal(t, map[string]string{"pin": "4"}, tree.DevicesOf(room)["porch-light"].Meta)
+			assert.Equal(t, gateway.NodeTypeMqtt, tree.NodesOf(room)["node-one"].Type)
+		}
+	})
+
+	t.Run("should fail with the path of the invalid entity", func(t *testing.T) {
+		h := newHome()
+		h.Buildings[0].Floors[0].Rooms[0].Devices[0].Name = "tv"
+
+		_, err := h.Tree()
+
+		assert.EqualError(t, err, "building building-one: floor floor-one: room room-one: device tv: name: the length must be between 5 and 50.")
+	})
+
+	t.Run("should fail for invalid direction", func(t *testing.T) {
+		h := newHome()
+		h.Buildings[0].Floors[0].Rooms[0].Direction = "up"
+
+		_, err := h.Tree()
+
+		assert.EqualError(t, err, "building building-one: floor floor-one: room room-one: direction up not supported")
+	})
+
+	t.Run("should fail when node refers to unknown device", func(t *testing.T) {
+		h := newHome()
+		h.Buildings[0].Floors[0].Rooms[0].Nodes[0].Devices = []string{"ceiling-fan"}
+
+		_, err := h.Tree()
+
+		assert.EqualError(t, err, "building building-one: floor floor-one: room room-one: node node-one: device ceiling-fan not found in the room")
+	})
+
+	t.Run("should fail for duplicate entities", func(t *testing.T) {
+		h := newHome()
+		h.Buildings = append(h.Buildings, h.Buildings[0])
+
+		_, err := h.Tree()
+
+		assert.EqualError(t, err, "building building-one: defined more than once")
+	})
+}
+
+func TestNewHome(t *testing.T) {
+	t.Run("should convert the tree back into home", func(t *testing.T) {
+		tree, err := newHome().Tree()
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		assert.Equal(t, newHome(), home.NewHome(tree))
+	})
+
+	t.Run("should return empty buildings for empty tree", func(t *testing.T) {
+		assert.Equal(t, home.Home{Buildings: []home.Building{}}, home.NewHome(gateway.NewTree()))
+	})
+}
@@ -0,0 +1,420 @@
+package home
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/audit"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/store"
+)
+
+// Mode decides how the imported home is reconciled with the store
+type Mode string
+
+const (
+	// ModeMerge creates or updates the imported entities and leaves
+	// the entities missing from the import untouched
+	ModeMerge Mode = "merge"
+	// ModeReplace makes the store identical to the import, entities
+	// missing from the import are deleted
+	ModeReplace Mode = "replace"
+)
+
+// NewMode converts string mode as Mode, empty string defaults to ModeMerge
+func NewMode(mode string) (Mode, error) {
+	switch Mode(mode) {
+	case ModeMerge, "":
+		return ModeMerge, nil
+	case ModeReplace:
+		return ModeReplace, nil
+	default:
+		return "", fmt.Errorf("mode %s not supported, should be one of %s or %s", mode, ModeMerge, ModeReplace)
+	}
+}
+
+// Action represents the change made to an entity
+type Action string
+
+const (
+	// ActionCreate the entity does not exist in the store
+	ActionCreate Action = "create"
+	// ActionUpdate the entity exists in the store and differs from the import
+	ActionUpdate Action = "update"
+	// ActionDelete the entity exists in the store but not in the import
+	ActionDelete Action = "delete"
+)
+
+const (
+	kindBuilding = "building"
+	kindFloor    = "floor"
+	kindRoom     = "room"
+	kindDevice   = "device"
+	kindNode     = "node"
+)
+
+// Change represents a single entity that is created, updated or deleted,
+// Fields lists the fields which differ for an update
+type Change struct {
+	Action Action   `json:"action" yaml:"action"`
+	Kind   string   `json:"kind" yaml:"kind"`
+	Path   string   `json:"path" yaml:"path"`
+	Fields []string `json:"fields,omitempty" yaml:"fields,omitempty"`
+}
+
+// Plan represents the changes required to reconcile the store with the import
+type Plan struct {
+	Mode    Mode     `json:"mode" yaml:"mode"`
+	DryRun  bool     `json:"dryRun" yaml:"dryRun"`
+	Changes []Change `json:"changes" yaml:"changes"`
+	current gateway.Tree
+	desired gateway.Tree
+}
+
+// NewPlan returns the changes required to turn current into desired
+func NewPlan(current, desired gateway.Tree, mode Mode) Plan {
+	plan := Plan{Mode: mode, Changes: []Change{}, current: current, desired: desired}
+	for id, building := range desired.Buildings {
+		existing, ok := current.Buildings[id]
+		plan.compare(kindBuilding, id, newBuilding(building), newBuilding(existing), ok)
+
+		for id, floor := range desired.FloorsOf(building) {
+			existing, ok := current.FloorsOf(building)[id]
+			plan.compare(kindFloor, path.Join(building.ID(), id), newFloor(floor), newFloor(existing), ok)
+
+			for id, room := range desired.RoomsOf(floor) {
+				roomPath := path.Join(building.ID(), floor.ID(), id)
+				existing, ok := current.RoomsOf(floor)[id]
+				plan.compare(kindRoom, roomPath, newRoom(room), newRoom(existing), ok)
+
+				for id, device := range desired.DevicesOf(room) {
+					existing, ok := current.DevicesOf(room)[id]
+					plan.compare(kindDevice, path.Join(roomPath, id), newDevice(device), newDevice(existing), ok)
+				}
+
+				for id, node := range desired.NodesOf(room) {
+					existing, ok := current.NodesOf(room)[id]
+					plan.compare(kindNode, path.Join(roomPath, id), newNode(node), newNode(existing), ok)
+				}
+			}
+		}
+	}
+
+	if mode == ModeReplace {
+		plan.deletions()
+	}
+
+	sort.Slice(plan.Changes, func(i, j int) bool {
+		if plan.Changes[i].Path == plan.Changes[j].Path {
+			return plan.Changes[i].Kind < plan.Changes[j].Kind
+		}
+		return plan.Changes[i].Path < plan.Changes[j].Path
+	})
+	return plan
+}
+
+// Count returns the number of changes for the action
+func (plan Plan) Count(action Action) int {
+	count := 0
+	for _, change := range plan.Changes {
+		if change.Action == action {
+			count++
+		}
+	}
+	return count
+}
+
+// String returns the human readable representation of the plan, one
+// line per change followed by the summary
+func (plan Plan) String() string {
+	if len(plan.Changes) == 0 {
+		return "No changes, the store matches the configuration.\n"
+	}
+
+	symbols := map[Action]string{ActionCreate: "+", ActionUpdate: "~", ActionDelete: "-"}
+	builder := strings.Builder{}
+	for _, change := range plan.Changes {
+		fmt.Fprintf(&builder, "%s %-8s %s", symbols[change.Action], change.Kind, change.Path)
+		if len(change.Fields) > 0 {
+			fmt.Fprintf(&builder, " (%s)", strings.Join(change.Fields, ", "))
+		}
+		builder.WriteString("\n")
+	}
+	fmt.Fprintf(&builder, "\n%d to create, %d to update, %d to delete.\n",
+		plan.Count(ActionCreate), plan.Count(ActionUpdate), plan.Count(ActionDelete))
+	return builder.String()
+}
+
+// Apply persists the plan in the store, entities are deleted first so
+// that a replaced subtree does not leave stale nested keys behind.
+// Applying the same plan again leaves the store unchanged
+func (plan Plan) Apply(s store.Store) error {
+	if plan.Mode == ModeReplace {
+		err := plan.applyDeletions(s)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(plan.Changes) == 0 {
+		return nil
+	}
+
+	buildings := plan.merge(plan.current.Buildings, plan.desired.Buildings).(gateway.Buildings)
+	err := s.UpsertBuildings(buildings)
+	if err != nil {
+		return err
+	}
+
+	for _, building := range plan.desired.Buildings {
+		floors := plan.merge(plan.current.FloorsOf(building), plan.desired.FloorsOf(building)).(gateway.Floors)
+		err := s.UpsertFloors(building, floors)
+		if err != nil {
+			return err
+		}
+
+		for _, floor := range plan.desired.FloorsOf(building) {
+			rooms := plan.merge(plan.current.RoomsOf(floor), plan.desired.RoomsOf(floor)).(gateway.Rooms)
+			err := s.UpsertRooms(floor, rooms)
+			if err != nil {
+				return err
+			}
+
+			for _, room := range plan.desired.RoomsOf(floor) {
+				err := plan.applyRoom(s, room)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (plan Plan) applyRoom(s store.Store, room gateway.Room) error {
+	devices := plan.merge(plan.current.DevicesOf(room), plan.desired.DevicesOf(room)).(gateway.Devices)
+	err := s.UpsertDevices(room, devices)
+	if err != nil {
+		return err
+	}
+
+	nodes := plan.merge(plan.current.NodesOf(room), plan.desired.NodesOf(room)).(gateway.Nodes)
+	return s.UpsertNodes(room, nodes)
+}
+
+func (plan *Plan) compare(kind, id string, desired, current interface{}, exists bool) {
+	if !exists {
+		plan.Changes = append(plan.Changes, Change{Action: ActionCreate, Kind: kind, Path: id})
+		return
+	}
+
+	fields := changedFields(desired, current)
+	if len(fields) > 0 {
+		plan.Changes = append(plan.Changes, Change{Action: ActionUpdate, Kind: kind, Path: id, Fields: fields})
+	}
+}
+
+func (plan *Plan) deleted(kind string, elem ...string) {
+	plan.Changes = append(plan.Changes, Change{Action: ActionDelete, Kind: kind, Path: path.Join(elem...)})
+}
+
+// Audit returns the audit entries of the changes made by the actor at the
+// time, an entry per entity along with its state before and after. The
+// entries are a nanosecond apart so that they are listed in the order of
+// the changes
+func (plan Plan) Audit(now time.Time, actor audit.Actor, method, route string) ([]audit.Entry, error) {
+	entries := make([]audit.Entry, 0, len(plan.Changes))
+	for i, change := range plan.Changes {
+		entry, err := audit.NewEntry(now.Add(time.Duration(i)), actor, method, route, change.Path, http.StatusOK)
+		if err != nil {
+			return nil, err
+		}
+		err = entry.SetState(entityOf(plan.current, change), entityOf(plan.desired, change))
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// entityOf returns the entity of the change in the tree, nil when the tree
+// does not have it
+func entityOf(tree gateway.Tree, change Change) interface{} {
+	ids := strings.Split(change.Path, "/")
+	building, ok := tree.Buildings[ids[0]]
+	if !ok {
+		return nil
+	} else if change.Kind == kindBuilding {
+		return newBuilding(building)
+	}
+	floor, ok := tree.FloorsOf(building)[ids[1]]
+	if !ok {
+		return nil
+	} else if change.Kind == kindFloor {
+		return newFloor(floor)
+	}
+	room, ok := tree.RoomsOf(floor)[ids[2]]
+	if !ok {
+		return nil
+	} else if change.Kind == kindRoom {
+		return newRoom(room)
+	}
+	if change.Kind == kindNode {
+		if node, ok := tree.NodesOf(room)[ids[3]]; ok {
+			return newNode(node)
+		}
+		return nil
+	}
+	if device, ok := tree.DevicesOf(room)[ids[3]]; ok {
+		return newDevice(device)
+	}
+	return nil
+}
+
+// deletions records the entities that exist only in the store, nested
+// entities of a deleted entity are deleted along with it
+func (plan *Plan) deletions() {
+	for id, building := range plan.current.Buildings {
+		if _, ok := plan.desired.Buildings[id]; !ok {
+			plan.deleted(kindBuilding, id)
+			continue
+		}
+
+		for id, floor := range plan.current.FloorsOf(building) {
+			if _, ok := plan.desired.FloorsOf(building)[id]; !ok {
+				plan.deleted(kindFloor, building.ID(), id)
+				continue
+			}
+
+			for id, room := range plan.current.RoomsOf(floor) {
+				if _, ok := plan.desired.RoomsOf(floor)[id]; !ok {
+					plan.deleted(kindRoom, building.ID(), floor.ID(), id)
+					continue
+				}
+
+				for id := range plan.current.DevicesOf(room) {
+					if _, ok := plan.desired.DevicesOf(room)[id]; !ok {
+						plan.deleted(kindDevice, building.ID(), floor.ID(), room.ID(), id)
+					}
+				}
+
+				for id := range plan.current.NodesOf(room) {
+					if _, ok := plan.desired.NodesOf(room)[id]; !ok {
+						plan.deleted(kindNode, building.ID(), floor.ID(), room.ID(), id)
+					}
+				}
+			}
+		}
+	}
+}
+
+func (plan Plan) applyDeletions(s store.Store) error {
+	for id, building := range plan.current.Buildings {
+		if _, ok := plan.desired.Buildings[id]; !ok {
+			err := s.DeleteBuilding(building)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		for id, floor := range plan.current.FloorsOf(building) {
+			if _, ok := plan.desired.FloorsOf(building)[id]; !ok {
+				err := s.DeleteFloor(floor)
+				if err != nil {
+					return err
+				}
+				continue
+			}
+
+			for id, room := range plan.current.RoomsOf(floor) {
+				if _, ok := plan.desired.RoomsOf(floor)[id]; !ok {
+					err := s.DeleteRoom(room)
+					if err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// merge returns the collection to persist, the collections are maps of
+// the entities keyed by id e.g. gateway.Floors, and the entities of the
+// desired collection replace the current ones having the same id. In
+// replace mode the desired collection is persisted as is which also drops
+// the deleted devices and nodes
+func (plan Plan) merge(current, desired interface{}) interface{} {
+	collections := []reflect.Value{reflect.ValueOf(desired)}
+	if plan.Mode == ModeMerge {
+		collections = append([]reflect.Value{reflect.ValueOf(current)}, collections...)
+	}
+
+	result := reflect.MakeMap(collections[0].Type())
+	for _, collection := range collections {
+		entities := collection.MapRange()
+		for entities.Next() {
+			result.SetMapIndex(entities.Key(), entities.Value())
+		}
+	}
+	return result.Interface()
+}
+
+// changedFields returns the yaml name of the fields which differ between
+// the home model values, nested entities are compared on their own
+func changedFields(desired, current interface{}) []string {
+	d := reflect.ValueOf(desired)
+	c := reflect.ValueOf(current)
+
+	var fields []string
+	for i := 0; i < d.NumField(); i++ {
+		field := d.Type().Field(i)
+		if field.Type.Kind() == reflect.Slice && field.Type.Elem().Kind() == reflect.Struct {
+			continue
+		}
+
+		x, y := d.Field(i), c.Field(i)
+		if x.Kind() == reflect.Slice || x.Kind() == reflect.Map {
+			if x.Len() == 0 && y.Len() == 0 {
+				continue
+			}
+		}
+
+		if !reflect.DeepEqual(x.Interface(), y.Interface()) {
+			fields = append(fields, strings.Split(field.Tag.Get("yaml"), ",")[0])
+		}
+	}
+	return fields
+}
+
+// Import reconciles the store with the desired tree using the mode,
+// the store is left untouched when dryRun is true
+func Import(s store.Store, desired gateway.Tree, mode Mode, dryRun bool) (Plan, error) {
+	current, err := s.Tree()
+	if err != nil {
+		return Plan{}, err
+	}
+
+	plan := NewPlan(current, desired, mode)
+	plan.DryRun = dryRun
+	if dryRun {
+		return plan, nil
+	}
+	return plan, plan.Apply(s)
+}
+
+// Export returns the home persisted in the store
+func Export(s store.Store) (Home, error) {
+	tree, err := s.Tree()
+	if err != nil {
+		return Home{}, err
+	}
+	return NewHome(tree), nil
+}
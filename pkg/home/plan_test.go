@@ -0,0 +1,235 @@
+package home_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/audit"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/home"
+	mockStore "gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/internal/mocks/store"
+)
+
+func newTree(t *testing.T, h home.Home) gateway.Tree {
+	tree, err := h.Tree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tree
+}
+
+func TestNewMode(t *testing.T) {
+	mode, err := home.NewMode("")
+	assert.NoError(t, err)
+	assert.Equal(t, home.ModeMerge, mode)
+
+	mode, err = home.NewMode("replace")
+	assert.NoError(t, err)
+	assert.Equal(t, home.ModeReplace, mode)
+
+	_, err = home.NewMode("sync")
+	assert.EqualError(t, err, "mode sync not supported, should be one of merge or replace")
+}
+
+func TestNewPlan(t *testing.T) {
+	t.Run("should create every entity for an empty store", func(t *testing.T) {
+		plan := home.NewPlan(gateway.NewTree(), newTree(t, newHome()), home.ModeMerge)
+
+		assert.Equal(t, []home.Change{
+			{Action: home.ActionCreate, Kind: "building", Path: "building-one"},
+			{Action: home.ActionCreate, Kind: "floor", Path: "building-one/floor-one"},
+			{Action: home.ActionCreate, Kind: "room", Path: "building-one/floor-one/room-one"},
+			{Action: home.ActionCreate, Kind: "node", Path: "building-one/floor-one/room-one/node-one"},
+			{Action: home.ActionCreate, Kind: "device", Path: "building-one/floor-one/room-one/porch-light"},
+		}, plan.Changes)
+		assert.Equal(t, 5, plan.Count(home.ActionCreate))
+	})
+
+	t.Run("should report no changes for identical tree", func(t *testing.T) {
+		plan := home.NewPlan(newTree(t, newHome()), newTree(t, newHome()), home.ModeReplace)
+
+		assert.Empty(t, plan.Changes)
+	})
+
+	t.Run("should report updates and keep missing entities when merging", func(t *testing.T) {
+		current := newHome()
+		current.Buildings = append(current.Buildings, home.Building{Name: "building-two", Latitude: 1, Longitude: 1})
+		desired := newHome()
+		desired.Buildings[0].Floors[0].Rooms[0].Description = "living"
+
+		plan := home.NewPlan(newTree(t, current), newTree(t, desired), home.ModeMerge)
+
+		assert.Equal(t, []home.Change{
+			{Action: home.ActionUpdate, Kind: "room", Path: "building-one/floor-one/room-one", Fields: []string{"description"}},
+		}, plan.Changes)
+	})
+
+	t.Run("should delete missing entities when replacing", func(t *testing.T) {
+		current := newHome()
+		current.Buildings = append(current.Buildings, home.Building{Name: "building-two", Latitude: 1, Longitude: 1})
+		desired := newHome()
+		desired.Buildings[0].Floors[0].Rooms[0].Devices = nil
+		desired.Buildings[0].Floors[0].Rooms[0].Nodes = nil
+
+		plan := home.NewPlan(newTree(t, current), newTree(t, desired), home.ModeReplace)
+
+		assert.Equal(t, []home.Change{
+			{Action: home.ActionDelete, Kind: "node", Path: "building-one/floor-one/room-one/node-one"},
+			{Action: home.ActionDelete, Kind: "device", Path: "building-one/floor-one/room-one/porch-light"},
+			{Action: home.ActionDelete, Kind: "building", Path: "building-two"},
+		}, plan.Changes)
+	})
+}
+
+func TestPlan_String(t *testing.T) {
+	t.Run("should list every change followed by the summary", func(t *testing.T) {
+		current := newHome()
+		current.Buildings = append(current.Buildings, home.Building{Name: "building-two", Latitude: 1, Longitude: 1})
+		desired := newHome()
+		desired.Buildings[0].Latitude = 13.01
+		desired.Buildings[0].Floors[0].Rooms[0].Nodes[0].Host = "192.168.1.21"
+
+		plan := home.NewPlan(newTree(t, current), newTree(t, desired), home.ModeReplace)
+
+		assert.Equal(t, `~ building building-one (latitude)
+~ node     building-one/floor-one/room-one/node-one (host)
+- building building-two
+
+0 to create, 2 to update, 1 to delete.
+`, plan.String())
+	})
+
+	t.Run("should report when there are no changes", func(t *testing.T) {
+		plan := home.NewPlan(newTree(t, newHome()), newTree(t, newHome()), home.ModeMerge)
+
+		assert.Equal(t, "No changes, the store matches the configuration.\n", plan.String())
+	})
+}
+
+func TestPlan_Audit(t *testing.T) {
+	current := newHome()
+	current.Buildings = append(current.Buildings, home.Building{Name: "building-two", Latitude: 1, Longitude: 1})
+	desired := newHome()
+	desired.Buildings[0].Floors[0].Rooms[0].Nodes[0].Host = "192.168.1.21"
+	plan := home.NewPlan(newTree(t, current), newTree(t, desired), home.ModeReplace)
+	now := time.Date(2026, 10, 19, 3, 0, 0, 0, time.UTC)
+
+	entries, err := plan.Audit(now, audit.Actor{Name: "editor", Kind: "user"}, "POST", "/v1/import")
+
+	if assert.NoError(t, err) && assert.Len(t, entries, 2) {
+		update, deletion := entries[0], entries[1]
+		assert.Equal(t, "building-one/floor-one/room-one/node-one", update.Entity)
+		assert.Equal(t, "editor", update.Actor.Name)
+		assert.Equal(t, audit.Success, update.Outcome)
+		assert.Equal(t, []audit.Change{{Path: "host", Before: json.RawMessage(`"192.168.1.20"`), After: json.RawMessage(`"192.168.1.21"`)}}, update.Diff)
+
+		assert.Equal(t, "building-two", deletion.Entity)
+		assert.Contains(t, string(deletion.Before), `"name":"building-two"`)
+		assert.Nil(t, deletion.After)
+		assert.Less(t, update.ID, deletion.ID)
+	}
+}
+
+func TestImport(t *testing.T) {
+	t.Run("should not touch the store for dry run", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		store := mockStore.NewMockStore(ctrl)
+		store.EXPECT().Tree().Return(gateway.NewTree(), nil)
+
+		plan, err := home.Import(store, newTree(t, newHome()), home.ModeMerge, true)
+
+		if assert.NoError(t, err) {
+			assert.True(t, plan.DryRun)
+			assert.Equal(t, 5, plan.Count(home.ActionCreate))
+		}
+	})
+
+	t.Run("should upsert the merged collections", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		desired := newTree(t, newHome())
+		building := desired.Buildings["building-one"]
+		floor := desired.FloorsOf(building)["floor-one"]
+		room := desired.RoomsOf(floor)["room-one"]
+		other := gateway.Building{Lat: 1, Lan: 1, PhysicalEntity: gateway.PhysicalEntity{Name: "building-two"}}
+
+		current := gateway.NewTree()
+		current.Buildings[other.ID()] = other
+
+		store := mockStore.NewMockStore(ctrl)
+		store.EXPECT().Tree().Return(current, nil)
+		store.EXPECT().UpsertBuildings(gateway.Buildings{building.ID(): building, other.ID(): other}).Return(nil)
+		store.EXPECT().UpsertFloors(building, desired.FloorsOf(building)).Return(nil)
+		store.EXPECT().UpsertRooms(floor, desired.RoomsOf(floor)).Return(nil)
+		store.EXPECT().UpsertDevices(room, desired.DevicesOf(room)).Return(nil)
+		store.EXPECT().UpsertNodes(room, desired.NodesOf(room)).Return(nil)
+
+		_, err := home.Import(store, newTree(t, newHome()), home.ModeMerge, false)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("should delete the missing entities before upserting when replacing", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		desired := newTree(t, newHome())
+		building := desired.Buildings["building-one"]
+		floor := desired.FloorsOf(building)["floor-one"]
+		room := desired.RoomsOf(floor)["room-one"]
+		other := gateway.Building{Lat: 1, Lan: 1, PhysicalEntity: gateway.PhysicalEntity{Name: "building-two"}}
+
+		current := gateway.NewTree()
+		current.Buildings[other.ID()] = other
+
+		store := mockStore.NewMockStore(ctrl)
+		store.EXPECT().Tree().Return(current, nil)
+		gomock.InOrder(
+			store.EXPECT().DeleteBuilding(other).Return(nil),
+			store.EXPECT().UpsertBuildings(gateway.Buildings{building.ID(): building}).Return(nil),
+		)
+		store.EXPECT().UpsertFloors(building, desired.FloorsOf(building)).Return(nil)
+		store.EXPECT().UpsertRooms(floor, desired.RoomsOf(floor)).Return(nil)
+		store.EXPECT().UpsertDevices(room, desired.DevicesOf(room)).Return(nil)
+		store.EXPECT().UpsertNodes(room, desired.NodesOf(room)).Return(nil)
+
+		plan, err := home.Import(store, newTree(t, newHome()), home.ModeReplace, false)
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, 1, plan.Count(home.ActionDelete))
+		}
+	})
+
+	t.Run("should fail when store fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		store := mockStore.NewMockStore(ctrl)
+		store.EXPECT().Tree().Return(gateway.Tree{}, errors.New("store failed"))
+
+		_, err := home.Import(store, newTree(t, newHome()), home.ModeMerge, false)
+
+		assert.EqualError(t, err, "store failed")
+	})
+}
+
+func TestExport(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockStore.NewMockStore(ctrl)
+	store.EXPECT().Tree().Return(newTree(t, newHome()), nil)
+
+	exported, err := home.Export(store)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, newHome(), exported)
+	}
+}
@@ -72,3 +72,43 @@ func (mr *MockServerMockRecorder) Path(route interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Path", reflect.TypeOf((*MockServer)(nil).Path), route)
 }
+
+// Group mocks base method
+func (m *MockServer) Group(prefix string) server.Server {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Group", prefix)
+	ret0, _ := ret[0].(server.Server)
+	return ret0
+}
+
+// Group indicates an expected call of Group
+func (mr *MockServerMockRecorder) Group(prefix interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Group", reflect.TypeOf((*MockServer)(nil).Group), prefix)
+}
+
+// Deprecated mocks base method
+func (m *MockServer) Deprecated(successor string) server.Server {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Deprecated", successor)
+	ret0, _ := ret[0].(server.Server)
+	return ret0
+}
+
+// Deprecated indicates an expected call of Deprecated
+func (mr *MockServerMockRecorder) Deprecated(successor interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Deprecated", reflect.TypeOf((*MockServer)(nil).Deprecated), successor)
+}
+
+// Authenticate mocks base method
+func (m *MockServer) Authenticate(authenticator server.Authenticator) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Authenticate", authenticator)
+}
+
+// Authenticate indicates an expected call of Authenticate
+func (mr *MockServerMockRecorder) Authenticate(authenticator interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authenticate", reflect.TypeOf((*MockServer)(nil).Authenticate), authenticator)
+}
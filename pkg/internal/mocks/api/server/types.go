@@ -5,6 +5,7 @@ package mockServer
 
 import (
 	gomock "github.com/golang/mock/gomock"
+	fasthttp "github.com/valyala/fasthttp"
 	reflect "reflect"
 )
 
@@ -64,8 +65,64 @@ func (mr *MockRequestContextMockRecorder) PostBody() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostBody", reflect.TypeOf((*MockRequestContext)(nil).PostBody))
 }
 
+// RequestHeader mocks base method
+func (m *MockRequestContext) RequestHeader(key string) []byte {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestHeader", key)
+	ret0, _ := ret[0].([]byte)
+	return ret0
+}
+
+// RequestHeader indicates an expected call of RequestHeader
+func (mr *MockRequestContextMockRecorder) RequestHeader(key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestHeader", reflect.TypeOf((*MockRequestContext)(nil).RequestHeader), key)
+}
+
+// Method mocks base method
+func (m *MockRequestContext) Method() []byte {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Method")
+	ret0, _ := ret[0].([]byte)
+	return ret0
+}
+
+// Method indicates an expected call of Method
+func (mr *MockRequestContextMockRecorder) Method() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Method", reflect.TypeOf((*MockRequestContext)(nil).Method))
+}
+
+// Path mocks base method
+func (m *MockRequestContext) Path() []byte {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Path")
+	ret0, _ := ret[0].([]byte)
+	return ret0
+}
+
+// Path indicates an expected call of Path
+func (mr *MockRequestContextMockRecorder) Path() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Path", reflect.TypeOf((*MockRequestContext)(nil).Path))
+}
+
+// QueryArgs mocks base method
+func (m *MockRequestContext) QueryArgs() *fasthttp.Args {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QueryArgs")
+	ret0, _ := ret[0].(*fasthttp.Args)
+	return ret0
+}
+
+// QueryArgs indicates an expected call of QueryArgs
+func (mr *MockRequestContextMockRecorder) QueryArgs() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryArgs", reflect.TypeOf((*MockRequestContext)(nil).QueryArgs))
+}
+
 // UserValue mocks base method
-func (m *MockRequestContext) UserValue(key string) interface{} {
+func (m *MockRequestContext) UserValue(key interface{}) interface{} {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "UserValue", key)
 	ret0, _ := ret[0].(interface{})
@@ -102,6 +159,18 @@ func (mr *MockRequestContextMockRecorder) SetBodyString(body interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetBodyString", reflect.TypeOf((*MockRequestContext)(nil).SetBodyString), body)
 }
 
+// SetContentType mocks base method
+func (m *MockRequestContext) SetContentType(contentType string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetContentType", contentType)
+}
+
+// SetContentType indicates an expected call of SetContentType
+func (mr *MockRequestContextMockRecorder) SetContentType(contentType interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetContentType", reflect.TypeOf((*MockRequestContext)(nil).SetContentType), contentType)
+}
+
 // Next mocks base method
 func (m *MockRequestContext) Next() error {
 	m.ctrl.T.Helper()
@@ -117,7 +186,7 @@ func (mr *MockRequestContextMockRecorder) Next() *gomock.Call {
 }
 
 // SetUserValue mocks base method
-func (m *MockRequestContext) SetUserValue(key string, value interface{}) {
+func (m *MockRequestContext) SetUserValue(key, value interface{}) {
 	m.ctrl.T.Helper()
 	m.ctrl.Call(m, "SetUserValue", key, value)
 }
@@ -127,3 +196,31 @@ func (mr *MockRequestContextMockRecorder) SetUserValue(key, value interface{}) *
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserValue", reflect.TypeOf((*MockRequestContext)(nil).SetUserValue), key, value)
 }
+
+// ResponseStatusCode mocks base method
+func (m *MockRequestContext) ResponseStatusCode() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResponseStatusCode")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// ResponseStatusCode indicates an expected call of ResponseStatusCode
+func (mr *MockRequestContextMockRecorder) ResponseStatusCode() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResponseStatusCode", reflect.TypeOf((*MockRequestContext)(nil).ResponseStatusCode))
+}
+
+// ResponseBody mocks base method
+func (m *MockRequestContext) ResponseBody() []byte {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResponseBody")
+	ret0, _ := ret[0].([]byte)
+	return ret0
+}
+
+// ResponseBody indicates an expected call of ResponseBody
+func (mr *MockRequestContextMockRecorder) ResponseBody() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResponseBody", reflect.TypeOf((*MockRequestContext)(nil).ResponseBody))
+}
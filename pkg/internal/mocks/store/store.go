@@ -5,7 +5,11 @@ package mockStore
 
 import (
 	gomock "github.com/golang/mock/gomock"
+	audit "gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/audit"
+	auth "gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/auth"
 	gateway "gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+	labels "gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/labels"
+	search "gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/search"
 	reflect "reflect"
 )
 
@@ -203,6 +207,406 @@ func (mr *MockStoreMockRecorder) DeleteRoom(room interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRoom", reflect.TypeOf((*MockStore)(nil).DeleteRoom), room)
 }
 
+// Devices mocks base method
+func (m *MockStore) Devices(room gateway.Room) (gateway.Devices, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Devices", room)
+	ret0, _ := ret[0].(gateway.Devices)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Devices indicates an expected call of Devices
+func (mr *MockStoreMockRecorder) Devices(room interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Devices", reflect.TypeOf((*MockStore)(nil).Devices), room)
+}
+
+// UpsertDevices mocks base method
+func (m *MockStore) UpsertDevices(room gateway.Room, devices gateway.Devices) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertDevices", room, devices)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertDevices indicates an expected call of UpsertDevices
+func (mr *MockStoreMockRecorder) UpsertDevices(room, devices interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertDevices", reflect.TypeOf((*MockStore)(nil).UpsertDevices), room, devices)
+}
+
+// UpsertDevice mocks base method
+func (m *MockStore) UpsertDevice(device gateway.Device) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertDevice", device)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertDevice indicates an expected call of UpsertDevice
+func (mr *MockStoreMockRecorder) UpsertDevice(device interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertDevice", reflect.TypeOf((*MockStore)(nil).UpsertDevice), device)
+}
+
+// DeleteDevice mocks base method
+func (m *MockStore) DeleteDevice(device gateway.Device) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteDevice", device)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteDevice indicates an expected call of DeleteDevice
+func (mr *MockStoreMockRecorder) DeleteDevice(device interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteDevice", reflect.TypeOf((*MockStore)(nil).DeleteDevice), device)
+}
+
+// Nodes mocks base method
+func (m *MockStore) Nodes(room gateway.Room) (gateway.Nodes, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Nodes", room)
+	ret0, _ := ret[0].(gateway.Nodes)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Nodes indicates an expected call of Nodes
+func (mr *MockStoreMockRecorder) Nodes(room interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Nodes", reflect.TypeOf((*MockStore)(nil).Nodes), room)
+}
+
+// UpsertNodes mocks base method
+func (m *MockStore) UpsertNodes(room gateway.Room, nodes gateway.Nodes) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertNodes", room, nodes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertNodes indicates an expected call of UpsertNodes
+func (mr *MockStoreMockRecorder) UpsertNodes(room, nodes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertNodes", reflect.TypeOf((*MockStore)(nil).UpsertNodes), room, nodes)
+}
+
+// Scenes mocks base method
+func (m *MockStore) Scenes(building gateway.Entity) (gateway.Scenes, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Scenes", building)
+	ret0, _ := ret[0].(gateway.Scenes)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Scenes indicates an expected call of Scenes
+func (mr *MockStoreMockRecorder) Scenes(building interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Scenes", reflect.TypeOf((*MockStore)(nil).Scenes), building)
+}
+
+// UpsertScenes mocks base method
+func (m *MockStore) UpsertScenes(building gateway.Entity, scenes gateway.Scenes) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertScenes", building, scenes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertScenes indicates an expected call of UpsertScenes
+func (mr *MockStoreMockRecorder) UpsertScenes(building, scenes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertScenes", reflect.TypeOf((*MockStore)(nil).UpsertScenes), building, scenes)
+}
+
+// UpsertScene mocks base method
+func (m *MockStore) UpsertScene(scene gateway.Scene) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertScene", scene)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertScene indicates an expected call of UpsertScene
+func (mr *MockStoreMockRecorder) UpsertScene(scene interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertScene", reflect.TypeOf((*MockStore)(nil).UpsertScene), scene)
+}
+
+// DeleteScene mocks base method
+func (m *MockStore) DeleteScene(scene gateway.Scene) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteScene", scene)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteScene indicates an expected call of DeleteScene
+func (mr *MockStoreMockRecorder) DeleteScene(scene interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteScene", reflect.TypeOf((*MockStore)(nil).DeleteScene), scene)
+}
+
+// Automations mocks base method
+func (m *MockStore) Automations(building gateway.Entity) (gateway.Automations, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Automations", building)
+	ret0, _ := ret[0].(gateway.Automations)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Automations indicates an expected call of Automations
+func (mr *MockStoreMockRecorder) Automations(building interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Automations", reflect.TypeOf((*MockStore)(nil).Automations), building)
+}
+
+// UpsertAutomations mocks base method
+func (m *MockStore) UpsertAutomations(building gateway.Entity, automations gateway.Automations) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertAutomations", building, automations)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertAutomations indicates an expected call of UpsertAutomations
+func (mr *MockStoreMockRecorder) UpsertAutomations(building, automations interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertAutomations", reflect.TypeOf((*MockStore)(nil).UpsertAutomations), building, automations)
+}
+
+// UpsertAutomation mocks base method
+func (m *MockStore) UpsertAutomation(automation gateway.Automation) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertAutomation", automation)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertAutomation indicates an expected call of UpsertAutomation
+func (mr *MockStoreMockRecorder) UpsertAutomation(automation interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertAutomation", reflect.TypeOf((*MockStore)(nil).UpsertAutomation), automation)
+}
+
+// DeleteAutomation mocks base method
+func (m *MockStore) DeleteAutomation(automation gateway.Automation) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAutomation", automation)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAutomation indicates an expected call of DeleteAutomation
+func (mr *MockStoreMockRecorder) DeleteAutomation(automation interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAutomation", reflect.TypeOf((*MockStore)(nil).DeleteAutomation), automation)
+}
+
+// Schedules mocks base method
+func (m *MockStore) Schedules(building gateway.Entity) (gateway.Schedules, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Schedules", building)
+	ret0, _ := ret[0].(gateway.Schedules)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Schedules indicates an expected call of Schedules
+func (mr *MockStoreMockRecorder) Schedules(building interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Schedules", reflect.TypeOf((*MockStore)(nil).Schedules), building)
+}
+
+// UpsertSchedules mocks base method
+func (m *MockStore) UpsertSchedules(building gateway.Entity, schedules gateway.Schedules) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertSchedules", building, schedules)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertSchedules indicates an expected call of UpsertSchedules
+func (mr *MockStoreMockRecorder) UpsertSchedules(building, schedules interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertSchedules", reflect.TypeOf((*MockStore)(nil).UpsertSchedules), building, schedules)
+}
+
+// UpsertSchedule mocks base method
+func (m *MockStore) UpsertSchedule(schedule gateway.Schedule) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertSchedule", schedule)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertSchedule indicates an expected call of UpsertSchedule
+func (mr *MockStoreMockRecorder) UpsertSchedule(schedule interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertSchedule", reflect.TypeOf((*MockStore)(nil).UpsertSchedule), schedule)
+}
+
+// DeleteSchedule mocks base method
+func (m *MockStore) DeleteSchedule(schedule gateway.Schedule) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSchedule", schedule)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSchedule indicates an expected call of DeleteSchedule
+func (mr *MockStoreMockRecorder) DeleteSchedule(schedule interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSchedule", reflect.TypeOf((*MockStore)(nil).DeleteSchedule), schedule)
+}
+
+// Groups mocks base method
+func (m *MockStore) Groups(building gateway.Entity) (gateway.Groups, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Groups", building)
+	ret0, _ := ret[0].(gateway.Groups)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Groups indicates an expected call of Groups
+func (mr *MockStoreMockRecorder) Groups(building interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Groups", reflect.TypeOf((*MockStore)(nil).Groups), building)
+}
+
+// UpsertGroups mocks base method
+func (m *MockStore) UpsertGroups(building gateway.Entity, groups gateway.Groups) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertGroups", building, groups)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertGroups indicates an expected call of UpsertGroups
+func (mr *MockStoreMockRecorder) UpsertGroups(building, groups interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertGroups", reflect.TypeOf((*MockStore)(nil).UpsertGroups), building, groups)
+}
+
+// UpsertGroup mocks base method
+func (m *MockStore) UpsertGroup(group gateway.Group) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertGroup", group)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertGroup indicates an expected call of UpsertGroup
+func (mr *MockStoreMockRecorder) UpsertGroup(group interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertGroup", reflect.TypeOf((*MockStore)(nil).UpsertGroup), group)
+}
+
+// DeleteGroup mocks base method
+func (m *MockStore) DeleteGroup(group gateway.Group) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteGroup", group)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteGroup indicates an expected call of DeleteGroup
+func (mr *MockStoreMockRecorder) DeleteGroup(group interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteGroup", reflect.TypeOf((*MockStore)(nil).DeleteGroup), group)
+}
+
+// States mocks base method
+func (m *MockStore) States(room gateway.Room) (gateway.States, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "States", room)
+	ret0, _ := ret[0].(gateway.States)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// States indicates an expected call of States
+func (mr *MockStoreMockRecorder) States(room interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "States", reflect.TypeOf((*MockStore)(nil).States), room)
+}
+
+// UpsertState mocks base method
+func (m *MockStore) UpsertState(device gateway.Device, state gateway.State) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertState", device, state)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertState indicates an expected call of UpsertState
+func (mr *MockStoreMockRecorder) UpsertState(device, state interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertState", reflect.TypeOf((*MockStore)(nil).UpsertState), device, state)
+}
+
+// Tree mocks base method
+func (m *MockStore) Tree() (gateway.Tree, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Tree")
+	ret0, _ := ret[0].(gateway.Tree)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Tree indicates an expected call of Tree
+func (mr *MockStoreMockRecorder) Tree() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Tree", reflect.TypeOf((*MockStore)(nil).Tree))
+}
+
+// Labelled mocks base method
+func (m *MockStore) Labelled(selector labels.Selector) (map[string]map[string]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Labelled", selector)
+	ret0, _ := ret[0].(map[string]map[string]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Labelled indicates an expected call of Labelled
+func (mr *MockStoreMockRecorder) Labelled(selector interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Labelled", reflect.TypeOf((*MockStore)(nil).Labelled), selector)
+}
+
+// Search mocks base method
+func (m *MockStore) Search(query string) ([]search.Hit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", query)
+	ret0, _ := ret[0].([]search.Hit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Search indicates an expected call of Search
+func (mr *MockStoreMockRecorder) Search(query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockStore)(nil).Search), query)
+}
+
+// Reindex mocks base method
+func (m *MockStore) Reindex() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Reindex")
+}
+
+// Reindex indicates an expected call of Reindex
+func (mr *MockStoreMockRecorder) Reindex() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reindex", reflect.TypeOf((*MockStore)(nil).Reindex))
+}
+
 // Uptime mocks base method
 func (m *MockStore) Uptime() (gateway.Status, error) {
 	m.ctrl.T.Helper()
@@ -231,3 +635,90 @@ func (mr *MockStoreMockRecorder) RefreshUptime() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshUptime", reflect.TypeOf((*MockStore)(nil).RefreshUptime))
 }
+
+// Tokens mocks base method
+func (m *MockStore) Tokens() (auth.Tokens, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Tokens")
+	ret0, _ := ret[0].(auth.Tokens)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Tokens indicates an expected call of Tokens
+func (mr *MockStoreMockRecorder) Tokens() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Tokens", reflect.TypeOf((*MockStore)(nil).Tokens))
+}
+
+// UpsertToken mocks base method
+func (m *MockStore) UpsertToken(token auth.Token) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertToken", token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertToken indicates an expected call of UpsertToken
+func (mr *MockStoreMockRecorder) UpsertToken(token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertToken", reflect.TypeOf((*MockStore)(nil).UpsertToken), token)
+}
+
+// DeleteToken mocks base method
+func (m *MockStore) DeleteToken(id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteToken", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteToken indicates an expected call of DeleteToken
+func (mr *MockStoreMockRecorder) DeleteToken(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteToken", reflect.TypeOf((*MockStore)(nil).DeleteToken), id)
+}
+
+// SigningKey mocks base method
+func (m *MockStore) SigningKey() ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SigningKey")
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SigningKey indicates an expected call of SigningKey
+func (mr *MockStoreMockRecorder) SigningKey() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SigningKey", reflect.TypeOf((*MockStore)(nil).SigningKey))
+}
+
+// AppendAudit mocks base method
+func (m *MockStore) AppendAudit(entry audit.Entry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AppendAudit", entry)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AppendAudit indicates an expected call of AppendAudit
+func (mr *MockStoreMockRecorder) AppendAudit(entry interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AppendAudit", reflect.TypeOf((*MockStore)(nil).AppendAudit), entry)
+}
+
+// Audit mocks base method
+func (m *MockStore) Audit(query audit.Query) (audit.Entries, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Audit", query)
+	ret0, _ := ret[0].(audit.Entries)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Audit indicates an expected call of Audit
+func (mr *MockStoreMockRecorder) Audit(query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Audit", reflect.TypeOf((*MockStore)(nil).Audit), query)
+}
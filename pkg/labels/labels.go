@@ -0,0 +1,211 @@
+// Package labels parses the selectors of the labels given to the entities
+// e.g. env=outdoor,type in (light,fan) and matches the labels against them
+package labels
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Operator is the comparison of a requirement of a selector
+type Operator string
+
+const (
+	// Equals requires the label to have the value
+	Equals Operator = "="
+	// NotEquals requires the label to be missing or to have another value
+	NotEquals Operator = "!="
+	// In requires the label to have one of the values
+	In Operator = "in"
+	// NotIn requires the label to be missing or to have none of the values
+	NotIn Operator = "notin"
+	// Exists requires the label whatever its value
+	Exists Operator = "exists"
+	// DoesNotExist requires the label to be missing
+	DoesNotExist Operator = "!"
+)
+
+var token = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9._/-]{0,61}[A-Za-z0-9])?$`)
+
+// Validate validates the keys and the values of the labels, they start and
+// end with a letter or a digit and have up to 63 letters, digits, dots,
+// dashes, underscores and slashes
+func Validate(labels map[string]string) error {
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if !token.MatchString(key) {
+			return fmt.Errorf("label key '%s' is invalid, expected up to 63 letters, digits, '.', '-', '_' or '/' e.g. env", key)
+		}
+		if !token.MatchString(labels[key]) {
+			return fmt.Errorf("label %s has invalid value '%s', expected up to 63 letters, digits, '.', '-', '_' or '/' e.g. outdoor", key, labels[key])
+		}
+	}
+	return nil
+}
+
+// Requirement is a condition on a label of a selector
+type Requirement struct {
+	Key      string
+	Operator Operator
+	Values   []string
+}
+
+// Positive returns true when the requirement needs the label to be
+// present
+func (requirement Requirement) Positive() bool {
+	return requirement.Operator == Equals || requirement.Operator == In || requirement.Operator == Exists
+}
+
+// Matches returns true when the labels satisfy the requirement
+func (requirement Requirement) Matches(labels map[string]string) bool {
+	value, ok := labels[requirement.Key]
+	switch requirement.Operator {
+	case Exists:
+		return ok
+	case DoesNotExist:
+		return !ok
+	case Equals, In:
+		return ok && contains(requirement.Values, value)
+	default:
+		return !ok || !contains(requirement.Values, value)
+	}
+}
+
+// String returns the requirement in the syntax of the selectors
+func (requirement Requirement) String() string {
+	switch requirement.Operator {
+	case Exists:
+		return requirement.Key
+	case DoesNotExist:
+		return "!" + requirement.Key
+	case In, NotIn:
+		return fmt.Sprintf("%s %s (%s)", requirement.Key, requirement.Operator, strings.Join(requirement.Values, ","))
+	default:
+		return requirement.Key + string(requirement.Operator) + requirement.Values[0]
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Selector selects the labels satisfying every requirement, the empty
+// selector selects everything
+type Selector []Requirement
+
+// Matches returns true when the labels satisfy every requirement
+func (selector Selector) Matches(labels map[string]string) bool {
+	for _, requirement := range selector {
+		if !requirement.Matches(labels) {
+			return false
+		}
+	}
+	return true
+}
+
+// Empty returns true when the selector selects everything
+func (selector Selector) Empty() bool {
+	return len(selector) == 0
+}
+
+// String returns the selector in its syntax
+func (selector Selector) String() string {
+	requirements := make([]string, 0, len(selector))
+	for _, requirement := range selector {
+		requirements = append(requirements, requirement.String())
+	}
+	return strings.Join(requirements, ",")
+}
+
+// Parse parses the selector given as requirements separated by commas,
+// a requirement is one of key=value, key==value, key!=value,
+// key in (value,...), key notin (value,...), key or !key
+func Parse(selector string) (Selector, error) {
+	result := Selector{}
+	for _, raw := range split(selector) {
+		requirement, err := parseRequirement(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, requirement)
+	}
+	return result, nil
+}
+
+// split splits the selector on the commas outside the parentheses
+func split(selector string) []string {
+	if strings.TrimSpace(selector) == "" {
+		return nil
+	}
+	var parts []string
+	depth, start := 0, 0
+	for i, char := range selector {
+		switch char {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, selector[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, selector[start:])
+}
+
+var setRequirement = regexp.MustCompile(`^(\S+)\s+(in|notin)\s*\((.*)\)$`)
+
+func parseRequirement(raw string) (Requirement, error) {
+	invalid := fmt.Errorf("requirement '%s' should be given as key=value, key!=value, key in (a,b), key notin (a,b), key or !key", raw)
+	if match := setRequirement.FindStringSubmatch(raw); match != nil {
+		requirement := Requirement{Key: match[1], Operator: Operator(match[2])}
+		for _, value := range strings.Split(match[3], ",") {
+			requirement.Values = append(requirement.Values, strings.TrimSpace(value))
+		}
+		return requirement, validateRequirement(requirement, invalid)
+	}
+
+	for _, operator := range []string{"!=", "==", "="} {
+		if index := strings.Index(raw, operator); index > 0 {
+			requirement := Requirement{
+				Key:      strings.TrimSpace(raw[:index]),
+				Operator: Equals,
+				Values:   []string{strings.TrimSpace(raw[index+len(operator):])},
+			}
+			if operator == "!=" {
+				requirement.Operator = NotEquals
+			}
+			return requirement, validateRequirement(requirement, invalid)
+		}
+	}
+
+	if strings.HasPrefix(raw, "!") {
+		return Requirement{Key: strings.TrimSpace(raw[1:]), Operator: DoesNotExist}, validateRequirement(Requirement{Key: strings.TrimSpace(raw[1:])}, invalid)
+	}
+	return Requirement{Key: raw, Operator: Exists}, validateRequirement(Requirement{Key: raw}, invalid)
+}
+
+func validateRequirement(requirement Requirement, invalid error) error {
+	if !token.MatchString(requirement.Key) {
+		return invalid
+	}
+	for _, value := range requirement.Values {
+		if !token.MatchString(value) {
+			return invalid
+		}
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package labels_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/labels"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("should parse the requirements of the selector", func(t *testing.T) {
+		selector, err := labels.Parse("env=outdoor, type in (light, fan),zone!=garden,floor==ground,dimmable,!broken,kind notin (switch)")
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, labels.Selector{
+				{Key: "env", Operator: labels.Equals, Values: []string{"outdoor"}},
+				{Key: "type", Operator: labels.In, Values: []string{"light", "fan"}},
+				{Key: "zone", Operator: labels.NotEquals, Values: []string{"garden"}},
+				{Key: "floor", Operator: labels.Equals, Values: []string{"ground"}},
+				{Key: "dimmable", Operator: labels.Exists},
+				{Key: "broken", Operator: labels.DoesNotExist},
+				{Key: "kind", Operator: labels.NotIn, Values: []string{"switch"}},
+			}, selector)
+			assert.Equal(t, "env=outdoor,type in (light,fan),zone!=garden,floor=ground,dimmable,!broken,kind notin (switch)", selector.String())
+		}
+	})
+
+	t.Run("should return the empty selector", func(t *testing.T) {
+		selector, err := labels.Parse(" ")
+
+		assert.NoError(t, err)
+		assert.True(t, selector.Empty())
+	})
+
+	t.Run("should reject invalid requirements", func(t *testing.T) {
+		for selector, message := range map[string]string{
+			"env=":             "requirement 'env=' should be given as key=value, key!=value, key in (a,b), key notin (a,b), key or !key",
+			"type in (light,)": "requirement 'type in (light,)' should be given as key=value, key!=value, key in (a,b), key notin (a,b), key or !key",
+			"env=outdoor,,":    "requirement '' should be given as key=value, key!=value, key in (a,b), key notin (a,b), key or !key",
+			"type in light":    "requirement 'type in light' should be given as key=value, key!=value, key in (a,b), key notin (a,b), key or !key",
+		} {
+			_, err := labels.Parse(selector)
+
+			assert.EqualError(t, err, message, selector)
+		}
+	})
+}
+
+func TestSelector_Matches(t *testing.T) {
+	porch := map[string]string{"env": "outdoor", "type": "light"}
+	fan := map[string]string{"env": "indoor", "type": "fan"}
+
+	for selector, expected := range map[string][]bool{
+		"":                          {true, true, true},
+		"env=outdoor":               {true, false, false},
+		"env!=outdoor":              {false, true, true},
+		"type in (light,fan)":       {true, true, false},
+		"type notin (light)":        {false, true, true},
+		"env":                       {true, true, false},
+		"!env":                      {false, false, true},
+		"env=indoor,type in (fan)":  {false, true, false},
+		"env=indoor,type in (bulb)": {false, false, false},
+	} {
+		parsed, err := labels.Parse(selector)
+		if assert.NoError(t, err) {
+			assert.Equal(t, expected, []bool{parsed.Matches(porch), parsed.Matches(fan), parsed.Matches(nil)}, selector)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, labels.Validate(map[string]string{"env": "outdoor", "dwarka.io/zone": "north_east"}))
+	assert.EqualError(t, labels.Validate(map[string]string{"env": "outdoor", "-env": "outdoor"}), "label key '-env' is invalid, expected up to 63 letters, digits, '.', '-', '_' or '/' e.g. env")
+	assert.EqualError(t, labels.Validate(map[string]string{"env": "out door"}), "label env has invalid value 'out door', expected up to 63 letters, digits, '.', '-', '_' or '/' e.g. outdoor")
+}
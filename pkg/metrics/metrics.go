@@ -0,0 +1,255 @@
+// Package metrics collects the counters, the gauges and the histograms of
+// the gateway and writes them in the Prometheus text format
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ContentType is the content type of the Prometheus text format
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+const (
+	kindCounter   = "counter"
+	kindGauge     = "gauge"
+	kindHistogram = "histogram"
+)
+
+// DefaultBuckets are the upper bounds in seconds of the buckets of the
+// latency histograms, from 1ms to 10s
+var DefaultBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Default is the registry of the metrics of the gateway
+var Default = NewRegistry()
+
+// Registry holds the metrics written together, every metric has a fixed
+// set of label names and a series for every set of label values
+type Registry struct {
+	mu       sync.Mutex
+	families map[string]*family
+}
+
+type family struct {
+	name    string
+	help    string
+	kind    string
+	labels  []string
+	buckets []float64
+	series  map[string]*series
+}
+
+type series struct {
+	values []string
+	value  float64
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// NewRegistry returns an empty Registry
+func NewRegistry() *Registry {
+	return &Registry{families: map[string]*family{}}
+}
+
+func (registry *Registry) register(name, help, kind string, buckets []float64, labels []string) *family {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	if existing, ok := registry.families[name]; ok {
+		return existing
+	}
+	metric := &family{name: name, help: help, kind: kind, labels: labels, buckets: buckets, series: map[string]*series{}}
+	registry.families[name] = metric
+	return metric
+}
+
+// update applies the change to the series of the label values, the label
+// values missing are left empty
+func (registry *Registry) update(metric *family, values []string, change func(*series)) {
+	labelled := make([]string, len(metric.labels))
+	copy(labelled, values)
+	key := strings.Join(labelled, "\xff")
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	current, ok := metric.series[key]
+	if !ok {
+		current = &series{values: labelled, counts: make([]uint64, len(metric.buckets))}
+		metric.series[key] = current
+	}
+	change(current)
+}
+
+// Counter is a value which only goes up e.g. the requests served
+type Counter struct {
+	registry *Registry
+	family   *family
+}
+
+// NewCounter registers the counter with the label names, registering a
+// name again returns the metric registered first
+func (registry *Registry) NewCounter(name, help string, labels ...string) Counter {
+	return Counter{registry: registry, family: registry.register(name, help, kindCounter, nil, labels)}
+}
+
+// Inc adds one to the series of the label values
+func (counter Counter) Inc(values ...string) {
+	counter.Add(1, values...)
+}
+
+// Add adds the value to the series of the label values
+func (counter Counter) Add(value float64, values ...string) {
+	counter.registry.update(counter.family, values, func(current *series) { current.value += value })
+}
+
+// Gauge is a value which goes up and down e.g. whether a device is online
+type Gauge struct {
+	registry *Registry
+	family   *family
+}
+
+// NewGauge registers the gauge with the label names, registering a name
+// again returns the metric registered first
+func (registry *Registry) NewGauge(name, help string, labels ...string) Gauge {
+	return Gauge{registry: registry, family: registry.register(name, help, kindGauge, nil, labels)}
+}
+
+// Set sets the value of the series of the label values
+func (gauge Gauge) Set(value float64, values ...string) {
+	gauge.registry.update(gauge.family, values, func(current *series) { current.value = value })
+}
+
+// Histogram counts the observations e.g. the latencies in the buckets of
+// their upper bounds along with their count and sum
+type Histogram struct {
+	registry *Registry
+	family   *family
+}
+
+// NewHistogram registers the histogram with the upper bounds of its
+// buckets and the label names, registering a name again returns the
+// metric registered first
+func (registry *Registry) NewHistogram(name, help string, buckets []float64, labels ...string) Histogram {
+	sorted := append([]float64{}, buckets...)
+	sort.Float64s(sorted)
+	return Histogram{registry: registry, family: registry.register(name, help, kindHistogram, sorted, labels)}
+}
+
+// Observe adds the value to the series of the label values
+func (histogram Histogram) Observe(value float64, values ...string) {
+	histogram.registry.update(histogram.family, values, func(current *series) {
+		for i, bound := range histogram.family.buckets {
+			if value <= bound {
+				current.counts[i]++
+				break
+			}
+		}
+		current.count++
+		current.sum += value
+	})
+}
+
+// WriteTo writes the metrics ordered by name in the Prometheus text format
+func (registry *Registry) WriteTo(w io.Writer) (int64, error) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	names := make([]string, 0, len(registry.families))
+	for name := range registry.families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	counter := &countingWriter{w: w}
+	out := bufio.NewWriter(counter)
+	for _, name := range names {
+		registry.families[name].write(out)
+	}
+	err := out.Flush()
+	return counter.n, err
+}
+
+func (metric *family) write(out *bufio.Writer) {
+	fmt.Fprintf(out, "# HELP %s %s\n", metric.name, escapeHelp(metric.help))
+	fmt.Fprintf(out, "# TYPE %s %s\n", metric.name, metric.kind)
+
+	keys := make([]string, 0, len(metric.series))
+	for key := range metric.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		current := metric.series[key]
+		if metric.kind != kindHistogram {
+			fmt.Fprintf(out, "%s%s %s\n", metric.name, labelsOf(metric.labels, current.values), formatValue(current.value))
+			continue
+		}
+
+		names := append(append([]string{}, metric.labels...), "le")
+		bucket := func(bound string) string {
+			return labelsOf(names, append(append([]string{}, current.values...), bound))
+		}
+		cumulative := uint64(0)
+		for i, bound := range metric.buckets {
+			cumulative += current.counts[i]
+			fmt.Fprintf(out, "%s_bucket%s %d\n", metric.name, bucket(formatValue(bound)), cumulative)
+		}
+		fmt.Fprintf(out, "%s_bucket%s %d\n", metric.name, bucket("+Inf"), current.count)
+		fmt.Fprintf(out, "%s_sum%s %s\n", metric.name, labelsOf(metric.labels, current.values), formatValue(current.sum))
+		fmt.Fprintf(out, "%s_count%s %d\n", metric.name, labelsOf(metric.labels, current.values), current.count)
+	}
+}
+
+func labelsOf(names, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = fmt.Sprintf(`%s="%s"`, name, escapeLabel(values[i]))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(help string) string {
+	return helpEscaper.Replace(help)
+}
+
+func escapeLabel(value string) string {
+	return labelEscaper.Replace(value)
+}
+
+func formatValue(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	}
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (writer *countingWriter) Write(data []byte) (int, error) {
+	n, err := writer.w.Write(data)
+	writer.n += int64(n)
+	return n, err
+}
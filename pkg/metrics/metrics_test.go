@@ -0,0 +1,56 @@
+package metrics_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/metrics"
+)
+
+func TestRegistry_WriteTo(t *testing.T) {
+	t.Run("should write the counters and the gauges ordered by name and labels", func(t *testing.T) {
+		registry := metrics.NewRegistry()
+		requests := registry.NewCounter("requests_total", "Requests served", "route", "status")
+		online := registry.NewGauge("device_online", "Whether the device is online", "device")
+		requests.Inc("/v1/buildings", "200")
+		requests.Add(2, "/v1/buildings", "200")
+		requests.Inc(`/v1/"quoted"`, "404")
+		online.Set(1, "home/ground/hall/fan")
+
+		builder := &strings.Builder{}
+		n, err := registry.WriteTo(builder)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(builder.Len()), n)
+		assert.Equal(t, `# HELP device_online Whether the device is online
+# TYPE device_online gauge
+device_online{device="home/ground/hall/fan"} 1
+# HELP requests_total Requests served
+# TYPE requests_total counter
+requests_total{route="/v1/\"quoted\"",status="404"} 1
+requests_total{route="/v1/buildings",status="200"} 3
+`, builder.String())
+	})
+
+	t.Run("should write the cumulative buckets of the histograms", func(t *testing.T) {
+		registry := metrics.NewRegistry()
+		latency := registry.NewHistogram("latency_seconds", "Latency", []float64{0.5, 0.1}, "route")
+		for _, value := range []float64{0.05, 0.2, 0.3, 2} {
+			latency.Observe(value, "/ping")
+		}
+
+		builder := &strings.Builder{}
+		_, err := registry.WriteTo(builder)
+
+		assert.NoError(t, err)
+		assert.Equal(t, `# HELP latency_seconds Latency
+# TYPE latency_seconds histogram
+latency_seconds_bucket{route="/ping",le="0.1"} 1
+latency_seconds_bucket{route="/ping",le="0.5"} 3
+latency_seconds_bucket{route="/ping",le="+Inf"} 4
+latency_seconds_sum{route="/ping"} 2.55
+latency_seconds_count{route="/ping"} 4
+`, builder.String())
+	})
+}
@@ -0,0 +1,206 @@
+package mqtt
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+// the types of the control packets of MQTT 3.1.1 used by the client
+const (
+	packetConnect     byte = 1
+	packetConnAck     byte = 2
+	packetPublish     byte = 3
+	packetPubAck      byte = 4
+	packetSubscribe   byte = 8
+	packetSubAck      byte = 9
+	packetPingReq     byte = 12
+	packetPingResp    byte = 13
+	packetDisconnect  byte = 14
+	protocolLevel     byte = 4
+	subscribeFailure  byte = 0x80
+	maxRemainingBytes      = 4
+)
+
+// maxPacketSize limits the packets read from the broker, the larger ones
+// are skipped so that a message never holds more than it in memory
+const maxPacketSize = 1 << 20
+
+var errPacketTooLarge = errors.New("packet exceeds 1 MiB")
+
+// packet is a control packet along with the flags of its fixed header
+type packet struct {
+	kind  byte
+	flags byte
+	body  []byte
+}
+
+// encode returns the packet along with its fixed header
+func (p packet) encode() []byte {
+	header := []byte{p.kind<<4 | p.flags}
+	length := len(p.body)
+	for {
+		digit := byte(length % 128)
+		length /= 128
+		if length > 0 {
+			digit |= 0x80
+		}
+		header = append(header, digit)
+		if length == 0 {
+			break
+		}
+	}
+	return append(header, p.body...)
+}
+
+// readPacket reads the next packet, the body of a packet larger than
+// maxPacketSize is skipped and errPacketTooLarge is returned along with
+// its type
+func readPacket(reader *bufio.Reader) (packet, error) {
+	first, err := reader.ReadByte()
+	if err != nil {
+		return packet{}, err
+	}
+	p := packet{kind: first >> 4, flags: first & 0x0f}
+
+	length, multiplier := 0, 1
+	for i := 0; ; i++ {
+		if i == maxRemainingBytes {
+			return packet{}, errors.New("malformed remaining length")
+		}
+		digit, err := reader.ReadByte()
+		if err != nil {
+			return packet{}, err
+		}
+		length += int(digit&0x7f) * multiplier
+		multiplier *= 128
+		if digit&0x80 == 0 {
+			break
+		}
+	}
+
+	if length > maxPacketSize {
+		_, err := io.CopyN(ioutil.Discard, reader, int64(length))
+		if err != nil {
+			return packet{}, err
+		}
+		return p, errPacketTooLarge
+	}
+	p.body = make([]byte, length)
+	_, err = io.ReadFull(reader, p.body)
+	return p, err
+}
+
+func appendString(data []byte, value string) []byte {
+	data = binary.BigEndian.AppendUint16(data, uint16(len(value)))
+	return append(data, value...)
+}
+
+// readString returns the string at the start of the data along with the
+// data after it
+func readString(data []byte) (string, []byte, error) {
+	if len(data) < 2 {
+		return "", nil, errors.New("malformed string")
+	}
+	length := int(binary.BigEndian.Uint16(data))
+	if len(data) < 2+length {
+		return "", nil, errors.New("malformed string")
+	}
+	return string(data[2 : 2+length]), data[2+length:], nil
+}
+
+func connectPacket(clientID, username, password string, keepAlive uint16) packet {
+	flags := byte(0x02) // clean session
+	if username != "" {
+		flags |= 0x80
+	}
+	if password != "" {
+		flags |= 0x40
+	}
+	body := appendString(nil, "MQTT")
+	body = append(body, protocolLevel, flags)
+	body = binary.BigEndian.AppendUint16(body, keepAlive)
+	body = appendString(body, clientID)
+	if username != "" {
+		body = appendString(body, username)
+	}
+	if password != "" {
+		body = appendString(body, password)
+	}
+	return packet{kind: packetConnect, body: body}
+}
+
+// connAckError returns the reason the broker refused the connection, nil
+// when it is accepted
+func connAckError(p packet) error {
+	if p.kind != packetConnAck || len(p.body) != 2 {
+		return fmt.Errorf("expected connack, received packet of type %d", p.kind)
+	}
+	switch p.body[1] {
+	case 0:
+		return nil
+	case 1:
+		return errors.New("connection refused, unacceptable protocol version")
+	case 2:
+		return errors.New("connection refused, identifier rejected")
+	case 3:
+		return errors.New("connection refused, server unavailable")
+	case 4:
+		return errors.New("connection refused, bad user name or password")
+	case 5:
+		return errors.New("connection refused, not authorized")
+	}
+	return fmt.Errorf("connection refused, return code %d", p.body[1])
+}
+
+// subscribePacket subscribes to the topics with at most once or at least
+// once delivery
+func subscribePacket(id uint16, topics []string) packet {
+	body := binary.BigEndian.AppendUint16(nil, id)
+	for _, topic := range topics {
+		body = appendString(body, topic)
+		body = append(body, 1)
+	}
+	return packet{kind: packetSubscribe, flags: 0x02, body: body}
+}
+
+// subAckError returns an error when the broker refused a subscription
+func subAckError(p packet, topics []string) error {
+	if len(p.body) < 2 {
+		return errors.New("malformed suback")
+	}
+	for i, code := range p.body[2:] {
+		if code == subscribeFailure && i < len(topics) {
+			return fmt.Errorf("subscription to %s refused by the broker", topics[i])
+		}
+	}
+	return nil
+}
+
+// parsePublish returns the message of the publish packet along with its id
+// and its quality of service, the id is given only for at least once
+func parsePublish(p packet) (Message, uint16, byte, error) {
+	qos := (p.flags >> 1) & 0x03
+	if qos > 1 {
+		return Message{}, 0, qos, fmt.Errorf("quality of service %d not supported", qos)
+	}
+	topic, rest, err := readString(p.body)
+	if err != nil {
+		return Message{}, 0, qos, err
+	}
+	var id uint16
+	if qos == 1 {
+		if len(rest) < 2 {
+			return Message{}, 0, qos, errors.New("malformed publish")
+		}
+		id, rest = binary.BigEndian.Uint16(rest), rest[2:]
+	}
+	return Message{Topic: topic, Payload: rest, Retained: p.flags&0x01 == 1}, id, qos, nil
+}
+
+func pubAckPacket(id uint16) packet {
+	return packet{kind: packetPubAck, body: binary.BigEndian.AppendUint16(nil, id)}
+}
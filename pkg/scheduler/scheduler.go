@@ -0,0 +1,250 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/automation"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/store"
+)
+
+// Publisher is reported the states the devices are switched to by the
+// schedules e.g. the automation engine
+type Publisher interface {
+	Publish(event automation.Event) bool
+}
+
+// Run is the outcome of a schedule, Missed is set for the run of a
+// schedule which missed its runs while the server was stopped
+type Run struct {
+	Schedule string
+	Building string
+	Time     time.Time
+	Missed   bool
+	Err      error
+}
+
+// Scheduler runs the schedules of the buildings, the schedules are read
+// from the store on every tick so that the changes apply immediately
+type Scheduler struct {
+	store     store.Store
+	clock     automation.Clock
+	publisher Publisher
+
+	mu   sync.Mutex
+	last time.Time
+}
+
+// NewScheduler returns the Scheduler of the schedules in the store running
+// on the clock, the publisher is optional
+func NewScheduler(store store.Store, clock automation.Clock, publisher Publisher) *Scheduler {
+	return &Scheduler{store: store, clock: clock, publisher: publisher, last: clock.Now()}
+}
+
+// Run catches up the missed runs and then runs the schedules as they are
+// due every minute until stop is closed
+func (scheduler *Scheduler) Run(stop <-chan struct{}) {
+	scheduler.CatchUp(scheduler.clock.Now())
+	tick := scheduler.clock.After(untilNextMinute(scheduler.clock.Now()))
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-tick:
+			scheduler.Tick(now)
+			tick = scheduler.clock.After(untilNextMinute(now))
+		}
+	}
+}
+
+func untilNextMinute(now time.Time) time.Duration {
+	return now.Truncate(time.Minute).Add(time.Minute).Sub(now)
+}
+
+// CatchUp runs once the schedules which missed any run until now since
+// their last run or their creation, the schedules skipping the missed runs
+// are left out
+func (scheduler *Scheduler) CatchUp(now time.Time) []Run {
+	return scheduler.each(func(building gateway.Building, schedule gateway.Schedule) (Run, bool) {
+		if schedule.Missed != gateway.MissedRunOnce {
+			return Run{}, false
+		}
+		since := schedule.Created
+		if schedule.LastRun != nil && schedule.LastRun.After(since) {
+			since = *schedule.LastRun
+		}
+		next, err := schedule.Next(building, since)
+		if err != nil {
+			log.Printf("unable to evaluate schedule %s of %s, reason: %v", schedule.ID(), building.ID(), err)
+			return Run{}, false
+		}
+		if next.IsZero() || !next.Before(now) {
+			return Run{}, false
+		}
+		run := scheduler.run(building, schedule, now)
+		run.Missed = true
+		return run, true
+	})
+}
+
+// Tick runs the schedules due since the previous tick, a schedule runs
+// once even when several of its runs are due
+func (scheduler *Scheduler) Tick(now time.Time) []Run {
+	scheduler.mu.Lock()
+	last := scheduler.last
+	if !now.After(last) {
+		scheduler.mu.Unlock()
+		return nil
+	}
+	scheduler.last = now
+	scheduler.mu.Unlock()
+
+	return scheduler.each(func(building gateway.Building, schedule gateway.Schedule) (Run, bool) {
+		next, err := schedule.Next(building, last)
+		if err != nil {
+			log.Printf("unable to evaluate schedule %s of %s, reason: %v", schedule.ID(), building.ID(), err)
+			return Run{}, false
+		}
+		if next.IsZero() || next.After(now) {
+			return Run{}, false
+		}
+		return scheduler.run(building, schedule, now), true
+	})
+}
+
+// each calls f with the enabled schedules of the buildings ordered by the
+// ids of the buildings and of the schedules
+func (scheduler *Scheduler) each(f func(building gateway.Building, schedule gateway.Schedule) (Run, bool)) []Run {
+	buildings, err := scheduler.store.Buildings()
+	if err != nil {
+		log.Printf("unable to evaluate the schedules, reason: %v", err)
+		return nil
+	}
+	ids := make([]string, 0, len(buildings))
+	for id := range buildings {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var runs []Run
+	for _, id := range ids {
+		building := buildings[id]
+		schedules, err := scheduler.store.Schedules(building)
+		if err != nil {
+			log.Printf("unable to evaluate the schedules of %s, reason: %v", id, err)
+			continue
+		}
+		names := make([]string, 0, len(schedules))
+		for name, schedule := range schedules {
+			if !schedule.Disabled {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			if run, ok := f(building, schedules[name]); ok {
+				runs = append(runs, run)
+			}
+		}
+	}
+	return runs
+}
+
+// run switches the target of the schedule and records the time of the run
+func (scheduler *Scheduler) run(building gateway.Building, schedule gateway.Schedule, now time.Time) Run {
+	run := Run{Schedule: schedule.ID(), Building: building.ID(), Time: now}
+	run.Err = scheduler.activate(building, schedule)
+	if run.Err != nil {
+		log.Printf("schedule %s of %s failed, reason: %v", schedule.ID(), building.ID(), run.Err)
+	} else {
+		log.Printf("schedule %s of %s ran", schedule.ID(), building.ID())
+	}
+
+	// the schedule is read again as it may have changed during the run
+	schedules, err := scheduler.store.Schedules(building)
+	if err != nil {
+		log.Printf("unable to record the run of schedule %s of %s, reason: %v", schedule.ID(), building.ID(), err)
+		return run
+	}
+	current, ok := schedules[schedule.ID()]
+	if !ok {
+		return run
+	}
+	current.LastRun = &now
+	err = scheduler.store.UpsertSchedule(current)
+	if err != nil {
+		log.Printf("unable to record the run of schedule %s of %s, reason: %v", schedule.ID(), building.ID(), err)
+	}
+	return run
+}
+
+// activate switches the device, the devices of the room or the scene of
+// the schedule and records their states
+func (scheduler *Scheduler) activate(building gateway.Building, schedule gateway.Schedule) error {
+	tree, err := scheduler.store.Tree()
+	if err != nil {
+		return err
+	}
+
+	scene := gateway.Scene{Building: building}
+	switch {
+	case schedule.Scene != "":
+		scenes, err := scheduler.store.Scenes(building)
+		if err != nil {
+			return err
+		}
+		var ok bool
+		scene, ok = scenes[schedule.Scene]
+		if !ok {
+			return fmt.Errorf("scene %s not found", schedule.Scene)
+		}
+	case schedule.Room != "":
+		ids := strings.Split(schedule.Room, "/")
+		room, ok := tree.RoomsOf(tree.FloorsOf(building)[ids[0]])[ids[1]]
+		if !ok {
+			return fmt.Errorf("room %s not found", schedule.Room)
+		}
+		devices := make([]string, 0, len(tree.DevicesOf(room)))
+		for id := range tree.DevicesOf(room) {
+			devices = append(devices, id)
+		}
+		sort.Strings(devices)
+		for _, device := range devices {
+			scene.Targets = append(scene.Targets, gateway.Target{Floor: ids[0], Room: ids[1], Device: device, State: schedule.State})
+		}
+	default:
+		ids := strings.Split(schedule.Device, "/")
+		scene.Targets = []gateway.Target{{Floor: ids[0], Room: ids[1], Device: ids[2], State: schedule.State}}
+	}
+
+	var failed []string
+	for _, result := range scene.Activate(tree) {
+		if result.Err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", result.Target.Path(), result.Err))
+			continue
+		}
+		err := scheduler.store.UpsertState(*result.Device, result.Target.State)
+		if err != nil {
+			return err
+		}
+		if scheduler.publisher != nil {
+			scheduler.publisher.Publish(automation.Event{
+				Kind:     automation.EventState,
+				Building: building.ID(),
+				Path:     result.Target.Path(),
+				State:    string(result.Target.State),
+			})
+		}
+	}
+	if len(failed) > 0 {
+		return errors.New(strings.Join(failed, ", "))
+	}
+	return nil
+}
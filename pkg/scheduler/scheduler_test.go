@@ -0,0 +1,158 @@
+package scheduler_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/automation"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/scheduler"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/store"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/testutils"
+)
+
+// monday 19 october 2026 at 18:00 in Bengaluru
+var evening = time.Date(2026, 10, 19, 12, 30, 0, 0, time.UTC)
+
+// recordingPublisher records the published events
+type recordingPublisher struct {
+	mu     sync.Mutex
+	events []automation.Event
+}
+
+func (publisher *recordingPublisher) Publish(event automation.Event) bool {
+	publisher.mu.Lock()
+	defer publisher.mu.Unlock()
+	publisher.events = append(publisher.events, event)
+	return true
+}
+
+// newStore returns the store with building-one in Bengaluru along with
+// floor-one/room-one having the porch-light and the broken-lamp
+func newStore(t *testing.T, schedules ...gateway.Schedule) (store.Store, *testutils.RecordingNode) {
+	building := testutils.NewBuilding("building-one")
+	building.Lat, building.Lan, building.Timezone = 12.97, 77.59, "Asia/Kolkata"
+	persistentStore, node := testutils.NewSwitchingStore(t, building, "porch-light", "broken-lamp")
+	assert.NoError(t, persistentStore.UpsertScene(gateway.Scene{
+		Building:       building,
+		Targets:        []gateway.Target{{Floor: "floor-one", Room: "room-one", Device: "porch-light", State: gateway.StateOff}},
+		PhysicalEntity: gateway.PhysicalEntity{Name: "movie-mode"},
+	}))
+	for _, schedule := range schedules {
+		schedule.Building = building
+		assert.NoError(t, persistentStore.UpsertSchedule(schedule))
+	}
+	return persistentStore, node
+}
+
+func newSchedule(name string, schedule gateway.Schedule) gateway.Schedule {
+	if schedule.Mode == "" {
+		schedule.Mode = gateway.ScheduleRecurring
+	}
+	if schedule.Missed == "" {
+		schedule.Missed = gateway.MissedSkip
+	}
+	schedule.Created = evening.Add(-72 * time.Hour)
+	schedule.PhysicalEntity = gateway.PhysicalEntity{Name: name}
+	return schedule
+}
+
+func TestScheduler_Tick(t *testing.T) {
+	t.Run("should run the schedules in the time zone of the building once they are due", func(t *testing.T) {
+		persistentStore, node := newStore(t,
+			newSchedule("porch on weekdays", gateway.Schedule{Cron: "15 18 * * 1-5", Skip: []string{"2026-10-20"}, Device: "floor-one/room-one/porch-light", State: gateway.StateOn}),
+			newSchedule("room off at night", gateway.Schedule{Cron: "0 23 * * *", Room: "floor-one/room-one", State: gateway.StateOff}),
+			newSchedule("movie on friday", gateway.Schedule{Mode: gateway.ScheduleOnce, At: "2026-10-23T21:00", Scene: "movie-mode"}),
+		)
+		publisher := &recordingPublisher{}
+		runner := scheduler.NewScheduler(persistentStore, automation.NewManualClock(evening), publisher)
+
+		assert.Empty(t, runner.Tick(evening.Add(10*time.Minute)))
+		runs := runner.Tick(evening.Add(20 * time.Minute))
+		if assert.Len(t, runs, 1) {
+			assert.Equal(t, "porch-on-weekdays", runs[0].Schedule)
+			assert.NoError(t, runs[0].Err)
+		}
+		runs = runner.Tick(evening.Add(5 * time.Hour))
+		if assert.Len(t, runs, 1) {
+			assert.Equal(t, "room-off-at-night", runs[0].Schedule)
+			assert.EqualError(t, runs[0].Err, "floor-one/room-one/broken-lamp: node unreachable")
+		}
+		// the porch light is skipped on tuesday
+		assert.Len(t, runner.Tick(evening.Add(25*time.Hour)), 0)
+		assert.Len(t, runner.Tick(evening.Add(4*24*time.Hour+9*time.Hour)), 3)
+
+		// the devices of the room are switched in parallel
+		assert.ElementsMatch(t, []string{
+			"on:porch-light", "off:broken-lamp", "off:porch-light",
+			"on:porch-light", "off:broken-lamp", "off:porch-light", "off:porch-light",
+		}, node.Switched())
+		assert.Len(t, publisher.events, 5)
+		schedules, err := persistentStore.Schedules(testutils.NewBuilding("building-one"))
+		if assert.NoError(t, err) && assert.NotNil(t, schedules["movie-on-friday"].LastRun) {
+			assert.True(t, evening.Add(4*24*time.Hour+9*time.Hour).Equal(*schedules["movie-on-friday"].LastRun))
+		}
+	})
+
+	t.Run("should leave out the disabled schedules", func(t *testing.T) {
+		disabled := newSchedule("porch on weekdays", gateway.Schedule{Cron: "15 18 * * 1-5", Device: "floor-one/room-one/porch-light", State: gateway.StateOn})
+		disabled.Disabled = true
+		persistentStore, node := newStore(t, disabled)
+		runner := scheduler.NewScheduler(persistentStore, automation.NewManualClock(evening), nil)
+
+		assert.Empty(t, runner.Tick(evening.Add(20*time.Minute)))
+		assert.Empty(t, node.Switched())
+	})
+}
+
+func TestScheduler_CatchUp(t *testing.T) {
+	t.Run("should run once the schedules which missed their runs", func(t *testing.T) {
+		lastRun := evening.Add(-48 * time.Hour)
+		missed := newSchedule("porch every day", gateway.Schedule{Cron: "15 18 * * *", Missed: gateway.MissedRunOnce, Device: "floor-one/room-one/porch-light", State: gateway.StateOn})
+		missed.LastRun = &lastRun
+		persistentStore, node := newStore(t,
+			missed,
+			newSchedule("lamp off", gateway.Schedule{Cron: "15 18 * * *", Device: "floor-one/room-one/broken-lamp", State: gateway.StateOff}),
+			newSchedule("room off later", gateway.Schedule{Mode: gateway.ScheduleOnce, At: "2026-10-30T23:00", Missed: gateway.MissedRunOnce, Room: "floor-one/room-one", State: gateway.StateOff}),
+		)
+		runner := scheduler.NewScheduler(persistentStore, automation.NewManualClock(evening), nil)
+
+		runs := runner.CatchUp(evening)
+
+		if assert.Len(t, runs, 1) {
+			assert.Equal(t, "porch-every-day", runs[0].Schedule)
+			assert.True(t, runs[0].Missed)
+		}
+		assert.Equal(t, []string{"on:porch-light"}, node.Switched())
+		assert.Empty(t, runner.CatchUp(evening.Add(time.Minute)))
+	})
+}
+
+func TestScheduler_Run(t *testing.T) {
+	t.Run("should run on the clock until stopped", func(t *testing.T) {
+		persistentStore, node := newStore(t,
+			newSchedule("porch on weekdays", gateway.Schedule{Cron: "15 18 * * 1-5", Device: "floor-one/room-one/porch-light", State: gateway.StateOn}),
+		)
+		clock := automation.NewManualClock(evening)
+		runner := scheduler.NewScheduler(persistentStore, clock, nil)
+		stop := make(chan struct{})
+		done := make(chan struct{})
+		go func() {
+			runner.Run(stop)
+			close(done)
+		}()
+
+		// the scheduler ticks at its own pace, the clock keeps moving until
+		// a tick passes the run
+		assert.Eventually(t, func() bool {
+			clock.Advance(time.Minute)
+			return len(node.Switched()) == 1
+		}, time.Second, 10*time.Millisecond)
+		close(stop)
+		<-done
+
+		assert.Equal(t, []string{"on:porch-light"}, node.Switched())
+	})
+}
@@ -0,0 +1,328 @@
+// Package search indexes the names, descriptions, labels and meta of the
+// entities in memory and finds them by the words of a query, the words
+// match exactly, as a prefix or within a few typos
+package search
+
+import (
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// weights of the fields, a word of the name outweighs the same word in
+// the description
+const (
+	nameWeight        = 3
+	labelWeight       = 2
+	descriptionWeight = 1
+)
+
+// Document is the text of an entity which is searched
+type Document struct {
+	Name        string
+	Description string
+	Labels      map[string]string
+	Meta        map[string]string
+}
+
+func (document Document) terms() map[string]float64 {
+	result := map[string]float64{}
+	add := func(text string, weight float64) {
+		for _, term := range Terms(text) {
+			if weight > result[term] {
+				result[term] = weight
+			}
+		}
+	}
+	add(document.Name, nameWeight)
+	add(document.Description, descriptionWeight)
+	for _, values := range []map[string]string{document.Labels, document.Meta} {
+		for key, value := range values {
+			add(key, labelWeight)
+			add(value, labelWeight)
+		}
+	}
+	return result
+}
+
+// Hit is a document matching every word of a query, Names are the names
+// of the entities from the building down to the entity
+type Hit struct {
+	Path  string
+	Names []string
+	Score float64
+	Document
+}
+
+// Index is an inverted index of the documents keyed by their path e.g.
+// building-id/floor-id, it is safe for concurrent use
+type Index struct {
+	mutex     sync.RWMutex
+	loaded    bool
+	documents map[string]Document
+	postings  map[string]map[string]float64
+}
+
+// NewIndex returns an empty Index which is yet to be loaded
+func NewIndex() *Index {
+	return &Index{documents: map[string]Document{}, postings: map[string]map[string]float64{}}
+}
+
+// Loaded returns true once the documents are loaded
+func (index *Index) Loaded() bool {
+	index.mutex.RLock()
+	defer index.mutex.RUnlock()
+	return index.loaded
+}
+
+// Load replaces every document of the index
+func (index *Index) Load(documents map[string]Document) {
+	index.mutex.Lock()
+	defer index.mutex.Unlock()
+	index.documents = map[string]Document{}
+	index.postings = map[string]map[string]float64{}
+	for entity, document := range documents {
+		index.add(entity, document)
+	}
+	index.loaded = true
+}
+
+// Reset drops the documents so that the index is loaded again
+func (index *Index) Reset() {
+	index.mutex.Lock()
+	defer index.mutex.Unlock()
+	index.documents = map[string]Document{}
+	index.postings = map[string]map[string]float64{}
+	index.loaded = false
+}
+
+// Replace replaces the children of the parent path with the documents
+// keyed by id, the children missing from the documents are removed along
+// with the documents nested under them. The index is left as is until it
+// is loaded
+func (index *Index) Replace(parent string, documents map[string]Document) {
+	index.mutex.Lock()
+	defer index.mutex.Unlock()
+	if !index.loaded {
+		return
+	}
+
+	for entity := range index.documents {
+		child, ok := ChildOf(parent, entity)
+		if !ok {
+			continue
+		}
+		if _, ok := documents[child]; !ok || path.Join(parent, child) == entity {
+			index.remove(entity)
+		}
+	}
+	for id, document := range documents {
+		index.add(path.Join(parent, id), document)
+	}
+}
+
+func (index *Index) add(entity string, document Document) {
+	index.documents[entity] = document
+	for term, weight := range document.terms() {
+		if index.postings[term] == nil {
+			index.postings[term] = map[string]float64{}
+		}
+		index.postings[term][entity] = weight
+	}
+}
+
+func (index *Index) remove(entity string) {
+	for term := range index.documents[entity].terms() {
+		delete(index.postings[term], entity)
+		if len(index.postings[term]) == 0 {
+			delete(index.postings, term)
+		}
+	}
+	delete(index.documents, entity)
+}
+
+// Search returns the documents matching every word of the query ordered
+// by their score and then by their path. A word matches the document or
+// the building, the floor and the room it is in with a lower score, at
+// least one word has to match the document itself
+func (index *Index) Search(query string) []Hit {
+	index.mutex.RLock()
+	defer index.mutex.RUnlock()
+
+	words := Terms(query)
+	matches := make([]map[string]float64, 0, len(words))
+	candidates := map[string]bool{}
+	for _, word := range words {
+		matched := index.matching(word)
+		for entity := range matched {
+			candidates[entity] = true
+		}
+		matches = append(matches, matched)
+	}
+
+	hits := []Hit{}
+	for entity := range candidates {
+		ids := strings.Split(entity, "/")
+		score := 0.0
+		for _, matched := range matches {
+			best := matched[entity]
+			for i := 1; i < len(ids) && best == 0; i++ {
+				best = matched[strings.Join(ids[:len(ids)-i], "/")] / nameWeight
+			}
+			if best == 0 {
+				score = 0
+				break
+			}
+			score += best
+		}
+		if score > 0 {
+			hits = append(hits, Hit{Path: entity, Names: index.names(entity), Score: score, Document: index.documents[entity]})
+		}
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].Path < hits[j].Path
+	})
+	return hits
+}
+
+// matching returns the score of the documents matching the word keyed by
+// their path
+func (index *Index) matching(word string) map[string]float64 {
+	matched := map[string]float64{}
+	for term, postings := range index.postings {
+		quality := match(word, term)
+		if quality == 0 {
+			continue
+		}
+		for entity, weight := range postings {
+			if score := quality * weight; score > matched[entity] {
+				matched[entity] = score
+			}
+		}
+	}
+	return matched
+}
+
+// names returns the names of the entities along the path, the id is used
+// for the entities which are not indexed
+func (index *Index) names(entity string) []string {
+	ids := strings.Split(entity, "/")
+	names := make([]string, 0, len(ids))
+	for i := range ids {
+		name := index.documents[strings.Join(ids[:i+1], "/")].Name
+		if name == "" {
+			name = ids[i]
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// Terms returns the lower cased words of the text split on everything
+// other than letters and digits
+func Terms(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(char rune) bool {
+		return !unicode.IsLetter(char) && !unicode.IsDigit(char)
+	})
+}
+
+// match returns how well the word of the query matches the term, 1 when
+// equal, 0.75 as a prefix of the term and less with every typo
+func match(word, term string) float64 {
+	if word == term {
+		return 1
+	}
+	if strings.HasPrefix(term, word) {
+		return 0.75
+	}
+	typos := allowedTypos(word)
+	if typos == 0 {
+		return 0
+	}
+	if edits := distance([]rune(word), []rune(term), typos); edits <= typos {
+		return 0.5 / float64(edits)
+	}
+	return 0
+}
+
+// allowedTypos allows a typo in words of 4 letters and two typos in words
+// of 8 letters
+func allowedTypos(word string) int {
+	switch length := len([]rune(word)); {
+	case length >= 8:
+		return 2
+	case length >= 4:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// distance returns the number of insertions, deletions, substitutions and
+// transpositions of adjacent letters turning a into b, it stops counting
+// once the edits exceed max
+func distance(a, b []rune, max int) int {
+	if diff := len(a) - len(b); diff > max || -diff > max {
+		return max + 1
+	}
+	rows := make([][]int, len(a)+1)
+	for i := range rows {
+		rows[i] = make([]int, len(b)+1)
+		rows[i][0] = i
+	}
+	for j := range rows[0] {
+		rows[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		lowest := rows[i][0]
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			rows[i][j] = minOf(rows[i-1][j]+1, rows[i][j-1]+1, rows[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				rows[i][j] = minOf(rows[i][j], rows[i-2][j-2]+1)
+			}
+			if rows[i][j] < lowest {
+				lowest = rows[i][j]
+			}
+		}
+		if lowest > max {
+			return max + 1
+		}
+	}
+	return rows[len(a)][len(b)]
+}
+
+func minOf(values ...int) int {
+	result := values[0]
+	for _, value := range values[1:] {
+		if value < result {
+			result = value
+		}
+	}
+	return result
+}
+
+// ChildOf returns the id of the child of the parent path which is or
+// contains the entity e.g. floor-id for building-id and
+// building-id/floor-id/room-id, false when the entity is not under the
+// parent. An empty parent is the root of the buildings
+func ChildOf(parent, entity string) (string, bool) {
+	if parent != "" {
+		if !strings.HasPrefix(entity, parent+"/") {
+			return "", false
+		}
+		entity = strings.TrimPrefix(entity, parent+"/")
+	}
+	if entity == "" {
+		return "", false
+	}
+	return strings.SplitN(entity, "/", 2)[0], true
+}
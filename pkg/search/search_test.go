@@ -0,0 +1,127 @@
+package search_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/search"
+)
+
+func newIndex() *search.Index {
+	index := search.NewIndex()
+	index.Load(map[string]search.Document{
+		"home":                               {Name: "home"},
+		"home/first":                         {Name: "first floor"},
+		"home/first/guest-bathroom":          {Name: "guest bathroom", Description: "next to the guest bedroom"},
+		"home/first/guest-bathroom/geyser":   {Name: "geyser", Meta: map[string]string{"capability": "heater"}},
+		"home/first/guest-bedroom":           {Name: "guest bedroom"},
+		"home/first/guest-bedroom/lamp":      {Name: "bedside lamp", Labels: map[string]string{"env": "indoor"}},
+		"home/ground/kitchen/exhaust-fan":    {Name: "exhaust fan", Description: "above the stove"},
+		"farm-house/ground/porch/porch-lamp": {Name: "porch lamp", Labels: map[string]string{"env": "outdoor"}},
+	})
+	return index
+}
+
+func paths(hits []search.Hit) []string {
+	result := []string{}
+	for _, hit := range hits {
+		result = append(result, hit.Path)
+	}
+	return result
+}
+
+func TestIndex_Search(t *testing.T) {
+	index := newIndex()
+
+	t.Run("should match every word of the query in the entity or in the names of its location", func(t *testing.T) {
+		hits := index.Search("guest bathroom geyser")
+
+		if assert.Len(t, hits, 1) {
+			assert.Equal(t, "home/first/guest-bathroom/geyser", hits[0].Path)
+			assert.Equal(t, []string{"home", "first floor", "guest bathroom", "geyser"}, hits[0].Names)
+		}
+		assert.Equal(t, []string{"home/first/guest-bathroom"}, paths(index.Search("Guest Bathroom")))
+		assert.Equal(t, []string{"home/first/guest-bathroom", "home/first/guest-bedroom"}, paths(index.Search("guest")))
+	})
+
+	t.Run("should match the prefixes and the typos", func(t *testing.T) {
+		assert.Equal(t, []string{"home/first/guest-bathroom/geyser"}, paths(index.Search("geys")))
+		assert.Equal(t, []string{"home/first/guest-bathroom/geyser"}, paths(index.Search("gyser")))
+		assert.Equal(t, []string{"home/ground/kitchen/exhaust-fan"}, paths(index.Search("exhuast")))
+		assert.Empty(t, index.Search("fun"))
+	})
+
+	t.Run("should match the labels and the meta", func(t *testing.T) {
+		assert.Equal(t, []string{"farm-house/ground/porch/porch-lamp"}, paths(index.Search("outdoor lamp")))
+		assert.Equal(t, []string{"home/first/guest-bathroom/geyser"}, paths(index.Search("heater")))
+	})
+
+	t.Run("should rank the exact matches above the prefixes and the typos", func(t *testing.T) {
+		hits := index.Search("lamp")
+
+		assert.Equal(t, []string{"farm-house/ground/porch/porch-lamp", "home/first/guest-bedroom/lamp"}, paths(hits))
+		assert.Equal(t, 3.0, hits[0].Score)
+	})
+}
+
+func TestIndex_Replace(t *testing.T) {
+	t.Run("should replace the children along with the documents nested under them", func(t *testing.T) {
+		index := newIndex()
+
+		index.Replace("home/first", map[string]search.Document{"guest-bedroom": {Name: "guest room"}})
+
+		assert.Empty(t, index.Search("bathroom"))
+		assert.Empty(t, index.Search("bedroom"))
+		assert.Equal(t, []string{"home/first/guest-bedroom"}, paths(index.Search("guest")))
+		hits := index.Search("bedside")
+		if assert.Len(t, hits, 1) {
+			assert.Equal(t, []string{"home", "first floor", "guest room", "bedside lamp"}, hits[0].Names)
+		}
+	})
+
+	t.Run("should be left as is until loaded", func(t *testing.T) {
+		index := search.NewIndex()
+
+		index.Replace("", map[string]search.Document{"home": {Name: "home"}})
+
+		assert.False(t, index.Loaded())
+		assert.Empty(t, index.Search("home"))
+	})
+
+	t.Run("should drop the documents once reset", func(t *testing.T) {
+		index := newIndex()
+
+		index.Reset()
+
+		assert.False(t, index.Loaded())
+		assert.Empty(t, index.Search("home"))
+	})
+}
+
+func TestTerms(t *testing.T) {
+	assert.Equal(t, []string{"guest", "bathroom", "geyser", "2"}, search.Terms("Guest-Bathroom, geyser #2"))
+}
+
+func TestChildOf(t *testing.T) {
+	t.Run("should return the child of the parent containing the entity", func(t *testing.T) {
+		child, ok := search.ChildOf("home", "home/ground/kitchen")
+
+		assert.True(t, ok)
+		assert.Equal(t, "ground", child)
+	})
+
+	t.Run("should return the building for the root", func(t *testing.T) {
+		child, ok := search.ChildOf("", "home/ground")
+
+		assert.True(t, ok)
+		assert.Equal(t, "home", child)
+	})
+
+	t.Run("should not return the entities outside the parent", func(t *testing.T) {
+		for _, entity := range []string{"home", "homestead/ground", "office/ground"} {
+			_, ok := search.ChildOf("home", entity)
+
+			assert.False(t, ok, entity)
+		}
+	})
+}
@@ -0,0 +1,90 @@
+package solar
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// Dawn is the civil dawn, the sky is bright enough to do without
+	// lights outdoors
+	Dawn = "dawn"
+	// Sunrise is the time the upper edge of the sun rises
+	Sunrise = "sunrise"
+	// Sunset is the time the upper edge of the sun sets
+	Sunset = "sunset"
+	// Dusk is the civil dusk, lights are needed outdoors afterwards
+	Dusk = "dusk"
+
+	expectedEvents = "dawn, sunrise, sunset or dusk"
+)
+
+// events are ordered by the time they occur during the day
+var events = []string{Dawn, Sunrise, Sunset, Dusk}
+
+var altitudes = map[string]float64{
+	Dawn:    civilTwilight,
+	Sunrise: horizon,
+	Sunset:  horizon,
+	Dusk:    civilTwilight,
+}
+
+// Moment is a solar event moved by an offset e.g. sunset-15m is a
+// quarter of an hour before the sunset
+type Moment struct {
+	Event  string
+	Offset time.Duration
+}
+
+// ParseMoment parses the moment given as the event optionally followed
+// by a signed duration e.g. dusk, sunset-15m or sunrise+1h30m
+func ParseMoment(value string) (Moment, error) {
+	for _, event := range events {
+		if !strings.HasPrefix(value, event) {
+			continue
+		}
+
+		offset := strings.TrimPrefix(value, event)
+		if offset == "" {
+			return Moment{Event: event}, nil
+		}
+		duration, err := time.ParseDuration(offset)
+		if err != nil || (offset[0] != '+' && offset[0] != '-') {
+			return Moment{}, fmt.Errorf("offset %s of %s should be a signed duration e.g. %s-15m", offset, event, event)
+		}
+		return Moment{Event: event, Offset: duration}, nil
+	}
+	return Moment{}, fmt.Errorf("moment %s should be %s optionally followed by an offset e.g. sunset-15m", value, expectedEvents)
+}
+
+// At returns the time in UTC of the moment on the date at the latitude
+// and the longitude
+func (moment Moment) At(date time.Time, latitude, longitude float64) (time.Time, error) {
+	at, err := Time(moment.Event, date, latitude, longitude)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return at.Add(moment.Offset), nil
+}
+
+// String returns the moment in the format accepted by ParseMoment
+func (moment Moment) String() string {
+	// the zero seconds and minutes are left out e.g. 1h30m rather than 1h30m0s
+	offset := moment.Offset.String()
+	if strings.HasSuffix(offset, "m0s") {
+		offset = strings.TrimSuffix(offset, "0s")
+	}
+	if strings.HasSuffix(offset, "h0m") {
+		offset = strings.TrimSuffix(offset, "0m")
+	}
+
+	switch {
+	case moment.Offset > 0:
+		return moment.Event + "+" + offset
+	case moment.Offset < 0:
+		return moment.Event + offset
+	default:
+		return moment.Event
+	}
+}
@@ -0,0 +1,113 @@
+package solar
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	// julian2000 is the julian day of 2000-01-01 12:00 UTC
+	julian2000 = 2451545.0
+	// julianUnix is the julian day of 1970-01-01 00:00 UTC
+	julianUnix = 2440587.5
+	// horizon is the altitude of the center of the sun at the sunrise and
+	// the sunset, the refraction and the radius of the sun are included
+	horizon = -0.833
+	// civilTwilight is the altitude of the center of the sun at the civil
+	// dawn and the civil dusk
+	civilTwilight = -6.0
+	// obliquity is the axial tilt of the earth
+	obliquity = 23.4397
+)
+
+// Times represents the solar events of a day at a location
+type Times struct {
+	Dawn    time.Time `json:"dawn"`
+	Sunrise time.Time `json:"sunrise"`
+	Sunset  time.Time `json:"sunset"`
+	Dusk    time.Time `json:"dusk"`
+}
+
+// Calculate returns the civil dawn, the sunrise, the sunset and the civil
+// dusk in UTC of the date at the latitude and the longitude, the longitude
+// is positive towards east. The times are accurate to a minute or so, an
+// error is returned when any of them does not occur on the date e.g. a
+// polar night or a white night
+func Calculate(date time.Time, latitude, longitude float64) (Times, error) {
+	times := Times{}
+	for i, at := range []*time.Time{&times.Dawn, &times.Sunrise, &times.Sunset, &times.Dusk} {
+		var err error
+		*at, err = Time(events[i], date, latitude, longitude)
+		if err != nil {
+			return Times{}, err
+		}
+	}
+	return times, nil
+}
+
+// Time returns the time in UTC of the event on the date at the latitude
+// and the longitude, an error is returned when the event does not occur
+// on the date
+func Time(event string, date time.Time, latitude, longitude float64) (time.Time, error) {
+	altitude, ok := altitudes[event]
+	if !ok {
+		return time.Time{}, fmt.Errorf("event %s not supported, expected %s", event, expectedEvents)
+	}
+
+	transit, declination := transit(date, longitude)
+	hourAngle, err := hourAngle(latitude, declination, altitude)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%v on %s at %.4f, %.4f", err, date.Format("2006-01-02"), latitude, longitude)
+	}
+	if event == Dawn || event == Sunrise {
+		return fromJulian(transit - hourAngle/360), nil
+	}
+	return fromJulian(transit + hourAngle/360), nil
+}
+
+// transit returns the julian day of the solar noon of the date at the
+// longitude along with the declination of the sun
+func transit(date time.Time, longitude float64) (float64, float64) {
+	midnight := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	days := math.Ceil(toJulian(midnight) - julian2000 + 0.0008)
+	noon := days - longitude/360
+
+	anomaly := math.Mod(357.5291+0.98560028*noon, 360)
+	center := 1.9148*sin(anomaly) + 0.0200*sin(2*anomaly) + 0.0003*sin(3*anomaly)
+	ecliptic := math.Mod(anomaly+center+180+102.9372, 360)
+
+	transit := julian2000 + noon + 0.0053*sin(anomaly) - 0.0069*sin(2*ecliptic)
+	declination := math.Asin(sin(ecliptic)*sin(obliquity)) * 180 / math.Pi
+	return transit, declination
+}
+
+// hourAngle returns the hour angle in degrees at which the sun reaches
+// the altitude
+func hourAngle(latitude, declination, altitude float64) (float64, error) {
+	cos := (sin(altitude) - sin(latitude)*sin(declination)) / (cosine(latitude) * cosine(declination))
+	switch {
+	case cos > 1:
+		return 0, fmt.Errorf("sun stays below %.3f°", altitude)
+	case cos < -1:
+		return 0, fmt.Errorf("sun stays above %.3f°", altitude)
+	}
+	return math.Acos(cos) * 180 / math.Pi, nil
+}
+
+func toJulian(t time.Time) float64 {
+	return float64(t.Unix())/86400 + julianUnix
+}
+
+func fromJulian(julian float64) time.Time {
+	seconds := (julian - julianUnix) * 86400
+	return time.Unix(int64(math.Round(seconds)), 0).UTC()
+}
+
+func sin(degrees float64) float64 {
+	return math.Sin(degrees * math.Pi / 180)
+}
+
+func cosine(degrees float64) float64 {
+	return math.Cos(degrees * math.Pi / 180)
+}
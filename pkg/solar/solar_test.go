@@ -0,0 +1,100 @@
+package solar_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/solar"
+)
+
+func parse(value string) time.Time {
+	at, _ := time.Parse(time.RFC3339, value)
+	return at
+}
+
+func TestCalculate(t *testing.T) {
+	t.Run("should calculate the civil dawn, the sunrise, the sunset and the civil dusk", func(t *testing.T) {
+		for name, expected := range map[string]struct {
+			latitude, longitude         float64
+			date                        string
+			dawn, sunrise, sunset, dusk string
+		}{
+			"bengaluru": {12.97, 77.59, "2026-10-19", "2026-10-19T00:19:00Z", "2026-10-19T00:40:00Z", "2026-10-19T12:29:00Z", "2026-10-19T12:50:00Z"},
+			"london":    {51.5074, -0.1278, "2026-06-21", "2026-06-21T02:55:00Z", "2026-06-21T03:43:00Z", "2026-06-21T20:21:00Z", "2026-06-21T21:09:00Z"},
+			"new york":  {40.7128, -74.006, "2026-12-21", "2026-12-21T11:46:00Z", "2026-12-21T12:16:00Z", "2026-12-21T21:31:00Z", "2026-12-21T22:02:00Z"},
+			"sydney":    {-33.8688, 151.2093, "2026-01-01", "2025-12-31T18:18:00Z", "2025-12-31T18:47:00Z", "2026-01-01T09:09:00Z", "2026-01-01T09:38:00Z"},
+		} {
+			date, _ := time.Parse("2006-01-02", expected.date)
+
+			actual, err := solar.Calculate(date, expected.latitude, expected.longitude)
+
+			if assert.NoError(t, err, name) {
+				assert.WithinDuration(t, parse(expected.dawn), actual.Dawn, time.Minute, name)
+				assert.WithinDuration(t, parse(expected.sunrise), actual.Sunrise, time.Minute, name)
+				assert.WithinDuration(t, parse(expected.sunset), actual.Sunset, time.Minute, name)
+				assert.WithinDuration(t, parse(expected.dusk), actual.Dusk, time.Minute, name)
+			}
+		}
+	})
+
+	t.Run("should fail when the sun does not rise", func(t *testing.T) {
+		date, _ := time.Parse("2006-01-02", "2026-12-21")
+
+		_, err := solar.Calculate(date, 78.22, 15.65)
+
+		assert.EqualError(t, err, "sun stays below -6.000° on 2026-12-21 at 78.2200, 15.6500")
+	})
+
+	t.Run("should fail when the night stays bright but still return the sunset", func(t *testing.T) {
+		date, _ := time.Parse("2006-01-02", "2026-06-21")
+
+		_, err := solar.Calculate(date, 63.43, 10.39)
+		assert.EqualError(t, err, "sun stays above -6.000° on 2026-06-21 at 63.4300, 10.3900")
+
+		sunset, err := solar.Time(solar.Sunset, date, 63.43, 10.39)
+		if assert.NoError(t, err) {
+			assert.WithinDuration(t, parse("2026-06-21T21:38:00Z"), sunset, time.Minute)
+		}
+	})
+}
+
+func TestParseMoment(t *testing.T) {
+	t.Run("should parse the event along with the offset", func(t *testing.T) {
+		for value, expected := range map[string]solar.Moment{
+			"dusk":          {Event: solar.Dusk},
+			"sunset-15m":    {Event: solar.Sunset, Offset: -15 * time.Minute},
+			"sunrise+1h30m": {Event: solar.Sunrise, Offset: 90 * time.Minute},
+		} {
+			actual, err := solar.ParseMoment(value)
+
+			if assert.NoError(t, err, value) {
+				assert.Equal(t, expected, actual, value)
+				assert.Equal(t, value, actual.String())
+			}
+		}
+	})
+
+	t.Run("should fail on unknown event or unsigned offset", func(t *testing.T) {
+		for value, message := range map[string]string{
+			"noon":      "moment noon should be dawn, sunrise, sunset or dusk optionally followed by an offset e.g. sunset-15m",
+			"sunset15m": "offset 15m of sunset should be a signed duration e.g. sunset-15m",
+			"dawn-soon": "offset -soon of dawn should be a signed duration e.g. dawn-15m",
+		} {
+			_, err := solar.ParseMoment(value)
+
+			assert.EqualError(t, err, message, value)
+		}
+	})
+
+	t.Run("should move the event by the offset", func(t *testing.T) {
+		date, _ := time.Parse("2006-01-02", "2026-10-19")
+		moment, _ := solar.ParseMoment("sunset-15m")
+
+		actual, err := moment.At(date, 12.97, 77.59)
+
+		if assert.NoError(t, err) {
+			assert.WithinDuration(t, parse("2026-10-19T12:14:00Z"), actual, time.Minute)
+		}
+	})
+}
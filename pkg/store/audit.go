@@ -0,0 +1,139 @@
+package store
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kvtools/valkeyrie/store"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/audit"
+)
+
+// the audit stream is kept under a root of its own next to the base path so
+// that reading the tree never reads the stream, every entry is a key of its
+// own so that appending never rewrites the entries before it. The entries
+// are keyed by the month and by the entity so that a query lists only the
+// months since the time or the entity it asks for. The months which have
+// entries are kept as keys of their own so that a copy lists the stream a
+// month at a time
+const (
+	auditPath        = "_audit"
+	auditByTime      = "time"
+	auditByEntity    = "entity"
+	auditMonths      = "months"
+	auditEntriesPath = "_entries"
+	auditMonthLayout = "2006-01"
+)
+
+// AuditRootPath returns the root of the audit stream of the base path
+func AuditRootPath(basePath string) string {
+	return path.Join(auditPath, basePath)
+}
+
+// AppendAudit appends the entry to the audit stream in store
+func (ps PersistentStore) AppendAudit(entry audit.Entry) error {
+	err := ps.putJSON(path.Join(ps.auditMonthPath(entry.Time), entry.ID), entry)
+	if err != nil {
+		return err
+	}
+	month := entry.Time.UTC().Format(auditMonthLayout)
+	err = ps.kvStore.Put(path.Join(AuditRootPath(ps.path), auditMonths, month), []byte(month), nil)
+	if err != nil || entry.Entity == "" {
+		return err
+	}
+	return ps.putJSON(path.Join(ps.auditEntityPath(entry.Entity), auditEntriesPath, entry.ID), entry)
+}
+
+// Audit returns the entries of the audit stream matching the query,
+// ordered from the oldest to the latest
+func (ps PersistentStore) Audit(query audit.Query) (audit.Entries, error) {
+	var pairs []*store.KVPair
+	var err error
+	switch {
+	case query.Entity != "":
+		pairs, err = ps.listAudit(ps.auditEntityPath(query.Entity))
+	case query.Since.IsZero():
+		pairs, err = ps.listAudit(path.Join(AuditRootPath(ps.path), auditByTime))
+	default:
+		pairs, err = ps.listAuditSince(query.Since, query.Until)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// the ids start with the time so that the entries out of the query
+	// are skipped before they are parsed
+	since, until := auditID(query.Since), auditID(query.Until)
+	entries := audit.Entries{}
+	for _, pair := range pairs {
+		id := path.Base(pair.Key)
+		if query.Entity != "" && path.Base(path.Dir(pair.Key)) != auditEntriesPath {
+			continue
+		}
+		if (since != "" && id < since) || (until != "" && id >= until) {
+			continue
+		}
+		entry, err := audit.NewEntryFromJSON(pair.Value)
+		if err != nil {
+			return nil, err
+		}
+		if query.Matches(entry) {
+			entries = append(entries, entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].ID < entries[j].ID })
+	return entries, nil
+}
+
+// listAuditSince lists the months from the time since up to the time until,
+// or up to now when it is not given
+func (ps PersistentStore) listAuditSince(since, until time.Time) ([]*store.KVPair, error) {
+	if until.IsZero() {
+		until = time.Now()
+	}
+	last := monthOf(until)
+	var pairs []*store.KVPair
+	for month := monthOf(since); !month.After(last); month = month.AddDate(0, 1, 0) {
+		listed, err := ps.listAudit(ps.auditMonthPath(month))
+		if err != nil {
+			return nil, err
+		}
+		pairs = append(pairs, listed...)
+	}
+	return pairs, nil
+}
+
+// listAudit lists the keys under the prefix, the prefix is ended by a
+// slash so that the list never matches the keys next to it e.g. home-two
+// for home
+func (ps PersistentStore) listAudit(prefix string) ([]*store.KVPair, error) {
+	pairs, err := ps.kvStore.List(prefix+"/", nil)
+	if err == store.ErrKeyNotFound {
+		return nil, nil
+	}
+	return pairs, err
+}
+
+func (ps PersistentStore) auditMonthPath(at time.Time) string {
+	return path.Join(AuditRootPath(ps.path), auditByTime, at.UTC().Format(auditMonthLayout))
+}
+
+func (ps PersistentStore) auditEntityPath(entity string) string {
+	return path.Join(AuditRootPath(ps.path), auditByEntity, strings.Trim(entity, "/"))
+}
+
+// auditID returns the prefix of the ids of the entries appended at the
+// time, empty for the zero time
+func auditID(at time.Time) string {
+	if at.IsZero() {
+		return ""
+	}
+	return fmt.Sprintf("%019d", at.UnixNano())
+}
+
+func monthOf(at time.Time) time.Time {
+	at = at.UTC()
+	return time.Date(at.Year(), at.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,103 @@
+package store_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	libKVStore "github.com/kvtools/valkeyrie/store"
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/audit"
+	mockKVStore "gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/internal/mocks/valkeyrie/store"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/store"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/testutils"
+)
+
+func TestPersistentStore_Audit(t *testing.T) {
+	now := time.Date(2026, 10, 19, 3, 0, 0, 0, time.UTC)
+	newEntry := func(t *testing.T, at time.Time, entity string) audit.Entry {
+		entry, err := audit.NewEntry(at, audit.Anonymous, "DELETE", "/v1/buildings/"+entity, entity, 200)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return entry
+	}
+
+	t.Run("should append and query entries ordered by time", func(t *testing.T) {
+		kvStore, _ := newBoltDB(t)
+		persistentStore := store.NewPersistentStore("dwarka", kvStore)
+		second := newEntry(t, now.Add(time.Minute), "home/terrace")
+		first := newEntry(t, now, "home")
+		third := newEntry(t, now.Add(time.Hour), "guest-house")
+		for _, entry := range []audit.Entry{second, first, third} {
+			assert.NoError(t, persistentStore.AppendAudit(entry))
+		}
+
+		entries, err := persistentStore.Audit(audit.Query{})
+		if assert.NoError(t, err) {
+			assert.Equal(t, audit.Entries{first, second, third}, entries)
+		}
+
+		entries, err = persistentStore.Audit(audit.Query{Entity: "home", Since: now.Add(time.Second)})
+		if assert.NoError(t, err) {
+			assert.Equal(t, audit.Entries{second}, entries)
+		}
+	})
+
+	t.Run("should keep the stream out of the tree", func(t *testing.T) {
+		kvStore, _ := newBoltDB(t)
+		persistentStore := store.NewPersistentStore("dwarka", kvStore)
+		assert.NoError(t, persistentStore.UpsertBuilding(testutils.NewBuilding("home")))
+		assert.NoError(t, persistentStore.AppendAudit(newEntry(t, now, "home")))
+
+		pairs, err := kvStore.List("dwarka", nil)
+
+		if assert.NoError(t, err) && assert.Len(t, pairs, 2) {
+			assert.Equal(t, "dwarka/_labels/home", pairs[0].Key)
+			assert.Equal(t, "dwarka/buildings", pairs[1].Key)
+		}
+	})
+
+	t.Run("should list only the months since the time", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		entry := newEntry(t, now, "home")
+		data, _ := json.Marshal(entry)
+		mockStore := mockKVStore.NewMockStore(ctrl)
+		mockStore.EXPECT().List("_audit/dwarka/time/2026-09/", nil).Return(nil, libKVStore.ErrKeyNotFound)
+		mockStore.EXPECT().List("_audit/dwarka/time/2026-10/", nil).Return([]*libKVStore.KVPair{
+			{Key: "_audit/dwarka/time/2026-10/" + entry.ID, Value: data},
+			{Key: "_audit/dwarka/time/2026-10/0000000000000000001-0a1b2c3d", Value: []byte("corrupted")},
+		}, nil)
+
+		entries, err := store.NewPersistentStore("dwarka", mockStore).Audit(audit.Query{Since: now.AddDate(0, -1, 0), Until: now.Add(time.Hour)})
+
+		assert.NoError(t, err)
+		assert.Equal(t, audit.Entries{entry}, entries)
+	})
+
+	t.Run("should return no entries for empty store", func(t *testing.T) {
+		kvStore, _ := newBoltDB(t)
+
+		entries, err := store.NewPersistentStore("dwarka", kvStore).Audit(audit.Query{})
+
+		assert.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+
+	t.Run("should keep the entries along with a building named audit", func(t *testing.T) {
+		kvStore, _ := newBoltDB(t)
+		persistentStore := store.NewPersistentStore("dwarka", kvStore)
+		assert.NoError(t, persistentStore.AppendAudit(newEntry(t, now, "home")))
+
+		assert.NoError(t, persistentStore.DeleteBuilding(testutils.NewBuilding("audit")))
+
+		entries, err := persistentStore.Audit(audit.Query{})
+		assert.NoError(t, err)
+		assert.Len(t, entries, 1)
+		problems, err := store.Verify(kvStore, "dwarka")
+		assert.NoError(t, err)
+		assert.Empty(t, problems)
+	})
+}
@@ -0,0 +1,53 @@
+package store
+
+import (
+	"fmt"
+	"path"
+
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+)
+
+const automationsBasePath = "_automations"
+
+// Automations returns all the Automations of the building from store
+func (ps PersistentStore) Automations(building gateway.Entity) (gateway.Automations, error) {
+	value, err := ps.get(ps.automationsRootPath(building), gateway.Automations{})
+	if err != nil {
+		return nil, err
+	}
+	return gateway.NewAutomations(building, value)
+}
+
+// UpsertAutomations creates or updates Automations in store
+func (ps PersistentStore) UpsertAutomations(building gateway.Entity, automations gateway.Automations) error {
+	return ps.putJSON(ps.automationsRootPath(building), automations)
+}
+
+// UpsertAutomation creates or updates Automation in store
+func (ps PersistentStore) UpsertAutomation(automation gateway.Automation) error {
+	automations, err := ps.Automations(automation.Building)
+	if err != nil {
+		return err
+	}
+	automations[automation.ID()] = automation
+	return ps.putJSON(ps.automationsRootPath(automation.Building), automations)
+}
+
+// DeleteAutomation deletes the automation from store, NotFound is
+// returned when the automation does not exist
+func (ps PersistentStore) DeleteAutomation(automation gateway.Automation) error {
+	automations, err := ps.Automations(automation.Building)
+	if err != nil {
+		return err
+	}
+	if _, ok := automations[automation.ID()]; !ok {
+		return NotFound(fmt.Sprintf("automation %s not found", automation.ID()))
+	}
+
+	delete(automations, automation.ID())
+	return ps.putJSON(ps.automationsRootPath(automation.Building), automations)
+}
+
+func (ps PersistentStore) automationsRootPath(building gateway.Entity) string {
+	return path.Join(ps.buildingRootPath(building), automationsBasePath)
+}
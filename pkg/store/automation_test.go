@@ -0,0 +1,50 @@
+package store_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/solar"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/store"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/testutils"
+)
+
+func TestPersistentStore_Automations(t *testing.T) {
+	building := testutils.NewBuilding("building-one")
+	automation := gateway.Automation{
+		Building:       building,
+		Triggers:       []gateway.Trigger{{Type: gateway.TriggerSun, Event: solar.Sunset, Offset: "-15m"}},
+		Actions:        []gateway.Action{{Type: gateway.ActionDevice, Device: "floor-one/room-one/porch-light", State: gateway.StateOn}},
+		PhysicalEntity: gateway.PhysicalEntity{Name: "porch at dusk"},
+	}
+
+	t.Run("should upsert and delete automation", func(t *testing.T) {
+		kvStore, _ := newBoltDB(t)
+		persistentStore := store.NewPersistentStore("dwarka", kvStore)
+		assert.NoError(t, persistentStore.UpsertBuilding(building))
+
+		assert.NoError(t, persistentStore.UpsertAutomation(automation))
+		automations, err := persistentStore.Automations(building)
+		if assert.NoError(t, err) {
+			assert.Equal(t, gateway.Automations{"porch-at-dusk": automation}, automations)
+		}
+		problems, err := store.Verify(kvStore, "dwarka")
+		assert.NoError(t, err)
+		assert.Empty(t, problems)
+
+		assert.NoError(t, persistentStore.DeleteAutomation(automation))
+		automations, err = persistentStore.Automations(building)
+		if assert.NoError(t, err) {
+			assert.Empty(t, automations)
+		}
+	})
+
+	t.Run("should fail to delete missing automation", func(t *testing.T) {
+		kvStore, _ := newBoltDB(t)
+
+		err := store.NewPersistentStore("dwarka", kvStore).DeleteAutomation(automation)
+
+		assert.Equal(t, store.NotFound("automation porch-at-dusk not found"), err)
+	})
+}
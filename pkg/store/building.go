@@ -2,6 +2,7 @@ package store
 
 import (
 	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/search"
 	"path"
 )
 
@@ -20,7 +21,7 @@ func (ps PersistentStore) Buildings() (gateway.Buildings, error) {
 
 // UpsertBuildings creates or updates Buildings in store
 func (ps PersistentStore) UpsertBuildings(buildings gateway.Buildings) error {
-	return ps.putJSON(ps.buildingsRootPath(), buildings)
+	return ps.putBuildings(buildings)
 }
 
 // UpsertBuilding creates or updates Building in store
@@ -30,7 +31,7 @@ func (ps PersistentStore) UpsertBuilding(building gateway.Building) error {
 		return err
 	}
 	buildings[building.ID()] = building
-	return ps.putJSON(ps.buildingsRootPath(), buildings)
+	return ps.putBuildings(buildings)
 }
 
 // DeleteBuilding deletes the building and nested path from store
@@ -41,7 +42,7 @@ func (ps PersistentStore) DeleteBuilding(building gateway.Building) error {
 	}
 
 	delete(buildings, building.ID())
-	err = ps.putJSON(ps.buildingsRootPath(), buildings)
+	err = ps.putBuildings(buildings)
 	if err != nil {
 		return err
 	}
@@ -49,6 +50,19 @@ func (ps PersistentStore) DeleteBuilding(building gateway.Building) error {
 	return ps.safeDelete(ps.buildingRootPath(building))
 }
 
+// putBuildings persists the buildings and indexes their labels
+func (ps PersistentStore) putBuildings(buildings gateway.Buildings) error {
+	err := ps.putJSON(ps.buildingsRootPath(), buildings)
+	if err != nil {
+		return err
+	}
+	documents := map[string]search.Document{}
+	for id, building := range buildings {
+		documents[id] = documentOf(building.PhysicalEntity, nil)
+	}
+	return ps.index("", documents)
+}
+
 func (ps PersistentStore) buildingsRootPath() string {
 	return path.Join(ps.path, buildingsBasePath)
 }
@@ -90,6 +90,7 @@ func TestPersistentStore_UpsertBuilding(t *testing.T) {
 			},
 		)
 
+		expectLabelIndex(mockStore)
 		persistentStore := store.NewPersistentStore("dwarka", mockStore)
 		err := persistentStore.UpsertBuilding(building)
 		assert.NoError(t, err)
@@ -176,6 +177,7 @@ func TestPersistentStore_UpsertBuildings(t *testing.T) {
 			},
 		)
 
+		expectLabelIndex(mockStore)
 		persistentStore := store.NewPersistentStore("dwarka", mockStore)
 		err := persistentStore.UpsertBuildings(buildings)
 		assert.NoError(t, err)
@@ -239,6 +241,7 @@ func TestPersistentStore_DeleteBuilding(t *testing.T) {
 			},
 		)
 
+		expectLabelIndex(mockStore)
 		persistentStore := store.NewPersistentStore("dwarka", mockStore)
 		err := persistentStore.DeleteBuilding(building)
 		assert.NoError(t, err)
@@ -264,6 +267,7 @@ func TestPersistentStore_DeleteBuilding(t *testing.T) {
 		mockStore.EXPECT().Put("dwarka/buildings", gomock.Any(), nil).Return(nil)
 		mockStore.EXPECT().DeleteTree("dwarka/building-one").Return(fmt.Errorf("unable to delete"))
 
+		expectLabelIndex(mockStore)
 		persistentStore := store.NewPersistentStore("dwarka", mockStore)
 		err := persistentStore.DeleteBuilding(building)
 		if assert.Error(t, err) {
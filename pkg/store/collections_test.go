@@ -0,0 +1,136 @@
+package store_test
+
+import (
+	"testing"
+	"time"
+
+	libKVStore "github.com/kvtools/valkeyrie/store"
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/solar"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/store"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/testutils"
+)
+
+func TestPersistentStore_BuildingCollections(t *testing.T) {
+	building := testutils.NewBuilding("building-one")
+	automation := gateway.Automation{
+		Building:       building,
+		Triggers:       []gateway.Trigger{{Type: gateway.TriggerSun, Event: solar.Sunset, Offset: "-15m"}},
+		Actions:        []gateway.Action{{Type: gateway.ActionDevice, Device: "floor-one/room-one/porch-light", State: gateway.StateOn}},
+		PhysicalEntity: gateway.PhysicalEntity{Name: "porch at dusk"},
+	}
+	scene := gateway.Scene{
+		Building:       building,
+		Targets:        []gateway.Target{{Floor: "floor-one", Room: "room-one", Device: "porch-light", State: gateway.StateOn}},
+		PhysicalEntity: gateway.PhysicalEntity{Name: "movie mode"},
+	}
+	schedule := gateway.Schedule{
+		Building:       building,
+		Mode:           gateway.ScheduleRecurring,
+		Cron:           "30 18 * * 1-5",
+		Missed:         gateway.MissedRunOnce,
+		Device:         "floor-one/room-one/porch-light",
+		State:          gateway.StateOn,
+		Created:        time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+		PhysicalEntity: gateway.PhysicalEntity{Name: "porch on weekdays"},
+	}
+	group := gateway.Group{
+		Building:       building,
+		Selector:       &gateway.Selector{Floor: "ground", Capability: "light", Meta: map[string]string{"zone": "exterior"}},
+		PhysicalEntity: gateway.PhysicalEntity{Name: "exterior lights"},
+	}
+
+	for _, test := range []struct {
+		collection string
+		key        string
+		stored     interface{}
+		read       func(persistentStore store.Store) error
+		upsert     func(persistentStore store.Store) error
+		delete     func(persistentStore store.Store) error
+		replace    func(persistentStore store.Store) error
+	}{
+		{
+			collection: "automations",
+			key:        "dwarka/building-one/_automations",
+			stored:     gateway.Automations{automation.ID(): automation},
+			read: func(persistentStore store.Store) error {
+				_, err := persistentStore.Automations(building)
+				return err
+			},
+			upsert: func(persistentStore store.Store) error { return persistentStore.UpsertAutomation(automation) },
+			delete: func(persistentStore store.Store) error { return persistentStore.DeleteAutomation(automation) },
+			replace: func(persistentStore store.Store) error {
+				return persistentStore.UpsertAutomations(building, gateway.Automations{})
+			},
+		},
+		{
+			collection: "scenes",
+			key:        "dwarka/building-one/_scenes",
+			stored:     gateway.Scenes{scene.ID(): scene},
+			read: func(persistentStore store.Store) error {
+				_, err := persistentStore.Scenes(building)
+				return err
+			},
+			upsert: func(persistentStore store.Store) error { return persistentStore.UpsertScene(scene) },
+			delete: func(persistentStore store.Store) error { return persistentStore.DeleteScene(scene) },
+			replace: func(persistentStore store.Store) error {
+				return persistentStore.UpsertScenes(building, gateway.Scenes{})
+			},
+		},
+		{
+			collection: "schedules",
+			key:        "dwarka/building-one/_schedules",
+			stored:     gateway.Schedules{schedule.ID(): schedule},
+			read: func(persistentStore store.Store) error {
+				_, err := persistentStore.Schedules(building)
+				return err
+			},
+			upsert: func(persistentStore store.Store) error { return persistentStore.UpsertSchedule(schedule) },
+			delete: func(persistentStore store.Store) error { return persistentStore.DeleteSchedule(schedule) },
+			replace: func(persistentStore store.Store) error {
+				return persistentStore.UpsertSchedules(building, gateway.Schedules{})
+			},
+		},
+		{
+			collection: "groups",
+			key:        "dwarka/building-one/_groups",
+			stored:     gateway.Groups{group.ID(): group},
+			read: func(persistentStore store.Store) error {
+				_, err := persistentStore.Groups(building)
+				return err
+			},
+			upsert: func(persistentStore store.Store) error { return persistentStore.UpsertGroup(group) },
+			delete: func(persistentStore store.Store) error { return persistentStore.DeleteGroup(group) },
+			replace: func(persistentStore store.Store) error {
+				return persistentStore.UpsertGroups(building, gateway.Groups{})
+			},
+		},
+	} {
+		test := test
+		for _, failing := range []struct {
+			name    string
+			kvStore func(t *testing.T) libKVStore.Store
+			calls   []func(persistentStore store.Store) error
+		}{
+			{
+				name:    "read",
+				kvStore: func(t *testing.T) libKVStore.Store { return failingReads(t, test.key) },
+				calls:   []func(persistentStore store.Store) error{test.read, test.upsert, test.delete},
+			},
+			{
+				name:    "written",
+				kvStore: func(t *testing.T) libKVStore.Store { return failingWrites(t, test.key, test.stored) },
+				calls:   []func(persistentStore store.Store) error{test.upsert, test.delete, test.replace},
+			},
+		} {
+			t.Run("should fail when the "+test.collection+" are not "+failing.name, func(t *testing.T) {
+				persistentStore := store.NewPersistentStore("dwarka", failing.kvStore(t))
+
+				for _, call := range failing.calls {
+					assert.EqualError(t, call(persistentStore), "store unavailable")
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package store
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"go.etcd.io/bbolt"
+)
+
+const compactTimeout = time.Second
+
+// CompactBoltDB rewrites the BoltDB file without the free pages left
+// behind by the deleted keys and returns the size before and after,
+// the file should not be used by a running server meanwhile
+func CompactBoltDB(filePath string) (int64, int64, error) {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	src, err := bbolt.Open(filePath, info.Mode(), &bbolt.Options{ReadOnly: true, Timeout: compactTimeout})
+	if err != nil {
+		return 0, 0, fmt.Errorf("unable to open %s, reason: %v", filePath, err)
+	}
+
+	compacted := filePath + ".compact"
+	dst, err := bbolt.Open(compacted, info.Mode(), &bbolt.Options{Timeout: compactTimeout})
+	if err != nil {
+		_ = src.Close()
+		return 0, 0, fmt.Errorf("unable to create %s, reason: %v", compacted, err)
+	}
+
+	err = dst.Update(func(dstTx *bbolt.Tx) error {
+		return src.View(func(srcTx *bbolt.Tx) error {
+			return srcTx.ForEach(func(name []byte, bucket *bbolt.Bucket) error {
+				copied, err := dstTx.CreateBucket(name)
+				if err != nil {
+					return err
+				}
+				return copyBucket(bucket, copied)
+			})
+		})
+	})
+	for _, db := range []*bbolt.DB{src, dst} {
+		if closeErr := db.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		_ = os.Remove(compacted)
+		return 0, 0, err
+	}
+
+	err = os.Rename(compacted, filePath)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	compactedInfo, err := os.Stat(filePath)
+	if err != nil {
+		return 0, 0, err
+	}
+	return info.Size(), compactedInfo.Size(), nil
+}
+
+// copyBucket copies every key along with the nested buckets
+func copyBucket(src, dst *bbolt.Bucket) error {
+	err := dst.SetSequence(src.Sequence())
+	if err != nil {
+		return err
+	}
+
+	return src.ForEach(func(key, value []byte) error {
+		if value != nil {
+			return dst.Put(key, value)
+		}
+
+		nested, err := dst.CreateBucket(key)
+		if err != nil {
+			return err
+		}
+		return copyBucket(src.Bucket(key), nested)
+	})
+}
@@ -0,0 +1,37 @@
+package store_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/store"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/testutils"
+)
+
+func TestCompactBoltDB(t *testing.T) {
+	kvStore, filePath := newBoltDB(t)
+	persistentStore := store.NewPersistentStore("dwarka", kvStore)
+	for i := 0; i < 50; i++ {
+		buildings := gateway.Buildings{}
+		for j := 0; j <= i; j++ {
+			building := testutils.NewBuilding(fmt.Sprintf("building-%02d", j))
+			buildings[building.ID()] = building
+		}
+		assert.NoError(t, persistentStore.UpsertBuildings(buildings))
+	}
+	expected, err := persistentStore.Buildings()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	before, after, err := store.CompactBoltDB(filePath)
+
+	if assert.NoError(t, err) {
+		assert.LessOrEqual(t, after, before)
+		actual, err := persistentStore.Buildings()
+		assert.NoError(t, err)
+		assert.Equal(t, expected, actual)
+	}
+}
@@ -0,0 +1,285 @@
+package store
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/kvtools/valkeyrie/store"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/audit"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+)
+
+// CopyOptions selects the keys left out of a copy and whether the keys of
+// the destination are replaced
+type CopyOptions struct {
+	Overwrite       bool
+	SkipCredentials bool
+	SkipAudit       bool
+}
+
+// CopyResult describes the keys copied from one kv store to another along
+// with the keys of the source left out by the options
+type CopyResult struct {
+	Keys               int
+	Checksum           string
+	SkippedCredentials int
+	SkippedAudit       int
+}
+
+// Checksum returns the sha256 of the keys relative to the base path along
+// with their values, snapshots of the same tree under different base
+// paths have the same checksum
+func (snapshot Snapshot) Checksum() string {
+	hash := sha256.New()
+	for _, entry := range snapshot.Entries {
+		writeChecksum(hash, strings.TrimPrefix(entry.Key, snapshot.BasePath), entry.Value)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func writeChecksum(hash hash.Hash, key string, value []byte) {
+	hash.Write([]byte(key))
+	hash.Write([]byte{0})
+	hash.Write(value)
+	hash.Write([]byte{0})
+}
+
+// Copy copies the tree under the base path of the source along with its
+// credentials and its audit stream to the base path of the destination one
+// key at a time and verifies the count and checksum of the keys afterwards.
+// The tree is listed a collection at a time i.e. the collections next to
+// the buildings, a building along with the entities nested in it and a
+// month of the audit stream, so that a copy never holds the whole tree. The
+// copy fails when the destination already has keys under its base path
+// unless overwrite is set, in which case the keys missing from the source
+// are deleted before the keys of the source are written. The keys left out
+// by the options are neither copied nor deleted
+func Copy(from store.Store, fromBasePath string, to store.Store, toBasePath string, options CopyOptions) (CopyResult, error) {
+	source, destination := newTreeWalker(from, fromBasePath), newTreeWalker(to, toBasePath)
+
+	existing := 0
+	err := destination.walk(func(entry Entry) error {
+		if options.skips(destination, entry.Key) {
+			return nil
+		} else if !options.Overwrite {
+			existing++
+			return nil
+		}
+		found, err := from.Exists(source.key(destination.relative(entry.Key)), nil)
+		if err != nil || found {
+			return err
+		}
+		if err := to.Delete(entry.Key); err != nil && err != store.ErrKeyNotFound {
+			return fmt.Errorf("unable to delete %s, reason: %v", entry.Key, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return CopyResult{}, err
+	}
+	if existing > 0 {
+		return CopyResult{}, fmt.Errorf("destination already has %d keys under %s", existing, toBasePath)
+	}
+
+	result := CopyResult{}
+	checksum := sha256.New()
+	err = source.walk(func(entry Entry) error {
+		switch {
+		case options.SkipCredentials && source.credentials(entry.Key):
+			result.SkippedCredentials++
+			return nil
+		case options.SkipAudit && source.audit(entry.Key):
+			result.SkippedAudit++
+			return nil
+		}
+		relative := source.relative(entry.Key)
+		if err := to.Put(destination.key(relative), entry.Value, nil); err != nil {
+			return fmt.Errorf("unable to write %s, reason: %v", destination.key(relative), err)
+		}
+		writeChecksum(checksum, relative, entry.Value)
+		result.Keys++
+		return nil
+	})
+	if err != nil {
+		return CopyResult{}, err
+	}
+	result.Checksum = hex.EncodeToString(checksum.Sum(nil))
+
+	copied := 0
+	checksum.Reset()
+	err = destination.walk(func(entry Entry) error {
+		if !options.skips(destination, entry.Key) {
+			writeChecksum(checksum, destination.relative(entry.Key), entry.Value)
+			copied++
+		}
+		return nil
+	})
+	if err != nil {
+		return CopyResult{}, err
+	}
+	if copied != result.Keys {
+		return CopyResult{}, fmt.Errorf("copied %d keys to %s but the source has %d keys", copied, toBasePath, result.Keys)
+	}
+	if hex.EncodeToString(checksum.Sum(nil)) != result.Checksum {
+		return CopyResult{}, fmt.Errorf("checksum of the keys under %s does not match the source", toBasePath)
+	}
+	return result, nil
+}
+
+// skips returns true when the key is left out of the copy by the options
+func (options CopyOptions) skips(walker treeWalker, key string) bool {
+	return (options.SkipCredentials && walker.credentials(key)) || (options.SkipAudit && walker.audit(key))
+}
+
+// treeWalker visits the keys of the tree under the base path along with
+// its audit stream a collection at a time, the keys which are not reachable
+// from the collections e.g. the floors of a building missing from the
+// buildings are not visited and are reported by Verify
+type treeWalker struct {
+	ps PersistentStore
+}
+
+func newTreeWalker(kvStore store.Store, basePath string) treeWalker {
+	return treeWalker{ps: PersistentStore{path: basePath, kvStore: kvStore}}
+}
+
+// walk visits every key of the tree, the buildings and the months of the
+// audit stream are read before their keys are visited so that a visit
+// deleting them does not cut the walk short
+func (walker treeWalker) walk(visit func(Entry) error) error {
+	buildings := gateway.Buildings{}
+	pair, err := walker.ps.kvStore.Get(walker.ps.buildingsRootPath(), nil)
+	if err == nil {
+		buildings, err = gateway.NewBuildings(pair.Value)
+	}
+	if err != nil && err != store.ErrKeyNotFound {
+		return err
+	}
+	months, err := walker.list(path.Join(AuditRootPath(walker.ps.path), auditMonths))
+	if err != nil {
+		return err
+	}
+
+	ids := make([]string, 0, len(buildings))
+	for id := range buildings {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	collections := append([]string{buildingsBasePath, labelsBasePath, authPath, path.Dir(uptimePath)}, ids...)
+	for _, collection := range collections {
+		if err := walker.visitCollection(path.Join(walker.ps.path, collection), visit); err != nil {
+			return err
+		}
+	}
+	for _, month := range months {
+		if err := visit(month); err != nil {
+			return err
+		}
+		if err := walker.visitMonth(path.Base(month.Key), visit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// visitCollection visits the key along with the keys nested under it
+func (walker treeWalker) visitCollection(key string, visit func(Entry) error) error {
+	pair, err := walker.ps.kvStore.Get(key, nil)
+	if err != nil && err != store.ErrKeyNotFound {
+		return err
+	} else if err == nil {
+		if err := visit(Entry{Key: key, Value: pair.Value}); err != nil {
+			return err
+		}
+	}
+	return walker.visitList(key, visit)
+}
+
+// visitMonth visits the entries of the audit stream appended in the month
+// along with their keys by the entity
+func (walker treeWalker) visitMonth(month string, visit func(Entry) error) error {
+	entries, err := walker.list(path.Join(AuditRootPath(walker.ps.path), auditByTime, month))
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err := visit(entry); err != nil {
+			return err
+		}
+		parsed, err := audit.NewEntryFromJSON(entry.Value)
+		if err != nil {
+			return fmt.Errorf("unable to parse %s, reason: %v", entry.Key, err)
+		}
+		if parsed.Entity == "" {
+			continue
+		}
+		key := path.Join(walker.ps.auditEntityPath(parsed.Entity), auditEntriesPath, path.Base(entry.Key))
+		pair, err := walker.ps.kvStore.Get(key, nil)
+		if err == store.ErrKeyNotFound {
+			continue
+		} else if err != nil {
+			return err
+		}
+		if err := visit(Entry{Key: key, Value: pair.Value}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (walker treeWalker) visitList(key string, visit func(Entry) error) error {
+	entries, err := walker.list(key)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err := visit(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// list reads the keys nested under the key ordered by key
+func (walker treeWalker) list(key string) ([]Entry, error) {
+	pairs, err := walker.ps.kvStore.List(key+"/", nil)
+	if err != nil && err != store.ErrKeyNotFound {
+		return nil, err
+	}
+	entries := make([]Entry, 0, len(pairs))
+	for _, pair := range pairs {
+		entries = append(entries, Entry{Key: strings.TrimPrefix(pair.Key, "/"), Value: pair.Value})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+	return entries, nil
+}
+
+// relative returns the key relative to the base path, the keys of the
+// audit stream are kept apart under the audit path
+func (walker treeWalker) relative(key string) string {
+	if walker.audit(key) {
+		return path.Join("/", auditPath, strings.TrimPrefix(key, AuditRootPath(walker.ps.path)))
+	}
+	return strings.TrimPrefix(key, walker.ps.path)
+}
+
+// key returns the key of the relative key under the base path
+func (walker treeWalker) key(relative string) string {
+	if strings.HasPrefix(relative, "/"+auditPath+"/") {
+		return path.Join(AuditRootPath(walker.ps.path), strings.TrimPrefix(relative, "/"+auditPath))
+	}
+	return path.Join(walker.ps.path, relative)
+}
+
+func (walker treeWalker) credentials(key string) bool {
+	return unexported(walker.ps.path, key)
+}
+
+func (walker treeWalker) audit(key string) bool {
+	return strings.HasPrefix(key, AuditRootPath(walker.ps.path)+"/")
+}
@@ -0,0 +1,204 @@
+package store_test
+
+import (
+	"testing"
+	"time"
+
+	libKVStore "github.com/kvtools/valkeyrie/store"
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/audit"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/auth"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/store"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/testutils"
+)
+
+// seedCredentialsAndAudit puts a token along with the signing key and an
+// audit entry of the entity i.e. two keys of the credentials and three keys
+// of the audit stream
+func seedCredentialsAndAudit(t *testing.T, kvStore libKVStore.Store, basePath string) {
+	persistentStore := store.NewPersistentStore(basePath, kvStore)
+	token, _, _ := auth.NewAPIKey("alice", time.Now(), 0)
+	assert.NoError(t, persistentStore.UpsertToken(token))
+	_, err := persistentStore.SigningKey()
+	assert.NoError(t, err)
+	entry, err := audit.NewEntry(time.Now(), audit.Anonymous, "DELETE", "/v1/buildings/building-one", "building-one", 200)
+	assert.NoError(t, err)
+	assert.NoError(t, persistentStore.AppendAudit(entry))
+}
+
+// listingStore records the prefixes listed through the kv store
+type listingStore struct {
+	libKVStore.Store
+	listed []string
+}
+
+func (kvStore *listingStore) List(prefix string, options *libKVStore.ReadOptions) ([]*libKVStore.KVPair, error) {
+	kvStore.listed = append(kvStore.listed, prefix)
+	return kvStore.Store.List(prefix, options)
+}
+
+func TestCopy(t *testing.T) {
+	t.Run("should copy every key under the base path", func(t *testing.T) {
+		from, _ := newBoltDB(t)
+		to, _ := newBoltDB(t)
+		buildings, _ := testutils.NewBuildings("building-one")
+		put(t, from, "dwarka/buildings", buildings)
+		put(t, from, "dwarka/building-one/floors", gateway.Floors{})
+		put(t, from, "dwarka-old/buildings", buildings)
+
+		result, err := store.Copy(from, "dwarka", to, "dwarka", store.CopyOptions{})
+
+		if assert.NoError(t, err) {
+			source, _ := store.Backup(from, "dwarka")
+			copied, _ := store.Backup(to, "dwarka")
+			assert.Equal(t, 2, result.Keys)
+			assert.NotEmpty(t, result.Checksum)
+			assert.Equal(t, source.Entries, copied.Entries)
+		}
+	})
+
+	t.Run("should copy the credentials and the audit stream", func(t *testing.T) {
+		from, _ := newBoltDB(t)
+		to, _ := newBoltDB(t)
+		put(t, from, "dwarka/buildings", gateway.Buildings{})
+		seedCredentialsAndAudit(t, from, "dwarka")
+
+		result, err := store.Copy(from, "dwarka", to, "home", store.CopyOptions{})
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, 6, result.Keys)
+			source, _ := store.NewPersistentStore("dwarka", from).Tokens()
+			tokens, err := store.NewPersistentStore("home", to).Tokens()
+			assert.NoError(t, err)
+			assert.Equal(t, source, tokens)
+			entries, err := store.NewPersistentStore("home", to).Audit(audit.Query{Entity: "building-one"})
+			assert.NoError(t, err)
+			assert.Len(t, entries, 1)
+		}
+	})
+
+	t.Run("should report the keys left out of the copy", func(t *testing.T) {
+		from, _ := newBoltDB(t)
+		to, _ := newBoltDB(t)
+		put(t, from, "dwarka/buildings", gateway.Buildings{})
+		seedCredentialsAndAudit(t, from, "dwarka")
+
+		result, err := store.Copy(from, "dwarka", to, "dwarka", store.CopyOptions{SkipCredentials: true, SkipAudit: true})
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, store.CopyResult{Keys: 1, Checksum: result.Checksum, SkippedCredentials: 2, SkippedAudit: 3}, result)
+			tokens, err := store.NewPersistentStore("dwarka", to).Tokens()
+			assert.NoError(t, err)
+			assert.Empty(t, tokens)
+			entries, err := store.NewPersistentStore("dwarka", to).Audit(audit.Query{})
+			assert.NoError(t, err)
+			assert.Empty(t, entries)
+		}
+	})
+
+	t.Run("should list the source a collection at a time", func(t *testing.T) {
+		kvStore, _ := newBoltDB(t)
+		from := &listingStore{Store: kvStore}
+		to, _ := newBoltDB(t)
+		buildings, _ := testutils.NewBuildings("building-one")
+		buildings["building-two"] = testutils.NewBuilding("building-two")
+		put(t, from, "dwarka/buildings", buildings)
+		seedCredentialsAndAudit(t, from, "dwarka")
+		month := time.Now().UTC().Format("2006-01")
+
+		_, err := store.Copy(from, "dwarka", to, "dwarka", store.CopyOptions{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{
+			"_audit/dwarka/months/",
+			"dwarka/buildings/", "dwarka/_labels/", "dwarka/_auth/", "dwarka/status/",
+			"dwarka/building-one/", "dwarka/building-two/",
+			"_audit/dwarka/time/" + month + "/",
+		}, from.listed)
+	})
+
+	t.Run("should have the same checksum under a different base path", func(t *testing.T) {
+		from, _ := newBoltDB(t)
+		buildings, _ := testutils.NewBuildings("building-one")
+		put(t, from, "dwarka/buildings", buildings)
+		seedCredentialsAndAudit(t, from, "dwarka")
+
+		to, _ := newBoltDB(t)
+		other, _ := newBoltDB(t)
+
+		dwarka, err := store.Copy(from, "dwarka", to, "dwarka", store.CopyOptions{})
+		assert.NoError(t, err)
+		home, err := store.Copy(from, "dwarka", other, "home", store.CopyOptions{})
+		assert.NoError(t, err)
+
+		assert.Equal(t, dwarka.Checksum, home.Checksum)
+	})
+
+	t.Run("should move the keys to the base path of the destination", func(t *testing.T) {
+		from, _ := newBoltDB(t)
+		to, _ := newBoltDB(t)
+		buildings, _ := testutils.NewBuildings("building-one")
+		put(t, from, "dwarka/buildings", buildings)
+
+		result, err := store.Copy(from, "dwarka", to, "home", store.CopyOptions{})
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, 1, result.Keys)
+			actual, err := store.NewPersistentStore("home", to).Buildings()
+			assert.NoError(t, err)
+			assert.Equal(t, buildings, actual)
+		}
+	})
+
+	t.Run("should fail when the destination is not empty", func(t *testing.T) {
+		from, _ := newBoltDB(t)
+		to, _ := newBoltDB(t)
+		buildings, _ := testutils.NewBuildings("building-one")
+		put(t, from, "dwarka/buildings", buildings)
+		put(t, to, "dwarka/buildings", gateway.Buildings{})
+
+		_, err := store.Copy(from, "dwarka", to, "dwarka", store.CopyOptions{})
+
+		assert.EqualError(t, err, "destination already has 1 keys under dwarka")
+	})
+
+	t.Run("should replace the keys of the destination on overwrite", func(t *testing.T) {
+		from, _ := newBoltDB(t)
+		to, _ := newBoltDB(t)
+		buildings, _ := testutils.NewBuildings("building-one")
+		put(t, from, "dwarka/buildings", buildings)
+		existing, _ := testutils.NewBuildings("building-two")
+		put(t, to, "dwarka/buildings", existing)
+		put(t, to, "dwarka/building-two/floors", gateway.Floors{})
+		put(t, to, "dwarka-old/buildings", gateway.Buildings{})
+		seedCredentialsAndAudit(t, to, "dwarka")
+
+		result, err := store.Copy(from, "dwarka", to, "dwarka", store.CopyOptions{Overwrite: true, SkipCredentials: true})
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, 1, result.Keys)
+			copied, _ := store.Backup(to, "dwarka")
+			assert.Len(t, copied.Entries, 1)
+			old, _ := store.Backup(to, "dwarka-old")
+			assert.Len(t, old.Entries, 1)
+			tokens, _ := store.NewPersistentStore("dwarka", to).Tokens()
+			assert.Len(t, tokens, 1)
+			entries, _ := store.NewPersistentStore("dwarka", to).Audit(audit.Query{})
+			assert.Empty(t, entries)
+		}
+	})
+}
+
+func TestSnapshot_Checksum(t *testing.T) {
+	entries := func(basePath string) []store.Entry {
+		return []store.Entry{{Key: basePath + "/buildings", Value: []byte("{}")}}
+	}
+
+	dwarka := store.Snapshot{BasePath: "dwarka", Entries: entries("dwarka")}
+	home := store.Snapshot{BasePath: "home", Entries: entries("home")}
+	changed := store.Snapshot{BasePath: "dwarka", Entries: []store.Entry{{Key: "dwarka/buildings", Value: []byte("[]")}}}
+
+	assert.Equal(t, dwarka.Checksum(), home.Checksum())
+	assert.NotEqual(t, dwarka.Checksum(), changed.Checksum())
+}
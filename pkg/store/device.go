@@ -0,0 +1,63 @@
+package store
+
+import (
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/search"
+	"path"
+)
+
+const (
+	devicesBasePath = "devices"
+)
+
+// Devices returns all the Devices from store
+func (ps PersistentStore) Devices(room gateway.Room) (gateway.Devices, error) {
+	value, err := ps.get(ps.devicesRootPath(room), gateway.Devices{})
+	if err != nil {
+		return nil, err
+	}
+	return gateway.NewDevices(room, value)
+}
+
+// UpsertDevices creates or updates Devices in store
+func (ps PersistentStore) UpsertDevices(room gateway.Room, devices gateway.Devices) error {
+	return ps.putDevices(room, devices)
+}
+
+// UpsertDevice creates or updates Device in store
+func (ps PersistentStore) UpsertDevice(device gateway.Device) error {
+	devices, err := ps.Devices(device.Room)
+	if err != nil {
+		return err
+	}
+	devices[device.ID()] = device
+	return ps.putDevices(device.Room, devices)
+}
+
+// DeleteDevice deletes the device from store
+func (ps PersistentStore) DeleteDevice(device gateway.Device) error {
+	devices, err := ps.Devices(device.Room)
+	if err != nil {
+		return err
+	}
+
+	delete(devices, device.ID())
+	return ps.putDevices(device.Room, devices)
+}
+
+// putDevices persists the devices of the room and indexes their labels
+func (ps PersistentStore) putDevices(room gateway.Room, devices gateway.Devices) error {
+	err := ps.putJSON(ps.devicesRootPath(room), devices)
+	if err != nil {
+		return err
+	}
+	documents := map[string]search.Document{}
+	for id, device := range devices {
+		documents[id] = documentOf(device.PhysicalEntity, device.Meta)
+	}
+	return ps.index(path.Join(room.Floor.Building.ID(), room.Floor.ID(), room.ID()), documents)
+}
+
+func (ps PersistentStore) devicesRootPath(room gateway.Room) string {
+	return path.Join(ps.roomRootPath(room), devicesBasePath)
+}
@@ -0,0 +1,161 @@
+package store_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-cmp/cmp"
+	libKVStore "github.com/kvtools/valkeyrie/store"
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+	mockKVStore "gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/internal/mocks/valkeyrie/store"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/store"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/testutils"
+)
+
+func TestPersistentStore_Devices(t *testing.T) {
+	t.Run("should return devices", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		room := testutils.NewRoom("room-one")
+		expectedDevices := gateway.Devices{"porch-light": gateway.Device{
+			Room: room,
+			Meta: map[string]string{"type": "light"},
+			PhysicalEntity: gateway.PhysicalEntity{
+				Name:        "porch light",
+				Description: "test device",
+			},
+		}}
+		data, _ := json.Marshal(expectedDevices)
+
+		mockStore := mockKVStore.NewMockStore(ctrl)
+		mockStore.EXPECT().Get("dwarka/building-one/floor-one/room-one/devices", nil).Return(&libKVStore.KVPair{Value: data}, nil)
+
+		persistentStore := store.NewPersistentStore("dwarka", mockStore)
+
+		actual, err := persistentStore.Devices(room)
+
+		assert.NoError(t, err)
+		if !cmp.Equal(expectedDevices, actual) {
+			assert.Fail(t, cmp.Diff(expectedDevices, actual))
+		}
+	})
+
+	t.Run("should return empty devices when none are persisted", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockStore := mockKVStore.NewMockStore(ctrl)
+		mockStore.EXPECT().Get("dwarka/building-one/floor-one/room-one/devices", nil).Return(nil, libKVStore.ErrKeyNotFound)
+
+		persistentStore := store.NewPersistentStore("dwarka", mockStore)
+
+		actual, err := persistentStore.Devices(testutils.NewRoom("room-one"))
+
+		assert.NoError(t, err)
+		assert.Equal(t, gateway.Devices{}, actual)
+	})
+
+	t.Run("should handle error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockStore := mockKVStore.NewMockStore(ctrl)
+		mockStore.EXPECT().Get("dwarka/building-one/floor-one/room-one/devices", nil).Return(nil, fmt.Errorf("store unavailable"))
+
+		persistentStore := store.NewPersistentStore("dwarka", mockStore)
+
+		actual, err := persistentStore.Devices(testutils.NewRoom("room-one"))
+
+		if assert.Error(t, err) {
+			assert.Equal(t, "store unavailable", err.Error())
+		}
+		assert.Nil(t, actual)
+	})
+}
+
+func TestPersistentStore_UpsertDevice(t *testing.T) {
+	t.Run("should add device to existing devices", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		_, existing := testutils.NewDevices("existing")
+		device := testutils.NewDevice("porch light")
+		data, _ := json.Marshal(gateway.Devices{"existing": existing})
+
+		mockStore := mockKVStore.NewMockStore(ctrl)
+		mockStore.EXPECT().Get("dwarka/building-one/floor-one/room-one/devices", nil).Return(&libKVStore.KVPair{Value: data}, nil)
+		mockStore.EXPECT().Put("dwarka/building-one/floor-one/room-one/devices", gomock.Any(), nil).DoAndReturn(
+			func(key string, data []byte, options *libKVStore.WriteOptions) error {
+				actual := map[string]json.RawMessage{}
+				err := json.Unmarshal(data, &actual)
+				if err != nil {
+					return err
+				}
+
+				assert.Contains(t, actual, "existing")
+				assert.Contains(t, actual, "porch-light")
+				return nil
+			},
+		)
+
+		expectLabelIndex(mockStore)
+		persistentStore := store.NewPersistentStore("dwarka", mockStore)
+		err := persistentStore.UpsertDevice(device)
+		assert.NoError(t, err)
+	})
+
+	t.Run("should handle error when fetching devices", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockStore := mockKVStore.NewMockStore(ctrl)
+		mockStore.EXPECT().Get("dwarka/building-one/floor-one/room-one/devices", nil).Return(nil, fmt.Errorf("unable to get devices"))
+
+		persistentStore := store.NewPersistentStore("dwarka", mockStore)
+		err := persistentStore.UpsertDevice(testutils.NewDevice("porch light"))
+		if assert.Error(t, err) {
+			assert.Equal(t, "unable to get devices", err.Error())
+		}
+	})
+}
+
+func TestPersistentStore_DeleteDevice(t *testing.T) {
+	t.Run("should remove device from devices", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		devices, device := testutils.NewDevices("porch-light")
+		data, _ := json.Marshal(devices)
+
+		mockStore := mockKVStore.NewMockStore(ctrl)
+		mockStore.EXPECT().Get("dwarka/building-one/floor-one/room-one/devices", nil).Return(&libKVStore.KVPair{Value: data}, nil)
+		mockStore.EXPECT().Put("dwarka/building-one/floor-one/room-one/devices", []byte("{}"), nil).Return(nil)
+
+		expectLabelIndex(mockStore)
+		persistentStore := store.NewPersistentStore("dwarka", mockStore)
+		err := persistentStore.DeleteDevice(device)
+		assert.NoError(t, err)
+	})
+
+	t.Run("should handle error when saving devices", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		devices, device := testutils.NewDevices("porch-light")
+		data, _ := json.Marshal(devices)
+
+		mockStore := mockKVStore.NewMockStore(ctrl)
+		mockStore.EXPECT().Get("dwarka/building-one/floor-one/room-one/devices", nil).Return(&libKVStore.KVPair{Value: data}, nil)
+		mockStore.EXPECT().Put("dwarka/building-one/floor-one/room-one/devices", gomock.Any(), nil).Return(fmt.Errorf("unable to save"))
+
+		persistentStore := store.NewPersistentStore("dwarka", mockStore)
+		err := persistentStore.DeleteDevice(device)
+		if assert.Error(t, err) {
+			assert.Equal(t, "unable to save", err.Error())
+		}
+	})
+}
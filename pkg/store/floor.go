@@ -2,6 +2,7 @@ package store
 
 import (
 	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/search"
 	"path"
 )
 
@@ -20,7 +21,7 @@ func (ps PersistentStore) Floors(building gateway.Entity) (gateway.Floors, error
 
 // UpsertFloors creates or updates Floors in store
 func (ps PersistentStore) UpsertFloors(building gateway.Entity, floors gateway.Floors) error {
-	return ps.putJSON(ps.floorsRootPath(building), floors)
+	return ps.putFloors(building, floors)
 }
 
 // UpsertFloor creates or updates Floor in store
@@ -30,7 +31,7 @@ func (ps PersistentStore) UpsertFloor(floor gateway.Floor) error {
 		return err
 	}
 	floors[floor.ID()] = floor
-	return ps.putJSON(ps.floorsRootPath(floor.Building), floors)
+	return ps.putFloors(floor.Building, floors)
 }
 
 // DeleteFloor deletes the floor and nested path from store
@@ -41,7 +42,7 @@ func (ps PersistentStore) DeleteFloor(floor gateway.Floor) error {
 	}
 
 	delete(floors, floor.ID())
-	err = ps.putJSON(ps.floorsRootPath(floor.Building), floors)
+	err = ps.putFloors(floor.Building, floors)
 	if err != nil {
 		return err
 	}
@@ -49,6 +50,19 @@ func (ps PersistentStore) DeleteFloor(floor gateway.Floor) error {
 	return ps.safeDelete(ps.floorRootPath(floor))
 }
 
+// putFloors persists the floors of the building and indexes their labels
+func (ps PersistentStore) putFloors(building gateway.Entity, floors gateway.Floors) error {
+	err := ps.putJSON(ps.floorsRootPath(building), floors)
+	if err != nil {
+		return err
+	}
+	documents := map[string]search.Document{}
+	for id, floor := range floors {
+		documents[id] = documentOf(floor.PhysicalEntity, nil)
+	}
+	return ps.index(building.ID(), documents)
+}
+
 func (ps PersistentStore) floorsRootPath(building gateway.Entity) string {
 	return path.Join(ps.buildingRootPath(building), floorsBasePath)
 }
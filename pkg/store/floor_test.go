@@ -105,6 +105,7 @@ func TestPersistentStore_UpsertFloor(t *testing.T) {
 			},
 		)
 
+		expectLabelIndex(mockStore)
 		persistentStore := store.NewPersistentStore("dwarka", mockStore)
 		newFloor := gateway.Floor{
 			Level:    1,
@@ -197,6 +198,7 @@ func TestPersistentStore_UpsertFloors(t *testing.T) {
 			},
 		)
 
+		expectLabelIndex(mockStore)
 		persistentStore := store.NewPersistentStore("dwarka", mockStore)
 		err := persistentStore.UpsertFloors(testutils.NewBuilding("building-one"), floors)
 		assert.NoError(t, err)
@@ -260,6 +262,7 @@ func TestPersistentStore_DeleteFloor(t *testing.T) {
 			},
 		)
 
+		expectLabelIndex(mockStore)
 		persistentStore := store.NewPersistentStore("dwarka", mockStore)
 		err := persistentStore.DeleteFloor(floor)
 		assert.NoError(t, err)
@@ -285,6 +288,7 @@ func TestPersistentStore_DeleteFloor(t *testing.T) {
 		mockStore.EXPECT().Put("dwarka/building-one/floors", gomock.Any(), nil).Return(nil)
 		mockStore.EXPECT().DeleteTree("dwarka/building-one/floor-one").Return(fmt.Errorf("unable to delete"))
 
+		expectLabelIndex(mockStore)
 		persistentStore := store.NewPersistentStore("dwarka", mockStore)
 		err := persistentStore.DeleteFloor(floor)
 		if assert.Error(t, err) {
@@ -0,0 +1,53 @@
+package store
+
+import (
+	"fmt"
+	"path"
+
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+)
+
+const groupsBasePath = "_groups"
+
+// Groups returns all the Groups of the building from store
+func (ps PersistentStore) Groups(building gateway.Entity) (gateway.Groups, error) {
+	value, err := ps.get(ps.groupsRootPath(building), gateway.Groups{})
+	if err != nil {
+		return nil, err
+	}
+	return gateway.NewGroups(building, value)
+}
+
+// UpsertGroups creates or updates Groups in store
+func (ps PersistentStore) UpsertGroups(building gateway.Entity, groups gateway.Groups) error {
+	return ps.putJSON(ps.groupsRootPath(building), groups)
+}
+
+// UpsertGroup creates or updates Group in store
+func (ps PersistentStore) UpsertGroup(group gateway.Group) error {
+	groups, err := ps.Groups(group.Building)
+	if err != nil {
+		return err
+	}
+	groups[group.ID()] = group
+	return ps.putJSON(ps.groupsRootPath(group.Building), groups)
+}
+
+// DeleteGroup deletes the group from store, NotFound is
+// returned when the group does not exist
+func (ps PersistentStore) DeleteGroup(group gateway.Group) error {
+	groups, err := ps.Groups(group.Building)
+	if err != nil {
+		return err
+	}
+	if _, ok := groups[group.ID()]; !ok {
+		return NotFound(fmt.Sprintf("group %s not found", group.ID()))
+	}
+
+	delete(groups, group.ID())
+	return ps.putJSON(ps.groupsRootPath(group.Building), groups)
+}
+
+func (ps PersistentStore) groupsRootPath(building gateway.Entity) string {
+	return path.Join(ps.buildingRootPath(building), groupsBasePath)
+}
@@ -0,0 +1,48 @@
+package store_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/store"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/testutils"
+)
+
+func TestPersistentStore_Groups(t *testing.T) {
+	building := testutils.NewBuilding("building-one")
+	group := gateway.Group{
+		Building:       building,
+		Selector:       &gateway.Selector{Floor: "ground", Capability: "light", Meta: map[string]string{"zone": "exterior"}},
+		PhysicalEntity: gateway.PhysicalEntity{Name: "exterior lights"},
+	}
+
+	t.Run("should upsert and delete group", func(t *testing.T) {
+		kvStore, _ := newBoltDB(t)
+		persistentStore := store.NewPersistentStore("dwarka", kvStore)
+		assert.NoError(t, persistentStore.UpsertBuilding(building))
+
+		assert.NoError(t, persistentStore.UpsertGroup(group))
+		groups, err := persistentStore.Groups(building)
+		if assert.NoError(t, err) {
+			assert.Equal(t, gateway.Groups{"exterior-lights": group}, groups)
+		}
+		problems, err := store.Verify(kvStore, "dwarka")
+		assert.NoError(t, err)
+		assert.Empty(t, problems)
+
+		assert.NoError(t, persistentStore.DeleteGroup(group))
+		groups, err = persistentStore.Groups(building)
+		if assert.NoError(t, err) {
+			assert.Empty(t, groups)
+		}
+	})
+
+	t.Run("should fail to delete missing group", func(t *testing.T) {
+		kvStore, _ := newBoltDB(t)
+
+		err := store.NewPersistentStore("dwarka", kvStore).DeleteGroup(group)
+
+		assert.Equal(t, store.NotFound("group exterior-lights not found"), err)
+	})
+}
@@ -0,0 +1,98 @@
+package store
+
+import (
+	"time"
+
+	"github.com/kvtools/valkeyrie/store"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/metrics"
+)
+
+var (
+	operationDuration = metrics.Default.NewHistogram("dwarka_store_operation_duration_seconds",
+		"Latency of the operations of the kv store by backend and operation", metrics.DefaultBuckets, "backend", "operation")
+	operationErrors = metrics.Default.NewCounter("dwarka_store_operation_errors_total",
+		"Operations of the kv store failed by backend and operation, a missing key is not a failure", "backend", "operation")
+)
+
+// instrumented measures the operations of the kv store of the backend
+type instrumented struct {
+	store.Store
+	backend string
+}
+
+// Instrument returns the kv store recording the latency and the errors of
+// its operations in the metrics labelled with the backend
+func Instrument(kvStore store.Store, backend string) store.Store {
+	return instrumented{Store: kvStore, backend: backend}
+}
+
+func (kv instrumented) measure(operation string, start time.Time, err error) {
+	operationDuration.Observe(time.Since(start).Seconds(), kv.backend, operation)
+	if err != nil && err != store.ErrKeyNotFound {
+		operationErrors.Inc(kv.backend, operation)
+	}
+}
+
+// Put measures the Put of the kv store
+func (kv instrumented) Put(key string, value []byte, options *store.WriteOptions) error {
+	start := time.Now()
+	err := kv.Store.Put(key, value, options)
+	kv.measure("put", start, err)
+	return err
+}
+
+// Get measures the Get of the kv store
+func (kv instrumented) Get(key string, options *store.ReadOptions) (*store.KVPair, error) {
+	start := time.Now()
+	pair, err := kv.Store.Get(key, options)
+	kv.measure("get", start, err)
+	return pair, err
+}
+
+// Delete measures the Delete of the kv store
+func (kv instrumented) Delete(key string) error {
+	start := time.Now()
+	err := kv.Store.Delete(key)
+	kv.measure("delete", start, err)
+	return err
+}
+
+// Exists measures the Exists of the kv store
+func (kv instrumented) Exists(key string, options *store.ReadOptions) (bool, error) {
+	start := time.Now()
+	exists, err := kv.Store.Exists(key, options)
+	kv.measure("exists", start, err)
+	return exists, err
+}
+
+// List measures the List of the kv store
+func (kv instrumented) List(directory string, options *store.ReadOptions) ([]*store.KVPair, error) {
+	start := time.Now()
+	pairs, err := kv.Store.List(directory, options)
+	kv.measure("list", start, err)
+	return pairs, err
+}
+
+// DeleteTree measures the DeleteTree of the kv store
+func (kv instrumented) DeleteTree(directory string) error {
+	start := time.Now()
+	err := kv.Store.DeleteTree(directory)
+	kv.measure("delete_tree", start, err)
+	return err
+}
+
+// AtomicPut measures the AtomicPut of the kv store
+func (kv instrumented) AtomicPut(key string, value []byte, previous *store.KVPair, options *store.WriteOptions) (bool, *store.KVPair, error) {
+	start := time.Now()
+	ok, pair, err := kv.Store.AtomicPut(key, value, previous, options)
+	kv.measure("atomic_put", start, err)
+	return ok, pair, err
+}
+
+// AtomicDelete measures the AtomicDelete of the kv store
+func (kv instrumented) AtomicDelete(key string, previous *store.KVPair) (bool, error) {
+	start := time.Now()
+	ok, err := kv.Store.AtomicDelete(key, previous)
+	kv.measure("atomic_delete", start, err)
+	return ok, err
+}
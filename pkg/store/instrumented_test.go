@@ -0,0 +1,27 @@
+package store_test
+
+import (
+	"strings"
+	"testing"
+
+	libKVStore "github.com/kvtools/valkeyrie/store"
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/metrics"
+)
+
+func TestInstrument(t *testing.T) {
+	kvStore, _ := newBoltDB(t)
+	assert.NoError(t, kvStore.Put("dwarka/key", []byte("value"), nil))
+	_, err := kvStore.Get("dwarka/missing", nil)
+	assert.Equal(t, libKVStore.ErrKeyNotFound, err)
+	_, err = kvStore.AtomicDelete("dwarka/key", &libKVStore.KVPair{Key: "dwarka/key", LastIndex: 42})
+	assert.Error(t, err)
+
+	builder := &strings.Builder{}
+	_, err = metrics.Default.WriteTo(builder)
+
+	assert.NoError(t, err)
+	assert.Contains(t, builder.String(), `dwarka_store_operation_duration_seconds_count{backend="boltdb",operation="put"}`)
+	assert.Contains(t, builder.String(), `dwarka_store_operation_errors_total{backend="boltdb",operation="atomic_delete"}`)
+	assert.NotContains(t, builder.String(), `dwarka_store_operation_errors_total{backend="boltdb",operation="get"}`)
+}
@@ -0,0 +1,292 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/kvtools/valkeyrie/store"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/labels"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/search"
+)
+
+const labelsBasePath = "_labels"
+
+// LabelIndex indexes the labels of the building, its floors, rooms and
+// devices keyed by their path e.g. building-id/floor-id, Keys holds the
+// ordered paths of the entities having each label key so that a selector
+// reads an index per building instead of every collection
+type LabelIndex struct {
+	Entities map[string]map[string]string `json:"entities"`
+	Keys     map[string][]string          `json:"keys"`
+}
+
+// NewLabelIndex returns the LabelIndex from []byte
+func NewLabelIndex(data []byte) (LabelIndex, error) {
+	index := LabelIndex{}
+	err := json.Unmarshal(data, &index)
+	if err != nil {
+		return LabelIndex{}, fmt.Errorf("unable to parse label index, %w", err)
+	}
+	if index.Entities == nil {
+		index.Entities = map[string]map[string]string{}
+	}
+	if index.Keys == nil {
+		index.Keys = map[string][]string{}
+	}
+	return index, nil
+}
+
+// newLabelIndexOf indexes every entity of the building of the tree
+func newLabelIndexOf(tree gateway.Tree, buildingID string) LabelIndex {
+	index := LabelIndex{Entities: map[string]map[string]string{}, Keys: map[string][]string{}}
+	building, ok := tree.Buildings[buildingID]
+	if !ok {
+		return index
+	}
+	index.Entities[buildingID] = building.Labels
+	for floorID, floor := range tree.FloorsOf(building) {
+		index.Entities[path.Join(buildingID, floorID)] = floor.Labels
+		for roomID, room := range tree.RoomsOf(floor) {
+			index.Entities[path.Join(buildingID, floorID, roomID)] = room.Labels
+			for deviceID, device := range tree.DevicesOf(room) {
+				index.Entities[path.Join(buildingID, floorID, roomID, deviceID)] = device.Labels
+			}
+		}
+	}
+	index.reindexKeys()
+	return index
+}
+
+// Select returns the labels of the entities matching the selector keyed by
+// their path, the entities are narrowed down to the ones having the keys
+// required by the selector before matching their labels
+func (index LabelIndex) Select(selector labels.Selector) map[string]map[string]string {
+	var candidates []string
+	narrowed := false
+	for _, requirement := range selector {
+		if !requirement.Positive() {
+			continue
+		}
+		paths := index.Keys[requirement.Key]
+		if !narrowed || len(paths) < len(candidates) {
+			candidates, narrowed = paths, true
+		}
+	}
+	if !narrowed {
+		for entity := range index.Entities {
+			candidates = append(candidates, entity)
+		}
+	}
+
+	result := map[string]map[string]string{}
+	for _, entity := range candidates {
+		if selector.Matches(index.Entities[entity]) {
+			result[entity] = index.Entities[entity]
+		}
+	}
+	return result
+}
+
+// replace replaces the children of the parent path with the entities
+// keyed by id, the children missing from the entities are removed along
+// with the entities nested under them
+func (index LabelIndex) replace(parent string, entities map[string]map[string]string) {
+	for entity := range index.Entities {
+		child, ok := search.ChildOf(parent, entity)
+		if !ok {
+			continue
+		}
+		if _, ok := entities[child]; !ok {
+			delete(index.Entities, entity)
+		}
+	}
+	for id, entityLabels := range entities {
+		index.Entities[path.Join(parent, id)] = entityLabels
+	}
+	index.reindexKeys()
+}
+
+func (index LabelIndex) reindexKeys() {
+	for key := range index.Keys {
+		delete(index.Keys, key)
+	}
+	for entity, entityLabels := range index.Entities {
+		for key := range entityLabels {
+			index.Keys[key] = append(index.Keys[key], entity)
+		}
+	}
+	for key := range index.Keys {
+		sort.Strings(index.Keys[key])
+	}
+}
+
+// maxLabelIndexAttempts limits the attempts to update an index modified
+// concurrently
+const maxLabelIndexAttempts = 10
+
+// Labelled returns the labels of the buildings, floors, rooms and devices
+// matching the selector keyed by their path e.g. building-id/floor-id
+func (ps PersistentStore) Labelled(selector labels.Selector) (map[string]map[string]string, error) {
+	buildings, err := ps.Buildings()
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]map[string]string{}
+	for id := range buildings {
+		index, err := ps.labelIndex(id)
+		if err != nil {
+			return nil, err
+		}
+		for entity, entityLabels := range index.Select(selector) {
+			result[entity] = entityLabels
+		}
+	}
+	return result, nil
+}
+
+// labelIndex returns the persisted index of the building, the index is
+// built from the entities when the store predates it
+func (ps PersistentStore) labelIndex(buildingID string) (LabelIndex, error) {
+	kv, err := ps.kvStore.Get(ps.labelsPath(buildingID), nil)
+	if err == store.ErrKeyNotFound {
+		return ps.buildLabelIndex(buildingID)
+	} else if err != nil {
+		return LabelIndex{}, err
+	}
+	return NewLabelIndex(kv.Value)
+}
+
+// buildLabelIndex builds the index of the building from its entities and
+// persists it unless it was built concurrently
+func (ps PersistentStore) buildLabelIndex(buildingID string) (LabelIndex, error) {
+	tree, err := ps.Tree()
+	if err != nil {
+		return LabelIndex{}, err
+	}
+	index := newLabelIndexOf(tree, buildingID)
+	data, err := json.Marshal(index)
+	if err != nil {
+		return LabelIndex{}, err
+	}
+	_, _, err = ps.kvStore.AtomicPut(ps.labelsPath(buildingID), data, nil, nil)
+	if err != nil && err != store.ErrKeyExists {
+		return LabelIndex{}, err
+	}
+	return index, nil
+}
+
+// indexLabels replaces the labels of the children of the parent path in
+// the index after their collection is written. The index is kept per
+// building so that a write reads and writes the entities of its building
+// only, the buildings removed from the collection lose their index
+func (ps PersistentStore) indexLabels(parent string, entities map[string]map[string]string) error {
+	if parent != "" {
+		buildingID := strings.SplitN(parent, "/", 2)[0]
+		return ps.updateLabelIndex(buildingID, func(index LabelIndex) bool {
+			index.replace(parent, entities)
+			return true
+		})
+	}
+
+	for id, entityLabels := range entities {
+		entityLabels := entityLabels
+		err := ps.updateLabelIndex(id, func(index LabelIndex) bool {
+			if current, ok := index.Entities[id]; ok && reflect.DeepEqual(current, entityLabels) {
+				return false
+			}
+			index.Entities[id] = entityLabels
+			index.reindexKeys()
+			return true
+		})
+		if err != nil {
+			return err
+		}
+	}
+	indexes, err := ps.kvStore.List(ps.labelsRootPath()+"/", nil)
+	if err != nil && err != store.ErrKeyNotFound {
+		return err
+	}
+	for _, kv := range indexes {
+		if _, ok := entities[path.Base(kv.Key)]; ok {
+			continue
+		}
+		if err := ps.kvStore.Delete(kv.Key); err != nil && err != store.ErrKeyNotFound {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateLabelIndex applies the update to the index of the building and
+// writes it back unless it was modified meanwhile, in which case the update
+// is applied again to the index read afresh. An index which is missing is
+// built from the entities which already hold the write
+func (ps PersistentStore) updateLabelIndex(buildingID string, update func(index LabelIndex) bool) error {
+	key := ps.labelsPath(buildingID)
+	for attempt := 0; attempt < maxLabelIndexAttempts; attempt++ {
+		previous, err := ps.kvStore.Get(key, nil)
+		if err == store.ErrKeyNotFound {
+			_, err = ps.buildLabelIndex(buildingID)
+			return err
+		} else if err != nil {
+			return err
+		}
+		index, err := NewLabelIndex(previous.Value)
+		if err != nil {
+			return err
+		}
+		if !update(index) {
+			return nil
+		}
+		data, err := json.Marshal(index)
+		if err != nil {
+			return err
+		}
+		_, _, err = ps.kvStore.AtomicPut(key, data, previous, nil)
+		if err == store.ErrKeyModified || err == store.ErrKeyNotFound {
+			continue
+		}
+		return err
+	}
+	return fmt.Errorf("unable to update label index %s, it was modified concurrently %d times", key, maxLabelIndexAttempts)
+}
+
+// verifyLabelIndex returns the verification of the persisted index of the
+// building, the index is stale when it differs from the labels of the
+// entities of the tree
+func verifyLabelIndex(tree gateway.Tree, treeErr error, buildingID string) func(data []byte) error {
+	return func(data []byte) error {
+		index, err := NewLabelIndex(data)
+		if err != nil {
+			return err
+		}
+		if treeErr != nil {
+			return treeErr
+		}
+		expected := newLabelIndexOf(tree, buildingID)
+		for _, value := range []LabelIndex{index, expected} {
+			for entity, entityLabels := range value.Entities {
+				if len(entityLabels) == 0 {
+					value.Entities[entity] = nil
+				}
+			}
+		}
+		if !reflect.DeepEqual(index, expected) {
+			return fmt.Errorf("stale, it is rebuilt from the entities once deleted")
+		}
+		return nil
+	}
+}
+
+func (ps PersistentStore) labelsRootPath() string {
+	return path.Join(ps.path, labelsBasePath)
+}
+
+func (ps PersistentStore) labelsPath(buildingID string) string {
+	return path.Join(ps.labelsRootPath(), buildingID)
+}
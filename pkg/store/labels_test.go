@@ -0,0 +1,177 @@
+package store_test
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	libKVStore "github.com/kvtools/valkeyrie/store"
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+	mockKVStore "gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/internal/mocks/valkeyrie/store"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/labels"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/store"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/testutils"
+)
+
+// labelIndexKey matches the keys of the label indexes of the buildings
+type labelIndexKey struct{}
+
+func (labelIndexKey) Matches(x interface{}) bool {
+	key, ok := x.(string)
+	return ok && strings.HasPrefix(key, "dwarka/_labels/")
+}
+
+func (labelIndexKey) String() string {
+	return "is a key of a label index"
+}
+
+// expectLabelIndex expects the label indexes of the buildings to be
+// updated once the collection of the buildings, floors, rooms or devices
+// is written
+func expectLabelIndex(mockStore *mockKVStore.MockStore) {
+	mockStore.EXPECT().Get(labelIndexKey{}, nil).Return(&libKVStore.KVPair{Value: []byte(`{}`)}, nil).AnyTimes()
+	mockStore.EXPECT().AtomicPut(labelIndexKey{}, gomock.Any(), gomock.Any(), nil).Return(true, nil, nil).AnyTimes()
+	mockStore.EXPECT().List("dwarka/_labels/", nil).Return(nil, libKVStore.ErrKeyNotFound).AnyTimes()
+}
+
+func selectLabelled(t *testing.T, persistentStore store.Store, selector string) []string {
+	parsed, err := labels.Parse(selector)
+	if err != nil {
+		t.Fatal(err)
+	}
+	labelled, err := persistentStore.Labelled(parsed)
+	assert.NoError(t, err)
+	paths := []string{}
+	for path := range labelled {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func TestPersistentStore_Labelled(t *testing.T) {
+	building := testutils.NewBuilding("building-one")
+	building.Labels = map[string]string{"env": "home"}
+	floor := testutils.NewFloor("floor-one")
+	floor.Labels = map[string]string{"env": "indoor", "level": "ground"}
+	room := testutils.NewRoom("room-one")
+	porch := testutils.NewDevice("porch-light")
+	porch.Labels = map[string]string{"env": "outdoor", "type": "light"}
+	fan := testutils.NewDevice("ceiling-fan")
+	fan.Labels = map[string]string{"env": "indoor", "type": "fan"}
+
+	t.Run("should select the entities using the index kept up to date on writes", func(t *testing.T) {
+		kvStore, _ := newBoltDB(t)
+		persistentStore := store.NewPersistentStore("dwarka", kvStore)
+		assert.NoError(t, persistentStore.UpsertBuilding(building))
+		assert.NoError(t, persistentStore.UpsertFloor(floor))
+		assert.NoError(t, persistentStore.UpsertRoom(room))
+		assert.NoError(t, persistentStore.UpsertDevice(porch))
+		assert.NoError(t, persistentStore.UpsertDevice(fan))
+
+		assert.Equal(t, []string{"building-one/floor-one", "building-one/floor-one/room-one/ceiling-fan"}, selectLabelled(t, persistentStore, "env=indoor"))
+		assert.Equal(t, []string{"building-one/floor-one/room-one/ceiling-fan", "building-one/floor-one/room-one/porch-light"}, selectLabelled(t, persistentStore, "type in (light,fan)"))
+		assert.Equal(t, []string{"building-one/floor-one/room-one"}, selectLabelled(t, persistentStore, "!env"))
+		assert.Equal(t, []string{"building-one/floor-one/room-one/porch-light"}, selectLabelled(t, persistentStore, "env=outdoor,type in (light,fan)"))
+		problems, err := store.Verify(kvStore, "dwarka")
+		assert.NoError(t, err)
+		assert.Empty(t, problems)
+
+		fan.Labels = map[string]string{"type": "fan"}
+		assert.NoError(t, persistentStore.UpsertDevice(fan))
+		assert.Equal(t, []string{"building-one/floor-one"}, selectLabelled(t, persistentStore, "env=indoor"))
+
+		assert.NoError(t, persistentStore.DeleteFloor(floor))
+		assert.Equal(t, []string{"building-one"}, selectLabelled(t, persistentStore, ""))
+	})
+
+	t.Run("should build the index of a store which predates it", func(t *testing.T) {
+		kvStore, _ := newBoltDB(t)
+		persistentStore := store.NewPersistentStore("dwarka", kvStore)
+		assert.NoError(t, persistentStore.UpsertBuilding(building))
+		assert.NoError(t, persistentStore.UpsertFloor(floor))
+		assert.NoError(t, kvStore.Delete("dwarka/_labels/building-one"))
+
+		assert.Equal(t, []string{"building-one", "building-one/floor-one"}, selectLabelled(t, persistentStore, "env"))
+		_, err := kvStore.Get("dwarka/_labels/building-one", nil)
+		assert.NoError(t, err)
+
+		assert.NoError(t, kvStore.Delete("dwarka/_labels/building-one"))
+		assert.NoError(t, persistentStore.UpsertRoom(room))
+		assert.Equal(t, []string{"building-one", "building-one/floor-one"}, selectLabelled(t, persistentStore, "env"))
+	})
+
+	t.Run("should keep an index per building and drop the index of a deleted building", func(t *testing.T) {
+		kvStore, _ := newBoltDB(t)
+		persistentStore := store.NewPersistentStore("dwarka", kvStore)
+		other := testutils.NewBuilding("building-two")
+		other.Labels = map[string]string{"env": "office"}
+		assert.NoError(t, persistentStore.UpsertBuilding(building))
+		assert.NoError(t, persistentStore.UpsertFloor(floor))
+		assert.NoError(t, persistentStore.UpsertBuilding(other))
+
+		assert.Equal(t, []string{"building-one", "building-one/floor-one", "building-two"}, selectLabelled(t, persistentStore, "env"))
+		data, err := kvStore.Get("dwarka/_labels/building-two", nil)
+		if assert.NoError(t, err) {
+			assert.JSONEq(t, `{"entities":{"building-two":{"env":"office"}},"keys":{"env":["building-two"]}}`, string(data.Value))
+		}
+
+		assert.NoError(t, persistentStore.DeleteBuilding(building))
+		assert.Equal(t, []string{"building-two"}, selectLabelled(t, persistentStore, "env"))
+		_, err = kvStore.Get("dwarka/_labels/building-one", nil)
+		assert.Equal(t, libKVStore.ErrKeyNotFound, err)
+	})
+
+	t.Run("should keep the labels written concurrently", func(t *testing.T) {
+		kvStore, _ := newBoltDB(t)
+		persistentStore := store.NewPersistentStore("dwarka", kvStore)
+		assert.NoError(t, persistentStore.UpsertBuilding(building))
+		assert.NoError(t, persistentStore.UpsertFloor(floor))
+		rooms := gateway.Rooms{}
+		for i := 0; i < 8; i++ {
+			room := testutils.NewRoom(fmt.Sprintf("room-%d", i))
+			rooms[room.ID()] = room
+		}
+		assert.NoError(t, persistentStore.UpsertRooms(floor, rooms))
+
+		var wg sync.WaitGroup
+		for _, room := range rooms {
+			device := testutils.NewDevice("lamp")
+			device.Room = room
+			device.Labels = map[string]string{"type": "light"}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				assert.NoError(t, persistentStore.UpsertDevice(device))
+			}()
+		}
+		wg.Wait()
+
+		assert.Len(t, selectLabelled(t, persistentStore, "type=light"), len(rooms))
+		problems, err := store.Verify(kvStore, "dwarka")
+		assert.NoError(t, err)
+		assert.Empty(t, problems)
+	})
+
+	t.Run("should report the stale index", func(t *testing.T) {
+		kvStore, _ := newBoltDB(t)
+		persistentStore := store.NewPersistentStore("dwarka", kvStore)
+		assert.NoError(t, persistentStore.UpsertBuilding(building))
+		assert.NoError(t, kvStore.Put("dwarka/buildings", []byte(`{"building-one":{"name":"building-one"}}`), nil))
+
+		problems, err := store.Verify(kvStore, "dwarka")
+
+		assert.NoError(t, err)
+		assert.Equal(t, []store.Problem{{Key: "dwarka/_labels/building-one", Reason: "stale, it is rebuilt from the entities once deleted"}}, problems)
+	})
+}
+
+func TestNewLabelIndex(t *testing.T) {
+	_, err := store.NewLabelIndex([]byte(`[]`))
+
+	assert.EqualError(t, err, "unable to parse label index, json: cannot unmarshal array into Go value of type store.LabelIndex")
+}
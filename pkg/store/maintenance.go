@@ -0,0 +1,233 @@
+package store
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kvtools/valkeyrie/store"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/audit"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/auth"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+)
+
+// Entry is a key along with its value as persisted in the kv store
+type Entry struct {
+	Key   string `json:"key"`
+	Value []byte `json:"value"`
+}
+
+// Snapshot holds every key persisted under the base path, it is
+// used to backup and restore the store irrespective of the backend
+type Snapshot struct {
+	BasePath  string    `json:"basePath"`
+	CreatedAt time.Time `json:"createdAt"`
+	Entries   []Entry   `json:"entries"`
+}
+
+// Problem describes a key which is invalid or unreachable from the buildings
+type Problem struct {
+	Key    string `json:"key"`
+	Reason string `json:"reason"`
+}
+
+func (problem Problem) String() string {
+	return fmt.Sprintf("%s: %s", problem.Key, problem.Reason)
+}
+
+// Backup reads every key under the base path ordered by key, the tokens
+// and the signing key of the jwts are left out so that the secrets never
+// leave the store. The audit stream is kept next to the base path so that
+// restoring a backup keeps the entries appended after it
+func Backup(kvStore store.Store, basePath string) (Snapshot, error) {
+	entries, err := listTree(kvStore, basePath)
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	snapshot := Snapshot{BasePath: basePath, CreatedAt: time.Now().UTC(), Entries: []Entry{}}
+	for _, entry := range entries {
+		if !unexported(basePath, entry.Key) {
+			snapshot.Entries = append(snapshot.Entries, entry)
+		}
+	}
+	return snapshot, nil
+}
+
+// listTree reads every key under the base path ordered by key
+func listTree(kvStore store.Store, basePath string) ([]Entry, error) {
+	pairs, err := kvStore.List(basePath, nil)
+	if err != nil && err != store.ErrKeyNotFound {
+		return nil, err
+	}
+
+	entries := []Entry{}
+	for _, pair := range pairs {
+		key := strings.TrimPrefix(pair.Key, "/")
+		// the list matches the prefix e.g. dwarka-old/buildings for dwarka
+		if key != basePath && !strings.HasPrefix(key, basePath+"/") {
+			continue
+		}
+		entries = append(entries, Entry{Key: key, Value: pair.Value})
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+	return entries, nil
+}
+
+// unexported returns true when the key under the base path is kept out of
+// the backups and the restores i.e. the credentials
+func unexported(basePath, key string) bool {
+	return strings.HasPrefix(key, path.Join(basePath, authPath)+"/")
+}
+
+// Restore replaces every key under the base path with the entries of
+// the snapshot, the keys are moved from the base path of the snapshot
+// to the given base path
+func Restore(kvStore store.Store, snapshot Snapshot, basePath string) error {
+	entries := make([]Entry, len(snapshot.Entries))
+	for i, entry := range snapshot.Entries {
+		entries[i] = Entry{Key: path.Join(basePath, strings.TrimPrefix(entry.Key, snapshot.BasePath)), Value: entry.Value}
+	}
+	return replaceTree(kvStore, basePath, entries)
+}
+
+// replaceTree writes the entries and then deletes the keys under the base
+// path which are not among them, a failed write leaves the keys in place.
+// The credentials and the trees next to the base path e.g. dwarka-old for
+// dwarka are untouched
+func replaceTree(kvStore store.Store, basePath string, entries []Entry) error {
+	existing, err := Backup(kvStore, basePath)
+	if err != nil {
+		return err
+	}
+
+	written := map[string]bool{}
+	for _, entry := range entries {
+		if unexported(basePath, entry.Key) {
+			continue
+		}
+		if err := kvStore.Put(entry.Key, entry.Value, nil); err != nil {
+			return fmt.Errorf("unable to write %s, reason: %v", entry.Key, err)
+		}
+		written[entry.Key] = true
+	}
+	for _, entry := range existing.Entries {
+		if written[entry.Key] {
+			continue
+		}
+		if err := kvStore.Delete(entry.Key); err != nil && err != store.ErrKeyNotFound {
+			return fmt.Errorf("unable to delete %s, reason: %v", entry.Key, err)
+		}
+	}
+	return nil
+}
+
+// Verify parses every collection reachable from the buildings and reports
+// the collections which are invalid along with the keys which are not
+// reachable from the buildings e.g. floors of a deleted building
+func Verify(kvStore store.Store, basePath string) ([]Problem, error) {
+	entries, err := listTree(kvStore, basePath)
+	if err != nil {
+		return nil, err
+	}
+
+	values := map[string][]byte{}
+	for _, entry := range entries {
+		values[entry.Key] = entry.Value
+	}
+
+	ps := PersistentStore{path: basePath, kvStore: kvStore}
+	problems := []Problem{}
+	checked := map[string]bool{ps.uptimeRootPath(): true, ps.signingKeyRootPath(): true}
+	check := func(key string, parse func(data []byte) error) {
+		data, ok := values[key]
+		if !ok {
+			return
+		}
+		checked[key] = true
+		if err := parse(data); err != nil {
+			problems = append(problems, Problem{Key: key, Reason: err.Error()})
+		}
+	}
+
+	check(ps.tokensRootPath(), func(data []byte) error {
+		_, err := auth.NewTokens(data)
+		return err
+	})
+	auditEntries, err := listTree(kvStore, AuditRootPath(basePath))
+	if err != nil {
+		return nil, err
+	}
+	months := path.Join(AuditRootPath(basePath), auditMonths) + "/"
+	for _, entry := range auditEntries {
+		if strings.HasPrefix(entry.Key, months) {
+			continue
+		}
+		if _, err := audit.NewEntryFromJSON(entry.Value); err != nil {
+			problems = append(problems, Problem{Key: entry.Key, Reason: err.Error()})
+		}
+	}
+
+	var buildings gateway.Buildings
+	check(ps.buildingsRootPath(), func(data []byte) (err error) {
+		buildings, err = gateway.NewBuildings(data)
+		return err
+	})
+	tree, treeErr := ps.Tree()
+	for _, building := range buildings {
+		check(ps.labelsPath(building.ID()), verifyLabelIndex(tree, treeErr, building.ID()))
+		check(ps.scenesRootPath(building), func(data []byte) error {
+			_, err := gateway.NewScenes(building, data)
+			return err
+		})
+		check(ps.automationsRootPath(building), func(data []byte) error {
+			_, err := gateway.NewAutomations(building, data)
+			return err
+		})
+		check(ps.schedulesRootPath(building), func(data []byte) error {
+			_, err := gateway.NewSchedules(building, data)
+			return err
+		})
+		check(ps.groupsRootPath(building), func(data []byte) error {
+			_, err := gateway.NewGroups(building, data)
+			return err
+		})
+		var floors gateway.Floors
+		check(ps.floorsRootPath(building), func(data []byte) (err error) {
+			floors, err = gateway.NewFloors(building, data)
+			return err
+		})
+		for _, floor := range floors {
+			var rooms gateway.Rooms
+			check(ps.roomsRootPath(floor), func(data []byte) (err error) {
+				rooms, err = gateway.NewRooms(floor, data)
+				return err
+			})
+			for _, room := range rooms {
+				check(ps.devicesRootPath(room), func(data []byte) error {
+					_, err := gateway.NewDevices(room, data)
+					return err
+				})
+				check(ps.nodesRootPath(room), func(data []byte) error {
+					_, err := gateway.NewNodes(room, data)
+					return err
+				})
+				check(ps.statesRootPath(room), func(data []byte) error {
+					_, err := gateway.NewStates(data)
+					return err
+				})
+			}
+		}
+	}
+
+	for _, entry := range entries {
+		if !checked[entry.Key] {
+			problems = append(problems, Problem{Key: entry.Key, Reason: "orphaned, not reachable from the buildings"})
+		}
+	}
+	sort.Slice(problems, func(i, j int) bool { return problems[i].Key < problems[j].Key })
+	return problems, nil
+}
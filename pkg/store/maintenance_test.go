@@ -0,0 +1,237 @@
+package store_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	libKVStore "github.com/kvtools/valkeyrie/store"
+	"github.com/kvtools/valkeyrie/store/boltdb"
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/audit"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/auth"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+	mockKVStore "gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/internal/mocks/valkeyrie/store"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/store"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/testutils"
+)
+
+func init() {
+	boltdb.Register()
+}
+
+// newBoltDB returns the kv store backed by a BoltDB file in a temp directory
+// along with the path of the file
+func newBoltDB(t *testing.T) (libKVStore.Store, string) {
+	filePath := filepath.Join(t.TempDir(), "dwarka.db")
+	kvStore, err := store.NewKVStore(string(libKVStore.BOLTDB), "dwarka", filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return kvStore, filePath
+}
+
+func put(t *testing.T, kvStore libKVStore.Store, key string, value interface{}) {
+	data, err := json.Marshal(value)
+	if err == nil {
+		err = kvStore.Put(key, data, nil)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// failingReads returns the kv store failing to read the key
+func failingReads(t *testing.T, key string) libKVStore.Store {
+	kvStore := mockKVStore.NewMockStore(gomock.NewController(t))
+	kvStore.EXPECT().Get(key, nil).Return(nil, fmt.Errorf("store unavailable")).AnyTimes()
+	return kvStore
+}
+
+// failingWrites returns the kv store holding the value at the key and
+// failing to write it
+func failingWrites(t *testing.T, key string, value interface{}) libKVStore.Store {
+	data, err := json.Marshal(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kvStore := mockKVStore.NewMockStore(gomock.NewController(t))
+	kvStore.EXPECT().Get(key, nil).Return(&libKVStore.KVPair{Key: key, Value: data}, nil).AnyTimes()
+	kvStore.EXPECT().Put(key, gomock.Any(), nil).Return(fmt.Errorf("store unavailable")).AnyTimes()
+	return kvStore
+}
+
+func TestBackup(t *testing.T) {
+	t.Run("should read every key under the base path", func(t *testing.T) {
+		kvStore, _ := newBoltDB(t)
+		buildings, _ := testutils.NewBuildings("building-one")
+		put(t, kvStore, "dwarka/buildings", buildings)
+		put(t, kvStore, "dwarka/status/server", testutils.Uptime())
+		put(t, kvStore, "dwarka-old/buildings", buildings)
+
+		snapshot, err := store.Backup(kvStore, "dwarka")
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, "dwarka", snapshot.BasePath)
+			assert.Len(t, snapshot.Entries, 2)
+			assert.Equal(t, "dwarka/buildings", snapshot.Entries[0].Key)
+			assert.Equal(t, "dwarka/status/server", snapshot.Entries[1].Key)
+		}
+	})
+
+	t.Run("should leave out the audit stream and the credentials", func(t *testing.T) {
+		kvStore, _ := newBoltDB(t)
+		persistentStore := store.NewPersistentStore("dwarka", kvStore)
+		put(t, kvStore, "dwarka/buildings", gateway.Buildings{})
+		assert.NoError(t, persistentStore.AppendAudit(audit.Entry{ID: "0000000000000000001-0a1b2c3d", Entity: "home"}))
+		token, _, _ := auth.NewAPIKey("alice", time.Now(), 0)
+		assert.NoError(t, persistentStore.UpsertToken(token))
+		_, err := persistentStore.SigningKey()
+		assert.NoError(t, err)
+
+		snapshot, err := store.Backup(kvStore, "dwarka")
+
+		if assert.NoError(t, err) && assert.Len(t, snapshot.Entries, 1) {
+			assert.Equal(t, "dwarka/buildings", snapshot.Entries[0].Key)
+		}
+	})
+
+	t.Run("should return empty snapshot for empty store", func(t *testing.T) {
+		kvStore, _ := newBoltDB(t)
+
+		snapshot, err := store.Backup(kvStore, "dwarka")
+
+		if assert.NoError(t, err) {
+			assert.Empty(t, snapshot.Entries)
+		}
+	})
+}
+
+func TestRestore(t *testing.T) {
+	t.Run("should replace the keys under the base path", func(t *testing.T) {
+		kvStore, _ := newBoltDB(t)
+		buildings, _ := testutils.NewBuildings("building-one")
+		put(t, kvStore, "dwarka/buildings", buildings)
+		snapshot, err := store.Backup(kvStore, "dwarka")
+		if !assert.NoError(t, err) {
+			return
+		}
+		put(t, kvStore, "dwarka/building-two/floors", gateway.Floors{})
+
+		err = store.Restore(kvStore, snapshot, "dwarka")
+
+		if assert.NoError(t, err) {
+			restored, err := store.Backup(kvStore, "dwarka")
+			assert.NoError(t, err)
+			assert.Equal(t, snapshot.Entries, restored.Entries)
+		}
+	})
+
+	t.Run("should move the keys to the given base path", func(t *testing.T) {
+		kvStore, _ := newBoltDB(t)
+		buildings, _ := testutils.NewBuildings("building-one")
+		put(t, kvStore, "dwarka/buildings", buildings)
+		snapshot, err := store.Backup(kvStore, "dwarka")
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		err = store.Restore(kvStore, snapshot, "home")
+
+		if assert.NoError(t, err) {
+			actual, err := store.NewPersistentStore("home", kvStore).Buildings()
+			assert.NoError(t, err)
+			assert.Equal(t, buildings, actual)
+		}
+	})
+
+	t.Run("should leave the trees next to the base path untouched", func(t *testing.T) {
+		kvStore, _ := newBoltDB(t)
+		buildings, _ := testutils.NewBuildings("building-one")
+		put(t, kvStore, "dwarka/buildings", buildings)
+		put(t, kvStore, "dwarka-old/buildings", buildings)
+
+		err := store.Restore(kvStore, store.Snapshot{BasePath: "dwarka", Entries: []store.Entry{}}, "dwarka")
+
+		if assert.NoError(t, err) {
+			restored, _ := store.Backup(kvStore, "dwarka")
+			old, _ := store.Backup(kvStore, "dwarka-old")
+			assert.Empty(t, restored.Entries)
+			assert.Len(t, old.Entries, 1)
+		}
+	})
+
+	t.Run("should keep the audit stream", func(t *testing.T) {
+		kvStore, _ := newBoltDB(t)
+		put(t, kvStore, "dwarka/buildings", gateway.Buildings{})
+		snapshot, err := store.Backup(kvStore, "dwarka")
+		if !assert.NoError(t, err) {
+			return
+		}
+		persistentStore := store.NewPersistentStore("dwarka", kvStore)
+		entry := audit.Entry{ID: "0000000000000000001-0a1b2c3d", Method: "DELETE", Entity: "home/terrace"}
+		assert.NoError(t, persistentStore.AppendAudit(entry))
+
+		err = store.Restore(kvStore, snapshot, "dwarka")
+
+		if assert.NoError(t, err) {
+			entries, err := persistentStore.Audit(audit.Query{})
+			assert.NoError(t, err)
+			assert.Equal(t, audit.Entries{entry}, entries)
+		}
+	})
+
+	t.Run("should keep the keys when a write fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		snapshot := store.Snapshot{BasePath: "dwarka", Entries: []store.Entry{{Key: "dwarka/buildings", Value: []byte("{}")}}}
+
+		mockStore := mockKVStore.NewMockStore(ctrl)
+		mockStore.EXPECT().List("dwarka", nil).Return([]*libKVStore.KVPair{{Key: "dwarka/building-two/floors", Value: []byte("{}")}}, nil)
+		mockStore.EXPECT().Put("dwarka/buildings", []byte("{}"), nil).Return(fmt.Errorf("store unavailable"))
+
+		err := store.Restore(mockStore, snapshot, "dwarka")
+
+		assert.EqualError(t, err, "unable to write dwarka/buildings, reason: store unavailable")
+	})
+}
+
+func TestVerify(t *testing.T) {
+	t.Run("should report no problems for consistent store", func(t *testing.T) {
+		kvStore, _ := newBoltDB(t)
+		persistentStore := store.NewPersistentStore("dwarka", kvStore)
+		device := testutils.NewDevice("porch-light")
+		room := device.Room
+		floor := room.Floor
+		building := floor.Building.(gateway.Building)
+		assert.NoError(t, persistentStore.UpsertBuilding(building))
+		assert.NoError(t, persistentStore.UpsertFloor(floor))
+		assert.NoError(t, persistentStore.UpsertRoom(room))
+		assert.NoError(t, persistentStore.UpsertDevice(device))
+		assert.NoError(t, persistentStore.RefreshUptime())
+
+		problems, err := store.Verify(kvStore, "dwarka")
+
+		if assert.NoError(t, err) {
+			assert.Empty(t, problems)
+		}
+	})
+
+	t.Run("should report invalid collections and orphaned keys", func(t *testing.T) {
+		kvStore, _ := newBoltDB(t)
+		buildings, _ := testutils.NewBuildings("building-one")
+		put(t, kvStore, "dwarka/buildings", buildings)
+		assert.NoError(t, kvStore.Put("dwarka/building-one/floors", []byte("[]"), nil))
+		put(t, kvStore, "dwarka/building-two/floors", gateway.Floors{})
+
+		problems, err := store.Verify(kvStore, "dwarka")
+
+		if assert.NoError(t, err) && assert.Len(t, problems, 2) {
+			assert.Equal(t, "dwarka/building-one/floors", problems[0].Key)
+			assert.Equal(t, store.Problem{Key: "dwarka/building-two/floors", Reason: "orphaned, not reachable from the buildings"}, problems[1])
+		}
+	})
+}
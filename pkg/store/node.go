@@ -0,0 +1,28 @@
+package store
+
+import (
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+	"path"
+)
+
+const (
+	nodesBasePath = "nodes"
+)
+
+// Nodes returns all the Nodes of the room from store
+func (ps PersistentStore) Nodes(room gateway.Room) (gateway.Nodes, error) {
+	value, err := ps.get(ps.nodesRootPath(room), gateway.Nodes{})
+	if err != nil {
+		return nil, err
+	}
+	return gateway.NewNodes(room, value)
+}
+
+// UpsertNodes creates or updates Nodes in store
+func (ps PersistentStore) UpsertNodes(room gateway.Room, nodes gateway.Nodes) error {
+	return ps.putJSON(ps.nodesRootPath(room), nodes)
+}
+
+func (ps PersistentStore) nodesRootPath(room gateway.Room) string {
+	return path.Join(ps.roomRootPath(room), nodesBasePath)
+}
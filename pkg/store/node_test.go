@@ -0,0 +1,74 @@
+package store_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-cmp/cmp"
+	libKVStore "github.com/kvtools/valkeyrie/store"
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+	mockKVStore "gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/internal/mocks/valkeyrie/store"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/store"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/testutils"
+)
+
+func TestPersistentStore_Nodes(t *testing.T) {
+	t.Run("should return nodes", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		room := testutils.NewRoom("room-one")
+		expectedNodes := gateway.Nodes{"node-one": gateway.NodeMetadata{
+			Room:           room,
+			Host:           "192.168.1.20",
+			Devices:        []string{"porch-light"},
+			PhysicalEntity: gateway.PhysicalEntity{Name: "node-one"},
+		}}
+		data, _ := json.Marshal(expectedNodes)
+
+		mockStore := mockKVStore.NewMockStore(ctrl)
+		mockStore.EXPECT().Get("dwarka/building-one/floor-one/room-one/nodes", nil).Return(&libKVStore.KVPair{Value: data}, nil)
+
+		persistentStore := store.NewPersistentStore("dwarka", mockStore)
+
+		actual, err := persistentStore.Nodes(room)
+
+		assert.NoError(t, err)
+		if !cmp.Equal(expectedNodes, actual) {
+			assert.Fail(t, cmp.Diff(expectedNodes, actual))
+		}
+	})
+
+	t.Run("should return empty nodes when none are persisted", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockStore := mockKVStore.NewMockStore(ctrl)
+		mockStore.EXPECT().Get("dwarka/building-one/floor-one/room-one/nodes", nil).Return(nil, libKVStore.ErrKeyNotFound)
+
+		persistentStore := store.NewPersistentStore("dwarka", mockStore)
+
+		actual, err := persistentStore.Nodes(testutils.NewRoom("room-one"))
+
+		assert.NoError(t, err)
+		assert.Equal(t, gateway.Nodes{}, actual)
+	})
+}
+
+func TestPersistentStore_UpsertNodes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	room := testutils.NewRoom("room-one")
+	nodes := gateway.Nodes{"node-one": gateway.NodeMetadata{Room: room, Host: "192.168.1.20", PhysicalEntity: gateway.PhysicalEntity{Name: "node-one"}}}
+	data, _ := json.Marshal(nodes)
+
+	mockStore := mockKVStore.NewMockStore(ctrl)
+	mockStore.EXPECT().Put("dwarka/building-one/floor-one/room-one/nodes", data, nil).Return(nil)
+
+	persistentStore := store.NewPersistentStore("dwarka", mockStore)
+
+	assert.NoError(t, persistentStore.UpsertNodes(room, nodes))
+}
@@ -2,6 +2,7 @@ package store
 
 import (
 	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/search"
 	"path"
 )
 
@@ -20,7 +21,7 @@ func (ps PersistentStore) Rooms(floor gateway.Floor) (gateway.Rooms, error) {
 
 // UpsertRooms creates or updates Rooms in store
 func (ps PersistentStore) UpsertRooms(floor gateway.Floor, rooms gateway.Rooms) error {
-	return ps.putJSON(ps.roomsRootPath(floor), rooms)
+	return ps.putRooms(floor, rooms)
 }
 
 // UpsertRoom creates or updates Room in store
@@ -30,7 +31,7 @@ func (ps PersistentStore) UpsertRoom(room gateway.Room) error {
 		return err
 	}
 	rooms[room.ID()] = room
-	return ps.putJSON(ps.roomsRootPath(room.Floor), rooms)
+	return ps.putRooms(room.Floor, rooms)
 }
 
 // DeleteRoom deletes the room and nested path from store
@@ -41,7 +42,7 @@ func (ps PersistentStore) DeleteRoom(room gateway.Room) error {
 	}
 
 	delete(rooms, room.ID())
-	err = ps.putJSON(ps.roomsRootPath(room.Floor), rooms)
+	err = ps.putRooms(room.Floor, rooms)
 	if err != nil {
 		return err
 	}
@@ -49,6 +50,19 @@ func (ps PersistentStore) DeleteRoom(room gateway.Room) error {
 	return ps.safeDelete(ps.roomRootPath(room))
 }
 
+// putRooms persists the rooms of the floor and indexes their labels
+func (ps PersistentStore) putRooms(floor gateway.Floor, rooms gateway.Rooms) error {
+	err := ps.putJSON(ps.roomsRootPath(floor), rooms)
+	if err != nil {
+		return err
+	}
+	documents := map[string]search.Document{}
+	for id, room := range rooms {
+		documents[id] = documentOf(room.PhysicalEntity, nil)
+	}
+	return ps.index(path.Join(floor.Building.ID(), floor.ID()), documents)
+}
+
 func (ps PersistentStore) roomsRootPath(floor gateway.Floor) string {
 	return path.Join(ps.floorRootPath(floor), roomsBasePath)
 }
@@ -105,6 +105,7 @@ func TestPersistentStore_UpsertRoom(t *testing.T) {
 			},
 		)
 
+		expectLabelIndex(mockStore)
 		persistentStore := store.NewPersistentStore("dwarka", mockStore)
 		newRoom := gateway.Room{
 			Direction: gateway.DirectionWest,
@@ -198,6 +199,7 @@ func TestPersistentStore_UpsertRooms(t *testing.T) {
 			},
 		)
 
+		expectLabelIndex(mockStore)
 		persistentStore := store.NewPersistentStore("dwarka", mockStore)
 		err := persistentStore.UpsertRooms(testutils.NewFloor("floor-one"), rooms)
 		assert.NoError(t, err)
@@ -261,6 +263,7 @@ func TestPersistentStore_DeleteRoom(t *testing.T) {
 			},
 		)
 
+		expectLabelIndex(mockStore)
 		persistentStore := store.NewPersistentStore("dwarka", mockStore)
 		err := persistentStore.DeleteRoom(room)
 		assert.NoError(t, err)
@@ -286,6 +289,7 @@ func TestPersistentStore_DeleteRoom(t *testing.T) {
 		mockStore.EXPECT().Put("dwarka/building-one/floor-one/rooms", gomock.Any(), nil).Return(nil)
 		mockStore.EXPECT().DeleteTree("dwarka/building-one/floor-one/room-one").Return(fmt.Errorf("unable to delete"))
 
+		expectLabelIndex(mockStore)
 		persistentStore := store.NewPersistentStore("dwarka", mockStore)
 		err := persistentStore.DeleteRoom(room)
 		if assert.Error(t, err) {
@@ -0,0 +1,79 @@
+package store
+
+import (
+	"fmt"
+	"path"
+
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+)
+
+const (
+	scenesBasePath = "_scenes"
+	statesBasePath = "_states"
+)
+
+// Scenes returns all the Scenes of the building from store
+func (ps PersistentStore) Scenes(building gateway.Entity) (gateway.Scenes, error) {
+	value, err := ps.get(ps.scenesRootPath(building), gateway.Scenes{})
+	if err != nil {
+		return nil, err
+	}
+	return gateway.NewScenes(building, value)
+}
+
+// UpsertScenes creates or updates Scenes in store
+func (ps PersistentStore) UpsertScenes(building gateway.Entity, scenes gateway.Scenes) error {
+	return ps.putJSON(ps.scenesRootPath(building), scenes)
+}
+
+// UpsertScene creates or updates Scene in store
+func (ps PersistentStore) UpsertScene(scene gateway.Scene) error {
+	scenes, err := ps.Scenes(scene.Building)
+	if err != nil {
+		return err
+	}
+	scenes[scene.ID()] = scene
+	return ps.putJSON(ps.scenesRootPath(scene.Building), scenes)
+}
+
+// DeleteScene deletes the scene from store, NotFound is returned when
+// the scene does not exist
+func (ps PersistentStore) DeleteScene(scene gateway.Scene) error {
+	scenes, err := ps.Scenes(scene.Building)
+	if err != nil {
+		return err
+	}
+	if _, ok := scenes[scene.ID()]; !ok {
+		return NotFound(fmt.Sprintf("scene %s not found", scene.ID()))
+	}
+
+	delete(scenes, scene.ID())
+	return ps.putJSON(ps.scenesRootPath(scene.Building), scenes)
+}
+
+// States returns the last reported state of the devices of the room
+func (ps PersistentStore) States(room gateway.Room) (gateway.States, error) {
+	value, err := ps.get(ps.statesRootPath(room), gateway.States{})
+	if err != nil {
+		return nil, err
+	}
+	return gateway.NewStates(value)
+}
+
+// UpsertState records the state the device was switched to
+func (ps PersistentStore) UpsertState(device gateway.Device, state gateway.State) error {
+	states, err := ps.States(device.Room)
+	if err != nil {
+		return err
+	}
+	states[device.ID()] = state
+	return ps.putJSON(ps.statesRootPath(device.Room), states)
+}
+
+func (ps PersistentStore) scenesRootPath(building gateway.Entity) string {
+	return path.Join(ps.buildingRootPath(building), scenesBasePath)
+}
+
+func (ps PersistentStore) statesRootPath(room gateway.Room) string {
+	return path.Join(ps.roomRootPath(room), statesBasePath)
+}
@@ -0,0 +1,75 @@
+package store_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/store"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/testutils"
+)
+
+func TestPersistentStore_Scenes(t *testing.T) {
+	building := testutils.NewBuilding("building-one")
+	scene := gateway.Scene{
+		Building:       building,
+		Targets:        []gateway.Target{{Floor: "floor-one", Room: "room-one", Device: "porch-light", State: gateway.StateOn}},
+		PhysicalEntity: gateway.PhysicalEntity{Name: "movie mode"},
+	}
+
+	t.Run("should upsert and delete scene", func(t *testing.T) {
+		kvStore, _ := newBoltDB(t)
+		persistentStore := store.NewPersistentStore("dwarka", kvStore)
+		assert.NoError(t, persistentStore.UpsertBuilding(building))
+
+		assert.NoError(t, persistentStore.UpsertScene(scene))
+		scenes, err := persistentStore.Scenes(building)
+		if assert.NoError(t, err) {
+			assert.Equal(t, gateway.Scenes{"movie-mode": scene}, scenes)
+		}
+		problems, err := store.Verify(kvStore, "dwarka")
+		assert.NoError(t, err)
+		assert.Empty(t, problems)
+
+		assert.NoError(t, persistentStore.DeleteScene(scene))
+		scenes, err = persistentStore.Scenes(building)
+		if assert.NoError(t, err) {
+			assert.Empty(t, scenes)
+		}
+	})
+
+	t.Run("should fail to delete missing scene", func(t *testing.T) {
+		kvStore, _ := newBoltDB(t)
+
+		err := store.NewPersistentStore("dwarka", kvStore).DeleteScene(scene)
+
+		assert.Equal(t, store.NotFound("scene movie-mode not found"), err)
+	})
+
+	t.Run("should keep the scenes along with a floor named scenes", func(t *testing.T) {
+		kvStore, _ := newBoltDB(t)
+		persistentStore := store.NewPersistentStore("dwarka", kvStore)
+		assert.NoError(t, persistentStore.UpsertScene(scene))
+
+		assert.NoError(t, persistentStore.DeleteFloor(gateway.Floor{Building: building, Level: 1, PhysicalEntity: gateway.PhysicalEntity{Name: "scenes"}}))
+
+		scenes, err := persistentStore.Scenes(building)
+		assert.NoError(t, err)
+		assert.Len(t, scenes, 1)
+	})
+}
+
+func TestPersistentStore_States(t *testing.T) {
+	kvStore, _ := newBoltDB(t)
+	persistentStore := store.NewPersistentStore("dwarka", kvStore)
+	device := testutils.NewDevice("porch-light")
+
+	assert.NoError(t, persistentStore.UpsertState(device, gateway.StateOn))
+	assert.NoError(t, persistentStore.UpsertState(testutils.NewDevice("ceiling-fan"), gateway.StateOff))
+	assert.NoError(t, persistentStore.UpsertState(device, gateway.StateOff))
+
+	states, err := persistentStore.States(device.Room)
+	if assert.NoError(t, err) {
+		assert.Equal(t, gateway.States{"porch-light": gateway.StateOff, "ceiling-fan": gateway.StateOff}, states)
+	}
+}
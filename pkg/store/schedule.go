@@ -0,0 +1,53 @@
+package store
+
+import (
+	"fmt"
+	"path"
+
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+)
+
+const schedulesBasePath = "_schedules"
+
+// Schedules returns all the Schedules of the building from store
+func (ps PersistentStore) Schedules(building gateway.Entity) (gateway.Schedules, error) {
+	value, err := ps.get(ps.schedulesRootPath(building), gateway.Schedules{})
+	if err != nil {
+		return nil, err
+	}
+	return gateway.NewSchedules(building, value)
+}
+
+// UpsertSchedules creates or updates Schedules in store
+func (ps PersistentStore) UpsertSchedules(building gateway.Entity, schedules gateway.Schedules) error {
+	return ps.putJSON(ps.schedulesRootPath(building), schedules)
+}
+
+// UpsertSchedule creates or updates Schedule in store
+func (ps PersistentStore) UpsertSchedule(schedule gateway.Schedule) error {
+	schedules, err := ps.Schedules(schedule.Building)
+	if err != nil {
+		return err
+	}
+	schedules[schedule.ID()] = schedule
+	return ps.putJSON(ps.schedulesRootPath(schedule.Building), schedules)
+}
+
+// DeleteSchedule deletes the schedule from store, NotFound is
+// returned when the schedule does not exist
+func (ps PersistentStore) DeleteSchedule(schedule gateway.Schedule) error {
+	schedules, err := ps.Schedules(schedule.Building)
+	if err != nil {
+		return err
+	}
+	if _, ok := schedules[schedule.ID()]; !ok {
+		return NotFound(fmt.Sprintf("schedule %s not found", schedule.ID()))
+	}
+
+	delete(schedules, schedule.ID())
+	return ps.putJSON(ps.schedulesRootPath(schedule.Building), schedules)
+}
+
+func (ps PersistentStore) schedulesRootPath(building gateway.Entity) string {
+	return path.Join(ps.buildingRootPath(building), schedulesBasePath)
+}
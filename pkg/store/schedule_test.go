@@ -0,0 +1,57 @@
+package store_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/store"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/testutils"
+)
+
+func TestPersistentStore_Schedules(t *testing.T) {
+	building := testutils.NewBuilding("building-one")
+	lastRun := time.Date(2026, 10, 19, 18, 30, 0, 0, time.UTC)
+	schedule := gateway.Schedule{
+		Building:       building,
+		Mode:           gateway.ScheduleRecurring,
+		Cron:           "30 18 * * 1-5",
+		Skip:           []string{"2026-12-25"},
+		Missed:         gateway.MissedRunOnce,
+		Device:         "floor-one/room-one/porch-light",
+		State:          gateway.StateOn,
+		Created:        time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+		LastRun:        &lastRun,
+		PhysicalEntity: gateway.PhysicalEntity{Name: "porch on weekdays"},
+	}
+
+	t.Run("should upsert and delete schedule", func(t *testing.T) {
+		kvStore, _ := newBoltDB(t)
+		persistentStore := store.NewPersistentStore("dwarka", kvStore)
+		assert.NoError(t, persistentStore.UpsertBuilding(building))
+
+		assert.NoError(t, persistentStore.UpsertSchedule(schedule))
+		schedules, err := persistentStore.Schedules(building)
+		if assert.NoError(t, err) {
+			assert.Equal(t, gateway.Schedules{"porch-on-weekdays": schedule}, schedules)
+		}
+		problems, err := store.Verify(kvStore, "dwarka")
+		assert.NoError(t, err)
+		assert.Empty(t, problems)
+
+		assert.NoError(t, persistentStore.DeleteSchedule(schedule))
+		schedules, err = persistentStore.Schedules(building)
+		if assert.NoError(t, err) {
+			assert.Empty(t, schedules)
+		}
+	})
+
+	t.Run("should fail to delete missing schedule", func(t *testing.T) {
+		kvStore, _ := newBoltDB(t)
+
+		err := store.NewPersistentStore("dwarka", kvStore).DeleteSchedule(schedule)
+
+		assert.Equal(t, store.NotFound("schedule porch-on-weekdays not found"), err)
+	})
+}
@@ -0,0 +1,68 @@
+package store
+
+import (
+	"path"
+
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/search"
+)
+
+// Search returns the buildings, floors, rooms and devices matching every
+// word of the query from the index kept in memory, the index is built
+// from the entities by the first search
+func (ps PersistentStore) Search(query string) ([]search.Hit, error) {
+	if !ps.text.Loaded() {
+		tree, err := ps.Tree()
+		if err != nil {
+			return nil, err
+		}
+		ps.text.Load(documentsOf(tree))
+	}
+	return ps.text.Search(query), nil
+}
+
+// Reindex drops the index kept in memory so that the next search builds
+// it from the entities e.g. once a backup is restored
+func (ps PersistentStore) Reindex() {
+	ps.text.Reset()
+}
+
+// index updates the label index and the index kept in memory with the
+// children of the parent path after their collection is written
+func (ps PersistentStore) index(parent string, documents map[string]search.Document) error {
+	entities := map[string]map[string]string{}
+	for id, document := range documents {
+		entities[id] = document.Labels
+	}
+	err := ps.indexLabels(parent, entities)
+	if err != nil {
+		return err
+	}
+	if ps.text != nil {
+		ps.text.Replace(parent, documents)
+	}
+	return nil
+}
+
+func documentOf(entity gateway.PhysicalEntity, meta map[string]string) search.Document {
+	return search.Document{Name: entity.Name, Description: entity.Description, Labels: entity.Labels, Meta: meta}
+}
+
+// documentsOf returns the documents of every entity of the tree keyed by
+// their path
+func documentsOf(tree gateway.Tree) map[string]search.Document {
+	documents := map[string]search.Document{}
+	for buildingID, building := range tree.Buildings {
+		documents[buildingID] = documentOf(building.PhysicalEntity, nil)
+		for floorID, floor := range tree.FloorsOf(building) {
+			documents[path.Join(buildingID, floorID)] = documentOf(floor.PhysicalEntity, nil)
+			for roomID, room := range tree.RoomsOf(floor) {
+				documents[path.Join(buildingID, floorID, roomID)] = documentOf(room.PhysicalEntity, nil)
+				for deviceID, device := range tree.DevicesOf(room) {
+					documents[path.Join(buildingID, floorID, roomID, deviceID)] = documentOf(device.PhysicalEntity, device.Meta)
+				}
+			}
+		}
+	}
+	return documents
+}
@@ -0,0 +1,56 @@
+package store_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/store"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/testutils"
+)
+
+func searchPaths(t *testing.T, persistentStore store.Store, query string) []string {
+	hits, err := persistentStore.Search(query)
+	assert.NoError(t, err)
+	paths := []string{}
+	for _, hit := range hits {
+		paths = append(paths, hit.Path)
+	}
+	return paths
+}
+
+func TestPersistentStore_Search(t *testing.T) {
+	geyser := testutils.NewDevice("geyser")
+	geyser.Meta = map[string]string{"capability": "heater"}
+
+	t.Run("should build the index from the entities and keep it up to date on writes", func(t *testing.T) {
+		kvStore, _ := newBoltDB(t)
+		persistentStore := store.NewPersistentStore("dwarka", kvStore)
+		assert.NoError(t, persistentStore.UpsertBuilding(geyser.Room.Floor.Building.(gateway.Building)))
+		assert.NoError(t, persistentStore.UpsertFloor(geyser.Room.Floor))
+		assert.NoError(t, persistentStore.UpsertRoom(geyser.Room))
+		assert.NoError(t, persistentStore.UpsertDevice(geyser))
+
+		assert.Equal(t, []string{"building-one/floor-one/room-one/geyser"}, searchPaths(t, persistentStore, "room-one heater"))
+
+		renamed := geyser.Room
+		renamed.Description = "guest bathroom"
+		assert.NoError(t, persistentStore.UpsertRoom(renamed))
+		assert.Equal(t, []string{"building-one/floor-one/room-one/geyser"}, searchPaths(t, persistentStore, "bathroom gyser"))
+
+		assert.NoError(t, persistentStore.DeleteRoom(renamed))
+		assert.Empty(t, searchPaths(t, persistentStore, "geyser"))
+	})
+
+	t.Run("should rebuild the index once dropped", func(t *testing.T) {
+		kvStore, _ := newBoltDB(t)
+		persistentStore := store.NewPersistentStore("dwarka", kvStore)
+		assert.NoError(t, persistentStore.UpsertBuilding(testutils.NewBuilding("building-one")))
+		assert.Equal(t, []string{"building-one"}, searchPaths(t, persistentStore, "building"))
+
+		assert.NoError(t, store.NewPersistentStore("dwarka", kvStore).UpsertBuilding(testutils.NewBuilding("building-two")))
+		persistentStore.Reindex()
+
+		assert.Equal(t, []string{"building-one", "building-two"}, searchPaths(t, persistentStore, "building"))
+	})
+}
@@ -6,7 +6,11 @@ import (
 
 	"github.com/kvtools/valkeyrie"
 	"github.com/kvtools/valkeyrie/store"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/audit"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/auth"
 	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/labels"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/search"
 )
 
 //go:generate $PWD/scripts/mockgen $PWD/pkg/store/store.go $PWD/pkg/internal/mocks/store/store.go mockStore
@@ -27,8 +31,42 @@ type Store interface {
 	UpsertRooms(floor gateway.Floor, rooms gateway.Rooms) error
 	UpsertRoom(room gateway.Room) error
 	DeleteRoom(room gateway.Room) error
+	Devices(room gateway.Room) (gateway.Devices, error)
+	UpsertDevices(room gateway.Room, devices gateway.Devices) error
+	UpsertDevice(device gateway.Device) error
+	DeleteDevice(device gateway.Device) error
+	Nodes(room gateway.Room) (gateway.Nodes, error)
+	UpsertNodes(room gateway.Room, nodes gateway.Nodes) error
+	Scenes(building gateway.Entity) (gateway.Scenes, error)
+	UpsertScenes(building gateway.Entity, scenes gateway.Scenes) error
+	UpsertScene(scene gateway.Scene) error
+	DeleteScene(scene gateway.Scene) error
+	Automations(building gateway.Entity) (gateway.Automations, error)
+	UpsertAutomations(building gateway.Entity, automations gateway.Automations) error
+	UpsertAutomation(automation gateway.Automation) error
+	DeleteAutomation(automation gateway.Automation) error
+	Schedules(building gateway.Entity) (gateway.Schedules, error)
+	UpsertSchedules(building gateway.Entity, schedules gateway.Schedules) error
+	UpsertSchedule(schedule gateway.Schedule) error
+	DeleteSchedule(schedule gateway.Schedule) error
+	Groups(building gateway.Entity) (gateway.Groups, error)
+	UpsertGroups(building gateway.Entity, groups gateway.Groups) error
+	UpsertGroup(group gateway.Group) error
+	DeleteGroup(group gateway.Group) error
+	States(room gateway.Room) (gateway.States, error)
+	UpsertState(device gateway.Device, state gateway.State) error
+	Tree() (gateway.Tree, error)
+	Labelled(selector labels.Selector) (map[string]map[string]string, error)
+	Search(query string) ([]search.Hit, error)
+	Reindex()
 	Uptime() (gateway.Status, error)
 	RefreshUptime() error
+	Tokens() (auth.Tokens, error)
+	UpsertToken(token auth.Token) error
+	DeleteToken(id string) error
+	SigningKey() ([]byte, error)
+	AppendAudit(entry audit.Entry) error
+	Audit(query audit.Query) (audit.Entries, error)
 }
 
 // NotFound is thrown when the key is not found in the store during a Get operation
@@ -40,10 +78,16 @@ func (err NotFound) Error() string {
 }
 
 // PersistentStore is a persistent implementation for Store
-// the data is persisted in one of the kv store supported by libkv
+// the data is persisted in one of the kv store supported by libkv.
+//
+// The keys of the collections which are not entities of the hierarchy
+// e.g. _scenes or _auth start with an underscore, which is never part of
+// a slug, so that they never collide with a floor, a room or a device and
+// deleting an entity named auth never deletes the tokens
 type PersistentStore struct {
 	path    string
 	kvStore store.Store
+	text    *search.Index
 }
 
 func (ps PersistentStore) get(path string, defaultValue interface{}) ([]byte, error) {
@@ -78,16 +122,27 @@ func (ps PersistentStore) safeDelete(path string) error {
 
 // NewPersistentStore returns a instance of PersistentStore
 func NewPersistentStore(path string, store store.Store) Store {
-	return &PersistentStore{path: path, kvStore: store}
+	return &PersistentStore{path: path, kvStore: store, text: search.NewIndex()}
 }
 
 // NewStore return libkv/store.PersistentStore with necessary defaults
 func NewStore(basePath string, backend string, bucketName string, addrs ...string) (Store, error) {
+	s, err := NewKVStore(backend, bucketName, addrs...)
+	if err != nil {
+		return nil, err
+	}
+	return NewPersistentStore(basePath, s), nil
+}
+
+// NewKVStore returns the kv store of the backend which is used by
+// PersistentStore, it is used as is by the maintenance operations and its
+// operations are measured in the metrics
+func NewKVStore(backend string, bucketName string, addrs ...string) (store.Store, error) {
 	s, err := valkeyrie.NewStore(store.Backend(backend), addrs, &store.Config{
 		Bucket: bucketName,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("unable to create backend store, reason: %v", err)
 	}
-	return NewPersistentStore(basePath, s), nil
+	return Instrument(s, backend), nil
 }
@@ -0,0 +1,75 @@
+package store
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/kvtools/valkeyrie/store"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/auth"
+)
+
+// the keys under authPath are never backed up or dumped
+const (
+	authPath       = "_auth"
+	tokensPath     = authPath + "/tokens"
+	signingKeyPath = authPath + "/signing-key"
+)
+
+// Tokens returns all the tokens issued to the clients from store
+func (ps PersistentStore) Tokens() (auth.Tokens, error) {
+	value, err := ps.get(ps.tokensRootPath(), auth.Tokens{})
+	if err != nil {
+		return nil, err
+	}
+	return auth.NewTokens(value)
+}
+
+// UpsertToken creates or updates Token in store
+func (ps PersistentStore) UpsertToken(token auth.Token) error {
+	tokens, err := ps.Tokens()
+	if err != nil {
+		return err
+	}
+	tokens[token.ID] = token
+	return ps.putJSON(ps.tokensRootPath(), tokens)
+}
+
+// DeleteToken deletes the token from store which revokes the credentials
+// issued with it, NotFound is returned when the token does not exist
+func (ps PersistentStore) DeleteToken(id string) error {
+	tokens, err := ps.Tokens()
+	if err != nil {
+		return err
+	}
+	if _, ok := tokens[id]; !ok {
+		return NotFound(fmt.Sprintf("token %s not found", id))
+	}
+
+	delete(tokens, id)
+	return ps.putJSON(ps.tokensRootPath(), tokens)
+}
+
+// SigningKey returns the key used to sign the JWTs, the key is
+// generated and persisted on first use
+func (ps PersistentStore) SigningKey() ([]byte, error) {
+	kv, err := ps.kvStore.Get(ps.signingKeyRootPath(), nil)
+	if err == nil {
+		return kv.Value, nil
+	} else if err != store.ErrKeyNotFound {
+		return nil, err
+	}
+
+	signingKey, err := auth.NewSigningKey()
+	if err != nil {
+		return nil, err
+	}
+	return signingKey, ps.put(ps.signingKeyRootPath(), signingKey)
+}
+
+func (ps PersistentStore) tokensRootPath() string {
+	return path.Join(ps.path, tokensPath)
+}
+
+func (ps PersistentStore) signingKeyRootPath() string {
+	return path.Join(ps.path, signingKeyPath)
+}
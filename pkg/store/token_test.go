@@ -0,0 +1,71 @@
+package store_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/auth"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/store"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/testutils"
+)
+
+func TestPersistentStore_Tokens(t *testing.T) {
+	t.Run("should upsert and delete token", func(t *testing.T) {
+		kvStore, _ := newBoltDB(t)
+		persistentStore := store.NewPersistentStore("dwarka", kvStore)
+		token, _, _ := auth.NewAPIKey("alice", time.Date(2026, 10, 19, 3, 0, 0, 0, time.UTC), 0)
+
+		assert.NoError(t, persistentStore.UpsertToken(token))
+		tokens, err := persistentStore.Tokens()
+		if assert.NoError(t, err) {
+			assert.Equal(t, auth.Tokens{token.ID: token}, tokens)
+		}
+
+		assert.NoError(t, persistentStore.DeleteToken(token.ID))
+		tokens, err = persistentStore.Tokens()
+		if assert.NoError(t, err) {
+			assert.Empty(t, tokens)
+		}
+	})
+
+	t.Run("should fail to delete missing token", func(t *testing.T) {
+		kvStore, _ := newBoltDB(t)
+
+		err := store.NewPersistentStore("dwarka", kvStore).DeleteToken("missing")
+
+		assert.Equal(t, store.NotFound("token missing not found"), err)
+	})
+
+	t.Run("should not delete tokens along with a building named auth", func(t *testing.T) {
+		kvStore, _ := newBoltDB(t)
+		persistentStore := store.NewPersistentStore("dwarka", kvStore)
+		token, _, _ := auth.NewAPIKey("alice", time.Now(), 0)
+		assert.NoError(t, persistentStore.UpsertToken(token))
+
+		assert.NoError(t, persistentStore.DeleteBuilding(testutils.NewBuilding("auth")))
+
+		tokens, err := persistentStore.Tokens()
+		if assert.NoError(t, err) {
+			assert.Contains(t, tokens, token.ID)
+		}
+	})
+}
+
+func TestPersistentStore_SigningKey(t *testing.T) {
+	kvStore, _ := newBoltDB(t)
+	persistentStore := store.NewPersistentStore("dwarka", kvStore)
+
+	generated, err := persistentStore.SigningKey()
+	assert.NoError(t, err)
+	assert.Len(t, generated, 32)
+
+	persisted, err := persistentStore.SigningKey()
+	assert.NoError(t, err)
+	assert.Equal(t, generated, persisted)
+
+	problems, err := store.Verify(kvStore, "dwarka")
+	if assert.NoError(t, err) {
+		assert.Empty(t, problems)
+	}
+}
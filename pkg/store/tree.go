@@ -0,0 +1,74 @@
+package store
+
+import (
+	"strings"
+
+	"github.com/kvtools/valkeyrie/store"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+)
+
+// Tree returns all the buildings along with their floors, rooms,
+// devices and nodes using a single read of every key under the base path
+func (ps PersistentStore) Tree() (gateway.Tree, error) {
+	pairs, err := ps.kvStore.List(ps.path, nil)
+	if err != nil && err != store.ErrKeyNotFound {
+		return gateway.Tree{}, err
+	}
+
+	values := map[string][]byte{}
+	for _, pair := range pairs {
+		values[strings.TrimPrefix(pair.Key, "/")] = pair.Value
+	}
+
+	tree := gateway.NewTree()
+	data, ok := values[ps.buildingsRootPath()]
+	if !ok {
+		return tree, nil
+	}
+
+	tree.Buildings, err = gateway.NewBuildings(data)
+	if err != nil {
+		return gateway.Tree{}, err
+	}
+
+	for _, building := range tree.Buildings {
+		floors, err := gateway.NewFloors(building, valueOf(values, ps.floorsRootPath(building)))
+		if err != nil {
+			return gateway.Tree{}, err
+		}
+		tree.AddFloors(building, floors)
+
+		for _, floor := range floors {
+			rooms, err := gateway.NewRooms(floor, valueOf(values, ps.roomsRootPath(floor)))
+			if err != nil {
+				return gateway.Tree{}, err
+			}
+			tree.AddRooms(floor, rooms)
+
+			for _, room := range rooms {
+				devices, err := gateway.NewDevices(room, valueOf(values, ps.devicesRootPath(room)))
+				if err != nil {
+					return gateway.Tree{}, err
+				}
+				tree.AddDevices(room, devices)
+
+				nodes, err := gateway.NewNodes(room, valueOf(values, ps.nodesRootPath(room)))
+				if err != nil {
+					return gateway.Tree{}, err
+				}
+				tree.AddNodes(room, nodes)
+			}
+		}
+	}
+	return tree, nil
+}
+
+// valueOf returns the value of the key or an empty collection
+// when the key is not persisted yet
+func valueOf(values map[string][]byte, key string) []byte {
+	data, ok := values[key]
+	if !ok {
+		return []byte("{}")
+	}
+	return data
+}
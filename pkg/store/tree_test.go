@@ -0,0 +1,86 @@
+package store_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	libKVStore "github.com/kvtools/valkeyrie/store"
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+	mockKVStore "gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/internal/mocks/valkeyrie/store"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/store"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/testutils"
+)
+
+func TestPersistentStore_Tree(t *testing.T) {
+	t.Run("should build the tree from a single list of the base path", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		device := testutils.NewDevice("porch-light")
+		room := device.Room
+		floor := room.Floor
+		building := floor.Building.(gateway.Building)
+		node := gateway.NodeMetadata{Room: room, Host: "192.168.1.20", Devices: []string{device.ID()}, PhysicalEntity: gateway.PhysicalEntity{Name: "node-one"}}
+
+		pair := func(key string, value interface{}) *libKVStore.KVPair {
+			data, _ := json.Marshal(value)
+			return &libKVStore.KVPair{Key: key, Value: data}
+		}
+
+		mockStore := mockKVStore.NewMockStore(ctrl)
+		mockStore.EXPECT().List("dwarka", nil).Return([]*libKVStore.KVPair{
+			pair("dwarka/buildings", gateway.Buildings{building.ID(): building}),
+			pair("dwarka/building-one/floors", gateway.Floors{floor.ID(): floor}),
+			pair("dwarka/building-one/floor-one/rooms", gateway.Rooms{room.ID(): room}),
+			pair("dwarka/building-one/floor-one/room-one/devices", gateway.Devices{device.ID(): device}),
+			pair("dwarka/building-one/floor-one/room-one/nodes", gateway.Nodes{node.ID(): node}),
+			pair("dwarka/status/server", testutils.Uptime()),
+		}, nil)
+
+		persistentStore := store.NewPersistentStore("dwarka", mockStore)
+
+		tree, err := persistentStore.Tree()
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, gateway.Buildings{building.ID(): building}, tree.Buildings)
+			assert.Equal(t, gateway.Floors{floor.ID(): floor}, tree.FloorsOf(building))
+			assert.Equal(t, gateway.Rooms{room.ID(): room}, tree.RoomsOf(floor))
+			assert.Equal(t, gateway.Devices{device.ID(): device}, tree.DevicesOf(room))
+			assert.Equal(t, gateway.Nodes{node.ID(): node}, tree.NodesOf(room))
+		}
+	})
+
+	t.Run("should return empty tree when nothing is persisted", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockStore := mockKVStore.NewMockStore(ctrl)
+		mockStore.EXPECT().List("dwarka", nil).Return(nil, libKVStore.ErrKeyNotFound)
+
+		persistentStore := store.NewPersistentStore("dwarka", mockStore)
+
+		tree, err := persistentStore.Tree()
+
+		assert.NoError(t, err)
+		assert.Empty(t, tree.Buildings)
+	})
+
+	t.Run("should handle error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockStore := mockKVStore.NewMockStore(ctrl)
+		mockStore.EXPECT().List("dwarka", nil).Return(nil, fmt.Errorf("store unavailable"))
+
+		persistentStore := store.NewPersistentStore("dwarka", mockStore)
+
+		_, err := persistentStore.Tree()
+
+		if assert.Error(t, err) {
+			assert.Equal(t, "store unavailable", err.Error())
+		}
+	})
+}
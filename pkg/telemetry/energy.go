@@ -0,0 +1,93 @@
+package telemetry
+
+import (
+	"path"
+	"time"
+)
+
+// EnergyMetrics are the metrics of the total energy in kWh counted by the
+// meters e.g. the ENERGY.Total of the smart plugs, in the order they are
+// preferred
+var EnergyMetrics = []string{"energy_total", "total", "energy", "kwh"}
+
+// PowerMetrics are the metrics of the power in W, the energy is derived
+// from the mean power of every hour when a device counts no energy
+var PowerMetrics = []string{"energy_power", "power", "watts"}
+
+// Consumption returns the energy in kWh consumed by the device of the
+// series, whose metric is ignored, from the time until the time, ok
+// is false when the device reports neither the energy nor the power. The
+// energy is read from the hourly points, every hour is accounted to the
+// time it starts at
+func (db *DB) Consumption(device Series, from, to time.Time) (kWh float64, ok bool, err error) {
+	metrics, err := db.Metrics(path.Join(device.Building, device.Floor, device.Room, device.Device))
+	if err != nil {
+		return 0, false, err
+	}
+	reported := map[string]bool{}
+	for _, metric := range metrics {
+		reported[metric] = true
+	}
+
+	series := func(metric string) Series {
+		device.Metric = metric
+		return device
+	}
+	for _, metric := range EnergyMetrics {
+		if reported[metric] {
+			kWh, err = db.counted(series(metric), from, to)
+			return kWh, err == nil, err
+		}
+	}
+	for _, metric := range PowerMetrics {
+		if reported[metric] {
+			kWh, err = db.integrated(series(metric), from, to)
+			return kWh, err == nil, err
+		}
+	}
+	return 0, false, nil
+}
+
+// counted returns the increase of the energy counter, the hour before the
+// time is read to account for the first hour, a counter going down is
+// taken as restarted from zero
+func (db *DB) counted(series Series, from, to time.Time) (float64, error) {
+	points, _, err := db.Query(series, from.Add(-time.Hour), to, time.Hour)
+	if err != nil {
+		return 0, err
+	}
+
+	total := 0.0
+	var previous *Point
+	for i := range points {
+		point := points[i]
+		if point.Time.Before(from) {
+			previous = &points[i]
+			continue
+		}
+		switch {
+		case previous == nil:
+			total += point.Last - point.Min
+		case point.Last >= previous.Last:
+			total += point.Last - previous.Last
+		default:
+			total += point.Last
+		}
+		previous = &points[i]
+	}
+	return total, nil
+}
+
+// integrated returns the energy of the mean power of every hour
+func (db *DB) integrated(series Series, from, to time.Time) (float64, error) {
+	points, _, err := db.Query(series, from, to, time.Hour)
+	if err != nil {
+		return 0, err
+	}
+
+	total := 0.0
+	for _, point := range points {
+		total += point.Mean / 1000
+	}
+	return total, nil
+}
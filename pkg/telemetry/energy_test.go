@@ -0,0 +1,61 @@
+package telemetry_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/telemetry"
+)
+
+func TestDB_Consumption(t *testing.T) {
+	start := time.Date(2026, 10, 19, 6, 0, 0, 0, time.UTC)
+	plug := telemetry.Series{Building: "home", Floor: "ground", Room: "hall", Device: "plug"}
+	reading := func(metric string, at time.Duration, value float64) telemetry.Reading {
+		series := plug
+		series.Metric = metric
+		return telemetry.Reading{Series: series, Time: start.Add(at), Value: value}
+	}
+
+	t.Run("should count the increase of the total energy restarting from zero", func(t *testing.T) {
+		db := openDB(t, start.Add(6*time.Hour))
+		assert.NoError(t, db.Append(
+			reading("energy_total", 0, 10), reading("energy_total", 30*time.Minute, 10.5), reading("energy_power", 0, 500),
+			reading("energy_total", 70*time.Minute, 11), reading("energy_total", 130*time.Minute, 0.25),
+			reading("energy_total", 190*time.Minute, 0.75),
+		))
+
+		kWh, ok, err := db.Consumption(plug, start, start.Add(4*time.Hour))
+
+		if assert.NoError(t, err) && assert.True(t, ok) {
+			assert.InDelta(t, 1.75, kWh, 1e-9)
+		}
+		kWh, _, err = db.Consumption(plug, start.Add(2*time.Hour), start.Add(4*time.Hour))
+		if assert.NoError(t, err) {
+			assert.InDelta(t, 0.75, kWh, 1e-9)
+		}
+	})
+
+	t.Run("should derive the energy from the mean power of every hour", func(t *testing.T) {
+		db := openDB(t, start.Add(6*time.Hour))
+		assert.NoError(t, db.Append(
+			reading("power", 0, 400), reading("power", 30*time.Minute, 600), reading("power", 90*time.Minute, 250),
+		))
+
+		kWh, ok, err := db.Consumption(plug, start, start.Add(4*time.Hour))
+
+		if assert.NoError(t, err) && assert.True(t, ok) {
+			assert.InDelta(t, 0.75, kWh, 1e-9)
+		}
+	})
+
+	t.Run("should not meter a device reporting neither the energy nor the power", func(t *testing.T) {
+		db := openDB(t, start.Add(6*time.Hour))
+		assert.NoError(t, db.Append(reading("temperature", 0, 21)))
+
+		_, ok, err := db.Consumption(plug, start, start.Add(4*time.Hour))
+
+		assert.NoError(t, err)
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,134 @@
+package telemetry
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TopicPrefix is the prefix of the topics the nodes publish their readings on
+const TopicPrefix = "tele/"
+
+var metricName = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]{0,62}$`)
+
+// Series identifies the readings of a metric of a device e.g. temperature
+type Series struct {
+	Building string `json:"building"`
+	Floor    string `json:"floor"`
+	Room     string `json:"room"`
+	Device   string `json:"device"`
+	Metric   string `json:"metric"`
+}
+
+// Key returns the series as building-id/floor-id/room-id/device-id/metric
+func (series Series) Key() string {
+	return path.Join(series.Building, series.Floor, series.Room, series.Device, series.Metric)
+}
+
+// Validate validates whether the metric is a lower cased name of up to 63
+// letters, digits, '.', '-' or '_'
+func (series Series) Validate() error {
+	if !metricName.MatchString(series.Metric) {
+		return fmt.Errorf("metric '%s' should have up to 63 lower case letters, digits, '.', '-' or '_' e.g. temperature", series.Metric)
+	}
+	return nil
+}
+
+// Reading is a value of the series at a time
+type Reading struct {
+	Series
+	Time  time.Time `json:"time"`
+	Value float64   `json:"value"`
+}
+
+// Validate validates the series and whether the value is a finite number
+func (reading Reading) Validate() error {
+	if !finite(reading.Value) {
+		return fmt.Errorf("value of %s should be a finite number", reading.Metric)
+	}
+	return reading.Series.Validate()
+}
+
+func finite(value float64) bool {
+	return !math.IsNaN(value) && !math.IsInf(value, 0)
+}
+
+// IsTelemetry returns true when the topic carries readings
+func IsTelemetry(topic string) bool {
+	return strings.HasPrefix(topic, TopicPrefix)
+}
+
+// Parse returns the readings of a message published at the time on a topic
+// of the building. The topic is tele/floor-id/room-id/device-id/metric
+// with a number as the payload, or tele/floor-id/room-id/device-id
+// optionally followed by a name e.g. SENSOR with a JSON object as the
+// payload whose numbers are the readings named by their lower cased keys,
+// the keys of the nested objects are joined by '_' e.g. energy_power
+func Parse(building, topic, payload string, at time.Time) ([]Reading, error) {
+	ids := strings.Split(strings.TrimPrefix(topic, TopicPrefix), "/")
+	if !IsTelemetry(topic) || len(ids) < 3 || len(ids) > 4 {
+		return nil, fmt.Errorf("topic %s should be given as tele/floor-id/room-id/device-id/metric", topic)
+	}
+	for _, id := range ids {
+		if id == "" {
+			return nil, fmt.Errorf("topic %s should be given as tele/floor-id/room-id/device-id/metric", topic)
+		}
+	}
+	device := Series{Building: building, Floor: ids[0], Room: ids[1], Device: ids[2]}
+
+	payload = strings.TrimSpace(payload)
+	if value, err := strconv.ParseFloat(payload, 64); err == nil {
+		if !finite(value) {
+			return nil, errors.New("payload should be a finite number")
+		}
+		if len(ids) != 4 {
+			return nil, fmt.Errorf("topic %s should be given as tele/floor-id/room-id/device-id/metric for a number", topic)
+		}
+		device.Metric = strings.ToLower(ids[3])
+		if err := device.Validate(); err != nil {
+			return nil, err
+		}
+		return []Reading{{Series: device, Time: at, Value: value}}, nil
+	}
+
+	values := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(payload), &values); err != nil {
+		return nil, errors.New("payload should be a number or a JSON object of numbers")
+	}
+	readings := []Reading{}
+	flatten("", values, func(metric string, value float64) {
+		series := device
+		series.Metric = metric
+		reading := Reading{Series: series, Time: at, Value: value}
+		if reading.Validate() == nil {
+			readings = append(readings, reading)
+		}
+	})
+	if len(readings) == 0 {
+		return nil, errors.New("payload has no numeric readings")
+	}
+	sort.Slice(readings, func(i, j int) bool { return readings[i].Metric < readings[j].Metric })
+	return readings, nil
+}
+
+func flatten(prefix string, values map[string]interface{}, reading func(metric string, value float64)) {
+	for key, value := range values {
+		metric := strings.ToLower(key)
+		if prefix != "" {
+			metric = prefix + "_" + metric
+		}
+		switch typed := value.(type) {
+		case float64:
+			reading(metric, typed)
+		case map[string]interface{}:
+			flatten(metric, typed, reading)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package telemetry_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/telemetry"
+)
+
+func TestParse(t *testing.T) {
+	at := time.Date(2026, 10, 19, 6, 0, 0, 0, time.UTC)
+	sensor := telemetry.Series{Building: "home", Floor: "ground", Room: "hall", Device: "sensor"}
+	series := func(metric string) telemetry.Series {
+		result := sensor
+		result.Metric = metric
+		return result
+	}
+
+	t.Run("should parse a number published on the topic of the metric", func(t *testing.T) {
+		readings, err := telemetry.Parse("home", "tele/ground/hall/sensor/Temperature", " 21.5 ", at)
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, []telemetry.Reading{{Series: series("temperature"), Time: at, Value: 21.5}}, readings)
+			assert.Equal(t, "home/ground/hall/sensor/temperature", readings[0].Key())
+		}
+	})
+
+	t.Run("should parse the numbers of a JSON object skipping the other values", func(t *testing.T) {
+		readings, err := telemetry.Parse("home", "tele/ground/hall/sensor/SENSOR",
+			`{"Time":"2026-10-19T06:00:00","Humidity":40,"ENERGY":{"Power":45.5,"Total":1.25}}`, at)
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, []telemetry.Reading{
+				{Series: series("energy_power"), Time: at, Value: 45.5},
+				{Series: series("energy_total"), Time: at, Value: 1.25},
+				{Series: series("humidity"), Time: at, Value: 40},
+			}, readings)
+		}
+	})
+
+	t.Run("should reject invalid messages", func(t *testing.T) {
+		for topic, payload := range map[string]string{
+			"tele/ground/hall":                    "21.5",
+			"tele/ground/hall/sensor":             "21.5",
+			"tele/ground//sensor/temperature":     "21.5",
+			"tele/ground/hall/sensor/temperature": "warm",
+			"tele/ground/hall/sensor/SENSOR":      `{"Time":"2026-10-19T06:00:00"}`,
+			"tele/ground/hall/sensor/temp rature": "21.5",
+		} {
+			_, err := telemetry.Parse("home", topic, payload, at)
+
+			assert.Error(t, err, topic)
+		}
+	})
+
+	t.Run("should reject the values which are not finite", func(t *testing.T) {
+		for _, payload := range []string{"NaN", "Inf", "-Inf", "+Inf"} {
+			_, err := telemetry.Parse("home", "tele/ground/hall/sensor/temperature", payload, at)
+
+			assert.EqualError(t, err, "payload should be a finite number", payload)
+		}
+		_, err := telemetry.Parse("home", "tele/ground/hall/sensor/SENSOR", `{"Temperature":1e400}`, at)
+
+		assert.Error(t, err)
+	})
+}
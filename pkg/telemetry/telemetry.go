@@ -0,0 +1,374 @@
+// Package telemetry stores the numeric readings of the devices e.g. the
+// temperature, the humidity and the power in a BoltDB file separate from
+// the store of the entities. Every reading is kept as is along with its
+// rollups of 5 minutes and of an hour, each of them for its own retention
+package telemetry
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.etcd.io/bbolt"
+)
+
+const openTimeout = 5 * time.Second
+
+// Resolution is the interval a point of a tier stands for, the raw
+// readings have no resolution
+type Resolution time.Duration
+
+// String returns the resolution as the name of its tier e.g. 5m
+func (resolution Resolution) String() string {
+	if resolution == 0 {
+		return "raw"
+	}
+	value := time.Duration(resolution).String()
+	if strings.HasSuffix(value, "m0s") {
+		value = strings.TrimSuffix(value, "0s")
+	}
+	if strings.HasSuffix(value, "h0m") {
+		value = strings.TrimSuffix(value, "0m")
+	}
+	return value
+}
+
+// resolutions of the tiers from the finest to the coarsest
+var resolutions = []Resolution{0, Resolution(5 * time.Minute), Resolution(time.Hour)}
+
+// Retention is how long the readings and their rollups are kept, zero
+// keeps them forever
+type Retention struct {
+	Raw     time.Duration
+	Minutes time.Duration
+	Hours   time.Duration
+}
+
+// DefaultRetention keeps the readings for 2 days, the rollups of 5 minutes
+// for 30 days and the hourly rollups for a year
+var DefaultRetention = Retention{Raw: 48 * time.Hour, Minutes: 30 * 24 * time.Hour, Hours: 365 * 24 * time.Hour}
+
+func (retention Retention) of(resolution Resolution) time.Duration {
+	switch resolution {
+	case resolutions[0]:
+		return retention.Raw
+	case resolutions[1]:
+		return retention.Minutes
+	default:
+		return retention.Hours
+	}
+}
+
+// Point aggregates the readings of a series from Time until the next point
+type Point struct {
+	Time  time.Time `json:"time"`
+	Count int64     `json:"count"`
+	Mean  float64   `json:"mean"`
+	Min   float64   `json:"min"`
+	Max   float64   `json:"max"`
+	Last  float64   `json:"last"`
+	Sum   float64   `json:"sum"`
+	// lastAt is the time of the last reading in unix nanoseconds, it is
+	// left out of the points returned by the queries
+	lastAt int64
+}
+
+func newPoint(at time.Time, value float64) Point {
+	return Point{Time: at, Count: 1, Mean: value, Min: value, Max: value, Last: value, Sum: value, lastAt: at.UnixNano()}
+}
+
+func (point Point) merge(other Point) Point {
+	if point.Count == 0 {
+		other.Time = point.Time
+		return other
+	}
+	point.Count += other.Count
+	point.Sum += other.Sum
+	point.Mean = point.Sum / float64(point.Count)
+	point.Min = math.Min(point.Min, other.Min)
+	point.Max = math.Max(point.Max, other.Max)
+	if other.lastAt >= point.lastAt {
+		point.Last, point.lastAt = other.Last, other.lastAt
+	}
+	return point
+}
+
+func (point Point) encode() []byte {
+	data := make([]byte, 48)
+	for i, value := range []uint64{uint64(point.Count), math.Float64bits(point.Sum), math.Float64bits(point.Min),
+		math.Float64bits(point.Max), math.Float64bits(point.Last), uint64(point.lastAt)} {
+		binary.BigEndian.PutUint64(data[i*8:], value)
+	}
+	return data
+}
+
+func decodePoint(at time.Time, data []byte) (Point, error) {
+	if len(data) != 48 {
+		return Point{}, fmt.Errorf("rollup at %s has %d bytes, expected 48", at.Format(time.RFC3339), len(data))
+	}
+	value := func(i int) uint64 { return binary.BigEndian.Uint64(data[i*8:]) }
+	point := Point{
+		Time:   at,
+		Count:  int64(value(0)),
+		Sum:    math.Float64frombits(value(1)),
+		Min:    math.Float64frombits(value(2)),
+		Max:    math.Float64frombits(value(3)),
+		Last:   math.Float64frombits(value(4)),
+		lastAt: int64(value(5)),
+	}
+	if point.Count > 0 {
+		point.Mean = point.Sum / float64(point.Count)
+	}
+	return point, nil
+}
+
+func timeKey(at time.Time) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, uint64(at.UnixNano()))
+	return key
+}
+
+func keyTime(key []byte) time.Time {
+	return time.Unix(0, int64(binary.BigEndian.Uint64(key))).UTC()
+}
+
+// DB persists the readings in a BoltDB file with a bucket for every tier
+// holding a bucket for every series keyed by the time in nanoseconds
+type DB struct {
+	bolt      *bbolt.DB
+	retention Retention
+
+	// Clock returns the current time, used to choose the tier of a query
+	// and to apply the retention
+	Clock func() time.Time
+}
+
+// Open opens the BoltDB file, the file is created when missing
+func Open(file string, retention Retention) (*DB, error) {
+	err := os.MkdirAll(filepath.Dir(file), 0700)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create telemetry directory, reason: %v", err)
+	}
+	bolt, err := bbolt.Open(file, 0600, &bbolt.Options{Timeout: openTimeout})
+	if err != nil {
+		return nil, fmt.Errorf("unable to open telemetry file %s, reason: %v", file, err)
+	}
+	err = bolt.Update(func(tx *bbolt.Tx) error {
+		for _, resolution := range resolutions {
+			if _, err := tx.CreateBucketIfNotExists([]byte(resolution.String())); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		_ = bolt.Close()
+		return nil, err
+	}
+	return &DB{bolt: bolt, retention: retention, Clock: time.Now}, nil
+}
+
+// Close closes the BoltDB file
+func (db *DB) Close() error {
+	return db.bolt.Close()
+}
+
+// Append persists the readings and adds them to the rollups of their
+// series
+func (db *DB) Append(readings ...Reading) error {
+	for _, reading := range readings {
+		if err := reading.Validate(); err != nil {
+			return err
+		}
+	}
+	return db.bolt.Update(func(tx *bbolt.Tx) error {
+		for _, reading := range readings {
+			for _, resolution := range resolutions {
+				series, err := tx.Bucket([]byte(resolution.String())).CreateBucketIfNotExists([]byte(reading.Key()))
+				if err != nil {
+					return err
+				}
+				if resolution == 0 {
+					value := make([]byte, 8)
+					binary.BigEndian.PutUint64(value, math.Float64bits(reading.Value))
+					err = series.Put(timeKey(reading.Time), value)
+				} else {
+					err = rollup(series, resolution, reading)
+				}
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+func rollup(series *bbolt.Bucket, resolution Resolution, reading Reading) error {
+	at := reading.Time.UTC().Truncate(time.Duration(resolution))
+	point := Point{Time: at}
+	if data := series.Get(timeKey(at)); data != nil {
+		existing, err := decodePoint(at, data)
+		if err != nil {
+			return err
+		}
+		point = existing
+	}
+	return series.Put(timeKey(at), point.merge(newPoint(reading.Time, reading.Value)).encode())
+}
+
+// Metrics returns the metrics having readings for the device given as
+// building-id/floor-id/room-id/device-id
+func (db *DB) Metrics(device string) ([]string, error) {
+	metrics := []string{}
+	prefix := []byte(device + "/")
+	err := db.bolt.View(func(tx *bbolt.Tx) error {
+		cursor := tx.Bucket([]byte(resolutions[len(resolutions)-1].String())).Cursor()
+		for key, _ := cursor.Seek(prefix); key != nil && strings.HasPrefix(string(key), string(prefix)); key, _ = cursor.Next() {
+			metrics = append(metrics, strings.TrimPrefix(string(key), string(prefix)))
+		}
+		return nil
+	})
+	return metrics, err
+}
+
+// Query returns the points of the series from the time until the time, the
+// points are aggregated into intervals of the step when given. The query
+// reads the coarsest tier finer than the step which retains the from time,
+// Resolution of the tier is returned along with the points
+func (db *DB) Query(series Series, from, to time.Time, step time.Duration) ([]Point, Resolution, error) {
+	if !from.Before(to) {
+		return nil, 0, errors.New("from should be before to")
+	}
+	resolution := db.resolutionFor(from, step)
+
+	points := []Point{}
+	err := db.bolt.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(resolution.String())).Bucket([]byte(series.Key()))
+		if bucket == nil {
+			return nil
+		}
+		start := from.UTC().Truncate(time.Duration(resolution))
+		cursor := bucket.Cursor()
+		for key, value := cursor.Seek(timeKey(start)); key != nil; key, value = cursor.Next() {
+			at := keyTime(key)
+			if !at.Before(to) {
+				break
+			}
+			point := newPoint(at, math.Float64frombits(binary.BigEndian.Uint64(value)))
+			if resolution != 0 {
+				var err error
+				if point, err = decodePoint(at, value); err != nil {
+					return err
+				}
+			}
+			if step > 0 {
+				bucketed := at.Truncate(step)
+				if len(points) > 0 && points[len(points)-1].Time.Equal(bucketed) {
+					points[len(points)-1] = points[len(points)-1].merge(point)
+					continue
+				}
+				point.Time = bucketed
+			}
+			points = append(points, point)
+		}
+		return nil
+	})
+	for i := range points {
+		points[i].lastAt = 0
+	}
+	return points, resolution, err
+}
+
+// resolutionFor returns the coarsest resolution finer than the step among
+// the tiers retaining the time, the finest of them without a step
+func (db *DB) resolutionFor(from time.Time, step time.Duration) Resolution {
+	now := db.Clock()
+	retained := []Resolution{}
+	for _, resolution := range resolutions {
+		retention := db.retention.of(resolution)
+		if retention == 0 || !from.Before(now.Add(-retention)) {
+			retained = append(retained, resolution)
+		}
+	}
+	if len(retained) == 0 {
+		return resolutions[len(resolutions)-1]
+	}
+
+	chosen := retained[0]
+	for _, resolution := range retained {
+		if step > 0 && time.Duration(resolution) <= step {
+			chosen = resolution
+		}
+	}
+	return chosen
+}
+
+// Prune removes the readings and the rollups which are no longer retained
+func (db *DB) Prune(now time.Time) (int, error) {
+	removed := 0
+	err := db.bolt.Update(func(tx *bbolt.Tx) error {
+		for _, resolution := range resolutions {
+			retention := db.retention.of(resolution)
+			if retention == 0 {
+				continue
+			}
+			cutoff := timeKey(now.Add(-retention))
+			tier := tx.Bucket([]byte(resolution.String()))
+
+			empty := [][]byte{}
+			err := tier.ForEach(func(name, _ []byte) error {
+				series := tier.Bucket(name)
+				cursor := series.Cursor()
+				for key, _ := cursor.First(); key != nil && string(key) < string(cutoff); key, _ = cursor.First() {
+					if err := cursor.Delete(); err != nil {
+						return err
+					}
+					removed++
+				}
+				if key, _ := cursor.First(); key == nil {
+					empty = append(empty, append([]byte{}, name...))
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			for _, name := range empty {
+				if err := tier.DeleteBucket(name); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	return removed, err
+}
+
+// Run prunes the readings every interval until stopped
+func (db *DB) Run(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		removed, err := db.Prune(db.Clock())
+		if err != nil {
+			log.Printf("unable to prune telemetry, reason: %v", err)
+			continue
+		}
+		if removed > 0 {
+			log.Printf("pruned %d telemetry points which are no longer retained", removed)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package telemetry_test
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/telemetry"
+)
+
+var temperature = telemetry.Series{Building: "home", Floor: "ground", Room: "hall", Device: "sensor", Metric: "temperature"}
+
+func openDB(t *testing.T, now time.Time) *telemetry.DB {
+	db, err := telemetry.Open(filepath.Join(t.TempDir(), "telemetry", "telemetry.db"), telemetry.DefaultRetention)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	db.Clock = func() time.Time { return now }
+	return db
+}
+
+// appendMinutely appends a reading every minute from the time with the
+// value of the minutes since the time
+func appendMinutely(t *testing.T, db *telemetry.DB, from time.Time, minutes int) {
+	readings := []telemetry.Reading{}
+	for i := 0; i < minutes; i++ {
+		readings = append(readings, telemetry.Reading{Series: temperature, Time: from.Add(time.Duration(i) * time.Minute), Value: float64(i)})
+	}
+	assert.NoError(t, db.Append(readings...))
+}
+
+func TestDB_Query(t *testing.T) {
+	start := time.Date(2026, 10, 19, 6, 0, 0, 0, time.UTC)
+
+	t.Run("should return the raw readings without a step", func(t *testing.T) {
+		db := openDB(t, start.Add(time.Hour))
+		appendMinutely(t, db, start, 10)
+
+		points, resolution, err := db.Query(temperature, start.Add(2*time.Minute), start.Add(4*time.Minute), 0)
+
+		if assert.NoError(t, err) && assert.Len(t, points, 2) {
+			assert.Equal(t, "raw", resolution.String())
+			assert.Equal(t, start.Add(2*time.Minute), points[0].Time)
+			assert.Equal(t, telemetry.Point{Time: points[1].Time, Count: 1, Mean: 3, Min: 3, Max: 3, Last: 3, Sum: 3}, points[1])
+		}
+	})
+
+	t.Run("should aggregate the points into the step using the rollups", func(t *testing.T) {
+		db := openDB(t, start.Add(2*time.Hour))
+		appendMinutely(t, db, start, 120)
+
+		points, resolution, err := db.Query(temperature, start, start.Add(2*time.Hour), 30*time.Minute)
+
+		if assert.NoError(t, err) && assert.Len(t, points, 4) {
+			assert.Equal(t, "5m", resolution.String())
+			assert.Equal(t, telemetry.Point{Time: start, Count: 30, Mean: 14.5, Min: 0, Max: 29, Last: 29, Sum: 435}, points[0])
+			assert.Equal(t, start.Add(90*time.Minute), points[3].Time)
+		}
+
+		_, resolution, err = db.Query(temperature, start, start.Add(2*time.Hour), 24*time.Hour)
+		assert.NoError(t, err)
+		assert.Equal(t, "1h", resolution.String())
+	})
+
+	t.Run("should read the rollups once the readings are no longer retained", func(t *testing.T) {
+		db := openDB(t, start.Add(72*time.Hour))
+		appendMinutely(t, db, start, 60)
+
+		points, resolution, err := db.Query(temperature, start, start.Add(time.Hour), 0)
+
+		if assert.NoError(t, err) && assert.Len(t, points, 12) {
+			assert.Equal(t, "5m", resolution.String())
+			assert.Equal(t, telemetry.Point{Time: start.Add(55 * time.Minute), Count: 5, Mean: 57, Min: 55, Max: 59, Last: 59, Sum: 285}, points[11])
+		}
+	})
+
+	t.Run("should reject from after to", func(t *testing.T) {
+		db := openDB(t, start)
+
+		_, _, err := db.Query(temperature, start, start, 0)
+
+		assert.EqualError(t, err, "from should be before to")
+	})
+}
+
+func TestDB_Append(t *testing.T) {
+	start := time.Date(2026, 10, 19, 6, 0, 0, 0, time.UTC)
+
+	t.Run("should reject the readings which are not finite", func(t *testing.T) {
+		db := openDB(t, start.Add(time.Hour))
+
+		for _, value := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+			err := db.Append(telemetry.Reading{Series: temperature, Time: start, Value: value})
+
+			assert.EqualError(t, err, "value of temperature should be a finite number")
+		}
+		points, _, err := db.Query(temperature, start, start.Add(time.Hour), 5*time.Minute)
+		assert.NoError(t, err)
+		assert.Empty(t, points)
+	})
+}
+
+func TestDB_Prune(t *testing.T) {
+	start := time.Date(2026, 10, 19, 6, 0, 0, 0, time.UTC)
+	db := openDB(t, start)
+	appendMinutely(t, db, start, 60)
+
+	removed, err := db.Prune(start.Add(49 * time.Hour))
+
+	assert.NoError(t, err)
+	assert.Equal(t, 60, removed)
+	db.Clock = func() time.Time { return start }
+	points, _, err := db.Query(temperature, start, start.Add(time.Hour), 0)
+	assert.NoError(t, err)
+	assert.Empty(t, points)
+	points, _, err = db.Query(temperature, start, start.Add(time.Hour), 5*time.Minute)
+	assert.NoError(t, err)
+	assert.Len(t, points, 12)
+
+	metrics, err := db.Metrics("home/ground/hall/sensor")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"temperature"}, metrics)
+}
+
+func TestResolution_String(t *testing.T) {
+	for duration, expected := range map[time.Duration]string{0: "raw", 5 * time.Minute: "5m", time.Hour: "1h", 90 * time.Minute: "1h30m", 90 * time.Second: "1m30s"} {
+		assert.Equal(t, expected, telemetry.Resolution(duration).String())
+	}
+}
@@ -0,0 +1,132 @@
+package testutils
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	libKVStore "github.com/kvtools/valkeyrie/store"
+	"github.com/kvtools/valkeyrie/store/boltdb"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/store"
+)
+
+// RecordingNode records the switched devices and fails to switch the
+// devices named broken, it is safe for the parallel switches
+type RecordingNode struct {
+	mu       sync.Mutex
+	switched []string
+}
+
+// On records the device switched on
+func (node *RecordingNode) On(device gateway.Device) error {
+	return node.record("on:" + device.ID())
+}
+
+// Off records the device switched off
+func (node *RecordingNode) Off(device gateway.Device) error {
+	return node.record("off:" + device.ID())
+}
+
+func (node *RecordingNode) record(value string) error {
+	node.mu.Lock()
+	defer node.mu.Unlock()
+	node.switched = append(node.switched, value)
+	if strings.Contains(value, "broken") {
+		return errors.New("node unreachable")
+	}
+	return nil
+}
+
+// Switched returns the switches recorded as state:device-id in order
+func (node *RecordingNode) Switched() []string {
+	node.mu.Lock()
+	defer node.mu.Unlock()
+	return append([]string{}, node.switched...)
+}
+
+var (
+	nodesMu      sync.Mutex
+	nodes        = map[string]gateway.Node{}
+	nodeHosts    int
+	registerOnce sync.Once
+)
+
+// UseNode switches the devices of the mqtt nodes of the host using the node
+// until the test ends. The mqtt driver looking up the nodes by their host
+// is registered once so that the tests do not replace each other's nodes
+func UseNode(t testing.TB, host string, node gateway.Node) {
+	registerOnce.Do(func() {
+		gateway.RegisterDriver(gateway.NodeTypeMqtt, func(metadata gateway.NodeMetadata) (gateway.Node, error) {
+			nodesMu.Lock()
+			defer nodesMu.Unlock()
+			node, ok := nodes[metadata.Host]
+			if !ok {
+				return nil, fmt.Errorf("no node used for host %s", metadata.Host)
+			}
+			return node, nil
+		})
+	})
+
+	nodesMu.Lock()
+	defer nodesMu.Unlock()
+	nodes[host] = node
+	t.Cleanup(func() {
+		nodesMu.Lock()
+		defer nodesMu.Unlock()
+		delete(nodes, host)
+	})
+}
+
+// NewRecordingNode returns the RecordingNode used by the mqtt nodes of the
+// host returned, the host is unique to the node
+func NewRecordingNode(t testing.TB) (*RecordingNode, string) {
+	nodesMu.Lock()
+	nodeHosts++
+	host := fmt.Sprintf("recording-node-%d.local", nodeHosts)
+	nodesMu.Unlock()
+
+	node := &RecordingNode{}
+	UseNode(t, host, node)
+	return node, host
+}
+
+// NewSwitchingStore returns the store backed by a BoltDB file in a temp
+// directory along with the building having floor-one/room-one and its
+// devices, node-one of type mqtt controls the devices and its switches are
+// recorded by the node returned
+func NewSwitchingStore(t testing.TB, building gateway.Building, devices ...string) (store.Store, *RecordingNode) {
+	boltdb.Register()
+	kvStore, err := store.NewKVStore(string(libKVStore.BOLTDB), "dwarka", filepath.Join(t.TempDir(), "dwarka.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	persistentStore := store.NewPersistentStore("dwarka", kvStore)
+
+	room := NewRoom("room-one")
+	room.Floor.Building = building
+	seed := []error{
+		persistentStore.UpsertBuilding(building),
+		persistentStore.UpsertFloor(room.Floor),
+		persistentStore.UpsertRoom(room),
+	}
+	for _, name := range devices {
+		device := NewDevice(name)
+		device.Room = room
+		seed = append(seed, persistentStore.UpsertDevice(device))
+	}
+	node, host := NewRecordingNode(t)
+	seed = append(seed, persistentStore.UpsertNodes(room, gateway.Nodes{"node-one": gateway.NodeMetadata{
+		Room: room, Devices: devices, Host: host, Type: gateway.NodeTypeMqtt,
+		PhysicalEntity: gateway.PhysicalEntity{Name: "node-one"},
+	}}))
+	for _, err := range seed {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return persistentStore, node
+}
@@ -34,6 +34,37 @@ func NewFloors(name string) (gateway.Floors, gateway.Floor) {
 	return gateway.Floors{name: floor}, floor
 }
 
+// NewRoom return new room from name
+func NewRoom(name string) gateway.Room {
+	return gateway.Room{
+		Floor:          NewFloor("floor-one"),
+		Direction:      gateway.DirectionNorth,
+		PhysicalEntity: gateway.PhysicalEntity{Name: name},
+	}
+}
+
+// NewRooms creates and returns a room from name and
+// rooms after associating it
+func NewRooms(name string) (gateway.Rooms, gateway.Room) {
+	room := NewRoom(name)
+	return gateway.Rooms{name: room}, room
+}
+
+// NewDevice return new device from name
+func NewDevice(name string) gateway.Device {
+	return gateway.Device{
+		Room:           NewRoom("room-one"),
+		PhysicalEntity: gateway.PhysicalEntity{Name: name},
+	}
+}
+
+// NewDevices creates and returns a device from name and
+// devices after associating it
+func NewDevices(name string) (gateway.Devices, gateway.Device) {
+	device := NewDevice(name)
+	return gateway.Devices{name: device}, device
+}
+
 // AssociateFloorToBuilding associate the floor to the building
 func AssociateFloorToBuilding(building gateway.Building, floor gateway.Floor) gateway.Floor {
 	floor.Building = building
@@ -14,10 +14,16 @@ import (
 
 // ServeHTTPRequest serves http request using provided fasthttp handler
 func ServeHTTPRequest(store store.Store, req *http.Request) (*http.Response, error) {
+	client, closeServer := ServeInmemory(store)
+	defer closeServer()
+
+	return client.Do(req)
+}
+
+// ServeInmemory serves the api over an in-memory listener and returns the
+// http client connected to it along with the function to stop serving
+func ServeInmemory(store store.Store) (*http.Client, func()) {
 	ln := fasthttputil.NewInmemoryListener()
-	defer func() {
-		_ = ln.Close()
-	}()
 
 	go func() {
 		httpServer := api.NewServer("", "", store)
@@ -27,7 +33,7 @@ func ServeHTTPRequest(store store.Store, req *http.Request) (*http.Response, err
 		}
 	}()
 
-	client := http.Client{
+	client := &http.Client{
 		Transport: &http.Transport{
 			DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
 				return ln.Dial()
@@ -35,7 +41,9 @@ func ServeHTTPRequest(store store.Store, req *http.Request) (*http.Response, err
 		},
 	}
 
-	return client.Do(req)
+	return client, func() {
+		_ = ln.Close()
+	}
 }
 
 // Read unmarshal response.Body into the type provided as input
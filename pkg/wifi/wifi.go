@@ -0,0 +1,115 @@
+// Package wifi drives the wifi nodes through the HTTP command API of the
+// Tasmota firmware, the devices of a node are switched by the relays in
+// the order of the devices of the node i.e. the first device is Power1
+package wifi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+)
+
+// Timeout is the time a node is given to respond to a command
+const Timeout = 5 * time.Second
+
+// Register registers the driver of the wifi nodes
+func Register() {
+	gateway.RegisterDriver(gateway.NodeTypeWifi, NewNode)
+}
+
+// relayNode switches the devices of a wifi node and reports their state
+type relayNode struct {
+	metadata gateway.NodeMetadata
+	baseURL  string
+	client   *http.Client
+}
+
+// NewNode returns the node of the metadata, the host is the address of the
+// node e.g. 192.168.1.20 or http://192.168.1.20:8080
+func NewNode(metadata gateway.NodeMetadata) (gateway.Node, error) {
+	baseURL := strings.TrimSuffix(metadata.Host, "/")
+	if !strings.Contains(baseURL, "://") {
+		baseURL = "http://" + baseURL
+	}
+	if _, err := url.Parse(baseURL); err != nil {
+		return nil, fmt.Errorf("invalid host %s of node %s, %v", metadata.Host, metadata.ID(), err)
+	}
+	return relayNode{metadata: metadata, baseURL: baseURL, client: &http.Client{Timeout: Timeout}}, nil
+}
+
+// On switches on the relay of the device
+func (node relayNode) On(device gateway.Device) error {
+	return node.switchTo(device, gateway.StateOn)
+}
+
+// Off switches off the relay of the device
+func (node relayNode) Off(device gateway.Device) error {
+	return node.switchTo(device, gateway.StateOff)
+}
+
+// State returns the state of the relay of the device
+func (node relayNode) State(device gateway.Device) (gateway.State, error) {
+	return node.power(device, "")
+}
+
+func (node relayNode) switchTo(device gateway.Device, state gateway.State) error {
+	actual, err := node.power(device, state)
+	if err != nil {
+		return err
+	}
+	if actual != state {
+		return fmt.Errorf("node %s switched %s %s instead of %s", node.metadata.ID(), device.ID(), actual, state)
+	}
+	return nil
+}
+
+// power sends the Power command of the relay of the device along with the
+// state when given and returns the state of the relay responded
+func (node relayNode) power(device gateway.Device, state gateway.State) (gateway.State, error) {
+	relay, err := node.relayOf(device)
+	if err != nil {
+		return "", err
+	}
+	command := fmt.Sprintf("Power%d", relay)
+	if state != "" {
+		command += " " + string(state)
+	}
+
+	res, err := node.client.Get(node.baseURL + "/cm?cmnd=" + url.QueryEscape(command))
+	if err != nil {
+		return "", fmt.Errorf("node %s is unreachable, %v", node.metadata.ID(), err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("node %s responded %s to %s", node.metadata.ID(), res.Status, command)
+	}
+
+	response := map[string]interface{}{}
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		return "", fmt.Errorf("unable to parse the response of node %s, %v", node.metadata.ID(), err)
+	}
+	// the nodes with a single relay respond POWER instead of POWER1
+	value, ok := response[fmt.Sprintf("POWER%d", relay)].(string)
+	if !ok && relay == 1 {
+		value, ok = response["POWER"].(string)
+	}
+	if !ok {
+		return "", fmt.Errorf("node %s has no relay %d for %s", node.metadata.ID(), relay, device.ID())
+	}
+	return gateway.NewState(value)
+}
+
+// relayOf returns the relay of the device starting from 1
+func (node relayNode) relayOf(device gateway.Device) (int, error) {
+	for i, id := range node.metadata.Devices {
+		if id == device.ID() {
+			return i + 1, nil
+		}
+	}
+	return 0, fmt.Errorf("node %s does not control %s", node.metadata.ID(), device.ID())
+}
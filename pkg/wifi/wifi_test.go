@@ -0,0 +1,91 @@
+package wifi_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/gateway"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/testutils"
+	"gitlab.com/vedhabhavanam/smarthome/dwarka/pkg/wifi"
+)
+
+// newTasmota returns a node responding to the Power commands of its relays
+// like the Tasmota firmware, the commands received are recorded
+func newTasmota(t *testing.T, relays map[string]string, commands *[]string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		command := r.URL.Query().Get("cmnd")
+		*commands = append(*commands, command)
+		fields := strings.Fields(command)
+		relay := strings.ToUpper(fields[0])
+		if _, ok := relays[relay]; !ok {
+			_ = json.NewEncoder(w).Encode(map[string]string{"Command": "Unknown"})
+			return
+		}
+		if len(fields) > 1 {
+			relays[relay] = strings.ToUpper(fields[1])
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{relay: relays[relay]})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestNode(t *testing.T) {
+	metadata := func(host string) gateway.NodeMetadata {
+		return gateway.NodeMetadata{Type: gateway.NodeTypeWifi, Host: host, Devices: []string{"porch-light", "fan"},
+			PhysicalEntity: gateway.PhysicalEntity{Name: "porch-node"}}
+	}
+
+	t.Run("should switch the relay of the device", func(t *testing.T) {
+		commands := []string{}
+		server := newTasmota(t, map[string]string{"POWER1": "OFF", "POWER2": "OFF"}, &commands)
+		node, err := wifi.NewNode(metadata(server.URL))
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		assert.NoError(t, node.On(testutils.NewDevice("fan")))
+		assert.NoError(t, node.Off(testutils.NewDevice("porch-light")))
+
+		state, err := node.(gateway.Reporter).State(testutils.NewDevice("fan"))
+		assert.NoError(t, err)
+		assert.Equal(t, gateway.StateOn, state)
+		assert.Equal(t, []string{"Power2 on", "Power1 off", "Power2"}, commands)
+	})
+
+	t.Run("should read the state of a single relay", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"POWER":"ON"}`))
+		}))
+		defer server.Close()
+		node, _ := wifi.NewNode(metadata(strings.TrimPrefix(server.URL, "http://")))
+
+		state, err := node.(gateway.Reporter).State(testutils.NewDevice("porch-light"))
+
+		assert.NoError(t, err)
+		assert.Equal(t, gateway.StateOn, state)
+	})
+
+	t.Run("should fail for the devices without a relay", func(t *testing.T) {
+		commands := []string{}
+		server := newTasmota(t, map[string]string{"POWER1": "OFF"}, &commands)
+		node, _ := wifi.NewNode(metadata(server.URL))
+
+		assert.EqualError(t, node.On(testutils.NewDevice("fan")), "node porch-node has no relay 2 for fan")
+		assert.EqualError(t, node.On(testutils.NewDevice("lamp")), "node porch-node does not control lamp")
+		assert.Equal(t, []string{"Power2 on"}, commands)
+	})
+
+	t.Run("should fail when the node is unreachable", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		node, _ := wifi.NewNode(metadata(server.URL))
+
+		assert.EqualError(t, node.Off(testutils.NewDevice("fan")), "node porch-node responded 404 Not Found to Power2 off")
+		server.Close()
+		assert.Error(t, node.Off(testutils.NewDevice("fan")))
+	})
+}